@@ -0,0 +1,34 @@
+package main
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/jobs"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// newDebugServer returns the server for DEBUG_ADDR. It serves net/http/pprof
+// profiles under /debug/pprof/, e.g. /debug/pprof/goroutine?debug=1 to find
+// leaked WebSocket pumps, and expvar variables at /debug/vars, including the
+// hub and job stats and the goroutine count.
+//
+// Profiles expose memory contents and command lines, so the listener has no
+// authentication of its own: bind it to a loopback or private address that
+// only operators reach, never to one behind the public load balancer.
+func newDebugServer(addr string, hub *ws.Hub, scheduler *jobs.Scheduler) *http.Server {
+	expvar.Publish("hub", expvar.Func(func() any { return hub.Stats() }))
+	expvar.Publish("jobs", expvar.Func(func() any { return scheduler.Stats() }))
+	expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return &http.Server{Addr: addr, Handler: mux}
+}
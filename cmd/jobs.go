@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/dms"
+	"github.com/Vasu1712/scenyx-backend/internal/app/analytics"
+	"github.com/Vasu1712/scenyx-backend/internal/app/expiry"
+	"github.com/Vasu1712/scenyx-backend/internal/app/jobs"
+	"github.com/Vasu1712/scenyx-backend/internal/app/leaderboard"
+	"github.com/Vasu1712/scenyx-backend/internal/app/lifecycle"
+	"github.com/Vasu1712/scenyx-backend/internal/app/links"
+	"github.com/Vasu1712/scenyx-backend/internal/app/notify"
+	"github.com/Vasu1712/scenyx-backend/internal/app/offline"
+	"github.com/Vasu1712/scenyx-backend/internal/app/outbox"
+	"github.com/Vasu1712/scenyx-backend/internal/app/recommend"
+	"github.com/Vasu1712/scenyx-backend/internal/app/reputation"
+	"github.com/Vasu1712/scenyx-backend/internal/app/retention"
+	"github.com/Vasu1712/scenyx-backend/internal/app/schedule"
+	"github.com/Vasu1712/scenyx-backend/internal/app/transcripts"
+	"github.com/Vasu1712/scenyx-backend/internal/app/uploads"
+	"github.com/Vasu1712/scenyx-backend/internal/app/webhooks"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// jobSchedule returns the cron schedule for a job: JOB_SCHEDULE_<NAME>
+// (e.g. JOB_SCHEDULE_RETENTION="0 3 * * *") or def. It returns "" if the
+// variable is set to "off".
+func jobSchedule(name, def string) string {
+	v := strings.TrimSpace(os.Getenv("JOB_SCHEDULE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))))
+	switch {
+	case v == "":
+		return def
+	case strings.EqualFold(v, "off"):
+		return ""
+	default:
+		return v
+	}
+}
+
+// jobDef is a background job and its default schedule.
+type jobDef struct {
+	name    string
+	spec    string
+	timeout time.Duration // Bounds each run
+	fn      jobs.Func
+}
+
+// loadJobs registers the server's background jobs with s.
+//
+//   - scene-activation (@every 30s): remind RSVPs and take scheduled scenes live
+//   - stats-sample (@every 1m): record live listener counts into scene_stats
+//   - webhook-delivery (@every 10s): send queued webhook events and retry failures
+//   - offline-prune (@hourly): delete DM events queued for offline users over a week ago
+//   - scene-recommendations (@every 15m): rescore the public scenes recommended to each user
+//   - notification-summary (@every 1m): send users whose quiet hours ended a
+//     summary of the notifications held back during them
+//   - dm-scheduled-send (@every 10s): send DM messages whose send_at has passed
+//   - scene-transcripts (@every 15s): generate the transcript exports of large
+//     scenes and tell their hosts where to download them
+//   - orphan-blobs (@every 1m): delete the stored files of deleted rows, such
+//     as an ephemeral scene's attachments and transcript exports
+//   - scene-deadlines (@every 15s): warn scenes nearing their maximum duration
+//     and close the ones that have reached it
+//   - dm-message-expiry (@every 1m): delete DM messages past their
+//     conversation's message TTL and tell clients to remove them
+//   - user-reputation (@hourly): rescore each user from their participation
+//     and the reports and scene restrictions against them
+//   - leaderboards (@every 10m): rebuild the weekly and all-time scene and
+//     creator leaderboards
+//   - outbox-publish (@every 5s): publish recorded domain events to the
+//     message broker; only registered when EVENT_BROKER is set
+//   - retention (@hourly): delete data aged out by RETENTION_DM_MESSAGE_DAYS
+//     and RETENTION_STALE_SCENE_DAYS; only registered when either is set
+func loadJobs(s *jobs.Scheduler, stores *storeSet, hub *ws.Hub, dmHandler *dms.DMHandler, transcriber *transcripts.Service, sweeper *uploads.Sweeper, notifier *notify.Dispatcher, dispatcher *webhooks.Dispatcher, publisher *outbox.Publisher, frontend *links.Builder, boards *leaderboard.Service) error {
+	activation := &schedule.Service{Scenes: stores.Scenes, Hub: hub, Notify: notifier, Links: frontend}
+	sampler := &analytics.Sampler{Store: stores.Analytics, Hub: hub}
+	offlineQueue := &offline.Service{Store: stores.Offline}
+	recommender := &recommend.Service{Store: stores.Recommend}
+	expirer := &expiry.Service{Store: stores.DMs, Hub: hub}
+	closer := &lifecycle.Service{Scenes: stores.Scenes, Hub: hub, Notify: notifier, Links: frontend}
+	reputer := &reputation.Service{Store: stores.Reputation}
+
+	defs := []jobDef{
+		{"scene-activation", "@every 30s", 20 * time.Second, activation.Tick},
+		{"stats-sample", "@every 1m", 10 * time.Second, sampler.Sample},
+		{"webhook-delivery", "@every 10s", time.Minute, dispatcher.Deliver},
+		{"offline-prune", "@hourly", 5 * time.Minute, offlineQueue.Prune},
+		{"scene-recommendations", "@every 15m", 2 * time.Minute, recommender.Refresh},
+		{"notification-summary", "@every 1m", 30 * time.Second, notifier.SendSummaries},
+		{"dm-scheduled-send", "@every 10s", time.Minute, dmHandler.SendScheduled},
+		{"scene-transcripts", "@every 15s", 10 * time.Minute, transcriber.Process},
+		{"orphan-blobs", "@every 1m", 5 * time.Minute, sweeper.Sweep},
+		{"scene-deadlines", "@every 15s", time.Minute, closer.EndDue},
+		{"dm-message-expiry", "@every 1m", 30 * time.Second, expirer.Purge},
+		{"user-reputation", "@hourly", 5 * time.Minute, reputer.Refresh},
+		{"leaderboards", "@every 10m", 2 * time.Minute, boards.Refresh},
+	}
+
+	if publisher != nil {
+		defs = append(defs, jobDef{"outbox-publish", "@every 5s", time.Minute, publisher.Publish})
+	}
+
+	policy, err := retention.PolicyFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid retention policy: %w", err)
+	}
+	if policy.Enabled() {
+		cleaner := &retention.Service{Store: stores.Retention, Policy: policy}
+		defs = append(defs, jobDef{"retention", "@hourly", 10 * time.Minute, func(ctx context.Context) error {
+			result, err := cleaner.Clean(ctx)
+			if result.DMMessages > 0 || result.StaleScenes > 0 {
+				log.Printf("Retention cleanup deleted %d DM messages and %d stale scenes", result.DMMessages, result.StaleScenes)
+			}
+			return err
+		}})
+	}
+
+	for _, def := range defs {
+		spec := jobSchedule(def.name, def.spec)
+		if spec == "" {
+			log.Printf("Job %s disabled.", def.name)
+			continue
+		}
+		if err := s.Add(def.name, spec, def.timeout, def.fn); err != nil {
+			return err
+		}
+		log.Printf("Job %s scheduled: %s", def.name, spec)
+	}
+	return nil
+}
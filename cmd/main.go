@@ -1,52 +1,426 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Quiet hours name IANA time zones; embed them for hosts without zoneinfo
 
+	"github.com/Vasu1712/scenyx-backend/internal/api/admin"
+	"github.com/Vasu1712/scenyx-backend/internal/api/attachments"
 	"github.com/Vasu1712/scenyx-backend/internal/api/dms"
+	"github.com/Vasu1712/scenyx-backend/internal/api/gifts"
+	"github.com/Vasu1712/scenyx-backend/internal/api/graphql"
+	"github.com/Vasu1712/scenyx-backend/internal/api/grpc"
+	"github.com/Vasu1712/scenyx-backend/internal/api/integrations"
+	"github.com/Vasu1712/scenyx-backend/internal/api/leaderboards"
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/api/playback"
+	"github.com/Vasu1712/scenyx-backend/internal/api/reports"
 	"github.com/Vasu1712/scenyx-backend/internal/api/scenes"
+	"github.com/Vasu1712/scenyx-backend/internal/api/tickets"
+	"github.com/Vasu1712/scenyx-backend/internal/api/users"
+	webhookapi "github.com/Vasu1712/scenyx-backend/internal/api/webhooks"
+	"github.com/Vasu1712/scenyx-backend/internal/api/workspaces"
+	"github.com/Vasu1712/scenyx-backend/internal/app/achievements"
+	"github.com/Vasu1712/scenyx-backend/internal/app/analytics"
+	"github.com/Vasu1712/scenyx-backend/internal/app/jobs"
+	"github.com/Vasu1712/scenyx-backend/internal/app/leaderboard"
+	"github.com/Vasu1712/scenyx-backend/internal/app/lifecycle"
+	"github.com/Vasu1712/scenyx-backend/internal/app/links"
+	"github.com/Vasu1712/scenyx-backend/internal/app/notify"
+	"github.com/Vasu1712/scenyx-backend/internal/app/offline"
+	"github.com/Vasu1712/scenyx-backend/internal/app/payments"
+	"github.com/Vasu1712/scenyx-backend/internal/app/presence"
+	"github.com/Vasu1712/scenyx-backend/internal/app/spotify"
+	"github.com/Vasu1712/scenyx-backend/internal/app/tipping"
+	"github.com/Vasu1712/scenyx-backend/internal/app/transcripts"
+	"github.com/Vasu1712/scenyx-backend/internal/app/uploads"
+	"github.com/Vasu1712/scenyx-backend/internal/app/webhooks"
 	"github.com/Vasu1712/scenyx-backend/internal/middleware"
-	"github.com/Vasu1712/scenyx-backend/internal/storage/postgres" // Import postgres package
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
 	"github.com/Vasu1712/scenyx-backend/internal/ws"
 )
 
+// shutdownTimeout bounds how long in-flight requests and WebSocket drains may
+// take after SIGINT/SIGTERM before the process exits anyway.
+const shutdownTimeout = 15 * time.Second
+
+// wsTokenTTL is how long a WebSocket token stays valid; clients renew it
+// through /api/v1/users/ws-token.
+const wsTokenTTL = 15 * time.Minute
+
+// avatarFilesPath is where avatars stored on local disk are served from.
+const avatarFilesPath = "/media/avatars"
+
 func main() {
 	port := "8080"
 	if p := os.Getenv("PORT"); p != "" {
 		port = p
 	}
 
-	// --- Database Setup ---
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL == "" {
-		log.Fatal("DATABASE_URL environment variable is not set. Please provide the PostgreSQL connection string.")
+	// --- Storage Setup ---
+	stores, err := openPostgresStores()
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	defer stores.Close() // Ensure the database connections are closed when main exits
+
+	// STORE_CACHE_SIZE caches scene and playback reads in memory (see cacheStores)
+	if err := cacheStores(stores); err != nil {
+		log.Fatalf("Failed to configure the store cache: %v", err)
+	}
+	if os.Getenv("STORE_CACHE_SIZE") != "" && (os.Getenv("REDIS_URL") != "" || os.Getenv("WS_BROKER") == "postgres") {
+		log.Println("STORE_CACHE_SIZE is set on a multi-instance deployment; other instances' writes will only be seen as cached entries expire.")
+	}
+
+	sceneStore := stores.Scenes
+	dmStore := stores.DMs
+	userStore := stores.Users
+	playbackStore := stores.Playback
+
+	// --- WebSocket Hub Setup ---
+	hub := ws.NewHub()
+
+	// With REDIS_URL set, broadcasts fan out through Redis so clients on
+	// other instances behind the load balancer receive them too.
+	// WS_BROKER=postgres does the same with LISTEN/NOTIFY on DATABASE_URL,
+	// for deployments without Redis.
+	redisURL := os.Getenv("REDIS_URL")
+	switch wsBroker := os.Getenv("WS_BROKER"); wsBroker {
+	case "":
+		if redisURL == "" {
+			break
+		}
+		broker, err := ws.NewRedisBroker(redisURL)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis broker: %v", err)
+		}
+		defer broker.Close()
+		hub.UseBroker(broker)
+	case "postgres":
+		if redisURL != "" {
+			log.Fatalf("WS_BROKER=postgres and REDIS_URL both configure a WebSocket broker; set only one")
+		}
+		broker, err := ws.NewPostgresBroker(os.Getenv("DATABASE_URL"))
+		if err != nil {
+			log.Fatalf("Failed to initialize PostgreSQL broker: %v", err)
+		}
+		defer broker.Close()
+		hub.UseBroker(broker)
+	default:
+		log.Fatalf("WS_BROKER must be \"postgres\" or unset, got %q", wsBroker)
+	}
+
+	// WS_PING_PERIOD, WS_PONG_WAIT and WS_WRITE_WAIT (Go durations, e.g. "30s")
+	// tune how quickly dead connections are dropped
+	pump := ws.DefaultPumpConfig
+	for env, d := range map[string]*time.Duration{
+		"WS_PING_PERIOD": &pump.PingPeriod,
+		"WS_PONG_WAIT":   &pump.PongWait,
+		"WS_WRITE_WAIT":  &pump.WriteWait,
+	} {
+		if v := os.Getenv(env); v != "" {
+			if *d, err = time.ParseDuration(v); err != nil || *d <= 0 {
+				log.Fatalf("%s must be a positive duration, got %q", env, v)
+			}
+		}
+	}
+	if pump.PingPeriod >= pump.PongWait {
+		log.Fatalf("WS_PING_PERIOD (%s) must be shorter than WS_PONG_WAIT (%s)", pump.PingPeriod, pump.PongWait)
+	}
+	// WS_MESSAGE_RATE and WS_MESSAGE_BURST budget the frames each client may
+	// send; WS_RATE_POLICY ("drop" or "disconnect") handles those over it
+	if v := os.Getenv("WS_MESSAGE_RATE"); v != "" {
+		if pump.MessageRate, err = strconv.ParseFloat(v, 64); err != nil || pump.MessageRate < 0 {
+			log.Fatalf("WS_MESSAGE_RATE must be a non-negative number, got %q", v)
+		}
+	}
+	if v := os.Getenv("WS_MESSAGE_BURST"); v != "" {
+		if pump.MessageBurst, err = strconv.Atoi(v); err != nil || pump.MessageBurst < 1 {
+			log.Fatalf("WS_MESSAGE_BURST must be a positive integer, got %q", v)
+		}
 	}
+	switch policy := ws.RatePolicy(os.Getenv("WS_RATE_POLICY")); policy {
+	case "":
+	case ws.RateDrop, ws.RateDisconnect:
+		pump.OnRateExceeded = policy
+	default:
+		log.Fatalf("WS_RATE_POLICY must be drop or disconnect, got %q", policy)
+	}
+	// WS_SEND_QUEUE_SIZE bounds the messages queued for each client;
+	// WS_SEND_OVERFLOW ("drop-oldest" or "disconnect") handles those that
+	// fall further behind
+	if v := os.Getenv("WS_SEND_QUEUE_SIZE"); v != "" {
+		if pump.SendQueueSize, err = strconv.Atoi(v); err != nil || pump.SendQueueSize < 1 {
+			log.Fatalf("WS_SEND_QUEUE_SIZE must be a positive integer, got %q", v)
+		}
+	}
+	switch policy := ws.OverflowPolicy(os.Getenv("WS_SEND_OVERFLOW")); policy {
+	case "":
+	case ws.OverflowDropOldest, ws.OverflowDisconnect:
+		pump.OnSendOverflow = policy
+	default:
+		log.Fatalf("WS_SEND_OVERFLOW must be drop-oldest or disconnect, got %q", policy)
+	}
+	// WS_COMPRESS_MIN_BYTES (0 disables) and WS_COMPRESSION_LEVEL (1-9)
+	// control permessage-deflate for clients that support it
+	if v := os.Getenv("WS_COMPRESS_MIN_BYTES"); v != "" {
+		if pump.CompressMinSize, err = strconv.Atoi(v); err != nil || pump.CompressMinSize < 0 {
+			log.Fatalf("WS_COMPRESS_MIN_BYTES must be a non-negative integer, got %q", v)
+		}
+	}
+	if v := os.Getenv("WS_COMPRESSION_LEVEL"); v != "" {
+		if pump.CompressionLevel, err = strconv.Atoi(v); err != nil || pump.CompressionLevel < 1 || pump.CompressionLevel > 9 {
+			log.Fatalf("WS_COMPRESSION_LEVEL must be between 1 and 9, got %q", v)
+		}
+	}
+	hub.UsePumpConfig(pump)
 
-	// Initialize Postgres Scene Store
-	sceneStore, err := postgres.NewPostgresSceneStore(databaseURL)
+	// WS_MAX_CONNS_PER_USER and WS_MAX_CONNS_PER_IP cap simultaneous
+	// connections (0 disables a cap); set WS_TRUST_FORWARDED_FOR=true behind a
+	// proxy so per-IP caps see client addresses
+	limits := ws.DefaultConnLimits
+	for env, n := range map[string]*int{
+		"WS_MAX_CONNS_PER_USER": &limits.PerUser,
+		"WS_MAX_CONNS_PER_IP":   &limits.PerIP,
+	} {
+		if v := os.Getenv(env); v != "" {
+			if *n, err = strconv.Atoi(v); err != nil || *n < 0 {
+				log.Fatalf("%s must be a non-negative integer, got %q", env, v)
+			}
+		}
+	}
+	limits.TrustForwardedFor = os.Getenv("WS_TRUST_FORWARDED_FOR") == "true"
+	hub.UseConnLimits(limits)
+
+	// WS_SHARDS sets how many workers the hub spreads DMs and scenes over, so
+	// a busy scene only delays the rooms sharing its worker
+	if v := os.Getenv("WS_SHARDS"); v != "" {
+		shards, err := strconv.Atoi(v)
+		if err != nil || shards < 1 {
+			log.Fatalf("WS_SHARDS must be a positive integer, got %q", v)
+		}
+		hub.UseShards(shards)
+	}
+
+	// WebSocket upgrades are authenticated with short-lived tokens signed with
+	// WS_TOKEN_KEY (base64). All instances must share it; without it tokens
+	// only work on the process that issued them.
+	wsTokenKey, err := base64.StdEncoding.DecodeString(os.Getenv("WS_TOKEN_KEY"))
 	if err != nil {
-		log.Fatalf("Failed to initialize PostgreSQL scene store: %v", err)
+		log.Fatalf("WS_TOKEN_KEY must be base64-encoded: %v", err)
 	}
-	defer sceneStore.Close() // Ensure the database connection is closed when main exits
+	if len(wsTokenKey) == 0 {
+		log.Println("WS_TOKEN_KEY not set; signing WebSocket tokens with a random per-process key.")
+		wsTokenKey = make([]byte, 32)
+		rand.Read(wsTokenKey)
+	}
+	wsTokens := ws.NewTokenSigner(wsTokenKey, wsTokenTTL)
 
-	// Initialize Postgres DM Store
-	dmStore, err := postgres.NewPostgresDMStore(databaseURL)
+	// FRONTEND_BASE_URL is where the web app is served, e.g. https://scenyx.app;
+	// it forms redirects, share links, and notification deep links, and is the
+	// origin allowed by CORS. It defaults to the local dev server.
+	frontendBaseURL := os.Getenv("FRONTEND_BASE_URL")
+	if frontendBaseURL == "" {
+		frontendBaseURL = links.DefaultBaseURL
+	}
+	frontendLinks, err := links.New(frontendBaseURL)
 	if err != nil {
-		log.Fatalf("Failed to initialize PostgreSQL DM store: %v", err)
+		log.Fatalf("Invalid FRONTEND_BASE_URL: %v", err)
 	}
-	defer dmStore.Close() // Ensure the database connection is closed when main exits
 
+	// Persist last-seen times and tell DM peers when users come and go
+	presenceService := &presence.Service{Users: userStore, DMs: dmStore, Hub: hub}
+	hub.OnPresenceChange(presenceService.HandleChange)
+
+	// Notifications for individual users skip those their settings turn off
+	notifier := &notify.Dispatcher{Store: stores.Notify, Hub: hub}
+
+	// SCENE_EMPTY_CLOSE_AFTER (a Go duration, e.g. "30m") archives scenes
+	// left without connections that long and releases their slugs. Each
+	// instance only sees its own connections, and would close a scene whose
+	// remaining listeners are all on other instances, so it is refused when a
+	// WebSocket broker is configured.
+	if v := os.Getenv("SCENE_EMPTY_CLOSE_AFTER"); v != "" {
+		if redisURL != "" || os.Getenv("WS_BROKER") == "postgres" {
+			log.Fatalf("SCENE_EMPTY_CLOSE_AFTER only works on a single instance; unset it or the WebSocket broker (REDIS_URL, WS_BROKER)")
+		}
+		after, err := time.ParseDuration(v)
+		if err != nil || after <= 0 {
+			log.Fatalf("SCENE_EMPTY_CLOSE_AFTER must be a positive duration, got %q", v)
+		}
+		closer := &lifecycle.Service{Scenes: sceneStore, Hub: hub, Notify: notifier, Links: frontendLinks}
+		hub.OnSceneEmpty(after, closer.HandleEmpty)
+	}
+
+	// Record listening sessions for scene analytics, then check the badges
+	// listening earns, which read those sessions
+	analyticsService := &analytics.Service{Store: stores.Analytics}
+	achiever := &achievements.Engine{Store: stores.Badges, Scenes: sceneStore, Hub: hub}
+	hub.OnListenerChange(func(e ws.ListenerEvent) {
+		analyticsService.HandleListenerChange(e)
+		achiever.HandleListenerChange(e)
+	})
+
+	// Queue DM events for participants who are not connected and deliver them on connect
+	offlineService := &offline.Service{Store: stores.Offline, DMs: dmStore, Hub: hub}
+	hub.OnDMEvent(offlineService.HandleDMEvent)
+	hub.OnDMConnect(offlineService.HandleDMConnect)
 
-	// --- WebSocket Hub Setup ---
-	hub := ws.NewHub()
 	go hub.Run() // Start the WebSocket hub in a goroutine
 
+	// --- Uploads Setup ---
+	// Message attachments are optional; they are enabled when S3_BUCKET is configured.
+	// S3_ENDPOINT selects an S3-compatible provider (MinIO, R2, ...) instead of AWS.
+	var uploadService *uploads.Service
+	var attachmentStore storage.AttachmentStore
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		uploadService, err = uploads.NewService(uploads.Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          bucket,
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicBaseURL:   os.Getenv("S3_PUBLIC_BASE_URL"),
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize uploads service: %v", err)
+		}
+		attachmentStore = stores.Attachments
+	} else {
+		log.Println("S3_BUCKET not set; message attachments disabled.")
+	}
+
+	// Avatars and scene covers go to the S3 bucket when one is configured and
+	// to local disk otherwise; AVATAR_STORAGE ("s3" or "disk") overrides the choice.
+	var avatarStore uploads.Blobs
+	var diskAvatars *uploads.DiskStore
+	switch os.Getenv("AVATAR_STORAGE") {
+	case "s3":
+		if uploadService == nil {
+			log.Fatal("AVATAR_STORAGE=s3 requires S3_BUCKET to be set")
+		}
+		avatarStore = uploadService
+	case "", "disk":
+		if uploadService != nil && os.Getenv("AVATAR_STORAGE") == "" {
+			avatarStore = uploadService
+			break
+		}
+		avatarDir := os.Getenv("AVATAR_DIR")
+		if avatarDir == "" {
+			avatarDir = "data/avatars"
+		}
+		diskAvatars, err = uploads.NewDiskStore(avatarDir, avatarFilesPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize avatar storage: %v", err)
+		}
+		avatarStore = diskAvatars
+		log.Printf("Storing avatars on local disk in %s.", avatarDir)
+	default:
+		log.Fatalf("AVATAR_STORAGE must be s3 or disk, got %q", os.Getenv("AVATAR_STORAGE"))
+	}
+
+	// --- Moderation Setup ---
+	// Messages pass through the content filter when one is configured (see loadModerator).
+	moderator, err := loadModerator(stores.Moderation)
+	if err != nil {
+		log.Fatalf("Failed to initialize content moderation: %v", err)
+	}
+	if moderator == nil {
+		log.Println("No moderation filters configured; content moderation disabled.")
+	}
+
+	// --- Event Outbox Setup ---
+	// With EVENT_BROKER set, scene and DM events are recorded in the outbox and
+	// published by the outbox-publish job (see loadEventPublisher).
+	publisher, err := loadEventPublisher(stores.Outbox)
+	if err != nil {
+		log.Fatalf("Failed to initialize event publishing: %v", err)
+	}
+	if publisher != nil {
+		defer publisher.Close()
+	}
+
+	// Scene and DM events are queued for registered webhooks and delivered by the webhook-delivery job
+	dispatcher := &webhooks.Dispatcher{Store: stores.Webhooks, Client: &http.Client{Timeout: 10 * time.Second}}
+
+	// ADMIN_USER_IDS is a comma-separated list of users who can work the
+	// report queue and export conversations for compliance requests
+	admins := make(map[string]bool)
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = true
+		}
+	}
+
+	// Transcripts of large scenes are generated by a job and stored with the uploads
+	transcriber := &transcripts.Service{Store: stores.Transcripts, Scenes: sceneStore, Playback: playbackStore, Blobs: avatarStore, Hub: hub}
+
+	// Files whose rows were deleted, such as an ephemeral scene's attachments
+	// and transcript exports, are removed from the store that holds them
+	sweeper := &uploads.Sweeper{Store: stores.Orphans, Buckets: map[string]uploads.Blobs{models.BucketTranscripts: avatarStore}}
+	if uploadService != nil {
+		sweeper.Buckets[models.BucketAttachments] = uploadService
+	}
+
+	// --- Payments Setup ---
+	// Ticketed scenes and Stripe-paid gifts are optional; they are enabled when
+	// STRIPE_SECRET_KEY is configured. STRIPE_WEBHOOK_SECRET verifies the
+	// events Stripe posts to /api/v1/tickets/stripe/webhook.
+	var paymentService *payments.Service
+	var ticketStore storage.TicketStore
+	if secretKey := os.Getenv("STRIPE_SECRET_KEY"); secretKey != "" {
+		paymentService, err = payments.NewService(payments.Config{
+			SecretKey:     secretKey,
+			WebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		}, stores.Tickets, stores.Gifts)
+		if err != nil {
+			log.Fatalf("Failed to initialize Stripe payments: %v", err)
+		}
+		ticketStore = stores.Tickets
+	} else {
+		log.Println("STRIPE_SECRET_KEY not set; ticketed scenes and Stripe gifts disabled.")
+	}
+
+	// Leaderboards are rebuilt by the leaderboards job and cached in memory between runs
+	boards := &leaderboard.Service{Store: stores.Boards}
+
+	// Gifts paid with credits work without Stripe
+	tipper := &tipping.Service{Store: stores.Gifts, Payments: paymentService, Hub: hub, Links: frontendLinks}
+
 	// --- Handlers Setup ---
 	// Pass the PostgreSQL-backed stores to your handlers
-	dmHandler := &dms.DMHandler{Store: dmStore, Hub: hub}
-	sceneHandler := &scenes.SceneHandler{Store: sceneStore, Hub: hub}
+	dmHandler := &dms.DMHandler{Store: dmStore, Attachments: attachmentStore, Moderator: moderator, Webhooks: dispatcher, Hub: hub, Notify: notifier, Tokens: wsTokens, Workspaces: stores.Workspaces, Admins: admins}
+	sceneHandler := &scenes.SceneHandler{Store: sceneStore, Analytics: stores.Analytics, Hashtags: stores.Hashtags, Recommendations: stores.Recommend, Playback: playbackStore, Attachments: attachmentStore, Covers: avatarStore, Moderator: moderator, Webhooks: dispatcher, Hub: hub, Notify: notifier, Tokens: wsTokens, Links: frontendLinks, Transcripts: transcriber, Tickets: ticketStore, Achievements: achiever, Reputation: stores.Reputation, Workspaces: stores.Workspaces}
+	userHandler := &users.UserHandler{Store: userStore, Notifications: stores.Notify, Hub: hub, Avatars: avatarStore, Tokens: wsTokens, Badges: stores.Badges}
+	playbackHandler := &playback.PlaybackHandler{Store: playbackStore, Scenes: sceneStore, Workspaces: stores.Workspaces, Hub: hub}
+	leaderboardHandler := &leaderboards.LeaderboardHandler{Leaderboards: boards}
+	giftHandler := &gifts.GiftHandler{Tipping: tipper, Store: stores.Gifts, Scenes: sceneStore, Moderator: moderator, Tokens: wsTokens, Admins: admins}
+
+	reportHandler := &reports.ReportHandler{Store: stores.Moderation, DMs: dmStore, Scenes: sceneStore, Hub: hub, Tokens: wsTokens, Admins: admins}
+	graphqlHandler := &graphql.GraphQLHandler{Scenes: sceneStore, DMs: dmStore, Users: userStore, Workspaces: stores.Workspaces, Hub: hub, Tokens: wsTokens}
+	webhookHandler := &webhookapi.WebhookHandler{Store: stores.Webhooks, Scenes: sceneStore, Tokens: wsTokens, Admins: admins}
+	workspaceHandler := &workspaces.WorkspaceHandler{Store: stores.Workspaces, Hub: hub}
+
+	// Background jobs run on one instance at a time, elected through the database
+	scheduler := &jobs.Scheduler{Leader: stores.Leader}
+	if err := loadJobs(scheduler, stores, hub, dmHandler, transcriber, sweeper, notifier, dispatcher, publisher, frontendLinks, boards); err != nil {
+		log.Fatalf("Failed to configure background jobs: %v", err)
+	}
+	jobHandler := &admin.JobHandler{Scheduler: scheduler, Tokens: wsTokens, Admins: admins}
+	hubHandler := &admin.HubHandler{Hub: hub, Tokens: wsTokens, Admins: admins}
 
 	// --- HTTP Server Setup ---
 	mux := http.NewServeMux()
@@ -55,6 +429,75 @@ func main() {
 	dms.RegisterDMRoutes(mux, dmHandler)
 	// Register routes for Scenes
 	scenes.RegisterSceneRoutes(mux, sceneHandler)
+	// Register routes for Users
+	users.RegisterUserRoutes(mux, userHandler)
+	// Register routes for Playback
+	playback.RegisterPlaybackRoutes(mux, playbackHandler)
+	// Register routes for Gifts
+	gifts.RegisterGiftRoutes(mux, giftHandler)
+	// Register routes for Leaderboards
+	leaderboards.RegisterLeaderboardRoutes(mux, leaderboardHandler)
+	// Register routes for Reports
+	reports.RegisterReportRoutes(mux, reportHandler)
+	admin.RegisterJobRoutes(mux, jobHandler)
+	admin.RegisterHubRoutes(mux, hubHandler)
+	webhookapi.RegisterWebhookRoutes(mux, webhookHandler)
+	// Register routes for Workspaces
+	workspaces.RegisterWorkspaceRoutes(mux, workspaceHandler)
+	// Register the GraphQL endpoint
+	graphql.RegisterGraphQLRoutes(mux, graphqlHandler)
+
+	// Routes described by the OpenAPI document; optional routes are added as they are enabled
+	apiRoutes := slices.Concat(dms.Routes, scenes.Routes, users.Routes, playback.Routes, gifts.Routes, leaderboards.Routes, reports.Routes, admin.Routes, webhookapi.Routes, workspaces.Routes, graphql.Routes)
+
+	// Serve avatars stored on local disk
+	if diskAvatars != nil {
+		mux.Handle(avatarFilesPath+"/", http.StripPrefix(avatarFilesPath+"/", diskAvatars.Handler()))
+	}
+	// Register routes for Uploads
+	if uploadService != nil {
+		attachments.RegisterAttachmentRoutes(mux, &attachments.AttachmentHandler{Uploads: uploadService, Store: attachmentStore})
+		apiRoutes = append(apiRoutes, attachments.Routes...)
+	}
+
+	// Spotify integration is optional; it is enabled when the client ID is configured.
+	if clientID := os.Getenv("SPOTIFY_CLIENT_ID"); clientID != "" {
+		tokenKey, err := base64.StdEncoding.DecodeString(os.Getenv("SPOTIFY_TOKEN_KEY"))
+		if err != nil {
+			log.Fatalf("SPOTIFY_TOKEN_KEY must be a base64-encoded 32-byte key: %v", err)
+		}
+
+		spotifyStore := stores.Spotify
+
+		spotifyService, err := spotify.NewService(spotify.Config{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("SPOTIFY_CLIENT_SECRET"),
+			RedirectURI:  os.Getenv("SPOTIFY_REDIRECT_URI"),
+			TokenKey:     tokenKey,
+		}, spotifyStore)
+		if err != nil {
+			log.Fatalf("Failed to initialize Spotify service: %v", err)
+		}
+
+		// Register routes for Integrations
+		integrations.RegisterIntegrationRoutes(mux, &integrations.IntegrationHandler{Spotify: spotifyService, Scenes: sceneStore, Playback: playbackStore})
+		apiRoutes = append(apiRoutes, integrations.Routes...)
+	} else {
+		log.Println("SPOTIFY_CLIENT_ID not set; Spotify integration disabled.")
+	}
+
+	// Register routes for Tickets
+	if paymentService != nil {
+		tickets.RegisterTicketRoutes(mux, &tickets.TicketHandler{Payments: paymentService, Tickets: ticketStore, Scenes: sceneStore, Hub: hub, Links: frontendLinks, Tipping: tipper})
+		apiRoutes = append(apiRoutes, tickets.Routes...)
+	}
+
+	// Serve the OpenAPI document and Swagger UI
+	openapiHandler, err := openapi.NewOpenAPIHandler(openapi.Info{Title: "Scenyx API", Version: "1.0"}, apiRoutes)
+	if err != nil {
+		log.Fatalf("Failed to build the OpenAPI document: %v", err)
+	}
+	openapi.RegisterOpenAPIRoutes(mux, openapiHandler)
 
 	// Optional: catch-all logging for 404s
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -64,11 +507,89 @@ func main() {
 
 	// Apply the CORS middleware to the entire multiplexer
 	// (Assuming middleware.CORS is correctly defined in internal/middleware/cors.go)
-	corsMux := middleware.CORS(mux)
+	corsMux := middleware.CORS(frontendLinks.Origin(), mux)
 
-	log.Printf("Scenyx backend listening on :%s", port)
-	err = http.ListenAndServe(":"+port, corsMux) // Use corsMux here
-	if err != nil {
-		log.Fatalf("Server error: %v", err)
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: corsMux, // Use corsMux here
+	}
+
+	serverErr := make(chan error, 3)
+	go func() {
+		log.Printf("Scenyx backend listening on :%s", port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// With GRPC_PORT set, the read-only gRPC services in proto/scenyx/v1 are
+	// served on that port; GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE enable TLS.
+	var grpcServer *http.Server
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		certFile, keyFile := os.Getenv("GRPC_TLS_CERT_FILE"), os.Getenv("GRPC_TLS_KEY_FILE")
+		grpcServer, err = grpc.NewHTTPServer(":"+grpcPort, &grpc.Server{Scenes: sceneStore, DMs: dmStore, Workspaces: stores.Workspaces, Hub: hub}, certFile != "")
+		if err != nil {
+			log.Fatalf("Failed to initialize gRPC server: %v", err)
+		}
+		go func() {
+			log.Printf("gRPC services listening on :%s", grpcPort)
+			if certFile != "" {
+				serverErr <- grpcServer.ListenAndServeTLS(certFile, keyFile)
+			} else {
+				serverErr <- grpcServer.ListenAndServe()
+			}
+		}()
+	}
+
+	// With DEBUG_ADDR set (e.g. "127.0.0.1:6060"), pprof profiles and expvar
+	// stats are served on that address for operators; see newDebugServer
+	var debugServer *http.Server
+	if debugAddr := os.Getenv("DEBUG_ADDR"); debugAddr != "" {
+		debugServer = newDebugServer(debugAddr, hub, scheduler)
+		go func() {
+			log.Printf("Debug endpoints listening on %s", debugAddr)
+			serverErr <- debugServer.ListenAndServe()
+		}()
 	}
+
+	// --- Graceful Shutdown ---
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start background jobs; they stop when the shutdown signal arrives
+	go scheduler.Run(ctx)
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server error: %v", err)
+		}
+		return
+	case <-ctx.Done():
+		log.Println("Shutdown signal received; draining connections...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Stop accepting new connections and let in-flight HTTP requests finish.
+	// Hijacked WebSocket connections are not tracked by the server, so the hub
+	// drains and closes those itself.
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown error: %v", err)
+	}
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("gRPC server shutdown error: %v", err)
+		}
+	}
+	if debugServer != nil {
+		if err := debugServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Debug server shutdown error: %v", err)
+		}
+	}
+	if err := hub.Shutdown(shutdownCtx); err != nil {
+		log.Printf("WebSocket hub shutdown error: %v", err)
+	}
+
+	// Deferred Close calls release the storage backend and Redis broker on return
+	log.Println("Scenyx backend stopped.")
 }
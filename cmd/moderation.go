@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/moderation"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// moderationAPITimeout bounds a single call to the external moderation API.
+const moderationAPITimeout = 2 * time.Second
+
+// loadModerator builds the message content filter from the environment. It
+// returns nil when no filter is configured.
+//
+//   - MODERATION_WORDS: comma-separated words to match
+//   - MODERATION_WORDS_FILE: file with one word per line
+//   - MODERATION_API_URL, MODERATION_API_KEY: external moderation service
+//   - MODERATION_ACTION: reject (default), redact, or flag
+func loadModerator(flags storage.ModerationStore) (*moderation.Moderator, error) {
+	var filters []moderation.Filter
+
+	if v := os.Getenv("MODERATION_WORDS"); v != "" {
+		filters = append(filters, moderation.NewWordList(strings.Split(v, ",")))
+	}
+	if path := os.Getenv("MODERATION_WORDS_FILE"); path != "" {
+		list, err := moderation.LoadWordList(path)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, list)
+		log.Printf("Loaded %d moderation words from %s.", list.Len(), path)
+	}
+
+	if apiURL := os.Getenv("MODERATION_API_URL"); apiURL != "" {
+		filters = append(filters, &moderation.HTTPFilter{
+			URL:    apiURL,
+			APIKey: os.Getenv("MODERATION_API_KEY"),
+			Client: &http.Client{Timeout: moderationAPITimeout},
+		})
+	}
+
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	action := moderation.ActionReject
+	if v := os.Getenv("MODERATION_ACTION"); v != "" {
+		var err error
+		if action, err = moderation.ParseAction(v); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Content moderation enabled with %d filter(s); action: %s.", len(filters), action)
+	return &moderation.Moderator{Filters: filters, Action: action, Flags: flags}, nil
+}
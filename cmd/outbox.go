@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/outbox"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/storage/postgres"
+)
+
+// kafkaRESTTimeout bounds a single produce request to the Kafka REST Proxy.
+const kafkaRESTTimeout = 10 * time.Second
+
+// loadEventPublisher builds the event outbox publisher from the environment
+// and turns on outbox writes in the stores. It returns nil when no broker is
+// configured, in which case no events are recorded.
+//
+//   - EVENT_BROKER: nats or kafka
+//   - NATS_URL: server URL, default nats://localhost:4222
+//   - KAFKA_REST_URL: Kafka REST Proxy base URL (required for kafka)
+//   - EVENT_SUBJECT_PREFIX: subject/topic prefix, default "scenyx"
+func loadEventPublisher(store storage.OutboxStore) (*outbox.Publisher, error) {
+	var broker outbox.Broker
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("EVENT_BROKER"))); kind {
+	case "":
+		return nil, nil
+	case "nats":
+		natsURL := os.Getenv("NATS_URL")
+		if natsURL == "" {
+			natsURL = "nats://localhost:4222"
+		}
+		nats, err := outbox.NewNATS(natsURL)
+		if err != nil {
+			return nil, err
+		}
+		broker = nats
+	case "kafka":
+		restURL := os.Getenv("KAFKA_REST_URL")
+		if restURL == "" {
+			return nil, fmt.Errorf("KAFKA_REST_URL must be set when EVENT_BROKER is kafka")
+		}
+		broker = &outbox.Kafka{URL: restURL, Client: &http.Client{Timeout: kafkaRESTTimeout}}
+	default:
+		return nil, fmt.Errorf("EVENT_BROKER must be nats or kafka, got %q", kind)
+	}
+
+	postgres.EnableOutbox(true)
+	publisher := &outbox.Publisher{Store: store, Broker: broker, Prefix: os.Getenv("EVENT_SUBJECT_PREFIX")}
+	log.Printf("Publishing domain events to %s.", os.Getenv("EVENT_BROKER"))
+	return publisher, nil
+}
@@ -0,0 +1,301 @@
+// Command scenyxctl runs operational tasks against the Scenyx database:
+//
+//	scenyxctl scenes [-limit N] [-archived]         list scenes, newest first
+//	scenyxctl purge-user -id USER_ID [-yes]         permanently delete a user and their content
+//	scenyxctl migrate [-dir DIR] [-status]          apply pending SQL migrations
+//	scenyxctl stats                                 print instance-wide totals
+//	scenyxctl seed [-users N] [-scenes N]           fill a development database with sample data
+//	scenyxctl cleanup [-dm-days N] [-scene-days N]  apply the data retention policy once
+//
+// It connects to DATABASE_URL; DB_QUERY_TIMEOUT bounds each query as in the server.
+// Commands other than seed accept -json to print machine-readable output.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/retention"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/storage/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// command is a scenyxctl subcommand. run receives the arguments after its name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"scenes":     {"list scenes, newest first", runScenes},
+	"purge-user": {"permanently delete a user and their content", runPurgeUser},
+	"migrate":    {"apply pending SQL migrations", runMigrate},
+	"stats":      {"print instance-wide totals", runStats},
+	"seed":       {"fill a development database with sample data", runSeed},
+	"cleanup":    {"apply the data retention policy once", runCleanup},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "scenyxctl: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "scenyxctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: scenyxctl <command> [flags]\n\ncommands:")
+	for _, name := range []string{"scenes", "purge-user", "migrate", "stats", "seed", "cleanup"} {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun scenyxctl <command> -h for the command's flags.")
+}
+
+// connect opens a small pool on DATABASE_URL.
+func connect() (*pgxpool.Pool, error) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return nil, errors.New("DATABASE_URL environment variable is not set")
+	}
+	if v := os.Getenv("DB_QUERY_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("DB_QUERY_TIMEOUT must be a duration such as 5s: %w", err)
+		}
+		postgres.SetQueryTimeout(timeout)
+	}
+	cfg := postgres.DefaultPoolConfig()
+	cfg.MaxConns, cfg.MinConns = 2, 0
+	return postgres.Open(databaseURL, cfg)
+}
+
+// printJSON writes v as indented JSON to stdout.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func runScenes(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("scenes", flag.ExitOnError)
+	limit := fs.Int("limit", 50, "maximum number of scenes to list")
+	archived := fs.Bool("archived", false, "include archived scenes")
+	asJSON := fs.Bool("json", false, "print JSON")
+	fs.Parse(args)
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	scenes, err := postgres.NewPostgresAdminStore(db).ListScenes(ctx, *limit, *archived)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(scenes)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tARTIST\tCREATOR\tSTATUS\tLISTENERS\tCREATED")
+	for _, s := range scenes {
+		status := string(s.Status)
+		if s.ArchivedAt != nil {
+			status = "archived"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			s.ID, s.Name, s.ArtistName, s.CreatorID, status, s.Listeners, s.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func runPurgeUser(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("purge-user", flag.ExitOnError)
+	userID := fs.String("id", "", "ID of the user to purge (required)")
+	yes := fs.Bool("yes", false, "skip the confirmation prompt")
+	asJSON := fs.Bool("json", false, "print JSON")
+	fs.Parse(args)
+
+	if *userID == "" {
+		return errors.New("-id is required")
+	}
+	if !*yes {
+		fmt.Printf("Permanently delete user %s, their messages, and the scenes they created? Type the user ID to confirm: ", *userID)
+		var answer string
+		fmt.Scanln(&answer)
+		if answer != *userID {
+			return errors.New("aborted")
+		}
+	}
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	affected, err := postgres.NewPostgresAdminStore(db).PurgeUser(ctx, *userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("nothing found for user %s", *userID)
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(affected)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tROWS")
+	for _, t := range affected {
+		fmt.Fprintf(tw, "%s\t%d\n", t.Table, t.Rows)
+	}
+	return tw.Flush()
+}
+
+func runMigrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := fs.String("dir", "migrations", "directory containing the .sql migration files")
+	status := fs.Bool("status", false, "list migrations and whether each is applied, without applying any")
+	asJSON := fs.Bool("json", false, "print JSON")
+	fs.Parse(args)
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *status {
+		migrations, err := postgres.Migrations(ctx, db, *dir)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(migrations)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tAPPLIED")
+		for _, m := range migrations {
+			fmt.Fprintf(tw, "%s\t%t\n", m.Version, m.Applied)
+		}
+		return tw.Flush()
+	}
+
+	applied, err := postgres.Migrate(ctx, db, *dir)
+	if *asJSON {
+		if applied == nil {
+			applied = []string{}
+		}
+		printJSON(applied)
+	} else {
+		for _, version := range applied {
+			fmt.Printf("applied %s\n", version)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+	}
+	return err
+}
+
+func runStats(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	fs.Parse(args)
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := postgres.NewPostgresAdminStore(db).Stats(ctx)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(stats)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, row := range []struct {
+		name  string
+		value int64
+	}{
+		{"users", stats.Users},
+		{"live scenes", stats.LiveScenes},
+		{"scheduled scenes", stats.ScheduledScenes},
+		{"archived scenes", stats.ArchivedScenes},
+		{"conversations", stats.Conversations},
+		{"DM messages", stats.DMMessages},
+		{"scene messages", stats.SceneMessages},
+		{"attachments", stats.Attachments},
+		{"open reports", stats.OpenReports},
+		{"active listeners", stats.ActiveListeners},
+	} {
+		fmt.Fprintf(tw, "%s\t%d\n", row.name, row.value)
+	}
+	return tw.Flush()
+}
+
+func runCleanup(ctx context.Context, args []string) error {
+	policy, err := retention.PolicyFromEnv()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	dmDays := fs.Int("dm-days", int(policy.DMMessageAge.Hours()/24), "delete DM messages older than this many days (0 keeps them; default RETENTION_DM_MESSAGE_DAYS)")
+	sceneDays := fs.Int("scene-days", int(policy.StaleSceneAge.Hours()/24), "delete empty scenes inactive for this many days (0 keeps them; default RETENTION_STALE_SCENE_DAYS)")
+	asJSON := fs.Bool("json", false, "print JSON")
+	fs.Parse(args)
+
+	if *dmDays < 0 || *sceneDays < 0 {
+		return errors.New("-dm-days and -scene-days cannot be negative")
+	}
+	policy = retention.Policy{
+		DMMessageAge:  time.Duration(*dmDays) * 24 * time.Hour,
+		StaleSceneAge: time.Duration(*sceneDays) * 24 * time.Hour,
+	}
+	if !policy.Enabled() {
+		return errors.New("nothing to clean up; set -dm-days or -scene-days")
+	}
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cleaner := &retention.Service{Store: postgres.NewPostgresRetentionStore(db), Policy: policy}
+	result, err := cleaner.Clean(ctx)
+	if *asJSON {
+		printJSON(result)
+	} else {
+		fmt.Printf("deleted %d DM messages and %d stale scenes\n", result.DMMessages, result.StaleScenes)
+	}
+	return err
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/storage/postgres"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// seedEmailDomain marks seeded accounts so reruns reuse them instead of failing.
+const seedEmailDomain = "seed.scenyx.dev"
+
+// Sample data the seed command draws from.
+var (
+	seedFirstNames = []string{
+		"Ava", "Leo", "Maya", "Noah", "Zara", "Kai", "Isla", "Omar", "Priya", "Theo",
+		"Lena", "Ravi", "Nora", "Jonas", "Amara", "Felix", "Sana", "Diego", "Mila", "Hugo",
+	}
+	seedLastNames = []string{
+		"Okafor", "Lindqvist", "Sharma", "Moreau", "Nakamura", "Reyes", "Haddad", "Kowalski",
+		"Bennett", "Adeyemi", "Costa", "Fischer", "Ivanova", "Mensah", "Park", "Quinn",
+	}
+	seedArtists = []string{
+		"Fred again..", "Caribou", "Little Simz", "Khruangbin", "Bicep", "Jorja Smith", "Four Tet",
+		"Kaytranada", "Arlo Parks", "Floating Points", "Tame Impala", "Sault",
+	}
+	seedSceneNames = []string{
+		"Late Night Listening", "Sunday Morning Coffee", "Deep Focus", "Warehouse Warmup", "Rainy Day Rotation",
+		"First Listen Club", "Vinyl Only", "Headphones On", "Road Trip Mix", "After Hours",
+	}
+	seedTags   = []string{"electronic", "house", "hiphop", "indie", "jazz", "soul", "ambient", "chill", "dance", "rnb"}
+	seedTracks = [][2]string{
+		{"Delilah (pull me out of this)", "Fred again.."}, {"Can't Do Without You", "Caribou"},
+		{"Introvert", "Little Simz"}, {"Time (You and I)", "Khruangbin"}, {"Glue", "Bicep"},
+		{"Be Honest", "Jorja Smith"}, {"Baby", "Four Tet"}, {"10%", "Kaytranada"},
+		{"Caroline", "Arlo Parks"}, {"LesAlpx", "Floating Points"}, {"Let It Happen", "Tame Impala"},
+		{"Wildfires", "Sault"},
+	}
+	seedChatLines = []string{
+		"this drop is unreal", "who picked this one? 🔥", "turn it up!!", "first time hearing this, instantly saved",
+		"the bassline 😮‍💨", "perfect for a rainy evening", "can we get some more of this artist next?",
+		"been on repeat all week", "that transition was smooth", "saw them live last summer, incredible",
+		"adding this to my playlist", "ok this one goes hard", "need the remix of this", "vibes are immaculate tonight",
+	}
+	seedDMLines = []string{
+		"hey! are you joining the listening session later?", "yeah should be there around 9",
+		"did you hear the new Caribou album?", "not yet, is it good?", "it's so good, start with the second track",
+		"sending you a scene link in a sec", "haha that last track was chaotic", "let's do a jazz night this weekend",
+		"I'm in, I'll bring the playlist", "can you queue that song from yesterday?", "done, it's up next",
+		"thanks for the rec btw, loved it", "what are you listening to right now?", "on a big house kick lately",
+	}
+)
+
+func runSeed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	users := fs.Int("users", 20, "number of users to create")
+	scenes := fs.Int("scenes", 8, "number of scenes to create")
+	messages := fs.Int("messages", 20, "messages per DM conversation and per scene")
+	password := fs.String("password", "scenyx-dev", "password for every seeded user")
+	seed := fs.Int64("seed", 1, "random seed; the same seed produces the same data")
+	fs.Parse(args)
+
+	if *users < 2 {
+		return errors.New("-users must be at least 2")
+	}
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	s := &seeder{
+		rng:    rand.New(rand.NewSource(*seed)),
+		users:  postgres.NewPostgresUserStore(db),
+		scenes: postgres.NewPostgresSceneStore(db),
+		dms:    postgres.NewPostgresDMStore(db),
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	userIDs, err := s.seedUsers(ctx, *users, string(hash))
+	if err != nil {
+		return err
+	}
+	sceneCount, err := s.seedScenes(ctx, userIDs, *scenes, *messages)
+	if err != nil {
+		return err
+	}
+	dmCount, err := s.seedDMs(ctx, userIDs, *messages)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Seeded %d users, %d scenes, and %d conversations.\n", len(userIDs), sceneCount, dmCount)
+	fmt.Printf("Log in as user1@%s (through user%d@%s) with password %q.\n", seedEmailDomain, len(userIDs), seedEmailDomain, *password)
+	return nil
+}
+
+// seeder holds the stores and random source used by the seed command.
+type seeder struct {
+	rng    *rand.Rand
+	users  storage.UserStore
+	scenes storage.SceneStore
+	dms    storage.DMStore
+}
+
+// pick returns a random element of items.
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.Intn(len(items))]
+}
+
+// others returns up to n distinct user IDs other than exclude.
+func (s *seeder) others(userIDs []string, exclude string, n int) []string {
+	var picked []string
+	for _, i := range s.rng.Perm(len(userIDs)) {
+		if len(picked) == n {
+			break
+		}
+		if userIDs[i] != exclude {
+			picked = append(picked, userIDs[i])
+		}
+	}
+	return picked
+}
+
+// seedUsers creates n users, reusing accounts left by an earlier run.
+func (s *seeder) seedUsers(ctx context.Context, n int, passwordHash string) ([]string, error) {
+	userIDs := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		email := fmt.Sprintf("user%d@%s", i, seedEmailDomain)
+		name := pick(s.rng, seedFirstNames) + " " + pick(s.rng, seedLastNames)
+
+		username := fmt.Sprintf("user%d", i)
+
+		user, err := s.users.CreateUser(ctx, name, username, email, passwordHash)
+		if errors.Is(err, storage.ErrConflict) {
+			user, err = s.users.GetUserByEmail(ctx, email)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("seed user %s: %w", email, err)
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+	return userIDs, nil
+}
+
+// seedScenes creates n scenes with listeners, chat, and a queue.
+func (s *seeder) seedScenes(ctx context.Context, userIDs []string, n, messages int) (int, error) {
+	for i := 0; i < n; i++ {
+		creatorID := pick(s.rng, userIDs)
+		name := seedSceneNames[i%len(seedSceneNames)]
+		tags := []string{pick(s.rng, seedTags), pick(s.rng, seedTags)}
+		if tags[0] == tags[1] {
+			tags = tags[:1]
+		}
+
+		scene, err := s.scenes.CreateScene(ctx, "", name, pick(s.rng, seedArtists), creatorID, tags, nil, false)
+		if err != nil {
+			return i, fmt.Errorf("seed scene %q: %w", name, err)
+		}
+
+		listeners := append([]string{creatorID}, s.others(userIDs, creatorID, 3+s.rng.Intn(8))...)
+		for _, userID := range listeners {
+			if err := s.scenes.JoinScene(ctx, scene.ID, userID); err != nil && !errors.Is(err, storage.ErrConflict) {
+				return i, fmt.Errorf("join user %s to seeded scene %s: %w", userID, scene.ID, err)
+			}
+		}
+
+		for j := 0; j < messages; j++ {
+			if _, err := s.scenes.AddSceneMessage(ctx, scene.ID, pick(s.rng, listeners), pick(s.rng, seedChatLines)); err != nil {
+				return i, fmt.Errorf("seed message in scene %s: %w", scene.ID, err)
+			}
+		}
+
+		for _, j := range s.rng.Perm(len(seedTracks))[:3+s.rng.Intn(4)] {
+			item := &models.QueueItem{
+				SceneID: scene.ID,
+				Title:   seedTracks[j][0],
+				Artist:  seedTracks[j][1],
+				AddedBy: pick(s.rng, listeners),
+			}
+			if _, err := s.scenes.AddToQueue(ctx, item); err != nil {
+				return i, fmt.Errorf("seed queue of scene %s: %w", scene.ID, err)
+			}
+		}
+	}
+	return n, nil
+}
+
+// seedDMs gives every user a few one-to-one conversations plus one group chat.
+func (s *seeder) seedDMs(ctx context.Context, userIDs []string, messages int) (int, error) {
+	seen := make(map[string]bool)
+	var convs []*models.DMConversation
+	for _, userID := range userIDs {
+		for _, peerID := range s.others(userIDs, userID, 2) {
+			conv, err := s.dms.StartOrGetConversation(ctx, "", userID, peerID)
+			if err != nil {
+				return len(convs), fmt.Errorf("seed conversation between %s and %s: %w", userID, peerID, err)
+			}
+			if !seen[conv.ID] {
+				seen[conv.ID] = true
+				convs = append(convs, conv)
+			}
+		}
+	}
+
+	if len(userIDs) >= 3 {
+		creatorID := userIDs[0]
+		group, err := s.dms.CreateGroupConversation(ctx, "", "Weekend listening crew", creatorID, s.others(userIDs, creatorID, 4))
+		if err != nil {
+			return len(convs), fmt.Errorf("seed group conversation: %w", err)
+		}
+		convs = append(convs, group)
+	}
+
+	for _, conv := range convs {
+		participants, err := s.dms.GetParticipants(ctx, conv.ID)
+		if err != nil {
+			return len(convs), fmt.Errorf("get participants of seeded conversation %s: %w", conv.ID, err)
+		}
+		for j := 0; j < messages; j++ {
+			line := seedDMLines[(j+s.rng.Intn(3))%len(seedDMLines)]
+			if _, err := s.dms.AddMessage(ctx, conv.ID, participants[j%len(participants)], line); err != nil {
+				return len(convs), fmt.Errorf("seed message in conversation %s: %w", conv.ID, err)
+			}
+		}
+	}
+	return len(convs), nil
+}
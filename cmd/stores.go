@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/storage/cache"
+	"github.com/Vasu1712/scenyx-backend/internal/storage/postgres"
+)
+
+// storeSet bundles the store implementations the handlers depend on.
+type storeSet struct {
+	Scenes   storage.SceneStore
+	DMs      storage.DMStore
+	Users    storage.UserStore
+	Playback storage.PlaybackStore
+	Spotify  storage.SpotifyTokenStore
+
+	Attachments storage.AttachmentStore
+	Moderation  storage.ModerationStore
+	Analytics   storage.AnalyticsStore
+	Hashtags    storage.HashtagStore
+	Recommend   storage.RecommendationStore
+	Notify      storage.NotificationStore
+	Retention   storage.RetentionStore
+	Webhooks    storage.WebhookStore
+	Outbox      storage.OutboxStore
+	Offline     storage.OfflineStore
+	Transcripts storage.TranscriptStore
+	Orphans     storage.OrphanBlobStore
+	Tickets     storage.TicketStore
+	Gifts       storage.GiftStore
+	Badges      storage.BadgeStore
+	Reputation  storage.ReputationStore
+	Boards      storage.LeaderboardStore
+	Workspaces  storage.WorkspaceStore
+	Leader      storage.Leader // Elects the instance that runs background jobs
+
+	close func() // Releases the backend's connections
+}
+
+// Close releases the resources held by the backend.
+func (s *storeSet) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
+
+// openPostgresStores connects to DATABASE_URL and returns Postgres-backed stores
+// sharing a single connection pool.
+func openPostgresStores() (*storeSet, error) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return nil, fmt.Errorf("DATABASE_URL environment variable is not set. Please provide the PostgreSQL connection string")
+	}
+
+	// DB_QUERY_TIMEOUT (e.g. "3s") bounds every store call; "0" disables the deadline
+	if v := os.Getenv("DB_QUERY_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("DB_QUERY_TIMEOUT must be a duration such as 5s: %w", err)
+		}
+		postgres.SetQueryTimeout(timeout)
+	}
+
+	// One pool is shared by every store; DB_MAX_CONNS, DB_MIN_CONNS,
+	// and DB_CONN_MAX_LIFETIME override the defaults.
+	poolConfig, err := loadPoolConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid database pool configuration: %w", err)
+	}
+	db, err := postgres.Open(databaseURL, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Using PostgreSQL storage backend.")
+	return &storeSet{
+		Scenes:   postgres.NewPostgresSceneStore(db),
+		DMs:      postgres.NewPostgresDMStore(db),
+		Users:    postgres.NewPostgresUserStore(db),
+		Playback: postgres.NewPostgresPlaybackStore(db),
+		Spotify:  postgres.NewPostgresSpotifyTokenStore(db),
+
+		Attachments: postgres.NewPostgresAttachmentStore(db),
+		Moderation:  postgres.NewPostgresModerationStore(db),
+		Analytics:   postgres.NewPostgresAnalyticsStore(db),
+		Hashtags:    postgres.NewPostgresHashtagStore(db),
+		Recommend:   postgres.NewPostgresRecommendationStore(db),
+		Notify:      postgres.NewPostgresNotificationStore(db),
+		Retention:   postgres.NewPostgresRetentionStore(db),
+		Webhooks:    postgres.NewPostgresWebhookStore(db),
+		Outbox:      postgres.NewPostgresOutboxStore(db),
+		Offline:     postgres.NewPostgresOfflineStore(db),
+		Transcripts: postgres.NewPostgresTranscriptStore(db),
+		Orphans:     postgres.NewPostgresOrphanBlobStore(db),
+		Tickets:     postgres.NewPostgresTicketStore(db),
+		Gifts:       postgres.NewPostgresGiftStore(db),
+		Badges:      postgres.NewPostgresBadgeStore(db),
+		Reputation:  postgres.NewPostgresReputationStore(db),
+		Boards:      postgres.NewPostgresLeaderboardStore(db),
+		Workspaces:  postgres.NewPostgresWorkspaceStore(db),
+		Leader:      postgres.NewAdvisoryLeader(db, "scenyx-jobs"),
+		close:       db.Close,
+	}, nil
+}
+
+// defaultStoreCacheTTL is how long cached reads are served when
+// STORE_CACHE_SIZE is set without STORE_CACHE_TTL.
+const defaultStoreCacheTTL = 30 * time.Second
+
+// cacheStores puts an in-process LRU cache of STORE_CACHE_SIZE entries in
+// front of scene and playback reads, kept for at most STORE_CACHE_TTL. The
+// cache is off unless STORE_CACHE_SIZE is set. Only this instance's writes
+// invalidate it, so it is meant for single-instance deployments.
+func cacheStores(s *storeSet) error {
+	v := os.Getenv("STORE_CACHE_SIZE")
+	if v == "" {
+		return nil
+	}
+	size, err := strconv.Atoi(v)
+	if err != nil || size < 0 {
+		return fmt.Errorf("STORE_CACHE_SIZE must be a non-negative integer, got %q", v)
+	}
+	if size == 0 {
+		return nil
+	}
+	ttl := defaultStoreCacheTTL
+	if v := os.Getenv("STORE_CACHE_TTL"); v != "" {
+		if ttl, err = time.ParseDuration(v); err != nil || ttl <= 0 {
+			return fmt.Errorf("STORE_CACHE_TTL must be a positive duration, got %q", v)
+		}
+	}
+
+	s.Scenes = cache.NewSceneStore(s.Scenes, size, ttl)
+	s.Playback = cache.NewPlaybackStore(s.Playback, size, ttl)
+	log.Printf("Caching up to %d scenes and playback states for %s.", size, ttl)
+	return nil
+}
+
+// loadPoolConfig reads database pool overrides from the environment.
+func loadPoolConfig() (postgres.PoolConfig, error) {
+	cfg := postgres.DefaultPoolConfig()
+	if v := os.Getenv("DB_MAX_CONNS"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("DB_MAX_CONNS must be a positive integer, got %q", v)
+		}
+		cfg.MaxConns = int32(n)
+	}
+	if v := os.Getenv("DB_MIN_CONNS"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("DB_MIN_CONNS must be a non-negative integer, got %q", v)
+		}
+		cfg.MinConns = int32(n)
+	}
+	if cfg.MinConns > cfg.MaxConns {
+		return cfg, fmt.Errorf("DB_MIN_CONNS (%d) cannot exceed DB_MAX_CONNS (%d)", cfg.MinConns, cfg.MaxConns)
+	}
+	if v := os.Getenv("DB_CONN_MAX_LIFETIME"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("DB_CONN_MAX_LIFETIME must be a duration such as 5m: %w", err)
+		}
+		cfg.MaxConnLifetime = d
+	}
+	return cfg, nil
+}
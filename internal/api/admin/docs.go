@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/app/jobs"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// Routes describes the routes registered by RegisterJobRoutes and
+// RegisterHubRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/jobs", ID: "listJobs", Tag: "Admin",
+		Summary:     "List the background jobs' schedules and run stats",
+		Description: "Requires an admin's token from login or /api/v1/users/ws-token in an \"Authorization: Bearer\" header.",
+		Response: struct {
+			Leader bool         `json:"leader"`
+			Jobs   []jobs.Stats `json:"jobs"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/hub", ID: "getHub", Tag: "Admin",
+		Summary:     "Dump the WebSocket hub's stats and the connections of each DM and scene on the serving instance",
+		Description: "Requires an admin's token from login or /api/v1/users/ws-token in an \"Authorization: Bearer\" header.",
+		Query: []openapi.Param{
+			{Name: "dm_id", Description: "List only this DM"},
+			{Name: "scene_id", Description: "List only this scene"},
+		},
+		Response: ws.HubState{},
+	},
+}
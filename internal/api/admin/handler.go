@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/jobs"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// JobHandler exposes background job status to admins.
+type JobHandler struct {
+	Scheduler *jobs.Scheduler // The server's background job scheduler
+	Tokens    *ws.TokenSigner // Verifies the token identifying the admin
+	Admins    map[string]bool // User IDs allowed to view job status
+}
+
+// ListJobs handles the HTTP GET request for the background jobs' schedules and run stats.
+// The admin is identified by the token from login or /api/v1/users/ws-token,
+// passed as "Authorization: Bearer <token>".
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	adminID, err := h.Tokens.AuthenticateBearer(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected job status request: %v", err)
+		return
+	}
+	if !h.Admins[adminID] {
+		http.Error(w, "Only admins can view jobs", http.StatusForbidden)
+		log.Printf("Non-admin %q attempted to view jobs", adminID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"leader": h.Scheduler.IsLeader(),
+		"jobs":   h.Scheduler.Stats(),
+	})
+}
+
+// HubHandler exposes the WebSocket hub's state to admins, for debugging
+// rooms whose events stop arriving.
+type HubHandler struct {
+	Hub    *ws.Hub         // The server's WebSocket hub
+	Tokens *ws.TokenSigner // Verifies the token identifying the admin
+	Admins map[string]bool // User IDs allowed to view hub state
+}
+
+// GetHub handles the HTTP GET request for the hub's stats and the state of
+// every DM and scene with connections on this instance. The admin is
+// identified by the token from login or /api/v1/users/ws-token, passed as
+// "Authorization: Bearer <token>"; "dm_id" or "scene_id" narrow the listed
+// rooms to one.
+func (h *HubHandler) GetHub(w http.ResponseWriter, r *http.Request) {
+	adminID, err := h.Tokens.AuthenticateBearer(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected hub state request: %v", err)
+		return
+	}
+	if !h.Admins[adminID] {
+		http.Error(w, "Only admins can view hub state", http.StatusForbidden)
+		log.Printf("Non-admin %q attempted to view hub state", adminID)
+		return
+	}
+
+	state := h.Hub.Snapshot(r.URL.Query().Get("dm_id"), r.URL.Query().Get("scene_id"))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(state)
+}
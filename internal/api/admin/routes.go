@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterJobRoutes registers the background job status route with the provided ServeMux.
+func RegisterJobRoutes(mux *http.ServeMux, handler *JobHandler) {
+	mux.HandleFunc("/api/v1/admin/jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Admin] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Admin] %s %s", r.Method, r.URL.Path)
+		handler.ListJobs(w, r)
+	})
+}
+
+// RegisterHubRoutes registers the WebSocket hub state route with the provided ServeMux.
+func RegisterHubRoutes(mux *http.ServeMux, handler *HubHandler) {
+	mux.HandleFunc("/api/v1/admin/hub", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Admin] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Admin] %s %s", r.Method, r.URL.Path)
+		handler.GetHub(w, r)
+	})
+}
@@ -0,0 +1,39 @@
+package attachments
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// Routes describes the routes registered by RegisterAttachmentRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/uploads/presign", ID: "presignUpload", Tag: "Uploads",
+		Summary:     "Get an attachment upload URL",
+		Description: "The client uploads the file to the returned URL with the returned headers, then sends the attachment ID with its message.",
+		Body: struct {
+			UserID      string `json:"userID"`
+			Filename    string `json:"filename"`
+			ContentType string `json:"contentType"`
+			Size        int64  `json:"size"`
+		}{},
+		Status: http.StatusCreated,
+		Response: struct {
+			Attachment *models.Attachment `json:"attachment"`
+			UploadURL  string             `json:"uploadURL"`
+			Method     string             `json:"method"`
+			Headers    map[string]string  `json:"headers"`
+			ExpiresAt  time.Time          `json:"expiresAt"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/uploads/file", ID: "getAttachmentFile", Tag: "Uploads",
+		Summary:     "Redirect to an attachment's contents",
+		Description: "Redirects to the file in object storage.",
+		Query:       []openapi.Param{{Name: "id", Required: true}},
+		Status:      http.StatusFound,
+	},
+}
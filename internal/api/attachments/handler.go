@@ -0,0 +1,109 @@
+package attachments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/uploads"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// AttachmentHandler holds the dependencies for uploading and serving message attachments.
+type AttachmentHandler struct {
+	Uploads *uploads.Service        // Signs object storage URLs
+	Store   storage.AttachmentStore // Attachment metadata
+}
+
+// Presign handles the HTTP POST request for an attachment upload URL.
+// It expects a JSON payload with "userID", "filename", "contentType", and "size".
+// The client PUTs the file to the returned URL with the returned headers, then
+// sends the attachment ID with its message.
+func (h *AttachmentHandler) Presign(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID      string `json:"userID"`
+		Filename    string `json:"filename"`
+		ContentType string `json:"contentType"`
+		Size        int64  `json:"size"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for Presign: %v", err)
+		return
+	}
+
+	if req.UserID == "" || req.Filename == "" || req.ContentType == "" {
+		http.Error(w, "User ID, Filename, and Content Type cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: User ID, Filename, or Content Type is empty for Presign")
+		return
+	}
+
+	upload, err := h.Uploads.PresignAttachment("attachments", req.Filename, req.ContentType, req.Size)
+	if errors.Is(err, uploads.ErrUnsupportedType) {
+		http.Error(w, "Unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	if errors.Is(err, uploads.ErrTooLarge) {
+		http.Error(w, fmt.Sprintf("Size must be between 1 and %d bytes", uploads.MaxAttachmentSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error presigning upload for %s: %v", req.UserID, err)
+		return
+	}
+
+	att, err := h.Store.CreateAttachment(r.Context(), &models.Attachment{
+		Key:         upload.Key,
+		UploaderID:  req.UserID,
+		Name:        req.Filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+	})
+	if err != nil {
+		http.Error(w, "Failed to create attachment", http.StatusInternalServerError)
+		log.Printf("Error creating attachment for %s: %v", req.UserID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		Attachment *models.Attachment `json:"attachment"`
+		UploadURL  string             `json:"uploadURL"`
+		Method     string             `json:"method"`
+		Headers    map[string]string  `json:"headers"`
+		ExpiresAt  time.Time          `json:"expiresAt"`
+	}{att, upload.URL, http.MethodPut, upload.Headers, upload.ExpiresAt})
+	log.Printf("Issued upload URL for attachment %s (%s, %d bytes) to %s", att.ID, att.ContentType, att.Size, req.UserID)
+}
+
+// File handles the HTTP GET request for an attachment's contents by
+// redirecting to object storage. It expects an "id" query parameter.
+func (h *AttachmentHandler) File(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Attachment ID is required as a query parameter", http.StatusBadRequest)
+		log.Println("Validation error: Attachment ID is empty for File")
+		return
+	}
+
+	att, err := h.Store.GetAttachment(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting attachment %s: %v", id, err)
+		return
+	}
+
+	http.Redirect(w, r, h.Uploads.URL(att.Key), http.StatusFound)
+}
@@ -0,0 +1,29 @@
+package attachments
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterAttachmentRoutes registers the upload HTTP routes with the provided ServeMux.
+func RegisterAttachmentRoutes(mux *http.ServeMux, handler *AttachmentHandler) {
+	mux.HandleFunc("/api/v1/uploads/presign", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Upload] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Upload] %s %s", r.Method, r.URL.Path)
+		handler.Presign(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/uploads/file", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Upload] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Upload] %s %s", r.Method, r.URL.Path)
+		handler.File(w, r)
+	})
+}
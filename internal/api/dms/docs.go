@@ -0,0 +1,398 @@
+package dms
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// membersResponse is the reply of the group membership endpoints.
+type membersResponse struct {
+	Message string   `json:"message"`
+	Members []string `json:"members"`
+}
+
+// archiveResponse is the reply of Archive and Unarchive.
+type archiveResponse struct {
+	DMID     string `json:"dm_id"`
+	Archived bool   `json:"archived"`
+}
+
+// muteResponse is the reply of Mute and Unmute.
+type muteResponse struct {
+	DMID  string `json:"dm_id"`
+	Muted bool   `json:"muted"`
+}
+
+// callerAuth describes how the DM read routes identify the caller.
+const callerAuth = "The caller is identified by their token from login or /api/v1/users/ws-token in an " +
+	"\"Authorization: Bearer\" header."
+
+// readerAuth describes who may read a conversation on the DM read routes.
+const readerAuth = callerAuth + " They must be a participant and, in a workspace's conversation, " +
+	"still a member of the workspace (404 otherwise)."
+
+// userParam is the optional user_id the caller's bearer token must match.
+var userParam = openapi.Param{Name: "user_id", Description: "Must match the bearer token if given"}
+
+// Routes describes the routes registered by RegisterDMRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/start", ID: "startConversation", Tag: "DMs",
+		Summary:     "Start a one-to-one conversation, or get the existing one",
+		Description: "With workspace_id set, the conversation is private to that workspace and both users must be members.",
+		Body: struct {
+			User1       string `json:"user1"`
+			User2       string `json:"user2"`
+			WorkspaceID string `json:"workspace_id,omitempty"`
+		}{},
+		Response: models.DMConversation{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/list", ID: "listConversations", Tag: "DMs",
+		Summary: "List a page of the caller's conversations, most recently active first",
+		Description: callerAuth + " next_cursor is omitted once a page comes back short. Conversations the user archived " +
+			"are left out unless archived=true, which lists only those. Conversations of workspaces the user has left are not listed.",
+		Query: []openapi.Param{
+			userParam,
+			{Name: "limit", Type: "integer", Description: "Default 50, at most 200"},
+			{Name: "cursor", Description: "The next_cursor of the previous page"},
+			{Name: "archived", Type: "boolean", Description: "List archived conversations instead"},
+			{Name: "workspace_id", Description: "List this workspace's conversations instead of those outside any"},
+		},
+		Response: struct {
+			Conversations []models.DMConversation `json:"conversations"`
+			NextCursor    string                  `json:"next_cursor,omitempty"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/{id}/messages", ID: "getDMMessages", Tag: "DMs",
+		Summary:     "Fetch a page of a conversation's history in chronological order",
+		Description: readerAuth + " The page's messages from others are marked delivered to the caller.",
+		Query: []openapi.Param{
+			{Name: "limit", Type: "integer"},
+			{Name: "before", Description: "Message ID; only one of before and after may be set"},
+			{Name: "after", Description: "Message ID; only one of before and after may be set"},
+			userParam,
+		},
+		Response: []models.DMMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/{id}/messages", ID: "postDMMessage", Tag: "DMs",
+		Summary:     "Post a message to a conversation",
+		Description: "Takes the same body as POST /api/v1/dms/send without dm_id, and behaves the same way.",
+		Body: struct {
+			SenderID        string     `json:"sender_id"`
+			Content         string     `json:"content"`
+			ParentMessageID string     `json:"parent_message_id,omitempty"`
+			AttachmentIDs   []string   `json:"attachment_ids,omitempty"`
+			SendAt          *time.Time `json:"send_at,omitempty"`
+			KeyVersion      *int       `json:"key_version,omitempty"`
+		}{},
+		Response: models.DMMessage{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/messages", ID: "listDMMessages", Tag: "DMs",
+		Summary:     "Fetch a page of a conversation's history in chronological order",
+		Description: "Superseded by GET /api/v1/dms/{id}/messages. " + readerAuth,
+		Deprecated:  true,
+		Query: []openapi.Param{
+			{Name: "dm_id", Required: true},
+			{Name: "limit", Type: "integer"},
+			{Name: "before", Description: "Message ID; only one of before and after may be set"},
+			{Name: "after", Description: "Message ID; only one of before and after may be set"},
+			userParam,
+		},
+		Response: []models.DMMessage{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/search", ID: "searchDMMessages", Tag: "DMs",
+		Summary:     "Search the caller's conversations",
+		Description: callerAuth + " Matches are returned newest first with a few messages of context around each.",
+		Query: []openapi.Param{
+			userParam,
+			{Name: "q", Required: true},
+			{Name: "dm_id", Description: "Search only this conversation"},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: []models.DMSearchResult{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/send", ID: "sendDMMessage", Tag: "DMs",
+		Summary:    "Post a message to a conversation",
+		Deprecated: true,
+		Description: "Superseded by POST /api/v1/dms/{id}/messages. " +
+			"With parent_message_id set, the message is a reply in that message's thread. @username mentions of conversation participants are resolved into the message's mentions, and each mentioned user is sent a mention event. " +
+			"With send_at set (at most a year ahead), the message is held instead and the reply is 202 Accepted with the scheduled message; " +
+			"it is sent and broadcast once send_at passes. " +
+			"With key_version set, content is ciphertext sealed with that version of the conversation key: the server stores and relays it " +
+			"without filtering it or resolving mentions, and it cannot be scheduled.",
+		Body: struct {
+			DMID            string     `json:"dm_id"`
+			SenderID        string     `json:"sender_id"`
+			Content         string     `json:"content"`
+			ParentMessageID string     `json:"parent_message_id,omitempty"`
+			AttachmentIDs   []string   `json:"attachment_ids,omitempty"`
+			SendAt          *time.Time `json:"send_at,omitempty"`
+			KeyVersion      *int       `json:"key_version,omitempty"`
+		}{},
+		Response: models.DMMessage{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/export", ID: "exportDMMessages", Tag: "DMs",
+		Summary: "Stream a conversation's full history as newline-delimited JSON",
+		Description: "Served as application/x-ndjson, one message per line, oldest first, thread replies and deleted tombstones " +
+			"included. " + callerAuth + " They must be a participant and, in a workspace's conversation, still a " +
+			"member of the workspace, or an admin for a compliance export of any conversation. " +
+			"An error mid-stream ends the response early.",
+		Query: []openapi.Param{
+			{Name: "dm_id", Required: true},
+			userParam,
+		},
+		Response: models.DMMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/keys", ID: "addDMConversationKeys", Tag: "DMs",
+		Summary: "Distribute a new version of a conversation's end-to-end encryption key",
+		Description: "The sender wraps the key with each participant device's public key; the server never sees it unwrapped. " +
+			"Every recipient must be a participant (403). Versions are never overwritten (409). " +
+			"A dm.key event tells the conversation a new version is available.",
+		Body: struct {
+			DMID       string `json:"dm_id"`
+			SenderID   string `json:"sender_id"`
+			KeyVersion int    `json:"key_version"`
+			Keys       []struct {
+				UserID     string `json:"user_id"`
+				DeviceID   string `json:"device_id"`
+				WrappedKey string `json:"wrapped_key"`
+			} `json:"keys"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: []models.DMConversationKey{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/keys", ID: "getDMConversationKeys", Tag: "DMs",
+		Summary:     "Fetch a conversation's keys wrapped for one of the caller's devices, newest version first",
+		Description: readerAuth,
+		Query: []openapi.Param{
+			{Name: "dm_id", Required: true},
+			userParam,
+			{Name: "device_id", Required: true},
+		},
+		Response: []models.DMConversationKey{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/draft", ID: "getDMDraft", Tag: "DMs",
+		Summary:  "Fetch the message the user has started in a conversation",
+		Query:    []openapi.Param{{Name: "dm_id", Required: true}, {Name: "user_id", Required: true}},
+		Response: models.DMDraft{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/dms/draft", ID: "saveDMDraft", Tag: "DMs",
+		Summary: "Save the message the user has started in a conversation",
+		Description: "Empty content clears the draft; clients should clear it once the message is sent. " +
+			"The user's connections are sent a dm.draft event so the draft follows them between devices.",
+		Body: struct {
+			DMID    string `json:"dm_id"`
+			UserID  string `json:"user_id"`
+			Content string `json:"content"`
+		}{},
+		Response: models.DMDraft{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/scheduled", ID: "listScheduledDMMessages", Tag: "DMs",
+		Summary: "List the messages the user scheduled and has not sent yet, soonest first",
+		Query: []openapi.Param{
+			{Name: "user_id", Required: true},
+			{Name: "dm_id", Description: "Only list messages scheduled in this conversation"},
+		},
+		Response: []models.ScheduledDMMessage{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/dms/scheduled", ID: "cancelScheduledDMMessage", Tag: "DMs",
+		Summary: "Cancel a scheduled message before it is sent",
+		Query: []openapi.Param{
+			{Name: "id", Required: true},
+			{Name: "user_id", Required: true, Description: "The sender"},
+		},
+		Status: http.StatusNoContent,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/thread", ID: "getDMThread", Tag: "DMs",
+		Summary:     "Fetch a message's thread",
+		Description: readerAuth,
+		Query:       []openapi.Param{{Name: "message_id", Required: true, Description: "The top-level message or any reply"}, userParam},
+		Response:    models.DMThread{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/edit", ID: "editDMMessage", Tag: "DMs",
+		Summary: "Replace the content of a message",
+		Description: "Mentions are resolved again from the new content; only users the edit newly mentions are notified. " +
+			"With key_version set, content is ciphertext and has no mentions.",
+		Body: struct {
+			MessageID  string `json:"message_id"`
+			SenderID   string `json:"sender_id"`
+			Content    string `json:"content"`
+			KeyVersion *int   `json:"key_version,omitempty"`
+		}{},
+		Response: models.DMMessage{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/dms/delete", ID: "deleteDMMessage", Tag: "DMs",
+		Summary: "Delete the content of a message, leaving a tombstone",
+		Query: []openapi.Param{
+			{Name: "message_id", Required: true},
+			{Name: "sender_id", Required: true},
+		},
+		Response: models.DMMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/reactions/add", ID: "addDMReaction", Tag: "DMs",
+		Summary:  "Add the user's emoji reaction to a message",
+		Body:     reactionRequest{},
+		Response: models.DMMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/reactions/remove", ID: "removeDMReaction", Tag: "DMs",
+		Summary:  "Remove the user's emoji reaction from a message",
+		Body:     reactionRequest{},
+		Response: models.DMMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/read", ID: "markDMRead", Tag: "DMs",
+		Summary:     "Reset the user's unread count for a conversation",
+		Description: "Also marks the messages from others the user had not read as read, broadcasting a message.receipt event.",
+		Body: struct {
+			DMID   string `json:"dm_id"`
+			UserID string `json:"user_id"`
+		}{},
+		Response: struct {
+			Message string `json:"message"`
+		}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/archive", ID: "archiveDM", Tag: "DMs",
+		Summary:     "Hide a conversation from the user's default list",
+		Description: "Only affects the requesting user. A new message in the conversation unarchives it.",
+		Body: struct {
+			DMID   string `json:"dm_id"`
+			UserID string `json:"user_id"`
+		}{},
+		Response: archiveResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/unarchive", ID: "unarchiveDM", Tag: "DMs",
+		Summary: "Return an archived conversation to the user's default list",
+		Body: struct {
+			DMID   string `json:"dm_id"`
+			UserID string `json:"user_id"`
+		}{},
+		Response: archiveResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/mute", ID: "muteDM", Tag: "DMs",
+		Summary: "Mute a conversation for the user",
+		Description: "Messages are still delivered, but raise no notifications and do not add to the user's " +
+			"unread count. Only affects the requesting user.",
+		Body: struct {
+			DMID   string `json:"dm_id"`
+			UserID string `json:"user_id"`
+		}{},
+		Response: muteResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/unmute", ID: "unmuteDM", Tag: "DMs",
+		Summary: "Unmute a conversation for the user",
+		Body: struct {
+			DMID   string `json:"dm_id"`
+			UserID string `json:"user_id"`
+		}{},
+		Response: muteResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/ttl", ID: "setDMMessageTTL", Tag: "DMs",
+		Summary: "Turn disappearing messages on or off for a conversation",
+		Description: "Messages sent afterwards are deleted ttl_seconds after they were sent, and a message.expired " +
+			"event is broadcast so clients remove them. 0 turns it off; otherwise it must be between 60 seconds " +
+			"and 90 days. Messages already sent keep their expiry. Any participant may change it.",
+		Body: struct {
+			DMID       string `json:"dm_id"`
+			UserID     string `json:"user_id"`
+			TTLSeconds int    `json:"ttl_seconds"`
+		}{},
+		Response: models.DMMessageTTLEvent{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/unread-total", ID: "getDMUnreadTotal", Tag: "DMs",
+		Summary: "Count the user's unread messages across all conversations",
+		Query:   []openapi.Param{{Name: "user_id", Required: true}},
+		Response: struct {
+			UnreadTotal int `json:"unread_total"`
+		}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/groups/create", ID: "createDMGroup", Tag: "DMs",
+		Summary:     "Create a named group conversation",
+		Description: "With workspace_id set, the conversation is private to that workspace and every member must be a member of it.",
+		Body: struct {
+			Name        string   `json:"name"`
+			CreatorID   string   `json:"creator_id"`
+			Members     []string `json:"members"`
+			WorkspaceID string   `json:"workspace_id,omitempty"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.DMConversation{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/dms/members", ID: "listDMMembers", Tag: "DMs",
+		Summary:     "List the participants of a conversation",
+		Description: readerAuth,
+		Query:       []openapi.Param{{Name: "dm_id", Required: true}, userParam},
+		Response:    []string{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/members/add", ID: "addDMMember", Tag: "DMs",
+		Summary: "Add a user to a group conversation",
+		Description: "The caller is identified by their token from login or /api/v1/users/ws-token in an " +
+			"\"Authorization: Bearer\" header and must be a participant (404 otherwise). In a workspace's " +
+			"conversation, the user added must be a member of the workspace.",
+		Body: struct {
+			DMID   string `json:"dm_id"`
+			UserID string `json:"user_id"`
+		}{},
+		Response: membersResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/dms/members/remove", ID: "removeDMMember", Tag: "DMs",
+		Summary: "Remove a user from a group conversation",
+		Description: "The caller is identified by their token in an \"Authorization: Bearer\" header and must be a " +
+			"participant. Anyone may leave; only the group's creator may remove others (403). The removed user's " +
+			"connections to the conversation are closed.",
+		Body: struct {
+			DMID   string `json:"dm_id"`
+			UserID string `json:"user_id"`
+		}{},
+		Response: membersResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/ws/dms", ID: "connectDMSocket", Tag: "DMs",
+		Summary: "Open the conversation's WebSocket",
+		Description: "Upgrades to a WebSocket that carries the conversation's live events. Only participants, " +
+			"who in a workspace's conversation must still be members of the workspace, may connect. The wsToken may instead follow scenyx.token in Sec-WebSocket-Protocol. Offering " +
+			"scenyx.msgpack there switches frames in both directions to MessagePack. Connections over " +
+			"the per-user or per-address cap are closed with code 4029. Message events sent while the user had no " +
+			"connection to the conversation are delivered after connecting; they carry no seq and may repeat " +
+			"events already seen, so clients dedupe by id. Clients acknowledge messages they receive with a " +
+			"message.delivered frame whose payload is {\"message_ids\": [...]}; it is recorded as a delivery " +
+			"receipt and announced as a message.receipt event instead of being relayed.",
+		Query: []openapi.Param{
+			{Name: "dm_id", Required: true},
+			{Name: "token", Description: "wsToken from login or /api/v1/users/ws-token"},
+			{Name: "user_id", Description: "Must match the token's user if given"},
+			{Name: "last_event_id", Description: "ID of the last event received; newer events are replayed, or a resync event is sent"},
+		},
+		Status: http.StatusSwitchingProtocols,
+	},
+}
@@ -0,0 +1,75 @@
+package dms
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxDraftBody caps the request body of SaveDraft.
+const maxDraftBody = 64 << 10
+
+// GetDraft returns the message the user has started in a conversation, with
+// empty content if there is none. Query params: dm_id and user_id.
+func (h *DMHandler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	dmID := r.URL.Query().Get("dm_id")
+	userID := r.URL.Query().Get("user_id")
+	if dmID == "" || userID == "" {
+		http.Error(w, "DM ID and User ID are required as query parameters", http.StatusBadRequest)
+		return
+	}
+	draft, err := h.Store.GetDraft(r.Context(), dmID, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a participant in this conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get draft", http.StatusInternalServerError)
+		log.Printf("Error getting draft of %s in DM %s: %v", userID, dmID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
+
+// SaveDraft replaces the user's draft in a conversation; empty content
+// clears it, as clients should once the message is sent. The user's other
+// connections are sent the draft so it follows them between devices.
+func (h *DMHandler) SaveDraft(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxDraftBody)
+	var req struct {
+		DMID    string `json:"dm_id"`
+		UserID  string `json:"user_id"`
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "Draft is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DMID == "" || req.UserID == "" {
+		http.Error(w, "DM ID and User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	draft, err := h.Store.SaveDraft(r.Context(), req.DMID, req.UserID, req.Content)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a participant in this conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to save draft", http.StatusInternalServerError)
+		log.Printf("Error saving draft of %s in DM %s: %v", req.UserID, req.DMID, err)
+		return
+	}
+	h.Hub.SendToUser(req.UserID, ws.TypeDraft, draft)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
@@ -0,0 +1,94 @@
+package dms
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// exportPageSize is the number of messages read, written, and flushed at a
+// time by ExportMessages.
+const exportPageSize = 500
+
+// ExportMessages streams a conversation's full history, thread replies and
+// deleted tombstones included, as newline-delimited JSON: one message per
+// line, oldest first. The caller is identified by their bearer token, see
+// authenticate, and must be able to read the conversation, see
+// memberConversation, or be an admin, for compliance exports of any
+// conversation. Query params: dm_id and optionally user_id, which must match
+// the token.
+//
+// Messages are read a page at a time and flushed after each page, so a slow
+// client holds back the next read rather than the server buffering the
+// history. Errors after the first line can only cut the stream short.
+func (h *DMHandler) ExportMessages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	dmID := q.Get("dm_id")
+	if dmID == "" {
+		http.Error(w, "DM ID is required as a query parameter", http.StatusBadRequest)
+		return
+	}
+	conv, err := h.Store.GetConversation(r.Context(), dmID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Conversation not found", http.StatusNotFound)
+		return
+	}
+	visible := false
+	if err == nil {
+		visible, err = storage.ConversationVisible(r.Context(), h.Workspaces, conv, userID)
+	}
+	if err != nil {
+		http.Error(w, "Failed to export conversation", http.StatusInternalServerError)
+		log.Printf("Error loading DM %s for export: %v", dmID, err)
+		return
+	}
+	compliance := !visible
+	if compliance && !h.Admins[userID] {
+		http.Error(w, "User is not a participant in this conversation", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="dm-`+dmID+`.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	after := ""
+	total := 0
+	for {
+		msgs, err := h.Store.GetHistory(r.Context(), dmID, after, exportPageSize)
+		if err != nil {
+			log.Printf("Error reading DM %s for export after %d messages: %v", dmID, total, err)
+			return
+		}
+		for _, msg := range msgs {
+			if err := enc.Encode(msg); err != nil {
+				log.Printf("Export of DM %s stopped after %d messages: %v", dmID, total, err)
+				return
+			}
+			total++
+		}
+		// Writers that cannot flush still stream once their buffer fills
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Export of DM %s stopped after %d messages: %v", dmID, total, err)
+			return
+		}
+		if len(msgs) < exportPageSize {
+			break
+		}
+		after = msgs[len(msgs)-1].ID
+	}
+	if compliance {
+		log.Printf("Exported %d messages of DM %s for admin %s (compliance)", total, dmID, userID)
+		return
+	}
+	log.Printf("Exported %d messages of DM %s for %s", total, dmID, userID)
+}
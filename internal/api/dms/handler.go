@@ -1,93 +1,1079 @@
 package dms
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/Vasu1712/scenyx-backend/internal/storage/postgres"
+	"github.com/Vasu1712/scenyx-backend/internal/app/mentions"
+	"github.com/Vasu1712/scenyx-backend/internal/app/moderation"
+	"github.com/Vasu1712/scenyx-backend/internal/app/notify"
+	"github.com/Vasu1712/scenyx-backend/internal/app/webhooks"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
 	"github.com/Vasu1712/scenyx-backend/internal/ws"
 	"github.com/gorilla/websocket"
 )
 
 type DMHandler struct {
-	Store *postgres.PostgresDMStore
-	Hub   *ws.Hub
+	Store       storage.DMStore
+	Attachments storage.AttachmentStore // nil when uploads are disabled
+	Moderator   *moderation.Moderator   // nil when content filtering is disabled
+	Webhooks    *webhooks.Dispatcher    // nil when webhooks are disabled
+	Hub         *ws.Hub
+	Notify      *notify.Dispatcher     // Sends new message and mention notifications, honoring user preferences
+	Tokens      *ws.TokenSigner        // Verifies the tokens that authenticate WebSocket upgrades
+	Workspaces  storage.WorkspaceStore // Checks that participants of workspace conversations are still members
+	Admins      map[string]bool        // User IDs allowed to export any conversation for compliance requests
+}
+
+// authenticate returns the user identified by the token from login or
+// /api/v1/users/ws-token in the request's "Authorization: Bearer" header.
+// claimed, the user ID named in the request, may be empty but otherwise must
+// match. It returns false if a response has already been written.
+func (h *DMHandler) authenticate(w http.ResponseWriter, r *http.Request, claimed string) (string, bool) {
+	userID, err := h.Tokens.AuthenticateBearer(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected %s %s: %v", r.Method, r.URL.Path, err)
+		return "", false
+	}
+	if claimed != "" && claimed != userID {
+		http.Error(w, "Token was issued to another user", http.StatusForbidden)
+		log.Printf("User %s attempted %s %s as %s", userID, r.Method, r.URL.Path, claimed)
+		return "", false
+	}
+	return userID, true
 }
 
 func (h *DMHandler) StartOrGetConversation(w http.ResponseWriter, r *http.Request) {
 	// Assume user IDs are in POST body or JWT
 	var req struct {
-		User1 string `json:"user1"`
-		User2 string `json:"user2"`
+		User1       string `json:"user1"`
+		User2       string `json:"user2"`
+		WorkspaceID string `json:"workspace_id"` // Optional; both users must be members
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	conv, err := h.Store.StartOrGetConversation(r.Context(), req.WorkspaceID, req.User1, req.User2)
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "Both users must be members of the workspace", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to start conversation", http.StatusInternalServerError)
+		log.Printf("Error starting DM between %s and %s: %v", req.User1, req.User2, err)
+		return
 	}
-	json.NewDecoder(r.Body).Decode(&req)
-	conv := h.Store.StartOrGetConversation(req.User1, req.User2)
 	json.NewEncoder(w).Encode(conv)
 }
 
+// ListConversations returns a page of the caller's conversations, most recently active first.
+// The caller is identified by their bearer token, see authenticate.
+// Query params: optional user_id, which must match the token, optional limit, optional cursor
+// (the next_cursor of the previous page), optional archived (true lists only archived
+// conversations, which are otherwise left out), and optional workspace_id (lists that
+// workspace's conversations instead of those outside any).
 func (h *DMHandler) ListConversations(w http.ResponseWriter, r *http.Request) {
-	// Assume userID from JWT or query param
-	userID := r.URL.Query().Get("user_id")
-	convs := h.Store.GetConversations(userID)
-	json.NewEncoder(w).Encode(convs)
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	page := storage.ConversationPage{Cursor: q.Get("cursor"), WorkspaceID: q.Get("workspace_id")}
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		page.Limit = limit
+	}
+	if a := q.Get("archived"); a != "" {
+		archived, err := strconv.ParseBool(a)
+		if err != nil {
+			http.Error(w, "Archived must be true or false", http.StatusBadRequest)
+			return
+		}
+		page.Archived = archived
+	}
+	convs, err := h.Store.GetConversations(r.Context(), userID, page)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to list conversations", http.StatusInternalServerError)
+		log.Printf("Error listing DMs for user %s: %v", userID, err)
+		return
+	}
+
+	// next_cursor is omitted once a page comes back short
+	res := struct {
+		Conversations []*models.DMConversation `json:"conversations"`
+		NextCursor    string                   `json:"next_cursor,omitempty"`
+	}{Conversations: convs}
+	if res.Conversations == nil {
+		res.Conversations = []*models.DMConversation{}
+	}
+	if len(convs) == page.NormalizedLimit() {
+		res.NextCursor = storage.ConversationCursor(convs[len(convs)-1])
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
 }
 
+// dmIDParam returns the conversation ID of a RESTful route,
+// /api/v1/dms/{id}/..., or legacy, the ID the RPC-style route was given.
+func dmIDParam(r *http.Request, legacy string) string {
+	if id := r.PathValue("id"); id != "" {
+		return id
+	}
+	return legacy
+}
+
+// GetMessages returns a page of a conversation's history in chronological order.
+// The caller is identified by their bearer token, see authenticate, and must
+// be able to read the conversation, see memberConversation; the page's
+// messages are marked delivered to them. Query params: dm_id (unless the ID
+// is in the path), optional limit, at most one of before/after (message IDs),
+// and optional user_id, which must match the token.
 func (h *DMHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
-	dmID := r.URL.Query().Get("dm_id")
-	msgs := h.Store.GetMessages(dmID)
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	dmID := dmIDParam(r, q.Get("dm_id"))
+	if dmID == "" {
+		http.Error(w, "DM ID is required", http.StatusBadRequest)
+		return
+	}
+	page := storage.MessagePage{Before: q.Get("before"), After: q.Get("after")}
+	if page.Before != "" && page.After != "" {
+		http.Error(w, "Only one of before and after may be set", http.StatusBadRequest)
+		return
+	}
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		page.Limit = limit
+	}
+	if h.memberConversation(w, r, dmID, userID) == nil {
+		return
+	}
+	msgs, err := h.Store.GetMessages(r.Context(), dmID, page)
+	if err != nil {
+		http.Error(w, "Failed to get messages", http.StatusInternalServerError)
+		log.Printf("Error getting messages for DM %s: %v", dmID, err)
+		return
+	}
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = msg.ID
+	}
+	h.markDelivered(r.Context(), dmID, userID, ids)
 	json.NewEncoder(w).Encode(msgs)
 }
 
+// SendMessage posts a message to a conversation, named by dm_id or the path.
+// With parent_message_id set, the message is a reply in that message's thread.
 func (h *DMHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		DMID     string `json:"dm_id"`
-		SenderID string `json:"sender_id"`
-		Content  string `json:"content"`
+		DMID            string     `json:"dm_id"`
+		SenderID        string     `json:"sender_id"`
+		Content         string     `json:"content"`
+		ParentMessageID string     `json:"parent_message_id"`
+		AttachmentIDs   []string   `json:"attachment_ids"`
+		SendAt          *time.Time `json:"send_at"`
+		KeyVersion      *int       `json:"key_version"` // Set when Content is ciphertext
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.DMID = dmIDParam(r, req.DMID)
+	if req.KeyVersion != nil && !checkCiphertext(w, req.Content, *req.KeyVersion) {
+		return
+	}
+	if req.KeyVersion != nil && req.SendAt != nil {
+		http.Error(w, "Encrypted messages cannot be scheduled", http.StatusBadRequest)
+		return
+	}
+	if !h.checkAttachments(w, r, req.SenderID, req.AttachmentIDs) {
+		return
+	}
+	// The server cannot read ciphertext, so it is not filtered
+	decision := moderation.Decision{Content: req.Content}
+	if req.KeyVersion == nil {
+		var ok bool
+		if decision, ok = h.review(w, r, req.Content); !ok {
+			return
+		}
+	}
+	req.Content = decision.Content
+	if req.SendAt != nil {
+		scheduled := &models.ScheduledDMMessage{
+			DMConversationID: req.DMID,
+			SenderID:         req.SenderID,
+			Content:          req.Content,
+			AttachmentIDs:    req.AttachmentIDs,
+			SendAt:           *req.SendAt,
+		}
+		if req.ParentMessageID != "" {
+			scheduled.ParentMessageID = &req.ParentMessageID
+		}
+		if decision.Flagged {
+			scheduled.FlagReasons = decision.Reasons
+		}
+		h.scheduleMessage(w, r, scheduled)
+		return
+	}
+	var msg *models.DMMessage
+	var err error
+	if req.KeyVersion != nil {
+		msg, err = h.Store.AddEncryptedMessage(r.Context(), req.DMID, req.ParentMessageID, req.SenderID, req.Content, *req.KeyVersion)
+	} else if req.ParentMessageID != "" {
+		msg, err = h.Store.AddReply(r.Context(), req.DMID, req.ParentMessageID, req.SenderID, req.Content)
+	} else {
+		msg, err = h.Store.AddMessage(r.Context(), req.DMID, req.SenderID, req.Content)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Parent message not found in this conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to send message", http.StatusInternalServerError)
+		log.Printf("Error sending message to DM %s: %v", req.DMID, err)
+		return
+	}
+	var flagReasons []string
+	if decision.Flagged {
+		flagReasons = decision.Reasons
+	}
+	h.deliver(r.Context(), msg, req.AttachmentIDs, flagReasons)
+	json.NewEncoder(w).Encode(msg)
+}
+
+// deliver finishes sending a stored message: it links its attachments,
+// resolves its mentions, flags it if flagReasons is set, and broadcasts it
+// along with its notifications and webhook event.
+func (h *DMHandler) deliver(ctx context.Context, msg *models.DMMessage, attachmentIDs, flagReasons []string) {
+	if len(attachmentIDs) > 0 && h.Attachments != nil {
+		var err error
+		msg.Attachments, err = h.Attachments.LinkToDMMessage(ctx, msg.ID, msg.SenderID, attachmentIDs)
+		if err != nil {
+			// The message is already stored; report it without attachments
+			// rather than failing the send.
+			log.Printf("Error linking attachments to DM message %s: %v", msg.ID, err)
+		}
+	}
+	h.setMentions(ctx, msg, nil)
+	if len(flagReasons) > 0 && h.Moderator != nil {
+		h.Moderator.Flag(ctx, models.MessageTypeDM, msg.ID, msg.SenderID, flagReasons)
 	}
-	json.NewDecoder(r.Body).Decode(&req)
-	msg := h.Store.AddMessage(req.DMID, req.SenderID, req.Content)
 	// Broadcast via WebSocket
-	data, _ := json.Marshal(msg)
-	h.Hub.Broadcast <- ws.BroadcastMessage{DMID: req.DMID, Data: data}
+	h.Hub.SendToDM(msg.DMConversationID, ws.TypeChat, msg)
+	h.notifyMessage(ctx, msg)
+	h.notifyMentions(ctx, msg, nil)
+	h.Webhooks.Emit(models.EventMessageSent, "", webhooks.MessageSent{Type: models.MessageTypeDM, DMID: msg.DMConversationID, Message: msg})
+}
+
+// setMentions resolves the @username mentions in msg's content and stores
+// them, replacing previous. Failures are logged; the message is delivered
+// with whatever mentions it had.
+func (h *DMHandler) setMentions(ctx context.Context, msg *models.DMMessage, previous []models.Mention) {
+	var spans []models.Mention
+	if msg.KeyVersion == nil {
+		spans = mentions.Parse(msg.Content)
+	}
+	if len(spans) == 0 && len(previous) == 0 {
+		return
+	}
+	resolved, err := h.Store.SetMentions(ctx, msg.ID, spans)
+	if err != nil {
+		log.Printf("Error storing mentions of DM message %s: %v", msg.ID, err)
+		return
+	}
+	msg.Mentions = resolved
+}
+
+// notifyMessage sends a dm.received notice of msg to the conversation's
+// participants other than its sender, so they hear of it outside the
+// conversation.
+func (h *DMHandler) notifyMessage(ctx context.Context, msg *models.DMMessage) {
+	participants, err := h.Store.GetParticipants(ctx, msg.DMConversationID)
+	if err != nil {
+		log.Printf("Error loading participants to notify of DM message %s: %v", msg.ID, err)
+		return
+	}
+	recipients := slices.DeleteFunc(participants, func(userID string) bool { return userID == msg.SenderID })
+	h.Notify.Notify(ctx, notify.Notification{
+		Channel: models.ChannelDMs,
+		DMID:    msg.DMConversationID,
+		Type:    ws.TypeDMReceived,
+		Payload: msg,
+	}, recipients...)
+}
+
+// notifyMentions sends a mention notice to each user msg mentions, other
+// than its sender and anyone already in previous.
+func (h *DMHandler) notifyMentions(ctx context.Context, msg *models.DMMessage, previous []models.Mention) {
+	notified := make(map[string]bool)
+	for _, userID := range mentions.Recipients(previous, msg.SenderID) {
+		notified[userID] = true
+	}
+	notice := models.MentionNotice{
+		MessageType: models.MessageTypeDM,
+		MessageID:   msg.ID,
+		DMID:        msg.DMConversationID,
+		SenderID:    msg.SenderID,
+		Content:     msg.Content,
+	}
+	var recipients []string
+	for _, userID := range mentions.Recipients(msg.Mentions, msg.SenderID) {
+		if !notified[userID] {
+			recipients = append(recipients, userID)
+		}
+	}
+	h.Notify.Notify(ctx, notify.Notification{
+		Channel: models.ChannelMentions,
+		DMID:    msg.DMConversationID,
+		Type:    ws.TypeMention,
+		Payload: notice,
+	}, recipients...)
+}
+
+// review runs the content filter over a message before it is stored. It
+// writes an error response and returns false if the message is rejected.
+func (h *DMHandler) review(w http.ResponseWriter, r *http.Request, content string) (moderation.Decision, bool) {
+	if h.Moderator == nil || content == "" {
+		return moderation.Decision{Content: content}, true
+	}
+	decision, err := h.Moderator.Review(r.Context(), content)
+	if errors.Is(err, moderation.ErrRejected) {
+		http.Error(w, "Message was rejected by the content filter", http.StatusUnprocessableEntity)
+		return decision, false
+	}
+	return decision, true
+}
+
+// checkAttachments verifies that every attachment ID belongs to the sender and
+// is not yet linked to a message. It writes an error response and returns
+// false if not.
+func (h *DMHandler) checkAttachments(w http.ResponseWriter, r *http.Request, senderID string, ids []string) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	if h.Attachments == nil {
+		http.Error(w, "Attachments are not enabled", http.StatusBadRequest)
+		return false
+	}
+	n, err := h.Attachments.CountUnlinked(r.Context(), senderID, ids)
+	if err != nil {
+		http.Error(w, "Failed to check attachments", http.StatusInternalServerError)
+		log.Printf("Error checking attachments for %s: %v", senderID, err)
+		return false
+	}
+	if n != len(ids) {
+		http.Error(w, "Attachments must be uploaded by the sender and not already sent", http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// SearchMessages runs a full-text search over the caller's conversations,
+// newest match first, with a few messages of context around each match.
+// The caller is identified by their bearer token, see authenticate.
+// Query params: q, optional user_id, which must match the token, optional
+// dm_id to search one conversation, and optional limit.
+func (h *DMHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	search := storage.MessageSearch{Query: strings.TrimSpace(q.Get("q"))}
+	if search.Query == "" {
+		http.Error(w, "q is required as a query parameter", http.StatusBadRequest)
+		return
+	}
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		search.Limit = limit
+	}
+	results, err := h.Store.SearchMessages(r.Context(), userID, q.Get("dm_id"), search)
+	if err != nil {
+		http.Error(w, "Failed to search messages", http.StatusInternalServerError)
+		log.Printf("Error searching DM messages for user %s: %v", userID, err)
+		return
+	}
+	if results == nil {
+		results = []models.DMSearchResult{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// GetThread returns a message's thread: the top-level message and its replies.
+// The caller is identified by their bearer token, see authenticate, and must
+// be able to read the message's conversation. Query params: message_id
+// (either the top-level message or any reply) and optional user_id, which
+// must match the token.
+func (h *DMHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	messageID := q.Get("message_id")
+	if messageID == "" {
+		http.Error(w, "Message ID is required as a query parameter", http.StatusBadRequest)
+		return
+	}
+	thread, err := h.Store.GetThread(r.Context(), messageID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get thread", http.StatusInternalServerError)
+		log.Printf("Error getting thread of DM message %s: %v", messageID, err)
+		return
+	}
+	if h.memberConversation(w, r, thread.Parent.DMConversationID, userID) == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thread)
+}
+
+// EditMessage replaces the content of a message. Only its sender may edit
+// it. With key_version set, the new content is ciphertext.
+func (h *DMHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MessageID  string `json:"message_id"`
+		SenderID   string `json:"sender_id"`
+		Content    string `json:"content"`
+		KeyVersion *int   `json:"key_version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.MessageID == "" || req.SenderID == "" || req.Content == "" {
+		http.Error(w, "Message ID, Sender ID, and Content cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if req.KeyVersion != nil && !checkCiphertext(w, req.Content, *req.KeyVersion) {
+		return
+	}
+	decision := moderation.Decision{Content: req.Content}
+	if req.KeyVersion == nil {
+		var ok bool
+		if decision, ok = h.review(w, r, req.Content); !ok {
+			return
+		}
+	}
+	msg, err := h.Store.EditMessage(r.Context(), req.MessageID, req.SenderID, decision.Content, req.KeyVersion)
+	if !checkMessageWrite(w, err, req.MessageID) {
+		return
+	}
+	// Offsets shift with the new content, so mentions are resolved again;
+	// only users the edit newly mentions are notified
+	previous := msg.Mentions
+	h.setMentions(r.Context(), msg, previous)
+	if decision.Flagged {
+		h.Moderator.Flag(r.Context(), models.MessageTypeDM, msg.ID, req.SenderID, decision.Reasons)
+	}
+	h.Hub.SendToDM(msg.DMConversationID, ws.TypeMessageUpdated, msg)
+	h.notifyMentions(r.Context(), msg, previous)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msg)
+}
+
+// DeleteMessage removes the content of a message, leaving a tombstone.
+// Query params: message_id and sender_id. Only the sender may delete it.
+func (h *DMHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	messageID := r.URL.Query().Get("message_id")
+	senderID := r.URL.Query().Get("sender_id")
+	if messageID == "" || senderID == "" {
+		http.Error(w, "Message ID and Sender ID are required as query parameters", http.StatusBadRequest)
+		return
+	}
+	msg, err := h.Store.DeleteMessage(r.Context(), messageID, senderID)
+	if !checkMessageWrite(w, err, messageID) {
+		return
+	}
+	h.Hub.SendToDM(msg.DMConversationID, ws.TypeMessageDeleted, msg)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msg)
+}
+
+// reactionRequest is the JSON payload of the reaction endpoints.
+type reactionRequest struct {
+	MessageID string `json:"message_id"`
+	UserID    string `json:"user_id"`
+	Emoji     string `json:"emoji"`
+}
+
+// reactionEvent is broadcast to the conversation when a reaction changes.
+type reactionEvent struct {
+	DMID      string                 `json:"dm_id"`
+	MessageID string                 `json:"message_id"`
+	UserID    string                 `json:"user_id"`
+	Emoji     string                 `json:"emoji"`
+	Added     bool                   `json:"added"`
+	Reactions []models.ReactionCount `json:"reactions"`
+}
+
+// AddReaction adds the user's emoji reaction to a message.
+func (h *DMHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, true)
+}
+
+// RemoveReaction removes the user's emoji reaction from a message.
+func (h *DMHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, false)
+}
+
+// changeReaction adds or removes a reaction and broadcasts the new counts.
+func (h *DMHandler) changeReaction(w http.ResponseWriter, r *http.Request, add bool) {
+	var req reactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.MessageID == "" || req.UserID == "" || req.Emoji == "" {
+		http.Error(w, "Message ID, User ID, and Emoji cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if len(req.Emoji) > models.MaxReactionLength {
+		http.Error(w, "Emoji is too long", http.StatusBadRequest)
+		return
+	}
+
+	var msg *models.DMMessage
+	var err error
+	if add {
+		msg, err = h.Store.AddReaction(r.Context(), req.MessageID, req.UserID, req.Emoji)
+	} else {
+		msg, err = h.Store.RemoveReaction(r.Context(), req.MessageID, req.UserID, req.Emoji)
+	}
+	switch {
+	case errors.Is(err, storage.ErrConflict):
+		http.Error(w, "User already reacted with this emoji", http.StatusConflict)
+		return
+	case errors.Is(err, storage.ErrNotFound) && add:
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Reaction not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Failed to update reaction", http.StatusInternalServerError)
+		log.Printf("Error updating reaction on DM message %s: %v", req.MessageID, err)
+		return
+	}
+
+	reactions := msg.Reactions
+	if reactions == nil {
+		reactions = []models.ReactionCount{}
+	}
+	h.Hub.SendToDM(msg.DMConversationID, ws.TypeReaction, reactionEvent{
+		DMID:      msg.DMConversationID,
+		MessageID: msg.ID,
+		UserID:    req.UserID,
+		Emoji:     req.Emoji,
+		Added:     add,
+		Reactions: reactions,
+	})
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(msg)
 }
 
+// checkMessageWrite writes the error response for a failed edit or delete.
+// It returns true if err is nil and the handler should continue.
+func checkMessageWrite(w http.ResponseWriter, err error, messageID string) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Message not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrForbidden):
+		http.Error(w, "Only the sender can change this message", http.StatusForbidden)
+	default:
+		http.Error(w, "Failed to update message", http.StatusInternalServerError)
+		log.Printf("Error updating DM message %s: %v", messageID, err)
+	}
+	return false
+}
+
+// MarkRead resets the caller's unread count for a conversation.
+func (h *DMHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DMID   string `json:"dm_id"`
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DMID == "" || req.UserID == "" {
+		http.Error(w, "DM ID and User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	read, err := h.Store.MarkRead(r.Context(), req.DMID, req.UserID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a participant in this conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to mark conversation read", http.StatusInternalServerError)
+		log.Printf("Error marking DM %s read for %s: %v", req.DMID, req.UserID, err)
+		return
+	}
+	if len(read) > 0 {
+		now := time.Now()
+		h.Hub.SendToDM(req.DMID, ws.TypeReceipt, models.DMReceiptEvent{DMID: req.DMID, UserID: req.UserID, MessageIDs: read, ReadAt: &now})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Conversation marked as read"})
+}
+
+// Archive hides a conversation from the caller's default conversation list.
+// The other participants are unaffected, and a new message unarchives it.
+func (h *DMHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// Unarchive returns an archived conversation to the caller's default list.
+func (h *DMHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *DMHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	var req struct {
+		DMID   string `json:"dm_id"`
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DMID == "" || req.UserID == "" {
+		http.Error(w, "DM ID and User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	err := h.Store.SetArchived(r.Context(), req.DMID, req.UserID, archived)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a participant in this conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update conversation", http.StatusInternalServerError)
+		log.Printf("Error setting DM %s archived=%t for %s: %v", req.DMID, archived, req.UserID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(archiveResponse{DMID: req.DMID, Archived: archived})
+}
+
+// Mute stops a conversation from notifying the caller or raising their
+// unread count. Its messages are still delivered.
+func (h *DMHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	h.setMuted(w, r, true)
+}
+
+// Unmute restores a muted conversation's notifications and unread count.
+func (h *DMHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	h.setMuted(w, r, false)
+}
+
+func (h *DMHandler) setMuted(w http.ResponseWriter, r *http.Request, muted bool) {
+	var req struct {
+		DMID   string `json:"dm_id"`
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DMID == "" || req.UserID == "" {
+		http.Error(w, "DM ID and User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	err := h.Store.SetMuted(r.Context(), req.DMID, req.UserID, muted)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a participant in this conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update conversation", http.StatusInternalServerError)
+		log.Printf("Error setting DM %s muted=%t for %s: %v", req.DMID, muted, req.UserID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(muteResponse{DMID: req.DMID, Muted: muted})
+}
+
+// Bounds on a conversation's message TTL.
+const (
+	minMessageTTL = time.Minute
+	maxMessageTTL = 90 * 24 * time.Hour
+)
+
+// SetMessageTTL turns disappearing messages on or off for a conversation.
+// Messages sent afterwards are deleted ttl_seconds after they were sent; 0
+// turns it off. Any participant may change it, and the conversation is told
+// so every participant sees the setting.
+func (h *DMHandler) SetMessageTTL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DMID       string `json:"dm_id"`
+		UserID     string `json:"user_id"`
+		TTLSeconds int    `json:"ttl_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DMID == "" || req.UserID == "" {
+		http.Error(w, "DM ID and User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	if req.TTLSeconds != 0 && (req.TTLSeconds < 0 || ttl < minMessageTTL || ttl > maxMessageTTL) {
+		http.Error(w, fmt.Sprintf("TTL must be 0 or between %d and %d seconds",
+			int(minMessageTTL.Seconds()), int(maxMessageTTL.Seconds())), http.StatusBadRequest)
+		return
+	}
+	err := h.Store.SetMessageTTL(r.Context(), req.DMID, req.UserID, ttl)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a participant in this conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update conversation", http.StatusInternalServerError)
+		log.Printf("Error setting message TTL of DM %s to %s for %s: %v", req.DMID, ttl, req.UserID, err)
+		return
+	}
+
+	event := models.DMMessageTTLEvent{DMID: req.DMID, UserID: req.UserID, TTLSeconds: req.TTLSeconds}
+	h.Hub.SendToDM(req.DMID, ws.TypeMessageTTL, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+	log.Printf("Message TTL of DM %s set to %s by %s", req.DMID, ttl, req.UserID)
+}
+
+// UnreadTotal returns the user's unread message count across all conversations.
+func (h *DMHandler) UnreadTotal(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter", http.StatusBadRequest)
+		return
+	}
+	total, err := h.Store.GetUnreadTotal(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get unread total", http.StatusInternalServerError)
+		log.Printf("Error getting unread total for %s: %v", userID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"unread_total": total})
+}
+
+// CreateGroup creates a named conversation with the creator and any number of members,
+// private to a workspace when workspace_id is set.
+func (h *DMHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string   `json:"name"`
+		CreatorID   string   `json:"creator_id"`
+		Members     []string `json:"members"`
+		WorkspaceID string   `json:"workspace_id"` // Optional; the creator and every member must be members
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || req.CreatorID == "" {
+		http.Error(w, "Name and Creator ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	conv, err := h.Store.CreateGroupConversation(r.Context(), req.WorkspaceID, req.Name, req.CreatorID, req.Members)
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "Every member must be a member of the workspace", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create group", http.StatusInternalServerError)
+		log.Printf("Error creating group DM %q: %v", req.Name, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(conv)
+}
+
+// ListMembers returns the participants of a conversation. The caller is
+// identified by their bearer token, see authenticate, and must be able to
+// read the conversation. Query params: dm_id and optional user_id, which
+// must match the token.
+func (h *DMHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	dmID := q.Get("dm_id")
+	if dmID == "" {
+		http.Error(w, "DM ID is required as a query parameter", http.StatusBadRequest)
+		return
+	}
+	conv := h.memberConversation(w, r, dmID, userID)
+	if conv == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conv.Participants)
+}
+
+// AddMember adds a user to a group conversation. The caller is identified by
+// their bearer token, see authenticate, and must be a participant. It
+// expects a JSON payload with "dm_id" and "user_id", the user to add.
+func (h *DMHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DMID   string `json:"dm_id"`
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	actorID, ok := h.authenticate(w, r, "")
+	if !ok {
+		return
+	}
+	if req.DMID == "" || req.UserID == "" {
+		http.Error(w, "DM ID and User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if h.memberConversation(w, r, req.DMID, actorID) == nil {
+		return
+	}
+	err := h.Store.AddParticipant(r.Context(), req.DMID, req.UserID)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Failed to add member: not a group or user already a member", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "User is not a member of the conversation's workspace", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to add member", http.StatusInternalServerError)
+		log.Printf("Error adding %s to DM %s: %v", req.UserID, req.DMID, err)
+		return
+	}
+	log.Printf("User %s added %s to DM %s", actorID, req.UserID, req.DMID)
+	h.writeMembers(w, r, req.DMID, "Member added successfully")
+}
+
+// RemoveMember removes a user from a group conversation and closes their
+// connections to it. The caller is identified by their bearer token and may
+// remove themselves; only the group's creator may remove others. It expects
+// a JSON payload with "dm_id" and "user_id", the user to remove.
+func (h *DMHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DMID   string `json:"dm_id"`
+		UserID string `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	actorID, ok := h.authenticate(w, r, "")
+	if !ok {
+		return
+	}
+	if req.DMID == "" || req.UserID == "" {
+		http.Error(w, "DM ID and User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	conv := h.memberConversation(w, r, req.DMID, actorID)
+	if conv == nil {
+		return
+	}
+	if req.UserID != actorID && conv.CreatorID != actorID {
+		http.Error(w, "Only the group's creator can remove other members", http.StatusForbidden)
+		log.Printf("User %s attempted to remove %s from DM %s", actorID, req.UserID, req.DMID)
+		return
+	}
+	err := h.Store.RemoveParticipant(r.Context(), req.DMID, req.UserID)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Failed to remove member: not a group or user not a member", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to remove member", http.StatusInternalServerError)
+		log.Printf("Error removing %s from DM %s: %v", req.UserID, req.DMID, err)
+		return
+	}
+	h.Hub.DisconnectDMUser(req.DMID, req.UserID, "removed from conversation")
+	log.Printf("User %s removed %s from DM %s", actorID, req.UserID, req.DMID)
+	h.writeMembers(w, r, req.DMID, "Member removed successfully")
+}
+
+// memberConversation loads a conversation userID may read, see
+// storage.ConversationVisible. It returns nil if a response has already been
+// written; conversations the user may not read are reported not found.
+func (h *DMHandler) memberConversation(w http.ResponseWriter, r *http.Request, dmID, userID string) *models.DMConversation {
+	conv, err := h.Store.GetConversation(r.Context(), dmID)
+	if err == nil {
+		var visible bool
+		visible, err = storage.ConversationVisible(r.Context(), h.Workspaces, conv, userID)
+		if err == nil && !visible {
+			err = storage.ErrNotFound
+		}
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Conversation not found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error loading DM %s for %s: %v", dmID, userID, err)
+		return nil
+	}
+	return conv
+}
+
+// writeMembers replies with message and the conversation's participants
+// after a membership change.
+func (h *DMHandler) writeMembers(w http.ResponseWriter, r *http.Request, dmID, message string) {
+	members, err := h.Store.GetParticipants(r.Context(), dmID)
+	if err != nil {
+		http.Error(w, "Failed to load members", http.StatusInternalServerError)
+		log.Printf("Error loading participants of DM %s: %v", dmID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": message,
+		"members": members,
+	})
+}
+
 // WebSocket handler
-var upgrader = websocket.Upgrader{}
+// maxAckIDs caps the message IDs a single delivery ack may carry.
+const maxAckIDs = 200
 
+// deliveredAck is the payload of a message.delivered frame sent by a client
+// for messages it received.
+type deliveredAck struct {
+	MessageIDs []string `json:"message_ids"`
+}
+
+// markDelivered records that messages reached userID and tells the
+// conversation which of them were newly delivered. Failures are logged;
+// delivery receipts are best effort.
+func (h *DMHandler) markDelivered(ctx context.Context, dmID, userID string, messageIDs []string) {
+	delivered, err := h.Store.MarkDelivered(ctx, dmID, userID, messageIDs)
+	if err != nil {
+		log.Printf("Error marking messages of DM %s delivered to %s: %v", dmID, userID, err)
+		return
+	}
+	if len(delivered) == 0 {
+		return
+	}
+	now := time.Now()
+	h.Hub.SendToDM(dmID, ws.TypeReceipt, models.DMReceiptEvent{DMID: dmID, UserID: userID, MessageIDs: delivered, DeliveredAt: &now})
+}
+
+var upgrader = websocket.Upgrader{Subprotocols: ws.Subprotocols, EnableCompression: true}
+
+// ServeWS upgrades a participant of a conversation to its WebSocket; in a
+// workspace conversation they must also still be a member. The user is
+// identified by a token from /api/v1/users/ws-token, passed as the "token"
+// query parameter or after ws.TokenProtocol in Sec-WebSocket-Protocol.
 func (h *DMHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	dmID := r.URL.Query().Get("dm_id")
-	userID := r.URL.Query().Get("user_id")
+	if dmID == "" {
+		http.Error(w, "DM ID is required", http.StatusBadRequest)
+		return
+	}
+	userID, err := h.Tokens.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected DM WS for DM %s: %v", dmID, err)
+		return
+	}
+	conv, err := h.Store.GetConversation(r.Context(), dmID)
+	visible := false
+	if err == nil {
+		visible, err = storage.ConversationVisible(r.Context(), h.Workspaces, conv, userID)
+	}
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error loading DM %s for %s: %v", dmID, userID, err)
+		return
+	}
+	if !visible {
+		http.Error(w, "User is not a participant of this conversation", http.StatusForbidden)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
 	client := &ws.Client{
-		UserID: userID,
-		DMID:   dmID,
-		Send:   make(chan []byte, 256),
-		Conn:   conn,
-	}
-	h.Hub.Register <- client
-
-	// Read pump
-	go func() {
-		defer func() {
-			h.Hub.Unregister <- client
-			conn.Close()
-		}()
-		for {
-			_, msg, err := conn.ReadMessage()
-			if err != nil {
-				break
+		UserID:      userID,
+		DMID:        dmID,
+		Conn:        conn,
+		IP:          h.Hub.RemoteIP(r),
+		LastEventID: r.URL.Query().Get("last_event_id"),
+	}
+	h.Hub.Serve(client, func(msg []byte) {
+		// Only relay well-formed envelopes so other clients can dispatch on type
+		env, err := ws.Decode(msg)
+		if err != nil {
+			log.Printf("Dropping malformed WS frame from %s in DM %s: %v", userID, dmID, err)
+			return
+		}
+		// Delivery acks are recorded rather than relayed
+		if env.Type == ws.TypeDelivered {
+			var ack deliveredAck
+			if err := env.DecodePayload(&ack); err != nil || len(ack.MessageIDs) > maxAckIDs {
+				log.Printf("Dropping invalid delivery ack from %s in DM %s", userID, dmID)
+				return
 			}
-			h.Hub.Broadcast <- ws.BroadcastMessage{DMID: dmID, Data: msg}
+			h.markDelivered(context.Background(), dmID, userID, ack.MessageIDs)
+			return
 		}
-	}()
-	// Write pump
-	go func() {
-		for message := range client.Send {
-			conn.WriteMessage(websocket.TextMessage, message)
+		// Event IDs and sequence numbers are assigned by the server only
+		env.ID, env.Seq = "", 0
+		data, err := json.Marshal(env)
+		if err != nil {
+			log.Printf("Failed to re-encode WS frame from %s in DM %s: %v", userID, dmID, err)
+			return
 		}
-	}()
+		h.Hub.Broadcast(ws.BroadcastMessage{DMID: dmID, Data: data})
+	})
 }
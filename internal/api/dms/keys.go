@@ -0,0 +1,121 @@
+package dms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxWrappedKeys is the most devices a key can be distributed to at once.
+const maxWrappedKeys = 256
+
+// checkCiphertext validates the fields of an encrypted message. It writes an
+// error response and returns false if they are invalid. The ciphertext
+// itself is opaque.
+func checkCiphertext(w http.ResponseWriter, ciphertext string, keyVersion int) bool {
+	if keyVersion <= 0 {
+		http.Error(w, "Key version must be positive", http.StatusBadRequest)
+		return false
+	}
+	if ciphertext == "" {
+		http.Error(w, "Ciphertext cannot be empty", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// AddConversationKeys stores a new version of a conversation's key, wrapped
+// by the sender for each participant device, and tells the conversation a
+// new version is available. The server never sees the unwrapped key.
+func (h *DMHandler) AddConversationKeys(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DMID       string `json:"dm_id"`
+		SenderID   string `json:"sender_id"`
+		KeyVersion int    `json:"key_version"`
+		Keys       []struct {
+			UserID     string `json:"user_id"`
+			DeviceID   string `json:"device_id"`
+			WrappedKey string `json:"wrapped_key"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DMID == "" || req.SenderID == "" {
+		http.Error(w, "DM ID and Sender ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if req.KeyVersion <= 0 {
+		http.Error(w, "Key version must be positive", http.StatusBadRequest)
+		return
+	}
+	if len(req.Keys) == 0 || len(req.Keys) > maxWrappedKeys {
+		http.Error(w, fmt.Sprintf("Between 1 and %d keys are required", maxWrappedKeys), http.StatusBadRequest)
+		return
+	}
+	keys := make([]models.DMConversationKey, len(req.Keys))
+	for i, k := range req.Keys {
+		if k.UserID == "" || k.DeviceID == "" || k.WrappedKey == "" {
+			http.Error(w, "Each key needs a user ID, device ID, and wrapped key", http.StatusBadRequest)
+			return
+		}
+		keys[i] = models.DMConversationKey{UserID: k.UserID, DeviceID: k.DeviceID, WrappedKey: k.WrappedKey}
+	}
+
+	stored, err := h.Store.AddConversationKeys(r.Context(), req.DMID, req.SenderID, req.KeyVersion, keys)
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "Keys can only be exchanged between participants of the conversation", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "A device already has this key version", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to store keys", http.StatusInternalServerError)
+		log.Printf("Error storing version %d keys of DM %s from %s: %v", req.KeyVersion, req.DMID, req.SenderID, err)
+		return
+	}
+	h.Hub.SendToDM(req.DMID, ws.TypeConversationKey, models.DMKeyEvent{DMID: req.DMID, KeyVersion: req.KeyVersion, SenderID: req.SenderID})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(stored)
+	log.Printf("Stored version %d of DM %s key for %d devices from %s", req.KeyVersion, req.DMID, len(stored), req.SenderID)
+}
+
+// GetConversationKeys returns the keys of a conversation wrapped for one of
+// the caller's devices, newest version first. The caller is identified by
+// their bearer token, see authenticate, and must be able to read the
+// conversation. Query params: dm_id, device_id, and optional user_id, which
+// must match the token.
+func (h *DMHandler) GetConversationKeys(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	dmID, deviceID := q.Get("dm_id"), q.Get("device_id")
+	if dmID == "" || deviceID == "" {
+		http.Error(w, "DM ID and Device ID are required as query parameters", http.StatusBadRequest)
+		return
+	}
+	if h.memberConversation(w, r, dmID, userID) == nil {
+		return
+	}
+	keys, err := h.Store.GetConversationKeys(r.Context(), dmID, userID, deviceID)
+	if err != nil {
+		http.Error(w, "Failed to get keys", http.StatusInternalServerError)
+		log.Printf("Error getting keys of DM %s for %s device %s: %v", dmID, userID, deviceID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
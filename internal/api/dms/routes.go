@@ -3,10 +3,29 @@ package dms
 import (
 	"log"
 	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/middleware"
 )
 
 // RegisterDMRoutes registers all DM-related HTTP and WebSocket routes.
 func RegisterDMRoutes(mux *http.ServeMux, handler *DMHandler) {
+	// Resource routes take the conversation ID from the path. They are
+	// registered without a method so the RPC-style routes below stay more
+	// specific and the patterns do not conflict; /messages and /send are
+	// kept for older clients and marked deprecated.
+	mux.HandleFunc("/api/v1/dms/{id}/messages", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.GetMessages(w, r)
+		case http.MethodPost:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.SendMessage(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/api/v1/dms/start", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -25,22 +44,229 @@ func RegisterDMRoutes(mux *http.ServeMux, handler *DMHandler) {
 		handler.ListConversations(w, r)
 	})
 
-	mux.HandleFunc("/api/v1/dms/messages", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/dms/messages", middleware.Deprecated("/api/v1/dms/{id}/messages", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
 		handler.GetMessages(w, r)
+	}))
+
+	mux.HandleFunc("/api/v1/dms/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.SearchMessages(w, r)
 	})
 
-	mux.HandleFunc("/api/v1/dms/send", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/dms/send", middleware.Deprecated("/api/v1/dms/{id}/messages", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
 		handler.SendMessage(w, r)
+	}))
+
+	mux.HandleFunc("/api/v1/dms/thread", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.GetThread(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/edit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.EditMessage(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/delete", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.DeleteMessage(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/reactions/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.AddReaction(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/reactions/remove", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.RemoveReaction(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/read", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.MarkRead(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/archive", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.Archive(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/unarchive", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.Unarchive(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/mute", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.Mute(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/unmute", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.Unmute(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/export", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.ExportMessages(w, r)
+	})
+
+	// GET returns a device's wrapped conversation keys, POST distributes a new version
+	mux.HandleFunc("/api/v1/dms/keys", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.GetConversationKeys(w, r)
+		case http.MethodPost:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.AddConversationKeys(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[DM] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET returns the user's draft in a conversation, PUT replaces it
+	mux.HandleFunc("/api/v1/dms/draft", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.GetDraft(w, r)
+		case http.MethodPut:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.SaveDraft(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[DM] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET lists the user's scheduled messages, DELETE cancels one
+	mux.HandleFunc("/api/v1/dms/scheduled", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.ListScheduled(w, r)
+		case http.MethodDelete:
+			log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+			handler.CancelScheduled(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[DM] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/dms/ttl", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.SetMessageTTL(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/unread-total", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.UnreadTotal(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/groups/create", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.CreateGroup(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/members", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.ListMembers(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/members/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.AddMember(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/dms/members/remove", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		log.Printf("[DM] %s %s", r.Method, r.URL.Path)
+		handler.RemoveMember(w, r)
 	})
 
 	mux.HandleFunc("/ws/dms", func(w http.ResponseWriter, r *http.Request) {
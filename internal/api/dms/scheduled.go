@@ -0,0 +1,103 @@
+package dms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// maxScheduleAhead is how far in the future a message may be scheduled.
+const maxScheduleAhead = 365 * 24 * time.Hour
+
+// scheduleMessage stores a message sent with send_at instead of sending it,
+// and replies 202 Accepted with the scheduled message.
+func (h *DMHandler) scheduleMessage(w http.ResponseWriter, r *http.Request, m *models.ScheduledDMMessage) {
+	now := time.Now()
+	if !m.SendAt.After(now) || m.SendAt.After(now.Add(maxScheduleAhead)) {
+		http.Error(w, "send_at must be in the future and at most a year away", http.StatusBadRequest)
+		return
+	}
+	scheduled, err := h.Store.ScheduleMessage(r.Context(), m)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Sender is not a participant in this conversation, or the parent message was not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to schedule message", http.StatusInternalServerError)
+		log.Printf("Error scheduling message to DM %s: %v", m.DMConversationID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(scheduled)
+	log.Printf("Scheduled message %s to DM %s from %s for %s", scheduled.ID, scheduled.DMConversationID, scheduled.SenderID, scheduled.SendAt)
+}
+
+// ListScheduled returns the messages the user scheduled and has not sent
+// yet, soonest first. Query params: user_id and optional dm_id.
+func (h *DMHandler) ListScheduled(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	dmID := r.URL.Query().Get("dm_id")
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter", http.StatusBadRequest)
+		return
+	}
+	scheduled, err := h.Store.GetScheduledMessages(r.Context(), userID, dmID)
+	if err != nil {
+		http.Error(w, "Failed to get scheduled messages", http.StatusInternalServerError)
+		log.Printf("Error getting scheduled messages of %s: %v", userID, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scheduled)
+}
+
+// CancelScheduled deletes a scheduled message before it is sent. Query
+// params: id and user_id. Only the sender may cancel it.
+func (h *DMHandler) CancelScheduled(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	userID := r.URL.Query().Get("user_id")
+	if id == "" || userID == "" {
+		http.Error(w, "ID and User ID are required as query parameters", http.StatusBadRequest)
+		return
+	}
+	err := h.Store.CancelScheduledMessage(r.Context(), id, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scheduled message not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to cancel scheduled message", http.StatusInternalServerError)
+		log.Printf("Error cancelling scheduled message %s of %s: %v", id, userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Scheduled message %s cancelled by %s", id, userID)
+}
+
+// SendScheduled sends every scheduled message that is due, broadcasting
+// each as if its sender had just sent it. It is intended to run as a
+// scheduled job.
+func (h *DMHandler) SendScheduled(ctx context.Context) error {
+	for ctx.Err() == nil {
+		scheduled, msg, err := h.Store.SendScheduledMessage(ctx)
+		if err != nil {
+			return err
+		}
+		if scheduled == nil {
+			return nil
+		}
+		if msg == nil {
+			log.Printf("Dropped scheduled message %s: %s is no longer in DM %s", scheduled.ID, scheduled.SenderID, scheduled.DMConversationID)
+			continue
+		}
+		h.deliver(ctx, msg, scheduled.AttachmentIDs, scheduled.FlagReasons)
+	}
+	return ctx.Err()
+}
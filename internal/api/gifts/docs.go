@@ -0,0 +1,62 @@
+package gifts
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// Routes describes the routes registered by RegisterGiftRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodGet, Path: "/api/v1/gifts/catalog", ID: "getGiftCatalog", Tag: "Gifts",
+		Summary:  "List the gifts senders can choose from",
+		Response: []catalogEntry{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/gifts/send", ID: "sendGift", Tag: "Gifts",
+		Summary: "Tip a scene's host or send them a gift",
+		Description: "The sender is identified by their token from login or /api/v1/users/ws-token in an \"Authorization: Bearer\" header; " +
+			"senderID may be omitted, and is rejected with 403 if it names someone else. " +
+			"Only participants other than the host may send gifts. Tips carry amountCents (50 to 50000); other kinds cost their catalog price. " +
+			"With method credits the price is deducted from the sender's balance (402 if it is too low) and the gift is delivered at once. " +
+			"With method stripe the gift is returned pending with a checkoutURL and delivered once Stripe confirms the payment. " +
+			"Delivered gifts are sent to the scene as a gift.received event.",
+		Body: struct {
+			SceneID     string            `json:"sceneID"`
+			SenderID    string            `json:"senderID,omitempty"`
+			Kind        models.GiftKind   `json:"kind"`
+			AmountCents int               `json:"amountCents,omitempty"`
+			Message     string            `json:"message,omitempty"`
+			Method      models.GiftMethod `json:"method,omitempty"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.Gift{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/gifts/earnings", ID: "getSceneEarnings", Tag: "Gifts",
+		Summary:     "Total the gifts a scene's host received",
+		Description: "Only the host may view earnings. Refunded and unpaid gifts are not counted.",
+		Query:       []openapi.Param{{Name: "scene_id", Required: true}, {Name: "user_id", Required: true}},
+		Response:    models.SceneEarnings{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/gifts/credits", ID: "getCredits", Tag: "Gifts",
+		Summary:  "Get a user's credit balance",
+		Query:    []openapi.Param{{Name: "user_id", Required: true}},
+		Response: creditBalance{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/gifts/credits/grant", ID: "grantCredits", Tag: "Gifts",
+		Summary: "Add credits to a user's balance",
+		Description: "Only admins may grant credits. The admin is identified by their token in an \"Authorization: Bearer\" header; " +
+			"adminID may be omitted, and is rejected with 403 if it names someone else.",
+		Body: struct {
+			AdminID     string `json:"adminID,omitempty"`
+			UserID      string `json:"userID"`
+			AmountCents int    `json:"amountCents"`
+		}{},
+		Response: creditBalance{},
+	},
+}
@@ -0,0 +1,331 @@
+package gifts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/moderation"
+	"github.com/Vasu1712/scenyx-backend/internal/app/tipping"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// Limits on gifts.
+const (
+	minTipCents       = 50 // Stripe rejects smaller charges
+	maxTipCents       = 50_000
+	maxGiftMessage    = 140
+	maxCreditGrant    = 1_000_000
+	earningsTopSender = 10
+)
+
+// GiftHandler holds the dependencies for tipping scene hosts.
+type GiftHandler struct {
+	Tipping   *tipping.Service      // Charges and delivers gifts
+	Store     storage.GiftStore     // The gifts ledger and credit balances
+	Scenes    storage.SceneStore    // Supplies the host and participants of a scene
+	Moderator *moderation.Moderator // Content filter for gift messages; nil when filtering is disabled
+	Tokens    *ws.TokenSigner       // Verifies the token identifying whoever spends or grants credits
+	Admins    map[string]bool       // User IDs allowed to grant credits
+}
+
+// authenticate returns the user identified by the token from login or
+// /api/v1/users/ws-token in the request's "Authorization: Bearer" header.
+// claimed, the user ID named in the request, may be empty but otherwise must
+// match. It returns false if a response has already been written.
+func (h *GiftHandler) authenticate(w http.ResponseWriter, r *http.Request, claimed string) (string, bool) {
+	userID, err := h.Tokens.AuthenticateBearer(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected %s %s: %v", r.Method, r.URL.Path, err)
+		return "", false
+	}
+	if claimed != "" && claimed != userID {
+		http.Error(w, "Token was issued to another user", http.StatusForbidden)
+		log.Printf("User %s attempted %s %s as %s", userID, r.Method, r.URL.Path, claimed)
+		return "", false
+	}
+	return userID, true
+}
+
+// catalogEntry is a gift kind offered in the catalog.
+type catalogEntry struct {
+	Kind       models.GiftKind `json:"kind"`
+	PriceCents int             `json:"priceCents"` // 0 for tips, which carry any amount
+	Currency   string          `json:"currency"`
+}
+
+// GetCatalog handles the HTTP GET request for the gifts senders can choose
+// from and their prices, cheapest first.
+func (h *GiftHandler) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	catalog := []catalogEntry{{Kind: models.GiftTip, Currency: models.GiftCurrency}}
+	for kind, price := range models.GiftPrices {
+		catalog = append(catalog, catalogEntry{Kind: kind, PriceCents: price, Currency: models.GiftCurrency})
+	}
+	slices.SortFunc(catalog, func(a, b catalogEntry) int { return a.PriceCents - b.PriceCents })
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(catalog)
+}
+
+// SendGift handles the HTTP POST request to tip a scene's host. The sender is
+// identified by their bearer token, see authenticate. It expects a JSON
+// payload with "sceneID", "kind", and optionally "senderID", which must match
+// the token, "message", "method" ("credits", the default, or "stripe"), and
+// for tips "amountCents". Only participants other than the host may send gifts.
+func (h *GiftHandler) SendGift(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID     string            `json:"sceneID"`
+		SenderID    string            `json:"senderID"`
+		Kind        models.GiftKind   `json:"kind"`
+		AmountCents int               `json:"amountCents"`
+		Message     string            `json:"message"`
+		Method      models.GiftMethod `json:"method"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SendGift: %v", err)
+		return
+	}
+
+	senderID, ok := h.authenticate(w, r, req.SenderID)
+	if !ok {
+		return
+	}
+	req.SenderID = senderID
+
+	if req.SceneID == "" {
+		http.Error(w, "Scene ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for SendGift")
+		return
+	}
+	if !req.Kind.IsValid() {
+		http.Error(w, "Unknown gift kind", http.StatusBadRequest)
+		log.Printf("Validation error: Unknown gift kind %q for SendGift", req.Kind)
+		return
+	}
+	if req.Method == "" {
+		req.Method = models.GiftCredits
+	}
+	if req.Method != models.GiftCredits && req.Method != models.GiftStripe {
+		http.Error(w, "Method must be credits or stripe", http.StatusBadRequest)
+		return
+	}
+	amount := models.GiftPrices[req.Kind]
+	if req.Kind == models.GiftTip {
+		if req.AmountCents < minTipCents || req.AmountCents > maxTipCents {
+			http.Error(w, fmt.Sprintf("Tip must be between %d and %d cents", minTipCents, maxTipCents), http.StatusBadRequest)
+			log.Printf("Validation error: Invalid tip amount %d for SendGift", req.AmountCents)
+			return
+		}
+		amount = req.AmountCents
+	}
+	message := strings.TrimSpace(req.Message)
+	if len([]rune(message)) > maxGiftMessage {
+		http.Error(w, fmt.Sprintf("Message must be at most %d characters", maxGiftMessage), http.StatusBadRequest)
+		return
+	}
+
+	scene, err := h.Scenes.GetScene(r.Context(), req.SceneID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene %s for SendGift: %v", req.SceneID, err)
+		return
+	}
+	if scene.ArchivedAt != nil {
+		http.Error(w, "Scene is archived", http.StatusConflict)
+		return
+	}
+	if req.SenderID == scene.CreatorID {
+		http.Error(w, "Hosts cannot send gifts in their own scene", http.StatusBadRequest)
+		return
+	}
+	participants, err := h.Scenes.GetSceneParticipants(r.Context(), []string{scene.ID})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error loading participants of scene %s: %v", scene.ID, err)
+		return
+	}
+	if !slices.Contains(participants[scene.ID], req.SenderID) {
+		http.Error(w, "Only scene participants can send gifts", http.StatusForbidden)
+		return
+	}
+
+	if h.Moderator != nil && message != "" {
+		decision, err := h.Moderator.Review(r.Context(), message)
+		if errors.Is(err, moderation.ErrRejected) {
+			http.Error(w, "Message was rejected by the content filter", http.StatusUnprocessableEntity)
+			log.Printf("Rejected gift message from %s in scene %s: %v", req.SenderID, scene.ID, decision.Reasons)
+			return
+		}
+		message = decision.Content
+	}
+
+	gift, err := h.Tipping.Send(r.Context(), scene, &models.Gift{
+		SceneID:     scene.ID,
+		SenderID:    req.SenderID,
+		RecipientID: scene.CreatorID,
+		Kind:        req.Kind,
+		AmountCents: amount,
+		Currency:    models.GiftCurrency,
+		Message:     message,
+		Method:      req.Method,
+	})
+	if errors.Is(err, tipping.ErrInsufficientCredits) {
+		http.Error(w, "Insufficient credits", http.StatusPaymentRequired)
+		return
+	}
+	if errors.Is(err, tipping.ErrStripeDisabled) {
+		http.Error(w, "Stripe payments are not enabled", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if req.Method == models.GiftStripe {
+			status = http.StatusBadGateway
+		}
+		http.Error(w, "Failed to send gift", status)
+		log.Printf("Error sending %s gift from %s in scene %s: %v", req.Kind, req.SenderID, scene.ID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(gift)
+}
+
+// GetEarnings handles the HTTP GET request for the gifts a scene's host has
+// received. It expects the query parameters "scene_id" and "user_id"; only
+// the host may see them.
+func (h *GiftHandler) GetEarnings(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	userID := r.URL.Query().Get("user_id")
+
+	if sceneID == "" || userID == "" {
+		http.Error(w, "Scene ID and User ID are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID missing for GetEarnings")
+		return
+	}
+
+	scene, err := h.Scenes.GetScene(r.Context(), sceneID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene %s for GetEarnings: %v", sceneID, err)
+		return
+	}
+	if scene.CreatorID != userID {
+		http.Error(w, "Only the scene host can view its earnings", http.StatusForbidden)
+		log.Printf("User %s attempted to view earnings of scene %s", userID, sceneID)
+		return
+	}
+
+	earnings, err := h.Store.GetSceneEarnings(r.Context(), sceneID, earningsTopSender)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting earnings of scene %s: %v", sceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(earnings)
+}
+
+// creditBalance is the response of the credit routes.
+type creditBalance struct {
+	UserID       string `json:"userID"`
+	BalanceCents int    `json:"balanceCents"`
+	Currency     string `json:"currency"`
+}
+
+// GetCredits handles the HTTP GET request for a user's credit balance.
+// It expects the user ID as a query parameter "user_id".
+func (h *GiftHandler) GetCredits(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetCredits")
+		return
+	}
+
+	balance, err := h.Store.GetCreditBalance(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting credit balance of user %s: %v", userID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(creditBalance{UserID: userID, BalanceCents: balance, Currency: models.GiftCurrency})
+}
+
+// GrantCredits handles the HTTP POST request for an admin to add credits to
+// a user's balance. The admin is identified by their bearer token, see
+// authenticate. It expects a JSON payload with "userID", "amountCents", and
+// optionally "adminID", which must match the token.
+func (h *GiftHandler) GrantCredits(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AdminID     string `json:"adminID"`
+		UserID      string `json:"userID"`
+		AmountCents int    `json:"amountCents"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for GrantCredits: %v", err)
+		return
+	}
+
+	adminID, ok := h.authenticate(w, r, req.AdminID)
+	if !ok {
+		return
+	}
+	req.AdminID = adminID
+
+	if req.UserID == "" {
+		http.Error(w, "User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GrantCredits")
+		return
+	}
+	if req.AmountCents <= 0 || req.AmountCents > maxCreditGrant {
+		http.Error(w, fmt.Sprintf("Amount must be between 1 and %d cents", maxCreditGrant), http.StatusBadRequest)
+		return
+	}
+	if !h.Admins[req.AdminID] {
+		http.Error(w, "Only admins can grant credits", http.StatusForbidden)
+		log.Printf("Non-admin %s attempted to grant credits", req.AdminID)
+		return
+	}
+
+	balance, err := h.Store.AddCredits(r.Context(), req.UserID, req.AmountCents)
+	if err != nil {
+		http.Error(w, "Failed to grant credits", http.StatusInternalServerError)
+		log.Printf("Error granting credits to user %s: %v", req.UserID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(creditBalance{UserID: req.UserID, BalanceCents: balance, Currency: models.GiftCurrency})
+	log.Printf("Admin %s granted %d credits to user %s", req.AdminID, req.AmountCents, req.UserID)
+}
@@ -0,0 +1,59 @@
+package gifts
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterGiftRoutes registers the tipping and credit routes with the provided ServeMux.
+func RegisterGiftRoutes(mux *http.ServeMux, handler *GiftHandler) {
+	mux.HandleFunc("/api/v1/gifts/catalog", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Gift] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Gift] %s %s", r.Method, r.URL.Path)
+		handler.GetCatalog(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/gifts/send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Gift] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Gift] %s %s", r.Method, r.URL.Path)
+		handler.SendGift(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/gifts/earnings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Gift] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Gift] %s %s", r.Method, r.URL.Path)
+		handler.GetEarnings(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/gifts/credits", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Gift] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Gift] %s %s", r.Method, r.URL.Path)
+		handler.GetCredits(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/gifts/credits/grant", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Gift] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Gift] %s %s", r.Method, r.URL.Path)
+		handler.GrantCredits(w, r)
+	})
+}
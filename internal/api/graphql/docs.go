@@ -0,0 +1,34 @@
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+)
+
+// conversationAuth describes how conversation queries identify the caller.
+const conversationAuth = "conversation and conversations are answered only for the caller identified by their token " +
+	"from login or /api/v1/users/ws-token in an \"Authorization: Bearer\" header; conversation is null unless they are " +
+	"a participant and, in a workspace's conversation, still a member of the workspace."
+
+// Routes describes the routes registered by RegisterGraphQLRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodGet, Path: "/graphql", ID: "graphqlGet", Tag: "GraphQL",
+		Summary:     "Execute a GraphQL query",
+		Description: conversationAuth,
+		Query: []openapi.Param{
+			{Name: "query", Required: true},
+			{Name: "operationName"},
+			{Name: "variables", Description: "JSON-encoded object"},
+		},
+		Response: Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/graphql", ID: "graphqlPost", Tag: "GraphQL",
+		Summary:     "Execute a GraphQL query",
+		Description: conversationAuth,
+		Body:        Request{},
+		Response:    Response{},
+	},
+}
@@ -0,0 +1,534 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
+
+// maxDepth bounds how deeply a query may nest object fields, so one request
+// cannot fan out without limit.
+const maxDepth = 8
+
+// Type is a GraphQL object type.
+type Type struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field is a field of an object type. Fields without Resolve or Batch read
+// the Go struct field of the same name, ignoring case.
+type Field struct {
+	Type *Type    // Object type of the value; nil for scalars
+	List bool     // The value is a slice of Type
+	Args []string // Accepted argument names
+
+	// Resolve computes the field for one parent.
+	Resolve func(ctx context.Context, parent any, args Args) (any, error)
+	// Batch computes the field for every parent at the same level at once,
+	// returning one value per parent. It replaces Resolve for fields that
+	// would otherwise cost a query per parent.
+	Batch func(ctx context.Context, parents []any, args Args) ([]any, error)
+}
+
+// Args holds a field's arguments with variables substituted.
+type Args map[string]any
+
+// String returns a string argument, or "" if it is absent.
+func (a Args) String(name string) (string, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		return "", inputErrorf("argument %s must be a string", name)
+	}
+}
+
+// Int returns an integer argument, or def if it is absent.
+func (a Args) Int(name string, def int) (int, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return v, nil
+	case float64: // Numbers in JSON variables
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, inputErrorf("argument %s must be an integer", name)
+}
+
+// Strings returns a list-of-strings argument; a single string is accepted as
+// a list of one, as GraphQL input coercion requires.
+func (a Args) Strings(name string) ([]string, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, inputErrorf("argument %s must be a list of strings", name)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	}
+	return nil, inputErrorf("argument %s must be a list of strings", name)
+}
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Error is a GraphQL error; Path locates the field that failed.
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Response is the result of executing a request.
+type Response struct {
+	Data   any     `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// object is a result object whose keys keep the order they were selected in.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]any)}
+}
+
+func (o *object) set(key string, v any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+// MarshalJSON writes the keys in selection order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Execute runs a query against the root type. Requests that fail to parse
+// or validate return only errors; field errors null the field and are
+// reported alongside the data.
+func Execute(ctx context.Context, root *Type, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	vars, err := op.variables(req.Variables)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{doc: doc, vars: vars}
+	results, err := e.execObjects(ctx, root, []any{nil}, op.sel, [][]any{nil}, 0)
+	if err != nil {
+		return &Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	return &Response{Data: results[0], Errors: e.errors}
+}
+
+// operation picks the operation to run.
+func (d *document) operation(name string) (*operation, error) {
+	var op *operation
+	switch {
+	case name != "":
+		for _, o := range d.operations {
+			if o.name == name {
+				op = o
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+	case len(d.operations) == 1:
+		op = d.operations[0]
+	default:
+		return nil, fmt.Errorf("operationName is required when the document has several operations")
+	}
+	if op.kind != "query" {
+		return nil, fmt.Errorf("only queries are supported, not %ss", op.kind)
+	}
+	return op, nil
+}
+
+// variables applies defaults and checks required variables are set.
+func (op *operation) variables(given map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(op.vars))
+	for _, def := range op.vars {
+		v, ok := given[def.name]
+		if !ok && def.defaultVal != nil {
+			resolved, err := def.defaultVal.resolve(nil)
+			if err != nil {
+				return nil, err
+			}
+			v = resolved
+		}
+		if v == nil && def.nonNull {
+			return nil, fmt.Errorf("variable $%s is required", def.name)
+		}
+		vars[def.name] = v
+	}
+	return vars, nil
+}
+
+// executor holds the state of one request.
+type executor struct {
+	doc    *document
+	vars   map[string]any
+	errors []Error
+}
+
+// fieldGroup is the selections sharing a response key.
+type fieldGroup struct {
+	key  string
+	sels []*selection
+}
+
+// collectFields flattens fragments into the fields selected on typ, merging
+// fields with the same response key.
+func (e *executor) collectFields(typ *Type, sels []*selection, groups []*fieldGroup, visited map[string]bool) ([]*fieldGroup, error) {
+	for _, sel := range sels {
+		include, err := e.included(sel.directives)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+
+		switch {
+		case sel.spread != "":
+			if visited[sel.spread] {
+				continue
+			}
+			visited[sel.spread] = true
+			frag, ok := e.doc.fragments[sel.spread]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %q", sel.spread)
+			}
+			if frag.on != typ.Name {
+				continue
+			}
+			if groups, err = e.collectFields(typ, frag.sel, groups, visited); err != nil {
+				return nil, err
+			}
+		case sel.inline:
+			if sel.on != "" && sel.on != typ.Name {
+				continue
+			}
+			if groups, err = e.collectFields(typ, sel.sel, groups, visited); err != nil {
+				return nil, err
+			}
+		default:
+			key := sel.responseKey()
+			var group *fieldGroup
+			for _, g := range groups {
+				if g.key == key {
+					group = g
+				}
+			}
+			if group == nil {
+				group = &fieldGroup{key: key}
+				groups = append(groups, group)
+			} else if group.sels[0].name != sel.name {
+				return nil, fmt.Errorf("fields %q and %q conflict on response key %q", group.sels[0].name, sel.name, key)
+			}
+			group.sels = append(group.sels, sel)
+		}
+	}
+	return groups, nil
+}
+
+// included evaluates @skip and @include.
+func (e *executor) included(dirs []directive) (bool, error) {
+	for _, dir := range dirs {
+		if dir.name != "skip" && dir.name != "include" {
+			return false, fmt.Errorf("unknown directive @%s", dir.name)
+		}
+		var cond any
+		for _, arg := range dir.args {
+			if arg.name == "if" {
+				v, err := arg.val.resolve(e.vars)
+				if err != nil {
+					return false, err
+				}
+				cond = v
+			}
+		}
+		b, ok := cond.(bool)
+		if !ok {
+			return false, fmt.Errorf("@%s requires a boolean if argument", dir.name)
+		}
+		if b == (dir.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// args resolves a field's arguments, rejecting unknown ones.
+func (e *executor) args(name string, field *Field, sel *selection) (Args, error) {
+	args := make(Args, len(sel.args))
+	for _, arg := range sel.args {
+		known := false
+		for _, a := range field.Args {
+			known = known || a == arg.name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown argument %q on field %q", arg.name, name)
+		}
+		v, err := arg.val.resolve(e.vars)
+		if err != nil {
+			return nil, err
+		}
+		args[arg.name] = v
+	}
+	return args, nil
+}
+
+// execObjects executes sels on every parent of typ at once, so each field is
+// resolved level by level across all parents and Batch resolvers see the
+// whole level. paths[i] is the response path of parents[i].
+func (e *executor) execObjects(ctx context.Context, typ *Type, parents []any, sels []*selection, paths [][]any, depth int) ([]*object, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("query is nested more than %d levels deep", maxDepth)
+	}
+	groups, err := e.collectFields(typ, sels, nil, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*object, len(parents))
+	for i := range results {
+		results[i] = newObject()
+	}
+	for _, group := range groups {
+		sel := group.sels[0]
+		if sel.name == "__typename" {
+			for _, result := range results {
+				result.set(group.key, typ.Name)
+			}
+			continue
+		}
+		field, ok := typ.Fields[sel.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q on type %s", sel.name, typ.Name)
+		}
+		if field.Type == nil && sel.sel != nil {
+			return nil, fmt.Errorf("field %q of type %s cannot have a selection set", sel.name, typ.Name)
+		}
+		if field.Type != nil && sel.sel == nil {
+			return nil, fmt.Errorf("field %q of type %s must have a selection set", sel.name, typ.Name)
+		}
+		args, err := e.args(sel.name, field, sel)
+		if err != nil {
+			return nil, err
+		}
+
+		fieldPaths := make([][]any, len(parents))
+		for i := range parents {
+			fieldPaths[i] = append(append([]any(nil), paths[i]...), group.key)
+		}
+		values := e.resolve(ctx, field, sel.name, parents, args, fieldPaths)
+
+		if field.Type != nil {
+			// Fields selected under every occurrence of the key are merged
+			var sub []*selection
+			for _, s := range group.sels {
+				sub = append(sub, s.sel...)
+			}
+			if values, err = e.complete(ctx, field, values, sub, fieldPaths, depth); err != nil {
+				return nil, err
+			}
+		}
+		for i, result := range results {
+			result.set(group.key, values[i])
+		}
+	}
+	return results, nil
+}
+
+// resolve computes a field for every parent. Failed fields are nil and
+// recorded as errors.
+func (e *executor) resolve(ctx context.Context, field *Field, name string, parents []any, args Args, paths [][]any) []any {
+	if field.Batch != nil {
+		values, err := field.Batch(ctx, parents, args)
+		if err == nil && len(values) != len(parents) {
+			err = fmt.Errorf("internal error resolving %s", name)
+		}
+		if err != nil {
+			e.fieldError(err, paths[0])
+			return make([]any, len(parents))
+		}
+		return values
+	}
+
+	values := make([]any, len(parents))
+	for i, parent := range parents {
+		var v any
+		var err error
+		if field.Resolve != nil {
+			v, err = field.Resolve(ctx, parent, args)
+		} else {
+			v = structField(parent, name)
+		}
+		if err != nil {
+			e.fieldError(err, paths[i])
+			continue
+		}
+		values[i] = v
+	}
+	return values
+}
+
+// inputError is a field error caused by the request, reported to the client verbatim.
+type inputError struct{ msg string }
+
+func (e *inputError) Error() string { return e.msg }
+
+func inputErrorf(format string, args ...any) error {
+	return &inputError{msg: fmt.Sprintf(format, args...)}
+}
+
+// fieldError records a failed field. Errors other than inputErrors are
+// logged and reported without detail, as the REST handlers do.
+func (e *executor) fieldError(err error, path []any) {
+	var input *inputError
+	if errors.As(err, &input) {
+		e.errors = append(e.errors, Error{Message: input.msg, Path: path})
+		return
+	}
+	log.Printf("[GraphQL] Error resolving %v: %v", path, err)
+	e.errors = append(e.errors, Error{Message: "Failed to resolve field", Path: path})
+}
+
+// complete executes the sub-selection on object values, flattening lists so
+// the next level is resolved across all of them together.
+func (e *executor) complete(ctx context.Context, field *Field, values []any, sels []*selection, paths [][]any, depth int) ([]any, error) {
+	type slot struct{ parent, index int } // index is -1 for non-list fields
+	var items []any
+	var itemPaths [][]any
+	var slots []slot
+	lists := make([][]any, len(values))
+	for i, v := range values {
+		if isNil(v) {
+			values[i] = nil
+			continue
+		}
+		if !field.List {
+			items = append(items, v)
+			itemPaths = append(itemPaths, paths[i])
+			slots = append(slots, slot{i, -1})
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		lists[i] = make([]any, rv.Len())
+		for j := 0; j < rv.Len(); j++ {
+			item := rv.Index(j).Interface()
+			if isNil(item) {
+				continue
+			}
+			items = append(items, item)
+			itemPaths = append(itemPaths, append(append([]any(nil), paths[i]...), j))
+			slots = append(slots, slot{i, j})
+		}
+	}
+
+	var results []*object
+	if len(items) > 0 {
+		var err error
+		if results, err = e.execObjects(ctx, field.Type, items, sels, itemPaths, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	for k, s := range slots {
+		if s.index < 0 {
+			values[s.parent] = results[k]
+		} else {
+			lists[s.parent][s.index] = results[k]
+		}
+	}
+	if field.List {
+		for i := range values {
+			if lists[i] != nil {
+				values[i] = lists[i]
+			}
+		}
+	}
+	return values, nil
+}
+
+// structField reads the field of a struct (or pointer to one) whose Go name
+// matches name, ignoring case.
+func structField(parent any, name string) any {
+	v := reflect.ValueOf(parent)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	f := v.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+	if !f.IsValid() {
+		return nil
+	}
+	return f.Interface()
+}
+
+// isNil reports whether v is nil or a nil pointer, slice, or map.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Package graphql serves a read-only GraphQL endpoint over scenes,
+// conversations, messages, and users, so clients can fetch nested data
+// (scene → participants → profiles) in one request instead of one REST call
+// per level. Nested references are resolved a whole level at a time, so a
+// query costs a query per level rather than per object.
+//
+// The endpoint executes queries only; mutations stay on the REST API, and
+// introspection is not supported.
+package graphql
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxRequestBytes bounds the size of a POSTed query.
+const maxRequestBytes = 1 << 20
+
+type GraphQLHandler struct {
+	Scenes     storage.SceneStore
+	DMs        storage.DMStore
+	Users      storage.UserStore
+	Workspaces storage.WorkspaceStore // Memberships deciding who sees workspace scenes and conversations
+	Hub        *ws.Hub                // Live activeUsers counts
+	Tokens     *ws.TokenSigner        // Identifies the caller of conversation queries
+}
+
+// Query executes a GraphQL request, either POSTed as JSON
+// ({"query", "operationName", "variables"}) or sent as GET query parameters
+// of the same names with variables JSON-encoded. Conversations are only
+// served to the caller identified by the token from login or
+// /api/v1/users/ws-token in an "Authorization: Bearer" header.
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				http.Error(w, "variables must be a JSON object", http.StatusBadRequest)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	// Without a valid token the caller is anonymous and sees no conversations
+	callerID, _ := h.Tokens.AuthenticateBearer(r)
+	res := Execute(r.Context(), h.schema(callerID), req)
+	if res.Data == nil && len(res.Errors) > 0 {
+		log.Printf("[GraphQL] Rejected query: %s", res.Errors[0].Message)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,19 @@
+package graphql
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterGraphQLRoutes registers the GraphQL endpoint with the provided ServeMux.
+func RegisterGraphQLRoutes(mux *http.ServeMux, handler *GraphQLHandler) {
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[GraphQL] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[GraphQL] %s %s", r.Method, r.URL.Path)
+		handler.Query(w, r)
+	})
+}
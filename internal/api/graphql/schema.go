@@ -0,0 +1,406 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// Query limits.
+const (
+	maxUsersPerQuery = 200
+	maxSearchLimit   = 50
+)
+
+// userLoader caches users for the duration of one request so every
+// reference to the same profile, at any depth, costs at most one lookup.
+type userLoader struct {
+	store storage.UserStore
+	cache map[string]*models.User // nil values record users that do not exist
+}
+
+// load returns the users with the given IDs, fetching the uncached ones in one query.
+func (l *userLoader) load(ctx context.Context, ids []string) (map[string]*models.User, error) {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if _, ok := l.cache[id]; !ok && !seen[id] && id != "" {
+			missing = append(missing, id)
+			seen[id] = true
+		}
+	}
+	if len(missing) > 0 {
+		users, err := l.store.GetUsers(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range missing {
+			l.cache[id] = users[id]
+		}
+	}
+	return l.cache, nil
+}
+
+// userBatch resolves a user reference for every parent in one lookup.
+func (l *userLoader) userBatch(idOf func(parent any) string) func(ctx context.Context, parents []any, args Args) ([]any, error) {
+	return func(ctx context.Context, parents []any, args Args) ([]any, error) {
+		ids := make([]string, len(parents))
+		for i, parent := range parents {
+			ids[i] = idOf(parent)
+		}
+		users, err := l.load(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, len(parents))
+		for i, id := range ids {
+			if user := users[id]; user != nil {
+				values[i] = user
+			}
+		}
+		return values, nil
+	}
+}
+
+// usersList returns the existing users among ids, in order.
+func usersList(users map[string]*models.User, ids []string) []*models.User {
+	list := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		if user := users[id]; user != nil {
+			list = append(list, user)
+		}
+	}
+	return list
+}
+
+// schema builds the root query type. It is built per request so resolvers
+// share that request's user cache and callerID, the user identified by the
+// request's bearer token or empty without one.
+func (h *GraphQLHandler) schema(callerID string) *Type {
+	loader := &userLoader{store: h.Users, cache: make(map[string]*models.User)}
+
+	user := &Type{Name: "User", Fields: map[string]*Field{
+		"id":          {},
+		"username":    {},
+		"displayName": {},
+		"avatarURL":   {},
+		"createdAt":   {},
+		"lastSeenAt":  {},
+	}}
+
+	reaction := &Type{Name: "Reaction", Fields: map[string]*Field{
+		"emoji": {},
+		"count": {},
+	}}
+
+	sceneMessage := &Type{Name: "SceneMessage", Fields: map[string]*Field{
+		"id":        {},
+		"sceneID":   {},
+		"senderID":  {},
+		"content":   {},
+		"createdAt": {},
+		"sender": {Type: user, Batch: loader.userBatch(func(parent any) string {
+			return parent.(models.SceneMessage).SenderID
+		})},
+		"reactions": {Type: reaction, List: true},
+	}}
+
+	scene := &Type{Name: "Scene", Fields: map[string]*Field{
+		"id":            {},
+		"name":          {},
+		"artistName":    {},
+		"description":   {},
+		"coverImageURL": {},
+		"tags":          {},
+		"creatorID":     {},
+		"listeners":     {},
+		"createdAt":     {},
+		"updatedAt":     {},
+		"archivedAt":    {},
+		"status":        {},
+		"scheduledAt":   {},
+		"rsvpCount":     {},
+		"workspaceID":   {},
+		"activeUsers": {Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			return h.Hub.GetActiveSceneUsersCount(parent.(*models.Scene).ID), nil
+		}},
+		"creator": {Type: user, Batch: loader.userBatch(func(parent any) string {
+			return parent.(*models.Scene).CreatorID
+		})},
+		"participants": {Type: user, List: true, Batch: func(ctx context.Context, parents []any, args Args) ([]any, error) {
+			sceneIDs := make([]string, len(parents))
+			for i, parent := range parents {
+				sceneIDs[i] = parent.(*models.Scene).ID
+			}
+			participants, err := h.Scenes.GetSceneParticipants(ctx, sceneIDs)
+			if err != nil {
+				return nil, err
+			}
+			var userIDs []string
+			for _, ids := range participants {
+				userIDs = append(userIDs, ids...)
+			}
+			users, err := loader.load(ctx, userIDs)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]any, len(parents))
+			for i, sceneID := range sceneIDs {
+				values[i] = usersList(users, participants[sceneID])
+			}
+			return values, nil
+		}},
+		// The most recent messages, oldest first
+		"messages": {Type: sceneMessage, List: true, Args: []string{"limit"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			limit, err := pageLimit(args)
+			if err != nil {
+				return nil, err
+			}
+			msgs, err := h.Scenes.GetSceneMessages(ctx, parent.(*models.Scene).ID)
+			if err != nil {
+				return nil, err
+			}
+			return msgs[max(0, len(msgs)-limit):], nil
+		}},
+	}}
+
+	dmMessage := &Type{Name: "DMMessage", Fields: map[string]*Field{
+		"id": {},
+		"conversationID": {Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			return parent.(models.DMMessage).DMConversationID, nil
+		}},
+		"senderID":        {},
+		"content":         {},
+		"timestamp":       {},
+		"parentMessageID": {},
+		"replyCount":      {},
+		"editedAt":        {},
+		"deletedAt":       {},
+		"sender": {Type: user, Batch: loader.userBatch(func(parent any) string {
+			return parent.(models.DMMessage).SenderID
+		})},
+		"reactions": {Type: reaction, List: true},
+	}}
+
+	conversation := &Type{Name: "Conversation", Fields: map[string]*Field{
+		"id":          {},
+		"name":        {},
+		"isGroup":     {},
+		"workspaceID": {},
+		"unreadCount": {},
+		"createdAt":   {},
+		"updatedAt":   {},
+		"participantIDs": {Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			return parent.(*models.DMConversation).Participants, nil
+		}},
+		// Pass as conversations(cursor:) to list the conversations after this one
+		"cursor": {Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			return storage.ConversationCursor(parent.(*models.DMConversation)), nil
+		}},
+		"participants": {Type: user, List: true, Batch: func(ctx context.Context, parents []any, args Args) ([]any, error) {
+			var userIDs []string
+			for _, parent := range parents {
+				userIDs = append(userIDs, parent.(*models.DMConversation).Participants...)
+			}
+			users, err := loader.load(ctx, userIDs)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]any, len(parents))
+			for i, parent := range parents {
+				values[i] = usersList(users, parent.(*models.DMConversation).Participants)
+			}
+			return values, nil
+		}},
+		// A page of history, oldest first; see DMStore.GetMessages
+		"messages": {Type: dmMessage, List: true, Args: []string{"limit", "before", "after"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			limit, err := pageLimit(args)
+			if err != nil {
+				return nil, err
+			}
+			page := storage.MessagePage{Limit: limit}
+			if page.Before, err = args.String("before"); err != nil {
+				return nil, err
+			}
+			if page.After, err = args.String("after"); err != nil {
+				return nil, err
+			}
+			if page.Before != "" && page.After != "" {
+				return nil, inputErrorf("only one of before and after may be set")
+			}
+			return h.DMs.GetMessages(ctx, parent.(*models.DMConversation).ID, page)
+		}},
+	}}
+
+	return &Type{Name: "Query", Fields: map[string]*Field{
+		// Workspace scenes are null unless userID is a member
+		"scene": {Type: scene, Args: []string{"id", "userID"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			id, err := requiredString(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			userID, err := args.String("userID")
+			if err != nil {
+				return nil, err
+			}
+			sc, err := h.Scenes.GetScene(ctx, id)
+			if err == nil {
+				var visible bool
+				visible, err = storage.SceneVisible(ctx, h.Workspaces, sc, userID)
+				if err == nil && !visible {
+					err = storage.ErrNotFound
+				}
+			}
+			return notFoundAsNil(sc, err)
+		}},
+		// Scenes the user has joined in workspaceID, or the public ones without it
+		"scenes": {Type: scene, List: true, Args: []string{"userID", "workspaceID"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			userID, err := requiredString(args, "userID")
+			if err != nil {
+				return nil, err
+			}
+			workspaceID, err := args.String("workspaceID")
+			if err != nil {
+				return nil, err
+			}
+			return h.Scenes.GetScenesForUser(ctx, userID, workspaceID)
+		}},
+		// Public scenes only
+		"searchScenes": {Type: scene, List: true, Args: []string{"query", "limit", "offset"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			query, err := requiredString(args, "query")
+			if err != nil {
+				return nil, err
+			}
+			limit, err := args.Int("limit", 20)
+			if err != nil || limit <= 0 {
+				return nil, inputErrorf("limit must be a positive integer")
+			}
+			offset, err := args.Int("offset", 0)
+			if err != nil || offset < 0 {
+				return nil, inputErrorf("offset must be a non-negative integer")
+			}
+			return h.Scenes.SearchScenes(ctx, "", query, min(limit, maxSearchLimit), offset)
+		}},
+		"user": {Type: user, Args: []string{"id"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			id, err := requiredString(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			users, err := loader.load(ctx, []string{id})
+			if err != nil {
+				return nil, err
+			}
+			return users[id], nil
+		}},
+		"users": {Type: user, List: true, Args: []string{"ids"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			ids, err := args.Strings("ids")
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) > maxUsersPerQuery {
+				return nil, inputErrorf("at most %d users can be requested at once", maxUsersPerQuery)
+			}
+			users, err := loader.load(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			return usersList(users, ids), nil
+		}},
+		// A page of the caller's conversations in workspaceID, or outside any
+		// workspace without it, most recently active first
+		"conversations": {Type: conversation, List: true, Args: []string{"userID", "workspaceID", "limit", "cursor"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			userID, err := conversationCaller(callerID, args)
+			if err != nil {
+				return nil, err
+			}
+			limit, err := pageLimit(args)
+			if err != nil {
+				return nil, err
+			}
+			page := storage.ConversationPage{Limit: limit}
+			if page.Cursor, err = args.String("cursor"); err != nil {
+				return nil, err
+			}
+			if page.WorkspaceID, err = args.String("workspaceID"); err != nil {
+				return nil, err
+			}
+			convs, err := h.DMs.GetConversations(ctx, userID, page)
+			if errors.Is(err, storage.ErrInvalidCursor) {
+				return nil, inputErrorf("invalid cursor")
+			}
+			return convs, err
+		}},
+		// Null unless the caller may read it, see storage.ConversationVisible
+		"conversation": {Type: conversation, Args: []string{"id", "userID"}, Resolve: func(ctx context.Context, parent any, args Args) (any, error) {
+			id, err := requiredString(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			userID, err := conversationCaller(callerID, args)
+			if err != nil {
+				return nil, err
+			}
+			conv, err := h.DMs.GetConversation(ctx, id)
+			if err == nil {
+				var visible bool
+				visible, err = storage.ConversationVisible(ctx, h.Workspaces, conv, userID)
+				if err == nil && !visible {
+					err = storage.ErrNotFound
+				}
+			}
+			return notFoundAsNil(conv, err)
+		}},
+	}}
+}
+
+// conversationCaller returns the user conversation queries are answered for:
+// the caller, who must have a token, and whom the optional userID argument
+// must name.
+func conversationCaller(callerID string, args Args) (string, error) {
+	userID, err := args.String("userID")
+	if err != nil {
+		return "", err
+	}
+	if callerID == "" {
+		return "", inputErrorf("conversations require a bearer token")
+	}
+	if userID != "" && userID != callerID {
+		return "", inputErrorf("token was issued to another user")
+	}
+	return callerID, nil
+}
+
+// requiredString returns a non-empty string argument.
+func requiredString(args Args, name string) (string, error) {
+	s, err := args.String(name)
+	if err != nil {
+		return "", err
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		return "", inputErrorf("argument %s is required", name)
+	}
+	return s, nil
+}
+
+// pageLimit reads a limit argument, clamped like REST page sizes.
+func pageLimit(args Args) (int, error) {
+	limit, err := args.Int("limit", storage.DefaultPageLimit)
+	if err != nil || limit <= 0 {
+		return 0, inputErrorf("limit must be a positive integer")
+	}
+	return min(limit, storage.MaxPageLimit), nil
+}
+
+// notFoundAsNil turns storage.ErrNotFound into a null result.
+func notFoundAsNil[T any](v *T, err error) (any, error) {
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,12 @@
+//go:build go1.24
+
+package grpc
+
+import "net/http"
+
+// enableCleartextHTTP2 makes server accept HTTP/2 without TLS.
+func enableCleartextHTTP2(server *http.Server) error {
+	server.Protocols = new(http.Protocols)
+	server.Protocols.SetUnencryptedHTTP2(true)
+	return nil
+}
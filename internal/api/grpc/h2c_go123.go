@@ -0,0 +1,13 @@
+//go:build !go1.24
+
+package grpc
+
+import (
+	"errors"
+	"net/http"
+)
+
+// enableCleartextHTTP2 fails: net/http only serves HTTP/2 without TLS from Go 1.24.
+func enableCleartextHTTP2(server *http.Server) error {
+	return errors.New("serving gRPC without TLS requires Go 1.24 or later; configure a TLS certificate instead")
+}
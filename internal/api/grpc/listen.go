@@ -0,0 +1,16 @@
+package grpc
+
+import "net/http"
+
+// NewHTTPServer returns an HTTP/2 server for s on addr. Servers started with
+// ListenAndServeTLS negotiate HTTP/2 themselves; without TLS, cleartext
+// HTTP/2 (h2c) is enabled, which gRPC clients use for insecure connections.
+func NewHTTPServer(addr string, s *Server, useTLS bool) (*http.Server, error) {
+	server := &http.Server{Addr: addr, Handler: s}
+	if !useTLS {
+		if err := enableCleartextHTTP2(server); err != nil {
+			return nil, err
+		}
+	}
+	return server, nil
+}
@@ -0,0 +1,457 @@
+package grpc
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// protoDir holds the .proto files the hand-written encoders must match.
+const protoDir = "../../../proto/scenyx/v1"
+
+// protoField is a field declared in a .proto message.
+type protoField struct {
+	name     string
+	typ      string
+	repeated bool
+}
+
+// protoFile is what the tests need of the .proto files: each message's
+// fields by number, and each RPC's request and response message.
+type protoFile struct {
+	messages map[string]map[int]protoField
+	rpcs     map[string][2]string // Method path -> request, response
+}
+
+var (
+	protoComment = regexp.MustCompile(`//[^\n]*`)
+	protoMessage = regexp.MustCompile(`message\s+(\w+)\s*\{([^}]*)\}`)
+	protoFieldRe = regexp.MustCompile(`(repeated\s+)?([\w.]+)\s+(\w+)\s*=\s*(\d+)\s*;`)
+	protoService = regexp.MustCompile(`service\s+(\w+)\s*\{((?:[^{}]|\{\s*\})*)\}`)
+	protoRPC     = regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*([\w.]+)\s*\)\s*returns\s*\(\s*([\w.]+)\s*\)`)
+)
+
+// parseProtos reads the messages and services of every .proto file in dir.
+func parseProtos(t *testing.T, dir string) *protoFile {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.proto"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no .proto files in %s: %v", dir, err)
+	}
+
+	p := &protoFile{
+		messages: map[string]map[int]protoField{
+			"google.protobuf.Timestamp": {1: {name: "seconds", typ: "int64"}, 2: {name: "nanos", typ: "int32"}},
+		},
+		rpcs: make(map[string][2]string),
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := protoComment.ReplaceAllString(string(src), "")
+		for _, m := range protoMessage.FindAllStringSubmatch(text, -1) {
+			fields := make(map[int]protoField)
+			for _, f := range protoFieldRe.FindAllStringSubmatch(m[2], -1) {
+				number, _ := strconv.Atoi(f[4])
+				if _, dup := fields[number]; dup {
+					t.Fatalf("%s: field number %d used twice", m[1], number)
+				}
+				fields[number] = protoField{name: f[3], typ: f[2], repeated: f[1] != ""}
+			}
+			p.messages[m[1]] = fields
+		}
+		for _, svc := range protoService.FindAllStringSubmatch(text, -1) {
+			for _, rpc := range protoRPC.FindAllStringSubmatch(svc[2], -1) {
+				p.rpcs["/scenyx.v1."+svc[1]+"/"+rpc[1]] = [2]string{rpc[2], rpc[3]}
+			}
+		}
+	}
+	return p
+}
+
+// encode writes a message from values keyed by field name, as a client
+// generated from the .proto would.
+func (p *protoFile) encode(t *testing.T, msg string, values map[string]any) []byte {
+	t.Helper()
+	numbers := make(map[string]int)
+	for number, f := range p.messages[msg] {
+		numbers[f.name] = number
+	}
+	var e encoder
+	for name, v := range values {
+		number, ok := numbers[name]
+		if !ok {
+			t.Fatalf("%s has no field %s", msg, name)
+		}
+		switch v := v.(type) {
+		case string:
+			e.string(number, v)
+		case int:
+			e.int(number, int64(v))
+		default:
+			t.Fatalf("%s.%s: unsupported request value %T", msg, name, v)
+		}
+	}
+	return e.buf
+}
+
+// decode reads a message by its .proto definition into values keyed by field
+// name: strings, int64s, bools, float64s, time.Times for timestamps, nested
+// maps for messages, and slices for repeated fields. Unset fields are
+// absent. seen collects the messages decoded.
+func (p *protoFile) decode(t *testing.T, msg string, b []byte, seen map[string]bool) map[string]any {
+	t.Helper()
+	seen[msg] = true
+	fields := p.messages[msg]
+	values := make(map[string]any)
+	d := &decoder{buf: b}
+	for {
+		number, wireType, ok, err := d.next()
+		if err != nil {
+			t.Fatalf("%s: %v", msg, err)
+		}
+		if !ok {
+			return values
+		}
+		f, ok := fields[number]
+		if !ok {
+			t.Fatalf("%s: field %d is not in the .proto", msg, number)
+		}
+
+		var v any
+		switch f.typ {
+		case "string":
+			v, err = d.string(wireType)
+		case "int32":
+			var n uint64
+			n, err = d.varint(wireType)
+			v = int64(int32(n))
+		case "int64":
+			var n uint64
+			n, err = d.varint(wireType)
+			v = int64(n)
+		case "bool":
+			var n uint64
+			n, err = d.varint(wireType)
+			v = n != 0
+		case "double":
+			if wireType != wireFixed64 || len(d.buf) < 8 {
+				t.Fatalf("%s.%s: want an 8-byte fixed64 field, got wire type %d", msg, f.name, wireType)
+			}
+			v = math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+			d.buf = d.buf[8:]
+		default:
+			if _, ok := p.messages[f.typ]; !ok {
+				t.Fatalf("%s.%s: unknown type %s", msg, f.name, f.typ)
+			}
+			var sub []byte
+			sub, err = d.bytes(wireType)
+			nested := p.decode(t, f.typ, sub, seen)
+			if f.typ == "google.protobuf.Timestamp" {
+				seconds, _ := nested["seconds"].(int64)
+				nanos, _ := nested["nanos"].(int64)
+				v = time.Unix(seconds, nanos).UTC()
+			} else {
+				v = nested
+			}
+		}
+		if err != nil {
+			t.Fatalf("%s.%s: %v", msg, f.name, err)
+		}
+
+		if f.repeated {
+			list, _ := values[f.name].([]any)
+			values[f.name] = append(list, v)
+		} else {
+			values[f.name] = v
+		}
+	}
+}
+
+// Fixtures returned by the fake stores when called with the arguments the
+// test requests carry. Any other arguments get nothing back, so a service
+// reading the wrong field number fails the comparison.
+var (
+	t0 = time.Date(2026, 3, 4, 5, 6, 7, 8, time.UTC)
+	t1 = t0.Add(time.Hour)
+	t2 = t0.Add(2 * time.Hour)
+
+	testScene = &models.Scene{
+		ID: "scene-1", Name: "Late Night", ArtistName: "Nova", Description: "Slow jams",
+		CoverImageURL: "https://example.com/cover.png", Tags: []string{"jazz", ""}, CreatorID: "user-1",
+		Listeners: 12, CreatedAt: t0, UpdatedAt: t1, ArchivedAt: &t2, Status: models.SceneScheduled,
+		ScheduledAt: &t1, RSVPCount: 3, RequiresApproval: true, SkipThreshold: 0.5, WorkspaceID: "ws-1",
+	}
+	testSceneMessages = []models.SceneMessage{
+		{ID: "sm-1", SceneID: "scene-1", SenderID: "user-2", Content: "first", CreatedAt: t0},
+		{ID: "sm-2", SceneID: "scene-1", SenderID: "user-1", Content: "hello", CreatedAt: t1,
+			Reactions: []models.ReactionCount{{Emoji: "🔥", Count: 2}, {Emoji: "👍", Count: 1}}},
+	}
+	testParent       = "dm-msg-0"
+	testConversation = &models.DMConversation{
+		ID: "dm-1", Name: "Crew", IsGroup: true, Participants: []string{"user-1", "user-2"},
+		UnreadCount: 4, CreatedAt: t0, UpdatedAt: t1, WorkspaceID: "ws-1",
+	}
+	testDMMessage = models.DMMessage{
+		ID: "dm-msg-1", DMConversationID: "dm-1", SenderID: "user-2", Content: "hey", Timestamp: t0,
+		ParentMessageID: &testParent, ReplyCount: 2, EditedAt: &t1, DeletedAt: &t2,
+		Reactions: []models.ReactionCount{{Emoji: "🎉", Count: 5}},
+	}
+)
+
+type fakeScenes struct {
+	storage.SceneStore
+}
+
+func (fakeScenes) GetScene(ctx context.Context, sceneID string) (*models.Scene, error) {
+	if sceneID != testScene.ID {
+		return nil, storage.ErrNotFound
+	}
+	return testScene, nil
+}
+
+func (fakeScenes) GetScenesForUser(ctx context.Context, userID, workspaceID string) ([]*models.Scene, error) {
+	if userID != "user-1" || workspaceID != "ws-1" {
+		return nil, nil
+	}
+	return []*models.Scene{testScene}, nil
+}
+
+func (fakeScenes) SearchScenes(ctx context.Context, workspaceID, query string, limit, offset int) ([]*models.Scene, error) {
+	if workspaceID != "" || query != "jams" || limit != 5 || offset != 2 {
+		return nil, nil
+	}
+	return []*models.Scene{testScene}, nil
+}
+
+func (fakeScenes) GetSceneParticipants(ctx context.Context, sceneIDs []string) (map[string][]string, error) {
+	return map[string][]string{testScene.ID: {"user-1", "user-2"}}, nil
+}
+
+func (fakeScenes) GetSceneMessages(ctx context.Context, sceneID string) ([]models.SceneMessage, error) {
+	if sceneID != testScene.ID {
+		return nil, nil
+	}
+	return testSceneMessages, nil
+}
+
+type fakeDMs struct {
+	storage.DMStore
+}
+
+func (fakeDMs) GetConversation(ctx context.Context, dmID string) (*models.DMConversation, error) {
+	if dmID != testConversation.ID {
+		return nil, storage.ErrNotFound
+	}
+	return testConversation, nil
+}
+
+func (fakeDMs) GetConversations(ctx context.Context, userID string, page storage.ConversationPage) ([]*models.DMConversation, error) {
+	want := storage.ConversationPage{Limit: 1, Cursor: "cursor-1", WorkspaceID: "ws-1"}
+	if userID != "user-1" || page != want {
+		return nil, nil
+	}
+	return []*models.DMConversation{testConversation}, nil
+}
+
+func (fakeDMs) GetMessages(ctx context.Context, dmID string, page storage.MessagePage) ([]models.DMMessage, error) {
+	if dmID != testConversation.ID || page.Limit != 3 || (page.Before != "dm-msg-9") == (page.After != "dm-msg-0") {
+		return nil, nil
+	}
+	return []models.DMMessage{testDMMessage}, nil
+}
+
+type fakeWorkspaces struct {
+	storage.WorkspaceStore
+}
+
+func (fakeWorkspaces) GetMemberRole(ctx context.Context, workspaceID, userID string) (models.WorkspaceRole, error) {
+	if workspaceID != "ws-1" || userID != "user-1" {
+		return "", storage.ErrNotFound
+	}
+	return models.WorkspaceMember, nil
+}
+
+// Expected decodings of the fixtures, keyed by .proto field name.
+var (
+	wantScene = map[string]any{
+		"id": "scene-1", "name": "Late Night", "artist_name": "Nova", "description": "Slow jams",
+		"cover_image_url": "https://example.com/cover.png", "tags": []any{"jazz", ""}, "creator_id": "user-1",
+		"listeners": int64(12), "created_at": t0, "updated_at": t1, "archived_at": t2, "status": "scheduled",
+		"scheduled_at": t1, "rsvp_count": int64(3), "requires_approval": true, "skip_threshold": 0.5,
+		"workspace_id": "ws-1",
+	}
+	wantSceneMessage = map[string]any{
+		"id": "sm-2", "scene_id": "scene-1", "sender_id": "user-1", "content": "hello", "created_at": t1,
+		"reactions": []any{
+			map[string]any{"emoji": "🔥", "count": int64(2)},
+			map[string]any{"emoji": "👍", "count": int64(1)},
+		},
+	}
+	wantConversation = map[string]any{
+		"id": "dm-1", "name": "Crew", "is_group": true, "participant_ids": []any{"user-1", "user-2"},
+		"unread_count": int64(4), "created_at": t0, "updated_at": t1, "workspace_id": "ws-1",
+	}
+	wantDMMessage = map[string]any{
+		"id": "dm-msg-1", "conversation_id": "dm-1", "sender_id": "user-2", "content": "hey", "timestamp": t0,
+		"parent_message_id": "dm-msg-0", "reply_count": int64(2), "edited_at": t1, "deleted_at": t2,
+		"reactions": []any{map[string]any{"emoji": "🎉", "count": int64(5)}},
+	}
+)
+
+func TestMessagesRoundTrip(t *testing.T) {
+	protos := parseProtos(t, protoDir)
+	s := &Server{Scenes: fakeScenes{}, DMs: fakeDMs{}, Workspaces: fakeWorkspaces{}, Hub: ws.NewHub()}
+	methods := s.methods()
+
+	tests := []struct {
+		path string
+		req  map[string]any
+		want map[string]any
+	}{
+		{
+			path: "/scenyx.v1.SceneService/GetScene",
+			req:  map[string]any{"scene_id": "scene-1", "user_id": "user-1"},
+			want: wantScene,
+		},
+		{
+			path: "/scenyx.v1.SceneService/ListUserScenes",
+			req:  map[string]any{"user_id": "user-1", "workspace_id": "ws-1"},
+			want: map[string]any{"scenes": []any{wantScene}},
+		},
+		{
+			path: "/scenyx.v1.SceneService/SearchScenes",
+			req:  map[string]any{"query": "jams", "limit": 5, "offset": 2},
+			want: map[string]any{"scenes": []any{wantScene}},
+		},
+		{
+			path: "/scenyx.v1.SceneService/ListParticipants",
+			req:  map[string]any{"scene_id": "scene-1", "user_id": "user-1"},
+			want: map[string]any{"user_ids": []any{"user-1", "user-2"}},
+		},
+		{
+			path: "/scenyx.v1.SceneService/ListMessages",
+			req:  map[string]any{"scene_id": "scene-1", "limit": 1, "user_id": "user-1"},
+			want: map[string]any{"messages": []any{wantSceneMessage}},
+		},
+		{
+			path: "/scenyx.v1.DMService/ListConversations",
+			req:  map[string]any{"user_id": "user-1", "page_size": 1, "page_token": "cursor-1", "workspace_id": "ws-1"},
+			want: map[string]any{
+				"conversations":   []any{wantConversation},
+				"next_page_token": storage.ConversationCursor(testConversation),
+			},
+		},
+		{
+			path: "/scenyx.v1.DMService/GetConversation",
+			req:  map[string]any{"conversation_id": "dm-1", "user_id": "user-1"},
+			want: wantConversation,
+		},
+		{
+			path: "/scenyx.v1.DMService/ListMessages",
+			req:  map[string]any{"conversation_id": "dm-1", "limit": 3, "before": "dm-msg-9", "user_id": "user-1"},
+			want: map[string]any{"messages": []any{wantDMMessage}},
+		},
+		{
+			path: "/scenyx.v1.DMService/ListMessages",
+			req:  map[string]any{"conversation_id": "dm-1", "limit": 3, "after": "dm-msg-0", "user_id": "user-1"},
+			want: map[string]any{"messages": []any{wantDMMessage}},
+		},
+	}
+
+	seen := make(map[string]bool)
+	tested := make(map[string]bool)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			types, ok := protos.rpcs[tt.path]
+			if !ok {
+				t.Fatalf("%s is not declared in the .proto files", tt.path)
+			}
+			handle, ok := methods[tt.path]
+			if !ok {
+				t.Fatalf("%s is not served", tt.path)
+			}
+			tested[tt.path] = true
+
+			seen[types[0]] = true
+			resp, err := handle(context.Background(), decodeMust(t, protos.encode(t, types[0], tt.req)))
+			if err != nil {
+				t.Fatalf("%s: %v", tt.path, err)
+			}
+			got := protos.decode(t, types[1], resp, seen)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s response:\n got %v\nwant %v", types[1], got, tt.want)
+			}
+		})
+	}
+
+	for path := range methods {
+		if _, ok := protos.rpcs[path]; !ok {
+			t.Errorf("%s is served but not declared in the .proto files", path)
+		}
+	}
+	for path := range protos.rpcs {
+		if !tested[path] {
+			t.Errorf("%s has no round-trip test", path)
+		}
+	}
+	for msg := range protos.messages {
+		if !seen[msg] {
+			t.Errorf("message %s is not covered by a round-trip test", msg)
+		}
+	}
+}
+
+// decodeMust decodes a request message, failing the test on error.
+func decodeMust(t *testing.T, b []byte) *request {
+	t.Helper()
+	req, err := decodeRequest(b)
+	if err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	return req
+}
+
+func TestDecodeRequestSkipsUnknownFields(t *testing.T) {
+	var e encoder
+	e.string(1, "scene-1")
+	e.double(9, 1.5)
+	e.message(10, func(m *encoder) { m.string(1, "nested") })
+	e.int(2, 7)
+	e.string(1, "scene-2") // The last value of a field wins
+
+	req := decodeMust(t, e.buf)
+	if req.strings[1] != "scene-2" || req.ints[2] != 7 {
+		t.Errorf("decodeRequest = strings %v, ints %v; want field 1 %q and field 2 7", req.strings, req.ints, "scene-2")
+	}
+
+	for _, truncated := range [][]byte{e.buf[:len(e.buf)-1], {0x0a, 0x05, 'a'}, {0x80}} {
+		if _, err := decodeRequest(truncated); err == nil {
+			t.Errorf("decodeRequest(%x) succeeded; want an error", truncated)
+		}
+	}
+}
+
+func TestEncodeNegativeInt(t *testing.T) {
+	var e encoder
+	e.int(1, -1)
+	d := &decoder{buf: e.buf}
+	field, wireType, _, _ := d.next()
+	v, err := d.int32(wireType)
+	if field != 1 || err != nil || v != -1 || len(e.buf) != 11 {
+		t.Errorf("int(1, -1) = %x; decoded field %d value %d err %v, want a ten-byte varint of -1", e.buf, field, v, err)
+	}
+}
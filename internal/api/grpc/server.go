@@ -0,0 +1,202 @@
+// Package grpc serves the read-only SceneService and DMService defined in
+// proto/scenyx/v1 for typed clients and server-to-server integrations.
+//
+// The gRPC protocol (length-prefixed messages over HTTP/2 with the status in
+// trailers) is implemented on net/http, and messages are encoded by hand
+// (see wire.go and messages.go), so the package needs no generated code.
+// Compressed messages are not supported.
+package grpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxMessageBytes bounds a request message, matching gRPC's default.
+const maxMessageBytes = 4 << 20
+
+// Code is a gRPC status code.
+type Code int
+
+// Status codes used by the services.
+const (
+	CodeOK                Code = 0
+	CodeInvalidArgument   Code = 3
+	CodeDeadlineExceeded  Code = 4
+	CodeNotFound          Code = 5
+	CodeResourceExhausted Code = 8
+	CodeUnimplemented     Code = 12
+	CodeInternal          Code = 13
+)
+
+// statusError is an RPC failure with the status to report.
+type statusError struct {
+	code Code
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+func statusf(code Code, format string, args ...any) error {
+	return &statusError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// method handles one RPC: it decodes the request and returns the encoded response.
+type method func(ctx context.Context, req *request) ([]byte, error)
+
+// Server implements the services over the stores.
+type Server struct {
+	Scenes     storage.SceneStore
+	DMs        storage.DMStore
+	Workspaces storage.WorkspaceStore // Memberships deciding who sees workspace scenes and conversations
+	Hub        *ws.Hub                // Live activeUsers counts
+}
+
+// methods maps each RPC's path to its handler.
+func (s *Server) methods() map[string]method {
+	return map[string]method{
+		"/scenyx.v1.SceneService/GetScene":         s.getScene,
+		"/scenyx.v1.SceneService/ListUserScenes":   s.listUserScenes,
+		"/scenyx.v1.SceneService/SearchScenes":     s.searchScenes,
+		"/scenyx.v1.SceneService/ListParticipants": s.listParticipants,
+		"/scenyx.v1.SceneService/ListMessages":     s.listSceneMessages,
+		"/scenyx.v1.DMService/ListConversations":   s.listConversations,
+		"/scenyx.v1.DMService/GetConversation":     s.getConversation,
+		"/scenyx.v1.DMService/ListMessages":        s.listDMMessages,
+	}
+}
+
+// ServeHTTP handles one unary RPC.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		log.Printf("[gRPC] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/grpc" && !strings.HasPrefix(ct, "application/grpc+proto") {
+		http.Error(w, "Content-Type must be application/grpc", http.StatusUnsupportedMediaType)
+		return
+	}
+	log.Printf("[gRPC] %s", r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/grpc")
+	resp, err := s.call(r)
+	if err != nil {
+		// A "trailers-only" response: the status goes in the headers
+		writeStatus(w, "", err)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	frame := make([]byte, 5, 5+len(resp))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(resp)))
+	w.Write(append(frame, resp...))
+	writeStatus(w, http.TrailerPrefix, nil)
+}
+
+// call reads the request message and runs the RPC.
+func (s *Server) call(r *http.Request) ([]byte, error) {
+	handle, ok := s.methods()[r.URL.Path]
+	if !ok {
+		return nil, statusf(CodeUnimplemented, "unknown method %s", r.URL.Path)
+	}
+
+	ctx := r.Context()
+	if v := r.Header.Get("Grpc-Timeout"); v != "" {
+		timeout, err := parseTimeout(v)
+		if err != nil {
+			return nil, statusf(CodeInvalidArgument, "%v", err)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var header [5]byte
+	if _, err := io.ReadFull(r.Body, header[:]); err != nil {
+		return nil, statusf(CodeInvalidArgument, "missing request message")
+	}
+	if header[0] != 0 {
+		return nil, statusf(CodeUnimplemented, "compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxMessageBytes {
+		return nil, statusf(CodeResourceExhausted, "request message is larger than %d bytes", maxMessageBytes)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r.Body, body); err != nil {
+		return nil, statusf(CodeInvalidArgument, "truncated request message")
+	}
+	req, err := decodeRequest(body)
+	if err != nil {
+		return nil, statusf(CodeInvalidArgument, "%v", err)
+	}
+
+	resp, err := handle(ctx, req)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, statusf(CodeDeadlineExceeded, "deadline exceeded")
+	}
+	var status *statusError
+	if err != nil && !errors.As(err, &status) {
+		log.Printf("[gRPC] Error in %s: %v", r.URL.Path, err)
+		return nil, statusf(CodeInternal, "internal error")
+	}
+	return resp, err
+}
+
+// writeStatus reports the RPC's outcome in the headers, or in the trailers
+// when prefix is http.TrailerPrefix.
+func writeStatus(w http.ResponseWriter, prefix string, err error) {
+	code, msg := CodeOK, ""
+	var status *statusError
+	if errors.As(err, &status) {
+		code, msg = status.code, status.msg
+	}
+	w.Header().Set(prefix+"Grpc-Status", strconv.Itoa(int(code)))
+	if msg != "" {
+		w.Header().Set(prefix+"Grpc-Message", encodeMessage(msg))
+	}
+}
+
+// encodeMessage percent-encodes a status message as the gRPC spec requires.
+func encodeMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// parseTimeout parses a grpc-timeout header such as "500m" (milliseconds).
+func parseTimeout(v string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	if len(v) < 2 || len(v) > 9 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", v)
+	}
+	unit, ok := units[v[len(v)-1]]
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid grpc-timeout %q", v)
+	}
+	return time.Duration(n) * unit, nil
+}
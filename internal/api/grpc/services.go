@@ -0,0 +1,245 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// Limits shared with the HTTP API.
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+// requireString returns a non-empty string field of req.
+func requireString(req *request, field int, name string) (string, error) {
+	s := req.strings[field]
+	if s == "" {
+		return "", statusf(CodeInvalidArgument, "%s is required", name)
+	}
+	return s, nil
+}
+
+// pageLimit reads a limit field, clamped like REST page sizes.
+func pageLimit(req *request, field int) (int, error) {
+	limit := req.ints[field]
+	if limit < 0 {
+		return 0, statusf(CodeInvalidArgument, "limit must not be negative")
+	}
+	return storage.MessagePage{Limit: limit}.NormalizedLimit(), nil
+}
+
+// notFound maps storage.ErrNotFound to a NOT_FOUND status.
+func notFound(err error, what, id string) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return statusf(CodeNotFound, "%s %s not found", what, id)
+	}
+	return err
+}
+
+// visibleScene loads a scene for userID, the caller. Workspace scenes are
+// reported not found to anyone but members.
+func (s *Server) visibleScene(ctx context.Context, sceneID, userID string) (*models.Scene, error) {
+	scene, err := s.Scenes.GetScene(ctx, sceneID)
+	if err == nil {
+		var visible bool
+		visible, err = storage.SceneVisible(ctx, s.Workspaces, scene, userID)
+		if err == nil && !visible {
+			err = storage.ErrNotFound
+		}
+	}
+	if err != nil {
+		return nil, notFound(err, "scene", sceneID)
+	}
+	return scene, nil
+}
+
+// visibleConversation loads a conversation for userID, the caller.
+// Conversations they may not read are reported not found; see
+// storage.ConversationVisible.
+func (s *Server) visibleConversation(ctx context.Context, dmID, userID string) (*models.DMConversation, error) {
+	conv, err := s.DMs.GetConversation(ctx, dmID)
+	if err == nil {
+		var visible bool
+		visible, err = storage.ConversationVisible(ctx, s.Workspaces, conv, userID)
+		if err == nil && !visible {
+			err = storage.ErrNotFound
+		}
+	}
+	if err != nil {
+		return nil, notFound(err, "conversation", dmID)
+	}
+	return conv, nil
+}
+
+// encodeScenes writes a ListScenesResponse, filling in live listener counts.
+func (s *Server) encodeScenes(scenes []*models.Scene) []byte {
+	var e encoder
+	for _, scene := range scenes {
+		scene.ActiveUsers = s.Hub.GetActiveSceneUsersCount(scene.ID)
+		e.message(1, func(m *encoder) { encodeScene(m, scene) })
+	}
+	return e.buf
+}
+
+// --- SceneService ---
+
+func (s *Server) getScene(ctx context.Context, req *request) ([]byte, error) {
+	sceneID, err := requireString(req, 1, "scene_id")
+	if err != nil {
+		return nil, err
+	}
+	scene, err := s.visibleScene(ctx, sceneID, req.strings[2])
+	if err != nil {
+		return nil, err
+	}
+	scene.ActiveUsers = s.Hub.GetActiveSceneUsersCount(scene.ID)
+	var e encoder
+	encodeScene(&e, scene)
+	return e.buf, nil
+}
+
+func (s *Server) listUserScenes(ctx context.Context, req *request) ([]byte, error) {
+	userID, err := requireString(req, 1, "user_id")
+	if err != nil {
+		return nil, err
+	}
+	scenes, err := s.Scenes.GetScenesForUser(ctx, userID, req.strings[2])
+	if err != nil {
+		return nil, err
+	}
+	return s.encodeScenes(scenes), nil
+}
+
+func (s *Server) searchScenes(ctx context.Context, req *request) ([]byte, error) {
+	query, err := requireString(req, 1, "query")
+	if err != nil {
+		return nil, err
+	}
+	limit, offset := req.ints[2], req.ints[3]
+	if limit < 0 || offset < 0 {
+		return nil, statusf(CodeInvalidArgument, "limit and offset must not be negative")
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	scenes, err := s.Scenes.SearchScenes(ctx, "", query, min(limit, maxSearchLimit), offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.encodeScenes(scenes), nil
+}
+
+func (s *Server) listParticipants(ctx context.Context, req *request) ([]byte, error) {
+	sceneID, err := requireString(req, 1, "scene_id")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.visibleScene(ctx, sceneID, req.strings[2]); err != nil {
+		return nil, err
+	}
+	participants, err := s.Scenes.GetSceneParticipants(ctx, []string{sceneID})
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	e.strings(1, participants[sceneID])
+	return e.buf, nil
+}
+
+func (s *Server) listSceneMessages(ctx context.Context, req *request) ([]byte, error) {
+	sceneID, err := requireString(req, 1, "scene_id")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(req, 2)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.visibleScene(ctx, sceneID, req.strings[3]); err != nil {
+		return nil, err
+	}
+	msgs, err := s.Scenes.GetSceneMessages(ctx, sceneID)
+	if err != nil {
+		return nil, err
+	}
+	msgs = msgs[max(0, len(msgs)-limit):] // The most recent, oldest first
+	var e encoder
+	for i := range msgs {
+		e.message(1, func(m *encoder) { encodeSceneMessage(m, &msgs[i]) })
+	}
+	return e.buf, nil
+}
+
+// --- DMService ---
+
+func (s *Server) listConversations(ctx context.Context, req *request) ([]byte, error) {
+	userID, err := requireString(req, 1, "user_id")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(req, 2)
+	if err != nil {
+		return nil, err
+	}
+	page := storage.ConversationPage{Limit: limit, Cursor: req.strings[3], WorkspaceID: req.strings[4]}
+	convs, err := s.DMs.GetConversations(ctx, userID, page)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		return nil, statusf(CodeInvalidArgument, "invalid page_token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	for _, conv := range convs {
+		e.message(1, func(m *encoder) { encodeConversation(m, conv) })
+	}
+	if len(convs) == limit {
+		e.string(2, storage.ConversationCursor(convs[len(convs)-1]))
+	}
+	return e.buf, nil
+}
+
+func (s *Server) getConversation(ctx context.Context, req *request) ([]byte, error) {
+	dmID, err := requireString(req, 1, "conversation_id")
+	if err != nil {
+		return nil, err
+	}
+	conv, err := s.visibleConversation(ctx, dmID, req.strings[2])
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	encodeConversation(&e, conv)
+	return e.buf, nil
+}
+
+func (s *Server) listDMMessages(ctx context.Context, req *request) ([]byte, error) {
+	dmID, err := requireString(req, 1, "conversation_id")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(req, 2)
+	if err != nil {
+		return nil, err
+	}
+	page := storage.MessagePage{Limit: limit, Before: req.strings[3], After: req.strings[4]}
+	if page.Before != "" && page.After != "" {
+		return nil, statusf(CodeInvalidArgument, "only one of before and after may be set")
+	}
+	if _, err := s.visibleConversation(ctx, dmID, req.strings[5]); err != nil {
+		return nil, err
+	}
+	msgs, err := s.DMs.GetMessages(ctx, dmID, page)
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	for i := range msgs {
+		e.message(1, func(m *encoder) { encodeDMMessage(m, &msgs[i]) })
+	}
+	return e.buf, nil
+}
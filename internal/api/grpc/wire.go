@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// This file implements the parts of the protobuf wire format the services
+// need: varints, length-delimited strings and messages, and
+// google.protobuf.Timestamp. Fields follow proto3 rules: zero values are not
+// encoded, and unknown fields are skipped when decoding.
+
+// Wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// encoder appends fields to a message.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(field, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+func (e *encoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) strings(field int, list []string) {
+	for _, s := range list {
+		// Repeated fields keep empty elements
+		e.tag(field, wireBytes)
+		e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+		e.buf = append(e.buf, s...)
+	}
+}
+
+// int encodes an int32 or int64 field; negative values take ten bytes, as in protobuf.
+func (e *encoder) int(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+func (e *encoder) bool(field int, v bool) {
+	if !v {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = append(e.buf, 1)
+}
+
+func (e *encoder) double(field int, v float64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireFixed64)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+}
+
+// message encodes a nested message written by fn. Nested messages are
+// always encoded, even when empty, so presence is preserved.
+func (e *encoder) message(field int, fn func(*encoder)) {
+	var sub encoder
+	fn(&sub)
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(sub.buf)))
+	e.buf = append(e.buf, sub.buf...)
+}
+
+// timestamp encodes a google.protobuf.Timestamp; the zero time is left unset.
+func (e *encoder) timestamp(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	e.message(field, func(ts *encoder) {
+		ts.int(1, t.Unix())
+		ts.int(2, int64(t.Nanosecond()))
+	})
+}
+
+// optionalTimestamp encodes t unless it is nil.
+func (e *encoder) optionalTimestamp(field int, t *time.Time) {
+	if t != nil {
+		e.timestamp(field, *t)
+	}
+}
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// decoder reads the fields of a message in order.
+type decoder struct {
+	buf []byte
+}
+
+// next returns the next field's number and wire type, and false at the end.
+func (d *decoder) next() (field, wireType int, ok bool, err error) {
+	if len(d.buf) == 0 {
+		return 0, 0, false, nil
+	}
+	key, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, 0, false, errTruncated
+	}
+	d.buf = d.buf[n:]
+	if key>>3 == 0 || key>>3 > math.MaxInt32 {
+		return 0, 0, false, fmt.Errorf("invalid protobuf field number %d", key>>3)
+	}
+	return int(key >> 3), int(key & 7), true, nil
+}
+
+func (d *decoder) varint(wireType int) (uint64, error) {
+	if wireType != wireVarint {
+		return 0, fmt.Errorf("expected varint, got wire type %d", wireType)
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+// int32 reads an int32 field, which negative values encode in ten bytes.
+func (d *decoder) int32(wireType int) (int, error) {
+	v, err := d.varint(wireType)
+	return int(int32(v)), err
+}
+
+func (d *decoder) bytes(wireType int) ([]byte, error) {
+	if wireType != wireBytes {
+		return nil, fmt.Errorf("expected length-delimited field, got wire type %d", wireType)
+	}
+	size, n := binary.Uvarint(d.buf)
+	if n <= 0 || size > uint64(len(d.buf)-n) {
+		return nil, errTruncated
+	}
+	b := d.buf[n : n+int(size)]
+	d.buf = d.buf[n+int(size):]
+	return b, nil
+}
+
+func (d *decoder) string(wireType int) (string, error) {
+	b, err := d.bytes(wireType)
+	return string(b), err
+}
+
+// skip discards a field the message does not define.
+func (d *decoder) skip(wireType int) error {
+	var size int
+	switch wireType {
+	case wireVarint:
+		_, err := d.varint(wireType)
+		return err
+	case wireBytes:
+		_, err := d.bytes(wireType)
+		return err
+	case wireFixed64:
+		size = 8
+	case wireFixed32:
+		size = 4
+	default:
+		return fmt.Errorf("unsupported wire type %d", wireType)
+	}
+	if len(d.buf) < size {
+		return errTruncated
+	}
+	d.buf = d.buf[size:]
+	return nil
+}
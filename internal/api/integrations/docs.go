@@ -0,0 +1,61 @@
+package integrations
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// Routes describes the routes registered by RegisterIntegrationRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodGet, Path: "/api/v1/integrations/spotify/authorize", ID: "spotifyAuthorize", Tag: "Integrations",
+		Summary: "Get the Spotify consent URL",
+		Query:   []openapi.Param{{Name: "user_id", Required: true}},
+		Response: struct {
+			URL string `json:"url"`
+		}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/integrations/spotify/exchange", ID: "spotifyExchange", Tag: "Integrations",
+		Summary:     "Complete the Spotify OAuth flow",
+		Description: `Takes the "code" and "state" Spotify redirected back with.`,
+		Body: struct {
+			Code  string `json:"code"`
+			State string `json:"state"`
+		}{},
+		Response: struct {
+			Message string `json:"message"`
+			UserID  string `json:"userID"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/integrations/spotify/status", ID: "spotifyStatus", Tag: "Integrations",
+		Summary: "Check whether a user has linked Spotify",
+		Query:   []openapi.Param{{Name: "user_id", Required: true}},
+		Response: struct {
+			Linked bool `json:"linked"`
+		}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/integrations/spotify/unlink", ID: "spotifyUnlink", Tag: "Integrations",
+		Summary: "Disconnect a user's Spotify account",
+		Body: struct {
+			UserID string `json:"userID"`
+		}{},
+		Response: struct {
+			Message string `json:"message"`
+		}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/integrations/spotify/playlists", ID: "spotifyExportPlaylist", Tag: "Integrations",
+		Summary:     "Push a scene's playlist to the creator's Spotify account",
+		Description: "The first export creates a private playlist; later exports append the tracks played since. Returns 409 if the creator has not linked Spotify or must relink to grant playlist access.",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			UserID  string `json:"userID"`
+		}{},
+		Response: models.SpotifyPlaylist{},
+	},
+}
@@ -0,0 +1,191 @@
+package integrations
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/spotify"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// IntegrationHandler holds the dependencies for third-party account linking.
+type IntegrationHandler struct {
+	Spotify  *spotify.Service      // Spotify OAuth and token service
+	Scenes   storage.SceneStore    // Used to check who may export a scene
+	Playback storage.PlaybackStore // Supplies the play history exported as a playlist
+}
+
+// SpotifyAuthorize handles the HTTP GET request for the Spotify consent URL.
+// It expects the user ID as a query parameter "user_id".
+func (h *IntegrationHandler) SpotifyAuthorize(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for SpotifyAuthorize")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"url": h.Spotify.AuthorizeURL(userID),
+	})
+}
+
+// SpotifyExchange handles the HTTP POST request that completes the OAuth flow.
+// It expects a JSON payload with the "code" and "state" Spotify redirected back with.
+func (h *IntegrationHandler) SpotifyExchange(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SpotifyExchange: %v", err)
+		return
+	}
+
+	if req.Code == "" || req.State == "" {
+		http.Error(w, "Code and State cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Code or State is empty for SpotifyExchange")
+		return
+	}
+
+	userID, err := h.Spotify.ExchangeCode(r.Context(), req.Code, req.State)
+	if errors.Is(err, spotify.ErrInvalidState) {
+		http.Error(w, "Invalid OAuth state", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to link Spotify account", http.StatusBadGateway)
+		log.Printf("Error exchanging Spotify code: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Spotify account linked successfully",
+		"userID":  userID,
+	})
+	log.Printf("Linked Spotify account for user %s", userID)
+}
+
+// SpotifyStatus handles the HTTP GET request to check whether a user has linked Spotify.
+// It expects the user ID as a query parameter "user_id".
+func (h *IntegrationHandler) SpotifyStatus(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for SpotifyStatus")
+		return
+	}
+
+	linked, err := h.Spotify.IsLinked(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking Spotify link for user %s: %v", userID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]bool{"linked": linked})
+}
+
+// SpotifyUnlink handles the HTTP POST request to disconnect a user's Spotify account.
+// It expects a JSON payload with "userID".
+func (h *IntegrationHandler) SpotifyUnlink(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string `json:"userID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil || req.UserID == "" {
+		http.Error(w, "User ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Spotify.Unlink(r.Context(), req.UserID); err != nil {
+		http.Error(w, "Failed to unlink Spotify account", http.StatusInternalServerError)
+		log.Printf("Error unlinking Spotify for user %s: %v", req.UserID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Spotify account unlinked"})
+}
+
+// SpotifyExportPlaylist handles the HTTP POST request to push everything played
+// in a scene to the creator's Spotify account. It expects a JSON payload with
+// "sceneID" and "userID". Exporting again appends the tracks played since.
+func (h *IntegrationHandler) SpotifyExportPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string `json:"sceneID"`
+		UserID  string `json:"userID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SpotifyExportPlaylist: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "SceneID and UserID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: SceneID or UserID is empty for SpotifyExportPlaylist")
+		return
+	}
+
+	scene, err := h.Scenes.GetScene(r.Context(), req.SceneID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene %s for SpotifyExportPlaylist: %v", req.SceneID, err)
+		return
+	}
+
+	if req.UserID != scene.CreatorID {
+		http.Error(w, "Only the scene creator can export its playlist", http.StatusForbidden)
+		return
+	}
+
+	plays, err := h.Playback.GetPlayHistory(r.Context(), req.SceneID)
+	if err != nil {
+		http.Error(w, "Failed to get play history", http.StatusInternalServerError)
+		log.Printf("Error getting play history for scene %s: %v", req.SceneID, err)
+		return
+	}
+
+	exported, err := h.Spotify.ExportPlaylist(r.Context(), req.UserID, models.NewScenePlaylist(scene, plays))
+	if errors.Is(err, spotify.ErrNotLinked) {
+		http.Error(w, "Spotify account not linked", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, spotify.ErrMissingScope) {
+		http.Error(w, "Relink Spotify to allow playlist exports", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to export playlist to Spotify", http.StatusBadGateway)
+		log.Printf("Error exporting scene %s to Spotify for user %s: %v", req.SceneID, req.UserID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(exported)
+	log.Printf("Exported scene %s to Spotify playlist %s (%d tracks)", exported.SceneID, exported.PlaylistID, exported.TrackCount)
+}
@@ -0,0 +1,59 @@
+package integrations
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterIntegrationRoutes registers all third-party integration routes with the provided ServeMux.
+func RegisterIntegrationRoutes(mux *http.ServeMux, handler *IntegrationHandler) {
+	mux.HandleFunc("/api/v1/integrations/spotify/authorize", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Integration] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Integration] %s %s", r.Method, r.URL.Path)
+		handler.SpotifyAuthorize(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/integrations/spotify/exchange", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Integration] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Integration] %s %s", r.Method, r.URL.Path)
+		handler.SpotifyExchange(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/integrations/spotify/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Integration] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Integration] %s %s", r.Method, r.URL.Path)
+		handler.SpotifyStatus(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/integrations/spotify/unlink", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Integration] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Integration] %s %s", r.Method, r.URL.Path)
+		handler.SpotifyUnlink(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/integrations/spotify/playlists", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Integration] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Integration] %s %s", r.Method, r.URL.Path)
+		handler.SpotifyExportPlaylist(w, r)
+	})
+}
@@ -0,0 +1,24 @@
+package leaderboards
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// Routes describes the routes registered by RegisterLeaderboardRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodGet, Path: "/api/v1/leaderboards", ID: "getLeaderboard", Tag: "Leaderboards",
+		Summary: "Fetch a leaderboard",
+		Description: "The scenes board ranks public scenes by hours listened; the creators board ranks creators by scenes hosted, " +
+			"then by listeners. Weekly boards count the last seven days. Boards are recomputed every 10 minutes and may be cached for a minute.",
+		Query: []openapi.Param{
+			{Name: "board", Required: true, Description: "scenes or creators"},
+			{Name: "period", Description: "week (default) or all-time"},
+			{Name: "limit", Type: "integer", Description: "Entries to return, at most 100; defaults to 20"},
+		},
+		Response: models.Leaderboard{},
+	},
+}
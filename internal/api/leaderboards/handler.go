@@ -0,0 +1,66 @@
+package leaderboards
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/leaderboard"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// defaultLimit is how many entries a leaderboard request returns without a "limit".
+const defaultLimit = 20
+
+// LeaderboardHandler holds the dependencies for serving leaderboards.
+type LeaderboardHandler struct {
+	Leaderboards *leaderboard.Service // Cached boards built by the leaderboards job
+}
+
+// GetLeaderboard handles the HTTP GET request for a leaderboard. It expects
+// the query parameters "board" ("scenes" or "creators") and accepts "period"
+// ("week", the default, or "all-time") and "limit". Boards are recomputed
+// by the leaderboards job, so they are empty until it first runs.
+func (h *LeaderboardHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	board := models.LeaderboardBoard(q.Get("board"))
+	period := models.PeriodWeek
+	if p := q.Get("period"); p != "" {
+		period = models.LeaderboardPeriod(p)
+	}
+
+	if !board.IsValid() {
+		http.Error(w, "Board must be scenes or creators", http.StatusBadRequest)
+		log.Printf("Validation error: unknown board %q for GetLeaderboard", board)
+		return
+	}
+	if !period.IsValid() {
+		http.Error(w, "Period must be week or all-time", http.StatusBadRequest)
+		log.Printf("Validation error: unknown period %q for GetLeaderboard", period)
+		return
+	}
+
+	limit := defaultLimit
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, leaderboard.Size)
+	}
+
+	top, err := h.Leaderboards.Get(r.Context(), board, period, limit)
+	if err != nil {
+		http.Error(w, "Failed to get leaderboard", http.StatusInternalServerError)
+		log.Printf("Error getting %s %s leaderboard: %v", period, board, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Boards change only when the job runs; let clients and proxies reuse them briefly
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(top)
+}
@@ -0,0 +1,19 @@
+package leaderboards
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterLeaderboardRoutes registers the leaderboard routes with the provided ServeMux.
+func RegisterLeaderboardRoutes(mux *http.ServeMux, handler *LeaderboardHandler) {
+	mux.HandleFunc("/api/v1/leaderboards", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Leaderboard] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Leaderboard] %s %s", r.Method, r.URL.Path)
+		handler.GetLeaderboard(w, r)
+	})
+}
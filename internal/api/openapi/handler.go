@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Paths the document and the Swagger UI are served at.
+const (
+	SpecPath = "/api/v1/openapi.json"
+	DocsPath = "/api/v1/docs"
+)
+
+// swaggerUIVersion pins the swagger-ui-dist release the docs page loads.
+const swaggerUIVersion = "5.17.14"
+
+// OpenAPIHandler serves a prebuilt document.
+type OpenAPIHandler struct {
+	spec []byte
+}
+
+// NewOpenAPIHandler builds the document for routes once, up front.
+func NewOpenAPIHandler(info Info, routes []Route) (*OpenAPIHandler, error) {
+	doc, err := Build(info, routes)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAPIHandler{spec: spec}, nil
+}
+
+// Spec handles the HTTP GET request for the OpenAPI document.
+func (h *OpenAPIHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.spec)
+}
+
+// Docs handles the HTTP GET request for the Swagger UI page. The page's
+// scripts and styles come from the swagger-ui-dist package on a CDN.
+func (h *OpenAPIHandler) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, docsPage, swaggerUIVersion, swaggerUIVersion, SpecPath)
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Scenyx API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@%[1]s/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@%[2]s/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: %[3]q, dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,27 @@
+package openapi
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterOpenAPIRoutes registers the OpenAPI document and Swagger UI routes with the provided ServeMux.
+func RegisterOpenAPIRoutes(mux *http.ServeMux, handler *OpenAPIHandler) {
+	mux.HandleFunc(SpecPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[OpenAPI] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		handler.Spec(w, r)
+	})
+
+	mux.HandleFunc(DocsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[OpenAPI] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		handler.Docs(w, r)
+	})
+}
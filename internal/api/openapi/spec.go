@@ -0,0 +1,376 @@
+// Package openapi describes the HTTP API as an OpenAPI 3 document and serves
+// it, together with a Swagger UI, so frontend clients can be generated.
+//
+// Each API package declares its routes as []Route next to RegisterXRoutes.
+// Request and response schemas are derived from the Go types the handlers
+// decode and encode, following encoding/json's rules, so the document stays
+// in step with the models.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route describes one method on one path.
+type Route struct {
+	Method      string
+	Path        string
+	ID          string // operationId; unique across the API
+	Tag         string
+	Summary     string
+	Description string
+	Query       []Param
+	Body        any     // Value whose type is the JSON request body, if any
+	Form        []Param // multipart/form-data fields, for uploads
+	Status      int     // Success status; defaults to 200
+	Response    any     // Value whose type is the JSON response body, if any
+	Deprecated  bool    // Kept for compatibility; clients should move to the successor route
+}
+
+// Param is a query parameter or form field.
+type Param struct {
+	Name        string
+	Type        string // "string" (default), "integer", "boolean", or "file" (form fields only)
+	Format      string // e.g. "date-time"
+	Required    bool
+	Description string
+}
+
+// Info identifies the API.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Document is an OpenAPI 3.0 document.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components components                       `json:"components"`
+}
+
+type components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []parameter          `json:"parameters,omitempty"`
+	RequestBody *requestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of the OpenAPI schema object the generator emits.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// errorResponse is every handler's failure: http.Error's plain-text message.
+var errorResponse = &response{
+	Description: "Error; the body is a plain-text message",
+	Content:     map[string]*mediaType{"text/plain": {Schema: &Schema{Type: "string"}}},
+}
+
+// Build assembles the document for routes. It fails if two routes share a
+// method and path or an ID, or if a body type cannot be encoded as JSON.
+func Build(info Info, routes []Route) (*Document, error) {
+	doc := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Paths:      make(map[string]map[string]*operation),
+		Components: components{Schemas: make(map[string]*Schema)},
+	}
+	g := &generator{schemas: doc.Components.Schemas, names: make(map[string]reflect.Type)}
+	ids := make(map[string]bool)
+
+	for _, route := range routes {
+		method := strings.ToLower(route.Method)
+		if doc.Paths[route.Path] == nil {
+			doc.Paths[route.Path] = make(map[string]*operation)
+		}
+		if doc.Paths[route.Path][method] != nil {
+			return nil, fmt.Errorf("openapi: %s %s is declared twice", route.Method, route.Path)
+		}
+		if route.ID == "" || ids[route.ID] {
+			return nil, fmt.Errorf("openapi: %s %s needs a unique ID, got %q", route.Method, route.Path, route.ID)
+		}
+		ids[route.ID] = true
+
+		op, err := g.operation(route)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: %s %s: %w", route.Method, route.Path, err)
+		}
+		doc.Paths[route.Path][method] = op
+	}
+	return doc, nil
+}
+
+func (g *generator) operation(route Route) (*operation, error) {
+	op := &operation{
+		OperationID: route.ID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   map[string]*response{"default": errorResponse},
+		Deprecated:  route.Deprecated,
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	// Path templates such as /s/{slug} declare their parameters implicitly
+	for _, name := range pathParams(route.Path) {
+		op.Parameters = append(op.Parameters, parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, p := range route.Query {
+		op.Parameters = append(op.Parameters, parameter{
+			Name:        p.Name,
+			In:          "query",
+			Required:    p.Required,
+			Description: p.Description,
+			Schema:      p.schema(),
+		})
+	}
+
+	switch {
+	case route.Body != nil:
+		schema, err := g.schemaOf(reflect.TypeOf(route.Body))
+		if err != nil {
+			return nil, err
+		}
+		op.RequestBody = &requestBody{Required: true, Content: map[string]*mediaType{"application/json": {Schema: schema}}}
+	case len(route.Form) > 0:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for _, p := range route.Form {
+			schema.Properties[p.Name] = p.schema()
+			if p.Required {
+				schema.Required = append(schema.Required, p.Name)
+			}
+		}
+		op.RequestBody = &requestBody{Required: true, Content: map[string]*mediaType{"multipart/form-data": {Schema: schema}}}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	res := &response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		schema, err := g.schemaOf(reflect.TypeOf(route.Response))
+		if err != nil {
+			return nil, err
+		}
+		res.Content = map[string]*mediaType{"application/json": {Schema: schema}}
+	}
+	op.Responses[strconv.Itoa(status)] = res
+	return op, nil
+}
+
+// pathParams returns the names of the {param} segments of a path template.
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, segment[1:len(segment)-1])
+		}
+	}
+	return names
+}
+
+func (p Param) schema() *Schema {
+	switch p.Type {
+	case "", "string":
+		return &Schema{Type: "string", Format: p.Format}
+	case "file":
+		return &Schema{Type: "string", Format: "binary"}
+	default:
+		return &Schema{Type: p.Type, Format: p.Format}
+	}
+}
+
+// generator derives schemas from Go types. Named structs become shared
+// components referenced by $ref; anonymous structs are inlined.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[string]reflect.Type // Component name -> the type that claimed it
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+	rawType       = reflect.TypeFor[json.RawMessage]()
+)
+
+func (g *generator) schemaOf(t reflect.Type) (*Schema, error) {
+	if t.Kind() == reflect.Pointer {
+		schema, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		if schema.Ref != "" {
+			return schema, nil // $ref siblings are ignored, so nullable cannot be set
+		}
+		schema.Nullable = true
+		return schema, nil
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case t == rawType:
+		return &Schema{}, nil
+	case t.Implements(marshalerType):
+		return nil, fmt.Errorf("%s has a custom JSON encoding", t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil // Any JSON value
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map key type %s is not a string", t.Key())
+		}
+		values, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.component(t)
+	}
+	return nil, fmt.Errorf("type %s cannot be encoded as JSON", t)
+}
+
+// component returns a $ref to the named struct t, generating it on first use.
+func (g *generator) component(t reflect.Type) (*Schema, error) {
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:] // Unexported request/response types still get client-friendly names
+	if other, ok := g.names[name]; ok && other != t {
+		// Disambiguate same-named types from different packages
+		name = strings.ReplaceAll(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:], ".", "_") + "." + name
+	}
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := g.names[name]; ok {
+		return ref, nil
+	}
+	g.names[name] = t // Claimed before generating so recursive types terminate
+
+	schema, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	g.schemas[name] = schema
+	return ref, nil
+}
+
+// structSchema lists t's JSON fields. Fields tagged omitempty are optional;
+// embedded structs without a tag are flattened as encoding/json does.
+func (g *generator) structSchema(t reflect.Type) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := g.addFields(schema, t); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (g *generator) addFields(schema *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := g.addFields(schema, ft); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := g.schemaOf(f.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if strings.Contains(opts, "string") && prop.Ref == "" && prop.Type != "" {
+			prop = &Schema{Type: "string", Format: prop.Format, Nullable: prop.Nullable}
+		}
+		schema.Properties[name] = prop
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package playback
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// timeResponse carries the server clock for NTP-style offset estimation. A
+// client records t0 when it sends the request and t3 when the response
+// arrives; its offset from the server is ((ReceivedAtMs - t0) + (ServerTimeMs - t3)) / 2
+// and the round trip is (t3 - t0) - (ServerTimeMs - ReceivedAtMs).
+type timeResponse struct {
+	ClientTimeMs int64     `json:"clientTimeMs,omitempty"` // The request's client_time, echoed back
+	ReceivedAtMs int64     `json:"receivedAtMs"`           // Server clock when the request arrived, in Unix milliseconds
+	ServerTimeMs int64     `json:"serverTimeMs"`           // Server clock when the response was written, in Unix milliseconds
+	ServerTime   time.Time `json:"serverTime"`             // ServerTimeMs as an RFC 3339 timestamp
+}
+
+// GetTime handles the HTTP GET request for the server clock. The optional
+// "client_time" query parameter, the client's send time in Unix
+// milliseconds, is echoed back so a client can match responses to requests.
+func (h *PlaybackHandler) GetTime(w http.ResponseWriter, r *http.Request) {
+	received := time.Now()
+
+	var clientTime int64
+	if raw := r.URL.Query().Get("client_time"); raw != "" {
+		var err error
+		clientTime, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || clientTime < 0 {
+			http.Error(w, "client_time must be Unix milliseconds", http.StatusBadRequest)
+			log.Printf("Validation error: Invalid client_time %q for GetTime", raw)
+			return
+		}
+	}
+
+	// A cached response would make the measurement meaningless
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	now := time.Now()
+	json.NewEncoder(w).Encode(timeResponse{
+		ClientTimeMs: clientTime,
+		ReceivedAtMs: received.UnixMilli(),
+		ServerTimeMs: now.UnixMilli(),
+		ServerTime:   now.UTC(),
+	})
+}
@@ -0,0 +1,65 @@
+package playback
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// viewerParam is the caller of a route reading a scene's playback, which is
+// not found for workspace scenes unless it names a member.
+var viewerParam = openapi.Param{Name: "user_id", Description: "The caller; required for scenes in a workspace"}
+
+// Routes describes the routes registered by RegisterPlaybackRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodGet, Path: "/api/v1/playback/state", ID: "getPlaybackState", Tag: "Playback",
+		Summary:  "Fetch a scene's current playback state",
+		Query:    []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response: playbackResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/playback/set", ID: "setPlaybackState", Tag: "Playback",
+		Summary:     "Change a scene's playback",
+		Description: "Only the scene host and co-hosts may set state. Changing the track also broadcasts a now_playing event.",
+		Body: struct {
+			SceneID     string `json:"sceneID"`
+			UserID      string `json:"userID"`
+			TrackID     string `json:"trackID"`
+			TrackTitle  string `json:"trackTitle"`
+			TrackArtist string `json:"trackArtist"`
+			PositionMs  int64  `json:"positionMs"`
+			IsPlaying   bool   `json:"isPlaying"`
+		}{},
+		Response: playbackResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/playback/skip", ID: "voteSkipTrack", Tag: "Playback",
+		Summary: "Vote to skip a scene's current track",
+		Description: "Only scene participants may vote, once per track. When the votes reach the scene's skipThreshold " +
+			"share of active users, playback advances to the next queued track (or stops if the queue is empty) " +
+			"and a track.skipped event is broadcast with the new playback state and queue.",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			UserID  string `json:"userID"`
+		}{},
+		Response: skipResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/playback/playlist", ID: "getScenePlaylist", Tag: "Playback",
+		Summary:     "Export everything played in a scene as a playlist",
+		Description: "Tracks are listed once each, in the order they were first played.",
+		Query:       []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response:    models.ScenePlaylist{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/time", ID: "getServerTime", Tag: "Playback",
+		Summary: "Read the server clock",
+		Description: "Used to estimate clock offset and round-trip time for synchronized playback. Pass the send time " +
+			"as client_time, note the arrival time t3, and compute offset = ((receivedAtMs - client_time) + " +
+			"(serverTimeMs - t3)) / 2. Playback events carry serverTimeMs for the same purpose.",
+		Query:    []openapi.Param{{Name: "client_time", Type: "integer", Description: "Client send time in Unix milliseconds, echoed back"}},
+		Response: timeResponse{},
+	},
+}
@@ -0,0 +1,193 @@
+package playback
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// PlaybackHandler holds the dependencies for handling scene playback requests.
+type PlaybackHandler struct {
+	Store      storage.PlaybackStore  // Persists each scene's player state
+	Scenes     storage.SceneStore     // Used to look up who may control playback
+	Workspaces storage.WorkspaceStore // Memberships of the workspaces private scenes belong to
+	Hub        *ws.Hub                // Broadcasts state changes to scene listeners
+}
+
+// playbackResponse is the playback state plus the extrapolated current
+// position. It is also the payload of playback events, so clients that have
+// measured their clock offset with /api/v1/time can place the position on
+// their own clock.
+type playbackResponse struct {
+	*models.PlaybackState
+	CurrentPositionMs int64 `json:"currentPositionMs"`
+	ServerTimeMs      int64 `json:"serverTimeMs"` // Server clock, in Unix milliseconds, at which CurrentPositionMs holds
+}
+
+// newPlaybackResponse extrapolates state to the current server time.
+func newPlaybackResponse(state *models.PlaybackState) playbackResponse {
+	now := time.Now()
+	return playbackResponse{state, state.CurrentPositionMs(now), now.UnixMilli()}
+}
+
+// getVisibleScene loads a scene for userID, the caller, writing the error
+// response if it cannot. Workspace scenes are reported not found to anyone
+// but members.
+func (h *PlaybackHandler) getVisibleScene(w http.ResponseWriter, r *http.Request, sceneID, userID string) (*models.Scene, bool) {
+	scene, err := h.Scenes.GetScene(r.Context(), sceneID)
+	if err == nil {
+		var visible bool
+		visible, err = storage.SceneVisible(r.Context(), h.Workspaces, scene, userID)
+		if err == nil && !visible {
+			err = storage.ErrNotFound
+		}
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene %s: %v", sceneID, err)
+		return nil, false
+	}
+	return scene, true
+}
+
+// GetState handles the HTTP GET request for a scene's current playback state.
+// It expects the scene ID as a query parameter "scene_id" and accepts the
+// caller's "user_id", required for workspace scenes.
+func (h *PlaybackHandler) GetState(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for GetState")
+		return
+	}
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	state, err := h.Store.GetPlayback(r.Context(), sceneID)
+	if errors.Is(err, storage.ErrNotFound) {
+		// Nothing has been played yet; report an idle player
+		state = &models.PlaybackState{SceneID: sceneID, UpdatedAt: time.Now()}
+	} else if err != nil {
+		http.Error(w, "Failed to get playback state", http.StatusInternalServerError)
+		log.Printf("Error getting playback for scene %s: %v", sceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPlaybackResponse(state))
+}
+
+// SetState handles the HTTP POST request for a scene host or co-host to change playback.
+// It expects a JSON payload with "sceneID", "userID", the track fields,
+// "positionMs", and "isPlaying". Only the host and co-hosts may set state.
+func (h *PlaybackHandler) SetState(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID     string `json:"sceneID"`
+		UserID      string `json:"userID"`
+		TrackID     string `json:"trackID"`
+		TrackTitle  string `json:"trackTitle"`
+		TrackArtist string `json:"trackArtist"`
+		PositionMs  int64  `json:"positionMs"`
+		IsPlaying   bool   `json:"isPlaying"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SetState: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for SetState")
+		return
+	}
+	if req.PositionMs < 0 {
+		http.Error(w, "Position cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.Scenes.GetSceneRole(r.Context(), req.SceneID, req.UserID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting role of user %s in scene %s for SetState: %v", req.UserID, req.SceneID, err)
+		return
+	}
+	if !role.CanModerate() {
+		http.Error(w, "Only the scene host and co-hosts can control playback", http.StatusForbidden)
+		log.Printf("User %s attempted to control playback for scene %s", req.UserID, req.SceneID)
+		return
+	}
+
+	state, err := h.Store.SetPlayback(r.Context(), &models.PlaybackState{
+		SceneID:     req.SceneID,
+		TrackID:     req.TrackID,
+		TrackTitle:  req.TrackTitle,
+		TrackArtist: req.TrackArtist,
+		PositionMs:  req.PositionMs,
+		IsPlaying:   req.IsPlaying,
+		UpdatedBy:   req.UserID,
+	})
+	if err != nil {
+		http.Error(w, "Failed to set playback state", http.StatusInternalServerError)
+		log.Printf("Error setting playback for scene %s: %v", req.SceneID, err)
+		return
+	}
+
+	// Keep every listener in the scene in sync
+	h.Hub.SendToScene(state.SceneID, ws.TypePlayback, newPlaybackResponse(state))
+	if state.TrackChanged() {
+		h.Hub.SendToScene(state.SceneID, ws.TypeNowPlaying, state.NowPlaying())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPlaybackResponse(state))
+}
+
+// GetPlaylist handles the HTTP GET request to export everything played in a
+// scene as a playlist. It expects the scene ID as a query parameter "scene_id"
+// and accepts the caller's "user_id", required for workspace scenes.
+func (h *PlaybackHandler) GetPlaylist(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for GetPlaylist")
+		return
+	}
+
+	scene, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id"))
+	if !ok {
+		return
+	}
+
+	plays, err := h.Store.GetPlayHistory(r.Context(), sceneID)
+	if err != nil {
+		http.Error(w, "Failed to get play history", http.StatusInternalServerError)
+		log.Printf("Error getting play history for scene %s: %v", sceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.NewScenePlaylist(scene, plays))
+}
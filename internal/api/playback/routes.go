@@ -0,0 +1,59 @@
+package playback
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterPlaybackRoutes registers all playback-related HTTP routes with the provided ServeMux.
+func RegisterPlaybackRoutes(mux *http.ServeMux, handler *PlaybackHandler) {
+	mux.HandleFunc("/api/v1/playback/state", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Playback] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Playback] %s %s", r.Method, r.URL.Path)
+		handler.GetState(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/playback/set", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Playback] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Playback] %s %s", r.Method, r.URL.Path)
+		handler.SetState(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/playback/skip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Playback] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Playback] %s %s", r.Method, r.URL.Path)
+		handler.VoteSkip(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/playback/playlist", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Playback] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Playback] %s %s", r.Method, r.URL.Path)
+		handler.GetPlaylist(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/time", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Playback] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		// Not logged: clients hit this several times per sync
+		handler.GetTime(w, r)
+	})
+}
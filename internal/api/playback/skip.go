@@ -0,0 +1,157 @@
+package playback
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// skipResponse reports the vote tally for the track a user voted to skip.
+type skipResponse struct {
+	SceneID string `json:"sceneID"`
+	TrackID string `json:"trackID"`
+	Votes   int    `json:"votes"`
+	Needed  int    `json:"needed"`
+	Skipped bool   `json:"skipped"` // Whether this vote skipped the track
+}
+
+// skipVotesNeeded is how many votes skip a track when activeUsers are in the
+// scene; at least one vote is always required.
+func skipVotesNeeded(threshold float64, activeUsers int) int {
+	return max(1, int(math.Ceil(threshold*float64(max(activeUsers, 1)))))
+}
+
+// VoteSkip handles the HTTP POST request to vote to skip a scene's current track.
+// It expects a JSON payload with "sceneID" and "userID"; only scene
+// participants may vote. Once the votes reach the scene's skip threshold,
+// playback advances to the next queued track and a track.skipped event is
+// broadcast along with the new playback state and queue.
+func (h *PlaybackHandler) VoteSkip(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string `json:"sceneID"`
+		UserID  string `json:"userID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for VoteSkip: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for VoteSkip")
+		return
+	}
+
+	scene, err := h.Scenes.GetScene(r.Context(), req.SceneID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene %s for VoteSkip: %v", req.SceneID, err)
+		return
+	}
+	if scene.SkipThreshold <= 0 {
+		http.Error(w, "Vote-to-skip is disabled in this scene", http.StatusConflict)
+		return
+	}
+
+	participants, err := h.Scenes.GetSceneParticipants(r.Context(), []string{req.SceneID})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error loading participants of scene %s: %v", req.SceneID, err)
+		return
+	}
+	if !slices.Contains(participants[req.SceneID], req.UserID) {
+		http.Error(w, "Only scene participants can vote to skip", http.StatusForbidden)
+		log.Printf("User %s attempted to vote to skip in scene %s without joining", req.UserID, req.SceneID)
+		return
+	}
+
+	state, err := h.Store.GetPlayback(r.Context(), req.SceneID)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && state.TrackID == "") {
+		http.Error(w, "Nothing is playing", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get playback state", http.StatusInternalServerError)
+		log.Printf("Error getting playback for scene %s: %v", req.SceneID, err)
+		return
+	}
+
+	votes, err := h.Store.AddSkipVote(r.Context(), req.SceneID, state.TrackID, req.UserID)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "You already voted to skip this track", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to record skip vote", http.StatusInternalServerError)
+		log.Printf("Error recording skip vote of %s in scene %s: %v", req.UserID, req.SceneID, err)
+		return
+	}
+
+	resp := skipResponse{
+		SceneID: req.SceneID,
+		TrackID: state.TrackID,
+		Votes:   votes,
+		Needed:  skipVotesNeeded(scene.SkipThreshold, h.Hub.GetActiveSceneUsersCount(req.SceneID)),
+	}
+	if resp.Votes >= resp.Needed {
+		resp.Skipped = h.skip(r, resp, req.UserID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// skip advances playback past the voted-off track on behalf of the user whose
+// vote reached the threshold, and tells the scene. It reports false if
+// another vote got there first.
+func (h *PlaybackHandler) skip(r *http.Request, vote skipResponse, userID string) bool {
+	state, next, err := h.Store.SkipTrack(r.Context(), vote.SceneID, vote.TrackID, userID)
+	if errors.Is(err, storage.ErrConflict) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error skipping track %s in scene %s: %v", vote.TrackID, vote.SceneID, err)
+		return false
+	}
+
+	h.Hub.SendToScene(vote.SceneID, ws.TypeTrackSkipped, models.TrackSkipped{
+		SceneID: vote.SceneID,
+		TrackID: vote.TrackID,
+		Votes:   vote.Votes,
+		Needed:  vote.Needed,
+		Next:    next,
+	})
+	h.Hub.SendToScene(vote.SceneID, ws.TypePlayback, newPlaybackResponse(state))
+	if state.TrackChanged() {
+		h.Hub.SendToScene(vote.SceneID, ws.TypeNowPlaying, state.NowPlaying())
+	}
+
+	// The skip consumed the head of the queue
+	if next != nil {
+		queue, err := h.Scenes.GetQueue(r.Context(), vote.SceneID)
+		if err != nil {
+			log.Printf("Error loading queue for scene %s after skip: %v", vote.SceneID, err)
+			return true
+		}
+		if queue == nil {
+			queue = []models.QueueItem{}
+		}
+		h.Hub.SendToScene(vote.SceneID, ws.TypeQueue, queue)
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package reports
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// adminAuth describes how the admin routes identify the caller.
+const adminAuth = "The admin is identified by their token from login or /api/v1/users/ws-token in an \"Authorization: Bearer\" header."
+
+// Routes describes the routes registered by RegisterReportRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/reports", ID: "createReport", Tag: "Reports",
+		Summary: "Report a message, scene, or user",
+		Body: struct {
+			ReporterID string              `json:"reporterID"`
+			TargetType models.ReportTarget `json:"targetType"`
+			TargetID   string              `json:"targetID"`
+			Reason     string              `json:"reason"`
+		}{},
+		Status: http.StatusCreated,
+		Response: struct {
+			ID     string              `json:"id"`
+			Status models.ReportStatus `json:"status"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/admin/reports", ID: "listReports", Tag: "Admin",
+		Summary:     "List the moderation queue",
+		Description: adminAuth,
+		Query: []openapi.Param{
+			{Name: "status", Description: `"open" (default), "dismissed", "actioned", or "all"`},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: []models.Report{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/admin/reports/resolve", ID: "resolveReport", Tag: "Admin",
+		Summary:     "Close a report",
+		Description: adminAuth + ` "remove" deletes a reported message or archives a reported scene; reported users can only be dismissed.`,
+		Body: struct {
+			ReportID string `json:"reportID"`
+			Action   string `json:"action"` // "dismiss" or "remove"
+			Note     string `json:"note,omitempty"`
+		}{},
+		Response: models.Report{},
+	},
+}
@@ -0,0 +1,293 @@
+package reports
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxReasonLength caps the free-form text of a report.
+const maxReasonLength = 1000
+
+// Actions an admin can take when resolving a report.
+const (
+	actionDismiss = "dismiss" // Close the report without changes
+	actionRemove  = "remove"  // Delete the reported message or archive the reported scene
+)
+
+// ReportHandler holds the dependencies for filing and reviewing abuse reports.
+type ReportHandler struct {
+	Store  storage.ModerationStore // Persists reports
+	DMs    storage.DMStore         // Removes reported DM messages
+	Scenes storage.SceneStore      // Removes reported scene messages and archives scenes
+	Hub    *ws.Hub                 // Tells clients about removed content
+	Tokens *ws.TokenSigner         // Verifies the token identifying the admin
+	Admins map[string]bool         // User IDs allowed to review reports
+}
+
+// checkReport writes the appropriate error response for a failed report lookup.
+// It returns true if err is nil and the handler should continue.
+func checkReport(w http.ResponseWriter, err error, reportID string) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Report not found", http.StatusNotFound)
+		log.Printf("Report not found for ID: %s", reportID)
+		return false
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Report has already been resolved", http.StatusConflict)
+		return false
+	}
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+	log.Printf("Error accessing report %s: %v", reportID, err)
+	return false
+}
+
+// CreateReport handles the HTTP POST request to report a message, scene, or user.
+// It expects a JSON payload with "reporterID", "targetType", "targetID", and "reason".
+func (h *ReportHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ReporterID string              `json:"reporterID"`
+		TargetType models.ReportTarget `json:"targetType"`
+		TargetID   string              `json:"targetID"`
+		Reason     string              `json:"reason"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for CreateReport: %v", err)
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.ReporterID == "" || req.TargetID == "" || req.Reason == "" {
+		http.Error(w, "Reporter ID, Target ID, and Reason cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Reporter ID, Target ID, or Reason is empty for CreateReport")
+		return
+	}
+	switch req.TargetType {
+	case models.ReportDMMessage, models.ReportSceneMessage, models.ReportScene, models.ReportUser:
+	default:
+		http.Error(w, "Target type must be dm_message, scene_message, scene, or user", http.StatusBadRequest)
+		return
+	}
+	if len(req.Reason) > maxReasonLength {
+		http.Error(w, "Reason is too long", http.StatusBadRequest)
+		return
+	}
+	if req.TargetType == models.ReportUser && req.TargetID == req.ReporterID {
+		http.Error(w, "You cannot report yourself", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.Store.CreateReport(r.Context(), &models.Report{
+		ReporterID: req.ReporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Reported "+strings.ReplaceAll(string(req.TargetType), "_", " ")+" not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "You have already reported this", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create report", http.StatusInternalServerError)
+		log.Printf("Error creating report on %s %s: %v", req.TargetType, req.TargetID, err)
+		return
+	}
+
+	// The reporter only needs confirmation; the snapshot is for admins
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{"id": report.ID, "status": report.Status})
+}
+
+// ListReports handles the HTTP GET request for the moderation queue.
+// The admin is identified by the token from login or /api/v1/users/ws-token,
+// passed as "Authorization: Bearer <token>". It accepts optional "status"
+// (default "open"; "all" for every status) and "limit" query parameters.
+func (h *ReportHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.checkAdmin(w, r); !ok {
+		return
+	}
+
+	q := r.URL.Query()
+
+	status := models.ReportStatus(q.Get("status"))
+	switch status {
+	case "":
+		status = models.ReportOpen
+	case "all":
+		status = ""
+	case models.ReportOpen, models.ReportDismissed, models.ReportActioned:
+	default:
+		http.Error(w, "Status must be open, dismissed, actioned, or all", http.StatusBadRequest)
+		return
+	}
+
+	limit := storage.DefaultPageLimit
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, storage.MaxPageLimit)
+	}
+
+	reports, err := h.Store.ListReports(r.Context(), status, limit)
+	if err != nil {
+		http.Error(w, "Failed to list reports", http.StatusInternalServerError)
+		log.Printf("Error listing %q reports: %v", status, err)
+		return
+	}
+	if reports == nil {
+		reports = []models.Report{} // Return an empty slice instead of nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reports)
+}
+
+// ResolveReport handles the HTTP POST request to close a report.
+// The admin is identified by their bearer token, as for ListReports.
+// It expects a JSON payload with "reportID", "action" ("dismiss" or
+// "remove"), and an optional "note". Removing deletes a reported message
+// or archives a reported scene; reported users can only be dismissed.
+func (h *ReportHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := h.checkAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ReportID string `json:"reportID"`
+		Action   string `json:"action"`
+		Note     string `json:"note"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for ResolveReport: %v", err)
+		return
+	}
+
+	if req.ReportID == "" {
+		http.Error(w, "Report ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Report ID is empty for ResolveReport")
+		return
+	}
+
+	report, err := h.Store.GetReport(r.Context(), req.ReportID)
+	if !checkReport(w, err, req.ReportID) {
+		return
+	}
+	if report.Status != models.ReportOpen {
+		http.Error(w, "Report has already been resolved", http.StatusConflict)
+		return
+	}
+
+	var status models.ReportStatus
+	switch req.Action {
+	case actionDismiss:
+		status = models.ReportDismissed
+	case actionRemove:
+		if report.TargetType == models.ReportUser {
+			http.Error(w, "Reported users cannot be removed; dismiss the report or act on their content", http.StatusBadRequest)
+			return
+		}
+		if err := h.removeTarget(r, report); err != nil {
+			http.Error(w, "Failed to remove reported content", http.StatusInternalServerError)
+			log.Printf("Error removing %s %s for report %s: %v", report.TargetType, report.TargetID, report.ID, err)
+			return
+		}
+		status = models.ReportActioned
+	default:
+		http.Error(w, "Action must be dismiss or remove", http.StatusBadRequest)
+		return
+	}
+
+	report, err = h.Store.ResolveReport(r.Context(), req.ReportID, adminID, status, strings.TrimSpace(req.Note))
+	if !checkReport(w, err, req.ReportID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// removeTarget deletes the reported message or archives the reported scene
+// and notifies connected clients. Content that is already gone is not an error.
+func (h *ReportHandler) removeTarget(r *http.Request, report *models.Report) error {
+	ctx := r.Context()
+	switch report.TargetType {
+	case models.ReportDMMessage:
+		msg, err := h.DMs.DeleteMessage(ctx, report.TargetID, report.ReportedUserID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		h.Hub.SendToDM(msg.DMConversationID, ws.TypeMessageDeleted, msg)
+	case models.ReportSceneMessage:
+		msg, err := h.Scenes.DeleteSceneMessage(ctx, report.TargetID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		h.Hub.SendToScene(msg.SceneID, ws.TypeMessageDeleted, msg)
+	case models.ReportScene:
+		err := h.Scenes.SetArchived(ctx, report.TargetID, true)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		scene, err := h.Scenes.GetScene(ctx, report.TargetID)
+		if err != nil {
+			return err
+		}
+		h.Hub.SendToScene(scene.ID, ws.TypeSceneArchived, scene)
+	}
+	log.Printf("Removed %s %s for report %s", report.TargetType, report.TargetID, report.ID)
+	return nil
+}
+
+// checkAdmin identifies the caller by their bearer token and returns their
+// user ID. It writes a 401 or 403 response and returns false unless the
+// token is valid and was issued to an admin.
+func (h *ReportHandler) checkAdmin(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, err := h.Tokens.AuthenticateBearer(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected %s %s: %v", r.Method, r.URL.Path, err)
+		return "", false
+	}
+	if !h.Admins[userID] {
+		http.Error(w, "Only admins can review reports", http.StatusForbidden)
+		log.Printf("Non-admin %q attempted to review reports", userID)
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,39 @@
+package reports
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterReportRoutes registers the abuse reporting and moderation queue routes with the provided ServeMux.
+func RegisterReportRoutes(mux *http.ServeMux, handler *ReportHandler) {
+	mux.HandleFunc("/api/v1/reports", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Report] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Report] %s %s", r.Method, r.URL.Path)
+		handler.CreateReport(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/admin/reports", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Report] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Report] %s %s", r.Method, r.URL.Path)
+		handler.ListReports(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/admin/reports/resolve", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Report] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Report] %s %s", r.Method, r.URL.Path)
+		handler.ResolveReport(w, r)
+	})
+}
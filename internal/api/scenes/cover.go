@@ -0,0 +1,164 @@
+package scenes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/uploads"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+	"github.com/google/uuid"
+)
+
+// UploadCover handles the HTTP POST request to replace a scene's cover art.
+// It expects a multipart form with "sceneID" and "userID" fields and the image
+// in a "cover" file field. The image is cropped square and stored at each of
+// uploads.CoverSizes. Only the creator may upload.
+func (h *SceneHandler) UploadCover(w http.ResponseWriter, r *http.Request) {
+	// Leave room for the multipart framing and the ID fields
+	r.Body = http.MaxBytesReader(w, r.Body, uploads.MaxCoverSize+64<<10)
+
+	file, header, err := r.FormFile("cover")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, fmt.Sprintf("Cover must be at most %d bytes", uploads.MaxCoverSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading cover form for UploadCover: %v", err)
+		return
+	}
+	defer file.Close()
+
+	sceneID := r.FormValue("sceneID")
+	userID := r.FormValue("userID")
+	if sceneID == "" || userID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for UploadCover")
+		return
+	}
+	if header.Size <= 0 || header.Size > uploads.MaxCoverSize {
+		http.Error(w, fmt.Sprintf("Cover must be between 1 and %d bytes", uploads.MaxCoverSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading cover for scene %s: %v", sceneID, err)
+		return
+	}
+	contentType := http.DetectContentType(sniff[:n])
+	if !uploads.IsCoverType(contentType) {
+		http.Error(w, "Cover must be a JPEG, PNG, or GIF image", http.StatusUnsupportedMediaType)
+		return
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error rewinding cover for scene %s: %v", sceneID, err)
+		return
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), sceneID)
+	if !checkScene(w, err, sceneID) {
+		return
+	}
+	if scene.CreatorID != userID {
+		http.Error(w, "Only the scene creator can change the cover", http.StatusForbidden)
+		log.Printf("User %s attempted to change the cover of scene %s", userID, sceneID)
+		return
+	}
+
+	resized, err := uploads.ResizeCover(file)
+	if errors.Is(err, uploads.ErrInvalidImage) {
+		http.Error(w, "Cover could not be read as an image", http.StatusBadRequest)
+		log.Printf("Validation error: %v for UploadCover", err)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to process cover", http.StatusInternalServerError)
+		log.Printf("Error resizing cover for scene %s: %v", sceneID, err)
+		return
+	}
+
+	key := path.Join("covers", uuid.NewString())
+	for size, data := range resized {
+		err = h.Covers.Put(r.Context(), uploads.CoverKey(key, size), "image/jpeg", bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			h.deleteCover(r, key)
+			http.Error(w, "Failed to store cover", http.StatusInternalServerError)
+			log.Printf("Error storing %s cover for scene %s: %v", size, sceneID, err)
+			return
+		}
+	}
+
+	scene, oldKey, err := h.Store.SetSceneCover(r.Context(), sceneID, key)
+	if !checkScene(w, err, sceneID) {
+		h.deleteCover(r, key)
+		return
+	}
+	if oldKey != "" {
+		h.deleteCover(r, oldKey)
+	}
+	scene.ActiveUsers = h.Hub.GetActiveSceneUsersCount(scene.ID)
+
+	// Let open clients refresh the scene header
+	h.Hub.SendToScene(scene.ID, ws.TypeSceneUpdated, scene)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scene)
+
+	log.Printf("Updated cover for scene %s (%s, %d bytes)", scene.ID, contentType, header.Size)
+}
+
+// deleteCover removes every stored size of a cover that is no longer
+// referenced. Failures only leave orphaned files behind, so they are logged
+// rather than returned.
+func (h *SceneHandler) deleteCover(r *http.Request, key string) {
+	for size := range uploads.CoverSizes {
+		if err := h.Covers.Delete(r.Context(), uploads.CoverKey(key, size)); err != nil {
+			log.Printf("Error deleting %s cover %s: %v", size, key, err)
+		}
+	}
+}
+
+// GetCover handles the HTTP GET request for a scene's uploaded cover by
+// redirecting to where it is stored. It expects the query parameters
+// "scene_id" and "size" (small, medium, or large; defaults to large), and
+// accepts the caller's "user_id", required for workspace scenes.
+func (h *SceneHandler) GetCover(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	size := r.URL.Query().Get("size")
+
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter (e.g., ?scene_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for GetCover")
+		return
+	}
+	if size == "" {
+		size = "large"
+	}
+	if _, ok := uploads.CoverSizes[size]; !ok {
+		http.Error(w, "Size must be small, medium, or large", http.StatusBadRequest)
+		return
+	}
+
+	scene, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id"))
+	if !ok {
+		return
+	}
+	if scene.CoverKey == "" {
+		http.Error(w, "Scene has no uploaded cover", http.StatusNotFound)
+		return
+	}
+
+	http.Redirect(w, r, h.Covers.URL(uploads.CoverKey(scene.CoverKey, size)), http.StatusFound)
+}
@@ -0,0 +1,696 @@
+package scenes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// sceneUserRequest is the payload of the endpoints that take only a scene and a user.
+type sceneUserRequest struct {
+	SceneID string `json:"sceneID"`
+	UserID  string `json:"userID"`
+}
+
+// viewerParam is the caller of a route reading a scene. Scenes in a workspace
+// are not found unless it names a member.
+var viewerParam = openapi.Param{Name: "user_id", Description: "The caller; required for workspace scenes"}
+
+// sceneUpdateFields are the editable details of a scene, taken by both
+// update routes.
+type sceneUpdateFields struct {
+	Name          *string   `json:"name,omitempty"`
+	ArtistName    *string   `json:"artistName,omitempty"`
+	Description   *string   `json:"description,omitempty"`
+	CoverImageURL *string   `json:"coverImageURL,omitempty"`
+	Tags          *[]string `json:"tags,omitempty"`
+
+	RequiresApproval *bool    `json:"requiresApproval,omitempty"`
+	SkipThreshold    *float64 `json:"skipThreshold,omitempty"`
+
+	// MaxDurationSeconds ends the scene that long after it started; 0 removes the limit
+	MaxDurationSeconds *int `json:"maxDurationSeconds,omitempty"`
+
+	// TicketPriceCents charges entry through Stripe (50 to 1000000, in the currency's smallest unit); 0 makes the scene free
+	TicketPriceCents *int    `json:"ticketPriceCents,omitempty"`
+	TicketCurrency   *string `json:"ticketCurrency,omitempty"` // Three-letter ISO 4217 code; defaults to usd
+}
+
+// userRequest is the payload of the resource routes that take a user, the
+// scene being named by the path.
+type userRequest struct {
+	UserID string `json:"userID"`
+}
+
+// Payloads shared by paired endpoints in the OpenAPI document.
+var (
+	sceneDataResponse = struct {
+		Name        string             `json:"name"`
+		ArtistName  string             `json:"artistName"`
+		Listeners   int                `json:"listeners"`
+		ActiveUsers int                `json:"activeUsers"`
+		NowPlaying  *models.NowPlaying `json:"nowPlaying"`
+		Ephemeral   bool               `json:"ephemeral"`
+
+		TicketPriceCents int    `json:"ticketPriceCents"`
+		TicketCurrency   string `json:"ticketCurrency"`
+	}{}
+	activeUsersResponse = struct {
+		SceneID string   `json:"sceneID"`
+		UserIDs []string `json:"userIDs"`
+		Count   int      `json:"count"`
+	}{}
+	reactionBody = struct {
+		MessageID string `json:"messageID"`
+		UserID    string `json:"userID"`
+		Emoji     string `json:"emoji"`
+	}{}
+	pinBody = struct {
+		SceneID   string `json:"sceneID"`
+		UserID    string `json:"userID"`
+		MessageID string `json:"messageID"`
+	}{}
+	membershipResponse = struct {
+		Message   string `json:"message"`
+		Listeners int    `json:"listeners"`
+	}{}
+	messageResponse = struct {
+		Message string `json:"message"`
+	}{}
+)
+
+// Routes describes the routes registered by RegisterSceneRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/create", ID: "createScene", Tag: "Scenes",
+		Summary: "Create a scene",
+		Description: "With scheduledAt set, the scene starts as scheduled. An ephemeral scene's chat, queue, " +
+			"play history, attachments, and transcript exports are deleted when it is archived or closed, and " +
+			"it cannot be exported. With workspaceID set, the scene is " +
+			"private to that workspace and the creator must be a member.",
+		Body: struct {
+			Name        string     `json:"name"`
+			ArtistName  string     `json:"artistName"`
+			CreatorID   string     `json:"CreatorID"`
+			Tags        []string   `json:"tags,omitempty"`
+			ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
+			Ephemeral   bool       `json:"ephemeral,omitempty"`
+			WorkspaceID string     `json:"workspaceID,omitempty"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/list", ID: "listScenes", Tag: "Scenes",
+		Summary: "List the scenes a user has joined",
+		Query: []openapi.Param{
+			{Name: "user_id", Required: true},
+			{Name: "workspace_id", Description: "List the user's scenes in this workspace instead of their public scenes"},
+		},
+		Response: []models.Scene{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/search", ID: "searchScenes", Tag: "Scenes",
+		Summary:     "Search scenes by name, artist, or tag",
+		Description: "Archived scenes are never returned. nextOffset is omitted once a page comes back short.",
+		Query: []openapi.Param{
+			{Name: "q", Required: true},
+			{Name: "workspace_id", Description: "Search this workspace's scenes instead of the public ones"},
+			{Name: "user_id", Description: "A member of the workspace; required with workspace_id"},
+			{Name: "limit", Type: "integer", Description: "Default 20, at most 50"},
+			{Name: "offset", Type: "integer"},
+		},
+		Response: struct {
+			Scenes     []models.Scene `json:"scenes"`
+			NextOffset *int           `json:"nextOffset,omitempty"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/recommended", ID: "getRecommendedScenes", Tag: "Scenes",
+		Summary: "List the public scenes recommended to a user",
+		Description: "Scenes are scored by hashtags shared with scenes the user listened to in the last 30 days, " +
+			"whether the user follows the creator, and time spent listening to the creator. " +
+			"Scores are recomputed by the scene-recommendations job; users without activity get an empty list.",
+		Query: []openapi.Param{
+			{Name: "user_id", Required: true},
+			{Name: "limit", Type: "integer", Description: "Default 20, at most 50"},
+		},
+		Response: struct {
+			Scenes []models.RecommendedScene `json:"scenes"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/tags", ID: "getTagScenes", Tag: "Scenes",
+		Summary:     "List the scenes using a hashtag",
+		Description: "Scenes match when their name, description, or tags carry the hashtag or their chat used it; the most recently used come first. Archived scenes are never returned.",
+		Query: []openapi.Param{
+			{Name: "tag", Required: true, Description: "With or without the leading #"},
+			{Name: "limit", Type: "integer", Description: "Default 20, at most 50"},
+			{Name: "offset", Type: "integer"},
+		},
+		Response: struct {
+			Tag        string         `json:"tag"`
+			Scenes     []models.Scene `json:"scenes"`
+			NextOffset *int           `json:"nextOffset,omitempty"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/tags/trending", ID: "getTrendingTags", Tag: "Scenes",
+		Summary:     "List the hashtags trending over the last 24 hours",
+		Description: "Hashtags are ranked by their uses in scene chat plus scenes newly tagged with them.",
+		Query:       []openapi.Param{{Name: "limit", Type: "integer", Description: "Default 10, at most 50"}},
+		Response: struct {
+			Since time.Time            `json:"since"`
+			Tags  []models.TrendingTag `json:"tags"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/{id}", ID: "getScene", Tag: "Scenes",
+		Summary:     "Fetch a scene's listener counts and current track",
+		Description: "nowPlaying is null when nothing is playing; the scene socket carries now_playing events as the track changes.",
+		Query:       []openapi.Param{viewerParam},
+		Response:    sceneDataResponse,
+	},
+	{
+		Method: http.MethodPatch, Path: "/api/v1/scenes/{id}", ID: "patchScene", Tag: "Scenes",
+		Summary: "Edit a scene's details",
+		Description: "Omitted fields are left unchanged. Only the creator may edit. Setting coverImageURL replaces any uploaded cover. " +
+			"A scene with maxDurationSeconds (60 seconds to 7 days) is sent scene.ending warnings 10, 5, and 1 minutes before it ends, " +
+			"then is archived and sent scene.closed with reason \"ended\".",
+		Body: struct {
+			UserID string `json:"userID"`
+			sceneUpdateFields
+		}{},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/scenes/{id}", ID: "removeScene", Tag: "Scenes",
+		Summary: "Permanently remove a scene",
+		Description: "Only the creator may delete. The scene's participants, messages, and queue are removed together. " +
+			"Connected clients are sent a scene.closed event, and their connections are closed once it is written.",
+		Query:  []openapi.Param{{Name: "user_id", Required: true}},
+		Status: http.StatusNoContent,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/{id}/participants", ID: "listSceneParticipants", Tag: "Scenes",
+		Summary: "List the users currently connected to a scene",
+		Description: "Only connections to this instance are counted. The scene socket carries listener.joined " +
+			"and listener.left events as users open their first or close their last connection.",
+		Query:    []openapi.Param{viewerParam},
+		Response: activeUsersResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/{id}/participants", ID: "addSceneParticipant", Tag: "Scenes",
+		Summary: "Add a user to a scene's listeners",
+		Description: "For scenes that require approval, the user is instead queued for the creator, who is sent a " +
+			"join.requested event, and the response is 202 Accepted with the pending request. " +
+			"Ticketed scenes return 402 Payment Required until the user has paid for a ticket.",
+		Body:     userRequest{},
+		Response: membershipResponse,
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/scenes/{id}/participants", ID: "removeSceneParticipant", Tag: "Scenes",
+		Summary:  "Remove a user from a scene's listeners",
+		Body:     userRequest{},
+		Response: membershipResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/data", ID: "getSceneData", Tag: "Scenes",
+		Summary: "Fetch a scene's listener counts and current track",
+		Description: "Superseded by GET /api/v1/scenes/{id}. nowPlaying is null when nothing is playing; " +
+			"the scene socket carries now_playing events as the track changes.",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			UserID  string `json:"userID"` // The caller; required for workspace scenes
+		}{},
+		Response:   sceneDataResponse,
+		Deprecated: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/active-users", ID: "getSceneActiveUsers", Tag: "Scenes",
+		Summary:     "List the users currently connected to a scene",
+		Description: "Superseded by GET /api/v1/scenes/{id}/participants.",
+		Query:       []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response:    activeUsersResponse,
+		Deprecated:  true,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/join", ID: "joinScene", Tag: "Scenes",
+		Summary:     "Add a user to a scene's listeners",
+		Description: "Superseded by POST /api/v1/scenes/{id}/participants.",
+		Body:        sceneUserRequest{},
+		Response:    membershipResponse,
+		Deprecated:  true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/join-requests", ID: "listSceneJoinRequests", Tag: "Scene moderation",
+		Summary:     "List pending join requests",
+		Description: "Oldest first, each with the requesting user's reputation. Only the host and co-hosts may list them.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "user_id", Required: true},
+		},
+		Response: []models.SceneJoinRequest{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/reputation", ID: "getSceneUserReputation", Tag: "Scene moderation",
+		Summary: "Fetch a user's reputation for moderating a scene",
+		Description: "Scores are recomputed hourly from the user's listening, hosting, and chat over the last 90 days, " +
+			"minus penalties for reports against them and scenes that banned or muted them. Only the host and co-hosts may fetch it.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "user_id", Required: true, Description: "The host or co-host asking"},
+			{Name: "target_id", Required: true, Description: "The user to look up"},
+		},
+		Response: models.Reputation{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/join-requests/approve", ID: "approveSceneJoinRequest", Tag: "Scene moderation",
+		Summary:     "Let a pending user into a scene",
+		Description: "The user is sent a join.resolved event.",
+		Body:        moderationRequest{},
+		Response:    models.JoinDecision{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/join-requests/reject", ID: "rejectSceneJoinRequest", Tag: "Scene moderation",
+		Summary:     "Turn down a pending join request",
+		Description: "The user is sent a join.resolved event and may ask again.",
+		Body:        moderationRequest{},
+		Response:    models.JoinDecision{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/leave", ID: "leaveScene", Tag: "Scenes",
+		Summary:     "Remove a user from a scene's listeners",
+		Description: "Superseded by DELETE /api/v1/scenes/{id}/participants.",
+		Body:        sceneUserRequest{},
+		Response:    membershipResponse,
+		Deprecated:  true,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/messages", ID: "sendSceneMessage", Tag: "Scene chat",
+		Summary:     "Post a chat message in a scene",
+		Description: "The stored message is broadcast to every WebSocket client connected to the scene. @username mentions are resolved into the message's mentions, and each mentioned user is sent a mention event.",
+		Body: struct {
+			SceneID       string   `json:"sceneID"`
+			SenderID      string   `json:"senderID"`
+			Content       string   `json:"content"`
+			AttachmentIDs []string `json:"attachmentIDs,omitempty"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.SceneMessage{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/messages", ID: "listSceneMessages", Tag: "Scene chat",
+		Summary:  "List a scene's chat history",
+		Query:    []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response: []models.SceneMessage{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/messages/search", ID: "searchSceneMessages", Tag: "Scene chat",
+		Summary:     "Search a scene's chat",
+		Description: "Matches are returned newest first with a few messages of context around each.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "q", Required: true},
+			{Name: "limit", Type: "integer"},
+			viewerParam,
+		},
+		Response: []models.SceneSearchResult{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/pins", ID: "listScenePins", Tag: "Scene chat",
+		Summary:  "List a scene's pinned messages",
+		Query:    []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response: []models.PinnedMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/pins/add", ID: "pinSceneMessage", Tag: "Scene chat",
+		Summary:     "Pin a scene chat message",
+		Description: "Only the scene host and co-hosts may pin.",
+		Body:        pinBody,
+		Response:    []models.PinnedMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/pins/remove", ID: "unpinSceneMessage", Tag: "Scene chat",
+		Summary:     "Unpin a scene chat message",
+		Description: "Only the scene host and co-hosts may unpin.",
+		Body:        pinBody,
+		Response:    []models.PinnedMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/messages/reactions/add", ID: "addSceneReaction", Tag: "Scene chat",
+		Summary:  "React to a scene chat message",
+		Body:     reactionBody,
+		Response: models.SceneMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/messages/reactions/remove", ID: "removeSceneReaction", Tag: "Scene chat",
+		Summary:  "Withdraw a reaction from a scene chat message",
+		Body:     reactionBody,
+		Response: models.SceneMessage{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/polls", ID: "createScenePoll", Tag: "Scene polls",
+		Summary:     "Ask a question in a scene",
+		Description: "Takes 2 to 10 options. Only the host and co-hosts may create polls; the poll is broadcast as a poll event.",
+		Body: struct {
+			SceneID  string   `json:"sceneID"`
+			UserID   string   `json:"userID"`
+			Question string   `json:"question"`
+			Options  []string `json:"options"`
+		}{},
+		Response: models.ScenePoll{},
+		Status:   http.StatusCreated,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/polls", ID: "listScenePolls", Tag: "Scene polls",
+		Summary:  "List a scene's polls with their results",
+		Query:    []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response: []models.ScenePoll{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/polls/results", ID: "getScenePollResults", Tag: "Scene polls",
+		Summary:  "Get a poll's current vote counts",
+		Query:    []openapi.Param{{Name: "poll_id", Required: true}, viewerParam},
+		Response: models.ScenePoll{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/polls/vote", ID: "voteScenePoll", Tag: "Scene polls",
+		Summary:     "Vote in a poll",
+		Description: "option is zero-based. Only participants may vote; voting again changes the choice. The updated counts are broadcast as a poll event.",
+		Body: struct {
+			PollID string `json:"pollID"`
+			UserID string `json:"userID"`
+			Option int    `json:"option"`
+		}{},
+		Response: models.ScenePoll{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/polls/close", ID: "closeScenePoll", Tag: "Scene polls",
+		Summary:     "Stop a poll accepting votes",
+		Description: "Only the host and co-hosts may close polls; the final results are broadcast as a poll event.",
+		Body: struct {
+			PollID string `json:"pollID"`
+			UserID string `json:"userID"`
+		}{},
+		Response: models.ScenePoll{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/queue/add", ID: "addToQueue", Tag: "Scene queue",
+		Summary: "Queue a track in a scene",
+		Body: struct {
+			SceneID    string `json:"sceneID"`
+			UserID     string `json:"userID"`
+			Title      string `json:"title"`
+			Artist     string `json:"artist"`
+			ArtworkURL string `json:"artworkURL"`
+			ProviderID string `json:"providerID"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.QueueItem{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/queue/remove", ID: "removeFromQueue", Tag: "Scene queue",
+		Summary: "Drop a track from a scene's queue",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			ItemID  string `json:"itemID"`
+		}{},
+		Response: []models.QueueItem{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/queue/reorder", ID: "reorderQueue", Tag: "Scene queue",
+		Summary:     "Change the order of a scene's queue",
+		Description: "itemIDs is the complete queue in the new order.",
+		Body: struct {
+			SceneID string   `json:"sceneID"`
+			ItemIDs []string `json:"itemIDs"`
+		}{},
+		Response: []models.QueueItem{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/queue/list", ID: "listQueue", Tag: "Scene queue",
+		Summary:  "List a scene's queued tracks in play order",
+		Query:    []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response: []models.QueueItem{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/queue/vote", ID: "voteQueueItem", Tag: "Scene queue",
+		Summary:     "Vote a queued track up or down",
+		Description: "vote is 1 for up, -1 for down, or 0 to withdraw. Only scene participants may vote.",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			UserID  string `json:"userID"`
+			ItemID  string `json:"itemID"`
+			Vote    int    `json:"vote"`
+		}{},
+		Response: []models.QueueItem{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/api/v1/scenes/update", ID: "updateScene", Tag: "Scenes",
+		Summary:     "Edit a scene's details",
+		Description: "Superseded by PATCH /api/v1/scenes/{id}.",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			UserID  string `json:"userID"`
+			sceneUpdateFields
+		}{},
+		Response:   models.Scene{},
+		Deprecated: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/cover", ID: "getSceneCover", Tag: "Scenes",
+		Summary:     "Redirect to a scene's uploaded cover",
+		Description: "Redirects to where the cover image is stored.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "size", Description: "small (160px), medium (320px), or large (640px, the default)"},
+			viewerParam,
+		},
+		Status: http.StatusFound,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/cover", ID: "uploadSceneCover", Tag: "Scenes",
+		Summary: "Replace a scene's cover art",
+		Description: "Only the creator may upload. The image (JPEG, PNG, or GIF) is cropped square and stored at " +
+			"each size listed in coverImages; coverImageURL points at the large copy.",
+		Form: []openapi.Param{
+			{Name: "sceneID", Required: true},
+			{Name: "userID", Required: true},
+			{Name: "cover", Type: "file", Required: true},
+		},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/scenes/delete", ID: "deleteScene", Tag: "Scenes",
+		Summary:     "Permanently remove a scene",
+		Description: "Superseded by DELETE /api/v1/scenes/{id}.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "user_id", Required: true},
+		},
+		Status:     http.StatusNoContent,
+		Deprecated: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/archive", ID: "archiveScene", Tag: "Scenes",
+		Summary:     "Archive or restore a scene",
+		Description: "Only the creator may archive.",
+		Body: struct {
+			SceneID  string `json:"sceneID"`
+			UserID   string `json:"userID"`
+			Archived bool   `json:"archived"`
+		}{},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/kick", ID: "kickSceneUser", Tag: "Scene moderation",
+		Summary:     "Remove a user from a scene",
+		Description: "Closes the user's WebSocket connections. Kicked users may rejoin.",
+		Body:        moderationRequest{},
+		Response: struct {
+			Message      string `json:"message"`
+			Disconnected int    `json:"disconnected"`
+		}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/ban", ID: "banSceneUser", Tag: "Scene moderation",
+		Summary:     "Ban a user from a scene",
+		Description: "The user is removed from the participants, disconnected, and blocked from rejoining.",
+		Body:        moderationRequest{},
+		Response:    messageResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/unban", ID: "unbanSceneUser", Tag: "Scene moderation",
+		Summary:  "Lift a user's ban from a scene",
+		Body:     moderationRequest{},
+		Response: messageResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/mute", ID: "muteSceneUser", Tag: "Scene moderation",
+		Summary:  "Stop a user posting chat messages in a scene",
+		Body:     moderationRequest{},
+		Response: messageResponse,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/unmute", ID: "unmuteSceneUser", Tag: "Scene moderation",
+		Summary:  "Let a muted user post again",
+		Body:     moderationRequest{},
+		Response: messageResponse,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/roles", ID: "listSceneRoles", Tag: "Scene moderation",
+		Summary:     "List a scene's host and co-hosts",
+		Description: "Host first. Participants not listed are listeners.",
+		Query:       []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response:    []models.SceneRoleAssignment{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/roles/promote", ID: "promoteSceneUser", Tag: "Scene moderation",
+		Summary:     "Make a participant a co-host",
+		Description: "Co-hosts may moderate, pin messages, resolve join requests, and control playback. Only the host may promote; the scene is sent a role.changed event.",
+		Body:        moderationRequest{},
+		Response:    models.SceneRoleAssignment{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/roles/demote", ID: "demoteSceneUser", Tag: "Scene moderation",
+		Summary:     "Make a co-host a listener again",
+		Description: "Only the host may demote; the scene is sent a role.changed event.",
+		Body:        moderationRequest{},
+		Response:    models.SceneRoleAssignment{},
+	},
+	{
+		Method: http.MethodGet, Path: "/ws/scenes", ID: "connectSceneSocket", Tag: "Scenes",
+		Summary: "Open the scene's WebSocket",
+		Description: "Upgrades to a WebSocket that carries the scene's live events. Only the creator and joined users " +
+			"may connect. The wsToken may instead follow scenyx.token in Sec-WebSocket-Protocol. Offering " +
+			"scenyx.msgpack there switches frames in both directions to MessagePack. Connections over " +
+			"the per-user or per-address cap are closed with code 4029.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "token", Description: "wsToken from login or /api/v1/users/ws-token"},
+			{Name: "user_id", Description: "Must match the token's user if given"},
+			{Name: "last_event_id", Description: "ID of the last event received; newer events are replayed, or a resync event is sent"},
+		},
+		Status: http.StatusSwitchingProtocols,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/generate-share-link", ID: "generateShareLink", Tag: "Scenes",
+		Summary:  "Confirm a scene exists and get its share links",
+		Query:    []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response: sceneShareLinks{},
+	},
+	{
+		Method: http.MethodGet, Path: sharePagePath, ID: "shareScene", Tag: "Scenes",
+		Summary: "Serve a scene's share page",
+		Description: "Returns an HTML page with Open Graph and Twitter card tags (title, description, cover image) " +
+			"so shared links unfurl, and redirects browsers to the frontend scene view. Workspace scenes have no share page.",
+		Query: []openapi.Param{{Name: "scene_id", Required: true}},
+	},
+	{
+		Method: http.MethodGet, Path: slugSharePrefix + "{slug}", ID: "shareSceneBySlug", Tag: "Scenes",
+		Summary:     "Serve the share page of a scene by its slug",
+		Description: "Same page as " + sharePagePath + "; share links use this form once the creator claims a slug.",
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/slug", ID: "setSceneSlug", Tag: "Scenes",
+		Summary: "Claim a human-readable slug for a scene",
+		Description: "Only the creator may set it. Slugs are 3-48 lowercase letters, digits, and single hyphens, " +
+			"unique across scenes (409 if taken). An empty slug releases the current one.",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			UserID  string `json:"userID"`
+			Slug    string `json:"slug"`
+		}{},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/transcript", ID: "exportSceneTranscript", Tag: "Scenes",
+		Summary: "Export a scene's chat and track history as JSON or CSV",
+		Description: "Only the creator may export, and ephemeral scenes cannot be exported (409). Scenes with up to 2000 messages are returned as a file download " +
+			"(a SceneTranscript for json, one row per message or track for csv). Larger scenes are exported in the " +
+			"background: the reply is 202 Accepted with the export, and a transcript.ready event is sent when it is done.",
+		Body: struct {
+			SceneID string                  `json:"sceneID"`
+			UserID  string                  `json:"userID"`
+			Format  models.TranscriptFormat `json:"format,omitempty"`
+		}{},
+		Response: models.SceneTranscript{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/transcript", ID: "getSceneTranscriptExport", Tag: "Scenes",
+		Summary:  "Check on a background transcript export; downloadURL is set once it is ready",
+		Query:    []openapi.Param{{Name: "export_id", Required: true}, {Name: "user_id", Required: true}},
+		Response: models.TranscriptExport{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/by-slug", ID: "getSceneBySlug", Tag: "Scenes",
+		Summary:  "Resolve a slug to its scene",
+		Query:    []openapi.Param{{Name: "slug", Required: true}, viewerParam},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/join-by-link", ID: "joinSceneByLink", Tag: "Scenes",
+		Summary:     "Join a scene via a shared URL",
+		Description: "Redirects to the frontend scene view. Users without a ticket to a ticketed scene are redirected without joining.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "user_id", Required: true},
+		},
+		Status: http.StatusFound,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/schedule", ID: "rescheduleScene", Tag: "Scenes",
+		Summary:     "Move a scheduled scene's start time",
+		Description: "Only the creator may reschedule.",
+		Body: struct {
+			SceneID     string    `json:"sceneID"`
+			UserID      string    `json:"userID"`
+			ScheduledAt time.Time `json:"scheduledAt"`
+		}{},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/rsvp/add", ID: "addRSVP", Tag: "Scenes",
+		Summary:  "RSVP to a scheduled scene",
+		Body:     sceneUserRequest{},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/scenes/rsvp/remove", ID: "removeRSVP", Tag: "Scenes",
+		Summary:  "Withdraw an RSVP",
+		Body:     sceneUserRequest{},
+		Response: models.Scene{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/rsvps", ID: "listRSVPs", Tag: "Scenes",
+		Summary:  "List who RSVP'd to a scene",
+		Query:    []openapi.Param{{Name: "scene_id", Required: true}, viewerParam},
+		Response: []string{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/analytics", ID: "getSceneAnalytics", Tag: "Scene analytics",
+		Summary:     "Fetch a scene's listener analytics",
+		Description: "Only the scene creator may view analytics.",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "user_id", Required: true},
+			{Name: "from", Format: "date-time", Description: "Default: 7 days before to"},
+			{Name: "to", Format: "date-time", Description: "Default: now"},
+			{Name: "bucket", Description: `"hour" (default) or "day"`},
+		},
+		Response: models.SceneAnalytics{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/scenes/stats", ID: "getSceneStats", Tag: "Scene analytics",
+		Summary: "Fetch a scene's daily peak and average concurrent listeners",
+		Query: []openapi.Param{
+			{Name: "scene_id", Required: true},
+			{Name: "days", Type: "integer", Description: "Default 30, at most 365"},
+			viewerParam,
+		},
+		Response: []models.SceneDayStats{},
+	},
+}
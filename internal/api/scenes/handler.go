@@ -2,30 +2,126 @@ package scenes
 
 import (
 	"encoding/json" // For encoding and decoding JSON
+	"errors"        // For matching storage sentinel errors
 	"fmt"           // For string formatting, especially for redirects
 	"log"           // For logging information
 	"net/http"      // For HTTP request and response handling
+	"net/url"       // For validating cover image URLs
+	"slices"        // For checking scene participation
+	"strconv"       // For parsing pagination parameters
+	"strings"       // For trimming updated scene fields
+	"time"          // For validating scheduled start times
 
+	"github.com/Vasu1712/scenyx-backend/internal/app/achievements" // Badges earned by creating scenes
+	"github.com/Vasu1712/scenyx-backend/internal/app/links"      // Frontend URLs for redirects, share links, and notifications
+	"github.com/Vasu1712/scenyx-backend/internal/app/mentions"   // @username parsing for message mentions
+	"github.com/Vasu1712/scenyx-backend/internal/app/moderation" // Content filter run before messages are stored
+	"github.com/Vasu1712/scenyx-backend/internal/app/notify"     // Mention and join notifications, filtered by user preferences
+	"github.com/Vasu1712/scenyx-backend/internal/app/transcripts" // Chat and track history exports
+	"github.com/Vasu1712/scenyx-backend/internal/app/uploads"    // Storage for uploaded cover art
+	"github.com/Vasu1712/scenyx-backend/internal/app/webhooks"   // Outbound event webhooks
 	"github.com/Vasu1712/scenyx-backend/internal/models" // Import models package to use Scene struct
-	"github.com/Vasu1712/scenyx-backend/internal/storage/postgres" // Import the postgres package to use PostgresSceneStore
-	"github.com/Vasu1712/scenyx-backend/internal/ws"             // Import the WebSocket hub
-	"github.com/gorilla/websocket"                              // WebSocket library
+	"github.com/Vasu1712/scenyx-backend/internal/storage"    // Storage interfaces and sentinel errors
+	"github.com/Vasu1712/scenyx-backend/internal/ws"         // Import the WebSocket hub
+	"github.com/gorilla/websocket"                          // WebSocket library
 )
 
 // SceneHandler holds the dependencies for handling scene-related HTTP requests.
 type SceneHandler struct {
-	Store *postgres.PostgresSceneStore // A pointer to the PostgresSceneStore to interact with scene data
-	Hub   *ws.Hub                      // A pointer to the WebSocket Hub for active user tracking
+	Store       storage.SceneStore      // The SceneStore used to interact with scene data
+	Analytics   storage.AnalyticsStore  // Listening sessions reported by the hub
+	Hashtags    storage.HashtagStore    // Hashtags of scenes and their chat, for tag pages and trending
+
+	Recommendations storage.RecommendationStore // Per-user scene recommendations built by a background job
+	Playback    storage.PlaybackStore   // Supplies the now-playing track in scene data
+	Attachments storage.AttachmentStore // Message attachments; nil when uploads are disabled
+	Covers      uploads.Blobs           // Where uploaded scene covers are stored
+	Moderator   *moderation.Moderator   // Content filter; nil when filtering is disabled
+	Webhooks    *webhooks.Dispatcher    // Outbound event webhooks; nil when disabled
+	Hub         *ws.Hub                 // A pointer to the WebSocket Hub for active user tracking
+	Notify      *notify.Dispatcher      // Sends notifications to individual users, honoring their preferences
+	Tokens      *ws.TokenSigner         // Verifies the tokens that authenticate WebSocket upgrades
+	Links       *links.Builder          // Forms frontend URLs for redirects, share links, and notifications
+	Transcripts *transcripts.Service    // Exports chat and track history for scene creators
+	Tickets     storage.TicketStore     // Tickets bought for ticketed scenes; nil when Stripe is not configured
+	Achievements *achievements.Engine   // Awards badges for scene events
+	Reputation  storage.ReputationStore // User standing shown to hosts deciding on join requests and bans
+	Workspaces  storage.WorkspaceStore  // Memberships of the workspaces private scenes belong to
+}
+
+// joinRequestNotice is the join.requested payload, with a link the creator
+// can follow to the scene.
+type joinRequestNotice struct {
+	*models.SceneJoinRequest
+	Link string `json:"link"`
+}
+
+// joinDecisionNotice is the join.resolved payload. Approved users get a link
+// to the scene.
+type joinDecisionNotice struct {
+	models.JoinDecision
+	Link string `json:"link,omitempty"`
+}
+
+// checkScene writes the appropriate error response for a failed scene lookup.
+// It returns true if err is nil and the handler should continue.
+func checkScene(w http.ResponseWriter, err error, sceneID string) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		log.Printf("Scene not found for ID: %s", sceneID)
+		return false
+	}
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+	log.Printf("Error accessing scene %s: %v", sceneID, err)
+	return false
+}
+
+// getVisibleScene loads a scene for userID, the caller, writing the error
+// response if it cannot. Workspace scenes are reported not found to anyone
+// but members, so private scenes cannot be discovered by ID or slug.
+func (h *SceneHandler) getVisibleScene(w http.ResponseWriter, r *http.Request, sceneID, userID string) (*models.Scene, bool) {
+	scene, err := h.Store.GetScene(r.Context(), sceneID)
+	if !checkScene(w, err, sceneID) {
+		return nil, false
+	}
+	return scene, h.checkVisible(w, r, scene, userID)
+}
+
+// checkVisible writes a not found response and returns false unless userID
+// may see scene, see getVisibleScene.
+func (h *SceneHandler) checkVisible(w http.ResponseWriter, r *http.Request, scene *models.Scene, userID string) bool {
+	visible, err := storage.SceneVisible(r.Context(), h.Workspaces, scene, userID)
+	if err == nil && !visible {
+		err = storage.ErrNotFound
+	}
+	return checkScene(w, err, scene.ID)
+}
+
+// sceneIDParam returns the scene ID of a RESTful route, /api/v1/scenes/{id},
+// or legacy, the ID the RPC-style route was given in its query or body.
+func sceneIDParam(r *http.Request, legacy string) string {
+	if id := r.PathValue("id"); id != "" {
+		return id
+	}
+	return legacy
 }
 
 // CreateScene handles the HTTP POST request to create a new scene.
-// It expects a JSON payload in the request body with "name", "artistName", and "CreatorID" fields.
+// It expects a JSON payload in the request body with "name", "artistName", and "CreatorID" fields,
+// and optionally "tags", "scheduledAt", "ephemeral", and "workspaceID" to make the scene private to a workspace.
 func (h *SceneHandler) CreateScene(w http.ResponseWriter, r *http.Request) {
 	// Define a struct to parse the incoming JSON request body
 	var req struct {
-		Name       string `json:"name"`
-		ArtistName string `json:"artistName"` // Matches models.Scene and frontend payload
-		CreatorID  string `json:"CreatorID"`  // Matches models.Scene and frontend payload
+		Name        string     `json:"name"`
+		ArtistName  string     `json:"artistName"`  // Matches models.Scene and frontend payload
+		CreatorID   string     `json:"CreatorID"`   // Matches models.Scene and frontend payload
+		Tags        []string   `json:"tags"`        // Optional discovery tags
+		ScheduledAt *time.Time `json:"scheduledAt"` // Optional future start time; the scene starts as scheduled
+		Ephemeral   bool       `json:"ephemeral"`   // Delete chat and queue history when the scene ends
+		WorkspaceID string     `json:"workspaceID"` // Optional workspace the scene is private to; the creator must be a member
 	}
 
 	// Decode the JSON request body into the req struct
@@ -43,28 +139,53 @@ func (h *SceneHandler) CreateScene(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Validation error: %v for CreateScene", err)
+		return
+	}
+
+	if req.ScheduledAt != nil && !req.ScheduledAt.After(time.Now()) {
+		http.Error(w, "Scheduled time must be in the future", http.StatusBadRequest)
+		log.Printf("Validation error: Scheduled time %s is not in the future for CreateScene", req.ScheduledAt)
+		return
+	}
+
 	// Call the CreateScene method on the SceneStore to save the new scene.
-	scene := h.Store.CreateScene(req.Name, req.ArtistName, req.CreatorID)
-	if scene == nil {
+	scene, err := h.Store.CreateScene(r.Context(), req.WorkspaceID, req.Name, req.ArtistName, req.CreatorID, tags, req.ScheduledAt, req.Ephemeral)
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "Creator is not a member of the workspace", http.StatusForbidden)
+		log.Printf("Rejected CreateScene: %s is not a member of workspace %s", req.CreatorID, req.WorkspaceID)
+		return
+	}
+	if err != nil {
 		http.Error(w, "Failed to create scene", http.StatusInternalServerError)
+		log.Printf("Error creating scene: %v", err)
 		return
 	}
 
+	h.recordSceneHashtags(r, scene)
+
 	// Set the Content-Type header to application/json for the response
 	w.Header().Set("Content-Type", "application/json")
 	// Set the HTTP status code to 201 Created
 	w.WriteHeader(http.StatusCreated)
 	// Encode the created scene object into JSON and write it to the response body
 	json.NewEncoder(w).Encode(scene)
+	h.Webhooks.Emit(models.EventSceneCreated, scene.ID, scene)
+	h.Achievements.Handle(achievements.Event{Name: achievements.EventSceneCreated, UserID: scene.CreatorID, SceneID: scene.ID, At: scene.CreatedAt})
 
 	log.Printf("Created scene: ID=%s, Name=%s, Artist=%s, CreatorID=%s, Listeners=%d",
 		scene.ID, scene.Name, scene.ArtistName, scene.CreatorID, scene.Listeners)
 }
 
 // ListScenes handles the HTTP GET request to list all scenes associated with a user.
-// It expects the user ID as a query parameter "user_id".
+// It expects the user ID as a query parameter "user_id". With "workspace_id" it lists
+// the user's scenes in that workspace instead of their public scenes.
 func (h *SceneHandler) ListScenes(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
+	workspaceID := r.URL.Query().Get("workspace_id")
 
 	if userID == "" {
 		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
@@ -72,8 +193,13 @@ func (h *SceneHandler) ListScenes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	scenes := h.Store.GetScenesForUser(userID)
-	if scenes == nil { // Handle case where no scenes are found or an error occurred
+	scenes, err := h.Store.GetScenesForUser(r.Context(), userID, workspaceID)
+	if err != nil {
+		http.Error(w, "Failed to list scenes", http.StatusInternalServerError)
+		log.Printf("Error listing scenes for user %s: %v", userID, err)
+		return
+	}
+	if scenes == nil { // Handle case where no scenes are found
 		scenes = []*models.Scene{} // Return an empty slice instead of nil
 	}
 
@@ -89,19 +215,113 @@ func (h *SceneHandler) ListScenes(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Listed %d scenes for user ID: %s", len(scenes), userID)
 }
 
-// GetSceneData handles the HTTP POST request to get specific data for a scene.
-// It expects a JSON payload in the request body with a "sceneID" field.
-// It returns artistName, listeners, and activeUsers.
+// SearchScenes handles the HTTP GET request to search scenes by name, artist, or tag.
+// It expects the search text as the query parameter "q" and accepts optional
+// "limit" (default 20, at most 50) and "offset" parameters for pagination.
+// Archived scenes are never returned. Public scenes are searched unless
+// "workspace_id" is set, in which case "user_id" must name a member.
+func (h *SceneHandler) SearchScenes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := strings.TrimSpace(q.Get("q"))
+	workspaceID := q.Get("workspace_id")
+
+	if query == "" {
+		http.Error(w, "Search text is required as a query parameter (e.g., ?q=lofi)", http.StatusBadRequest)
+		log.Println("Validation error: q is empty for SearchScenes")
+		return
+	}
+
+	limit, offset := 20, 0
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+	if o := q.Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			http.Error(w, "Offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+
+	if workspaceID != "" && !h.checkWorkspaceMember(w, r, workspaceID, q.Get("user_id")) {
+		return
+	}
+
+	scenes, err := h.Store.SearchScenes(r.Context(), workspaceID, query, limit, offset)
+	if err != nil {
+		http.Error(w, "Failed to search scenes", http.StatusInternalServerError)
+		log.Printf("Error searching scenes for %q: %v", query, err)
+		return
+	}
+	if scenes == nil {
+		scenes = []*models.Scene{} // Return an empty slice instead of nil
+	}
+	for _, scene := range scenes {
+		scene.ActiveUsers = h.Hub.GetActiveSceneUsersCount(scene.ID)
+	}
+
+	// nextOffset is omitted once a page comes back short
+	res := struct {
+		Scenes     []*models.Scene `json:"scenes"`
+		NextOffset *int            `json:"nextOffset,omitempty"`
+	}{Scenes: scenes}
+	if len(scenes) == limit {
+		next := offset + limit
+		res.NextOffset = &next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+// checkWorkspaceMember writes an error response and returns false unless
+// userID is a member of the workspace.
+func (h *SceneHandler) checkWorkspaceMember(w http.ResponseWriter, r *http.Request, workspaceID, userID string) bool {
+	if userID == "" {
+		http.Error(w, "User ID is required to search a workspace", http.StatusBadRequest)
+		return false
+	}
+	_, err := h.Workspaces.GetMemberRole(r.Context(), workspaceID, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a member of the workspace", http.StatusForbidden)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking membership of %s in workspace %s: %v", userID, workspaceID, err)
+		return false
+	}
+	return true
+}
+
+// GetSceneData handles the HTTP request to get specific data for a scene:
+// GET /api/v1/scenes/{id}, or the legacy POST /api/v1/scenes/data with a
+// JSON payload holding a "sceneID" field. The caller's user ID, the "user_id"
+// query parameter or "userID" field, is required for workspace scenes.
+// It returns artistName, listeners, activeUsers, the nowPlaying track,
+// whether the scene is ephemeral, and its ticket price.
 func (h *SceneHandler) GetSceneData(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		SceneID string `json:"sceneID"` // Scene ID from the request body
+		UserID  string `json:"userID"`  // The caller, for workspace scenes
 	}
 
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		log.Printf("Error decoding request body for GetSceneData: %v", err)
-		return
+	req.SceneID = r.PathValue("id")
+	req.UserID = r.URL.Query().Get("user_id")
+	if req.SceneID == "" {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			log.Printf("Error decoding request body for GetSceneData: %v", err)
+			return
+		}
 	}
 
 	if req.SceneID == "" {
@@ -110,28 +330,43 @@ func (h *SceneHandler) GetSceneData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	scene := h.Store.GetScene(req.SceneID)
-	if scene == nil {
-		http.Error(w, "Scene not found", http.StatusNotFound)
-		log.Printf("Scene not found for ID: %s", req.SceneID)
+	scene, ok := h.getVisibleScene(w, r, req.SceneID, req.UserID)
+	if !ok {
 		return
 	}
 
 	// Dynamically get the active users count from the hub
 	activeUsers := h.Hub.GetActiveSceneUsersCount(scene.ID)
 
+	// Late joiners learn the current track here rather than waiting for the next now_playing event
+	var nowPlaying *models.NowPlaying
+	state, err := h.Playback.GetPlayback(r.Context(), scene.ID)
+	if err == nil {
+		nowPlaying = state.NowPlaying()
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Error getting playback for scene %s in GetSceneData: %v", scene.ID, err)
+	}
+
 	// Define the response struct to match the desired output format and frontend's expectations
 	var res struct {
 		Name       	 string `json:"name"`
 		ArtistName   string `json:"artistName"`
 		Listeners    int    `json:"listeners"`
 		ActiveUsers  int    `json:"activeUsers"`
+		NowPlaying   *models.NowPlaying `json:"nowPlaying"` // Null when nothing is playing
+		Ephemeral    bool   `json:"ephemeral"`  // History is deleted when the scene ends
+		TicketPriceCents int    `json:"ticketPriceCents"` // 0 for free scenes
+		TicketCurrency   string `json:"ticketCurrency"`
 	}
 
 	res.Name = scene.Name
 	res.ArtistName = scene.ArtistName
 	res.Listeners = scene.Listeners // This is now derived from len(scene.JoinedUserIDs)
 	res.ActiveUsers = activeUsers   // This is now from the WebSocket hub
+	res.NowPlaying = nowPlaying
+	res.Ephemeral = scene.Ephemeral
+	res.TicketPriceCents = scene.TicketPriceCents
+	res.TicketCurrency = scene.TicketCurrency
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -140,8 +375,38 @@ func (h *SceneHandler) GetSceneData(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Retrieved data for scene ID: %s (Listeners: %d, ActiveUsers: %d)", req.SceneID, res.Listeners, res.ActiveUsers)
 }
 
+// GetActiveUsers handles the HTTP GET request for the users currently
+// connected to a scene. It expects the scene ID in the path or, on the legacy
+// route, the query parameter "scene_id", and accepts the caller's "user_id",
+// required for workspace scenes.
+func (h *SceneHandler) GetActiveUsers(w http.ResponseWriter, r *http.Request) {
+	sceneID := sceneIDParam(r, r.URL.Query().Get("scene_id"))
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter (e.g., ?scene_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for GetActiveUsers")
+		return
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	var res struct {
+		SceneID string   `json:"sceneID"`
+		UserIDs []string `json:"userIDs"`
+		Count   int      `json:"count"`
+	}
+	res.SceneID = sceneID
+	res.UserIDs = h.Hub.GetActiveSceneUsers(sceneID)
+	res.Count = len(res.UserIDs)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
 // JoinScene handles the HTTP POST request to add a user to a scene's joined listeners.
-// It expects a JSON payload with "sceneID" and "userID".
+// It expects a JSON payload with "userID", and "sceneID" unless the scene ID is in the path.
 func (h *SceneHandler) JoinScene(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		SceneID string `json:"sceneID"`
@@ -154,6 +419,7 @@ func (h *SceneHandler) JoinScene(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error decoding request body for JoinScene: %v", err)
 		return
 	}
+	req.SceneID = sceneIDParam(r, req.SceneID)
 
 	if req.SceneID == "" || req.UserID == "" {
 		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
@@ -161,25 +427,46 @@ func (h *SceneHandler) JoinScene(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if h.Store.JoinScene(req.SceneID, req.UserID) {
-		scene := h.Store.GetScene(req.SceneID) // Get updated scene to return current listener count
-		if scene == nil {
-			http.Error(w, "Scene not found after join operation", http.StatusNotFound)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message":   "User joined scene successfully",
-			"listeners": scene.Listeners,
-		})
-	} else {
-		http.Error(w, "Failed to join scene or user already joined", http.StatusConflict)
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if !h.checkTicket(w, r, scene, req.UserID) {
+		return
+	}
+	if scene.RequiresApproval && req.UserID != scene.CreatorID {
+		h.requestJoin(w, r, scene, req.UserID)
+		return
+	}
+
+	err = h.Store.JoinScene(r.Context(), req.SceneID, req.UserID)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "User already joined scene", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "User is banned from this scene or not a member of its workspace", http.StatusForbidden)
+		return
+	}
+	if !checkScene(w, err, req.SceneID) {
+		return
 	}
+
+	scene, err = h.Store.GetScene(r.Context(), req.SceneID) // Get updated scene to return current listener count
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	h.Webhooks.Emit(models.EventUserJoined, scene.ID, webhooks.UserJoined{SceneID: scene.ID, UserID: req.UserID})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "User joined scene successfully",
+		"listeners": scene.Listeners,
+	})
 }
 
-// LeaveScene handles the HTTP POST request to remove a user from a scene's joined listeners.
-// It expects a JSON payload with "sceneID" and "userID".
+// LeaveScene handles the HTTP request to remove a user from a scene's joined listeners.
+// It expects a JSON payload with "userID", and "sceneID" unless the scene ID is in the path.
 func (h *SceneHandler) LeaveScene(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		SceneID string `json:"sceneID"`
@@ -192,6 +479,7 @@ func (h *SceneHandler) LeaveScene(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error decoding request body for LeaveScene: %v", err)
 		return
 	}
+	req.SceneID = sceneIDParam(r, req.SceneID)
 
 	if req.SceneID == "" || req.UserID == "" {
 		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
@@ -199,26 +487,193 @@ func (h *SceneHandler) LeaveScene(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if h.Store.LeaveScene(req.SceneID, req.UserID) {
-		scene := h.Store.GetScene(req.SceneID) // Get updated scene to return current listener count
-		if scene == nil {
-			// This case means the scene might have been deleted or an error occurred after leaving
-			http.Error(w, "Scene not found or error after leave operation", http.StatusNotFound)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message":   "User left scene successfully",
-			"listeners": scene.Listeners,
-		})
-	} else {
-		http.Error(w, "Failed to leave scene or user not found in joined list", http.StatusConflict)
+	err = h.Store.LeaveScene(r.Context(), req.SceneID, req.UserID)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "User not found in joined list", http.StatusConflict)
+		return
+	}
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID) // Get updated scene to return current listener count
+	if !checkScene(w, err, req.SceneID) {
+		return
 	}
+	h.Webhooks.Emit(models.EventUserJoined, scene.ID, webhooks.UserJoined{SceneID: scene.ID, UserID: req.UserID})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "User left scene successfully",
+		"listeners": scene.Listeners,
+	})
+}
+
+// hasTicket reports whether userID may enter scene as far as tickets are
+// concerned: the scene is free, they created it, or they hold a paid ticket.
+func (h *SceneHandler) hasTicket(r *http.Request, scene *models.Scene, userID string) (bool, error) {
+	if !scene.Ticketed() || userID == scene.CreatorID {
+		return true, nil
+	}
+	if h.Tickets == nil {
+		return false, nil
+	}
+	return h.Tickets.HasPaidTicket(r.Context(), scene.ID, userID)
+}
+
+// checkTicket writes 402 Payment Required and returns false if userID needs
+// a ticket to enter scene and has not paid for one.
+func (h *SceneHandler) checkTicket(w http.ResponseWriter, r *http.Request, scene *models.Scene, userID string) bool {
+	ok, err := h.hasTicket(r, scene, userID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking ticket of user %s for scene %s: %v", userID, scene.ID, err)
+		return false
+	}
+	if !ok {
+		http.Error(w, "A ticket is required to join this scene", http.StatusPaymentRequired)
+		log.Printf("User %s attempted to join ticketed scene %s without a ticket", userID, scene.ID)
+	}
+	return ok
+}
+
+// requestJoin files userID's request to join a scene that requires approval
+// and notifies the creator. It responds 202 Accepted while the request is pending.
+func (h *SceneHandler) requestJoin(w http.ResponseWriter, r *http.Request, scene *models.Scene, userID string) {
+	request, err := h.Store.RequestJoin(r.Context(), scene.ID, userID)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "User already joined or is awaiting approval", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "User is banned from this scene or not a member of its workspace", http.StatusForbidden)
+		return
+	}
+	if !checkScene(w, err, scene.ID) {
+		return
+	}
+	h.notifyJoinRequest(r, scene, request)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Join request sent to the scene creator",
+		"request": request,
+	})
+	log.Printf("User %s requested to join scene %s", userID, scene.ID)
+}
+
+// notifyJoinRequest tells the scene's creator about a new join request.
+func (h *SceneHandler) notifyJoinRequest(r *http.Request, scene *models.Scene, request *models.SceneJoinRequest) {
+	h.Notify.Notify(r.Context(), notify.Notification{
+		Channel: models.ChannelSceneInvites,
+		SceneID: scene.ID,
+		Type:    ws.TypeJoinRequested,
+		Payload: joinRequestNotice{request, h.Links.Scene(scene.ID)},
+	}, scene.CreatorID)
+}
+
+// ListJoinRequests handles the HTTP GET request to list a scene's pending join requests
+// with each requesting user's reputation. It expects the query parameters "scene_id"
+// and "user_id"; only the host and co-hosts may list them.
+func (h *SceneHandler) ListJoinRequests(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	userID := r.URL.Query().Get("user_id")
+	if sceneID == "" || userID == "" {
+		http.Error(w, "Scene ID and User ID are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for ListJoinRequests")
+		return
+	}
+
+	if _, ok := h.moderatorRole(w, r, sceneID, userID); !ok {
+		return
+	}
+
+	requests, err := h.Store.GetJoinRequests(r.Context(), sceneID)
+	if err != nil {
+		http.Error(w, "Failed to list join requests", http.StatusInternalServerError)
+		log.Printf("Error listing join requests for scene %s: %v", sceneID, err)
+		return
+	}
+	if requests == nil {
+		requests = []models.SceneJoinRequest{}
+	}
+
+	// Show hosts who is asking before they decide
+	userIDs := make([]string, len(requests))
+	for i, request := range requests {
+		userIDs[i] = request.UserID
+	}
+	reputations, err := h.Reputation.GetReputations(r.Context(), userIDs)
+	if err != nil {
+		http.Error(w, "Failed to list join requests", http.StatusInternalServerError)
+		log.Printf("Error getting reputations for join requests of scene %s: %v", sceneID, err)
+		return
+	}
+	for i := range requests {
+		rep := reputations[requests[i].UserID]
+		requests[i].Reputation = &rep
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(requests)
+}
+
+// ApproveJoinRequest handles the HTTP POST request to let a pending user into a scene.
+func (h *SceneHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.resolveJoinRequest(w, r, true)
+}
+
+// RejectJoinRequest handles the HTTP POST request to turn down a pending join request.
+func (h *SceneHandler) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.resolveJoinRequest(w, r, false)
+}
+
+// resolveJoinRequest approves or rejects a join request on behalf of the
+// host or a co-host and tells the requesting user over their open sockets.
+func (h *SceneHandler) resolveJoinRequest(w http.ResponseWriter, r *http.Request, approve bool) {
+	var req moderationRequest
+	if !h.decodeModeration(w, r, &req) {
+		return
+	}
+
+	err := h.Store.ResolveJoinRequest(r.Context(), req.SceneID, req.UserID, approve)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Join request not found", http.StatusNotFound)
+		log.Printf("No join request from user %s for scene %s", req.UserID, req.SceneID)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to resolve join request", http.StatusInternalServerError)
+		log.Printf("Error resolving join request of user %s for scene %s: %v", req.UserID, req.SceneID, err)
+		return
+	}
+	if approve {
+		h.Webhooks.Emit(models.EventUserJoined, req.SceneID, webhooks.UserJoined{SceneID: req.SceneID, UserID: req.UserID})
+	}
+	decision := models.JoinDecision{SceneID: req.SceneID, UserID: req.UserID, Approved: approve}
+	notice := joinDecisionNotice{JoinDecision: decision}
+	if approve {
+		notice.Link = h.Links.Scene(req.SceneID)
+	}
+	h.Notify.Notify(r.Context(), notify.Notification{
+		Channel: models.ChannelSceneInvites,
+		SceneID: req.SceneID,
+		Type:    ws.TypeJoinResolved,
+		Payload: notice,
+	}, req.UserID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(decision)
+	log.Printf("Join request of user %s for scene %s resolved by %s (approved=%t)", req.UserID, req.SceneID, req.ModeratorID, approve)
 }
 
-// GenerateShareLink confirms a scene exists and returns its ID for link generation.
-// This is a GET request, taking scene_id as a query parameter.
+// GenerateShareLink confirms a scene exists and returns its ID, the share page
+// URL that unfurls in link previews, and the frontend scene URL.
+// This is a GET request, taking scene_id and the caller's user_id, required
+// for workspace scenes, as query parameters.
 func (h *SceneHandler) GenerateShareLink(w http.ResponseWriter, r *http.Request) {
 	sceneID := r.URL.Query().Get("scene_id")
 
@@ -228,19 +683,14 @@ func (h *SceneHandler) GenerateShareLink(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	scene := h.Store.GetScene(sceneID)
-	if scene == nil {
-		http.Error(w, "Scene not found", http.StatusNotFound)
-		log.Printf("Scene not found for ID: %s", sceneID)
+	scene, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id"))
+	if !ok {
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"sceneID": scene.ID,
-		"message": "Scene exists and can be shared.",
-	})
+	json.NewEncoder(w).Encode(h.newSceneShareLinks(r, scene))
 	log.Printf("Share link requested for scene ID: %s", sceneID)
 }
 
@@ -258,91 +708,1612 @@ func (h *SceneHandler) JoinSceneByLink(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the scene exists
-	scene := h.Store.GetScene(sceneID)
-	if scene == nil {
-		http.Error(w, "Scene not found", http.StatusNotFound)
-		log.Printf("Attempted to join non-existent scene via link: %s", sceneID)
+	scene, ok := h.getVisibleScene(w, r, sceneID, userID)
+	if !ok {
+		return
+	}
+
+	// Send users without a ticket to the scene page, which offers one
+	if ok, err := h.hasTicket(r, scene, userID); err != nil || !ok {
+		if err != nil {
+			log.Printf("Error checking ticket of user %s for scene %s: %v", userID, sceneID, err)
+		}
+		http.Redirect(w, r, h.Links.Scene(sceneID), http.StatusFound)
+		return
+	}
+
+	if scene.RequiresApproval && userID != scene.CreatorID {
+		request, err := h.Store.RequestJoin(r.Context(), scene.ID, userID)
+		switch {
+		case err == nil:
+			log.Printf("User %s requested to join scene %s via link.", userID, sceneID)
+			h.notifyJoinRequest(r, scene, request)
+		case errors.Is(err, storage.ErrConflict):
+			log.Printf("User %s was already in or awaiting approval for scene %s.", userID, sceneID)
+		case errors.Is(err, storage.ErrForbidden):
+			http.Error(w, "User is banned from this scene or not a member of its workspace", http.StatusForbidden)
+			log.Printf("Banned or non-member user %s attempted to join scene %s via link.", userID, sceneID)
+			return
+		default:
+			log.Printf("User %s failed to request joining scene %s via link: %v", userID, sceneID, err)
+		}
+		http.Redirect(w, r, h.Links.Scene(sceneID), http.StatusFound)
 		return
 	}
 
 	// Attempt to add the user to the scene's joined listeners
-	joined := h.Store.JoinScene(sceneID, userID)
+	err := h.Store.JoinScene(r.Context(), scene.ID, userID)
 
-	if joined {
+	switch {
+	case err == nil:
 		log.Printf("User %s successfully joined scene %s via link.", userID, sceneID)
-	} else {
-		log.Printf("User %s was already in scene %s or failed to join via link.", userID, sceneID)
+		h.Webhooks.Emit(models.EventUserJoined, scene.ID, webhooks.UserJoined{SceneID: scene.ID, UserID: userID})
+	case errors.Is(err, storage.ErrConflict):
+		log.Printf("User %s was already in scene %s.", userID, sceneID)
+	case errors.Is(err, storage.ErrForbidden):
+		http.Error(w, "User is banned from this scene or not a member of its workspace", http.StatusForbidden)
+		log.Printf("Banned or non-member user %s attempted to join scene %s via link.", userID, sceneID)
+		return
+	default:
+		log.Printf("User %s failed to join scene %s via link: %v", userID, sceneID, err)
 	}
 
-	// ** IMPORTANT: Redirect to your frontend scene view **
-	// You need to replace "http://127.0.0.1:5173/scene-view" with the actual URL
-	// of your frontend page that displays the scene, passing the sceneID.
-	frontendSceneURL := fmt.Sprintf("http://127.0.0.1:5173/scene-view?scene_id=%s", sceneID)
-	http.Redirect(w, r, frontendSceneURL, http.StatusFound) // 302 Found for temporary redirect
+	// Redirect to the frontend scene view
+	http.Redirect(w, r, h.Links.Scene(sceneID), http.StatusFound) // 302 Found for temporary redirect
 }
 
-// WebSocket handler for scenes
-var sceneUpgrader = websocket.Upgrader{} // Use a separate upgrader for scenes if needed, or reuse DM one.
+// SendSceneMessage handles the HTTP POST request to post a chat message in a scene.
+// It expects a JSON payload with "sceneID", "senderID", and "content".
+// The stored message is broadcast to every WebSocket client connected to the scene.
+func (h *SceneHandler) SendSceneMessage(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID       string   `json:"sceneID"`
+		SenderID      string   `json:"senderID"`
+		Content       string   `json:"content"`
+		AttachmentIDs []string `json:"attachmentIDs"`
+	}
 
-func (h *SceneHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
-	sceneID := r.URL.Query().Get("scene_id")
-	userID := r.URL.Query().Get("user_id") // Assume user ID is passed for tracking active users
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SendSceneMessage: %v", err)
+		return
+	}
 
-	if sceneID == "" || userID == "" {
-		http.Error(w, "Scene ID and User ID are required for WebSocket connection", http.StatusBadRequest)
-		log.Println("Validation error: Scene ID or User ID missing for Scene WS")
+	// A message may consist solely of attachments
+	if req.SceneID == "" || req.SenderID == "" || (req.Content == "" && len(req.AttachmentIDs) == 0) {
+		http.Error(w, "Scene ID, Sender ID, and Content cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, Sender ID, or Content is empty for SendSceneMessage")
 		return
 	}
 
-	conn, err := sceneUpgrader.Upgrade(w, r, nil)
+	if len(req.AttachmentIDs) > 0 {
+		if h.Attachments == nil {
+			http.Error(w, "Attachments are not enabled", http.StatusBadRequest)
+			return
+		}
+		n, err := h.Attachments.CountUnlinked(r.Context(), req.SenderID, req.AttachmentIDs)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("Error checking attachments for %s: %v", req.SenderID, err)
+			return
+		}
+		if n != len(req.AttachmentIDs) {
+			http.Error(w, "Attachments must be uploaded by the sender and not already sent", http.StatusConflict)
+			return
+		}
+	}
+
+	_, err = h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+
+	muted, err := h.Store.HasRestriction(r.Context(), req.SceneID, req.SenderID, models.RestrictionMute)
 	if err != nil {
-		log.Printf("Failed to upgrade WebSocket for scene %s: %v", sceneID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking mute for %s in scene %s: %v", req.SenderID, req.SceneID, err)
+		return
+	}
+	if muted {
+		http.Error(w, "You are muted in this scene", http.StatusForbidden)
 		return
 	}
-	log.Printf("WebSocket connection upgraded for SceneID: %s, UserID: %s", sceneID, userID)
 
-	client := &ws.Client{
-		UserID:  userID,
-		SceneID: sceneID, // Set the SceneID for this client
-		Send:    make(chan []byte, 256),
-		Conn:    conn,
-	}
-	h.Hub.Register <- client
-
-	// Read pump: reads messages from the WebSocket connection
-	go func() {
-		defer func() {
-			h.Hub.Unregister <- client
-			conn.Close()
-			log.Printf("Read pump closed for client %s in scene %s", userID, sceneID)
-		}()
-		for {
-			// In a real application, you might expect messages from the client (e.g., chat messages within the scene)
-			// For now, we just read to keep the connection alive and detect disconnections.
-			_, _, err := conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("WebSocket read error for client %s in scene %s: %v", userID, sceneID, err)
-				}
-				break
-			}
-			// If you want to broadcast messages received from clients in a scene:
-			// h.Hub.Broadcast <- ws.BroadcastMessage{SceneID: sceneID, Data: message}
+	decision := moderation.Decision{Content: req.Content}
+	if h.Moderator != nil && req.Content != "" {
+		decision, err = h.Moderator.Review(r.Context(), req.Content)
+		if errors.Is(err, moderation.ErrRejected) {
+			http.Error(w, "Message was rejected by the content filter", http.StatusUnprocessableEntity)
+			log.Printf("Rejected message from %s in scene %s: %v", req.SenderID, req.SceneID, decision.Reasons)
+			return
+		}
+	}
+
+	msg, err := h.Store.AddSceneMessage(r.Context(), req.SceneID, req.SenderID, decision.Content)
+	if err != nil {
+		http.Error(w, "Failed to send message", http.StatusInternalServerError)
+		log.Printf("Error sending message to scene %s: %v", req.SceneID, err)
+		return
+	}
+
+	if len(req.AttachmentIDs) > 0 {
+		msg.Attachments, err = h.Attachments.LinkToSceneMessage(r.Context(), msg.ID, req.SenderID, req.AttachmentIDs)
+		if err != nil {
+			// The message is already stored; deliver it without attachments
+			log.Printf("Error linking attachments to scene message %s: %v", msg.ID, err)
 		}
-	}()
-
-	// Write pump: writes messages from the hub to the WebSocket connection
-	go func() {
-		defer func() {
-			conn.Close()
-			log.Printf("Write pump closed for client %s in scene %s", userID, sceneID)
-		}()
-		for message := range client.Send {
-			err := conn.WriteMessage(websocket.TextMessage, message)
-			if err != nil {
-				log.Printf("WebSocket write error for client %s in scene %s: %v", userID, sceneID, err)
-				return // Break from loop if write fails
-			}
+	}
+
+	if spans := mentions.Parse(msg.Content); len(spans) > 0 {
+		msg.Mentions, err = h.Store.SetSceneMessageMentions(r.Context(), msg.ID, spans)
+		if err != nil {
+			// The message is already stored; deliver it without mentions
+			log.Printf("Error storing mentions of scene message %s: %v", msg.ID, err)
 		}
-	}()
+	}
+
+	if decision.Flagged {
+		h.Moderator.Flag(r.Context(), models.MessageTypeScene, msg.ID, req.SenderID, decision.Reasons)
+	}
+
+	h.recordMessageHashtags(r, msg)
+
+	// Broadcast the new message to everyone connected to the scene
+	h.Hub.SendToScene(req.SceneID, ws.TypeChat, msg)
+	h.notifyMentions(r, msg)
+	h.Webhooks.Emit(models.EventMessageSent, req.SceneID, webhooks.MessageSent{Type: models.MessageTypeScene, SceneID: req.SceneID, Message: msg})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(msg)
+}
+
+// notifyMentions sends a mention notice to each user msg mentions, other
+// than its sender.
+func (h *SceneHandler) notifyMentions(r *http.Request, msg *models.SceneMessage) {
+	notice := models.MentionNotice{
+		MessageType: models.MessageTypeScene,
+		MessageID:   msg.ID,
+		SceneID:     msg.SceneID,
+		SenderID:    msg.SenderID,
+		Content:     msg.Content,
+		Link:        h.Links.Scene(msg.SceneID),
+	}
+	h.Notify.Notify(r.Context(), notify.Notification{
+		Channel: models.ChannelMentions,
+		SceneID: msg.SceneID,
+		Type:    ws.TypeMention,
+		Payload: notice,
+	}, mentions.Recipients(msg.Mentions, msg.SenderID)...)
+}
+
+// SearchSceneMessages handles the HTTP GET request to search a scene's chat.
+// It expects "scene_id" and "q" query parameters and accepts an optional
+// "limit" and the caller's "user_id", required for workspace scenes.
+// Matches are returned newest first with a few messages of context around each.
+func (h *SceneHandler) SearchSceneMessages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sceneID := q.Get("scene_id")
+	search := storage.MessageSearch{Query: strings.TrimSpace(q.Get("q"))}
+
+	if sceneID == "" || search.Query == "" {
+		http.Error(w, "Scene ID and q are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or q is empty for SearchSceneMessages")
+		return
+	}
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		search.Limit = n
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, q.Get("user_id")); !ok {
+		return
+	}
+
+	results, err := h.Store.SearchSceneMessages(r.Context(), sceneID, search)
+	if err != nil {
+		http.Error(w, "Failed to search messages", http.StatusInternalServerError)
+		log.Printf("Error searching messages in scene %s: %v", sceneID, err)
+		return
+	}
+	if results == nil {
+		results = []models.SceneSearchResult{} // Return an empty slice instead of nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
+
+// GetSceneMessages handles the HTTP GET request to list a scene's chat history.
+// It expects the scene ID as a query parameter "scene_id" and accepts the
+// caller's "user_id", required for workspace scenes.
+func (h *SceneHandler) GetSceneMessages(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for GetSceneMessages")
+		return
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	msgs, err := h.Store.GetSceneMessages(r.Context(), sceneID)
+	if err != nil {
+		http.Error(w, "Failed to list messages", http.StatusInternalServerError)
+		log.Printf("Error listing messages for scene %s: %v", sceneID, err)
+		return
+	}
+	if msgs == nil {
+		msgs = []models.SceneMessage{} // Return an empty slice instead of nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(msgs)
+
+	log.Printf("Listed %d messages for scene ID: %s", len(msgs), sceneID)
+}
+
+// sceneReactionEvent is broadcast to the scene when a reaction changes.
+type sceneReactionEvent struct {
+	SceneID   string                 `json:"sceneID"`
+	MessageID string                 `json:"messageID"`
+	UserID    string                 `json:"userID"`
+	Emoji     string                 `json:"emoji"`
+	Added     bool                   `json:"added"`
+	Reactions []models.ReactionCount `json:"reactions"`
+}
+
+// AddReaction handles the HTTP POST request to react to a scene chat message.
+// It expects a JSON payload with "messageID", "userID", and "emoji".
+func (h *SceneHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, true)
+}
+
+// RemoveReaction handles the HTTP POST request to withdraw a reaction from a scene chat message.
+// It expects a JSON payload with "messageID", "userID", and "emoji".
+func (h *SceneHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	h.changeReaction(w, r, false)
+}
+
+// changeReaction adds or removes a reaction and broadcasts the new counts to the scene.
+func (h *SceneHandler) changeReaction(w http.ResponseWriter, r *http.Request, add bool) {
+	var req struct {
+		MessageID string `json:"messageID"`
+		UserID    string `json:"userID"`
+		Emoji     string `json:"emoji"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for scene reaction: %v", err)
+		return
+	}
+
+	if req.MessageID == "" || req.UserID == "" || req.Emoji == "" {
+		http.Error(w, "Message ID, User ID, and Emoji cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Message ID, User ID, or Emoji is empty for scene reaction")
+		return
+	}
+	if len(req.Emoji) > models.MaxReactionLength {
+		http.Error(w, "Emoji is too long", http.StatusBadRequest)
+		log.Printf("Validation error: Emoji of %d bytes for scene reaction", len(req.Emoji))
+		return
+	}
+
+	var msg *models.SceneMessage
+	if add {
+		msg, err = h.Store.AddSceneReaction(r.Context(), req.MessageID, req.UserID, req.Emoji)
+	} else {
+		msg, err = h.Store.RemoveSceneReaction(r.Context(), req.MessageID, req.UserID, req.Emoji)
+	}
+	switch {
+	case errors.Is(err, storage.ErrConflict):
+		http.Error(w, "User already reacted with this emoji", http.StatusConflict)
+		return
+	case errors.Is(err, storage.ErrNotFound) && add:
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Reaction not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error updating reaction on scene message %s: %v", req.MessageID, err)
+		return
+	}
+
+	reactions := msg.Reactions
+	if reactions == nil {
+		reactions = []models.ReactionCount{}
+	}
+	h.Hub.SendToScene(msg.SceneID, ws.TypeReaction, sceneReactionEvent{
+		SceneID:   msg.SceneID,
+		MessageID: msg.ID,
+		UserID:    req.UserID,
+		Emoji:     req.Emoji,
+		Added:     add,
+		Reactions: reactions,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(msg)
+}
+
+// pinEvent is broadcast to the scene when its pinned messages change.
+type pinEvent struct {
+	SceneID   string                 `json:"sceneID"`
+	MessageID string                 `json:"messageID"`
+	Pinned    bool                   `json:"pinned"`
+	Pins      []models.PinnedMessage `json:"pins"`
+}
+
+// ListPins handles the HTTP GET request to list a scene's pinned messages.
+// It expects a "scene_id" query parameter and accepts the caller's "user_id",
+// required for workspace scenes.
+func (h *SceneHandler) ListPins(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for ListPins")
+		return
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	pins, err := h.Store.GetPinnedMessages(r.Context(), sceneID)
+	if !checkScene(w, err, sceneID) {
+		return
+	}
+	if pins == nil {
+		pins = []models.PinnedMessage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pins)
+}
+
+// PinMessage handles the HTTP POST request to pin a scene chat message.
+// It expects a JSON payload with "sceneID", "userID", and "messageID"; only the host and co-hosts may pin.
+func (h *SceneHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
+	h.changePin(w, r, true)
+}
+
+// UnpinMessage handles the HTTP POST request to unpin a scene chat message.
+// It expects a JSON payload with "sceneID", "userID", and "messageID"; only the host and co-hosts may unpin.
+func (h *SceneHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+	h.changePin(w, r, false)
+}
+
+// changePin pins or unpins a message and broadcasts the scene's new pin list.
+func (h *SceneHandler) changePin(w http.ResponseWriter, r *http.Request, pin bool) {
+	var req struct {
+		SceneID   string `json:"sceneID"`
+		UserID    string `json:"userID"`
+		MessageID string `json:"messageID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for pin: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" || req.MessageID == "" {
+		http.Error(w, "Scene ID, User ID, and Message ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, User ID, or Message ID is empty for pin")
+		return
+	}
+
+	role, err := h.Store.GetSceneRole(r.Context(), req.SceneID, req.UserID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if !role.CanModerate() {
+		http.Error(w, "Only the scene host and co-hosts can pin messages", http.StatusForbidden)
+		log.Printf("User %s attempted to change pins in scene %s", req.UserID, req.SceneID)
+		return
+	}
+
+	if pin {
+		err = h.Store.PinMessage(r.Context(), req.SceneID, req.MessageID, req.UserID)
+	} else {
+		err = h.Store.UnpinMessage(r.Context(), req.SceneID, req.MessageID)
+	}
+	switch {
+	case errors.Is(err, storage.ErrNotFound) && pin:
+		http.Error(w, "Message not found in this scene", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Message is not pinned", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrConflict):
+		http.Error(w, "Message is already pinned", http.StatusConflict)
+		return
+	case errors.Is(err, storage.ErrLimitReached):
+		http.Error(w, fmt.Sprintf("A scene can have at most %d pinned messages", models.MaxPinnedMessages), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error changing pin of message %s in scene %s: %v", req.MessageID, req.SceneID, err)
+		return
+	}
+
+	pins, err := h.Store.GetPinnedMessages(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if pins == nil {
+		pins = []models.PinnedMessage{}
+	}
+	h.Hub.SendToScene(req.SceneID, ws.TypeMessagePinned, pinEvent{
+		SceneID:   req.SceneID,
+		MessageID: req.MessageID,
+		Pinned:    pin,
+		Pins:      pins,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pins)
+	log.Printf("Message %s pinned=%t in scene %s by %s", req.MessageID, pin, req.SceneID, req.UserID)
+}
+
+// broadcastQueue sends the scene's current queue to its WebSocket clients.
+func (h *SceneHandler) broadcastQueue(r *http.Request, sceneID string) []models.QueueItem {
+	queue, err := h.Store.GetQueue(r.Context(), sceneID)
+	if err != nil {
+		log.Printf("Error loading queue for scene %s after change: %v", sceneID, err)
+		return nil
+	}
+	if queue == nil {
+		queue = []models.QueueItem{}
+	}
+
+	h.Hub.SendToScene(sceneID, ws.TypeQueue, queue)
+	return queue
+}
+
+// AddToQueue handles the HTTP POST request to queue a track in a scene.
+// It expects a JSON payload with "sceneID", "userID", "title", "artist",
+// "artworkURL", and "providerID".
+func (h *SceneHandler) AddToQueue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID    string `json:"sceneID"`
+		UserID     string `json:"userID"`
+		Title      string `json:"title"`
+		Artist     string `json:"artist"`
+		ArtworkURL string `json:"artworkURL"`
+		ProviderID string `json:"providerID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for AddToQueue: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" || req.Title == "" {
+		http.Error(w, "Scene ID, User ID, and Title cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, User ID, or Title is empty for AddToQueue")
+		return
+	}
+
+	_, err = h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+
+	item, err := h.Store.AddToQueue(r.Context(), &models.QueueItem{
+		SceneID:    req.SceneID,
+		Title:      req.Title,
+		Artist:     req.Artist,
+		ArtworkURL: req.ArtworkURL,
+		ProviderID: req.ProviderID,
+		AddedBy:    req.UserID,
+	})
+	if err != nil {
+		http.Error(w, "Failed to add track to queue", http.StatusInternalServerError)
+		log.Printf("Error adding track to queue for scene %s: %v", req.SceneID, err)
+		return
+	}
+
+	h.broadcastQueue(r, req.SceneID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+// RemoveFromQueue handles the HTTP POST request to drop a track from a scene's queue.
+// It expects a JSON payload with "sceneID" and "itemID".
+func (h *SceneHandler) RemoveFromQueue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string `json:"sceneID"`
+		ItemID  string `json:"itemID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for RemoveFromQueue: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.ItemID == "" {
+		http.Error(w, "Scene ID and Item ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or Item ID is empty for RemoveFromQueue")
+		return
+	}
+
+	err = h.Store.RemoveFromQueue(r.Context(), req.SceneID, req.ItemID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Queue item not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to remove track from queue", http.StatusInternalServerError)
+		log.Printf("Error removing item %s from queue for scene %s: %v", req.ItemID, req.SceneID, err)
+		return
+	}
+
+	queue := h.broadcastQueue(r, req.SceneID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(queue)
+}
+
+// ReorderQueue handles the HTTP POST request to change the order of a scene's queue.
+// It expects a JSON payload with "sceneID" and "itemIDs", the complete queue in the new order.
+func (h *SceneHandler) ReorderQueue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string   `json:"sceneID"`
+		ItemIDs []string `json:"itemIDs"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for ReorderQueue: %v", err)
+		return
+	}
+
+	if req.SceneID == "" {
+		http.Error(w, "Scene ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for ReorderQueue")
+		return
+	}
+
+	err = h.Store.ReorderQueue(r.Context(), req.SceneID, req.ItemIDs)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Item IDs must list every queued track exactly once", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to reorder queue", http.StatusInternalServerError)
+		log.Printf("Error reordering queue for scene %s: %v", req.SceneID, err)
+		return
+	}
+
+	queue := h.broadcastQueue(r, req.SceneID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(queue)
+}
+
+// VoteQueueItem handles the HTTP POST request to vote a queued track up or down.
+// It expects a JSON payload with "sceneID", "userID", "itemID", and "vote":
+// 1 for up, -1 for down, or 0 to withdraw. Only scene participants may vote;
+// the queue with its updated counts is broadcast to the scene.
+func (h *SceneHandler) VoteQueueItem(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string `json:"sceneID"`
+		UserID  string `json:"userID"`
+		ItemID  string `json:"itemID"`
+		Vote    int    `json:"vote"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for VoteQueueItem: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" || req.ItemID == "" {
+		http.Error(w, "Scene ID, User ID, and Item ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, User ID, or Item ID is empty for VoteQueueItem")
+		return
+	}
+	if req.Vote < -1 || req.Vote > 1 {
+		http.Error(w, "Vote must be 1, -1, or 0", http.StatusBadRequest)
+		log.Printf("Validation error: Invalid vote %d for VoteQueueItem", req.Vote)
+		return
+	}
+
+	participants, err := h.Store.GetSceneParticipants(r.Context(), []string{req.SceneID})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error loading participants of scene %s: %v", req.SceneID, err)
+		return
+	}
+	if !slices.Contains(participants[req.SceneID], req.UserID) {
+		http.Error(w, "Only scene participants can vote on tracks", http.StatusForbidden)
+		log.Printf("User %s attempted to vote on queue item %s without joining scene %s", req.UserID, req.ItemID, req.SceneID)
+		return
+	}
+
+	err = h.Store.VoteQueueItem(r.Context(), req.SceneID, req.ItemID, req.UserID, req.Vote)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Queue item not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to record vote", http.StatusInternalServerError)
+		log.Printf("Error recording vote of %s on queue item %s: %v", req.UserID, req.ItemID, err)
+		return
+	}
+
+	queue := h.broadcastQueue(r, req.SceneID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(queue)
+}
+
+// ListQueue handles the HTTP GET request to list a scene's queued tracks in play order.
+// It expects the scene ID as a query parameter "scene_id" and accepts the
+// caller's "user_id", required for workspace scenes.
+func (h *SceneHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for ListQueue")
+		return
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	queue, err := h.Store.GetQueue(r.Context(), sceneID)
+	if err != nil {
+		http.Error(w, "Failed to list queue", http.StatusInternalServerError)
+		log.Printf("Error listing queue for scene %s: %v", sceneID, err)
+		return
+	}
+	if queue == nil {
+		queue = []models.QueueItem{} // Return an empty slice instead of nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(queue)
+}
+
+// UpdateScene handles the HTTP PATCH request to edit a scene's details.
+// It expects a JSON payload with "userID", "sceneID" unless the scene ID is
+// in the path, and any of "name", "artistName",
+// "description", "coverImageURL", "tags", "requiresApproval", "skipThreshold",
+// "maxDurationSeconds" (0 removes the limit), "ticketPriceCents" (0 makes the
+// scene free), and "ticketCurrency"; omitted fields are left unchanged. Only
+// the creator may edit.
+func (h *SceneHandler) UpdateScene(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID       string  `json:"sceneID"`
+		UserID        string  `json:"userID"`
+		Name          *string `json:"name"`
+		ArtistName    *string `json:"artistName"`
+		Description   *string `json:"description"`
+		CoverImageURL *string   `json:"coverImageURL"`
+		Tags          *[]string `json:"tags"`
+		RequiresApproval *bool  `json:"requiresApproval"`
+		SkipThreshold *float64  `json:"skipThreshold"`
+		MaxDurationSeconds *int `json:"maxDurationSeconds"`
+		TicketPriceCents *int   `json:"ticketPriceCents"`
+		TicketCurrency *string  `json:"ticketCurrency"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for UpdateScene: %v", err)
+		return
+	}
+	req.SceneID = sceneIDParam(r, req.SceneID)
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for UpdateScene")
+		return
+	}
+
+	update := storage.SceneUpdate{
+		Name:          trimmed(req.Name),
+		ArtistName:    trimmed(req.ArtistName),
+		Description:   trimmed(req.Description),
+		CoverImageURL: trimmed(req.CoverImageURL),
+		RequiresApproval: req.RequiresApproval,
+		SkipThreshold:    req.SkipThreshold,
+		MaxDurationSeconds: req.MaxDurationSeconds,
+		TicketPriceCents: req.TicketPriceCents,
+	}
+	if req.TicketCurrency != nil {
+		currency := strings.ToLower(strings.TrimSpace(*req.TicketCurrency))
+		update.TicketCurrency = &currency
+	}
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			log.Printf("Validation error: %v for UpdateScene", err)
+			return
+		}
+		update.Tags = &tags
+	}
+	if update.Name == nil && update.ArtistName == nil && update.Description == nil && update.CoverImageURL == nil && update.Tags == nil && update.RequiresApproval == nil && update.SkipThreshold == nil && update.MaxDurationSeconds == nil &&
+		update.TicketPriceCents == nil && update.TicketCurrency == nil {
+		http.Error(w, "No fields to update", http.StatusBadRequest)
+		log.Println("Validation error: No fields to update for UpdateScene")
+		return
+	}
+	if (update.Name != nil && *update.Name == "") || (update.ArtistName != nil && *update.ArtistName == "") {
+		http.Error(w, "Scene Name and Artist Name cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene Name or Artist Name is empty for UpdateScene")
+		return
+	}
+	if update.CoverImageURL != nil && *update.CoverImageURL != "" && !isHTTPURL(*update.CoverImageURL) {
+		http.Error(w, "Cover image URL must be an http or https URL", http.StatusBadRequest)
+		log.Printf("Validation error: Invalid cover image URL for UpdateScene: %q", *update.CoverImageURL)
+		return
+	}
+	if update.SkipThreshold != nil && (*update.SkipThreshold < 0 || *update.SkipThreshold > 1) {
+		http.Error(w, "Skip threshold must be between 0 and 1", http.StatusBadRequest)
+		log.Printf("Validation error: Invalid skip threshold %v for UpdateScene", *update.SkipThreshold)
+		return
+	}
+	if d := update.MaxDurationSeconds; d != nil && *d != 0 && (*d < minSceneDuration || *d > maxSceneDuration) {
+		http.Error(w, fmt.Sprintf("Max duration must be 0 or between %d and %d seconds", minSceneDuration, maxSceneDuration), http.StatusBadRequest)
+		log.Printf("Validation error: Invalid max duration %d for UpdateScene", *d)
+		return
+	}
+	if p := update.TicketPriceCents; p != nil && *p != 0 && (*p < minTicketPrice || *p > maxTicketPrice) {
+		http.Error(w, fmt.Sprintf("Ticket price must be 0 or between %d and %d", minTicketPrice, maxTicketPrice), http.StatusBadRequest)
+		log.Printf("Validation error: Invalid ticket price %d for UpdateScene", *p)
+		return
+	}
+	if c := update.TicketCurrency; c != nil && !isCurrencyCode(*c) {
+		http.Error(w, "Ticket currency must be a three-letter ISO 4217 code", http.StatusBadRequest)
+		log.Printf("Validation error: Invalid ticket currency %q for UpdateScene", *c)
+		return
+	}
+	if p := update.TicketPriceCents; p != nil && *p != 0 && h.Tickets == nil {
+		http.Error(w, "Ticketed scenes are not enabled", http.StatusBadRequest)
+		return
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if scene.CreatorID != req.UserID {
+		http.Error(w, "Only the scene creator can edit the scene", http.StatusForbidden)
+		log.Printf("User %s attempted to edit scene %s", req.UserID, req.SceneID)
+		return
+	}
+	if d := update.MaxDurationSeconds; d != nil && *d != 0 && !sceneStart(scene).Add(time.Duration(*d)*time.Second).After(time.Now()) {
+		http.Error(w, "Max duration must end after the current time", http.StatusBadRequest)
+		log.Printf("Validation error: Max duration %d of scene %s has already passed", *d, req.SceneID)
+		return
+	}
+
+	oldCoverKey := scene.CoverKey
+	scene, err = h.Store.UpdateScene(r.Context(), req.SceneID, update)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	// A linked cover URL replaces any uploaded cover
+	if update.CoverImageURL != nil && oldCoverKey != "" {
+		h.deleteCover(r, oldCoverKey)
+	}
+	if update.Name != nil || update.Description != nil || update.Tags != nil {
+		h.recordSceneHashtags(r, scene)
+	}
+	scene.ActiveUsers = h.Hub.GetActiveSceneUsersCount(scene.ID)
+
+	// Let open clients refresh the scene header
+	h.Hub.SendToScene(scene.ID, ws.TypeSceneUpdated, scene)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scene)
+	log.Printf("Scene %s updated by creator %s", scene.ID, req.UserID)
+}
+
+// Limits on scene tags.
+const (
+	maxSceneTags   = 10
+	maxTagLength   = 32
+	maxSearchLimit = 50
+)
+
+// Limits on a scene's ticket price, in the currency's smallest unit. Stripe
+// rejects charges below about 50 cents.
+const (
+	minTicketPrice = 50
+	maxTicketPrice = 1_000_000
+)
+
+// isCurrencyCode reports whether code looks like a lowercase ISO 4217 code.
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Limits on a scene's maximum duration, in seconds.
+const (
+	minSceneDuration = 60
+	maxSceneDuration = 7 * 24 * 60 * 60
+)
+
+// sceneStart returns when a scene's maximum duration starts counting: its
+// scheduled time, or its creation if it was created live.
+func sceneStart(scene *models.Scene) time.Time {
+	if scene.ScheduledAt != nil {
+		return *scene.ScheduledAt
+	}
+	return scene.CreatedAt
+}
+
+// normalizeTags lowercases tags, strips a leading '#', and drops blanks and
+// duplicates. It returns an error if there are too many or one is too long.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len([]rune(tag)) > maxTagLength {
+			return nil, fmt.Errorf("Tags must be at most %d characters", maxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxSceneTags {
+		return nil, fmt.Errorf("A scene can have at most %d tags", maxSceneTags)
+	}
+	return normalized, nil
+}
+
+// trimmed returns s with surrounding whitespace removed, preserving nil.
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	return &t
+}
+
+// isHTTPURL reports whether raw is an absolute http or https URL.
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// DeleteScene handles the HTTP DELETE request to permanently remove a scene.
+// It expects the "user_id" query parameter and the scene ID in the path or,
+// on the legacy route, the "scene_id" query parameter; only the creator may delete.
+func (h *SceneHandler) DeleteScene(w http.ResponseWriter, r *http.Request) {
+	sceneID := sceneIDParam(r, r.URL.Query().Get("scene_id"))
+	userID := r.URL.Query().Get("user_id")
+
+	if sceneID == "" || userID == "" {
+		http.Error(w, "Scene ID and User ID are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID missing for DeleteScene")
+		return
+	}
+
+	coverKey, err := h.Store.DeleteScene(r.Context(), sceneID, userID)
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "Only the scene creator can delete the scene", http.StatusForbidden)
+		log.Printf("User %s attempted to delete scene %s", userID, sceneID)
+		return
+	}
+	if !checkScene(w, err, sceneID) {
+		return
+	}
+	if coverKey != "" {
+		h.deleteCover(r, coverKey)
+	}
+
+	// Tell open clients the scene is gone; their connections are closed once it is sent
+	h.Hub.CloseScene(sceneID, models.SceneClosed{SceneID: sceneID, Reason: models.SceneClosedDeleted})
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Scene %s deleted by creator %s", sceneID, userID)
+}
+
+// ArchiveScene handles the HTTP POST request to archive or restore a scene.
+// It expects a JSON payload with "sceneID", "userID", and "archived"; only the creator may archive.
+func (h *SceneHandler) ArchiveScene(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID  string `json:"sceneID"`
+		UserID   string `json:"userID"`
+		Archived bool   `json:"archived"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for ArchiveScene: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for ArchiveScene")
+		return
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if scene.CreatorID != req.UserID {
+		http.Error(w, "Only the scene creator can archive the scene", http.StatusForbidden)
+		log.Printf("User %s attempted to archive scene %s", req.UserID, req.SceneID)
+		return
+	}
+
+	err = h.Store.SetArchived(r.Context(), req.SceneID, req.Archived)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+
+	scene, err = h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	h.Hub.SendToScene(scene.ID, ws.TypeSceneArchived, scene)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scene)
+	log.Printf("Scene %s archived=%t by creator %s", req.SceneID, req.Archived, req.UserID)
+}
+
+// RescheduleScene handles the HTTP POST request to move a scheduled scene's start time.
+// It expects a JSON payload with "sceneID", "userID", and "scheduledAt"; only the creator may reschedule.
+func (h *SceneHandler) RescheduleScene(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID     string    `json:"sceneID"`
+		UserID      string    `json:"userID"`
+		ScheduledAt time.Time `json:"scheduledAt"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for RescheduleScene: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for RescheduleScene")
+		return
+	}
+	if !req.ScheduledAt.After(time.Now()) {
+		http.Error(w, "Scheduled time must be in the future", http.StatusBadRequest)
+		log.Printf("Validation error: Scheduled time %s is not in the future for RescheduleScene", req.ScheduledAt)
+		return
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if scene.CreatorID != req.UserID {
+		http.Error(w, "Only the scene creator can reschedule the scene", http.StatusForbidden)
+		log.Printf("User %s attempted to reschedule scene %s", req.UserID, req.SceneID)
+		return
+	}
+
+	scene, err = h.Store.Reschedule(r.Context(), req.SceneID, req.ScheduledAt)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Scene is already live", http.StatusConflict)
+		log.Printf("Reschedule rejected: scene %s is already live", req.SceneID)
+		return
+	}
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+
+	h.Hub.SendToScene(scene.ID, ws.TypeSceneUpdated, scene)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scene)
+	log.Printf("Scene %s rescheduled to %s by creator %s", scene.ID, req.ScheduledAt, req.UserID)
+}
+
+// AddRSVP handles the HTTP POST request to RSVP to a scheduled scene.
+// It expects a JSON payload with "sceneID" and "userID".
+func (h *SceneHandler) AddRSVP(w http.ResponseWriter, r *http.Request) {
+	h.changeRSVP(w, r, true)
+}
+
+// RemoveRSVP handles the HTTP POST request to withdraw an RSVP.
+// It expects a JSON payload with "sceneID" and "userID".
+func (h *SceneHandler) RemoveRSVP(w http.ResponseWriter, r *http.Request) {
+	h.changeRSVP(w, r, false)
+}
+
+func (h *SceneHandler) changeRSVP(w http.ResponseWriter, r *http.Request, add bool) {
+	var req struct {
+		SceneID string `json:"sceneID"`
+		UserID  string `json:"userID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding RSVP request body: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for RSVP")
+		return
+	}
+
+	if add {
+		err = h.Store.AddRSVP(r.Context(), req.SceneID, req.UserID)
+		switch {
+		case errors.Is(err, storage.ErrForbidden):
+			http.Error(w, "Scene is not scheduled", http.StatusConflict)
+			log.Printf("RSVP rejected: scene %s is not scheduled", req.SceneID)
+			return
+		case errors.Is(err, storage.ErrConflict):
+			http.Error(w, "Already RSVP'd to this scene", http.StatusConflict)
+			log.Printf("User %s already RSVP'd to scene %s", req.UserID, req.SceneID)
+			return
+		}
+		if !checkScene(w, err, req.SceneID) {
+			return
+		}
+	} else {
+		err = h.Store.RemoveRSVP(r.Context(), req.SceneID, req.UserID)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "RSVP not found", http.StatusNotFound)
+			log.Printf("No RSVP from user %s for scene %s", req.UserID, req.SceneID)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to remove RSVP", http.StatusInternalServerError)
+			log.Printf("Error removing RSVP of user %s from scene %s: %v", req.UserID, req.SceneID, err)
+			return
+		}
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scene)
+	log.Printf("User %s RSVP=%t for scene %s", req.UserID, add, req.SceneID)
+}
+
+// ListRSVPs handles the HTTP GET request to list who RSVP'd to a scene.
+// It expects the scene ID as the query parameter "scene_id" and accepts the
+// caller's "user_id", required for workspace scenes.
+func (h *SceneHandler) ListRSVPs(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter (e.g., ?scene_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for ListRSVPs")
+		return
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	userIDs, err := h.Store.GetRSVPs(r.Context(), sceneID)
+	if err != nil {
+		http.Error(w, "Failed to list RSVPs", http.StatusInternalServerError)
+		log.Printf("Error listing RSVPs for scene %s: %v", sceneID, err)
+		return
+	}
+	if userIDs == nil {
+		userIDs = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(userIDs)
+}
+
+// Analytics windows: the default span and the longest span allowed per bucket.
+const defaultAnalyticsWindow = 7 * 24 * time.Hour
+
+var maxAnalyticsWindow = map[models.AnalyticsBucket]time.Duration{
+	models.BucketHour: 31 * 24 * time.Hour,
+	models.BucketDay:  366 * 24 * time.Hour,
+}
+
+// GetSceneAnalytics handles the HTTP GET request for a scene's listener analytics.
+// It expects the query parameters "scene_id" and "user_id" and accepts optional
+// RFC 3339 "from" and "to" (default: the last 7 days) and "bucket" ("hour" or "day").
+// Only the scene creator may view analytics.
+func (h *SceneHandler) GetSceneAnalytics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sceneID := query.Get("scene_id")
+	userID := query.Get("user_id")
+	if sceneID == "" || userID == "" {
+		http.Error(w, "scene_id and user_id are required query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for GetSceneAnalytics")
+		return
+	}
+
+	bucket := models.BucketHour
+	if v := query.Get("bucket"); v != "" {
+		bucket = models.AnalyticsBucket(v)
+	}
+	maxWindow, ok := maxAnalyticsWindow[bucket]
+	if !ok {
+		http.Error(w, `bucket must be "hour" or "day"`, http.StatusBadRequest)
+		log.Printf("Validation error: Invalid bucket %q for GetSceneAnalytics", bucket)
+		return
+	}
+
+	to := time.Now()
+	if v := query.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "to must be an RFC 3339 timestamp", http.StatusBadRequest)
+			log.Printf("Validation error: Invalid to %q for GetSceneAnalytics", v)
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultAnalyticsWindow)
+	if v := query.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "from must be an RFC 3339 timestamp", http.StatusBadRequest)
+			log.Printf("Validation error: Invalid from %q for GetSceneAnalytics", v)
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		log.Printf("Validation error: from %s is not before to %s for GetSceneAnalytics", from, to)
+		return
+	}
+	if to.Sub(from) > maxWindow {
+		http.Error(w, fmt.Sprintf("Window is too long for %s buckets", bucket), http.StatusBadRequest)
+		log.Printf("Validation error: Window %s exceeds %s for %s buckets", to.Sub(from), maxWindow, bucket)
+		return
+	}
+
+	scene, ok := h.getVisibleScene(w, r, sceneID, userID)
+	if !ok {
+		return
+	}
+	if scene.CreatorID != userID {
+		http.Error(w, "Only the scene creator can view analytics", http.StatusForbidden)
+		log.Printf("User %s attempted to view analytics for scene %s", userID, sceneID)
+		return
+	}
+
+	analytics, err := h.Analytics.GetSceneAnalytics(r.Context(), sceneID, from, to, bucket)
+	if err != nil {
+		http.Error(w, "Failed to load scene analytics", http.StatusInternalServerError)
+		log.Printf("Error loading analytics for scene %s: %v", sceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(analytics)
+}
+
+// Day ranges accepted by GetSceneStats.
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+)
+
+// GetSceneStats handles the HTTP GET request for a scene's daily peak and
+// average concurrent listeners. It expects the query parameter "scene_id" and
+// accepts an optional "days" (default 30, at most 365) and the caller's
+// "user_id", required for workspace scenes.
+func (h *SceneHandler) GetSceneStats(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter (e.g., ?scene_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for GetSceneStats")
+		return
+	}
+
+	days := defaultStatsDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxStatsDays {
+			http.Error(w, fmt.Sprintf("days must be between 1 and %d", maxStatsDays), http.StatusBadRequest)
+			log.Printf("Validation error: Invalid days %q for GetSceneStats", v)
+			return
+		}
+		days = n
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	stats, err := h.Analytics.GetSceneStats(r.Context(), sceneID, days)
+	if err != nil {
+		http.Error(w, "Failed to load scene stats", http.StatusInternalServerError)
+		log.Printf("Error loading stats for scene %s: %v", sceneID, err)
+		return
+	}
+	if stats == nil {
+		stats = []models.SceneDayStats{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// moderationRequest is the JSON payload shared by the moderation endpoints.
+type moderationRequest struct {
+	SceneID     string `json:"sceneID"`
+	ModeratorID string `json:"moderatorID"`
+	UserID      string `json:"userID"`
+}
+
+// decodeModeration parses and authorizes a moderation request. The host and
+// co-hosts may moderate; nobody can target the host, and only the host can
+// target co-hosts.
+// It returns false if a response has already been written.
+func (h *SceneHandler) decodeModeration(w http.ResponseWriter, r *http.Request, req *moderationRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding moderation request body: %v", err)
+		return false
+	}
+
+	if req.SceneID == "" || req.ModeratorID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID, Moderator ID, and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, Moderator ID, or User ID is empty for moderation")
+		return false
+	}
+
+	role, ok := h.moderatorRole(w, r, req.SceneID, req.ModeratorID)
+	if !ok {
+		return false
+	}
+	target, err := h.Store.GetSceneRole(r.Context(), req.SceneID, req.UserID)
+	if !checkScene(w, err, req.SceneID) {
+		return false
+	}
+	if target == models.RoleHost {
+		http.Error(w, "The scene host cannot be moderated", http.StatusBadRequest)
+		return false
+	}
+	if target == models.RoleCoHost && role != models.RoleHost {
+		http.Error(w, "Only the scene host can moderate co-hosts", http.StatusForbidden)
+		log.Printf("Co-host %s attempted to moderate co-host %s in scene %s", req.ModeratorID, req.UserID, req.SceneID)
+		return false
+	}
+	return true
+}
+
+// moderatorRole loads userID's role in a scene and checks that it may
+// moderate. It returns false if a response has already been written.
+func (h *SceneHandler) moderatorRole(w http.ResponseWriter, r *http.Request, sceneID, userID string) (models.SceneRole, bool) {
+	role, err := h.Store.GetSceneRole(r.Context(), sceneID, userID)
+	if !checkScene(w, err, sceneID) {
+		return "", false
+	}
+	if !role.CanModerate() {
+		http.Error(w, "Only the scene host and co-hosts can moderate", http.StatusForbidden)
+		log.Printf("User %s attempted to moderate scene %s", userID, sceneID)
+		return "", false
+	}
+	return role, true
+}
+
+// KickUser handles the HTTP POST request to remove a user from a scene and
+// close their WebSocket connections. Kicked users may rejoin.
+func (h *SceneHandler) KickUser(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if !h.decodeModeration(w, r, &req) {
+		return
+	}
+
+	err := h.Store.LeaveScene(r.Context(), req.SceneID, req.UserID)
+	if err != nil && !errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Failed to kick user", http.StatusInternalServerError)
+		log.Printf("Error kicking %s from scene %s: %v", req.UserID, req.SceneID, err)
+		return
+	}
+	disconnected := h.Hub.DisconnectSceneUser(req.SceneID, req.UserID, "kicked")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":      "User kicked from scene",
+		"disconnected": disconnected,
+	})
+	log.Printf("User %s kicked from scene %s by %s", req.UserID, req.SceneID, req.ModeratorID)
+}
+
+// BanUser handles the HTTP POST request to ban a user from a scene. The user
+// is removed from the participants, disconnected, and blocked from rejoining.
+func (h *SceneHandler) BanUser(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if !h.decodeModeration(w, r, &req) {
+		return
+	}
+
+	if err := h.Store.AddRestriction(r.Context(), req.SceneID, req.UserID, models.RestrictionBan, req.ModeratorID); err != nil {
+		http.Error(w, "Failed to ban user", http.StatusInternalServerError)
+		log.Printf("Error banning %s from scene %s: %v", req.UserID, req.SceneID, err)
+		return
+	}
+	h.Hub.DisconnectSceneUser(req.SceneID, req.UserID, "banned")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "User banned from scene"})
+}
+
+// UnbanUser handles the HTTP POST request to lift a user's ban from a scene.
+func (h *SceneHandler) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	h.liftRestriction(w, r, models.RestrictionBan, "User unbanned from scene")
+}
+
+// MuteUser handles the HTTP POST request to stop a user posting chat messages in a scene.
+func (h *SceneHandler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if !h.decodeModeration(w, r, &req) {
+		return
+	}
+
+	if err := h.Store.AddRestriction(r.Context(), req.SceneID, req.UserID, models.RestrictionMute, req.ModeratorID); err != nil {
+		http.Error(w, "Failed to mute user", http.StatusInternalServerError)
+		log.Printf("Error muting %s in scene %s: %v", req.UserID, req.SceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "User muted in scene"})
+}
+
+// UnmuteUser handles the HTTP POST request to let a muted user post again.
+func (h *SceneHandler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	h.liftRestriction(w, r, models.RestrictionMute, "User unmuted in scene")
+}
+
+// liftRestriction removes a ban or mute on behalf of the scene creator.
+func (h *SceneHandler) liftRestriction(w http.ResponseWriter, r *http.Request, kind models.SceneRestriction, message string) {
+	var req moderationRequest
+	if !h.decodeModeration(w, r, &req) {
+		return
+	}
+
+	err := h.Store.RemoveRestriction(r.Context(), req.SceneID, req.UserID, kind)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User has no such restriction", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update restriction", http.StatusInternalServerError)
+		log.Printf("Error lifting %s for %s in scene %s: %v", kind, req.UserID, req.SceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// ListRoles handles the HTTP GET request to list a scene's host and co-hosts.
+// It expects the scene ID as the query parameter "scene_id" and accepts the
+// caller's "user_id", required for workspace scenes.
+func (h *SceneHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter (e.g., ?scene_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for ListRoles")
+		return
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	roles, err := h.Store.GetSceneRoles(r.Context(), sceneID)
+	if err != nil {
+		http.Error(w, "Failed to list roles", http.StatusInternalServerError)
+		log.Printf("Error listing roles for scene %s: %v", sceneID, err)
+		return
+	}
+	if roles == nil {
+		roles = []models.SceneRoleAssignment{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roles)
+}
+
+// PromoteUser handles the HTTP POST request to make a participant a co-host.
+func (h *SceneHandler) PromoteUser(w http.ResponseWriter, r *http.Request) {
+	h.changeRole(w, r, models.RoleCoHost)
+}
+
+// DemoteUser handles the HTTP POST request to make a co-host a listener again.
+func (h *SceneHandler) DemoteUser(w http.ResponseWriter, r *http.Request) {
+	h.changeRole(w, r, models.RoleListener)
+}
+
+// changeRole assigns role on behalf of the scene host and tells the scene.
+// Only the host may change roles, and only participants can be promoted.
+func (h *SceneHandler) changeRole(w http.ResponseWriter, r *http.Request, role models.SceneRole) {
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding role request body: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.ModeratorID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID, Moderator ID, and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, Moderator ID, or User ID is empty for role change")
+		return
+	}
+
+	current, err := h.Store.GetSceneRole(r.Context(), req.SceneID, req.ModeratorID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if current != models.RoleHost {
+		http.Error(w, "Only the scene host can change roles", http.StatusForbidden)
+		log.Printf("User %s attempted to change roles in scene %s", req.ModeratorID, req.SceneID)
+		return
+	}
+
+	if role == models.RoleCoHost {
+		participants, err := h.Store.GetSceneParticipants(r.Context(), []string{req.SceneID})
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("Error loading participants of scene %s: %v", req.SceneID, err)
+			return
+		}
+		if !slices.Contains(participants[req.SceneID], req.UserID) {
+			http.Error(w, "Only participants can be promoted", http.StatusConflict)
+			log.Printf("User %s is not a participant of scene %s", req.UserID, req.SceneID)
+			return
+		}
+	}
+
+	assignment, err := h.Store.SetSceneRole(r.Context(), req.SceneID, req.UserID, role, req.ModeratorID)
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "The scene host's role cannot be changed", http.StatusBadRequest)
+		return
+	}
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	h.Hub.SendToScene(req.SceneID, ws.TypeRoleChanged, assignment)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(assignment)
+	log.Printf("User %s made %s of scene %s by %s", req.UserID, role, req.SceneID, req.ModeratorID)
+}
+
+// WebSocket handler for scenes
+var sceneUpgrader = websocket.Upgrader{Subprotocols: ws.Subprotocols, EnableCompression: true} // Echo the negotiated protocol so browsers accept the handshake
+
+// ServeWS upgrades a participant of a scene to its WebSocket. The user is
+// identified by a token from /api/v1/users/ws-token, passed as the "token"
+// query parameter or after ws.TokenProtocol in Sec-WebSocket-Protocol.
+func (h *SceneHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required for WebSocket connection", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID missing for Scene WS")
+		return
+	}
+
+	userID, err := h.Tokens.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected Scene WS for scene %s: %v", sceneID, err)
+		return
+	}
+
+	scene, ok := h.getVisibleScene(w, r, sceneID, userID)
+	if !ok {
+		return
+	}
+	if scene.CreatorID != userID {
+		participants, err := h.Store.GetSceneParticipants(r.Context(), []string{sceneID})
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("Error loading participants of scene %s: %v", sceneID, err)
+			return
+		}
+		if !slices.Contains(participants[sceneID], userID) {
+			http.Error(w, "User has not joined this scene", http.StatusForbidden)
+			return
+		}
+	}
+
+	banned, err := h.Store.HasRestriction(r.Context(), sceneID, userID, models.RestrictionBan)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking ban for %s in scene %s: %v", userID, sceneID, err)
+		return
+	}
+	if banned {
+		http.Error(w, "User is banned from this scene", http.StatusForbidden)
+		return
+	}
+
+	conn, err := sceneUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade WebSocket for scene %s: %v", sceneID, err)
+		return
+	}
+	log.Printf("WebSocket connection upgraded for SceneID: %s, UserID: %s", sceneID, userID)
+
+	client := &ws.Client{
+		UserID:      userID,
+		SceneID:     sceneID, // Set the SceneID for this client
+		Conn:        conn,
+		IP:          h.Hub.RemoteIP(r),
+		LastEventID: r.URL.Query().Get("last_event_id"),
+	}
+	// Scene clients only receive; frames they send just keep the connection alive
+	h.Hub.Serve(client, nil)
 }
@@ -0,0 +1,133 @@
+package scenes
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/hashtags"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// trendingWindow is how far back hashtag uses count towards trending.
+const trendingWindow = 24 * time.Hour
+
+// Limits on the trending list.
+const (
+	defaultTrendingLimit = 10
+	maxTrendingLimit     = 50
+)
+
+// recordSceneHashtags stores the hashtags of scene's name and description
+// along with its tags. Failures only leave tag pages stale, so they are logged.
+func (h *SceneHandler) recordSceneHashtags(r *http.Request, scene *models.Scene) {
+	tags := hashtags.Parse(scene.Name + "\n" + scene.Description)
+	for _, tag := range scene.Tags {
+		if tag = hashtags.Normalize(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if err := h.Hashtags.SetSceneHashtags(r.Context(), scene.ID, tags); err != nil {
+		log.Printf("Error recording hashtags of scene %s: %v", scene.ID, err)
+	}
+}
+
+// recordMessageHashtags stores the hashtags used in a scene chat message.
+func (h *SceneHandler) recordMessageHashtags(r *http.Request, msg *models.SceneMessage) {
+	tags := hashtags.Parse(msg.Content)
+	if len(tags) == 0 {
+		return
+	}
+	if err := h.Hashtags.AddMessageHashtags(r.Context(), msg.SceneID, msg.ID, tags); err != nil {
+		log.Printf("Error recording hashtags of scene message %s: %v", msg.ID, err)
+	}
+}
+
+// GetTrendingTags handles the HTTP GET request for the hashtags used most in
+// scenes and scene chat over the last 24 hours. It accepts an optional "limit".
+func (h *SceneHandler) GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTrendingLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxTrendingLimit)
+	}
+
+	since := time.Now().Add(-trendingWindow)
+	trending, err := h.Hashtags.TrendingHashtags(r.Context(), since, limit)
+	if err != nil {
+		http.Error(w, "Failed to get trending tags", http.StatusInternalServerError)
+		log.Printf("Error getting trending tags: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		Since time.Time            `json:"since"`
+		Tags  []models.TrendingTag `json:"tags"`
+	}{since, trending})
+}
+
+// GetTagScenes handles the HTTP GET request for a tag page: the scenes whose
+// details or chat use a hashtag. It expects the query parameter "tag", with
+// or without the "#", and accepts optional "limit" and "offset".
+func (h *SceneHandler) GetTagScenes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	tag := hashtags.Normalize(q.Get("tag"))
+
+	if tag == "" {
+		http.Error(w, "Tag is required as a query parameter (e.g., ?tag=lofi)", http.StatusBadRequest)
+		log.Println("Validation error: Tag is empty for GetTagScenes")
+		return
+	}
+
+	limit, offset := 20, 0
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+	if o := q.Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			http.Error(w, "Offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+
+	scenes, err := h.Hashtags.GetScenesByHashtag(r.Context(), tag, limit, offset)
+	if err != nil {
+		http.Error(w, "Failed to get scenes for tag", http.StatusInternalServerError)
+		log.Printf("Error getting scenes for tag %q: %v", tag, err)
+		return
+	}
+	for _, scene := range scenes {
+		scene.ActiveUsers = h.Hub.GetActiveSceneUsersCount(scene.ID)
+	}
+
+	// nextOffset is omitted once a page comes back short
+	res := struct {
+		Tag        string          `json:"tag"`
+		Scenes     []*models.Scene `json:"scenes"`
+		NextOffset *int            `json:"nextOffset,omitempty"`
+	}{Tag: tag, Scenes: scenes}
+	if len(scenes) == limit {
+		next := offset + limit
+		res.NextOffset = &next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
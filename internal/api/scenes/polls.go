@@ -0,0 +1,278 @@
+package scenes
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// Limits on scene polls.
+const (
+	minPollOptions    = 2
+	maxPollOptions    = 10
+	maxQuestionLength = 200
+	maxOptionLength   = 100
+)
+
+// CreatePoll handles the HTTP POST request to ask a question in a scene.
+// It expects a JSON payload with "sceneID", "userID", "question", and "options";
+// only the host and co-hosts may create polls. The new poll is broadcast to the scene.
+func (h *SceneHandler) CreatePoll(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID  string   `json:"sceneID"`
+		UserID   string   `json:"userID"`
+		Question string   `json:"question"`
+		Options  []string `json:"options"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for CreatePoll: %v", err)
+		return
+	}
+
+	req.Question = strings.TrimSpace(req.Question)
+	if req.SceneID == "" || req.UserID == "" || req.Question == "" {
+		http.Error(w, "Scene ID, User ID, and Question cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, User ID, or Question is empty for CreatePoll")
+		return
+	}
+	if len([]rune(req.Question)) > maxQuestionLength {
+		http.Error(w, "Question is too long", http.StatusBadRequest)
+		return
+	}
+	options := make([]string, 0, len(req.Options))
+	for _, option := range req.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || len([]rune(option)) > maxOptionLength {
+			http.Error(w, "Poll options must be non-empty and at most 100 characters", http.StatusBadRequest)
+			return
+		}
+		options = append(options, option)
+	}
+	if len(options) < minPollOptions || len(options) > maxPollOptions {
+		http.Error(w, "A poll needs between 2 and 10 options", http.StatusBadRequest)
+		log.Printf("Validation error: %d options for CreatePoll", len(options))
+		return
+	}
+
+	role, err := h.Store.GetSceneRole(r.Context(), req.SceneID, req.UserID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if !role.CanModerate() {
+		http.Error(w, "Only the scene host and co-hosts can create polls", http.StatusForbidden)
+		log.Printf("User %s attempted to create a poll in scene %s", req.UserID, req.SceneID)
+		return
+	}
+
+	poll, err := h.Store.CreatePoll(r.Context(), req.SceneID, req.UserID, req.Question, options)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	h.Hub.SendToScene(poll.SceneID, ws.TypePoll, poll)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(poll)
+	log.Printf("Poll %s created in scene %s by %s", poll.ID, poll.SceneID, req.UserID)
+}
+
+// ListPolls handles the HTTP GET request to list a scene's polls with their results.
+// It expects the scene ID as the query parameter "scene_id" and accepts the
+// caller's "user_id", required for workspace scenes.
+func (h *SceneHandler) ListPolls(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter (e.g., ?scene_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for ListPolls")
+		return
+	}
+
+	if _, ok := h.getVisibleScene(w, r, sceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	polls, err := h.Store.GetPolls(r.Context(), sceneID)
+	if err != nil {
+		http.Error(w, "Failed to list polls", http.StatusInternalServerError)
+		log.Printf("Error listing polls for scene %s: %v", sceneID, err)
+		return
+	}
+	if polls == nil {
+		polls = []models.ScenePoll{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(polls)
+}
+
+// GetPollResults handles the HTTP GET request for a poll's current vote counts.
+// It expects the poll ID as the query parameter "poll_id" and accepts the
+// caller's "user_id", required for polls in workspace scenes.
+func (h *SceneHandler) GetPollResults(w http.ResponseWriter, r *http.Request) {
+	pollID := r.URL.Query().Get("poll_id")
+	if pollID == "" {
+		http.Error(w, "Poll ID is required as a query parameter (e.g., ?poll_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Poll ID is empty for GetPollResults")
+		return
+	}
+
+	poll, ok := h.getPoll(w, r, pollID)
+	if !ok {
+		return
+	}
+	if _, ok := h.getVisibleScene(w, r, poll.SceneID, r.URL.Query().Get("user_id")); !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(poll)
+}
+
+// VotePoll handles the HTTP POST request to vote in a poll.
+// It expects a JSON payload with "pollID", "userID", and the zero-based "option".
+// Only scene participants may vote; voting again changes the user's choice.
+// The updated counts are broadcast to the scene.
+func (h *SceneHandler) VotePoll(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PollID string `json:"pollID"`
+		UserID string `json:"userID"`
+		Option *int   `json:"option"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for VotePoll: %v", err)
+		return
+	}
+
+	if req.PollID == "" || req.UserID == "" || req.Option == nil {
+		http.Error(w, "Poll ID, User ID, and Option cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Poll ID, User ID, or Option is empty for VotePoll")
+		return
+	}
+
+	poll, ok := h.getPoll(w, r, req.PollID)
+	if !ok {
+		return
+	}
+	participants, err := h.Store.GetSceneParticipants(r.Context(), []string{poll.SceneID})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error loading participants of scene %s: %v", poll.SceneID, err)
+		return
+	}
+	if !slices.Contains(participants[poll.SceneID], req.UserID) {
+		http.Error(w, "Only scene participants can vote", http.StatusForbidden)
+		log.Printf("User %s attempted to vote in poll %s without joining scene %s", req.UserID, req.PollID, poll.SceneID)
+		return
+	}
+
+	poll, err = h.Store.Vote(r.Context(), req.PollID, req.UserID, *req.Option)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Poll not found", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrForbidden):
+		http.Error(w, "Poll is closed", http.StatusConflict)
+		return
+	case errors.Is(err, storage.ErrConflict):
+		http.Error(w, "Option is not part of this poll", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to record vote", http.StatusInternalServerError)
+		log.Printf("Error recording vote of %s in poll %s: %v", req.UserID, req.PollID, err)
+		return
+	}
+	h.Hub.SendToScene(poll.SceneID, ws.TypePoll, poll)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(poll)
+}
+
+// ClosePoll handles the HTTP POST request to stop a poll accepting votes.
+// It expects a JSON payload with "pollID" and "userID"; only the host and
+// co-hosts may close polls. The final results are broadcast to the scene.
+func (h *SceneHandler) ClosePoll(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PollID string `json:"pollID"`
+		UserID string `json:"userID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for ClosePoll: %v", err)
+		return
+	}
+
+	if req.PollID == "" || req.UserID == "" {
+		http.Error(w, "Poll ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Poll ID or User ID is empty for ClosePoll")
+		return
+	}
+
+	poll, ok := h.getPoll(w, r, req.PollID)
+	if !ok {
+		return
+	}
+	role, err := h.Store.GetSceneRole(r.Context(), poll.SceneID, req.UserID)
+	if !checkScene(w, err, poll.SceneID) {
+		return
+	}
+	if !role.CanModerate() {
+		http.Error(w, "Only the scene host and co-hosts can close polls", http.StatusForbidden)
+		log.Printf("User %s attempted to close poll %s", req.UserID, req.PollID)
+		return
+	}
+
+	poll, err = h.Store.ClosePoll(r.Context(), req.PollID)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Poll not found", http.StatusNotFound)
+		return
+	case errors.Is(err, storage.ErrConflict):
+		http.Error(w, "Poll is already closed", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to close poll", http.StatusInternalServerError)
+		log.Printf("Error closing poll %s: %v", req.PollID, err)
+		return
+	}
+	h.Hub.SendToScene(poll.SceneID, ws.TypePoll, poll)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(poll)
+	log.Printf("Poll %s closed by %s", poll.ID, req.UserID)
+}
+
+// getPoll loads a poll, writing the error response if it cannot. It returns
+// false if a response has already been written.
+func (h *SceneHandler) getPoll(w http.ResponseWriter, r *http.Request, pollID string) (*models.ScenePoll, bool) {
+	poll, err := h.Store.GetPoll(r.Context(), pollID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Poll not found", http.StatusNotFound)
+		log.Printf("Poll not found for ID: %s", pollID)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error accessing poll %s: %v", pollID, err)
+		return nil, false
+	}
+	return poll, true
+}
@@ -0,0 +1,47 @@
+package scenes
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// GetRecommendedScenes handles the HTTP GET request for the public scenes
+// recommended to a user. It expects the query parameter "user_id" and accepts
+// an optional "limit". Recommendations are recomputed periodically by the
+// scene-recommendations job, so new users get an empty list until it runs.
+func (h *SceneHandler) GetRecommendedScenes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID := q.Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetRecommendedScenes")
+		return
+	}
+
+	limit := 20
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	recommended, err := h.Recommendations.GetRecommendations(r.Context(), userID, limit)
+	if err != nil {
+		http.Error(w, "Failed to get recommended scenes", http.StatusInternalServerError)
+		log.Printf("Error getting recommended scenes for user %s: %v", userID, err)
+		return
+	}
+	for _, rec := range recommended {
+		rec.ActiveUsers = h.Hub.GetActiveSceneUsersCount(rec.ID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"scenes": recommended})
+}
@@ -0,0 +1,37 @@
+package scenes
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// GetReputation handles the HTTP GET request for a scene's host or a co-host
+// to look up a user's reputation before approving or banning them. It
+// expects the query parameters "scene_id", "user_id" (the moderator), and
+// "target_id" (the user looked up).
+func (h *SceneHandler) GetReputation(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	userID := r.URL.Query().Get("user_id")
+	targetID := r.URL.Query().Get("target_id")
+	if sceneID == "" || userID == "" || targetID == "" {
+		http.Error(w, "Scene ID, User ID, and Target ID are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID, User ID, or Target ID is empty for GetReputation")
+		return
+	}
+
+	if _, ok := h.moderatorRole(w, r, sceneID, userID); !ok {
+		return
+	}
+
+	reputations, err := h.Reputation.GetReputations(r.Context(), []string{targetID})
+	if err != nil {
+		http.Error(w, "Failed to get reputation", http.StatusInternalServerError)
+		log.Printf("Error getting reputation of %s for scene %s: %v", targetID, sceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reputations[targetID])
+}
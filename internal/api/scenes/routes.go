@@ -3,10 +3,50 @@ package scenes
 import (
 	"log"      // For logging messages
 	"net/http" // For HTTP request and response handling
+
+	"github.com/Vasu1712/scenyx-backend/internal/middleware" // Deprecation headers for the RPC-style routes
 )
 
 // RegisterSceneRoutes registers all scene-related HTTP routes with the provided ServeMux.
 func RegisterSceneRoutes(mux *http.ServeMux, handler *SceneHandler) {
+	// Resource routes take the scene ID from the path. They are registered
+	// without a method so the RPC-style routes below, kept for older clients
+	// and marked deprecated, stay more specific and the patterns do not conflict.
+	mux.HandleFunc("/api/v1/scenes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.GetSceneData(w, r)
+		case http.MethodPatch:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.UpdateScene(w, r)
+		case http.MethodDelete:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.DeleteScene(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET lists the connected users, POST joins, and DELETE leaves
+	mux.HandleFunc("/api/v1/scenes/{id}/participants", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.GetActiveUsers(w, r)
+		case http.MethodPost:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.JoinScene(w, r)
+		case http.MethodDelete:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.LeaveScene(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
 	// Register the handler for the "/api/v1/scenes/create" endpoint.
 	// This route is used to create a new scene.
 	mux.HandleFunc("/api/v1/scenes/create", func(w http.ResponseWriter, r *http.Request) {
@@ -37,7 +77,48 @@ func RegisterSceneRoutes(mux *http.ServeMux, handler *SceneHandler) {
 		handler.ListScenes(w, r)
 	})
 
-	mux.HandleFunc("/api/v1/scenes/data", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/scenes/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.SearchScenes(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/recommended", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetRecommendedScenes(w, r)
+	})
+
+	// Tag pages and trending hashtags
+	mux.HandleFunc("/api/v1/scenes/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetTagScenes(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/tags/trending", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetTrendingTags(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/data", middleware.Deprecated("/api/v1/scenes/{id}", func(w http.ResponseWriter, r *http.Request) {
 		// Ensure that only POST requests are allowed for this endpoint as it takes a body.
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -48,9 +129,19 @@ func RegisterSceneRoutes(mux *http.ServeMux, handler *SceneHandler) {
 		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
 		// Call the GetSceneData method of the SceneHandler to process the request.
 		handler.GetSceneData(w, r)
-	})
+	}))
+
+	mux.HandleFunc("/api/v1/scenes/active-users", middleware.Deprecated("/api/v1/scenes/{id}/participants", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetActiveUsers(w, r)
+	}))
 
-		mux.HandleFunc("/api/v1/scenes/join", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/scenes/join", middleware.Deprecated("/api/v1/scenes/{id}/participants", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
@@ -58,10 +149,51 @@ func RegisterSceneRoutes(mux *http.ServeMux, handler *SceneHandler) {
 		}
 		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
 		handler.JoinScene(w, r)
+	}))
+
+	// Join approval routes (scene host and co-hosts only)
+	mux.HandleFunc("/api/v1/scenes/join-requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ListJoinRequests(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/reputation", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetReputation(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/join-requests/approve", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ApproveJoinRequest(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/join-requests/reject", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.RejectJoinRequest(w, r)
 	})
 
 	// New route to allow a user to leave a scene
-	mux.HandleFunc("/api/v1/scenes/leave", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/v1/scenes/leave", middleware.Deprecated("/api/v1/scenes/{id}/participants", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
@@ -69,6 +201,303 @@ func RegisterSceneRoutes(mux *http.ServeMux, handler *SceneHandler) {
 		}
 		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
 		handler.LeaveScene(w, r)
+	}))
+
+	// Scene chat: POST sends a message, GET lists the history
+	mux.HandleFunc("/api/v1/scenes/messages", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.SendSceneMessage(w, r)
+		case http.MethodGet:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.GetSceneMessages(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/scenes/messages/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.SearchSceneMessages(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/pins", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ListPins(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/pins/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.PinMessage(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/pins/remove", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.UnpinMessage(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/messages/reactions/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.AddReaction(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/messages/reactions/remove", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.RemoveReaction(w, r)
+	})
+
+	// Scene polls: POST creates a poll, GET lists them with their results
+	mux.HandleFunc("/api/v1/scenes/polls", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.CreatePoll(w, r)
+		case http.MethodGet:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.ListPolls(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/scenes/polls/results", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetPollResults(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/polls/vote", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.VotePoll(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/polls/close", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ClosePoll(w, r)
+	})
+
+	// Track queue routes
+	mux.HandleFunc("/api/v1/scenes/queue/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.AddToQueue(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/queue/remove", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.RemoveFromQueue(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/queue/reorder", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ReorderQueue(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/queue/list", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ListQueue(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/queue/vote", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.VoteQueueItem(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/update", middleware.Deprecated("/api/v1/scenes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.UpdateScene(w, r)
+	}))
+
+	// GET redirects to a scene's uploaded cover, POST uploads a new one
+	mux.HandleFunc("/api/v1/scenes/cover", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.GetCover(w, r)
+		case http.MethodPost:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.UploadCover(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/scenes/delete", middleware.Deprecated("/api/v1/scenes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.DeleteScene(w, r)
+	}))
+
+	mux.HandleFunc("/api/v1/scenes/archive", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ArchiveScene(w, r)
+	})
+
+	// Moderation routes (scene host and co-hosts only)
+	mux.HandleFunc("/api/v1/scenes/kick", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.KickUser(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/ban", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.BanUser(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/unban", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.UnbanUser(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/mute", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.MuteUser(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/unmute", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.UnmuteUser(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/roles", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ListRoles(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/roles/promote", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.PromoteUser(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/roles/demote", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.DemoteUser(w, r)
 	})
 
 	// New WebSocket route for scene real-time updates
@@ -87,6 +516,62 @@ func RegisterSceneRoutes(mux *http.ServeMux, handler *SceneHandler) {
 		handler.GenerateShareLink(w, r)
 	})
 
+	// Share page that unfurls in link previews and redirects to the frontend
+	mux.HandleFunc(sharePagePath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ShareScene(w, r)
+	})
+
+	// Short share page of a scene that claimed a slug, /s/<slug>
+	mux.HandleFunc(slugSharePrefix, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ShareSceneBySlug(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/slug", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.SetSlug(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/transcript", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.GetTranscriptExport(w, r)
+		case http.MethodPost:
+			log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+			handler.ExportTranscript(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/scenes/by-slug", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetSceneBySlug(w, r)
+	})
+
 	// New route for a user to join a scene by clicking a shared link
 	mux.HandleFunc("/api/v1/scenes/join-by-link", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet { // This is a GET request, as it's a direct URL hit
@@ -97,6 +582,64 @@ func RegisterSceneRoutes(mux *http.ServeMux, handler *SceneHandler) {
 		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
 		handler.JoinSceneByLink(w, r)
 	})
-}
 
+	mux.HandleFunc("/api/v1/scenes/schedule", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.RescheduleScene(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/rsvp/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.AddRSVP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/rsvp/remove", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.RemoveRSVP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/rsvps", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.ListRSVPs(w, r)
+	})
 
+	mux.HandleFunc("/api/v1/scenes/analytics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetSceneAnalytics(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/scenes/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Scene] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Scene] %s %s", r.Method, r.URL.Path)
+		handler.GetSceneStats(w, r)
+	})
+}
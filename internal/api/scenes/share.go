@@ -0,0 +1,174 @@
+package scenes
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// sharePagePath is the API page shared links point at. Link previewers read
+// its Open Graph tags; browsers are sent on to the frontend.
+const sharePagePath = "/api/v1/scenes/share"
+
+// slugSharePrefix is the short share path of scenes with a slug, /s/<slug>.
+const slugSharePrefix = "/s/"
+
+// sharePage is the page served at sharePagePath. html/template escapes the
+// scene fields for their attribute and URL contexts.
+var sharePage = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <meta name="description" content="{{.Description}}">
+  <meta property="og:type" content="website">
+  <meta property="og:site_name" content="Scenyx">
+  <meta property="og:title" content="{{.Title}}">
+  <meta property="og:description" content="{{.Description}}">
+  <meta property="og:url" content="{{.URL}}">
+  {{- if .Image}}
+  <meta property="og:image" content="{{.Image}}">
+  <meta name="twitter:card" content="summary_large_image">
+  <meta name="twitter:image" content="{{.Image}}">
+  {{- else}}
+  <meta name="twitter:card" content="summary">
+  {{- end}}
+  <meta name="twitter:title" content="{{.Title}}">
+  <meta name="twitter:description" content="{{.Description}}">
+  <meta http-equiv="refresh" content="0; url={{.Redirect}}">
+</head>
+<body>
+  <p><a href="{{.Redirect}}">Open {{.Title}} on Scenyx</a></p>
+</body>
+</html>
+`))
+
+// sharePageData fills sharePage.
+type sharePageData struct {
+	Title       string
+	Description string
+	URL         string // Canonical share URL
+	Image       string // Absolute cover URL, empty if the scene has none
+	Redirect    string // Frontend scene page
+}
+
+// ShareScene handles the HTTP GET request for a scene's share page. It
+// expects the scene ID as a query parameter "scene_id". The page carries Open
+// Graph and Twitter card tags so shared links unfurl, and redirects browsers
+// to the frontend scene view. Workspace scenes have no share page, since link
+// previewers are not members.
+func (h *SceneHandler) ShareScene(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+
+	if sceneID == "" {
+		http.Error(w, "Scene ID is required as a query parameter (e.g., ?scene_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID is empty for ShareScene")
+		return
+	}
+
+	scene, ok := h.getVisibleScene(w, r, sceneID, "")
+	if !ok {
+		return
+	}
+	h.writeSharePage(w, r, scene)
+}
+
+// ShareSceneBySlug handles the HTTP GET request for the share page of the
+// scene that claimed the slug in the path, /s/<slug>. Like ShareScene, it
+// finds no page for workspace scenes.
+func (h *SceneHandler) ShareSceneBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := strings.ToLower(strings.TrimPrefix(r.URL.Path, slugSharePrefix))
+
+	if slug == "" || strings.Contains(slug, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	scene, err := h.Store.GetSceneBySlug(r.Context(), slug)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene by slug %s for ShareSceneBySlug: %v", slug, err)
+		return
+	}
+	if !h.checkVisible(w, r, scene, "") {
+		return
+	}
+	h.writeSharePage(w, r, scene)
+}
+
+// writeSharePage renders sharePage for scene.
+func (h *SceneHandler) writeSharePage(w http.ResponseWriter, r *http.Request, scene *models.Scene) {
+	base := requestBaseURL(r)
+	data := sharePageData{
+		Title:       scene.Name + " · " + scene.ArtistName,
+		Description: scene.Description,
+		URL:         shareURL(base, scene),
+		Redirect:    h.Links.Scene(scene.ID),
+	}
+	if data.Description == "" {
+		data.Description = "Listen to " + scene.ArtistName + " live with others on Scenyx."
+	}
+	if scene.CoverImages != nil {
+		data.Image = base + scene.CoverImages.Large
+	} else if isHTTPURL(scene.CoverImageURL) {
+		data.Image = scene.CoverImageURL
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// Let previewers cache the card briefly; scene details rarely change
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if err := sharePage.Execute(w, data); err != nil {
+		log.Printf("Error rendering share page for scene %s: %v", scene.ID, err)
+	}
+}
+
+// shareURL returns the share page URL of a scene on the API at base, using
+// its slug when it has claimed one.
+func shareURL(base string, scene *models.Scene) string {
+	if scene.Slug != "" {
+		return base + slugSharePrefix + scene.Slug
+	}
+	return base + sharePagePath + "?" + url.Values{"scene_id": {scene.ID}}.Encode()
+}
+
+// requestBaseURL returns the scheme and host the client used to reach the
+// API. X-Forwarded-Proto is honored so links stay https behind a TLS-terminating proxy.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+		scheme = proto
+	}
+	return scheme + "://" + strings.TrimSuffix(r.Host, "/")
+}
+
+// sceneShareLinks are the links returned for sharing a scene.
+type sceneShareLinks struct {
+	SceneID  string `json:"sceneID"`
+	URL      string `json:"url"`      // Share page, which unfurls with the scene's details; /s/<slug> once a slug is claimed
+	SceneURL string `json:"sceneURL"` // Frontend page of the scene, under FRONTEND_BASE_URL
+	Message  string `json:"message"`
+}
+
+// newSceneShareLinks returns the share links of scene for a request to the API.
+func (h *SceneHandler) newSceneShareLinks(r *http.Request, scene *models.Scene) sceneShareLinks {
+	return sceneShareLinks{
+		SceneID:  scene.ID,
+		URL:      shareURL(requestBaseURL(r), scene),
+		SceneURL: h.Links.Scene(scene.ID),
+		Message:  "Scene exists and can be shared.",
+	}
+}
@@ -0,0 +1,131 @@
+package scenes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// Limits on scene slugs.
+const (
+	minSlugLength = 3
+	maxSlugLength = 48
+)
+
+// normalizeSlug lowercases slug and checks that it is made of letters and
+// digits separated by single hyphens. An empty slug is valid and releases
+// the scene's slug.
+func normalizeSlug(slug string) (string, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if slug == "" {
+		return "", nil
+	}
+	if len(slug) < minSlugLength || len(slug) > maxSlugLength {
+		return "", fmt.Errorf("Slug must be between %d and %d characters", minSlugLength, maxSlugLength)
+	}
+	for i, c := range slug {
+		switch {
+		case 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+		case c == '-' && i > 0 && i < len(slug)-1 && slug[i-1] != '-':
+		default:
+			return "", errors.New("Slug may only contain letters, digits, and single hyphens between them")
+		}
+	}
+	return slug, nil
+}
+
+// SetSlug handles the HTTP POST request for a scene's creator to claim a slug.
+// It expects a JSON payload with "sceneID", "userID", and "slug"; an empty
+// slug releases the current one. Slugs are unique across scenes.
+func (h *SceneHandler) SetSlug(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string `json:"sceneID"`
+		UserID  string `json:"userID"`
+		Slug    string `json:"slug"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SetSlug: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for SetSlug")
+		return
+	}
+	slug, err := normalizeSlug(req.Slug)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Validation error: %v for SetSlug", err)
+		return
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if scene.CreatorID != req.UserID {
+		http.Error(w, "Only the scene creator can change the slug", http.StatusForbidden)
+		log.Printf("User %s attempted to change the slug of scene %s", req.UserID, req.SceneID)
+		return
+	}
+
+	scene, err = h.Store.SetSceneSlug(r.Context(), req.SceneID, slug)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Slug is already taken", http.StatusConflict)
+		return
+	}
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	scene.ActiveUsers = h.Hub.GetActiveSceneUsersCount(scene.ID)
+
+	// Let open clients refresh the scene header
+	h.Hub.SendToScene(scene.ID, ws.TypeSceneUpdated, scene)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scene)
+	log.Printf("Scene %s slug set to %q by creator %s", scene.ID, scene.Slug, req.UserID)
+}
+
+// GetSceneBySlug handles the HTTP GET request to resolve a slug to its scene.
+// It expects the slug as a query parameter "slug" and accepts the caller's
+// "user_id", required for workspace scenes.
+func (h *SceneHandler) GetSceneBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("slug")))
+
+	if slug == "" {
+		http.Error(w, "Slug is required as a query parameter (e.g., ?slug=friday-techno)", http.StatusBadRequest)
+		log.Println("Validation error: Slug is empty for GetSceneBySlug")
+		return
+	}
+
+	scene, err := h.Store.GetSceneBySlug(r.Context(), slug)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene by slug %s: %v", slug, err)
+		return
+	}
+	if !h.checkVisible(w, r, scene, r.URL.Query().Get("user_id")) {
+		return
+	}
+	scene.ActiveUsers = h.Hub.GetActiveSceneUsersCount(scene.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(scene)
+}
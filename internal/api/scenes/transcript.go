@@ -0,0 +1,126 @@
+package scenes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/transcripts"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// ExportTranscript handles the HTTP POST request for a scene's creator to
+// export its chat and track history; ephemeral scenes keep none and are
+// refused with 409 Conflict. It expects a JSON payload with
+// "sceneID", "userID", and "format" (json, the default, or csv). Scenes with
+// up to transcripts.InlineLimit messages are exported in the response as a
+// file download; larger ones are queued and the reply is 202 Accepted with
+// the export, whose downloadURL is set once GetTranscriptExport reports it
+// ready. The creator is also sent a transcript.ready event.
+func (h *SceneHandler) ExportTranscript(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string                  `json:"sceneID"`
+		UserID  string                  `json:"userID"`
+		Format  models.TranscriptFormat `json:"format"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for ExportTranscript: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for ExportTranscript")
+		return
+	}
+	if req.Format == "" {
+		req.Format = models.TranscriptJSON
+	}
+	if !req.Format.IsValid() {
+		http.Error(w, "Format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	scene, err := h.Store.GetScene(r.Context(), req.SceneID)
+	if !checkScene(w, err, req.SceneID) {
+		return
+	}
+	if scene.CreatorID != req.UserID {
+		http.Error(w, "Only the scene creator can export its transcript", http.StatusForbidden)
+		log.Printf("User %s attempted to export the transcript of scene %s", req.UserID, req.SceneID)
+		return
+	}
+	if scene.Ephemeral {
+		http.Error(w, "Ephemeral scenes cannot be exported", http.StatusConflict)
+		return
+	}
+
+	count, err := h.Transcripts.Store.CountSceneMessages(r.Context(), scene.ID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error counting messages of scene %s for ExportTranscript: %v", scene.ID, err)
+		return
+	}
+
+	if count > transcripts.InlineLimit {
+		export, err := h.Transcripts.Store.CreateTranscriptExport(r.Context(), scene.ID, req.UserID, req.Format)
+		if err != nil {
+			http.Error(w, "Failed to queue transcript export", http.StatusInternalServerError)
+			log.Printf("Error queueing transcript export of scene %s: %v", scene.ID, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(export)
+		log.Printf("Queued transcript export %s of scene %s (%d messages)", export.ID, scene.ID, count)
+		return
+	}
+
+	// Build the file before writing headers so a failure can still be reported
+	var buf bytes.Buffer
+	if err := h.Transcripts.Write(r.Context(), &buf, scene, req.Format); err != nil {
+		http.Error(w, "Failed to export transcript", http.StatusInternalServerError)
+		log.Printf("Error exporting transcript of scene %s: %v", scene.ID, err)
+		return
+	}
+	w.Header().Set("Content-Type", req.Format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="scene-`+scene.ID+`.`+string(req.Format)+`"`)
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
+
+// GetTranscriptExport handles the HTTP GET request for the status of a
+// queued transcript export. It expects the query parameters "export_id" and
+// "user_id", which must be the user who requested it.
+func (h *SceneHandler) GetTranscriptExport(w http.ResponseWriter, r *http.Request) {
+	exportID := r.URL.Query().Get("export_id")
+	userID := r.URL.Query().Get("user_id")
+
+	if exportID == "" || userID == "" {
+		http.Error(w, "Export ID and User ID are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Export ID or User ID is empty for GetTranscriptExport")
+		return
+	}
+
+	export, err := h.Transcripts.Store.GetTranscriptExport(r.Context(), exportID)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && export.RequestedBy != userID) {
+		http.Error(w, "Transcript export not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting transcript export %s: %v", exportID, err)
+		return
+	}
+	export.DownloadURL = h.Transcripts.URL(export)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(export)
+}
@@ -0,0 +1,39 @@
+package tickets
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// Routes describes the routes registered by RegisterTicketRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/tickets/checkout", ID: "createTicketCheckout", Tag: "Tickets",
+		Summary: "Start buying a ticket to a scene",
+		Description: "Creates a Stripe Checkout session for the scene's ticket price. Send the user to checkoutURL; " +
+			"the ticket stays pending until Stripe confirms the payment, then the user is joined to the scene and sent a ticket.updated event. " +
+			"Returns 409 if the scene is free or archived, or the user already has a ticket.",
+		Body: struct {
+			SceneID string `json:"sceneID"`
+			UserID  string `json:"userID"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.SceneTicket{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/tickets", ID: "getTicket", Tag: "Tickets",
+		Summary:     "Get a user's ticket to a scene",
+		Description: "Returns the paid ticket if the user has one, otherwise their most recent checkout.",
+		Query:       []openapi.Param{{Name: "scene_id", Required: true}, {Name: "user_id", Required: true}},
+		Response:    models.SceneTicket{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/tickets/stripe/webhook", ID: "stripeWebhook", Tag: "Tickets",
+		Summary: "Receive Stripe webhook events",
+		Description: "Called by Stripe, not clients. The payload must carry a valid Stripe-Signature header. " +
+			"Settles both ticket and gift checkouts. Handles checkout.session.completed, checkout.session.async_payment_succeeded, checkout.session.expired, " +
+			"checkout.session.async_payment_failed, and charge.refunded; other events are acknowledged and ignored.",
+	},
+}
@@ -0,0 +1,192 @@
+package tickets
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/links"
+	"github.com/Vasu1712/scenyx-backend/internal/app/payments"
+	"github.com/Vasu1712/scenyx-backend/internal/app/tipping"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxWebhookSize bounds the Stripe webhook payloads read; events are a few kilobytes.
+const maxWebhookSize = 256 << 10
+
+// TicketHandler holds the dependencies for buying tickets to ticketed scenes.
+type TicketHandler struct {
+	Payments *payments.Service   // Stripe Checkout sessions and webhook events
+	Tickets  storage.TicketStore // Tickets bought, checked before starting another checkout
+	Scenes   storage.SceneStore  // Supplies the price and joins buyers once they have paid
+	Hub      *ws.Hub             // Tells buyers their ticket was paid, failed, or refunded
+	Links    *links.Builder      // Forms the pages Stripe returns buyers to
+	Tipping  *tipping.Service    // Delivers gifts paid through Stripe
+}
+
+// CreateCheckout handles the HTTP POST request to buy a ticket to a scene.
+// It expects a JSON payload with "sceneID" and "userID" and responds with
+// the pending ticket, whose "checkoutURL" is the Stripe payment page. Access
+// is unlocked when Stripe reports the payment through StripeWebhook.
+func (h *TicketHandler) CreateCheckout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SceneID string `json:"sceneID"`
+		UserID  string `json:"userID"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for CreateCheckout: %v", err)
+		return
+	}
+
+	if req.SceneID == "" || req.UserID == "" {
+		http.Error(w, "Scene ID and User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID is empty for CreateCheckout")
+		return
+	}
+
+	scene, err := h.Scenes.GetScene(r.Context(), req.SceneID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting scene %s for CreateCheckout: %v", req.SceneID, err)
+		return
+	}
+	if !scene.Ticketed() || req.UserID == scene.CreatorID {
+		http.Error(w, "Scene does not require a ticket", http.StatusConflict)
+		return
+	}
+	if scene.ArchivedAt != nil {
+		http.Error(w, "Scene is archived", http.StatusConflict)
+		return
+	}
+
+	paid, err := h.Tickets.HasPaidTicket(r.Context(), scene.ID, req.UserID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking ticket of user %s for scene %s: %v", req.UserID, scene.ID, err)
+		return
+	}
+	if paid {
+		http.Error(w, "User already has a ticket", http.StatusConflict)
+		return
+	}
+
+	ticket, err := h.Payments.CreateCheckout(r.Context(), scene, req.UserID,
+		h.Links.SceneCheckout(scene.ID, "success"), h.Links.SceneCheckout(scene.ID, "cancelled"))
+	if err != nil {
+		http.Error(w, "Failed to start checkout", http.StatusBadGateway)
+		log.Printf("Error creating checkout for user %s in scene %s: %v", req.UserID, scene.ID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ticket)
+	log.Printf("Started checkout %s for user %s in scene %s", ticket.CheckoutSessionID, req.UserID, scene.ID)
+}
+
+// GetTicket handles the HTTP GET request for a user's ticket to a scene, so
+// clients returning from checkout can wait for the payment to be confirmed.
+// It expects the query parameters "scene_id" and "user_id".
+func (h *TicketHandler) GetTicket(w http.ResponseWriter, r *http.Request) {
+	sceneID := r.URL.Query().Get("scene_id")
+	userID := r.URL.Query().Get("user_id")
+
+	if sceneID == "" || userID == "" {
+		http.Error(w, "Scene ID and User ID are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: Scene ID or User ID missing for GetTicket")
+		return
+	}
+
+	ticket, err := h.Tickets.GetTicket(r.Context(), sceneID, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Ticket not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting ticket of user %s for scene %s: %v", userID, sceneID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ticket)
+}
+
+// StripeWebhook handles the HTTP POST requests Stripe sends as checkouts
+// complete, expire, and are refunded. The payload is verified against the
+// Stripe-Signature header. A paid ticket joins its buyer to the scene, or
+// lets them request to join if it requires approval; a paid gift is
+// delivered to its scene.
+func (h *TicketHandler) StripeWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading body for StripeWebhook: %v", err)
+		return
+	}
+
+	update, err := h.Payments.HandleWebhook(r.Context(), payload, r.Header.Get("Stripe-Signature"))
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		http.Error(w, "Invalid signature", http.StatusBadRequest)
+		log.Println("Rejected Stripe webhook with an invalid signature")
+		return
+	}
+	if err != nil {
+		// Stripe retries events that fail, so this one will be redelivered
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error handling Stripe webhook: %v", err)
+		return
+	}
+
+	switch {
+	case update == nil:
+	case update.Ticket != nil:
+		ticket := update.Ticket
+		if ticket.Status == models.TicketPaid {
+			h.admit(r, ticket)
+		}
+		h.Hub.SendToUser(ticket.UserID, ws.TypeTicketUpdated, ticket)
+		log.Printf("Ticket %s of user %s for scene %s is now %s", ticket.ID, ticket.UserID, ticket.SceneID, ticket.Status)
+	case update.Gift != nil:
+		gift := update.Gift
+		if gift.Status == models.GiftCompleted {
+			h.Tipping.Deliver(gift)
+		} else {
+			log.Printf("Gift %s from %s in scene %s is now %s", gift.ID, gift.SenderID, gift.SceneID, gift.Status)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// admit joins the buyer of a paid ticket to its scene, unless the scene
+// requires approval, in which case the buyer may now request to join.
+func (h *TicketHandler) admit(r *http.Request, ticket *models.SceneTicket) {
+	scene, err := h.Scenes.GetScene(r.Context(), ticket.SceneID)
+	if err != nil {
+		log.Printf("Error getting scene %s to admit ticket %s: %v", ticket.SceneID, ticket.ID, err)
+		return
+	}
+	if scene.RequiresApproval {
+		return
+	}
+	err = h.Scenes.JoinScene(r.Context(), scene.ID, ticket.UserID)
+	switch {
+	case err == nil, errors.Is(err, storage.ErrConflict):
+	case errors.Is(err, storage.ErrForbidden):
+		log.Printf("User %s paid for scene %s but is banned from it", ticket.UserID, scene.ID)
+	default:
+		log.Printf("Error joining user %s to scene %s after payment: %v", ticket.UserID, scene.ID, err)
+	}
+}
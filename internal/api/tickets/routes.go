@@ -0,0 +1,39 @@
+package tickets
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterTicketRoutes registers the ticket purchase and Stripe webhook routes with the provided ServeMux.
+func RegisterTicketRoutes(mux *http.ServeMux, handler *TicketHandler) {
+	mux.HandleFunc("/api/v1/tickets/checkout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Ticket] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Ticket] %s %s", r.Method, r.URL.Path)
+		handler.CreateCheckout(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/tickets", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Ticket] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Ticket] %s %s", r.Method, r.URL.Path)
+		handler.GetTicket(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/tickets/stripe/webhook", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Ticket] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Ticket] %s %s", r.Method, r.URL.Path)
+		handler.StripeWebhook(w, r)
+	})
+}
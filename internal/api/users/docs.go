@@ -0,0 +1,222 @@
+package users
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// Routes describes the routes registered by RegisterUserRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/users/signup", ID: "signup", Tag: "Users",
+		Summary:     "Register a new user",
+		Description: "username is optional and can be claimed later; 409 if the email or username is taken.",
+		Body: struct {
+			DisplayName string `json:"displayName"`
+			Username    string `json:"username,omitempty"`
+			Email       string `json:"email"`
+			Password    string `json:"password"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: sessionResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/users/login", ID: "login", Tag: "Users",
+		Summary: "Authenticate a user",
+		Body: struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}{},
+		Response: sessionResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/users/ws-token", ID: "renewWSToken", Tag: "Users",
+		Summary:     "Exchange a WebSocket token for a fresh one",
+		Description: "The token must not have expired yet; otherwise the user has to log in again.",
+		Body: struct {
+			Token string `json:"token"`
+		}{},
+		Response: struct {
+			Token     string    `json:"token"`
+			ExpiresAt time.Time `json:"expiresAt"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/profile", ID: "getProfile", Tag: "Users",
+		Summary:     "Fetch a user's profile",
+		Description: "badges lists the achievements the user earned, oldest first.",
+		Query:       []openapi.Param{{Name: "user_id", Required: true}},
+		Response:    profileResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/users/profile", ID: "updateProfile", Tag: "Users",
+		Summary: "Change a user's display name",
+		Body: struct {
+			UserID      string `json:"userID"`
+			DisplayName string `json:"displayName"`
+		}{},
+		Response: models.User{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/username", ID: "checkUsername", Tag: "Users",
+		Summary: "Check whether a username is available",
+		Query:   []openapi.Param{{Name: "username", Required: true}},
+		Response: struct {
+			Username  string `json:"username"` // Normalized to lowercase
+			Available bool   `json:"available"`
+		}{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/users/username", ID: "setUsername", Tag: "Users",
+		Summary: "Claim a username",
+		Description: "Usernames are 3-30 characters, start with a letter, contain only letters, digits, and " +
+			"underscores, and are stored lowercase. 409 if another user has it.",
+		Body: struct {
+			UserID   string `json:"userID"`
+			Username string `json:"username"`
+		}{},
+		Response: models.User{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/search", ID: "searchUsers", Tag: "Users",
+		Summary:     "Find users by username or display name prefix",
+		Description: "Matching is case-insensitive; username matches are listed first. Emails are not returned.",
+		Query: []openapi.Param{
+			{Name: "q", Required: true},
+			{Name: "limit", Type: "integer", Description: "Defaults to 20, at most 50"},
+		},
+		Response: struct {
+			Users []models.UserSummary `json:"users"`
+		}{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/users/follow", ID: "followUser", Tag: "Users",
+		Summary:     "Follow a user",
+		Description: "Scenes of followed creators are favored in recommendations.",
+		Body:        followRequest{},
+		Response:    followResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/users/unfollow", ID: "unfollowUser", Tag: "Users",
+		Summary:  "Stop following a user",
+		Body:     followRequest{},
+		Response: followResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/following", ID: "getFollowing", Tag: "Users",
+		Summary: "List the users a user follows",
+		Query:   []openapi.Param{{Name: "user_id", Required: true}},
+		Response: struct {
+			Users []models.UserSummary `json:"users"`
+		}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/settings", ID: "getSettings", Tag: "Users",
+		Summary:     "Get a user's settings",
+		Description: "Users who never saved settings get the defaults.",
+		Query:       []openapi.Param{{Name: "user_id", Required: true}},
+		Response:    settingsResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/users/settings", ID: "updateSettings", Tag: "Users",
+		Summary: "Change a user's settings",
+		Description: "Settings left out keep their current value. Unknown settings and unsupported values " +
+			"are rejected with 400. notifications turns notification channels on or off; muteAll silences " +
+			"them all, including conversations with an override. During notifications.quietHours, in its " +
+			"timeZone, notifications are held back and sent as one notification.summary event when they end.",
+		Body: struct {
+			UserID   string              `json:"userID"`
+			Settings models.UserSettings `json:"settings"`
+		}{},
+		Response: settingsResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/notifications/overrides", ID: "getNotificationOverrides", Tag: "Users",
+		Summary: "List a user's per-conversation notification overrides",
+		Query:   []openapi.Param{{Name: "user_id", Required: true}},
+		Response: struct {
+			Overrides []models.NotificationOverride `json:"overrides"`
+		}{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/users/notifications/overrides", ID: "setNotificationOverride", Tag: "Users",
+		Summary: "Set a user's notification level for a DM or scene",
+		Description: "Set exactly one of dmID and sceneID. level is all (notify even for channels turned off), " +
+			"mentions (only @mentions), or none (mute). 404 if the scene does not exist or the user is not in the DM.",
+		Body:     overrideRequest{},
+		Response: models.NotificationOverride{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/users/notifications/overrides", ID: "deleteNotificationOverride", Tag: "Users",
+		Summary: "Remove a user's notification override for a DM or scene",
+		Query: []openapi.Param{
+			{Name: "user_id", Required: true},
+			{Name: "dm_id", Description: "Set exactly one of dm_id and scene_id"},
+			{Name: "scene_id"},
+		},
+		Status: http.StatusNoContent,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/keys", ID: "getPublicKeys", Tag: "Users",
+		Summary: "List the public keys of every device of a user",
+		Query:   []openapi.Param{{Name: "user_id", Required: true}},
+		Response: struct {
+			Keys []models.PublicKey `json:"keys"`
+		}{},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/users/keys", ID: "registerPublicKey", Tag: "Users",
+		Summary: "Register the public key of one of a user's devices",
+		Description: "Replaces any key the device had. publicKey is base64, at most 1 KiB decoded. " +
+			"Others wrap conversation keys with it for end-to-end encrypted DMs.",
+		Body: struct {
+			UserID    string `json:"userID"`
+			DeviceID  string `json:"deviceID"`
+			Algorithm string `json:"algorithm"`
+			PublicKey string `json:"publicKey"`
+		}{},
+		Response: models.PublicKey{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/v1/users/keys", ID: "deletePublicKey", Tag: "Users",
+		Summary: "Remove the public key of a device",
+		Query:   []openapi.Param{{Name: "user_id", Required: true}, {Name: "device_id", Required: true}},
+		Status:  http.StatusNoContent,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/avatar", ID: "getAvatar", Tag: "Users",
+		Summary:     "Redirect to a user's avatar",
+		Description: "Redirects to where the avatar image is stored.",
+		Query:       []openapi.Param{{Name: "user_id", Required: true}},
+		Status:      http.StatusFound,
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/users/avatar", ID: "uploadAvatar", Tag: "Users",
+		Summary:     "Replace a user's avatar",
+		Description: "The content type is sniffed from the file rather than trusted from the client.",
+		Form: []openapi.Param{
+			{Name: "userID", Required: true},
+			{Name: "avatar", Type: "file", Required: true},
+		},
+		Response: models.User{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/users/presence", ID: "getPresence", Tag: "Users",
+		Summary:  "Fetch the presence of several users",
+		Query:    []openapi.Param{{Name: "ids", Required: true, Description: "Comma-separated user IDs"}},
+		Response: []ws.Presence{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/users/presence", ID: "setPresence", Tag: "Users",
+		Summary: `Switch a connected user between "online" and "away"`,
+		Body: struct {
+			UserID string            `json:"userID"`
+			Status ws.PresenceStatus `json:"status"`
+		}{},
+		Response: ws.Presence{},
+	},
+}
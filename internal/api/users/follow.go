@@ -0,0 +1,117 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// followRequest is the payload of Follow and Unfollow.
+type followRequest struct {
+	UserID     string `json:"userID"`
+	FolloweeID string `json:"followeeID"`
+}
+
+// followResponse is the reply of Follow and Unfollow.
+type followResponse struct {
+	UserID     string `json:"userID"`
+	FolloweeID string `json:"followeeID"`
+	Following  bool   `json:"following"`
+}
+
+// Follow handles the HTTP POST request for a user to follow another user,
+// typically a scene creator. It expects a JSON payload with "userID" and "followeeID".
+func (h *UserHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, true)
+}
+
+// Unfollow handles the HTTP POST request to stop following a user.
+// It expects a JSON payload with "userID" and "followeeID".
+func (h *UserHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, false)
+}
+
+func (h *UserHandler) changeFollow(w http.ResponseWriter, r *http.Request, follow bool) {
+	var req followRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding follow request body: %v", err)
+		return
+	}
+
+	if req.UserID == "" || req.FolloweeID == "" {
+		http.Error(w, "User ID and Followee ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: User ID or Followee ID is empty for follow")
+		return
+	}
+	if req.UserID == req.FolloweeID {
+		http.Error(w, "Users cannot follow themselves", http.StatusBadRequest)
+		return
+	}
+
+	if follow {
+		err = h.Store.Follow(r.Context(), req.UserID, req.FolloweeID)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		case errors.Is(err, storage.ErrConflict):
+			http.Error(w, "Already following this user", http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, "Failed to follow user", http.StatusInternalServerError)
+			log.Printf("Error following user %s by %s: %v", req.FolloweeID, req.UserID, err)
+			return
+		}
+	} else {
+		err = h.Store.Unfollow(r.Context(), req.UserID, req.FolloweeID)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			http.Error(w, "Not following this user", http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, "Failed to unfollow user", http.StatusInternalServerError)
+			log.Printf("Error unfollowing user %s by %s: %v", req.FolloweeID, req.UserID, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(followResponse{UserID: req.UserID, FolloweeID: req.FolloweeID, Following: follow})
+	log.Printf("User %s following=%t for user %s", req.UserID, follow, req.FolloweeID)
+}
+
+// GetFollowing handles the HTTP GET request to list the users a user follows.
+// It expects the user ID as a query parameter "user_id".
+func (h *UserHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetFollowing")
+		return
+	}
+
+	users, err := h.Store.GetFollowing(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get followed users", http.StatusInternalServerError)
+		log.Printf("Error getting users followed by %s: %v", userID, err)
+		return
+	}
+	// Only public fields are returned; emails stay private
+	results := make([]models.UserSummary, 0, len(users))
+	for _, user := range users {
+		results = append(results, user.Summary())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"users": results})
+}
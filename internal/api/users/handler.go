@@ -0,0 +1,442 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/uploads"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength is the shortest password accepted at signup.
+const minPasswordLength = 8
+
+// UserHandler holds the dependencies for handling user-related HTTP requests.
+type UserHandler struct {
+	Store         storage.UserStore         // The UserStore used to interact with user data
+	Notifications storage.NotificationStore // Per-conversation notification overrides
+	Hub           *ws.Hub                   // The WebSocket Hub, used for live presence
+	Avatars       uploads.Blobs             // Where uploaded avatars are stored
+	Tokens        *ws.TokenSigner           // Issues the tokens that authenticate WebSocket upgrades
+	Badges        storage.BadgeStore        // Badges shown on profiles
+}
+
+// profileResponse is the reply of GetProfile: the user plus the badges they earned.
+type profileResponse struct {
+	*models.User
+	Badges []models.UserBadge `json:"badges"`
+}
+
+// sessionResponse is the reply of Signup and Login: the user plus a token for
+// opening WebSockets, renewed through RenewWSToken before it expires.
+type sessionResponse struct {
+	*models.User
+	WSToken string `json:"wsToken"`
+}
+
+// maxPresenceIDs caps how many users a single presence lookup may request.
+const maxPresenceIDs = 100
+
+// checkUser writes the appropriate error response for a failed user lookup.
+// It returns true if err is nil and the handler should continue.
+func checkUser(w http.ResponseWriter, err error, userID string) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		log.Printf("User not found for ID: %s", userID)
+		return false
+	}
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+	log.Printf("Error accessing user %s: %v", userID, err)
+	return false
+}
+
+// Signup handles the HTTP POST request to register a new user.
+// It expects a JSON payload with "displayName", "email", and "password",
+// and optionally "username".
+func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DisplayName string `json:"displayName"`
+		Username    string `json:"username"`
+		Email       string `json:"email"`
+		Password    string `json:"password"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for Signup: %v", err)
+		return
+	}
+
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.DisplayName = strings.TrimSpace(req.DisplayName)
+
+	if req.DisplayName == "" || req.Email == "" || req.Password == "" {
+		http.Error(w, "Display Name, Email, and Password cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Display Name, Email, or Password is empty for Signup")
+		return
+	}
+	if len(req.Password) < minPasswordLength {
+		http.Error(w, "Password must be at least 8 characters", http.StatusBadRequest)
+		return
+	}
+	if req.Username != "" {
+		if req.Username, err = normalizeUsername(req.Username); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			log.Printf("Validation error: %v for Signup", err)
+			return
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		log.Printf("Error hashing password for %s: %v", req.Email, err)
+		return
+	}
+
+	user, err := h.Store.CreateUser(r.Context(), req.DisplayName, req.Username, req.Email, string(hash))
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Email or username is already registered", http.StatusConflict)
+		log.Printf("Signup rejected: email %s or username %q already registered", req.Email, req.Username)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		log.Printf("Error creating user %s: %v", req.Email, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sessionResponse{User: user, WSToken: h.Tokens.Issue(user.ID)})
+
+	log.Printf("Signed up user: ID=%s, Email=%s", user.ID, user.Email)
+}
+
+// Login handles the HTTP POST request to authenticate a user.
+// It expects a JSON payload with "email" and "password" and returns the user on success.
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for Login: %v", err)
+		return
+	}
+
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if req.Email == "" || req.Password == "" {
+		http.Error(w, "Email and Password cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: Email or Password is empty for Login")
+		return
+	}
+
+	// Use the same response for unknown emails and wrong passwords so
+	// callers can't probe which emails are registered.
+	user, err := h.Store.GetUserByEmail(r.Context(), req.Email)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error looking up user %s for login: %v", req.Email, err)
+		return
+	}
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		log.Printf("Failed login attempt for email: %s", req.Email)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sessionResponse{User: user, WSToken: h.Tokens.Issue(user.ID)})
+
+	log.Printf("User logged in: ID=%s", user.ID)
+}
+
+// RenewWSToken handles the HTTP POST request to exchange a WebSocket token
+// that has not expired yet for a fresh one. It expects a JSON payload with "token".
+func (h *UserHandler) RenewWSToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for RenewWSToken: %v", err)
+		return
+	}
+
+	userID, err := h.Tokens.Verify(req.Token)
+	if err != nil {
+		http.Error(w, "Invalid or expired token; log in again", http.StatusUnauthorized)
+		return
+	}
+
+	var res struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	res.ExpiresAt = time.Now().Add(h.Tokens.TTL()).Truncate(time.Second) // Tokens carry whole-second expiries
+	res.Token = h.Tokens.Issue(userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetProfile handles the HTTP GET request to fetch a user's profile and
+// badges. It expects the user ID as a query parameter "user_id".
+func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetProfile")
+		return
+	}
+
+	user, err := h.Store.GetUser(r.Context(), userID)
+	if !checkUser(w, err, userID) {
+		return
+	}
+	badges, err := h.Badges.GetUserBadges(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error getting badges of user %s: %v", userID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(profileResponse{User: user, Badges: badges})
+}
+
+// UpdateProfile handles the HTTP PUT request to change a user's display name.
+// It expects a JSON payload with "userID" and "displayName".
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID      string `json:"userID"`
+		DisplayName string `json:"displayName"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for UpdateProfile: %v", err)
+		return
+	}
+
+	req.DisplayName = strings.TrimSpace(req.DisplayName)
+	if req.UserID == "" || req.DisplayName == "" {
+		http.Error(w, "User ID and Display Name cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: User ID or Display Name is empty for UpdateProfile")
+		return
+	}
+
+	user, err := h.Store.UpdateDisplayName(r.Context(), req.UserID, req.DisplayName)
+	if !checkUser(w, err, req.UserID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+
+	log.Printf("Updated profile for user ID: %s", user.ID)
+}
+
+// UploadAvatar handles the HTTP POST request to replace a user's avatar.
+// It expects a multipart form with a "userID" field and the image in an "avatar" file field.
+// The content type is sniffed from the file rather than trusted from the client.
+func (h *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	// Leave room for the multipart framing and the userID field
+	r.Body = http.MaxBytesReader(w, r.Body, uploads.MaxAvatarSize+64<<10)
+
+	file, header, err := r.FormFile("avatar")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, fmt.Sprintf("Avatar must be at most %d bytes", uploads.MaxAvatarSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading avatar form for UploadAvatar: %v", err)
+		return
+	}
+	defer file.Close()
+
+	userID := r.FormValue("userID")
+	if userID == "" {
+		http.Error(w, "User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for UploadAvatar")
+		return
+	}
+	if header.Size <= 0 || header.Size > uploads.MaxAvatarSize {
+		http.Error(w, fmt.Sprintf("Avatar must be between 1 and %d bytes", uploads.MaxAvatarSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error reading avatar for %s: %v", userID, err)
+		return
+	}
+	contentType := http.DetectContentType(sniff[:n])
+	if !uploads.IsAvatarType(contentType) {
+		http.Error(w, "Avatar must be a JPEG, PNG, GIF, or WebP image", http.StatusUnsupportedMediaType)
+		return
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error rewinding avatar for %s: %v", userID, err)
+		return
+	}
+
+	_, err = h.Store.GetUser(r.Context(), userID)
+	if !checkUser(w, err, userID) {
+		return
+	}
+
+	key := uploads.NewKey("avatars", header.Filename)
+	if err = h.Avatars.Put(r.Context(), key, contentType, file, header.Size); err != nil {
+		http.Error(w, "Failed to store avatar", http.StatusInternalServerError)
+		log.Printf("Error storing avatar for %s: %v", userID, err)
+		return
+	}
+
+	user, oldKey, err := h.Store.SetAvatar(r.Context(), userID, key)
+	if !checkUser(w, err, userID) {
+		h.deleteAvatar(r, key)
+		return
+	}
+	if oldKey != "" {
+		h.deleteAvatar(r, oldKey)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+
+	log.Printf("Updated avatar for user ID: %s (%s, %d bytes)", user.ID, contentType, header.Size)
+}
+
+// deleteAvatar removes a stored avatar that is no longer referenced. Failures
+// only leave an orphaned file behind, so they are logged rather than returned.
+func (h *UserHandler) deleteAvatar(r *http.Request, key string) {
+	if err := h.Avatars.Delete(r.Context(), key); err != nil {
+		log.Printf("Error deleting avatar %s: %v", key, err)
+	}
+}
+
+// GetAvatar handles the HTTP GET request for a user's avatar by redirecting
+// to where it is stored. It expects the user ID as a query parameter "user_id".
+func (h *UserHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetAvatar")
+		return
+	}
+
+	user, err := h.Store.GetUser(r.Context(), userID)
+	if !checkUser(w, err, userID) {
+		return
+	}
+	if user.AvatarKey == "" {
+		http.Error(w, "User has no avatar", http.StatusNotFound)
+		return
+	}
+
+	http.Redirect(w, r, h.Avatars.URL(user.AvatarKey), http.StatusFound)
+}
+
+// GetPresence handles the HTTP GET request for the presence of several users.
+// It expects a comma-separated list of user IDs as the query parameter "ids".
+func (h *UserHandler) GetPresence(w http.ResponseWriter, r *http.Request) {
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		http.Error(w, "User IDs are required as a query parameter (e.g., ?ids=a,b)", http.StatusBadRequest)
+		log.Println("Validation error: ids is empty for GetPresence")
+		return
+	}
+	if len(ids) > maxPresenceIDs {
+		http.Error(w, "Too many user IDs requested", http.StatusBadRequest)
+		return
+	}
+
+	lastSeen, err := h.Store.GetLastSeen(r.Context(), ids)
+	if err != nil {
+		http.Error(w, "Failed to get presence", http.StatusInternalServerError)
+		log.Printf("Error getting last seen for presence lookup: %v", err)
+		return
+	}
+
+	presence := make([]ws.Presence, 0, len(ids))
+	for _, id := range ids {
+		presence = append(presence, ws.Presence{
+			UserID:     id,
+			Status:     h.Hub.GetStatus(id),
+			LastSeenAt: lastSeen[id],
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(presence)
+}
+
+// SetPresence handles the HTTP POST request for a connected user to switch
+// between "online" and "away". It expects a JSON payload with "userID" and "status".
+func (h *UserHandler) SetPresence(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID string            `json:"userID"`
+		Status ws.PresenceStatus `json:"status"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SetPresence: %v", err)
+		return
+	}
+
+	if req.UserID == "" || (req.Status != ws.StatusOnline && req.Status != ws.StatusAway) {
+		http.Error(w, "User ID is required and status must be online or away", http.StatusBadRequest)
+		return
+	}
+
+	if !h.Hub.SetStatus(req.UserID, req.Status) {
+		http.Error(w, "User has no open connections", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ws.Presence{UserID: req.UserID, Status: req.Status, LastSeenAt: time.Now()})
+}
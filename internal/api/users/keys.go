@@ -0,0 +1,123 @@
+package users
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// Limits on registered public keys.
+const (
+	maxPublicKeySize   = 1 << 10 // Decoded bytes
+	maxDeviceIDLength  = 64
+	maxAlgorithmLength = 32
+)
+
+// GetPublicKeys handles the HTTP GET request for the public keys of every
+// device of a user, which others wrap conversation keys with. It expects the
+// user ID as a query parameter "user_id".
+func (h *UserHandler) GetPublicKeys(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetPublicKeys")
+		return
+	}
+
+	keys, err := h.Store.GetPublicKeys(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get public keys", http.StatusInternalServerError)
+		log.Printf("Error getting public keys of user %s: %v", userID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
+
+// RegisterPublicKey handles the HTTP PUT request to register the public key
+// of one of a user's devices, replacing any key the device had. It expects a
+// JSON payload with "userID", "deviceID", "algorithm", and "publicKey" in
+// base64. Only the public half is ever sent to the server.
+func (h *UserHandler) RegisterPublicKey(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID    string `json:"userID"`
+		DeviceID  string `json:"deviceID"`
+		Algorithm string `json:"algorithm"`
+		PublicKey string `json:"publicKey"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for RegisterPublicKey: %v", err)
+		return
+	}
+
+	if req.UserID == "" || req.DeviceID == "" || req.Algorithm == "" {
+		http.Error(w, "User ID, Device ID, and Algorithm cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: missing field for RegisterPublicKey")
+		return
+	}
+	if len(req.DeviceID) > maxDeviceIDLength || len(req.Algorithm) > maxAlgorithmLength {
+		http.Error(w, fmt.Sprintf("Device ID must be at most %d and Algorithm at most %d characters", maxDeviceIDLength, maxAlgorithmLength), http.StatusBadRequest)
+		return
+	}
+	decoded, err := base64.StdEncoding.DecodeString(req.PublicKey)
+	if err != nil || len(decoded) == 0 || len(decoded) > maxPublicKeySize {
+		http.Error(w, fmt.Sprintf("Public key must be base64 of at most %d bytes", maxPublicKeySize), http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.Store.SavePublicKey(r.Context(), models.PublicKey{
+		UserID:    req.UserID,
+		DeviceID:  req.DeviceID,
+		Algorithm: req.Algorithm,
+		PublicKey: req.PublicKey,
+	})
+	if err != nil {
+		http.Error(w, "Failed to register public key", http.StatusInternalServerError)
+		log.Printf("Error registering public key of user %s device %s: %v", req.UserID, req.DeviceID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(key)
+	log.Printf("Registered %s public key for user %s device %s", key.Algorithm, key.UserID, key.DeviceID)
+}
+
+// DeletePublicKey handles the HTTP DELETE request to remove the public key
+// of a device the user no longer uses. It expects the query parameters
+// "user_id" and "device_id".
+func (h *UserHandler) DeletePublicKey(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	deviceID := r.URL.Query().Get("device_id")
+
+	if userID == "" || deviceID == "" {
+		http.Error(w, "User ID and Device ID are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: User ID or Device ID is empty for DeletePublicKey")
+		return
+	}
+
+	err := h.Store.DeletePublicKey(r.Context(), userID, deviceID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Device has no public key", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete public key", http.StatusInternalServerError)
+		log.Printf("Error deleting public key of user %s device %s: %v", userID, deviceID, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// overrideRequest is the payload of SetNotificationOverride. Exactly one of
+// DMID and SceneID is set.
+type overrideRequest struct {
+	UserID  string                   `json:"userID"`
+	DMID    string                   `json:"dmID,omitempty"`
+	SceneID string                   `json:"sceneID,omitempty"`
+	Level   models.NotificationLevel `json:"level"`
+}
+
+// GetNotificationOverrides handles the HTTP GET request for a user's
+// per-conversation notification overrides. It expects the user ID as a query
+// parameter "user_id".
+func (h *UserHandler) GetNotificationOverrides(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetNotificationOverrides")
+		return
+	}
+
+	overrides, err := h.Notifications.GetNotificationOverrides(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get notification overrides", http.StatusInternalServerError)
+		log.Printf("Error getting notification overrides of user %s: %v", userID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"overrides": overrides})
+}
+
+// SetNotificationOverride handles the HTTP PUT request to set a user's
+// notification level for one DM conversation or scene, overriding their
+// channel settings. It expects a JSON payload with "userID", either "dmID" or
+// "sceneID", and "level" (all, mentions, or none).
+func (h *UserHandler) SetNotificationOverride(w http.ResponseWriter, r *http.Request) {
+	var req overrideRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SetNotificationOverride: %v", err)
+		return
+	}
+
+	if req.UserID == "" || (req.DMID == "") == (req.SceneID == "") {
+		http.Error(w, "User ID and exactly one of DM ID and Scene ID are required", http.StatusBadRequest)
+		log.Println("Validation error: missing user or conversation for SetNotificationOverride")
+		return
+	}
+	if !req.Level.IsValid() {
+		http.Error(w, "Level must be all, mentions, or none", http.StatusBadRequest)
+		return
+	}
+
+	override, err := h.Notifications.SetNotificationOverride(r.Context(), req.UserID, req.DMID, req.SceneID, req.Level)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Scene not found or user is not in the conversation", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to set notification override", http.StatusInternalServerError)
+		log.Printf("Error setting notification override of user %s: %v", req.UserID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(override)
+	log.Printf("User %s set notifications to %q for DM %q / scene %q", req.UserID, req.Level, req.DMID, req.SceneID)
+}
+
+// DeleteNotificationOverride handles the HTTP DELETE request to drop a
+// user's override for a conversation, returning it to their channel
+// settings. It expects "user_id" and either "dm_id" or "scene_id" as query
+// parameters.
+func (h *UserHandler) DeleteNotificationOverride(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, dmID, sceneID := q.Get("user_id"), q.Get("dm_id"), q.Get("scene_id")
+
+	if userID == "" || (dmID == "") == (sceneID == "") {
+		http.Error(w, "user_id and exactly one of dm_id and scene_id are required as query parameters", http.StatusBadRequest)
+		log.Println("Validation error: missing user or conversation for DeleteNotificationOverride")
+		return
+	}
+
+	err := h.Notifications.DeleteNotificationOverride(r.Context(), userID, dmID, sceneID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Notification override not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete notification override", http.StatusInternalServerError)
+		log.Printf("Error deleting notification override of user %s: %v", userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
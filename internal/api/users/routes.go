@@ -0,0 +1,190 @@
+package users
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterUserRoutes registers all user-related HTTP routes with the provided ServeMux.
+func RegisterUserRoutes(mux *http.ServeMux, handler *UserHandler) {
+	mux.HandleFunc("/api/v1/users/signup", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[User] %s %s", r.Method, r.URL.Path)
+		handler.Signup(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/users/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[User] %s %s", r.Method, r.URL.Path)
+		handler.Login(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/users/ws-token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[User] %s %s", r.Method, r.URL.Path)
+		handler.RenewWSToken(w, r)
+	})
+
+	// GET reads a profile, PUT updates it
+	mux.HandleFunc("/api/v1/users/profile", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.GetProfile(w, r)
+		case http.MethodPut:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.UpdateProfile(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET checks whether a username is available, PUT claims it
+	mux.HandleFunc("/api/v1/users/username", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.CheckUsername(w, r)
+		case http.MethodPut:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.SetUsername(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mux.HandleFunc("/api/v1/users/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[User] %s %s", r.Method, r.URL.Path)
+		handler.SearchUsers(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/users/follow", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[User] %s %s", r.Method, r.URL.Path)
+		handler.Follow(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/users/unfollow", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[User] %s %s", r.Method, r.URL.Path)
+		handler.Unfollow(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/users/following", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[User] %s %s", r.Method, r.URL.Path)
+		handler.GetFollowing(w, r)
+	})
+
+	// GET returns a user's settings, PUT changes them
+	mux.HandleFunc("/api/v1/users/settings", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.GetSettings(w, r)
+		case http.MethodPut:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.UpdateSettings(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET lists a user's notification overrides, PUT sets one, DELETE removes one
+	mux.HandleFunc("/api/v1/users/notifications/overrides", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.GetNotificationOverrides(w, r)
+		case http.MethodPut:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.SetNotificationOverride(w, r)
+		case http.MethodDelete:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.DeleteNotificationOverride(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET lists a user's device public keys, PUT registers one, DELETE removes one
+	mux.HandleFunc("/api/v1/users/keys", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.GetPublicKeys(w, r)
+		case http.MethodPut:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.RegisterPublicKey(w, r)
+		case http.MethodDelete:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.DeletePublicKey(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET redirects to a user's avatar, POST uploads a new one
+	mux.HandleFunc("/api/v1/users/avatar", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.GetAvatar(w, r)
+		case http.MethodPost:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.UploadAvatar(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	// GET reads presence for several users, POST sets online/away
+	mux.HandleFunc("/api/v1/users/presence", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.GetPresence(w, r)
+		case http.MethodPost:
+			log.Printf("[User] %s %s", r.Method, r.URL.Path)
+			handler.SetPresence(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[User] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package users
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// maxSettingsBody caps the size of an UpdateSettings request.
+const maxSettingsBody = 16 << 10
+
+// settingsResponse is the reply of GetSettings and UpdateSettings.
+type settingsResponse struct {
+	UserID    string              `json:"userID"`
+	Settings  models.UserSettings `json:"settings"`
+	UpdatedAt *time.Time          `json:"updatedAt,omitempty"` // Omitted until the user first saves settings
+}
+
+// GetSettings handles the HTTP GET request for a user's settings. It expects
+// the user ID as a query parameter "user_id". Users who never saved settings
+// get the defaults.
+func (h *UserHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for GetSettings")
+		return
+	}
+
+	settings, updatedAt, err := h.Store.GetSettings(r.Context(), userID)
+	if !checkUser(w, err, userID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settingsResponse{UserID: userID, Settings: *settings, UpdatedAt: updatedAt})
+}
+
+// UpdateSettings handles the HTTP PUT request to change a user's settings.
+// It expects a JSON payload with "userID" and "settings". Settings left out
+// of the payload keep their current value; unknown settings are rejected.
+func (h *UserHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSettingsBody)
+
+	var req struct {
+		UserID   string          `json:"userID"`
+		Settings json.RawMessage `json:"settings"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "Request body is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for UpdateSettings: %v", err)
+		return
+	}
+
+	if req.UserID == "" {
+		http.Error(w, "User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for UpdateSettings")
+		return
+	}
+	if len(req.Settings) == 0 || bytes.Equal(req.Settings, []byte("null")) {
+		http.Error(w, "Settings cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	settings, _, err := h.Store.GetSettings(r.Context(), req.UserID)
+	if !checkUser(w, err, req.UserID) {
+		return
+	}
+
+	// Decode over the current settings so the frontend can send only what changed
+	dec := json.NewDecoder(bytes.NewReader(req.Settings))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(settings); err != nil {
+		http.Error(w, "Invalid settings: "+err.Error(), http.StatusBadRequest)
+		log.Printf("Validation error: %v for UpdateSettings", err)
+		return
+	}
+	if err := settings.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Validation error: %v for UpdateSettings", err)
+		return
+	}
+
+	updatedAt, err := h.Store.SaveSettings(r.Context(), req.UserID, *settings)
+	if !checkUser(w, err, req.UserID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settingsResponse{UserID: req.UserID, Settings: *settings, UpdatedAt: &updatedAt})
+	log.Printf("Updated settings for user %s", req.UserID)
+}
@@ -0,0 +1,146 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// Limits on usernames and user search.
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 30
+	maxSearchLimit    = 50
+)
+
+// normalizeUsername lowercases username and checks that it starts with a
+// letter and contains only letters, digits, and underscores.
+func normalizeUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+	if len(username) < minUsernameLength || len(username) > maxUsernameLength {
+		return "", fmt.Errorf("Username must be between %d and %d characters", minUsernameLength, maxUsernameLength)
+	}
+	for i, c := range username {
+		switch {
+		case 'a' <= c && c <= 'z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '_'):
+		default:
+			return "", errors.New("Username must start with a letter and contain only letters, digits, and underscores")
+		}
+	}
+	return username, nil
+}
+
+// SetUsername handles the HTTP PUT request to change a user's username.
+// It expects a JSON payload with "userID" and "username".
+func (h *UserHandler) SetUsername(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID   string `json:"userID"`
+		Username string `json:"username"`
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for SetUsername: %v", err)
+		return
+	}
+
+	if req.UserID == "" {
+		http.Error(w, "User ID cannot be empty", http.StatusBadRequest)
+		log.Println("Validation error: User ID is empty for SetUsername")
+		return
+	}
+	username, err := normalizeUsername(req.Username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		log.Printf("Validation error: %v for SetUsername", err)
+		return
+	}
+
+	user, err := h.Store.SetUsername(r.Context(), req.UserID, username)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "Username is already taken", http.StatusConflict)
+		return
+	}
+	if !checkUser(w, err, req.UserID) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+
+	log.Printf("Set username of user %s to %s", user.ID, user.Username)
+}
+
+// CheckUsername handles the HTTP GET request to check whether a username is
+// free to claim. It expects the username as a query parameter "username".
+func (h *UserHandler) CheckUsername(w http.ResponseWriter, r *http.Request) {
+	username, err := normalizeUsername(r.URL.Query().Get("username"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.Store.GetUserByUsername(r.Context(), username)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking username %s: %v", username, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"username":  username,
+		"available": errors.Is(err, storage.ErrNotFound),
+	})
+}
+
+// SearchUsers handles the HTTP GET request to find users by the start of
+// their username or display name, e.g. to start a DM. It expects the search
+// text as the query parameter "q" and accepts an optional "limit".
+func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	prefix := strings.TrimPrefix(strings.TrimSpace(q.Get("q")), "@")
+
+	if prefix == "" {
+		http.Error(w, "Search text is required as a query parameter (e.g., ?q=alex)", http.StatusBadRequest)
+		log.Println("Validation error: q is empty for SearchUsers")
+		return
+	}
+
+	limit := 20
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	users, err := h.Store.SearchUsers(r.Context(), prefix, limit)
+	if err != nil {
+		http.Error(w, "Failed to search users", http.StatusInternalServerError)
+		log.Printf("Error searching users for %q: %v", prefix, err)
+		return
+	}
+	// Only public fields are returned; emails stay private
+	results := make([]models.UserSummary, 0, len(users))
+	for _, user := range users {
+		results = append(results, user.Summary())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"users": results})
+}
@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// ownerParam is the optional user_id the caller's bearer token must match.
+var ownerParam = openapi.Param{Name: "user_id", Description: "Must match the bearer token if given"}
+
+// Routes describes the routes registered by RegisterWebhookRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/webhooks/create", ID: "createWebhook", Tag: "Webhooks",
+		Summary: "Register an outbound webhook",
+		Description: "The owner is identified by their token from login or /api/v1/users/ws-token in an " +
+			"\"Authorization: Bearer\" header; userID may be omitted, and is rejected with 403 if it names someone else. " +
+			"Scene webhooks can be registered by the scene creator; global webhooks, which also " +
+			"receive DM events, only by admins. The response includes the signing secret, which is not shown again.",
+		Body: struct {
+			UserID  string   `json:"userID,omitempty"`
+			SceneID string   `json:"sceneID,omitempty"`
+			URL     string   `json:"url"`
+			Events  []string `json:"events"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.Webhook{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/webhooks/list", ID: "listWebhooks", Tag: "Webhooks",
+		Summary:  "List the webhooks the caller registered",
+		Query:    []openapi.Param{ownerParam},
+		Response: []models.Webhook{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/webhooks/delete", ID: "deleteWebhook", Tag: "Webhooks",
+		Summary: "Remove a webhook",
+		Body: struct {
+			UserID    string `json:"userID,omitempty"`
+			WebhookID string `json:"webhookID"`
+		}{},
+		Status: http.StatusNoContent,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/webhooks/deliveries", ID: "listWebhookDeliveries", Tag: "Webhooks",
+		Summary: "List a webhook's recent deliveries",
+		Query: []openapi.Param{
+			ownerParam,
+			{Name: "webhook_id", Required: true},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: []models.WebhookDelivery{},
+	},
+}
@@ -0,0 +1,272 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/webhooks"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxWebhooksPerUser caps how many webhooks one user can register.
+const maxWebhooksPerUser = 20
+
+// WebhookHandler holds the dependencies for managing outbound webhooks.
+type WebhookHandler struct {
+	Store  storage.WebhookStore // Persists webhooks and their deliveries
+	Scenes storage.SceneStore   // Checks scene ownership for per-scene webhooks
+	Tokens *ws.TokenSigner      // Verifies the token identifying the caller
+	Admins map[string]bool      // User IDs allowed to register global webhooks
+}
+
+// authenticate returns the user identified by the token from login or
+// /api/v1/users/ws-token in the request's "Authorization: Bearer" header.
+// claimed, the user ID named in the request, may be empty but otherwise must
+// match. It returns false if a response has already been written.
+func (h *WebhookHandler) authenticate(w http.ResponseWriter, r *http.Request, claimed string) (string, bool) {
+	userID, err := h.Tokens.AuthenticateBearer(r)
+	if err != nil {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		log.Printf("Rejected %s %s: %v", r.Method, r.URL.Path, err)
+		return "", false
+	}
+	if claimed != "" && claimed != userID {
+		http.Error(w, "Token was issued to another user", http.StatusForbidden)
+		log.Printf("User %s attempted %s %s as %s", userID, r.Method, r.URL.Path, claimed)
+		return "", false
+	}
+	return userID, true
+}
+
+// checkWebhook loads a webhook and checks that userID owns it. It returns
+// nil if a response has already been written.
+func (h *WebhookHandler) checkWebhook(w http.ResponseWriter, r *http.Request, webhookID, userID string) *models.Webhook {
+	webhook, err := h.Store.GetWebhook(r.Context(), webhookID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error accessing webhook %s: %v", webhookID, err)
+		return nil
+	}
+	if webhook.OwnerID != userID {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		log.Printf("User %s attempted to access webhook %s", userID, webhookID)
+		return nil
+	}
+	return webhook
+}
+
+// CreateWebhook handles the HTTP POST request to register a webhook. The
+// owner is identified by their bearer token, see authenticate. It expects a
+// JSON payload with "url", "events", and optionally "sceneID" and "userID",
+// which must match the token. Scene webhooks can be registered by the scene creator; global
+// webhooks, which also receive DM events, only by admins. The response
+// includes the signing secret, which is not shown again.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID  string   `json:"userID"`
+		SceneID string   `json:"sceneID"`
+		URL     string   `json:"url"`
+		Events  []string `json:"events"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for CreateWebhook: %v", err)
+		return
+	}
+
+	userID, ok := h.authenticate(w, r, req.UserID)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	if req.URL == "" || len(req.Events) == 0 {
+		http.Error(w, "URL and events cannot be empty", http.StatusBadRequest)
+		return
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		http.Error(w, "URL must be an absolute http or https URL", http.StatusBadRequest)
+		return
+	}
+	var events []string
+	for _, event := range req.Events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			http.Error(w, "Unknown event "+strconv.Quote(event), http.StatusBadRequest)
+			return
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	webhook := &models.Webhook{OwnerID: req.UserID, URL: req.URL, Events: events}
+	if req.SceneID != "" {
+		scene, err := h.Scenes.GetScene(r.Context(), req.SceneID)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "Scene not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("Error accessing scene %s for CreateWebhook: %v", req.SceneID, err)
+			return
+		}
+		if scene.CreatorID != req.UserID {
+			http.Error(w, "Only the scene creator can add webhooks to the scene", http.StatusForbidden)
+			log.Printf("User %s attempted to add a webhook to scene %s", req.UserID, req.SceneID)
+			return
+		}
+		webhook.SceneID = &scene.ID
+	} else if !h.Admins[req.UserID] {
+		http.Error(w, "Only admins can register webhooks for every event; set sceneID", http.StatusForbidden)
+		log.Printf("Non-admin %s attempted to register a global webhook", req.UserID)
+		return
+	}
+
+	existing, err := h.Store.ListWebhooks(r.Context(), req.UserID)
+	if err != nil {
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		log.Printf("Error counting webhooks of %s: %v", req.UserID, err)
+		return
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		http.Error(w, "Webhook limit reached", http.StatusConflict)
+		return
+	}
+
+	if webhook.Secret, err = webhooks.NewSecret(); err != nil {
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		log.Printf("Error generating webhook secret: %v", err)
+		return
+	}
+	webhook, err = h.Store.CreateWebhook(r.Context(), webhook)
+	if err != nil {
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		log.Printf("Error creating webhook for %s: %v", req.UserID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+	log.Printf("Webhook %s registered by %s for %v", webhook.ID, req.UserID, events)
+}
+
+// ListWebhooks handles the HTTP GET request for the webhooks the caller,
+// identified by their bearer token, registered. An optional "user_id" query
+// parameter must match the token.
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.authenticate(w, r, r.URL.Query().Get("user_id"))
+	if !ok {
+		return
+	}
+
+	list, err := h.Store.ListWebhooks(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to list webhooks", http.StatusInternalServerError)
+		log.Printf("Error listing webhooks of %s: %v", userID, err)
+		return
+	}
+	if list == nil {
+		list = []models.Webhook{} // Return an empty slice instead of nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+// DeleteWebhook handles the HTTP POST request to remove a webhook. The owner
+// is identified by their bearer token. It expects a JSON payload with
+// "webhookID" and optionally "userID", which must match the token.
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		UserID    string `json:"userID"`
+		WebhookID string `json:"webhookID"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for DeleteWebhook: %v", err)
+		return
+	}
+	userID, ok := h.authenticate(w, r, req.UserID)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+	if req.WebhookID == "" {
+		http.Error(w, "Webhook ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	if h.checkWebhook(w, r, req.WebhookID, req.UserID) == nil {
+		return
+	}
+	err := h.Store.DeleteWebhook(r.Context(), req.WebhookID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+		log.Printf("Error deleting webhook %s: %v", req.WebhookID, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Webhook %s deleted by %s", req.WebhookID, req.UserID)
+}
+
+// ListDeliveries handles the HTTP GET request for a webhook's recent
+// deliveries. The owner is identified by their bearer token. It expects the
+// "webhook_id" and optional "limit" and "user_id", which must match the
+// token, query parameters.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	userID, ok := h.authenticate(w, r, q.Get("user_id"))
+	if !ok {
+		return
+	}
+	webhookID := q.Get("webhook_id")
+	if webhookID == "" {
+		http.Error(w, "webhook_id is a required query parameter", http.StatusBadRequest)
+		return
+	}
+
+	limit := storage.DefaultPageLimit
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			http.Error(w, "Limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, storage.MaxPageLimit)
+	}
+
+	if h.checkWebhook(w, r, webhookID, userID) == nil {
+		return
+	}
+	deliveries, err := h.Store.ListDeliveries(r.Context(), webhookID, limit)
+	if err != nil {
+		http.Error(w, "Failed to list deliveries", http.StatusInternalServerError)
+		log.Printf("Error listing deliveries of webhook %s: %v", webhookID, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{} // Return an empty slice instead of nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
@@ -0,0 +1,49 @@
+package webhooks
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterWebhookRoutes registers the outbound webhook management routes with the provided ServeMux.
+func RegisterWebhookRoutes(mux *http.ServeMux, handler *WebhookHandler) {
+	mux.HandleFunc("/api/v1/webhooks/create", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Webhook] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Webhook] %s %s", r.Method, r.URL.Path)
+		handler.CreateWebhook(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/webhooks/list", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Webhook] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Webhook] %s %s", r.Method, r.URL.Path)
+		handler.ListWebhooks(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/webhooks/delete", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Webhook] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Webhook] %s %s", r.Method, r.URL.Path)
+		handler.DeleteWebhook(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/webhooks/deliveries", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Webhook] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Webhook] %s %s", r.Method, r.URL.Path)
+		handler.ListDeliveries(w, r)
+	})
+}
@@ -0,0 +1,65 @@
+package workspaces
+
+import (
+	"net/http"
+
+	"github.com/Vasu1712/scenyx-backend/internal/api/openapi"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// Routes describes the routes registered by RegisterWorkspaceRoutes for the OpenAPI document.
+var Routes = []openapi.Route{
+	{
+		Method: http.MethodPost, Path: "/api/v1/workspaces/create", ID: "createWorkspace", Tag: "Workspaces",
+		Summary: "Create a workspace",
+		Description: "A workspace is a private community: scenes and DMs created in it are visible only to its " +
+			"members. The owner becomes its first member.",
+		Body: struct {
+			Name    string `json:"name"`
+			OwnerID string `json:"ownerID"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.Workspace{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/workspaces/list", ID: "listWorkspaces", Tag: "Workspaces",
+		Summary:  "List the workspaces a user is a member of",
+		Query:    []openapi.Param{{Name: "user_id", Required: true}},
+		Response: []models.Workspace{},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/workspaces/members", ID: "listWorkspaceMembers", Tag: "Workspaces",
+		Summary: "List a workspace's members",
+		Query: []openapi.Param{
+			{Name: "workspace_id", Required: true},
+			{Name: "user_id", Required: true, Description: "A member of the workspace"},
+		},
+		Response: []models.WorkspaceMembership{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/workspaces/members/add", ID: "addWorkspaceMember", Tag: "Workspaces",
+		Summary:     "Add a user to a workspace",
+		Description: "userID must be the owner or an admin; only the owner can add admins.",
+		Body: struct {
+			WorkspaceID string               `json:"workspaceID"`
+			UserID      string               `json:"userID"`
+			MemberID    string               `json:"memberID"`
+			Role        models.WorkspaceRole `json:"role,omitempty"`
+		}{},
+		Status:   http.StatusCreated,
+		Response: models.WorkspaceMembership{},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/workspaces/members/remove", ID: "removeWorkspaceMember", Tag: "Workspaces",
+		Summary: "Remove a user from a workspace",
+		Description: "Members can remove themselves; the owner and admins can remove members, and only the owner " +
+			"can remove admins. The user also leaves the workspace's scenes and group conversations, and their " +
+			"connections to the workspace's scenes and conversations are closed.",
+		Body: struct {
+			WorkspaceID string `json:"workspaceID"`
+			UserID      string `json:"userID"`
+			MemberID    string `json:"memberID"`
+		}{},
+		Status: http.StatusNoContent,
+	},
+}
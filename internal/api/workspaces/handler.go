@@ -0,0 +1,263 @@
+package workspaces
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxWorkspaceNameLength bounds workspace names, in characters.
+const maxWorkspaceNameLength = 100
+
+// WorkspaceHandler holds the dependencies for managing workspaces.
+type WorkspaceHandler struct {
+	Store storage.WorkspaceStore // Persists workspaces and their members
+	Hub   *ws.Hub                // Closes the connections of removed members
+}
+
+// checkMember loads userID's role in a workspace. It returns an empty role if
+// a response has already been written; non-members are told the workspace
+// does not exist.
+func (h *WorkspaceHandler) checkMember(w http.ResponseWriter, r *http.Request, workspaceID, userID string) models.WorkspaceRole {
+	role, err := h.Store.GetMemberRole(r.Context(), workspaceID, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Workspace not found", http.StatusNotFound)
+		return ""
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("Error checking membership of %s in workspace %s: %v", userID, workspaceID, err)
+		return ""
+	}
+	return role
+}
+
+// CreateWorkspace handles the HTTP POST request to create a workspace.
+// It expects a JSON payload with "name" and "ownerID"; the owner becomes
+// its first member.
+func (h *WorkspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name    string `json:"name"`
+		OwnerID string `json:"ownerID"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for CreateWorkspace: %v", err)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.OwnerID == "" {
+		http.Error(w, "Name and Owner ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if len([]rune(req.Name)) > maxWorkspaceNameLength {
+		http.Error(w, "Name is too long", http.StatusBadRequest)
+		return
+	}
+
+	workspace, err := h.Store.CreateWorkspace(r.Context(), req.Name, req.OwnerID)
+	if err != nil {
+		http.Error(w, "Failed to create workspace", http.StatusInternalServerError)
+		log.Printf("Error creating workspace %q for %s: %v", req.Name, req.OwnerID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(workspace)
+	log.Printf("Workspace %s created by %s", workspace.ID, req.OwnerID)
+}
+
+// ListWorkspaces handles the HTTP GET request for the workspaces a user is a member of.
+// It expects the "user_id" query parameter.
+func (h *WorkspaceHandler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		http.Error(w, "User ID is required as a query parameter (e.g., ?user_id=some_id)", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.Store.GetWorkspaces(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to list workspaces", http.StatusInternalServerError)
+		log.Printf("Error listing workspaces of %s: %v", userID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+// ListMembers handles the HTTP GET request for a workspace's members.
+// It expects the "workspace_id" and "user_id" query parameters; the user
+// must be a member.
+func (h *WorkspaceHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	workspaceID, userID := q.Get("workspace_id"), q.Get("user_id")
+	if workspaceID == "" || userID == "" {
+		http.Error(w, "workspace_id and user_id are required query parameters", http.StatusBadRequest)
+		return
+	}
+
+	if h.checkMember(w, r, workspaceID, userID) == "" {
+		return
+	}
+	members, err := h.Store.GetMembers(r.Context(), workspaceID)
+	if err != nil {
+		http.Error(w, "Failed to list members", http.StatusInternalServerError)
+		log.Printf("Error listing members of workspace %s: %v", workspaceID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(members)
+}
+
+// AddMember handles the HTTP POST request to add a user to a workspace.
+// It expects a JSON payload with "workspaceID", "userID" (the owner or an
+// admin adding them), "memberID", and an optional "role", "member" by
+// default. Only the owner can add admins.
+func (h *WorkspaceHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		WorkspaceID string               `json:"workspaceID"`
+		UserID      string               `json:"userID"`
+		MemberID    string               `json:"memberID"`
+		Role        models.WorkspaceRole `json:"role"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for AddMember: %v", err)
+		return
+	}
+	if req.WorkspaceID == "" || req.UserID == "" || req.MemberID == "" {
+		http.Error(w, "Workspace ID, User ID, and Member ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.WorkspaceMember
+	}
+	if !req.Role.Valid() || req.Role == models.WorkspaceOwner {
+		http.Error(w, "Role must be admin or member", http.StatusBadRequest)
+		return
+	}
+
+	role := h.checkMember(w, r, req.WorkspaceID, req.UserID)
+	if role == "" {
+		return
+	}
+	if !role.CanManage() || (req.Role == models.WorkspaceAdmin && role != models.WorkspaceOwner) {
+		http.Error(w, "Not allowed to add members with this role", http.StatusForbidden)
+		log.Printf("User %s attempted to add %s to workspace %s as %s", req.UserID, req.MemberID, req.WorkspaceID, req.Role)
+		return
+	}
+
+	member, err := h.Store.AddMember(r.Context(), req.WorkspaceID, req.MemberID, req.Role)
+	if errors.Is(err, storage.ErrConflict) {
+		http.Error(w, "User is already a member", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Workspace not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to add member", http.StatusInternalServerError)
+		log.Printf("Error adding %s to workspace %s: %v", req.MemberID, req.WorkspaceID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+	log.Printf("User %s added to workspace %s as %s by %s", req.MemberID, req.WorkspaceID, req.Role, req.UserID)
+}
+
+// RemoveMember handles the HTTP POST request to remove a user from a
+// workspace, and from its scenes and group conversations, closing their
+// connections to the workspace's scenes and conversations. It expects a JSON
+// payload with "workspaceID", "userID", and "memberID". Members can remove
+// themselves; the owner and admins can remove members, and only the owner
+// can remove admins. The owner cannot be removed.
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		WorkspaceID string `json:"workspaceID"`
+		UserID      string `json:"userID"`
+		MemberID    string `json:"memberID"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		log.Printf("Error decoding request body for RemoveMember: %v", err)
+		return
+	}
+	if req.WorkspaceID == "" || req.UserID == "" || req.MemberID == "" {
+		http.Error(w, "Workspace ID, User ID, and Member ID cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	role := h.checkMember(w, r, req.WorkspaceID, req.UserID)
+	if role == "" {
+		return
+	}
+	if req.MemberID != req.UserID {
+		memberRole, err := h.Store.GetMemberRole(r.Context(), req.WorkspaceID, req.MemberID)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "User is not a member", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("Error checking membership of %s in workspace %s: %v", req.MemberID, req.WorkspaceID, err)
+			return
+		}
+		if !role.CanManage() || (memberRole.CanManage() && role != models.WorkspaceOwner) {
+			http.Error(w, "Not allowed to remove this member", http.StatusForbidden)
+			log.Printf("User %s attempted to remove %s from workspace %s", req.UserID, req.MemberID, req.WorkspaceID)
+			return
+		}
+	}
+
+	rooms, err := h.Store.RemoveMember(r.Context(), req.WorkspaceID, req.MemberID)
+	if errors.Is(err, storage.ErrForbidden) {
+		http.Error(w, "The owner cannot be removed", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User is not a member", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to remove member", http.StatusInternalServerError)
+		log.Printf("Error removing %s from workspace %s: %v", req.MemberID, req.WorkspaceID, err)
+		return
+	}
+	h.disconnect(req.MemberID, rooms)
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("User %s removed from workspace %s by %s", req.MemberID, req.WorkspaceID, req.UserID)
+}
+
+// disconnect closes userID's connections to rooms on this instance.
+func (h *WorkspaceHandler) disconnect(userID string, rooms *models.WorkspaceRooms) {
+	scenes := make(map[string]bool, len(rooms.SceneIDs))
+	for _, id := range rooms.SceneIDs {
+		scenes[id] = true
+	}
+	dms := make(map[string]bool, len(rooms.DMIDs))
+	for _, id := range rooms.DMIDs {
+		dms[id] = true
+	}
+	h.Hub.DisconnectUser(userID, "removed from workspace", func(c *ws.Client) bool {
+		return (c.SceneID != "" && scenes[c.SceneID]) || (c.DMID != "" && dms[c.DMID])
+	})
+}
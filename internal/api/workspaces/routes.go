@@ -0,0 +1,59 @@
+package workspaces
+
+import (
+	"log"
+	"net/http"
+)
+
+// RegisterWorkspaceRoutes registers the workspace routes with the provided ServeMux.
+func RegisterWorkspaceRoutes(mux *http.ServeMux, handler *WorkspaceHandler) {
+	mux.HandleFunc("/api/v1/workspaces/create", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Workspace] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Workspace] %s %s", r.Method, r.URL.Path)
+		handler.CreateWorkspace(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/workspaces/list", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Workspace] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Workspace] %s %s", r.Method, r.URL.Path)
+		handler.ListWorkspaces(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/workspaces/members", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Workspace] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Workspace] %s %s", r.Method, r.URL.Path)
+		handler.ListMembers(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/workspaces/members/add", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Workspace] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Workspace] %s %s", r.Method, r.URL.Path)
+		handler.AddMember(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/workspaces/members/remove", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			log.Printf("[Workspace] Method Not Allowed: %s %s", r.Method, r.URL.Path)
+			return
+		}
+		log.Printf("[Workspace] %s %s", r.Method, r.URL.Path)
+		handler.RemoveMember(w, r)
+	})
+}
@@ -0,0 +1,164 @@
+// Package achievements awards badges to users when the events they take
+// part in satisfy a badge's rule.
+package achievements
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// Events the rules are evaluated on.
+const (
+	EventSceneCreated   = models.EventSceneCreated // A user created a scene
+	EventListenerJoined = "listener.joined"        // A user opened their first connection to a scene
+)
+
+// Thresholds of the default rules.
+const (
+	crowdListeners = 100 // Different listeners a scene needs for BadgeHundredCrowd
+	streakDays     = 7   // Consecutive listening days for BadgeWeeklyStreak
+)
+
+// evaluateTimeout bounds the database work done for a single event.
+const evaluateTimeout = 10 * time.Second
+
+// Event is something a user did that may earn a badge.
+type Event struct {
+	Name    string    // EventSceneCreated or EventListenerJoined
+	UserID  string    // User who acted
+	SceneID string    // Scene acted in
+	At      time.Time // When it happened
+}
+
+// Rule awards Badge when one of the On events satisfies Earned.
+type Rule struct {
+	Badge models.Badge
+	On    []string
+	// Recipient returns the user who earns the badge for ev; nil means the
+	// user who acted.
+	Recipient func(ctx context.Context, e *Engine, ev Event) (string, error)
+	// Earned reports whether ev earns the recipient the badge.
+	Earned func(ctx context.Context, e *Engine, ev Event) (bool, error)
+}
+
+// DefaultRules are the rules of the badges in models.Badges.
+var DefaultRules = []Rule{
+	{
+		Badge: models.BadgeFirstScene,
+		On:    []string{EventSceneCreated},
+		Earned: func(ctx context.Context, e *Engine, ev Event) (bool, error) {
+			return true, nil
+		},
+	},
+	{
+		Badge:     models.BadgeHundredCrowd,
+		On:        []string{EventListenerJoined},
+		Recipient: sceneCreator,
+		Earned: func(ctx context.Context, e *Engine, ev Event) (bool, error) {
+			listeners, err := e.Store.CountSceneListeners(ctx, ev.SceneID)
+			return listeners >= crowdListeners, err
+		},
+	},
+	{
+		Badge: models.BadgeWeeklyStreak,
+		On:    []string{EventListenerJoined},
+		Earned: func(ctx context.Context, e *Engine, ev Event) (bool, error) {
+			days, err := e.Store.ListeningStreak(ctx, ev.UserID, ev.At)
+			return days >= streakDays, err
+		},
+	},
+}
+
+// sceneCreator returns the creator of the event's scene.
+func sceneCreator(ctx context.Context, e *Engine, ev Event) (string, error) {
+	scene, err := e.Scenes.GetScene(ctx, ev.SceneID)
+	if err != nil {
+		return "", err
+	}
+	return scene.CreatorID, nil
+}
+
+// Engine evaluates the rules on each event and awards the badges earned.
+type Engine struct {
+	Store  storage.BadgeStore // Awarded badges and the facts rules check
+	Scenes storage.SceneStore // Looks up scene creators
+	Hub    *ws.Hub            // Sends badge.awarded to the recipient
+	Rules  []Rule             // nil uses DefaultRules
+}
+
+// Handle evaluates the rules on ev. It does not block the caller; failures
+// are logged. Handle on a nil Engine does nothing.
+func (e *Engine) Handle(ev Event) {
+	if e == nil {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	go e.evaluate(ev)
+}
+
+// HandleListenerChange evaluates the rules on listener joins. It is meant to
+// be called from the ws.Hub.OnListenerChange callback after the join is
+// recorded for analytics, since the streak rule reads listening sessions.
+func (e *Engine) HandleListenerChange(le ws.ListenerEvent) {
+	if le.Joined {
+		e.Handle(Event{Name: EventListenerJoined, UserID: le.UserID, SceneID: le.SceneID, At: le.At})
+	}
+}
+
+// evaluate awards the badges ev earns.
+func (e *Engine) evaluate(ev Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), evaluateTimeout)
+	defer cancel()
+
+	rules := e.Rules
+	if rules == nil {
+		rules = DefaultRules
+	}
+	for _, rule := range rules {
+		if !slices.Contains(rule.On, ev.Name) {
+			continue
+		}
+		if err := e.apply(ctx, rule, ev); err != nil {
+			log.Printf("Error evaluating badge %s for %s in scene %s: %v", rule.Badge, ev.Name, ev.SceneID, err)
+		}
+	}
+}
+
+// apply awards rule's badge if ev earns it and the recipient lacks it.
+func (e *Engine) apply(ctx context.Context, rule Rule, ev Event) error {
+	userID := ev.UserID
+	if rule.Recipient != nil {
+		var err error
+		if userID, err = rule.Recipient(ctx, e, ev); err != nil {
+			return err
+		}
+	}
+	// Most events come from users who already have the badge; skip its rule
+	has, err := e.Store.HasBadge(ctx, userID, rule.Badge)
+	if err != nil || has {
+		return err
+	}
+	earned, err := rule.Earned(ctx, e, ev)
+	if err != nil || !earned {
+		return err
+	}
+
+	awarded, err := e.Store.AwardBadge(ctx, userID, rule.Badge, ev.SceneID)
+	if err != nil || !awarded {
+		return err
+	}
+	badge := models.UserBadge{UserID: userID, SceneID: ev.SceneID, AwardedAt: time.Now()}
+	badge.BadgeInfo, _ = rule.Badge.Info()
+	badge.Badge = rule.Badge
+	e.Hub.SendToUser(userID, ws.TypeBadgeAwarded, badge)
+	log.Printf("Awarded badge %s to user %s", rule.Badge, userID)
+	return nil
+}
@@ -0,0 +1,21 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// Sampler records how many listeners each scene has. The counts come from
+// this instance's hub, so it is meant to run on a single instance.
+type Sampler struct {
+	Store storage.AnalyticsStore // Persists the samples
+	Hub   *ws.Hub                // Source of the live connection counts
+}
+
+// Sample records the hub's current scene counts.
+func (s *Sampler) Sample(ctx context.Context) error {
+	return s.Store.RecordConcurrency(ctx, s.Hub.ActiveSceneCounts(), time.Now())
+}
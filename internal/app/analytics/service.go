@@ -0,0 +1,36 @@
+// Package analytics records when users start and stop listening to scenes.
+package analytics
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// eventTimeout bounds the database work done for a single listener event.
+const eventTimeout = 5 * time.Second
+
+// Service persists listening sessions reported by the hub.
+type Service struct {
+	Store storage.AnalyticsStore
+}
+
+// HandleListenerChange opens or closes a listening session for e.
+// It is intended to be registered with ws.Hub.OnListenerChange.
+func (s *Service) HandleListenerChange(e ws.ListenerEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+	defer cancel()
+
+	if e.Joined {
+		if err := s.Store.StartListening(ctx, e.SceneID, e.UserID, e.At); err != nil {
+			log.Printf("Error recording user %s joining scene %s: %v", e.UserID, e.SceneID, err)
+		}
+		return
+	}
+	if err := s.Store.StopListening(ctx, e.SceneID, e.UserID, e.At); err != nil {
+		log.Printf("Error recording user %s leaving scene %s: %v", e.UserID, e.SceneID, err)
+	}
+}
@@ -0,0 +1,47 @@
+// Package expiry deletes disappearing DM messages once their conversation's
+// message TTL has run out, and tells the conversation's clients.
+package expiry
+
+import (
+	"context"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// batchSize is the number of messages deleted per store call.
+const batchSize = 500
+
+// Service purges expired DM messages.
+type Service struct {
+	Store storage.DMStore
+	Hub   *ws.Hub
+}
+
+// Purge deletes the messages past their expiry and broadcasts a
+// ws.TypeMessageExpired event to each conversation they belonged to. It is
+// intended to run as a scheduled job.
+func (s *Service) Purge(ctx context.Context) error {
+	total := 0
+	for {
+		expired, err := s.Store.DeleteExpiredMessages(ctx, batchSize)
+		n := 0
+		for dmID, ids := range expired {
+			s.Hub.SendToDM(dmID, ws.TypeMessageExpired, models.DMExpiredEvent{DMID: dmID, MessageIDs: ids})
+			n += len(ids)
+		}
+		total += n
+		if err != nil {
+			return err
+		}
+		if n < batchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("Deleted %d expired DM messages", total)
+	}
+	return nil
+}
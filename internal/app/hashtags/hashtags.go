@@ -0,0 +1,60 @@
+// Package hashtags finds #hashtags in scene details and chat so scenes can be
+// browsed by topic and trending topics ranked.
+package hashtags
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest hashtag recognized, in characters. It matches the
+// limit on scene tags so both can be looked up the same way.
+const MaxLength = 32
+
+// Parse returns the distinct hashtags in text, lowercased and without the
+// "#", in order of first appearance. A hashtag must start the text or follow
+// a character that cannot be part of one, is made of letters, digits, and
+// underscores, and contains at least one letter, so "#1" is not a hashtag.
+func Parse(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for i := 0; i < len(text); {
+		if text[i] != '#' || (i > 0 && isTagRune(lastRune(text[:i]))) {
+			i++
+			continue
+		}
+		end := i + 1
+		hasLetter := false
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !isTagRune(r) {
+				break
+			}
+			hasLetter = hasLetter || unicode.IsLetter(r)
+			end += size
+		}
+		tag := strings.ToLower(text[i+1 : end])
+		if hasLetter && utf8.RuneCountInString(tag) <= MaxLength && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		i = end
+	}
+	return tags
+}
+
+// Normalize lowercases tag and strips a leading "#", the form hashtags are
+// stored and looked up in.
+func Normalize(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
@@ -0,0 +1,198 @@
+// Package jobs runs recurring background tasks on cron schedules.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// Func is the work a job does on each run.
+type Func func(ctx context.Context) error
+
+// Stats describes a job's schedule and run history on this instance.
+type Stats struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+	Skipped        int64      `json:"skipped"` // Activations skipped because the previous run was still going
+	Running        bool       `json:"running"`
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+	LastDurationMS int64      `json:"lastDurationMS"`
+	LastError      string     `json:"lastError,omitempty"`
+	NextRunAt      time.Time  `json:"nextRunAt"`
+}
+
+type job struct {
+	schedule Schedule
+	timeout  time.Duration
+	fn       Func
+	stats    Stats
+}
+
+// Scheduler runs registered jobs when their schedules come due. When Leader
+// is set, jobs only run while this instance holds leadership, so a job runs
+// on one instance at a time across a deployment.
+type Scheduler struct {
+	Leader storage.Leader // Optional; nil runs jobs on every instance
+
+	mu     sync.Mutex
+	jobs   []*job
+	leader bool // Result of the last leadership check, for logging changes
+}
+
+// Add registers a job. timeout bounds each run; zero means no limit. It must
+// be called before Run.
+func (s *Scheduler) Add(name, spec string, timeout time.Duration, fn Func) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &job{schedule: schedule, timeout: timeout, fn: fn, stats: Stats{Name: name, Schedule: spec}})
+	return nil
+}
+
+// Run starts due jobs until ctx is cancelled. Runs in progress are given the
+// cancelled ctx and are not waited for.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	now := time.Now()
+	for _, j := range s.jobs {
+		j.stats.NextRunAt = j.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for {
+		timer := time.NewTimer(time.Until(s.nextRun()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if s.Leader != nil {
+				s.Leader.Resign()
+			}
+			return
+		case <-timer.C:
+		}
+		s.runDue(ctx)
+	}
+}
+
+// nextRun returns when the earliest job is due, or an hour from now if none is.
+func (s *Scheduler) nextRun() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := time.Now().Add(time.Hour)
+	for _, j := range s.jobs {
+		if !j.stats.NextRunAt.IsZero() && j.stats.NextRunAt.Before(next) {
+			next = j.stats.NextRunAt
+		}
+	}
+	return next
+}
+
+// runDue starts every job whose activation time has passed.
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := time.Now()
+	var due []*job
+	s.mu.Lock()
+	for _, j := range s.jobs {
+		if !j.stats.NextRunAt.IsZero() && !j.stats.NextRunAt.After(now) {
+			due = append(due, j)
+			j.stats.NextRunAt = j.schedule.Next(now)
+		}
+	}
+	s.mu.Unlock()
+	if len(due) == 0 || !s.isLeader(ctx) {
+		return
+	}
+
+	for _, j := range due {
+		s.mu.Lock()
+		if j.stats.Running {
+			j.stats.Skipped++
+			s.mu.Unlock()
+			log.Printf("[Jobs] Skipping %s: previous run still in progress", j.stats.Name)
+			continue
+		}
+		j.stats.Running = true
+		s.mu.Unlock()
+		go s.run(ctx, j)
+	}
+}
+
+// isLeader reports whether this instance should run jobs, logging changes.
+func (s *Scheduler) isLeader(ctx context.Context) bool {
+	if s.Leader == nil {
+		return true
+	}
+	leader, err := s.Leader.IsLeader(ctx)
+	if err != nil {
+		log.Printf("[Jobs] Leader election failed: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if leader != s.leader {
+		if leader {
+			log.Println("[Jobs] This instance is now the job leader")
+		} else {
+			log.Println("[Jobs] This instance is no longer the job leader")
+		}
+		s.leader = leader
+	}
+	return leader
+}
+
+// run executes one job and records the outcome.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := j.fn(ctx)
+	elapsed := time.Since(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.stats.Running = false
+	j.stats.Runs++
+	j.stats.LastRunAt = &start
+	j.stats.LastDurationMS = elapsed.Milliseconds()
+	j.stats.LastError = ""
+	if err != nil {
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+		log.Printf("[Jobs] %s failed after %s: %v", j.stats.Name, elapsed, err)
+	}
+}
+
+// Stats returns a snapshot of every job's stats, sorted by name.
+func (s *Scheduler) Stats() []Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]Stats, len(s.jobs))
+	for i, j := range s.jobs {
+		stats[i] = j.stats
+	}
+	sort.Slice(stats, func(a, b int) bool { return stats[a].Name < stats[b].Name })
+	return stats
+}
+
+// IsLeader reports the result of the most recent leadership check.
+func (s *Scheduler) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Leader == nil || s.leader
+}
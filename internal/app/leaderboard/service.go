@@ -0,0 +1,93 @@
+// Package leaderboard aggregates the weekly and all-time leaderboards on a
+// schedule and serves them from a short-lived in-memory cache, since every
+// client asks for the same few boards and they only change when the job runs.
+package leaderboard
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+const (
+	// Size is how many entries each board keeps.
+	Size = 100
+	// week is the span of the weekly boards.
+	week = 7 * 24 * time.Hour
+	// DefaultTTL is how long a board is served from memory.
+	DefaultTTL = time.Minute
+)
+
+// cacheKey identifies a cached board.
+type cacheKey struct {
+	board  models.LeaderboardBoard
+	period models.LeaderboardPeriod
+}
+
+// cachedBoard is a board and when it stops being served from memory.
+type cachedBoard struct {
+	leaderboard *models.Leaderboard
+	expiresAt   time.Time
+}
+
+// Service rebuilds the stored leaderboards and serves them.
+type Service struct {
+	Store storage.LeaderboardStore
+	TTL   time.Duration // Zero uses DefaultTTL
+
+	mu    sync.Mutex
+	cache map[cacheKey]cachedBoard
+}
+
+// Refresh recomputes every board. It is meant to run as a background job on
+// a single instance; other instances pick the new boards up as their cached
+// copies expire.
+func (s *Service) Refresh(ctx context.Context) error {
+	if _, err := s.Store.RefreshLeaderboards(ctx, time.Now().Add(-week), Size); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	clear(s.cache)
+	s.mu.Unlock()
+	return nil
+}
+
+// Get returns the top limit entries of a board, from memory when it was
+// loaded less than TTL ago.
+func (s *Service) Get(ctx context.Context, board models.LeaderboardBoard, period models.LeaderboardPeriod, limit int) (*models.Leaderboard, error) {
+	key := cacheKey{board, period}
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+
+	if !ok || now.After(cached.expiresAt) {
+		leaderboard, err := s.Store.GetLeaderboard(ctx, board, period)
+		if err != nil {
+			return nil, err
+		}
+		ttl := s.TTL
+		if ttl == 0 {
+			ttl = DefaultTTL
+		}
+		cached = cachedBoard{leaderboard: leaderboard, expiresAt: now.Add(ttl)}
+
+		s.mu.Lock()
+		if s.cache == nil {
+			s.cache = make(map[cacheKey]cachedBoard)
+		}
+		s.cache[key] = cached
+		s.mu.Unlock()
+	}
+
+	// Cached boards are shared; hand out a copy cut to limit
+	top := *cached.leaderboard
+	if limit < len(top.Entries) {
+		top.Entries = top.Entries[:limit]
+	}
+	return &top, nil
+}
@@ -0,0 +1,129 @@
+// Package lifecycle ends scenes that are no longer in use or have reached
+// their maximum duration: it archives them, releases their share links, and
+// tells clients and participants.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/links"
+	"github.com/Vasu1712/scenyx-backend/internal/app/notify"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// closeTimeout bounds the store calls made when the hub reports an empty scene.
+const closeTimeout = 30 * time.Second
+
+// endWarnings are how long before a scene's maximum duration runs out that
+// its clients are sent scene.ending, longest first.
+var endWarnings = []time.Duration{10 * time.Minute, 5 * time.Minute, time.Minute}
+
+// Service closes scenes.
+type Service struct {
+	Scenes storage.SceneStore // Archives scenes and lists their participants
+	Hub    *ws.Hub            // Delivers scene.closed to connected clients
+	Notify *notify.Dispatcher // Tells participants who are not connected that the scene closed
+	Links  *links.Builder     // Forms the scene link included in notifications
+}
+
+// closedNotice is the scene.closed notification sent to participants.
+type closedNotice struct {
+	models.SceneClosed
+	Name string `json:"name"`
+	Link string `json:"link"`
+}
+
+// Close archives a scene, releasing its slug, then sends scene.closed to
+// its connected clients, whose connections are closed once it is written,
+// and notifies its participants. It returns storage.ErrConflict if the scene
+// was already closed.
+func (s *Service) Close(ctx context.Context, sceneID string, reason models.SceneClosedReason) (*models.Scene, error) {
+	scene, err := s.Scenes.CloseScene(ctx, sceneID)
+	if err != nil {
+		return nil, err
+	}
+	closed := models.SceneClosed{SceneID: scene.ID, Reason: reason}
+	s.Hub.CloseScene(scene.ID, closed)
+
+	participants, err := s.Scenes.GetSceneParticipants(ctx, []string{scene.ID})
+	if err != nil {
+		log.Printf("Error loading participants to notify of closed scene %s: %v", scene.ID, err)
+	} else {
+		s.Notify.Notify(ctx, notify.Notification{
+			Channel: models.ChannelSceneLive,
+			SceneID: scene.ID,
+			Type:    ws.TypeSceneClosed,
+			Payload: closedNotice{closed, scene.Name, s.Links.Scene(scene.ID)},
+		}, participants[scene.ID]...)
+	}
+	log.Printf("Closed scene %s: %s", scene.ID, reason)
+	return scene, nil
+}
+
+// EndDue warns the clients of scenes nearing their maximum duration and
+// closes the scenes that have reached it. Each warning is claimed in the
+// store, so overlapping runs never send one twice; only the shortest due
+// warning is sent to a scene that was missed for a while.
+func (s *Service) EndDue(ctx context.Context) error {
+	now := time.Now()
+	scenes, err := s.Scenes.GetScenesEndingBy(ctx, now.Add(endWarnings[0]))
+	if err != nil {
+		return err
+	}
+	for _, scene := range scenes {
+		left := scene.EndsAt.Sub(now)
+		if left <= 0 {
+			_, err := s.Close(ctx, scene.ID, models.SceneClosedEnded)
+			if err != nil && !errors.Is(err, storage.ErrConflict) && !errors.Is(err, storage.ErrNotFound) {
+				log.Printf("Error ending scene %s: %v", scene.ID, err)
+			}
+			continue
+		}
+		s.warnEnding(ctx, scene, left)
+	}
+	return nil
+}
+
+// warnEnding sends scene.ending to a scene's clients if one of endWarnings
+// is due and has not been sent yet.
+func (s *Service) warnEnding(ctx context.Context, scene *models.Scene, left time.Duration) {
+	lead := time.Duration(0)
+	for _, w := range endWarnings {
+		if left <= w {
+			lead = w
+		}
+	}
+	if lead == 0 {
+		return
+	}
+	claimed, err := s.Scenes.ClaimEndWarning(ctx, scene.ID, lead)
+	if err != nil {
+		log.Printf("Error claiming end warning for scene %s: %v", scene.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	s.Hub.SendToScene(scene.ID, ws.TypeSceneEnding, models.SceneEnding{
+		SceneID:     scene.ID,
+		EndsAt:      *scene.EndsAt,
+		SecondsLeft: int(left.Round(time.Second).Seconds()),
+	})
+}
+
+// HandleEmpty closes a scene the hub reports has had no connections for the
+// configured period. It is registered with ws.Hub.OnSceneEmpty.
+func (s *Service) HandleEmpty(sceneID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+	_, err := s.Close(ctx, sceneID, models.SceneClosedEmpty)
+	if err != nil && !errors.Is(err, storage.ErrConflict) && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Error closing empty scene %s: %v", sceneID, err)
+	}
+}
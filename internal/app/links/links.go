@@ -0,0 +1,61 @@
+// Package links builds URLs into the Scenyx web frontend, for redirects,
+// share links, and deep links in notifications.
+package links
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is the local frontend dev server, used when no base URL is
+// configured.
+const DefaultBaseURL = "http://127.0.0.1:5173"
+
+// scenePath is the frontend page that displays a scene.
+const scenePath = "/scene-view"
+
+// Builder forms frontend URLs from a base URL.
+type Builder struct {
+	base *url.URL
+}
+
+// New creates a Builder for the frontend at baseURL, which must be an
+// absolute http or https URL. It may include a path prefix.
+func New(baseURL string) (*Builder, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("links: parse base URL: %w", err)
+	}
+	if (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("links: base URL must be an absolute http or https URL, got %q", baseURL)
+	}
+	base.Path = strings.TrimRight(base.Path, "/")
+	base.RawQuery, base.Fragment = "", ""
+	return &Builder{base: base}, nil
+}
+
+// Origin returns the scheme and host of the frontend, as browsers send it in
+// the Origin header.
+func (b *Builder) Origin() string {
+	return b.base.Scheme + "://" + b.base.Host
+}
+
+// URL returns the frontend URL for path with the given query parameters.
+func (b *Builder) URL(path string, query url.Values) string {
+	u := *b.base
+	u.Path += "/" + strings.TrimLeft(path, "/")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Scene returns the frontend URL of a scene's page.
+func (b *Builder) Scene(sceneID string) string {
+	return b.URL(scenePath, url.Values{"scene_id": {sceneID}})
+}
+
+// SceneCheckout returns the scene page Stripe Checkout returns a buyer to,
+// with result ("success" or "cancelled") in the checkout query parameter.
+func (b *Builder) SceneCheckout(sceneID, result string) string {
+	return b.URL(scenePath, url.Values{"scene_id": {sceneID}, "checkout": {result}})
+}
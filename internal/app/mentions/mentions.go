@@ -0,0 +1,65 @@
+// Package mentions finds @username mentions in chat messages so they can be
+// resolved to users, stored with the message, and notified.
+package mentions
+
+import (
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+// Username limits, matching the rules usernames are claimed under.
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 30
+)
+
+// MaxPerMessage caps how many mentions are resolved in one message, so a
+// single message cannot notify an unbounded number of users.
+const MaxPerMessage = 20
+
+// Parse returns the @username spans in content, in order of appearance. A
+// mention must start the content or follow a character that cannot be part
+// of a username, so email addresses are not matched. Usernames are
+// lowercased; UserID is left for the store to resolve. Repeated usernames are
+// returned once per occurrence, up to MaxPerMessage spans.
+func Parse(content string) []models.Mention {
+	var spans []models.Mention
+	for i := 0; i < len(content) && len(spans) < MaxPerMessage; i++ {
+		if content[i] != '@' || (i > 0 && isUsernameByte(content[i-1])) {
+			continue
+		}
+		end := i + 1
+		for end < len(content) && isUsernameByte(content[end]) {
+			end++
+		}
+		name := content[i+1 : end]
+		if n := len(name); n >= minUsernameLength && n <= maxUsernameLength && isLetter(name[0]) {
+			spans = append(spans, models.Mention{Username: strings.ToLower(name), Start: i, End: end})
+		}
+		i = end - 1
+	}
+	return spans
+}
+
+// Recipients returns the distinct users mentioned in order of first mention,
+// leaving out senderID so users are not notified of their own messages.
+func Recipients(mentions []models.Mention, senderID string) []string {
+	seen := map[string]bool{senderID: true}
+	var userIDs []string
+	for _, m := range mentions {
+		if !seen[m.UserID] {
+			seen[m.UserID] = true
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+	return userIDs
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isUsernameByte(c byte) bool {
+	return isLetter(c) || '0' <= c && c <= '9' || c == '_'
+}
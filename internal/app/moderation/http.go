@@ -0,0 +1,62 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPFilter delegates to an external moderation service. It POSTs
+// {"content": "..."} to URL and expects a response of the form
+// {"flagged": true, "categories": ["harassment"], "redacted": "..."},
+// where "redacted" is optional.
+type HTTPFilter struct {
+	URL    string
+	APIKey string // Sent as a bearer token when set
+	Client *http.Client
+}
+
+var _ Filter = (*HTTPFilter)(nil)
+
+// Check sends content to the moderation service.
+func (f *HTTPFilter) Check(ctx context.Context, content string) (Result, error) {
+	body, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return Result{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.URL, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("moderation: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+f.APIKey)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("moderation: call %s: %w", f.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return Result{}, fmt.Errorf("moderation: %s returned %s: %s", f.URL, resp.Status, msg)
+	}
+
+	var out struct {
+		Flagged    bool     `json:"flagged"`
+		Categories []string `json:"categories"`
+		Redacted   string   `json:"redacted"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Result{}, fmt.Errorf("moderation: decode response from %s: %w", f.URL, err)
+	}
+	return Result{Matched: out.Flagged, Reasons: out.Categories, Redacted: out.Redacted}, nil
+}
@@ -0,0 +1,132 @@
+// Package moderation screens chat messages before they are persisted. A
+// Moderator runs one or more Filters over the content and applies a single
+// configured Action to anything they match.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// Action is what happens to a message a filter matched.
+type Action string
+
+const (
+	// ActionReject refuses the message.
+	ActionReject Action = "reject"
+	// ActionRedact stores the message with the matched parts masked.
+	ActionRedact Action = "redact"
+	// ActionFlag stores the message unchanged and records it for review.
+	ActionFlag Action = "flag"
+)
+
+// ParseAction validates an action name.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionReject, ActionRedact, ActionFlag:
+		return a, nil
+	}
+	return "", fmt.Errorf("moderation: unknown action %q; want reject, redact, or flag", s)
+}
+
+// ErrRejected is returned by Review when the message must not be sent.
+var ErrRejected = errors.New("moderation: message rejected")
+
+// redactedPlaceholder replaces content a filter matched but could not redact itself.
+const redactedPlaceholder = "[removed by moderation]"
+
+// Result is what a Filter found in a message.
+type Result struct {
+	Matched bool     // Whether the content violates the filter
+	Reasons []string // Matched terms or categories, for logs and flags
+	// Redacted is the content with the matched parts masked. Filters that
+	// cannot locate matches leave it empty and the whole message is replaced.
+	Redacted string
+}
+
+// Filter inspects message content. Implementations must be safe for
+// concurrent use.
+type Filter interface {
+	Check(ctx context.Context, content string) (Result, error)
+}
+
+// Decision is the outcome of reviewing one message.
+type Decision struct {
+	Content string   // The content to persist, redacted when the action is redact
+	Flagged bool     // The message should be recorded with Moderator.Flag once stored
+	Reasons []string // What the filters matched
+}
+
+// Moderator applies Action to messages matched by any of Filters.
+type Moderator struct {
+	Filters []Filter
+	Action  Action
+	Flags   storage.ModerationStore // Records flagged messages
+}
+
+// reviewTimeout bounds the time spent running filters on a single message.
+const reviewTimeout = 3 * time.Second
+
+// Review runs every filter over content. It returns ErrRejected if the
+// message matched and the action is reject. A filter that fails is logged
+// and skipped so an outage of an external service does not block chat.
+func (m *Moderator) Review(ctx context.Context, content string) (Decision, error) {
+	ctx, cancel := context.WithTimeout(ctx, reviewTimeout)
+	defer cancel()
+
+	decision := Decision{Content: content}
+	matched := false
+	for _, f := range m.Filters {
+		res, err := f.Check(ctx, decision.Content)
+		if err != nil {
+			log.Printf("[Moderation] Filter %T failed, allowing message: %v", f, err)
+			continue
+		}
+		if !res.Matched {
+			continue
+		}
+		matched = true
+		decision.Reasons = append(decision.Reasons, res.Reasons...)
+		if m.Action == ActionRedact {
+			if res.Redacted == "" {
+				decision.Content = redactedPlaceholder
+				break
+			}
+			// Later filters see the redacted text
+			decision.Content = res.Redacted
+		}
+	}
+	if !matched {
+		return decision, nil
+	}
+
+	switch m.Action {
+	case ActionReject:
+		return decision, ErrRejected
+	case ActionFlag:
+		decision.Flagged = true
+	}
+	return decision, nil
+}
+
+// Flag records that a stored message was flagged. Failures are logged
+// rather than returned since the message has already been sent.
+func (m *Moderator) Flag(ctx context.Context, messageType, messageID, senderID string, reasons []string) {
+	flag := &models.MessageFlag{
+		MessageType: messageType,
+		MessageID:   messageID,
+		SenderID:    senderID,
+		Reasons:     reasons,
+	}
+	if err := m.Flags.FlagMessage(ctx, flag); err != nil {
+		log.Printf("[Moderation] Error flagging %s message %s: %v", messageType, messageID, err)
+		return
+	}
+	log.Printf("[Moderation] Flagged %s message %s from %s: %v", messageType, messageID, senderID, reasons)
+}
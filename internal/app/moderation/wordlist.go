@@ -0,0 +1,91 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// WordList matches whole words from a fixed list, ignoring case.
+type WordList struct {
+	words map[string]bool
+}
+
+var _ Filter = (*WordList)(nil)
+
+// NewWordList creates a WordList from words. Blank entries are ignored.
+func NewWordList(words []string) *WordList {
+	w := &WordList{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			w.words[word] = true
+		}
+	}
+	return w
+}
+
+// LoadWordList reads a WordList from a file with one word per line.
+// Lines starting with # are comments.
+func LoadWordList(path string) (*WordList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("moderation: open word list: %w", err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("moderation: read word list: %w", err)
+	}
+	return NewWordList(words), nil
+}
+
+// Len returns the number of words in the list.
+func (w *WordList) Len() int {
+	return len(w.words)
+}
+
+// Check reports the listed words in content and masks each with asterisks.
+func (w *WordList) Check(ctx context.Context, content string) (Result, error) {
+	var res Result
+	var b strings.Builder
+	runes := []rune(content)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if lower := strings.ToLower(word); w.words[lower] {
+			res.Matched = true
+			res.Reasons = append(res.Reasons, lower)
+			b.WriteString(strings.Repeat("*", j-i))
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	if res.Matched {
+		res.Redacted = b.String()
+	}
+	return res, nil
+}
+
+// isWordRune reports whether r is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\''
+}
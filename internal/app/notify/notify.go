@@ -0,0 +1,125 @@
+// Package notify delivers notifications to the users they concern, over
+// every WebSocket connection each user has open, skipping users whose
+// notification settings or conversation overrides turn them off and holding
+// back those that arrive during a user's quiet hours.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// maxSummaryItems caps the notifications listed in a quiet hours summary;
+// older ones are only counted.
+const maxSummaryItems = 50
+
+// Notification is an event for individual users. At most one of DMID and
+// SceneID is set; the recipients' overrides for that conversation apply.
+type Notification struct {
+	Channel models.NotificationChannel
+	DMID    string // DM conversation the notification comes from, if any
+	SceneID string // Scene the notification comes from, if any
+	Type    ws.MessageType
+	Payload any
+}
+
+// Dispatcher sends notifications that recipients' preferences allow.
+type Dispatcher struct {
+	Store storage.NotificationStore // Loads recipients' settings and overrides, and holds notifications during quiet hours
+	Hub   *ws.Hub                   // Delivers notifications to users' connections
+}
+
+// Notify sends n to each of userIDs whose preferences allow its channel.
+// Users in their quiet hours get it in the summary sent when those end. If
+// the preferences cannot be loaded, everyone is notified; a notification the
+// user turned off is better than one they miss.
+func (d *Dispatcher) Notify(ctx context.Context, n Notification, userIDs ...string) {
+	if len(userIDs) == 0 {
+		return
+	}
+	prefs, err := d.Store.GetNotificationPreferences(ctx, userIDs, n.DMID, n.SceneID)
+	if err != nil {
+		log.Printf("Error loading notification preferences for %s notification: %v", n.Type, err)
+	}
+	now := time.Now()
+	for _, userID := range userIDs {
+		p, ok := prefs[userID]
+		if ok && !p.Allows(n.Channel) {
+			continue
+		}
+		if ok && p.Settings.QuietHours.Active(now) && d.hold(ctx, userID, n) {
+			continue
+		}
+		d.Hub.SendToUser(userID, n.Type, n.Payload)
+	}
+}
+
+// hold queues n for userID's quiet hours summary. It reports false if n
+// could not be queued, in which case it is better sent right away.
+func (d *Dispatcher) hold(ctx context.Context, userID string, n Notification) bool {
+	payload, err := json.Marshal(n.Payload)
+	if err != nil {
+		log.Printf("Error encoding %s notification for user %s: %v", n.Type, userID, err)
+		return false
+	}
+	err = d.Store.QueueNotification(ctx, userID, models.QueuedNotification{
+		Channel: n.Channel,
+		Type:    string(n.Type),
+		Payload: payload,
+	})
+	if err != nil {
+		log.Printf("Error holding %s notification for user %s: %v", n.Type, userID, err)
+		return false
+	}
+	return true
+}
+
+// SendSummaries sends each user whose quiet hours have ended a summary of
+// the notifications held during them. It is intended to run as a scheduled
+// job; users still in their quiet hours are left for a later run.
+func (d *Dispatcher) SendSummaries(ctx context.Context) error {
+	userIDs, err := d.Store.GetQueuedNotificationUsers(ctx)
+	if err != nil || len(userIDs) == 0 {
+		return err
+	}
+	prefs, err := d.Store.GetNotificationPreferences(ctx, userIDs, "", "")
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, userID := range userIDs {
+		if prefs[userID].Settings.QuietHours.Active(now) {
+			continue
+		}
+		queued, err := d.Store.TakeQueuedNotifications(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(queued) == 0 {
+			continue
+		}
+		d.Hub.SendToUser(userID, ws.TypeNotificationSummary, summarize(queued))
+		log.Printf("Sent quiet hours summary of %d notifications to user %s", len(queued), userID)
+	}
+	return nil
+}
+
+// summarize counts queued notifications by channel and keeps the latest
+// maxSummaryItems of them.
+func summarize(queued []models.QueuedNotification) models.NotificationSummary {
+	summary := models.NotificationSummary{
+		Total:     len(queued),
+		ByChannel: make(map[models.NotificationChannel]int),
+	}
+	for _, n := range queued {
+		summary.ByChannel[n.Channel]++
+	}
+	summary.Notifications = queued[max(0, len(queued)-maxSummaryItems):]
+	return summary
+}
@@ -0,0 +1,110 @@
+// Package offline queues DM events for participants who are not connected to
+// the conversation and hands them over when they next connect, so the
+// WebSocket stream a client saw matches the REST history.
+package offline
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+const (
+	// eventTimeout bounds the database work done for a single event or connection.
+	eventTimeout = 5 * time.Second
+	// maxAge is how long queued events are kept; clients that stay away
+	// longer catch up from the REST history.
+	maxAge = 7 * 24 * time.Hour
+	// pruneBatch is the number of events deleted per store call when pruning.
+	pruneBatch = 1000
+)
+
+// queued lists the event types worth delivering late. Presence and typing
+// events are stale by the time anyone reconnects.
+var queued = []ws.MessageType{ws.TypeChat, ws.TypeMessageUpdated, ws.TypeMessageDeleted, ws.TypeReaction, ws.TypeReceipt, ws.TypeMessageExpired}
+
+// Service queues and delivers DM events for offline participants. Whether a
+// participant is connected is judged by this instance only, so behind a broker
+// users connected elsewhere may receive an event twice; clients dedupe by
+// envelope ID.
+type Service struct {
+	Store storage.OfflineStore // Holds the queued events
+	DMs   storage.DMStore      // Finds the participants of a DM
+	Hub   *ws.Hub              // Delivers queued events on connect
+}
+
+// HandleDMEvent queues e for every participant of the DM without a
+// connection to it. It is intended to be registered with ws.Hub.OnDMEvent.
+func (s *Service) HandleDMEvent(e ws.DMEvent) {
+	if !slices.Contains(queued, e.Type) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+	defer cancel()
+
+	participants, err := s.DMs.GetParticipants(ctx, e.DMID)
+	if err != nil {
+		log.Printf("Error loading participants to queue %s event for DM %s: %v", e.Type, e.DMID, err)
+		return
+	}
+	var offline []string
+	for _, userID := range participants {
+		if !slices.Contains(e.Connected, userID) {
+			offline = append(offline, userID)
+		}
+	}
+	if err := s.Store.EnqueueDMEvent(ctx, e.DMID, offline, e.Data); err != nil {
+		log.Printf("Error queueing %s event for DM %s: %v", e.Type, e.DMID, err)
+	}
+}
+
+// HandleDMConnect sends the client the events queued for its user in its DM.
+// Events that cannot be sent are queued again. It is intended to be
+// registered with ws.Hub.OnDMConnect.
+func (s *Service) HandleDMConnect(c *ws.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+	defer cancel()
+
+	events, err := s.Store.TakeDMEvents(ctx, c.UserID, c.DMID)
+	if err != nil {
+		log.Printf("Error loading queued events of user %s in DM %s: %v", c.UserID, c.DMID, err)
+		return
+	}
+	for i, data := range events {
+		if s.Hub.SendToClient(c, data) {
+			continue
+		}
+		for _, rest := range events[i:] {
+			if err := s.Store.EnqueueDMEvent(ctx, c.DMID, []string{c.UserID}, rest); err != nil {
+				log.Printf("Error requeueing event for user %s in DM %s: %v", c.UserID, c.DMID, err)
+				return
+			}
+		}
+		return
+	}
+}
+
+// Prune deletes events queued longer than maxAge. It is intended to run as
+// a scheduled job.
+func (s *Service) Prune(ctx context.Context) error {
+	cutoff := time.Now().Add(-maxAge)
+	var total int64
+	for {
+		n, err := s.Store.DeleteDMEventsBefore(ctx, cutoff, pruneBatch)
+		total += n
+		if err != nil {
+			return err
+		}
+		if n < pruneBatch || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("Pruned %d undelivered offline DM events", total)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Kafka publishes to Kafka through a Confluent-compatible REST Proxy (API
+// v2), with one produce request per topic in a batch. Each message's key
+// picks its partition, so events of one scene or conversation stay ordered.
+type Kafka struct {
+	URL    string       // REST Proxy base URL, e.g. http://kafka-rest:8082
+	Client *http.Client // Should have a timeout; http.DefaultClient if nil
+}
+
+// kafkaRecord is one record of a REST Proxy produce request.
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaProduceResponse is the REST Proxy's answer to a produce request; each
+// offset reports the outcome of the record at the same index.
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish produces msgs, grouped by topic in order of first appearance.
+func (k *Kafka) Publish(ctx context.Context, msgs []Message) error {
+	var topics []string
+	records := make(map[string][]kafkaRecord)
+	for _, msg := range msgs {
+		if _, ok := records[msg.Subject]; !ok {
+			topics = append(topics, msg.Subject)
+		}
+		records[msg.Subject] = append(records[msg.Subject], kafkaRecord{Key: msg.Key, Value: msg.Value})
+	}
+
+	for _, topic := range topics {
+		if err := k.produce(ctx, topic, records[topic]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// produce sends one topic's records in a single request.
+func (k *Kafka) produce(ctx context.Context, topic string, records []kafkaRecord) error {
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimSuffix(k.URL, "/") + "/topics/" + url.PathEscape(topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	client := k.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("produce to Kafka topic %s: %w", topic, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("produce to Kafka topic %s: REST proxy answered %d: %s", topic, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode Kafka produce response for topic %s: %w", topic, err)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("produce to Kafka topic %s: error %d: %s", topic, *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close does nothing; the HTTP client's connections are shared.
+func (k *Kafka) Close() error {
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package outbox publishes the domain events recorded in the event outbox to
+// a message broker (NATS or Kafka), so analytics and other services can
+// consume scene and DM activity.
+//
+// Events are written by the stores in the same transaction as the change
+// they describe and are only marked published once the broker has accepted
+// them, so delivery is at least once: consumers should dedupe on eventID.
+// Events of one scene or conversation are published in the order they were
+// written and carry its ID as the message key.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// Publishing tuning.
+const (
+	DefaultPrefix  = "scenyx"
+	batchSize      = 100
+	publishedTTL   = 24 * time.Hour // How long published events are kept for inspection
+	pruneInterval  = time.Hour
+	pruneBatchSize = 1000
+)
+
+// Message is one event addressed to a broker subject (NATS) or topic (Kafka).
+type Message struct {
+	Subject string
+	Key     string // Partitioning key; the scene or DM conversation ID
+	Value   []byte // JSON-encoded models.OutboxEvent
+}
+
+// Broker sends messages to a message broker.
+type Broker interface {
+	// Publish sends msgs, returning nil only once the broker has accepted
+	// all of them. Messages with the same subject must keep their order.
+	Publish(ctx context.Context, msgs []Message) error
+	Close() error
+}
+
+// Publisher moves events from the outbox to the broker.
+type Publisher struct {
+	Store  storage.OutboxStore
+	Broker Broker
+	Prefix string // Subjects are "<prefix>.<aggregate>.<event>"; DefaultPrefix if empty
+
+	lastPrune time.Time
+}
+
+// Subject returns the subject or topic an event is published to, e.g.
+// "scenyx.dm.message.sent".
+func (p *Publisher) Subject(event models.OutboxEvent) string {
+	prefix := p.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return prefix + "." + event.AggregateType + "." + event.Event
+}
+
+// Publish sends pending events until the outbox is drained. It is meant to
+// run as a recurring job; a batch the broker rejects is retried on the next run.
+func (p *Publisher) Publish(ctx context.Context) error {
+	for {
+		events, err := p.Store.PendingEvents(ctx, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		msgs := make([]Message, 0, len(events))
+		ids := make([]int64, 0, len(events))
+		for _, event := range events {
+			value, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("encode outbox event %s: %w", event.EventID, err)
+			}
+			msgs = append(msgs, Message{Subject: p.Subject(event), Key: event.AggregateID, Value: value})
+			ids = append(ids, event.ID)
+		}
+		if err := p.Broker.Publish(ctx, msgs); err != nil {
+			return fmt.Errorf("publish %d outbox events: %w", len(msgs), err)
+		}
+		if err := p.Store.MarkPublished(ctx, ids); err != nil {
+			return err
+		}
+
+		if len(events) < batchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if time.Since(p.lastPrune) >= pruneInterval {
+		deleted, err := p.Store.DeletePublishedBefore(ctx, time.Now().Add(-publishedTTL), pruneBatchSize)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			log.Printf("Pruned %d published outbox events", deleted)
+		}
+		// Keep pruning on every run until the backlog is gone
+		if deleted < pruneBatchSize {
+			p.lastPrune = time.Now()
+		}
+	}
+	return ctx.Err()
+}
+
+// Close closes the broker connection.
+func (p *Publisher) Close() error {
+	return p.Broker.Close()
+}
@@ -0,0 +1,327 @@
+// Package payments charges entry to ticketed scenes and gifts to scene hosts
+// through Stripe Checkout, and applies the webhook events Stripe sends as
+// payments settle.
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+const (
+	checkoutURL = "https://api.stripe.com/v1/checkout/sessions"
+
+	// signatureTolerance is how old a webhook's signed timestamp may be
+	// before the event is rejected as a possible replay.
+	signatureTolerance = 5 * time.Minute
+)
+
+// Checkout purposes, stored in each session's metadata so webhook events
+// reach the right ledger.
+const (
+	purposeTicket = "ticket"
+	purposeGift   = "gift"
+)
+
+var (
+	// ErrInvalidSignature is returned when a webhook's Stripe-Signature
+	// header does not match its payload.
+	ErrInvalidSignature = errors.New("payments: invalid webhook signature")
+)
+
+// Config holds the Stripe account credentials.
+type Config struct {
+	SecretKey     string // API secret key, sk_live_... or sk_test_...
+	WebhookSecret string // Signing secret of the webhook endpoint, whsec_...
+}
+
+// Service creates Checkout sessions for scene tickets and gifts and
+// resolves them from Stripe's webhook events.
+type Service struct {
+	cfg     Config
+	tickets storage.TicketStore
+	gifts   storage.GiftStore
+	client  *http.Client
+}
+
+// NewService creates a Service. Both credentials are required.
+func NewService(cfg Config, tickets storage.TicketStore, gifts storage.GiftStore) (*Service, error) {
+	if cfg.SecretKey == "" || cfg.WebhookSecret == "" {
+		return nil, errors.New("stripe secret key and webhook secret are both required")
+	}
+	return &Service{
+		cfg:     cfg,
+		tickets: tickets,
+		gifts:   gifts,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// checkoutSession is the part of a Stripe Checkout session Scenyx reads,
+// from both the API and webhook events.
+type checkoutSession struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	PaymentStatus string            `json:"payment_status"` // paid, unpaid, or no_payment_required
+	PaymentIntent string            `json:"payment_intent"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// Update is a ticket or gift whose status a webhook event changed; exactly
+// one field is set.
+type Update struct {
+	Ticket *models.SceneTicket
+	Gift   *models.Gift
+}
+
+// CreateCheckout starts a Stripe Checkout session charging userID the
+// scene's ticket price and records it as a pending ticket. The returned
+// ticket's CheckoutURL is the payment page to send the user to; Stripe
+// returns them to successURL or cancelURL.
+func (s *Service) CreateCheckout(ctx context.Context, scene *models.Scene, userID, successURL, cancelURL string) (*models.SceneTicket, error) {
+	session, err := s.createSession(ctx, checkoutRequest{
+		purpose:     purposeTicket,
+		sceneID:     scene.ID,
+		userID:      userID,
+		name:        "Ticket: " + scene.Name,
+		amountCents: scene.TicketPriceCents,
+		currency:    scene.TicketCurrency,
+		successURL:  successURL,
+		cancelURL:   cancelURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.tickets.CreateTicket(ctx, &models.SceneTicket{
+		SceneID:           scene.ID,
+		UserID:            userID,
+		AmountCents:       scene.TicketPriceCents,
+		Currency:          scene.TicketCurrency,
+		CheckoutSessionID: session.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ticket.CheckoutURL = session.URL
+	return ticket, nil
+}
+
+// CreateGiftCheckout starts a Stripe Checkout session for gift and records
+// it as a pending gift. The returned gift's CheckoutURL is the payment page
+// to send the sender to; Stripe returns them to successURL or cancelURL.
+func (s *Service) CreateGiftCheckout(ctx context.Context, gift *models.Gift, sceneName, successURL, cancelURL string) (*models.Gift, error) {
+	session, err := s.createSession(ctx, checkoutRequest{
+		purpose:     purposeGift,
+		sceneID:     gift.SceneID,
+		userID:      gift.SenderID,
+		name:        "Gift (" + string(gift.Kind) + ") in " + sceneName,
+		amountCents: gift.AmountCents,
+		currency:    gift.Currency,
+		successURL:  successURL,
+		cancelURL:   cancelURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pending := *gift
+	pending.CheckoutSessionID = session.ID
+	created, err := s.gifts.CreateGiftCheckout(ctx, &pending)
+	if err != nil {
+		return nil, err
+	}
+	created.CheckoutURL = session.URL
+	return created, nil
+}
+
+// checkoutRequest describes a one-item Checkout session.
+type checkoutRequest struct {
+	purpose     string // purposeTicket or purposeGift
+	sceneID     string
+	userID      string
+	name        string // Line item shown on the payment page
+	amountCents int
+	currency    string
+	successURL  string
+	cancelURL   string
+}
+
+// createSession creates a Stripe Checkout session charging one item.
+func (s *Service) createSession(ctx context.Context, c checkoutRequest) (*checkoutSession, error) {
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("success_url", c.successURL)
+	form.Set("cancel_url", c.cancelURL)
+	form.Set("client_reference_id", c.userID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", c.currency)
+	form.Set("line_items[0][price_data][unit_amount]", strconv.Itoa(c.amountCents))
+	form.Set("line_items[0][price_data][product_data][name]", c.name)
+	for _, prefix := range []string{"metadata", "payment_intent_data[metadata]"} {
+		form.Set(prefix+"[purpose]", c.purpose)
+		form.Set(prefix+"[scene_id]", c.sceneID)
+		form.Set(prefix+"[user_id]", c.userID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, checkoutURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+s.cfg.SecretKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stripe checkout request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("stripe checkout request failed: %s: %s", resp.Status, body)
+	}
+
+	var session checkoutSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return nil, fmt.Errorf("decode stripe checkout session: %w", err)
+	}
+	return &session, nil
+}
+
+// event is a Stripe webhook event.
+type event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// charge is the part of a Stripe charge read from charge.refunded events.
+type charge struct {
+	PaymentIntent string            `json:"payment_intent"`
+	Refunded      bool              `json:"refunded"` // Fully refunded; partial refunds keep the ticket or gift
+	Metadata      map[string]string `json:"metadata"` // Copied from the payment intent's metadata
+}
+
+// HandleWebhook verifies a webhook payload against its Stripe-Signature
+// header and applies the event to the ticket or gift it concerns. It
+// returns that ticket or gift if its status changed, or nil for events that
+// change nothing, including redeliveries of events already applied.
+func (s *Service) HandleWebhook(ctx context.Context, payload []byte, signature string) (*Update, error) {
+	if err := s.verifySignature(payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+	var ev event
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("decode stripe event: %w", err)
+	}
+
+	update := &Update{}
+	var err error
+	switch ev.Type {
+	case "checkout.session.completed", "checkout.session.async_payment_succeeded",
+		"checkout.session.expired", "checkout.session.async_payment_failed":
+		var session checkoutSession
+		if err := json.Unmarshal(ev.Data.Object, &session); err != nil {
+			return nil, fmt.Errorf("decode %s event %s: %w", ev.Type, ev.ID, err)
+		}
+		paid := ev.Type == "checkout.session.completed" || ev.Type == "checkout.session.async_payment_succeeded"
+		// Delayed payment methods complete the checkout unpaid and settle later
+		if paid && session.PaymentStatus != "paid" {
+			return nil, nil
+		}
+		// Sessions from before gifts existed carry no purpose; they are tickets
+		if session.Metadata["purpose"] == purposeGift {
+			status := models.GiftFailed
+			if paid {
+				status = models.GiftCompleted
+			}
+			update.Gift, err = s.gifts.ResolveGiftCheckout(ctx, session.ID, session.PaymentIntent, status)
+		} else {
+			status := models.TicketFailed
+			if paid {
+				status = models.TicketPaid
+			}
+			update.Ticket, err = s.tickets.ResolveCheckout(ctx, session.ID, session.PaymentIntent, status)
+		}
+	case "charge.refunded":
+		var c charge
+		if err := json.Unmarshal(ev.Data.Object, &c); err != nil {
+			return nil, fmt.Errorf("decode %s event %s: %w", ev.Type, ev.ID, err)
+		}
+		if !c.Refunded || c.PaymentIntent == "" {
+			return nil, nil
+		}
+		if c.Metadata["purpose"] == purposeGift {
+			update.Gift, err = s.gifts.RefundGift(ctx, c.PaymentIntent)
+		} else {
+			update.Ticket, err = s.tickets.RefundTicket(ctx, c.PaymentIntent)
+		}
+	default:
+		return nil, nil
+	}
+	// Sessions created outside Scenyx and redelivered events are ignored
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrConflict) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("apply %s event %s: %w", ev.Type, ev.ID, err)
+	}
+	return update, nil
+}
+
+// verifySignature checks a Stripe-Signature header of the form
+// "t=<unix time>,v1=<hex HMAC-SHA256>[,v1=...]" against payload. The HMAC
+// covers "<t>.<payload>" and is keyed with the webhook signing secret.
+func (s *Service) verifySignature(payload []byte, header string, now time.Time) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.cfg.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
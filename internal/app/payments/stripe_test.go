@@ -0,0 +1,62 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// stripeSignature returns the v1 signature Stripe sends for payload signed at t.
+func stripeSignature(secret string, t time.Time, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t.Unix(), 10) + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	s := &Service{cfg: Config{WebhookSecret: testWebhookSecret}}
+	now := time.Unix(1_800_000_000, 0)
+	payload := `{"id":"evt_1","type":"checkout.session.completed"}`
+	ts := strconv.FormatInt(now.Unix(), 10)
+	valid := stripeSignature(testWebhookSecret, now, payload)
+
+	tests := []struct {
+		name    string
+		payload string
+		header  string
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", payload, "t=" + ts + ",v1=" + valid, now, false},
+		{"valid among several", payload, "t=" + ts + ",v1=" + stripeSignature("whsec_old", now, payload) + ",v1=" + valid, now, false},
+		{"test-mode v0 ignored", payload, "t=" + ts + ",v1=" + valid + ",v0=deadbeef", now, false},
+		{"spaces after commas", payload, "t=" + ts + ", v1=" + valid, now, false},
+		{"within tolerance", payload, "t=" + ts + ",v1=" + valid, now.Add(signatureTolerance), false},
+		{"wrong secret", payload, "t=" + ts + ",v1=" + stripeSignature("whsec_other", now, payload), now, true},
+		{"tampered payload", payload + " ", "t=" + ts + ",v1=" + valid, now, true},
+		{"timestamp not signed", payload, "t=" + strconv.FormatInt(now.Unix()+1, 10) + ",v1=" + valid, now, true},
+		{"too old", payload, "t=" + ts + ",v1=" + valid, now.Add(signatureTolerance + time.Second), true},
+		{"too far ahead", payload, "t=" + ts + ",v1=" + valid, now.Add(-signatureTolerance - time.Second), true},
+		{"no timestamp", payload, "v1=" + valid, now, true},
+		{"no v1", payload, "t=" + ts + ",v0=" + valid, now, true},
+		{"not hex", payload, "t=" + ts + ",v1=" + valid[:len(valid)-1] + "z", now, true},
+		{"empty", payload, "", now, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.verifySignature([]byte(tt.payload), tt.header, tt.now)
+			if tt.wantErr && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("verifySignature = %v, want ErrInvalidSignature", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("verifySignature = %v, want nil", err)
+			}
+		})
+	}
+}
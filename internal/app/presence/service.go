@@ -0,0 +1,42 @@
+// Package presence persists presence changes reported by the WebSocket hub
+// and notifies a user's DM conversations about them.
+package presence
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// changeTimeout bounds the database work done for a single presence change.
+const changeTimeout = 5 * time.Second
+
+// Service reacts to presence changes from the hub.
+type Service struct {
+	Users storage.UserStore // Persists last_seen_at
+	DMs   storage.DMStore   // Finds the conversations to notify
+	Hub   *ws.Hub           // Delivers presence events
+}
+
+// HandleChange records p and broadcasts it to every DM the user belongs to.
+// It is intended to be registered with ws.Hub.OnPresenceChange.
+func (s *Service) HandleChange(p ws.Presence) {
+	ctx, cancel := context.WithTimeout(context.Background(), changeTimeout)
+	defer cancel()
+
+	if err := s.Users.TouchLastSeen(ctx, p.UserID, p.LastSeenAt); err != nil {
+		log.Printf("Error recording last seen for user %s: %v", p.UserID, err)
+	}
+
+	dmIDs, err := s.DMs.GetConversationIDs(ctx, p.UserID)
+	if err != nil {
+		log.Printf("Error loading conversations to notify presence of user %s: %v", p.UserID, err)
+		return
+	}
+	for _, dmID := range dmIDs {
+		s.Hub.SendToDM(dmID, ws.TypePresence, p)
+	}
+}
@@ -0,0 +1,44 @@
+// Package recommend periodically scores public scenes for each user from the
+// hashtags of scenes they listened to, the creators they follow, and how long
+// they listened to each creator.
+package recommend
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+const (
+	// window is how far back listening history counts.
+	window = 30 * 24 * time.Hour
+	// perUser is how many recommendations are kept for each user.
+	perUser = 50
+)
+
+// DefaultWeights favor followed creators, then creators the user spent time
+// with, then shared hashtags.
+var DefaultWeights = storage.RecommendationWeights{
+	SharedTag:       1,
+	FollowedCreator: 5,
+	HistoryHour:     1,
+	MaxHistoryHours: 4,
+}
+
+// Service rebuilds the stored recommendations.
+type Service struct {
+	Store   storage.RecommendationStore
+	Weights storage.RecommendationWeights // Zero uses DefaultWeights
+}
+
+// Refresh recomputes every user's recommendations. It is meant to run as a
+// background job on a single instance.
+func (s *Service) Refresh(ctx context.Context) error {
+	weights := s.Weights
+	if weights == (storage.RecommendationWeights{}) {
+		weights = DefaultWeights
+	}
+	_, err := s.Store.RefreshRecommendations(ctx, weights, time.Now().Add(-window), perUser)
+	return err
+}
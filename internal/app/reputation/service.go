@@ -0,0 +1,47 @@
+// Package reputation periodically scores each user from their recent
+// participation, the reports filed against them, and the scenes that banned
+// or muted them, for hosts deciding whom to admit or ban.
+package reputation
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// window is how far back participation counts. Reports and restrictions
+// count for as long as they exist.
+const window = 90 * 24 * time.Hour
+
+// DefaultWeights let steady participation earn up to 150 points; an actioned
+// report or a ban costs more than a month of casual listening earns.
+var DefaultWeights = storage.ReputationWeights{
+	ListeningHour:     1,
+	MaxListeningHours: 50,
+	SceneHosted:       5,
+	MaxScenesHosted:   10,
+	Message:           0.1,
+	MaxMessages:       500,
+	ActionedReport:    25,
+	OpenReport:        2,
+	SceneBan:          10,
+	SceneMute:         3,
+}
+
+// Service rebuilds the stored reputations.
+type Service struct {
+	Store   storage.ReputationStore
+	Weights storage.ReputationWeights // Zero uses DefaultWeights
+}
+
+// Refresh recomputes every user's reputation. It is meant to run as a
+// background job on a single instance.
+func (s *Service) Refresh(ctx context.Context) error {
+	weights := s.Weights
+	if weights == (storage.ReputationWeights{}) {
+		weights = DefaultWeights
+	}
+	_, err := s.Store.RefreshReputation(ctx, weights, time.Now().Add(-window))
+	return err
+}
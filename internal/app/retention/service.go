@@ -0,0 +1,100 @@
+// Package retention deletes data that is older than the configured policy.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// batchSize is the number of rows deleted per store call.
+const batchSize = 1000
+
+// Policy says how long data is kept. A zero age keeps that data forever.
+type Policy struct {
+	DMMessageAge  time.Duration // Delete DM messages older than this
+	StaleSceneAge time.Duration // Delete empty scenes with no activity for this long
+}
+
+// Enabled reports whether the policy deletes anything.
+func (p Policy) Enabled() bool {
+	return p.DMMessageAge > 0 || p.StaleSceneAge > 0
+}
+
+// PolicyFromEnv reads RETENTION_DM_MESSAGE_DAYS and RETENTION_STALE_SCENE_DAYS.
+// Unset or "0" keeps that data forever.
+func PolicyFromEnv() (Policy, error) {
+	var p Policy
+	var err error
+	if p.DMMessageAge, err = daysFromEnv("RETENTION_DM_MESSAGE_DAYS"); err != nil {
+		return p, err
+	}
+	if p.StaleSceneAge, err = daysFromEnv("RETENTION_STALE_SCENE_DAYS"); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func daysFromEnv(name string) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative number of days, got %q", name, v)
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// Result counts what one cleanup pass deleted.
+type Result struct {
+	DMMessages  int64 `json:"dmMessages"`
+	StaleScenes int64 `json:"staleScenes"`
+}
+
+// Service applies a Policy.
+type Service struct {
+	Store  storage.RetentionStore
+	Policy Policy
+}
+
+// Clean deletes everything the policy has aged out as of now.
+func (s *Service) Clean(ctx context.Context) (Result, error) {
+	var result Result
+	now := time.Now()
+	var err error
+
+	if s.Policy.DMMessageAge > 0 {
+		result.DMMessages, err = drain(ctx, now.Add(-s.Policy.DMMessageAge), s.Store.DeleteDMMessagesBefore)
+		if err != nil {
+			return result, err
+		}
+	}
+	if s.Policy.StaleSceneAge > 0 {
+		result.StaleScenes, err = drain(ctx, now.Add(-s.Policy.StaleSceneAge), s.Store.DeleteStaleScenes)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// drain calls del in batches until it deletes less than a full batch.
+func drain(ctx context.Context, cutoff time.Time, del func(context.Context, time.Time, int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		n, err := del(ctx, cutoff, batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < batchSize || ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+	}
+}
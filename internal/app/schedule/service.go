@@ -0,0 +1,78 @@
+// Package schedule sends reminders for scheduled scenes and takes them live
+// once their start time arrives.
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/links"
+	"github.com/Vasu1712/scenyx-backend/internal/app/notify"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// DefaultReminderLead is used when Service.ReminderLead is zero.
+const DefaultReminderLead = 15 * time.Minute
+
+// Service finds scheduled scenes that are about to start or are due.
+type Service struct {
+	Scenes       storage.SceneStore // Claims due scenes and lists their RSVPs
+	Hub          *ws.Hub            // Delivers go-live events to the scene
+	Notify       *notify.Dispatcher // Delivers reminders and go-live alerts to RSVPs who have not turned them off
+	ReminderLead time.Duration      // How long before the start to remind RSVPs
+	Links        *links.Builder     // Forms the scene link included in notifications
+}
+
+// sceneNotice is a scene event sent to an individual user, with a link to
+// open the scene in the frontend.
+type sceneNotice struct {
+	*models.Scene
+	Link string `json:"link"`
+}
+
+// Tick sends due reminders, then starts due scenes. The store claims each
+// scene atomically, so overlapping ticks never notify twice.
+func (s *Service) Tick(ctx context.Context) error {
+	lead := s.ReminderLead
+	if lead <= 0 {
+		lead = DefaultReminderLead
+	}
+	reminders, err := s.Scenes.ClaimReminders(ctx, lead)
+	if err != nil {
+		return err
+	}
+	for _, scene := range reminders {
+		s.notifyRSVPs(ctx, scene, ws.TypeSceneReminder)
+		log.Printf("Sent reminders for scene %s starting at %s", scene.ID, scene.ScheduledAt)
+	}
+
+	started, err := s.Scenes.StartDueScenes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, scene := range started {
+		s.Hub.SendToScene(scene.ID, ws.TypeSceneLive, scene)
+		s.notifyRSVPs(ctx, scene, ws.TypeSceneLive)
+		log.Printf("Scene %s is now live", scene.ID)
+	}
+	return nil
+}
+
+// notifyRSVPs sends scene and its link as a t event to the users who RSVP'd
+// to it, unless they turned off scene-live alerts.
+func (s *Service) notifyRSVPs(ctx context.Context, scene *models.Scene, t ws.MessageType) {
+	userIDs, err := s.Scenes.GetRSVPs(ctx, scene.ID)
+	if err != nil {
+		log.Printf("Error loading RSVPs to notify for scene %s: %v", scene.ID, err)
+		return
+	}
+	s.Notify.Notify(ctx, notify.Notification{
+		Channel: models.ChannelSceneLive,
+		SceneID: scene.ID,
+		Type:    t,
+		Payload: sceneNotice{scene, s.Links.Scene(scene.ID)},
+	}, userIDs...)
+}
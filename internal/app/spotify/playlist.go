@@ -0,0 +1,173 @@
+package spotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+const (
+	apiURL = "https://api.spotify.com/v1"
+
+	// playlistScope lets Scenyx create private playlists for the user.
+	playlistScope = "playlist-modify-private"
+
+	// maxTracksPerRequest is the most tracks Spotify adds to a playlist in one call.
+	maxTracksPerRequest = 100
+)
+
+// errNotFound is returned by callAPI for a 404, e.g. a playlist the user deleted.
+var errNotFound = errors.New("spotify: not found")
+
+// trackURI returns the Spotify URI for a track ID, accepting bare IDs and
+// spotify:track: URIs. Tracks from other providers report false.
+func trackURI(trackID string) (string, bool) {
+	if strings.HasPrefix(trackID, "spotify:track:") {
+		return trackID, true
+	}
+	// Spotify IDs are 22 base-62 characters
+	if len(trackID) != 22 {
+		return "", false
+	}
+	for _, c := range trackID {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return "", false
+		}
+	}
+	return "spotify:track:" + trackID, true
+}
+
+// ExportPlaylist pushes a scene's playlist to userID's Spotify account. The
+// first export creates a private playlist; later ones append the tracks
+// played since. A playlist the user deleted on Spotify is recreated. Tracks
+// that are not on Spotify are left out.
+func (s *Service) ExportPlaylist(ctx context.Context, userID string, playlist *models.ScenePlaylist) (*models.SpotifyPlaylist, error) {
+	stored, err := s.store.GetSpotifyToken(ctx, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNotLinked
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(strings.Fields(stored.Scope), playlistScope) {
+		return nil, ErrMissingScope
+	}
+
+	token, err := s.GetAccessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var uris []string
+	for _, track := range playlist.Tracks {
+		if uri, ok := trackURI(track.TrackID); ok {
+			uris = append(uris, uri)
+		}
+	}
+
+	existing, err := s.store.GetScenePlaylist(ctx, playlist.SceneID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	// A playlist owned by a previously linked account cannot be written to
+	if existing != nil && existing.UserID == userID && existing.TrackCount <= len(uris) {
+		err = s.addTracks(ctx, token, existing.PlaylistID, uris[existing.TrackCount:])
+		if err == nil {
+			existing.TrackCount = len(uris)
+			return s.store.SaveScenePlaylist(ctx, existing)
+		}
+		// The user deleted the playlist on Spotify; make a new one
+		if !errors.Is(err, errNotFound) {
+			return nil, err
+		}
+	}
+
+	created, err := s.createPlaylist(ctx, token, playlist)
+	if err != nil {
+		return nil, err
+	}
+	created.SceneID = playlist.SceneID
+	created.UserID = userID
+	if err := s.addTracks(ctx, token, created.PlaylistID, uris); err != nil {
+		return nil, err
+	}
+	created.TrackCount = len(uris)
+	return s.store.SaveScenePlaylist(ctx, created)
+}
+
+// createPlaylist creates an empty private playlist in the user's account.
+func (s *Service) createPlaylist(ctx context.Context, token string, playlist *models.ScenePlaylist) (*models.SpotifyPlaylist, error) {
+	var created struct {
+		ID           string `json:"id"`
+		ExternalURLs struct {
+			Spotify string `json:"spotify"`
+		} `json:"external_urls"`
+	}
+	err := s.callAPI(ctx, token, http.MethodPost, "/me/playlists", map[string]any{
+		"name":        playlist.Name,
+		"description": playlist.Description,
+		"public":      false,
+	}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("create Spotify playlist for scene %s: %w", playlist.SceneID, err)
+	}
+	return &models.SpotifyPlaylist{PlaylistID: created.ID, URL: created.ExternalURLs.Spotify}, nil
+}
+
+// addTracks appends uris to a playlist, in batches Spotify accepts.
+func (s *Service) addTracks(ctx context.Context, token, playlistID string, uris []string) error {
+	for batch := range slices.Chunk(uris, maxTracksPerRequest) {
+		err := s.callAPI(ctx, token, http.MethodPost, "/playlists/"+playlistID+"/tracks", map[string]any{
+			"uris": batch,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callAPI sends a JSON request to the Spotify Web API and decodes the
+// response into out, if given.
+func (s *Service) callAPI(ctx context.Context, token, method, path string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("spotify %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("spotify %s %s failed: %s: %s", method, path, resp.Status, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode spotify %s %s response: %w", method, path, err)
+	}
+	return nil
+}
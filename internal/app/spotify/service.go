@@ -0,0 +1,301 @@
+// Package spotify links Scenyx users to their Spotify accounts and hands out
+// short-lived access tokens for calling the Spotify Web API.
+package spotify
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+const (
+	authorizeURL = "https://accounts.spotify.com/authorize"
+	tokenURL     = "https://accounts.spotify.com/api/token"
+
+	// Scopes needed to read the user's library, control their player, and
+	// export scenes to their playlists.
+	defaultScopes = "user-read-playback-state user-modify-playback-state user-read-currently-playing streaming playlist-modify-private"
+
+	// expiryMargin refreshes access tokens slightly before Spotify expires them.
+	expiryMargin = 30 * time.Second
+)
+
+var (
+	// ErrNotLinked is returned when the user has not connected a Spotify account.
+	ErrNotLinked = errors.New("spotify: account not linked")
+	// ErrInvalidState is returned when the OAuth state parameter fails verification.
+	ErrInvalidState = errors.New("spotify: invalid OAuth state")
+	// ErrMissingScope is returned when the user linked their account before
+	// Scenyx asked for a permission it now needs; relinking grants it.
+	ErrMissingScope = errors.New("spotify: account linked without the required scope")
+)
+
+// Config holds the Spotify application credentials.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	// TokenKey is the 32-byte AES-256 key used to encrypt refresh tokens at
+	// rest and to sign OAuth state values.
+	TokenKey []byte
+}
+
+// accessToken is a cached, decrypted Spotify access token.
+type accessToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Service exchanges OAuth codes, stores refresh tokens, and issues access tokens.
+type Service struct {
+	cfg    Config
+	store  storage.SpotifyTokenStore
+	aead   cipher.AEAD
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]accessToken // userID -> access token
+}
+
+// NewService creates a Service. cfg.TokenKey must be exactly 32 bytes.
+func NewService(cfg Config, store storage.SpotifyTokenStore) (*Service, error) {
+	if len(cfg.TokenKey) != 32 {
+		return nil, fmt.Errorf("spotify token key must be 32 bytes, got %d", len(cfg.TokenKey))
+	}
+	block, err := aes.NewCipher(cfg.TokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("create token cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create token cipher: %w", err)
+	}
+
+	return &Service{
+		cfg:    cfg,
+		store:  store,
+		aead:   aead,
+		client: &http.Client{Timeout: 10 * time.Second},
+		cache:  make(map[string]accessToken),
+	}, nil
+}
+
+// AuthorizeURL returns the Spotify consent page URL for userID. The state
+// parameter is signed so the callback can trust which user it belongs to.
+func (s *Service) AuthorizeURL(userID string) string {
+	q := url.Values{}
+	q.Set("client_id", s.cfg.ClientID)
+	q.Set("response_type", "code")
+	q.Set("redirect_uri", s.cfg.RedirectURI)
+	q.Set("scope", defaultScopes)
+	q.Set("state", s.signState(userID))
+	return authorizeURL + "?" + q.Encode()
+}
+
+// signState returns "<userID>.<base64 HMAC(userID)>".
+func (s *Service) signState(userID string) string {
+	mac := hmac.New(sha256.New, s.cfg.TokenKey)
+	mac.Write([]byte(userID))
+	return userID + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyState returns the user ID encoded in a state produced by signState.
+func (s *Service) verifyState(state string) (string, error) {
+	i := strings.LastIndexByte(state, '.')
+	if i <= 0 {
+		return "", ErrInvalidState
+	}
+	userID := state[:i]
+	if !hmac.Equal([]byte(s.signState(userID)), []byte(state)) {
+		return "", ErrInvalidState
+	}
+	return userID, nil
+}
+
+// tokenResponse is the JSON body returned by Spotify's token endpoint.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// ExchangeCode completes the OAuth flow: it verifies state, trades code for
+// tokens, and stores the encrypted refresh token. It returns the linked user ID.
+func (s *Service) ExchangeCode(ctx context.Context, code, state string) (string, error) {
+	userID, err := s.verifyState(state)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.cfg.RedirectURI)
+
+	tok, err := s.requestToken(ctx, form)
+	if err != nil {
+		return "", err
+	}
+	if tok.RefreshToken == "" {
+		return "", errors.New("spotify: token response missing refresh token")
+	}
+
+	if err := s.saveRefreshToken(ctx, userID, tok.RefreshToken, tok.Scope); err != nil {
+		return "", err
+	}
+	s.cacheAccessToken(userID, tok)
+	return userID, nil
+}
+
+// GetAccessToken returns a valid access token for userID, refreshing it with
+// the stored refresh token when the cached one is missing or about to expire.
+func (s *Service) GetAccessToken(ctx context.Context, userID string) (string, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	stored, err := s.store.GetSpotifyToken(ctx, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", ErrNotLinked
+	}
+	if err != nil {
+		return "", err
+	}
+
+	refreshToken, err := s.decrypt(stored.EncryptedRefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("decrypt refresh token for user %s: %w", userID, err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+
+	tok, err := s.requestToken(ctx, form)
+	if err != nil {
+		return "", err
+	}
+
+	// Spotify may rotate the refresh token; persist the new one if so
+	if tok.RefreshToken != "" && tok.RefreshToken != refreshToken {
+		scope := tok.Scope
+		if scope == "" {
+			scope = stored.Scope
+		}
+		if err := s.saveRefreshToken(ctx, userID, tok.RefreshToken, scope); err != nil {
+			return "", err
+		}
+	}
+
+	s.cacheAccessToken(userID, tok)
+	return tok.AccessToken, nil
+}
+
+// IsLinked reports whether userID has connected a Spotify account.
+func (s *Service) IsLinked(ctx context.Context, userID string) (bool, error) {
+	_, err := s.store.GetSpotifyToken(ctx, userID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Unlink removes the user's stored Spotify credentials.
+func (s *Service) Unlink(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	delete(s.cache, userID)
+	s.mu.Unlock()
+	return s.store.DeleteSpotifyToken(ctx, userID)
+}
+
+// requestToken posts form to Spotify's token endpoint using client credentials.
+func (s *Service) requestToken(ctx context.Context, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.cfg.ClientID, s.cfg.ClientSecret)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("spotify token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("spotify token request failed: %s: %s", resp.Status, body)
+	}
+
+	var tok tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return nil, fmt.Errorf("decode spotify token response: %w", err)
+	}
+	return &tok, nil
+}
+
+// saveRefreshToken encrypts and persists a refresh token.
+func (s *Service) saveRefreshToken(ctx context.Context, userID, refreshToken, scope string) error {
+	encrypted, err := s.encrypt(refreshToken)
+	if err != nil {
+		return fmt.Errorf("encrypt refresh token for user %s: %w", userID, err)
+	}
+	return s.store.SaveSpotifyToken(ctx, &models.SpotifyToken{
+		UserID:                userID,
+		EncryptedRefreshToken: encrypted,
+		Scope:                 scope,
+	})
+}
+
+// cacheAccessToken remembers tok until shortly before it expires.
+func (s *Service) cacheAccessToken(userID string, tok *tokenResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[userID] = accessToken{
+		value:     tok.AccessToken,
+		expiresAt: time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - expiryMargin),
+	}
+}
+
+// encrypt seals plaintext with AES-GCM, prefixing the random nonce.
+func (s *Service) encrypt(plaintext string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// decrypt opens a value produced by encrypt.
+func (s *Service) decrypt(ciphertext []byte) (string, error) {
+	n := s.aead.NonceSize()
+	if len(ciphertext) < n {
+		return "", errors.New("ciphertext too short")
+	}
+	plaintext, err := s.aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,63 @@
+// Package tipping sends tips and virtual gifts to scene hosts, paid from the
+// sender's credit balance or through Stripe, and shows them in the scene.
+package tipping
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/links"
+	"github.com/Vasu1712/scenyx-backend/internal/app/payments"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+var (
+	// ErrInsufficientCredits is returned when a gift costs more than the
+	// sender's credit balance.
+	ErrInsufficientCredits = errors.New("tipping: insufficient credits")
+	// ErrStripeDisabled is returned for Stripe gifts when Stripe is not configured.
+	ErrStripeDisabled = errors.New("tipping: stripe payments are not enabled")
+)
+
+// Service sends gifts.
+type Service struct {
+	Store    storage.GiftStore // The gifts ledger and credit balances
+	Payments *payments.Service // Stripe Checkout; nil when Stripe is not configured
+	Hub      *ws.Hub           // Plays gift.received in the scene
+	Links    *links.Builder    // Forms the pages Stripe returns senders to
+}
+
+// Send charges gift to its sender by gift.Method. A credits gift is
+// delivered at once and returned completed. A Stripe gift is returned
+// pending with the CheckoutURL to pay at, and is delivered when Stripe
+// reports the payment.
+func (s *Service) Send(ctx context.Context, scene *models.Scene, gift *models.Gift) (*models.Gift, error) {
+	switch gift.Method {
+	case models.GiftStripe:
+		if s.Payments == nil {
+			return nil, ErrStripeDisabled
+		}
+		return s.Payments.CreateGiftCheckout(ctx, gift, scene.Name,
+			s.Links.SceneCheckout(scene.ID, "success"), s.Links.SceneCheckout(scene.ID, "cancelled"))
+	default:
+		sent, err := s.Store.SendGiftWithCredits(ctx, gift)
+		if errors.Is(err, storage.ErrConflict) {
+			return nil, ErrInsufficientCredits
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.Deliver(sent)
+		return sent, nil
+	}
+}
+
+// Deliver sends a completed gift to its scene's clients as gift.received,
+// which they show with the animation named by its kind.
+func (s *Service) Deliver(gift *models.Gift) {
+	s.Hub.SendToScene(gift.SceneID, ws.TypeGiftReceived, gift)
+	log.Printf("Gift %s (%s, %d %s) from %s delivered in scene %s", gift.ID, gift.Kind, gift.AmountCents, gift.Currency, gift.SenderID, gift.SceneID)
+}
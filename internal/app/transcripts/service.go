@@ -0,0 +1,158 @@
+// Package transcripts exports a scene's chat and track history as JSON or
+// CSV, inline for small scenes and through a background job for large ones.
+package transcripts
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/app/uploads"
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/Vasu1712/scenyx-backend/internal/ws"
+)
+
+// InlineLimit is the most chat messages a scene may have for its transcript
+// to be generated during the request. Larger scenes are exported in the
+// background.
+const InlineLimit = 2000
+
+// Service builds scene transcripts and works through queued exports.
+type Service struct {
+	Store    storage.TranscriptStore
+	Scenes   storage.SceneStore
+	Playback storage.PlaybackStore
+	Blobs    uploads.Blobs // Where background exports are stored for download
+	Hub      *ws.Hub       // Tells the host when their export is done
+}
+
+// Write writes the transcript of a scene to w in format.
+func (s *Service) Write(ctx context.Context, w io.Writer, scene *models.Scene, format models.TranscriptFormat) error {
+	messages, err := s.Scenes.GetSceneMessages(ctx, scene.ID)
+	if err != nil {
+		return err
+	}
+	tracks, err := s.Playback.GetPlayHistory(ctx, scene.ID)
+	if err != nil {
+		return err
+	}
+	t := models.SceneTranscript{Scene: scene, Messages: messages, Tracks: tracks, ExportedAt: time.Now().UTC()}
+	if t.Messages == nil {
+		t.Messages = []models.SceneMessage{}
+	}
+	if t.Tracks == nil {
+		t.Tracks = []models.PlayedTrack{}
+	}
+
+	if format == models.TranscriptCSV {
+		return writeCSV(w, &t)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// writeCSV writes one row per message and per track start, merged in time
+// order. Message rows leave the track columns empty and vice versa.
+func writeCSV(w io.Writer, t *models.SceneTranscript) error {
+	type row struct {
+		at     time.Time
+		fields []string
+	}
+	rows := make([]row, 0, len(t.Messages)+len(t.Tracks))
+	for _, m := range t.Messages {
+		rows = append(rows, row{m.CreatedAt, []string{"message", m.SenderID, m.Content, "", "", ""}})
+	}
+	for _, p := range t.Tracks {
+		rows = append(rows, row{p.PlayedAt, []string{"track", p.PlayedBy, "", p.TrackID, p.Title, p.Artist}})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].at.Before(rows[j].at) })
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "type", "user_id", "content", "track_id", "title", "artist"})
+	for _, r := range rows {
+		cw.Write(append([]string{r.at.UTC().Format(time.RFC3339)}, r.fields...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// URL returns where a ready export can be downloaded.
+func (s *Service) URL(export *models.TranscriptExport) string {
+	if export.BlobKey == "" {
+		return ""
+	}
+	return s.Blobs.URL(export.BlobKey)
+}
+
+// Process generates every waiting export, stores each file, and sends the
+// host a ws.TypeTranscriptReady event with the result. It is intended to run
+// as a scheduled job.
+func (s *Service) Process(ctx context.Context) error {
+	for ctx.Err() == nil {
+		export, err := s.Store.ClaimTranscriptExport(ctx)
+		if err != nil {
+			return err
+		}
+		if export == nil {
+			return nil
+		}
+
+		key, exportErr := s.generate(ctx, export)
+		errText := ""
+		if exportErr != nil {
+			log.Printf("Error generating transcript export %s of scene %s: %v", export.ID, export.SceneID, exportErr)
+			errText = "Transcript could not be generated"
+		}
+		completed, err := s.Store.CompleteTranscriptExport(ctx, export.ID, key, errText)
+		if errors.Is(err, storage.ErrNotFound) {
+			// The scene ended as ephemeral, or was deleted, while this ran
+			s.discard(ctx, export, key)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		export = completed
+		export.DownloadURL = s.URL(export)
+		s.Hub.SendToUser(export.RequestedBy, ws.TypeTranscriptReady, export)
+		log.Printf("Transcript export %s of scene %s %s", export.ID, export.SceneID, export.Status)
+	}
+	return ctx.Err()
+}
+
+// discard deletes the file stored for an export whose row is gone.
+func (s *Service) discard(ctx context.Context, export *models.TranscriptExport, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.Blobs.Delete(ctx, key); err != nil {
+		log.Printf("Error deleting transcript export %s of removed scene %s: %v", export.ID, export.SceneID, err)
+	}
+}
+
+// generate builds an export's transcript and stores it, returning its key.
+func (s *Service) generate(ctx context.Context, export *models.TranscriptExport) (string, error) {
+	scene, err := s.Scenes.GetScene(ctx, export.SceneID)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := s.Write(ctx, &buf, scene, export.Format); err != nil {
+		return "", err
+	}
+	key := path.Join("transcripts", export.ID, fmt.Sprintf("scene-%s.%s", export.SceneID, export.Format))
+	if err := s.Blobs.Put(ctx, key, export.Format.ContentType(), &buf, int64(buf.Len())); err != nil {
+		return "", err
+	}
+	return key, nil
+}
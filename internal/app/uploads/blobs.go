@@ -0,0 +1,173 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxAvatarSize is the largest image accepted as a user avatar.
+const MaxAvatarSize = 5 << 20 // 5 MiB
+
+// avatarTypes lists the content types accepted as avatars.
+var avatarTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// IsAvatarType reports whether contentType is accepted as an avatar.
+func IsAvatarType(contentType string) bool {
+	return avatarTypes[contentType]
+}
+
+// Blobs stores files that are uploaded through the API server rather than
+// directly by the client.
+type Blobs interface {
+	// Put stores size bytes read from body under key.
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	// Delete removes the object at key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns where clients can fetch the object at key.
+	URL(key string) string
+}
+
+var (
+	_ Blobs = (*Service)(nil)
+	_ Blobs = (*DiskStore)(nil)
+)
+
+// blobRequestTimeout bounds a server-side request to the bucket.
+const blobRequestTimeout = 30 * time.Second
+
+// Put uploads body to key in the bucket using a pre-signed PUT.
+func (s *Service) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	upload := s.PresignPut(key, contentType, size)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, upload.URL, body)
+	if err != nil {
+		return fmt.Errorf("uploads: build PUT for %s: %w", key, err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	return s.do(req, key)
+}
+
+// Delete removes key from the bucket using a pre-signed DELETE.
+func (s *Service) Delete(ctx context.Context, key string) error {
+	u := s.presign(http.MethodDelete, key, nil, uploadURLExpiry, s.now().UTC())
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return fmt.Errorf("uploads: build DELETE for %s: %w", key, err)
+	}
+	return s.do(req, key)
+}
+
+// do sends a signed request to the bucket and checks the response status.
+func (s *Service) do(req *http.Request, key string) error {
+	client := &http.Client{Timeout: blobRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("uploads: %s %s: %w", req.Method, key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("uploads: %s %s: %s: %s", req.Method, key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// DiskStore keeps files in a local directory, for development and
+// single-instance deployments without object storage.
+type DiskStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewDiskStore creates a DiskStore rooted at dir, creating it if needed.
+// URLs are formed by appending object keys to baseURL, where Handler is
+// expected to be mounted.
+func NewDiskStore(dir, baseURL string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("uploads: create %s: %w", dir, err)
+	}
+	return &DiskStore{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// Put writes body to key, replacing any existing file atomically.
+func (d *DiskStore) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	name, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("uploads: create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("uploads: create %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	n, err := io.Copy(tmp, io.LimitReader(body, size))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("uploads: write %s: %w", key, err)
+	}
+	if n != size {
+		return fmt.Errorf("uploads: write %s: got %d of %d bytes", key, n, size)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("uploads: store %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the file at key.
+func (d *DiskStore) Delete(ctx context.Context, key string) error {
+	name, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("uploads: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// URL returns the URL Handler serves key at.
+func (d *DiskStore) URL(key string) string {
+	return d.baseURL + "/" + escapePath(key)
+}
+
+// Handler serves the stored files. Directory listings are not exposed.
+func (d *DiskStore) Handler() http.Handler {
+	files := http.FileServer(http.Dir(d.dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// path maps key to a file under the store's directory, rejecting keys that
+// would escape it.
+func (d *DiskStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, ".."+string(filepath.Separator)) || clean == ".." {
+		return "", fmt.Errorf("uploads: invalid key %q", key)
+	}
+	return filepath.Join(d.dir, clean), nil
+}
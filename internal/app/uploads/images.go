@@ -0,0 +1,129 @@
+package uploads
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Register the GIF decoder
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder
+	"io"
+	"path"
+)
+
+// MaxCoverSize is the largest image accepted as a scene cover.
+const MaxCoverSize = 10 << 20 // 10 MiB
+
+// maxCoverPixels bounds the decoded size of a cover, so a small file cannot
+// expand into an image too large to hold in memory.
+const maxCoverPixels = 40_000_000
+
+// coverQuality is the JPEG quality covers are stored at.
+const coverQuality = 85
+
+// CoverSizes maps each size a scene cover is stored at to its width and
+// height in pixels. The names match models.SceneCoverImages.
+var CoverSizes = map[string]int{
+	"small":  160,
+	"medium": 320,
+	"large":  640,
+}
+
+// coverTypes lists the content types accepted as covers. WebP is left out
+// because the standard library cannot decode it for resizing.
+var coverTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// ErrInvalidImage is returned when an upload cannot be decoded as an image
+// or is too large to process.
+var ErrInvalidImage = errors.New("uploads: invalid image")
+
+// IsCoverType reports whether contentType is accepted as a scene cover.
+func IsCoverType(contentType string) bool {
+	return coverTypes[contentType]
+}
+
+// CoverKey returns the object key of a cover stored under key at size.
+func CoverKey(key, size string) string {
+	return path.Join(key, size+".jpg")
+}
+
+// ResizeCover decodes an image, crops it to a centered square, and encodes
+// it as a JPEG at each of CoverSizes.
+func ResizeCover(r io.Reader) (map[string][]byte, error) {
+	var buf bytes.Buffer
+	config, format, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxCoverPixels {
+		return nil, fmt.Errorf("%w: %s is %dx%d", ErrInvalidImage, format, config.Width, config.Height)
+	}
+	src, _, err := image.Decode(io.MultiReader(&buf, r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	// Crop to the largest centered square and copy it into RGBA so the
+	// scaler can read pixels directly
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side)
+	square := image.NewRGBA(crop)
+	offset := image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2)
+	draw.Draw(square, crop, src, offset, draw.Src)
+
+	resized := make(map[string][]byte, len(CoverSizes))
+	for name, px := range CoverSizes {
+		var out bytes.Buffer
+		if err := jpeg.Encode(&out, scaleSquare(square, px), &jpeg.Options{Quality: coverQuality}); err != nil {
+			return nil, fmt.Errorf("uploads: encode %s cover: %w", name, err)
+		}
+		resized[name] = out.Bytes()
+	}
+	return resized, nil
+}
+
+// scaleSquare resizes a square image to n×n by averaging the source pixels
+// that fall under each destination pixel. Upscaling repeats pixels.
+func scaleSquare(src *image.RGBA, n int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		y0, y1 := span(y, side, n)
+		for x := 0; x < n; x++ {
+			x0, x1 := span(x, side, n)
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					count++
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/count), uint8(g/count), uint8(b/count), uint8(a/count)
+		}
+	}
+	return dst
+}
+
+// span returns the range of source pixels under destination pixel i when
+// scaling side pixels to n. The range is never empty.
+func span(i, side, n int) (int, int) {
+	lo := i * side / n
+	hi := (i + 1) * side / n
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
@@ -0,0 +1,55 @@
+package uploads
+
+import (
+	"context"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// sweepBatch is the number of orphaned files loaded per store call.
+const sweepBatch = 100
+
+// Sweeper deletes the stored files whose rows were deleted, such as the
+// attachments and transcript exports of an ephemeral scene that ended.
+type Sweeper struct {
+	Store   storage.OrphanBlobStore
+	Buckets map[string]Blobs // Blob store for each models.Bucket* name
+}
+
+// Sweep deletes every queued file and removes it from the queue. A file in a
+// bucket with no blob store configured cannot exist, so it is dropped. It
+// stops at the first batch with a failed delete, leaving that file queued
+// for the next run. It is intended to run as a scheduled job.
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	for ctx.Err() == nil {
+		blobs, err := s.Store.PendingOrphanBlobs(ctx, sweepBatch)
+		if err != nil {
+			return err
+		}
+		if len(blobs) == 0 {
+			return nil
+		}
+
+		var done []int64
+		var deleteErr error
+		for _, b := range blobs {
+			store := s.Buckets[b.Bucket]
+			if store == nil {
+				log.Printf("No blob store for bucket %s; dropping orphaned file %s", b.Bucket, b.Key)
+			} else if err := store.Delete(ctx, b.Key); err != nil {
+				log.Printf("Error deleting orphaned file %s from bucket %s: %v", b.Key, b.Bucket, err)
+				deleteErr = err
+				continue
+			}
+			done = append(done, b.ID)
+		}
+		if err := s.Store.ForgetOrphanBlobs(ctx, done); err != nil {
+			return err
+		}
+		if deleteErr != nil {
+			return deleteErr
+		}
+	}
+	return ctx.Err()
+}
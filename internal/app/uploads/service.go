@@ -0,0 +1,266 @@
+// Package uploads issues pre-signed URLs for uploading files directly to an
+// S3-compatible bucket and for reading them back.
+package uploads
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxAttachmentSize is the largest file accepted for a message attachment.
+	MaxAttachmentSize = 25 << 20 // 25 MiB
+
+	// uploadURLExpiry is how long a pre-signed PUT URL stays valid.
+	uploadURLExpiry = 15 * time.Minute
+	// downloadURLExpiry is how long a pre-signed GET URL stays valid.
+	downloadURLExpiry = time.Hour
+)
+
+var (
+	// ErrUnsupportedType is returned when the content type is not allowed.
+	ErrUnsupportedType = errors.New("uploads: unsupported content type")
+	// ErrTooLarge is returned when the declared size exceeds the limit.
+	ErrTooLarge = errors.New("uploads: file too large")
+)
+
+// attachmentTypes lists the content types accepted as message attachments.
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"audio/mpeg":      true,
+	"video/mp4":       true,
+	"application/pdf": true,
+}
+
+// Config holds the bucket location and credentials.
+type Config struct {
+	// Endpoint is the S3 API base URL, e.g. https://s3.us-east-1.amazonaws.com
+	// or a MinIO/R2 endpoint. Objects are addressed path-style.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicBaseURL, if set, serves objects without signing (e.g. a CDN in
+	// front of a public bucket); object keys are appended to it.
+	PublicBaseURL string
+}
+
+// Upload describes a pre-signed PUT the client performs to store a file.
+type Upload struct {
+	Key       string            // Object key the file will be stored under
+	URL       string            // Pre-signed PUT URL
+	Headers   map[string]string // Headers the client must send with the PUT
+	ExpiresAt time.Time         // When URL stops being accepted
+}
+
+// Service signs requests against one bucket.
+type Service struct {
+	cfg      Config
+	endpoint *url.URL
+	now      func() time.Time
+}
+
+// NewService creates a Service. Endpoint defaults to AWS S3 in cfg.Region.
+func NewService(cfg Config) (*Service, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("uploads: bucket and credentials are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("uploads: invalid endpoint %q", cfg.Endpoint)
+	}
+	cfg.PublicBaseURL = strings.TrimRight(cfg.PublicBaseURL, "/")
+	return &Service{cfg: cfg, endpoint: endpoint, now: time.Now}, nil
+}
+
+// PresignAttachment validates an attachment's declared type and size and
+// returns a pre-signed PUT for it under a fresh key in prefix.
+func (s *Service) PresignAttachment(prefix, filename, contentType string, size int64) (*Upload, error) {
+	if !attachmentTypes[contentType] {
+		return nil, ErrUnsupportedType
+	}
+	if size <= 0 || size > MaxAttachmentSize {
+		return nil, ErrTooLarge
+	}
+	return s.PresignPut(NewKey(prefix, filename), contentType, size), nil
+}
+
+// PresignPut returns a pre-signed PUT for key. The content type and length
+// are part of the signature, so the client must upload exactly what it declared.
+func (s *Service) PresignPut(key, contentType string, size int64) *Upload {
+	headers := map[string]string{
+		"Content-Type":   contentType,
+		"Content-Length": strconv.FormatInt(size, 10),
+	}
+	now := s.now().UTC()
+	return &Upload{
+		Key:       key,
+		URL:       s.presign("PUT", key, headers, uploadURLExpiry, now),
+		Headers:   headers,
+		ExpiresAt: now.Add(uploadURLExpiry),
+	}
+}
+
+// URL returns a URL the object at key can be downloaded from: a public URL
+// when PublicBaseURL is configured, otherwise a pre-signed GET.
+func (s *Service) URL(key string) string {
+	if s.cfg.PublicBaseURL != "" {
+		return s.cfg.PublicBaseURL + "/" + escapePath(key)
+	}
+	return s.presign("GET", key, nil, downloadURLExpiry, s.now().UTC())
+}
+
+// NewKey returns a unique object key under prefix that keeps a sanitized
+// form of filename so downloads get a sensible name.
+func NewKey(prefix, filename string) string {
+	name := sanitizeFilename(filename)
+	if name == "" {
+		name = "file"
+	}
+	return path.Join(prefix, uuid.NewString(), name)
+}
+
+// sanitizeFilename keeps the base name and replaces characters that are
+// awkward in URLs and object keys.
+func sanitizeFilename(filename string) string {
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() > 128 {
+		return b.String()[b.Len()-128:]
+	}
+	return b.String()
+}
+
+// presign builds an AWS Signature Version 4 query-string signed URL for a
+// path-style request to key. headers are included in the signature.
+func (s *Service) presign(method, key string, headers map[string]string, expiry time.Duration, now time.Time) string {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+
+	canonicalURI := strings.TrimRight(s.endpoint.EscapedPath(), "/") + "/" + escapePath(s.cfg.Bucket) + "/" + escapePath(key)
+
+	signed := map[string]string{"host": s.endpoint.Host}
+	for k, v := range headers {
+		signed[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	names := make([]string, 0, len(signed))
+	for k := range signed {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + signed[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.cfg.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	canonicalQuery := canonicalQueryString(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.cfg.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return s.endpoint.Scheme + "://" + s.endpoint.Host + canonicalURI + "?" + canonicalQuery + "&X-Amz-Signature=" + signature
+}
+
+// canonicalQueryString encodes q sorted by key using AWS URI encoding.
+func canonicalQueryString(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, awsEscape(k)+"="+awsEscape(q.Get(k)))
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapePath URI-encodes each segment of p, keeping the slashes.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		segments[i] = awsEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsEscape percent-encodes everything except the RFC 3986 unreserved characters.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 returns HMAC-SHA256(key, data).
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
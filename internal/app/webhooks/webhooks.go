@@ -0,0 +1,207 @@
+// Package webhooks queues scene and DM events for integrators' webhooks and
+// delivers them as signed JSON POSTs, retrying failures with backoff.
+//
+// Each request carries these headers:
+//
+//	X-Scenyx-Event:     the event name, e.g. "message.sent"
+//	X-Scenyx-Delivery:  the delivery ID, stable across retries
+//	X-Scenyx-Timestamp: Unix seconds when the request was signed
+//	X-Scenyx-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// Receivers should recompute the signature and reject stale timestamps.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+// Delivery tuning.
+const (
+	MaxAttempts  = 8                // Attempts before a delivery is marked failed
+	batchSize    = 50               // Deliveries claimed per pass
+	claimLease   = 2 * time.Minute  // How long a claimed delivery is hidden from other senders
+	baseBackoff  = 30 * time.Second // Delay before the first retry, doubled each attempt
+	maxBackoff   = 6 * time.Hour
+	enqueueLimit = 5 * time.Second // Bounds queuing an event
+	sendWorkers  = 8
+)
+
+// Envelope is the JSON body POSTed to webhooks.
+type Envelope struct {
+	ID        string    `json:"id"` // Event ID, shared by every webhook's delivery
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// MessageSent is the data of a models.EventMessageSent event. Scene messages
+// go to the scene's webhooks and global ones; DM messages only to global ones.
+type MessageSent struct {
+	Type    string `json:"type"` // models.MessageTypeDM or models.MessageTypeScene
+	DMID    string `json:"dmID,omitempty"`
+	SceneID string `json:"sceneID,omitempty"`
+	Message any    `json:"message"`
+}
+
+// UserJoined is the data of a models.EventUserJoined event.
+type UserJoined struct {
+	SceneID string `json:"sceneID"`
+	UserID  string `json:"userID"`
+}
+
+// Dispatcher queues events and delivers them.
+type Dispatcher struct {
+	Store  storage.WebhookStore
+	Client *http.Client // Should have a timeout; http.DefaultClient if nil
+}
+
+// NewSecret returns a random signing secret for a new webhook.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Emit queues event for the subscribed webhooks: global ones and, when
+// sceneID is set, that scene's. It does not block the caller; failures are
+// logged. Emit on a nil Dispatcher does nothing.
+func (d *Dispatcher) Emit(event, sceneID string, data any) {
+	if d == nil {
+		return
+	}
+	envelope := Envelope{ID: uuid.NewString(), Event: event, CreatedAt: time.Now().UTC(), Data: data}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		log.Printf("Error encoding %s webhook payload: %v", event, err)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), enqueueLimit)
+		defer cancel()
+		if _, err := d.Store.EnqueueEvent(ctx, envelope.ID, event, sceneID, payload); err != nil {
+			log.Printf("Error queuing %s webhook event %s: %v", event, envelope.ID, err)
+		}
+	}()
+}
+
+// Deliver sends the deliveries that are due. It is meant to run as a
+// recurring job; retries are scheduled in the store, not in memory.
+func (d *Dispatcher) Deliver(ctx context.Context) error {
+	for {
+		deliveries, err := d.Store.ClaimDeliveries(ctx, batchSize, claimLease)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		work := make(chan models.WebhookDelivery)
+		for i := 0; i < sendWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for delivery := range work {
+					d.attempt(ctx, delivery)
+				}
+			}()
+		}
+		for _, delivery := range deliveries {
+			work <- delivery
+		}
+		close(work)
+		wg.Wait()
+
+		if len(deliveries) < batchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome.
+func (d *Dispatcher) attempt(ctx context.Context, delivery models.WebhookDelivery) {
+	statusCode, err := d.send(ctx, delivery)
+
+	var errMsg string
+	var retryAt *time.Time
+	if err != nil {
+		errMsg = err.Error()
+		if delivery.Attempts+1 < MaxAttempts {
+			at := time.Now().Add(backoff(delivery.Attempts))
+			retryAt = &at
+		} else {
+			log.Printf("Webhook delivery %s to %s failed after %d attempts: %v", delivery.ID, delivery.URL, MaxAttempts, err)
+		}
+	}
+	if err := d.Store.RecordAttempt(ctx, delivery.ID, statusCode, errMsg, retryAt); err != nil {
+		log.Printf("Error recording webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// backoff returns the delay before retrying after attempts failed attempts.
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff << attempts
+	if delay <= 0 || delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// send POSTs a signed delivery. Any non-2xx response is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Scenyx-Webhooks/1")
+	req.Header.Set("X-Scenyx-Event", delivery.Event)
+	req.Header.Set("X-Scenyx-Delivery", delivery.ID)
+	req.Header.Set("X-Scenyx-Timestamp", timestamp)
+	req.Header.Set("X-Scenyx-Signature", Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16)) // Drain so the connection can be reused
+
+	code := resp.StatusCode
+	if code < 200 || code > 299 {
+		return &code, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return &code, nil
+}
+
+// Sign returns the X-Scenyx-Signature value for body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,13 @@
+package middleware
+
+import "net/http"
+
+// Deprecated marks responses from a route that has been superseded, so
+// clients can find and move to successor, the path template that replaces it.
+func Deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+">; rel=\"successor-version\"")
+		next(w, r)
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+// TableRows is the number of rows an operation affected in one table.
+type TableRows struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+}
+
+// PlatformStats are instance-wide totals for operators.
+type PlatformStats struct {
+	Users           int64 `json:"users"`
+	LiveScenes      int64 `json:"liveScenes"`
+	ScheduledScenes int64 `json:"scheduledScenes"`
+	ArchivedScenes  int64 `json:"archivedScenes"`
+	Conversations   int64 `json:"conversations"`
+	DMMessages      int64 `json:"dmMessages"`
+	SceneMessages   int64 `json:"sceneMessages"`
+	Attachments     int64 `json:"attachments"`
+	OpenReports     int64 `json:"openReports"`
+	ActiveListeners int64 `json:"activeListeners"` // Sum of every scene's last sampled active_users
+}
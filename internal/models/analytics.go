@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// AnalyticsBucket is the width of one point in a listener time series.
+type AnalyticsBucket string
+
+const (
+	BucketHour AnalyticsBucket = "hour"
+	BucketDay  AnalyticsBucket = "day"
+)
+
+// ListenerCount is the number of distinct users who listened to a scene
+// at some point during the bucket starting at Time.
+type ListenerCount struct {
+	Time      time.Time `json:"time"`
+	Listeners int       `json:"listeners"`
+}
+
+// SceneAnalytics summarizes who listened to a scene between From and To.
+type SceneAnalytics struct {
+	SceneID            string          `json:"sceneID"`
+	From               time.Time       `json:"from"`
+	To                 time.Time       `json:"to"`
+	Bucket             AnalyticsBucket `json:"bucket"`
+	Listeners          []ListenerCount `json:"listeners"`          // Listener counts over time, oldest first
+	UniqueListeners    int             `json:"uniqueListeners"`    // Distinct users who listened in the window
+	Sessions           int             `json:"sessions"`           // Listening sessions overlapping the window
+	AvgSessionSeconds  float64         `json:"avgSessionSeconds"`  // Mean length of the finished sessions
+	ReturningListeners int             `json:"returningListeners"` // Listeners with more than one session
+	ReturningRate      float64         `json:"returningRate"`      // ReturningListeners / UniqueListeners
+}
+
+// SceneDayStats is the concurrent listener count of a scene over one UTC day,
+// sampled periodically while anyone was listening.
+type SceneDayStats struct {
+	Day           string     `json:"day"`           // YYYY-MM-DD
+	PeakListeners int        `json:"peakListeners"` // Highest sampled concurrency
+	PeakAt        *time.Time `json:"peakAt,omitempty"`
+	AvgListeners  float64    `json:"avgListeners"` // Mean concurrency across samples
+	Samples       int        `json:"samples"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Attachment is a file uploaded to object storage and sent with a message.
+type Attachment struct {
+	ID          string    `json:"id"`   // Unique identifier for the attachment (UUID)
+	Key         string    `json:"-"`    // Object key in the bucket
+	UploaderID  string    `json:"-"`    // The ID of the user who uploaded the file
+	Name        string    `json:"name"` // Original file name
+	ContentType string    `json:"type"` // MIME type declared at upload
+	Size        int64     `json:"size"` // Size in bytes
+	URL         string    `json:"url"`  // Where clients download the file from
+	CreatedAt   time.Time `json:"-"`    // Timestamp when the upload URL was issued
+}
+
+// AttachmentURL is the API path that redirects to the stored file.
+func AttachmentURL(id string) string {
+	return "/api/v1/uploads/file?id=" + id
+}
+
+// Buckets of orphaned blobs, naming the blob store that holds the file.
+const (
+	BucketAttachments = "attachments" // Message attachments, in the uploads bucket
+	BucketTranscripts = "transcripts" // Background transcript exports
+)
+
+// OrphanBlob is a stored file whose row was deleted, waiting for the file
+// itself to be removed from its bucket.
+type OrphanBlob struct {
+	ID     int64
+	Bucket string
+	Key    string
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Badge names an achievement a user can earn once.
+type Badge string
+
+// Badges awarded by the achievements engine.
+const (
+	BadgeFirstScene   Badge = "first-scene"   // Created a scene
+	BadgeHundredCrowd Badge = "hundred-crowd" // Hosted a scene 100 different listeners joined
+	BadgeWeeklyStreak Badge = "weekly-streak" // Listened on 7 consecutive days
+)
+
+// BadgeInfo describes a badge for clients to display.
+type BadgeInfo struct {
+	Badge       Badge  `json:"badge"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Badges lists every badge, in the order clients show them.
+var Badges = []BadgeInfo{
+	{BadgeFirstScene, "Opening Night", "Created their first scene"},
+	{BadgeHundredCrowd, "Crowd Puller", "Hosted a scene 100 listeners joined"},
+	{BadgeWeeklyStreak, "Regular", "Listened to scenes 7 days in a row"},
+}
+
+// UserBadge is a badge a user earned.
+type UserBadge struct {
+	BadgeInfo
+	UserID    string    `json:"userID"`
+	SceneID   string    `json:"sceneID,omitempty"` // Scene that earned the badge, if any
+	AwardedAt time.Time `json:"awardedAt"`
+}
+
+// Info returns the entry of b in Badges, or false if b is not a known badge.
+func (b Badge) Info() (BadgeInfo, bool) {
+	for _, info := range Badges {
+		if info.Badge == b {
+			return info, true
+		}
+	}
+	return BadgeInfo{}, false
+}
@@ -8,11 +8,69 @@ type DMMessage struct {
     SenderID       string    `json:"sender_id"`
     Content        string    `json:"content"`
     Timestamp      time.Time `json:"timestamp"`
+    ParentMessageID *string  `json:"parent_message_id,omitempty"` // Set on thread replies; points at the thread's top-level message
+    ReplyCount     int       `json:"reply_count"`                 // Number of replies in the thread this message starts
+    EditedAt       *time.Time `json:"edited_at,omitempty"`  // Set when the sender edited the message
+    DeletedAt      *time.Time `json:"deleted_at,omitempty"` // Set when the sender deleted the message; Content is then empty
+    ExpiresAt      *time.Time `json:"expires_at,omitempty"` // Set when the conversation had a message TTL; the message is deleted after it
+    KeyVersion     *int       `json:"key_version,omitempty"` // Set on end-to-end encrypted messages: Content is ciphertext sealed with this version of the conversation key
+    Reactions      []ReactionCount `json:"reactions,omitempty"` // Reaction counts, in order of first use
+    Attachments    []Attachment    `json:"attachments,omitempty"` // Files sent with the message
+    Mentions       []Mention       `json:"mentions,omitempty"`    // Users mentioned with @username, in order of appearance
+    Receipts       []DMReceipt     `json:"receipts,omitempty"`    // Recipients the message was delivered to, in order of delivery
 }
 
+// DMThread is a top-level message together with its replies in chronological order.
+type DMThread struct {
+    Parent  DMMessage   `json:"parent"`
+    Replies []DMMessage `json:"replies"`
+}
+
+// DMSearchResult is a message matching a search together with the messages
+// immediately before and after it in the same conversation and thread.
+type DMSearchResult struct {
+    DMConversationID string      `json:"dm_conversation_id"`
+    Message          DMMessage   `json:"message"`
+    Before           []DMMessage `json:"before"` // Oldest first
+    After            []DMMessage `json:"after"`  // Oldest first
+}
+
+// DMConversation is either a one-to-one DM or a named group with any number of participants.
 type DMConversation struct {
     ID             string    `json:"id"`
-    Participants   [2]string `json:"participants"`
+    Name           string    `json:"name,omitempty"`
+    IsGroup        bool      `json:"is_group"`
+    CreatorID      string    `json:"creator_id,omitempty"` // Who created a group conversation and may remove its members; empty for one-to-one conversations and older groups
+    WorkspaceID    string    `json:"workspace_id,omitempty"` // Workspace the conversation is private to, empty outside workspaces
+    Participants   []string  `json:"participants"`
+    Avatars        map[string]string `json:"avatars,omitempty"` // Avatar URL of each participant that has one, keyed by user ID
+    MessageTTL     int       `json:"message_ttl_seconds,omitempty"` // Seconds new messages live before they disappear, 0 if they are kept
+    UnreadCount    int       `json:"unread_count"` // Unread messages for the requesting user (listing only)
+    Archived       bool      `json:"archived"`     // The requesting user archived the conversation (listing only)
+    Muted          bool      `json:"muted"`        // The requesting user muted the conversation (listing only)
+    LastMessage    *DMMessagePreview `json:"last_message,omitempty"` // Most recent message, nil if there is none (listing only)
+    Counterpart    *DMCounterpart    `json:"counterpart,omitempty"`  // The other user of a one-to-one conversation (listing only)
     CreatedAt      time.Time `json:"createdAt"`
     UpdatedAt      time.Time `json:"updatedAt"`
-}
\ No newline at end of file
+}
+
+// DMSnippetLength is how many characters of a message a DMMessagePreview keeps.
+const DMSnippetLength = 140
+
+// DMMessagePreview is the start of a conversation's latest message, shown in
+// the conversation list.
+type DMMessagePreview struct {
+    ID        string    `json:"id"`
+    SenderID  string    `json:"sender_id"`
+    Snippet   string    `json:"snippet"` // The first DMSnippetLength characters of the content; empty if deleted or encrypted
+    Timestamp time.Time `json:"timestamp"`
+    Deleted   bool      `json:"deleted,omitempty"`
+    Encrypted bool      `json:"encrypted,omitempty"` // Content is ciphertext, so Snippet is empty
+}
+
+// DMCounterpart is the profile of the other user in a one-to-one conversation.
+type DMCounterpart struct {
+    UserID      string `json:"user_id"`
+    DisplayName string `json:"display_name"`
+    AvatarURL   string `json:"avatar_url,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// DMDraft is the unsent message a participant has started in a
+// conversation. Content is empty when there is no draft.
+type DMDraft struct {
+	DMID      string     `json:"dm_id"`
+	UserID    string     `json:"user_id"`
+	Content   string     `json:"content"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // When the draft was last saved; nil if there is none
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// PublicKey is a public key a user registered for one of their devices, for
+// others to wrap conversation keys with.
+type PublicKey struct {
+	UserID    string    `json:"userID"`
+	DeviceID  string    `json:"deviceID"`
+	Algorithm string    `json:"algorithm"` // Chosen by the clients, e.g. "x25519"
+	PublicKey string    `json:"publicKey"` // Base64
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DMConversationKey is one version of a conversation's key, wrapped for one
+// device of one participant. Only that device can unwrap it.
+type DMConversationKey struct {
+	DMID       string    `json:"dm_id"`
+	KeyVersion int       `json:"key_version"`
+	UserID     string    `json:"user_id"`
+	DeviceID   string    `json:"device_id"`
+	SenderID   string    `json:"sender_id"`   // Participant who wrapped the key
+	WrappedKey string    `json:"wrapped_key"` // Opaque to the server
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DMKeyEvent is broadcast to a conversation when a participant distributed a
+// new version of its key.
+type DMKeyEvent struct {
+	DMID       string `json:"dm_id"`
+	KeyVersion int    `json:"key_version"`
+	SenderID   string `json:"sender_id"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// DMMessageTTLEvent is broadcast to a conversation when a participant
+// changes how long its new messages live.
+type DMMessageTTLEvent struct {
+	DMID       string `json:"dm_id"`
+	UserID     string `json:"user_id"`
+	TTLSeconds int    `json:"ttl_seconds"` // 0 if messages are kept
+}
+
+// DMExpiredEvent is broadcast to a conversation when messages reached the
+// end of their TTL and were deleted. Clients remove them, along with any
+// replies in their threads.
+type DMExpiredEvent struct {
+	DMID       string   `json:"dm_id"`
+	MessageIDs []string `json:"message_ids"`
+}
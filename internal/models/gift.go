@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// GiftKind names a virtual gift. Clients play the animation of the same name
+// when a gift.received event arrives.
+type GiftKind string
+
+// Gift kinds. A tip carries any amount; the others have a fixed price.
+const (
+	GiftTip    GiftKind = "tip"
+	GiftHeart  GiftKind = "heart"
+	GiftFire   GiftKind = "fire"
+	GiftRocket GiftKind = "rocket"
+	GiftCrown  GiftKind = "crown"
+)
+
+// GiftCurrency is the currency of gifts and credit balances.
+const GiftCurrency = "usd"
+
+// GiftPrices are the prices of the fixed-price gifts, in cents.
+var GiftPrices = map[GiftKind]int{
+	GiftHeart:  100,
+	GiftFire:   500,
+	GiftRocket: 1000,
+	GiftCrown:  2500,
+}
+
+// IsValid reports whether k is a known gift kind.
+func (k GiftKind) IsValid() bool {
+	_, ok := GiftPrices[k]
+	return ok || k == GiftTip
+}
+
+// GiftMethod is how a gift was paid for.
+type GiftMethod string
+
+// Gift payment methods.
+const (
+	GiftCredits GiftMethod = "credits" // Deducted from the sender's credit balance
+	GiftStripe  GiftMethod = "stripe"  // Paid through Stripe Checkout
+)
+
+// GiftStatus is where a gift's payment stands.
+type GiftStatus string
+
+// Gift statuses.
+const (
+	GiftPending   GiftStatus = "pending"   // Checkout started; Stripe has not confirmed payment
+	GiftCompleted GiftStatus = "completed" // Paid and delivered to the scene
+	GiftFailed    GiftStatus = "failed"    // The checkout expired or the payment failed
+	GiftRefunded  GiftStatus = "refunded"  // Refunded; no longer counted in earnings
+)
+
+// Gift is an entry in the gifts ledger: a tip or virtual gift sent to the
+// host of a scene.
+type Gift struct {
+	ID                string     `json:"id"`
+	SceneID           string     `json:"sceneID"`
+	SenderID          string     `json:"senderID"`
+	RecipientID       string     `json:"recipientID"` // The scene's creator
+	Kind              GiftKind   `json:"kind"`
+	AmountCents       int        `json:"amountCents"`
+	Currency          string     `json:"currency"`
+	Message           string     `json:"message,omitempty"`
+	Method            GiftMethod `json:"method"`
+	Status            GiftStatus `json:"status"`
+	CheckoutSessionID string     `json:"-"`                     // Stripe Checkout session of a Stripe gift
+	PaymentIntentID   string     `json:"-"`                     // Stripe payment intent, set once the checkout completes
+	CheckoutURL       string     `json:"checkoutURL,omitempty"` // Stripe-hosted payment page; only set when the checkout is created
+	CreatedAt         time.Time  `json:"createdAt"`
+	CompletedAt       *time.Time `json:"completedAt,omitempty"`
+}
+
+// GiftKindEarnings totals the completed gifts of one kind.
+type GiftKindEarnings struct {
+	Kind        GiftKind `json:"kind"`
+	Count       int      `json:"count"`
+	AmountCents int      `json:"amountCents"`
+}
+
+// GiftSenderEarnings totals the completed gifts from one sender.
+type GiftSenderEarnings struct {
+	SenderID    string `json:"senderID"`
+	Count       int    `json:"count"`
+	AmountCents int    `json:"amountCents"`
+}
+
+// SceneEarnings totals the completed gifts a scene's host received.
+type SceneEarnings struct {
+	SceneID    string               `json:"sceneID"`
+	Currency   string               `json:"currency"`
+	TotalCents int                  `json:"totalCents"`
+	GiftCount  int                  `json:"giftCount"`
+	ByKind     []GiftKindEarnings   `json:"byKind"`     // Highest total first
+	TopSenders []GiftSenderEarnings `json:"topSenders"` // Highest total first
+}
@@ -0,0 +1,8 @@
+package models
+
+// TrendingTag is a hashtag ranked by how often it was used in a recent window.
+type TrendingTag struct {
+	Tag    string `json:"tag"`    // Lowercase, without the "#"
+	Uses   int    `json:"uses"`   // Chat messages using it plus scenes newly tagged with it
+	Scenes int    `json:"scenes"` // Distinct scenes it was used in
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SpotifyToken is a user's linked Spotify account. The refresh token is
+// stored encrypted and never serialized.
+type SpotifyToken struct {
+	UserID                string    `json:"userID"`    // The Scenyx user who linked the account
+	EncryptedRefreshToken []byte    `json:"-"`         // AES-GCM encrypted Spotify refresh token
+	Scope                 string    `json:"scope"`     // Space-separated scopes granted by the user
+	UpdatedAt             time.Time `json:"updatedAt"` // Timestamp of the last token exchange or refresh
+}
+
+// SpotifyPlaylist records the Spotify playlist a scene was exported to.
+type SpotifyPlaylist struct {
+	SceneID    string    `json:"sceneID"`    // The exported scene
+	UserID     string    `json:"userID"`     // The Scenyx user whose Spotify account owns the playlist
+	PlaylistID string    `json:"playlistID"` // Spotify playlist ID
+	URL        string    `json:"url"`        // Link to the playlist on Spotify
+	TrackCount int       `json:"trackCount"` // Number of the scene's tracks already in the playlist
+	SyncedAt   time.Time `json:"syncedAt"`   // Timestamp of the last export
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// LeaderboardBoard names what a leaderboard ranks.
+type LeaderboardBoard string
+
+// Leaderboards.
+const (
+	BoardScenes   LeaderboardBoard = "scenes"   // Scenes by hours listened
+	BoardCreators LeaderboardBoard = "creators" // Creators by scenes hosted
+)
+
+// LeaderboardPeriod is the span of activity a leaderboard counts.
+type LeaderboardPeriod string
+
+// Leaderboard periods.
+const (
+	PeriodWeek    LeaderboardPeriod = "week"     // The last seven days
+	PeriodAllTime LeaderboardPeriod = "all-time" // Everything
+)
+
+// Leaderboard is one ranking as the leaderboards job last computed it.
+type Leaderboard struct {
+	Board      LeaderboardBoard   `json:"board"`
+	Period     LeaderboardPeriod  `json:"period"`
+	Entries    []LeaderboardEntry `json:"entries"`              // Best first
+	ComputedAt *time.Time         `json:"computedAt,omitempty"` // nil if the job has not run yet
+}
+
+// LeaderboardEntry is a scene or creator's place on a leaderboard; exactly
+// one of Scene and Creator is set.
+type LeaderboardEntry struct {
+	Rank      int          `json:"rank"`              // 1 is the top
+	Score     float64      `json:"score"`             // Hours listened for scenes, scenes hosted for creators
+	Listeners int          `json:"listeners"`         // Different users who listened in the period
+	Scene     *Scene       `json:"scene,omitempty"`   // On the scenes board
+	Creator   *UserSummary `json:"creator,omitempty"` // On the creators board
+}
+
+// IsValid reports whether b is a known leaderboard.
+func (b LeaderboardBoard) IsValid() bool {
+	return b == BoardScenes || b == BoardCreators
+}
+
+// IsValid reports whether p is a known leaderboard period.
+func (p LeaderboardPeriod) IsValid() bool {
+	return p == PeriodWeek || p == PeriodAllTime
+}
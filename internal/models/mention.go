@@ -0,0 +1,21 @@
+package models
+
+// Mention is an @username in a message that resolved to a user. Start and End
+// are the byte offsets of the "@username" span in the message content.
+type Mention struct {
+	UserID   string `json:"userID"`   // The mentioned user
+	Username string `json:"username"` // Username as resolved, without the "@"
+	Start    int    `json:"start"`    // Offset of the "@"
+	End      int    `json:"end"`      // Offset just past the username
+}
+
+// MentionNotice is sent to a user when a message mentions them.
+type MentionNotice struct {
+	MessageType string `json:"messageType"`       // MessageTypeDM or MessageTypeScene
+	MessageID   string `json:"messageID"`         // The message containing the mention
+	SceneID     string `json:"sceneID,omitempty"` // Set for scene messages
+	DMID        string `json:"dmID,omitempty"`    // Set for DM messages
+	SenderID    string `json:"senderID"`          // Who sent the message
+	Content     string `json:"content"`           // Message body
+	Link        string `json:"link,omitempty"`    // Frontend page showing the message, when there is one
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Message types a MessageFlag can refer to.
+const (
+	MessageTypeDM    = "dm"
+	MessageTypeScene = "scene"
+)
+
+// MessageFlag records that the content filter matched a message that was
+// stored anyway so moderators can review it.
+type MessageFlag struct {
+	ID          string    `json:"id"`          // Unique identifier for the flag (UUID)
+	MessageType string    `json:"messageType"` // MessageTypeDM or MessageTypeScene
+	MessageID   string    `json:"messageID"`   // The flagged message
+	SenderID    string    `json:"senderID"`    // The ID of the user who sent the message
+	Reasons     []string  `json:"reasons"`     // What the filters matched
+	CreatedAt   time.Time `json:"createdAt"`   // Timestamp when the message was flagged
+}
+
+// ReportTarget is the kind of thing a user reported.
+type ReportTarget string
+
+const (
+	ReportDMMessage    ReportTarget = "dm_message"
+	ReportSceneMessage ReportTarget = "scene_message"
+	ReportScene        ReportTarget = "scene"
+	ReportUser         ReportTarget = "user"
+)
+
+// ReportStatus tracks a report through the moderation queue.
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"      // Waiting for an admin
+	ReportDismissed ReportStatus = "dismissed" // Reviewed; no action taken
+	ReportActioned  ReportStatus = "actioned"  // Reviewed; the reported content was removed
+)
+
+// Report is a user's complaint about a message, scene, or user.
+type Report struct {
+	ID             string       `json:"id"`                       // Unique identifier for the report (UUID)
+	ReporterID     string       `json:"reporterID"`               // The ID of the user who filed the report
+	TargetType     ReportTarget `json:"targetType"`               // What kind of thing was reported
+	TargetID       string       `json:"targetID"`                 // The reported message, scene, or user
+	ReportedUserID string       `json:"reportedUserID"`           // The author of the reported message or scene, or the reported user
+	Content        string       `json:"content,omitempty"`        // Message text or scene name when reported, kept for review after edits
+	Reason         string       `json:"reason"`                   // The reporter's explanation
+	Status         ReportStatus `json:"status"`                   // Where the report is in the queue
+	ResolvedBy     *string      `json:"resolvedBy,omitempty"`     // The admin who resolved the report
+	ResolutionNote string       `json:"resolutionNote,omitempty"` // The admin's note on the resolution
+	CreatedAt      time.Time    `json:"createdAt"`                // Timestamp when the report was filed
+	ResolvedAt     *time.Time   `json:"resolvedAt,omitempty"`     // Timestamp when the report was resolved
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NotificationChannel groups the notifications a user can turn on or off
+// together.
+type NotificationChannel string
+
+const (
+	ChannelDMs          NotificationChannel = "dms"          // New messages in the user's DM conversations
+	ChannelMentions     NotificationChannel = "mentions"     // @mentions in DM and scene messages
+	ChannelSceneInvites NotificationChannel = "sceneInvites" // Join requests to scenes the user created, and decisions on the user's own requests
+	ChannelSceneLive    NotificationChannel = "sceneLive"    // Reminders and go-live alerts for scenes the user RSVP'd to, and closing alerts for scenes they joined
+)
+
+// NotificationSettings are the notification channels a user has turned on.
+// MuteAll silences every channel and conversation without losing the
+// per-channel choices.
+type NotificationSettings struct {
+	MuteAll      bool       `json:"muteAll"`
+	DMs          bool       `json:"dms"`
+	Mentions     bool       `json:"mentions"`
+	SceneInvites bool       `json:"sceneInvites"`
+	SceneLive    bool       `json:"sceneLive"`
+	QuietHours   QuietHours `json:"quietHours"`
+}
+
+// QuietHours is a daily do-not-disturb window. Notifications that arrive
+// during it are held and sent as one summary when it ends. A window whose
+// start is after its end runs past midnight, e.g. 22:00 to 07:00.
+type QuietHours struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"`    // Local time the window opens, as HH:MM
+	End      string `json:"end"`      // Local time the window closes, as HH:MM
+	TimeZone string `json:"timeZone"` // IANA time zone, e.g. "Europe/Berlin"
+}
+
+// quietHoursLayout is the format of QuietHours.Start and End.
+const quietHoursLayout = "15:04"
+
+// Validate reports whether the window's times and time zone can be read.
+// Disabled windows are checked too, so they can be turned on later as saved.
+func (q QuietHours) Validate() error {
+	start, err := time.Parse(quietHoursLayout, q.Start)
+	if err != nil {
+		return errors.New("Quiet hours start must be a time such as 22:00")
+	}
+	end, err := time.Parse(quietHoursLayout, q.End)
+	if err != nil {
+		return errors.New("Quiet hours end must be a time such as 07:00")
+	}
+	if start.Equal(end) {
+		return errors.New("Quiet hours start and end must differ")
+	}
+	if _, err := time.LoadLocation(q.TimeZone); err != nil || q.TimeZone == "" {
+		return fmt.Errorf("Quiet hours time zone %q is not a known IANA time zone", q.TimeZone)
+	}
+	return nil
+}
+
+// Active reports whether t falls inside the window, in its time zone.
+func (q QuietHours) Active(t time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+	start, err1 := time.Parse(quietHoursLayout, q.Start)
+	end, err2 := time.Parse(quietHoursLayout, q.End)
+	loc, err3 := time.LoadLocation(q.TimeZone)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return false
+	}
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	from, until := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from < until {
+		return from <= now && now < until
+	}
+	return now >= from || now < until
+}
+
+// Enabled reports whether channel is turned on, ignoring MuteAll.
+func (s NotificationSettings) Enabled(channel NotificationChannel) bool {
+	switch channel {
+	case ChannelDMs:
+		return s.DMs
+	case ChannelMentions:
+		return s.Mentions
+	case ChannelSceneInvites:
+		return s.SceneInvites
+	case ChannelSceneLive:
+		return s.SceneLive
+	}
+	return false
+}
+
+// NotificationLevel is how much a user is notified of in one conversation,
+// overriding their channel settings.
+type NotificationLevel string
+
+const (
+	NotifyAll      NotificationLevel = "all"      // Every notification, even from channels turned off
+	NotifyMentions NotificationLevel = "mentions" // Only @mentions
+	NotifyNone     NotificationLevel = "none"     // Nothing; the conversation is muted
+)
+
+// IsValid reports whether l is a known level.
+func (l NotificationLevel) IsValid() bool {
+	switch l {
+	case NotifyAll, NotifyMentions, NotifyNone:
+		return true
+	}
+	return false
+}
+
+// NotificationOverride is a user's notification level for a single DM
+// conversation or scene. Exactly one of DMID and SceneID is set.
+type NotificationOverride struct {
+	UserID    string            `json:"userID"`
+	DMID      string            `json:"dmID,omitempty"`
+	SceneID   string            `json:"sceneID,omitempty"`
+	Level     NotificationLevel `json:"level"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// NotificationPreferences are what decides whether a user receives a
+// notification about one conversation: their settings, their override for
+// the conversation, empty if they have none, and whether they muted it.
+type NotificationPreferences struct {
+	Settings NotificationSettings
+	Override NotificationLevel
+	Muted    bool // The user muted the DM conversation; nothing from it is notified
+}
+
+// Allows reports whether a notification on channel should be delivered.
+func (p NotificationPreferences) Allows(channel NotificationChannel) bool {
+	if p.Settings.MuteAll || p.Muted {
+		return false
+	}
+	switch p.Override {
+	case NotifyAll:
+		return true
+	case NotifyMentions:
+		return channel == ChannelMentions
+	case NotifyNone:
+		return false
+	}
+	return p.Settings.Enabled(channel)
+}
+
+// QueuedNotification is a notification held back during a user's quiet hours.
+type QueuedNotification struct {
+	Channel   NotificationChannel `json:"channel"`
+	Type      string              `json:"type"`    // WebSocket event type the notification would have been sent as
+	Payload   json.RawMessage     `json:"payload"` // Payload it would have carried
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+// NotificationSummary is sent to a user when their quiet hours end, in place
+// of the notifications held back during them.
+type NotificationSummary struct {
+	Total         int                         `json:"total"`
+	ByChannel     map[NotificationChannel]int `json:"byChannel"`
+	Notifications []QueuedNotification        `json:"notifications"` // The most recent, oldest first; may be fewer than Total
+}
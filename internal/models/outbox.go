@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aggregate types of outbox events.
+const (
+	AggregateScene = "scene"
+	AggregateDM    = "dm"
+)
+
+// OutboxEvent is a domain event recorded alongside the change that caused it,
+// waiting to be published to the message broker.
+type OutboxEvent struct {
+	ID            int64           `json:"-"`       // Publishing order
+	EventID       string          `json:"eventID"` // Stable across redeliveries; consumers dedupe on it
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   string          `json:"aggregateID"` // Scene or DM conversation ID
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// PlaybackState is the shared player state for a scene. PositionMs is the
+// track position at UpdatedAt; while IsPlaying, clients extrapolate from there.
+type PlaybackState struct {
+	SceneID     string    `json:"sceneID"`     // The scene this state belongs to
+	TrackID     string    `json:"trackID"`     // External provider ID of the current track
+	TrackTitle  string    `json:"trackTitle"`  // Title of the current track
+	TrackArtist string    `json:"trackArtist"` // Artist of the current track
+	PositionMs  int64     `json:"positionMs"`  // Playback position in milliseconds at UpdatedAt
+	IsPlaying   bool      `json:"isPlaying"`   // Whether playback is running or paused
+	UpdatedBy   string    `json:"updatedBy"`   // The ID of the user who last changed the state
+	UpdatedAt   time.Time `json:"updatedAt"`   // Timestamp of the last state change
+	StartedBy   string    `json:"startedBy"`   // The ID of the user who started the current track
+	StartedAt   time.Time `json:"startedAt"`   // Timestamp when the current track was started
+}
+
+// CurrentPositionMs returns the position the track has reached at now,
+// accounting for time elapsed since the last update while playing.
+func (p *PlaybackState) CurrentPositionMs(now time.Time) int64 {
+	if !p.IsPlaying {
+		return p.PositionMs
+	}
+	return p.PositionMs + now.Sub(p.UpdatedAt).Milliseconds()
+}
+
+// TrackChanged reports whether the update that produced p started a new
+// track. The store stamps StartedAt and UpdatedAt with the same time when
+// the track changes.
+func (p *PlaybackState) TrackChanged() bool {
+	return p.TrackID != "" && p.StartedAt.Equal(p.UpdatedAt)
+}
+
+// NowPlaying returns the scene's current track, or nil if nothing is loaded.
+func (p *PlaybackState) NowPlaying() *NowPlaying {
+	if p.TrackID == "" {
+		return nil
+	}
+	return &NowPlaying{
+		SceneID:     p.SceneID,
+		TrackID:     p.TrackID,
+		TrackTitle:  p.TrackTitle,
+		TrackArtist: p.TrackArtist,
+		StartedBy:   p.StartedBy,
+		StartedAt:   p.StartedAt,
+	}
+}
+
+// NowPlaying announces the track a scene has moved on to.
+type NowPlaying struct {
+	SceneID     string    `json:"sceneID"`
+	TrackID     string    `json:"trackID"`     // External provider ID of the track
+	TrackTitle  string    `json:"trackTitle"`  // Track title
+	TrackArtist string    `json:"trackArtist"` // Track artist
+	StartedBy   string    `json:"startedBy"`   // The ID of the user who started it
+	StartedAt   time.Time `json:"startedAt"`   // Timestamp when it started
+}
+
+// TrackSkipped reports that a scene's listeners voted the current track off.
+// Next is the queued track that took its place, or nil if the queue was empty
+// and playback stopped.
+type TrackSkipped struct {
+	SceneID string     `json:"sceneID"`
+	TrackID string     `json:"trackID"` // External provider ID of the skipped track
+	Votes   int        `json:"votes"`   // Skip votes cast
+	Needed  int        `json:"needed"`  // Votes that were required
+	Next    *QueueItem `json:"next,omitempty"`
+}
+
+// PlayedTrack is a track that was started in a scene.
+type PlayedTrack struct {
+	TrackID  string    `json:"trackID"`  // External provider ID of the track
+	Title    string    `json:"title"`    // Track title
+	Artist   string    `json:"artist"`   // Track artist
+	PlayedBy string    `json:"playedBy"` // The ID of the user who started it
+	PlayedAt time.Time `json:"playedAt"` // Timestamp when it started
+}
+
+// ScenePlaylist is everything played in a scene, exported as a playlist.
+// Each track appears once, at its first play.
+type ScenePlaylist struct {
+	SceneID     string        `json:"sceneID"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Tracks      []PlayedTrack `json:"tracks"`
+}
+
+// NewScenePlaylist builds the playlist for scene from its play history,
+// oldest first.
+func NewScenePlaylist(scene *Scene, plays []PlayedTrack) *ScenePlaylist {
+	playlist := &ScenePlaylist{
+		SceneID:     scene.ID,
+		Name:        scene.Name,
+		Description: "Played in " + scene.Name + " on Scenyx",
+		Tracks:      []PlayedTrack{},
+	}
+	seen := make(map[string]bool, len(plays))
+	for _, play := range plays {
+		if play.TrackID == "" || seen[play.TrackID] {
+			continue
+		}
+		seen[play.TrackID] = true
+		playlist.Tracks = append(playlist.Tracks, play)
+	}
+	return playlist
+}
@@ -0,0 +1,11 @@
+package models
+
+// MaxReactionLength bounds the size in bytes of a reaction emoji, which may
+// be a multi-codepoint sequence such as a flag or skin-tone variant.
+const MaxReactionLength = 32
+
+// ReactionCount is the number of users who reacted to a message with one emoji.
+type ReactionCount struct {
+	Emoji string `json:"emoji"` // The reaction emoji
+	Count int    `json:"count"` // Number of users who reacted with it
+}
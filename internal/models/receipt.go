@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// DMReceipt records how far a DM message got with one recipient. Recipients
+// without a receipt have not received the message yet.
+type DMReceipt struct {
+	UserID      string     `json:"user_id"`
+	DeliveredAt time.Time  `json:"delivered_at"`      // When one of the recipient's devices received the message
+	ReadAt      *time.Time `json:"read_at,omitempty"` // When the recipient read it; nil until then
+}
+
+// DMReceiptEvent is broadcast to a conversation when a participant received
+// or read messages. Exactly one of DeliveredAt and ReadAt is set.
+type DMReceiptEvent struct {
+	DMID        string     `json:"dm_id"`
+	UserID      string     `json:"user_id"`
+	MessageIDs  []string   `json:"message_ids"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+}
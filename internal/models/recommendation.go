@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RecommendedScene is a scene recommended to a user with the signals behind it.
+type RecommendedScene struct {
+	*Scene
+	Score      float64               `json:"score"`      // Higher is a stronger recommendation
+	Reasons    RecommendationReasons `json:"reasons"`    // The parts the score was built from
+	ComputedAt time.Time             `json:"computedAt"` // When the recommendation job scored it
+}
+
+// RecommendationReasons are the signals a recommendation score combines.
+type RecommendationReasons struct {
+	SharedTags     int     `json:"sharedTags"`     // Hashtags shared with scenes the user listened to
+	FollowsCreator bool    `json:"followsCreator"` // The user follows the scene's creator
+	HistoryHours   float64 `json:"historyHours"`   // Hours the user listened to the creator's scenes, capped
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Reputation is a user's standing, recomputed periodically from their recent
+// participation and the moderation they have drawn. Scene hosts see it when
+// deciding on join requests and bans.
+type Reputation struct {
+	UserID     string            `json:"userID"`
+	Score      float64           `json:"score"`                // Participation points minus moderation penalties; 0 without history
+	Signals    ReputationSignals `json:"signals"`              // The parts the score was built from
+	ComputedAt *time.Time        `json:"computedAt,omitempty"` // When the reputation job scored the user; nil if it never has
+}
+
+// ReputationSignals are the facts a reputation score combines.
+type ReputationSignals struct {
+	ListeningHours  float64 `json:"listeningHours"`  // Hours listened to scenes recently
+	ScenesHosted    int     `json:"scenesHosted"`    // Scenes created recently
+	MessagesSent    int     `json:"messagesSent"`    // Scene chat messages sent recently
+	ReportsActioned int     `json:"reportsActioned"` // Reports against the user that an admin acted on
+	ReportsOpen     int     `json:"reportsOpen"`     // Reports against the user awaiting review
+	SceneBans       int     `json:"sceneBans"`       // Scenes that banned the user
+	SceneMutes      int     `json:"sceneMutes"`      // Scenes that muted the user
+}
@@ -1,16 +1,214 @@
 package models
 
-import "time"
+import (
+	"hash/crc32"
+	"net/url"
+	"strconv"
+	"time"
+)
 
 // Scene represents a user-created scene with a unique ID, name, artist, creator,
 // total listeners (derived), and active users (real-time via WebSocket).
 type Scene struct {
 	ID          string    `json:"id"`             // Unique identifier for the scene (UUID)
 	Name        string    `json:"name"`           // Name of the scene
+	Slug        string    `json:"slug,omitempty"` // Unique human-readable name used in share links, empty if unclaimed
 	ArtistName  string    `json:"artistName"`     // Name of the artist who created the scene
+	Description string    `json:"description"`    // Free-form description shown on the scene page
+	CoverImageURL string  `json:"coverImageURL"`  // URL of the scene's cover image
+	CoverKey    string    `json:"-"`              // Storage key of the uploaded cover, empty if the cover is linked
+	CoverImages *SceneCoverImages `json:"coverImages,omitempty"` // Resized copies of an uploaded cover, nil if none
+	Tags        []string  `json:"tags"`           // Lowercase genre/mood tags used for discovery
 	CreatorID   string    `json:"CreatorID"`      // The ID of the user who created this scene
 	Listeners   int       `json:"listeners"`      // Total number of listeners for the scene (derived from DB count)
 	ActiveUsers int       `json:"activeUsers"`    // Number of active users currently in the scene (real-time via WebSocket)
 	CreatedAt   time.Time `json:"createdAt"`      // Timestamp when the scene was created
 	UpdatedAt   time.Time `json:"updatedAt"`      // Timestamp when the scene was last updated
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty"` // Set when the creator archived the scene; archived scenes are hidden from listings
+	Status      SceneStatus `json:"status"`              // SceneScheduled until ScheduledAt passes, then SceneLive
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"` // Start time of a scheduled scene, nil for scenes created live
+	RSVPCount   int        `json:"rsvpCount"`             // Number of users who RSVP'd to a scheduled scene
+	RequiresApproval bool  `json:"requiresApproval"`      // Joins wait for the creator's approval
+	SkipThreshold float64  `json:"skipThreshold"`         // Fraction of active users whose votes skip the current track; 0 disables vote-to-skip
+	Ephemeral   bool       `json:"ephemeral"`             // Chat, queue, play history, attachments, and transcript exports are deleted when the scene is archived or closed
+	MaxDurationSeconds *int `json:"maxDurationSeconds,omitempty"` // How long the scene runs before it ends on its own, nil for no limit
+	EndsAt      *time.Time `json:"endsAt,omitempty"`      // When a scene with a maximum duration ends: its start plus MaxDurationSeconds
+	TicketPriceCents int   `json:"ticketPriceCents"`      // Price of entry in TicketCurrency's smallest unit; 0 for free scenes
+	TicketCurrency string  `json:"ticketCurrency"`        // Lowercase ISO 4217 code of TicketPriceCents
+	WorkspaceID string     `json:"workspaceID,omitempty"` // Workspace the scene is private to, empty for public scenes
 }
+
+// Ticketed reports whether users other than the creator must buy a ticket to join.
+func (s *Scene) Ticketed() bool {
+	return s.TicketPriceCents > 0
+}
+
+// SceneCoverImages are the URLs of an uploaded scene cover at each stored size.
+type SceneCoverImages struct {
+	Small  string `json:"small"`  // 160×160
+	Medium string `json:"medium"` // 320×320
+	Large  string `json:"large"`  // 640×640
+}
+
+// NewSceneCoverImages returns the cover URLs for an uploaded cover key, or
+// nil if the scene has none.
+func NewSceneCoverImages(sceneID, key string) *SceneCoverImages {
+	if key == "" {
+		return nil
+	}
+	return &SceneCoverImages{
+		Small:  SceneCoverURL(sceneID, "small", key),
+		Medium: SceneCoverURL(sceneID, "medium", key),
+		Large:  SceneCoverURL(sceneID, "large", key),
+	}
+}
+
+// SceneCoverURL is the API path that redirects to a scene's uploaded cover at
+// size. As with AvatarURL, the key is folded into a version parameter.
+func SceneCoverURL(sceneID, size, key string) string {
+	version := strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(key))), 36)
+	return "/api/v1/scenes/cover?scene_id=" + url.QueryEscape(sceneID) + "&size=" + size + "&v=" + version
+}
+
+// SceneStatus is whether a scene has started.
+type SceneStatus string
+
+const (
+	SceneScheduled SceneStatus = "scheduled" // Waiting for its start time
+	SceneLive      SceneStatus = "live"      // Started
+)
+
+// SceneJoinRequest is a user waiting for the creator to let them into a
+// scene that requires approval.
+type SceneJoinRequest struct {
+	SceneID    string      `json:"sceneID"`
+	UserID     string      `json:"userID"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	Reputation *Reputation `json:"reputation,omitempty"` // The user's standing; set when hosts list requests
+}
+
+// JoinDecision tells a user whether their join request was approved.
+type JoinDecision struct {
+	SceneID  string `json:"sceneID"`
+	UserID   string `json:"userID"`
+	Approved bool   `json:"approved"`
+}
+
+// SceneClosedReason says why a scene was closed.
+type SceneClosedReason string
+
+const (
+	SceneClosedDeleted SceneClosedReason = "deleted" // The creator deleted the scene
+	SceneClosedEmpty   SceneClosedReason = "empty"   // Nobody was connected for SCENE_EMPTY_CLOSE_AFTER; the scene was archived
+	SceneClosedEnded   SceneClosedReason = "ended"   // The scene reached its maximum duration and was archived
+)
+
+// SceneEnding is the scene.ending payload, a countdown warning sent as a
+// scene with a maximum duration nears its end.
+type SceneEnding struct {
+	SceneID     string    `json:"sceneID"`
+	EndsAt      time.Time `json:"endsAt"`
+	SecondsLeft int       `json:"secondsLeft"`
+}
+
+// SceneClosed is the scene.closed payload, sent just before the server
+// closes every connection to the scene.
+type SceneClosed struct {
+	SceneID string            `json:"sceneID"`
+	Reason  SceneClosedReason `json:"reason"`
+}
+
+// SceneMessage is a chat message posted inside a scene.
+type SceneMessage struct {
+	ID        string    `json:"id"`        // Unique identifier for the message (UUID)
+	SceneID   string    `json:"sceneID"`   // The scene this message was posted in
+	SenderID  string    `json:"senderID"`  // The ID of the user who sent the message
+	Content   string    `json:"content"`   // Message body
+	CreatedAt time.Time `json:"createdAt"` // Timestamp when the message was sent
+	Reactions []ReactionCount `json:"reactions,omitempty"` // Reaction counts, in order of first use
+	Attachments []Attachment  `json:"attachments,omitempty"` // Files sent with the message
+	Mentions    []Mention     `json:"mentions,omitempty"`    // Users mentioned with @username, in order of appearance
+}
+
+// SceneSearchResult is a scene message matching a search together with the
+// messages immediately before and after it.
+type SceneSearchResult struct {
+	SceneID string         `json:"sceneID"`
+	Message SceneMessage   `json:"message"`
+	Before  []SceneMessage `json:"before"` // Oldest first
+	After   []SceneMessage `json:"after"`  // Oldest first
+}
+
+// MaxPinnedMessages is how many messages a scene can have pinned at once.
+const MaxPinnedMessages = 3
+
+// PinnedMessage is a scene chat message the creator pinned as an announcement.
+type PinnedMessage struct {
+	SceneMessage
+	PinnedBy string    `json:"pinnedBy"` // The ID of the user who pinned the message
+	PinnedAt time.Time `json:"pinnedAt"` // Timestamp when the message was pinned
+}
+
+// QueueItem is a track waiting to be played in a scene.
+type QueueItem struct {
+	ID         string    `json:"id"`         // Unique identifier for the queue entry (UUID)
+	SceneID    string    `json:"sceneID"`    // The scene this entry is queued in
+	Position   int       `json:"position"`   // Zero-based order in the queue
+	Title      string    `json:"title"`      // Track title
+	Artist     string    `json:"artist"`     // Track artist
+	ArtworkURL string    `json:"artworkURL"` // URL of the track's cover art
+	ProviderID string    `json:"providerID"` // External provider ID (e.g. a Spotify track ID)
+	AddedBy    string    `json:"addedBy"`    // The ID of the user who queued the track
+	AddedAt    time.Time `json:"addedAt"`    // Timestamp when the track was queued
+	Upvotes    int       `json:"upvotes"`    // Number of listeners who voted the track up
+	Downvotes  int       `json:"downvotes"`  // Number of listeners who voted the track down
+}
+
+// ScenePoll is a multiple-choice question asked in a scene, with its votes so far.
+type ScenePoll struct {
+	ID         string       `json:"id"`
+	SceneID    string       `json:"sceneID"`
+	CreatedBy  string       `json:"createdBy"`
+	Question   string       `json:"question"`
+	Options    []PollOption `json:"options"`    // In the order they were given
+	TotalVotes int          `json:"totalVotes"` // Sum of the options' votes
+	CreatedAt  time.Time    `json:"createdAt"`
+	ClosedAt   *time.Time   `json:"closedAt,omitempty"` // Set once the poll stops accepting votes
+}
+
+// PollOption is one of a poll's answers and how many users chose it.
+type PollOption struct {
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// SceneRole is what a user may do in a scene.
+type SceneRole string
+
+const (
+	RoleHost     SceneRole = "host"     // The creator; manages roles and the scene itself
+	RoleCoHost   SceneRole = "co-host"  // Moderates and controls playback alongside the host
+	RoleListener SceneRole = "listener" // Everyone else
+)
+
+// CanModerate reports whether the role may moderate users and control playback.
+func (r SceneRole) CanModerate() bool {
+	return r == RoleHost || r == RoleCoHost
+}
+
+// SceneRoleAssignment is a user's role in a scene.
+type SceneRoleAssignment struct {
+	SceneID    string    `json:"sceneID"`
+	UserID     string    `json:"userID"`
+	Role       SceneRole `json:"role"`
+	AssignedBy string    `json:"assignedBy"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// SceneRestriction is a moderation action that persists on a user in a scene.
+type SceneRestriction string
+
+const (
+	RestrictionBan  SceneRestriction = "ban"  // The user cannot join the scene
+	RestrictionMute SceneRestriction = "mute" // The user cannot post chat messages
+)
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ScheduledDMMessage is a DM message held back until SendAt, when it is sent
+// as if the sender had sent it then.
+type ScheduledDMMessage struct {
+	ID               string    `json:"id"`
+	DMConversationID string    `json:"dm_conversation_id"`
+	SenderID         string    `json:"sender_id"`
+	Content          string    `json:"content"`
+	ParentMessageID  *string   `json:"parent_message_id,omitempty"` // Thread to reply in; sent top-level if the parent is deleted first
+	AttachmentIDs    []string  `json:"attachment_ids,omitempty"`
+	FlagReasons      []string  `json:"-"` // Content filter matches to flag the message with once it is sent
+	SendAt           time.Time `json:"send_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Theme is the color scheme a user picked for the frontend.
+type Theme string
+
+const (
+	ThemeSystem Theme = "system" // Follow the device setting
+	ThemeLight  Theme = "light"
+	ThemeDark   Theme = "dark"
+)
+
+// DMAudience is who may start a direct message conversation with a user.
+type DMAudience string
+
+const (
+	DMFromEveryone  DMAudience = "everyone"
+	DMFromFollowing DMAudience = "following" // Only users they follow
+	DMFromNobody    DMAudience = "nobody"
+)
+
+// UserSettings are a user's frontend preferences, kept server-side so they
+// follow the user across devices.
+type UserSettings struct {
+	Theme         Theme                `json:"theme"`
+	Locale        string               `json:"locale"` // BCP 47 language tag, e.g. "en" or "pt-BR"
+	Privacy       PrivacySettings      `json:"privacy"`
+	Notifications NotificationSettings `json:"notifications"` // Per-conversation overrides are kept separately
+}
+
+// PrivacySettings control what other users can see of a user and how they
+// can reach them.
+type PrivacySettings struct {
+	ShowOnlineStatus      bool       `json:"showOnlineStatus"`      // Show presence and last seen to others
+	ShowListeningActivity bool       `json:"showListeningActivity"` // Show which scene the user is listening to
+	Searchable            bool       `json:"searchable"`            // Appear in user search results
+	AllowDMsFrom          DMAudience `json:"allowDMsFrom"`
+}
+
+// DefaultUserSettings are the settings of a user who has not saved any.
+func DefaultUserSettings() UserSettings {
+	return UserSettings{
+		Theme:  ThemeSystem,
+		Locale: "en",
+		Privacy: PrivacySettings{
+			ShowOnlineStatus:      true,
+			ShowListeningActivity: true,
+			Searchable:            true,
+			AllowDMsFrom:          DMFromEveryone,
+		},
+		Notifications: NotificationSettings{
+			DMs:          true,
+			Mentions:     true,
+			SceneInvites: true,
+			SceneLive:    true,
+			QuietHours: QuietHours{
+				Start:    "22:00",
+				End:      "07:00",
+				TimeZone: "UTC",
+			},
+		},
+	}
+}
+
+// Validate reports the first setting that has an unsupported value.
+func (s UserSettings) Validate() error {
+	switch s.Theme {
+	case ThemeSystem, ThemeLight, ThemeDark:
+	default:
+		return fmt.Errorf("Theme must be %q, %q, or %q", ThemeSystem, ThemeLight, ThemeDark)
+	}
+	if !isLanguageTag(s.Locale) {
+		return errors.New("Locale must be a language tag such as \"en\" or \"pt-BR\"")
+	}
+	switch s.Privacy.AllowDMsFrom {
+	case DMFromEveryone, DMFromFollowing, DMFromNobody:
+	default:
+		return fmt.Errorf("allowDMsFrom must be %q, %q, or %q", DMFromEveryone, DMFromFollowing, DMFromNobody)
+	}
+	return s.Notifications.QuietHours.Validate()
+}
+
+// isLanguageTag reports whether tag looks like a BCP 47 language tag: a 2-3
+// letter language followed by up to three subtags of 2-8 letters or digits.
+func isLanguageTag(tag string) bool {
+	if len(tag) > 35 {
+		return false
+	}
+	for i, part := range strings.Split(tag, "-") {
+		if i > 3 {
+			return false
+		}
+		if i == 0 {
+			if len(part) < 2 || len(part) > 3 || !isAlnum(part, true) {
+				return false
+			}
+			continue
+		}
+		if len(part) < 2 || len(part) > 8 || !isAlnum(part, false) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string, lettersOnly bool) bool {
+	for _, c := range []byte(s) {
+		letter := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+		if !letter && (lettersOnly || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
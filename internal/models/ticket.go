@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// TicketStatus is where a scene ticket's payment stands.
+type TicketStatus string
+
+// Ticket statuses.
+const (
+	TicketPending  TicketStatus = "pending"  // Checkout started; Stripe has not confirmed payment
+	TicketPaid     TicketStatus = "paid"     // Paid; the user may join the scene
+	TicketFailed   TicketStatus = "failed"   // The checkout expired or the payment failed
+	TicketRefunded TicketStatus = "refunded" // Refunded; the user may no longer join
+)
+
+// SceneTicket is a user's purchase of entry to a ticketed scene.
+type SceneTicket struct {
+	ID                string       `json:"id"`
+	SceneID           string       `json:"sceneID"`
+	UserID            string       `json:"userID"`
+	AmountCents       int          `json:"amountCents"` // Price charged, in the currency's smallest unit
+	Currency          string       `json:"currency"`    // Lowercase ISO 4217 code
+	Status            TicketStatus `json:"status"`
+	CheckoutSessionID string       `json:"checkoutSessionID"`     // Stripe Checkout session
+	PaymentIntentID   string       `json:"-"`                     // Stripe payment intent, set once the checkout completes
+	CheckoutURL       string       `json:"checkoutURL,omitempty"` // Stripe-hosted payment page; only set when the checkout is created
+	CreatedAt         time.Time    `json:"createdAt"`
+	PaidAt            *time.Time   `json:"paidAt,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// TranscriptFormat is the file format of a scene transcript.
+type TranscriptFormat string
+
+// Transcript formats.
+const (
+	TranscriptJSON TranscriptFormat = "json" // A SceneTranscript document
+	TranscriptCSV  TranscriptFormat = "csv"  // One row per message or track, in time order
+)
+
+// IsValid reports whether f is a known format.
+func (f TranscriptFormat) IsValid() bool {
+	return f == TranscriptJSON || f == TranscriptCSV
+}
+
+// ContentType returns the MIME type of a transcript in f.
+func (f TranscriptFormat) ContentType() string {
+	if f == TranscriptCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// TranscriptStatus is how far a transcript export has got.
+type TranscriptStatus string
+
+// Transcript export statuses.
+const (
+	TranscriptPending TranscriptStatus = "pending" // Waiting for the export job
+	TranscriptRunning TranscriptStatus = "running" // Being generated
+	TranscriptReady   TranscriptStatus = "ready"   // Generated; DownloadURL is set
+	TranscriptFailed  TranscriptStatus = "failed"  // Could not be generated; Error says why
+)
+
+// SceneTranscript is a scene's chat and track history, as exported.
+type SceneTranscript struct {
+	Scene      *Scene         `json:"scene"`
+	Messages   []SceneMessage `json:"messages"` // Oldest first
+	Tracks     []PlayedTrack  `json:"tracks"`   // Every track started, oldest first
+	ExportedAt time.Time      `json:"exportedAt"`
+}
+
+// TranscriptExport is a transcript of a large scene generated in the
+// background for its host to download.
+type TranscriptExport struct {
+	ID          string           `json:"id"`
+	SceneID     string           `json:"sceneID"`
+	RequestedBy string           `json:"requestedBy"`
+	Format      TranscriptFormat `json:"format"`
+	Status      TranscriptStatus `json:"status"`
+	DownloadURL string           `json:"downloadURL,omitempty"` // Set once ready
+	Error       string           `json:"error,omitempty"`       // Set if it failed
+	BlobKey     string           `json:"-"`                     // Where the file is stored
+	CreatedAt   time.Time        `json:"createdAt"`
+	CompletedAt *time.Time       `json:"completedAt,omitempty"`
+}
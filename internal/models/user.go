@@ -1 +1,45 @@
-package models
\ No newline at end of file
+package models
+
+import (
+	"hash/crc32"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// User represents a registered Scenyx account.
+type User struct {
+	ID           string    `json:"id"`          // Unique identifier for the user (UUID)
+	Username     string    `json:"username,omitempty"` // Unique lowercase handle others can search for, empty if not chosen
+	DisplayName  string    `json:"displayName"` // Name shown to other users
+	Email        string    `json:"email"`       // Email address used to log in (unique)
+	PasswordHash string    `json:"-"`           // bcrypt hash of the user's password, never serialized
+	CreatedAt    time.Time `json:"createdAt"`   // Timestamp when the user signed up
+	LastSeenAt   *time.Time `json:"lastSeenAt,omitempty"` // Last time the user connected or disconnected, nil if never
+	AvatarKey    string    `json:"-"`                   // Storage key of the uploaded avatar, empty if none
+	AvatarURL    string    `json:"avatarURL,omitempty"` // Where clients load the avatar from, empty if none
+}
+
+// UserSummary is the public view of a user returned by search, without
+// private fields such as the email address.
+type UserSummary struct {
+	ID          string `json:"id"`
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"displayName"`
+	AvatarURL   string `json:"avatarURL,omitempty"`
+}
+
+// Summary returns the public view of u.
+func (u *User) Summary() UserSummary {
+	return UserSummary{ID: u.ID, Username: u.Username, DisplayName: u.DisplayName, AvatarURL: u.AvatarURL}
+}
+
+// AvatarURL is the API path that redirects to a user's avatar. The key is
+// folded into a version parameter so clients refetch after a new upload.
+func AvatarURL(userID, key string) string {
+	if key == "" {
+		return ""
+	}
+	version := strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(key))), 36)
+	return "/api/v1/users/avatar?user_id=" + url.QueryEscape(userID) + "&v=" + version
+}
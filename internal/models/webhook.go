@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event names, shared by webhooks and the event outbox.
+const (
+	EventSceneCreated = "scene.created"
+	EventUserJoined   = "user.joined"
+	EventMessageSent  = "message.sent"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{EventSceneCreated, EventUserJoined, EventMessageSent}
+
+// Webhook is an integrator's endpoint for scene and DM events.
+type Webhook struct {
+	ID        string    `json:"id"`                // Unique identifier for the webhook (UUID)
+	OwnerID   string    `json:"ownerID"`           // The user who registered it
+	SceneID   *string   `json:"sceneID,omitempty"` // Only this scene's events; nil for every event
+	URL       string    `json:"url"`               // Where payloads are POSTed
+	Secret    string    `json:"secret,omitempty"`  // HMAC key for signatures; only returned on creation
+	Events    []string  `json:"events"`            // Subscribed event names
+	CreatedAt time.Time `json:"createdAt"`         // Timestamp when the webhook was registered
+}
+
+// DeliveryStatus is the state of a webhook delivery.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"   // Waiting for its first or next attempt
+	DeliveryDelivered DeliveryStatus = "delivered" // The endpoint answered 2xx
+	DeliveryFailed    DeliveryStatus = "failed"    // Out of attempts
+)
+
+// WebhookDelivery is one event queued for one webhook.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhookID"`
+	EventID        string          `json:"eventID"` // Shared by every delivery of the same event
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+
+	URL    string `json:"-"` // Filled in when claimed for sending
+	Secret string `json:"-"`
+}
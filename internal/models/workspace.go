@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Workspace is a private community on a shared deployment. Its scenes and
+// DMs are visible only to its members; scenes and DMs outside any workspace
+// form the public space.
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"ownerID"`
+	Members   int       `json:"members"` // Number of members, the owner included
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WorkspaceRole is a member's standing in a workspace.
+type WorkspaceRole string
+
+// Workspace roles.
+const (
+	WorkspaceOwner  WorkspaceRole = "owner"  // Created the workspace; cannot be removed
+	WorkspaceAdmin  WorkspaceRole = "admin"  // Manages members alongside the owner
+	WorkspaceMember WorkspaceRole = "member" // Sees and joins the workspace's scenes and DMs
+)
+
+// Valid reports whether r is a known role.
+func (r WorkspaceRole) Valid() bool {
+	switch r {
+	case WorkspaceOwner, WorkspaceAdmin, WorkspaceMember:
+		return true
+	}
+	return false
+}
+
+// CanManage reports whether a member with role r may add and remove members.
+func (r WorkspaceRole) CanManage() bool {
+	return r == WorkspaceOwner || r == WorkspaceAdmin
+}
+
+// WorkspaceMembership is a user's membership of a workspace.
+type WorkspaceMembership struct {
+	WorkspaceID string        `json:"workspaceID"`
+	UserID      string        `json:"userID"`
+	Role        WorkspaceRole `json:"role"`
+	JoinedAt    time.Time     `json:"joinedAt"`
+}
+
+// WorkspaceRooms are the scenes and DM conversations of a workspace a member
+// could reach, returned when they are removed so their connections can be closed.
+type WorkspaceRooms struct {
+	SceneIDs []string // Every scene of the workspace
+	DMIDs    []string // The conversations of the workspace the member took part in
+}
@@ -0,0 +1,141 @@
+// Package cache puts an in-process LRU cache in front of the stores whose
+// reads dominate, for single-instance deployments. Concurrent misses for the
+// same key are collapsed into one store call with singleflight, so a burst
+// of identical requests reaches the database once.
+//
+// Writes made through the cached stores invalidate the entries they touch.
+// Writes made by other instances, or through other stores, are only seen once
+// entries expire, so deployments running several instances should leave the
+// cache off.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LRU is a fixed-size, string-keyed cache whose entries also expire after a
+// TTL. It is safe for concurrent use.
+type LRU[V any] struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	order   *list.List       // Front is the most recently used
+	loading map[string]*load // Loads under way, so Remove can mark them stale
+	group   singleflight.Group
+}
+
+// load is a store call under way for a key.
+type load struct {
+	stale bool // The key was removed since the call started; its result may predate a write
+}
+
+// entry is an element of LRU.order.
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU creates a cache holding up to size entries for at most ttl each.
+func NewLRU[V any](size int, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		size:    size,
+		ttl:     ttl,
+		items:   make(map[string]*list.Element, size),
+		order:   list.New(),
+		loading: make(map[string]*load),
+	}
+}
+
+// Get returns the value cached under key, if it has not expired.
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		if time.Now().Before(e.expiresAt) {
+			c.order.MoveToFront(el)
+			return e.value, true
+		}
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+	var zero V
+	return zero, false
+}
+
+// Remove drops key, so the next Get misses. A load of key already under way
+// is not stored, and the next Load starts a new one.
+func (c *LRU[V]) Remove(key string) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+	if l, ok := c.loading[key]; ok {
+		l.stale = true
+		delete(c.loading, key)
+	}
+	c.mu.Unlock()
+	c.group.Forget(key)
+}
+
+// Load returns the value cached under key or calls fetch to load it,
+// sharing one call among concurrent callers missing the same key. Errors
+// are not cached. The call outlives a caller that gives up, since others
+// may be waiting on it; fetch is expected to bound itself.
+func (c *LRU[V]) Load(ctx context.Context, key string, fetch func(context.Context) (V, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+	result, err, _ := c.group.Do(key, func() (any, error) {
+		l := &load{}
+		c.mu.Lock()
+		c.loading[key] = l
+		c.mu.Unlock()
+
+		value, err := fetch(context.WithoutCancel(ctx))
+		c.finish(key, l, value, err == nil)
+		return value, err
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return result.(V), nil
+}
+
+// finish ends load l of key, storing value if the load succeeded and the
+// key was not removed meanwhile. The least recently used entry is evicted
+// when the cache is full.
+func (c *LRU[V]) finish(key string, l *load, value V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loading[key] == l {
+		delete(c.loading, key)
+	}
+	if !ok || l.stale {
+		return
+	}
+	e := &entry[V]{key: key, value: value, expiresAt: time.Now().Add(c.ttl)}
+	if el, ok := c.items[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(e)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry[V]).key)
+	}
+}
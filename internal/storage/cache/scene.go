@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+)
+
+// SceneStore caches GetScene in front of another storage.SceneStore. Methods
+// that change a scene's row, its participants, or its RSVPs drop the scene
+// from the cache; every other method goes straight to the wrapped store.
+type SceneStore struct {
+	storage.SceneStore
+	scenes *LRU[*models.Scene]
+}
+
+var _ storage.SceneStore = (*SceneStore)(nil)
+
+// NewSceneStore caches up to size scenes of store for at most ttl each.
+func NewSceneStore(store storage.SceneStore, size int, ttl time.Duration) *SceneStore {
+	return &SceneStore{SceneStore: store, scenes: NewLRU[*models.Scene](size, ttl)}
+}
+
+// GetScene returns a copy of the cached scene, so callers may set fields
+// such as ActiveUsers without changing it for others.
+func (s *SceneStore) GetScene(ctx context.Context, sceneID string) (*models.Scene, error) {
+	scene, err := s.scenes.Load(ctx, sceneID, func(ctx context.Context) (*models.Scene, error) {
+		return s.SceneStore.GetScene(ctx, sceneID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	copied := *scene
+	return &copied, nil
+}
+
+// UpdateScene drops the scene from the cache.
+func (s *SceneStore) UpdateScene(ctx context.Context, sceneID string, update storage.SceneUpdate) (*models.Scene, error) {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.UpdateScene(ctx, sceneID, update)
+}
+
+// SetSceneCover drops the scene from the cache.
+func (s *SceneStore) SetSceneCover(ctx context.Context, sceneID, key string) (*models.Scene, string, error) {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.SetSceneCover(ctx, sceneID, key)
+}
+
+// SetSceneSlug drops the scene from the cache.
+func (s *SceneStore) SetSceneSlug(ctx context.Context, sceneID, slug string) (*models.Scene, error) {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.SetSceneSlug(ctx, sceneID, slug)
+}
+
+// DeleteScene drops the scene from the cache.
+func (s *SceneStore) DeleteScene(ctx context.Context, sceneID, creatorID string) (string, error) {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.DeleteScene(ctx, sceneID, creatorID)
+}
+
+// SetArchived drops the scene from the cache.
+func (s *SceneStore) SetArchived(ctx context.Context, sceneID string, archived bool) error {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.SetArchived(ctx, sceneID, archived)
+}
+
+// CloseScene drops the scene from the cache.
+func (s *SceneStore) CloseScene(ctx context.Context, sceneID string) (*models.Scene, error) {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.CloseScene(ctx, sceneID)
+}
+
+// Reschedule drops the scene from the cache.
+func (s *SceneStore) Reschedule(ctx context.Context, sceneID string, at time.Time) (*models.Scene, error) {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.Reschedule(ctx, sceneID, at)
+}
+
+// AddRSVP drops the scene from the cache.
+func (s *SceneStore) AddRSVP(ctx context.Context, sceneID, userID string) error {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.AddRSVP(ctx, sceneID, userID)
+}
+
+// RemoveRSVP drops the scene from the cache.
+func (s *SceneStore) RemoveRSVP(ctx context.Context, sceneID, userID string) error {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.RemoveRSVP(ctx, sceneID, userID)
+}
+
+// JoinScene drops the scene from the cache.
+func (s *SceneStore) JoinScene(ctx context.Context, sceneID, userID string) error {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.JoinScene(ctx, sceneID, userID)
+}
+
+// ResolveJoinRequest drops the scene from the cache.
+func (s *SceneStore) ResolveJoinRequest(ctx context.Context, sceneID, userID string, approve bool) error {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.ResolveJoinRequest(ctx, sceneID, userID, approve)
+}
+
+// LeaveScene drops the scene from the cache.
+func (s *SceneStore) LeaveScene(ctx context.Context, sceneID, userID string) error {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.LeaveScene(ctx, sceneID, userID)
+}
+
+// AddRestriction drops the scene from the cache.
+func (s *SceneStore) AddRestriction(ctx context.Context, sceneID, userID string, kind models.SceneRestriction, createdBy string) error {
+	defer s.scenes.Remove(sceneID)
+	return s.SceneStore.AddRestriction(ctx, sceneID, userID, kind, createdBy)
+}
+
+// ClaimReminders drops the scenes it returns from the cache.
+func (s *SceneStore) ClaimReminders(ctx context.Context, lead time.Duration) ([]*models.Scene, error) {
+	scenes, err := s.SceneStore.ClaimReminders(ctx, lead)
+	s.removeAll(scenes)
+	return scenes, err
+}
+
+// StartDueScenes drops the scenes it returns from the cache.
+func (s *SceneStore) StartDueScenes(ctx context.Context) ([]*models.Scene, error) {
+	scenes, err := s.SceneStore.StartDueScenes(ctx)
+	s.removeAll(scenes)
+	return scenes, err
+}
+
+// removeAll drops scenes from the cache.
+func (s *SceneStore) removeAll(scenes []*models.Scene) {
+	for _, scene := range scenes {
+		s.scenes.Remove(scene.ID)
+	}
+}
+
+// PlaybackStore caches GetPlayback in front of another storage.PlaybackStore.
+// Methods that change a scene's playback state drop it from the cache.
+type PlaybackStore struct {
+	storage.PlaybackStore
+	states *LRU[*models.PlaybackState]
+}
+
+var _ storage.PlaybackStore = (*PlaybackStore)(nil)
+
+// NewPlaybackStore caches up to size playback states of store for at most ttl each.
+func NewPlaybackStore(store storage.PlaybackStore, size int, ttl time.Duration) *PlaybackStore {
+	return &PlaybackStore{PlaybackStore: store, states: NewLRU[*models.PlaybackState](size, ttl)}
+}
+
+// GetPlayback returns a copy of the cached state.
+func (s *PlaybackStore) GetPlayback(ctx context.Context, sceneID string) (*models.PlaybackState, error) {
+	state, err := s.states.Load(ctx, sceneID, func(ctx context.Context) (*models.PlaybackState, error) {
+		return s.PlaybackStore.GetPlayback(ctx, sceneID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	copied := *state
+	return &copied, nil
+}
+
+// SetPlayback drops the scene's playback state from the cache.
+func (s *PlaybackStore) SetPlayback(ctx context.Context, state *models.PlaybackState) (*models.PlaybackState, error) {
+	defer s.states.Remove(state.SceneID)
+	return s.PlaybackStore.SetPlayback(ctx, state)
+}
+
+// SkipTrack drops the scene's playback state from the cache.
+func (s *PlaybackStore) SkipTrack(ctx context.Context, sceneID, trackID, updatedBy string) (*models.PlaybackState, *models.QueueItem, error) {
+	defer s.states.Remove(sceneID)
+	return s.PlaybackStore.SkipTrack(ctx, sceneID, trackID, updatedBy)
+}
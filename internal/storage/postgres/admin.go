@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAdminStore implements storage.AdminStore using PostgreSQL.
+type PostgresAdminStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.AdminStore = (*PostgresAdminStore)(nil)
+
+// NewPostgresAdminStore creates a new PostgresAdminStore backed by the shared pool db.
+func NewPostgresAdminStore(db *pgxpool.Pool) *PostgresAdminStore {
+	return &PostgresAdminStore{db: db}
+}
+
+// ListScenes returns scenes newest first.
+func (s *PostgresAdminStore) ListScenes(ctx context.Context, limit int, includeArchived bool) ([]*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + sceneColumns + `
+		FROM scenes s
+		WHERE $1 OR s.archived_at IS NULL
+		ORDER BY s.created_at DESC
+		LIMIT $2`
+	rows, err := s.db.Query(ctx, query, includeArchived, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list scenes: %w", err)
+	}
+	defer rows.Close()
+
+	var scenes []*models.Scene
+	for rows.Next() {
+		scene := &models.Scene{}
+		if err := scanScene(rows, scene); err != nil {
+			return nil, fmt.Errorf("scan scene row: %w", err)
+		}
+		scenes = append(scenes, scene)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scene rows: %w", err)
+	}
+	return scenes, nil
+}
+
+// purgeSteps deletes a user's data ($1 is the user ID), in order. Replies by
+// other users are detached from the user's DM messages first so they survive
+// the cascade, and conversations left without participants are removed.
+// Workspaces the user owns go last, taking their scenes, conversations, and
+// memberships with them.
+var purgeSteps = []struct {
+	table string
+	query string
+}{
+	{"dm_messages (replies detached)", `
+		UPDATE dm_messages SET parent_message_id = NULL
+		WHERE sender_id <> $1 AND parent_message_id IN (SELECT id FROM dm_messages WHERE sender_id = $1)`},
+	{"dm_messages", `DELETE FROM dm_messages WHERE sender_id = $1`},
+	{"scene_messages", `DELETE FROM scene_messages WHERE sender_id = $1`},
+	{"message_reactions", `DELETE FROM message_reactions WHERE user_id = $1`},
+	{"message_mentions", `DELETE FROM message_mentions WHERE user_id = $1`},
+	{"dm_message_receipts", `DELETE FROM dm_message_receipts WHERE user_id = $1`},
+	{"dm_scheduled_messages", `DELETE FROM dm_scheduled_messages WHERE sender_id = $1`},
+	{"dm_drafts", `DELETE FROM dm_drafts WHERE user_id = $1`},
+	{"dm_conversation_keys", `DELETE FROM dm_conversation_keys WHERE user_id = $1`},
+	{"user_public_keys", `DELETE FROM user_public_keys WHERE user_id = $1`},
+	{"attachments", `DELETE FROM attachments WHERE uploader_id = $1`},
+	{"dm_participants", `DELETE FROM dm_participants WHERE user_id = $1`},
+	{"dm_offline_events", `DELETE FROM dm_offline_events WHERE user_id = $1`},
+	{"dm_conversations", `
+		DELETE FROM dm_conversations c
+		WHERE NOT EXISTS (SELECT 1 FROM dm_participants p WHERE p.dm_conversation_id = c.id)`},
+	{"dm_conversations (creator cleared)", `UPDATE dm_conversations SET created_by = NULL WHERE created_by = $1`},
+	{"scenes", `DELETE FROM scenes WHERE creator_id::text = $1`},
+	{"scene_participants", `DELETE FROM scene_participants WHERE user_id = $1`},
+	{"scene_rsvps", `DELETE FROM scene_rsvps WHERE user_id = $1`},
+	{"scene_bans", `DELETE FROM scene_bans WHERE user_id = $1`},
+	{"scene_join_requests", `DELETE FROM scene_join_requests WHERE user_id = $1`},
+	{"scene_roles", `DELETE FROM scene_roles WHERE user_id = $1`},
+	{"scene_poll_votes", `DELETE FROM scene_poll_votes WHERE user_id = $1`},
+	{"scene_queue_votes", `DELETE FROM scene_queue_votes WHERE user_id = $1`},
+	{"scene_skip_votes", `DELETE FROM scene_skip_votes WHERE user_id = $1`},
+	{"scene_listen_sessions", `DELETE FROM scene_listen_sessions WHERE user_id = $1`},
+	{"scene_spotify_playlists", `DELETE FROM scene_spotify_playlists WHERE user_id = $1`},
+	{"scene_tickets", `DELETE FROM scene_tickets WHERE user_id = $1`},
+	{"scene_transcript_exports", `DELETE FROM scene_transcript_exports WHERE requested_by = $1`},
+	{"spotify_tokens", `DELETE FROM spotify_tokens WHERE user_id = $1`},
+	{"user_settings", `DELETE FROM user_settings WHERE user_id = $1`},
+	{"notification_overrides", `DELETE FROM notification_overrides WHERE user_id = $1`},
+	{"notification_queue", `DELETE FROM notification_queue WHERE user_id = $1`},
+	{"user_follows", `DELETE FROM user_follows WHERE follower_id = $1 OR followee_id = $1`},
+	{"scene_recommendations", `DELETE FROM scene_recommendations WHERE user_id = $1`},
+	{"message_flags", `DELETE FROM message_flags WHERE sender_id = $1`},
+	{"reports", `DELETE FROM reports WHERE reporter_id = $1`},
+	{"gifts", `DELETE FROM gifts WHERE sender_id = $1 OR recipient_id = $1`},
+	{"user_credits", `DELETE FROM user_credits WHERE user_id = $1`},
+	{"user_badges", `DELETE FROM user_badges WHERE user_id = $1`},
+	{"user_reputation", `DELETE FROM user_reputation WHERE user_id = $1`},
+	{"webhooks", `DELETE FROM webhooks WHERE owner_id = $1`},
+	{"workspace_members", `DELETE FROM workspace_members WHERE user_id = $1`},
+	{"workspaces", `DELETE FROM workspaces WHERE owner_id = $1`},
+	{"users", `DELETE FROM users WHERE id::text = $1`},
+}
+
+// PurgeUser deletes a user and their content in one transaction. Reports
+// about the user are kept for the moderation record. Uploaded objects are not
+// removed from blob storage.
+func (s *PostgresAdminStore) PurgeUser(ctx context.Context, userID string) ([]models.TableRows, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin purging user %s: %w", userID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var affected []models.TableRows
+	var total int64
+	for _, step := range purgeSteps {
+		tag, err := tx.Exec(ctx, step.query, userID)
+		if err != nil {
+			return nil, fmt.Errorf("purge %s of user %s: %w", step.table, userID, err)
+		}
+		if n := tag.RowsAffected(); n > 0 {
+			affected = append(affected, models.TableRows{Table: step.table, Rows: n})
+			total += n
+		}
+	}
+	if total == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit purge of user %s: %w", userID, err)
+	}
+	return affected, nil
+}
+
+// Stats returns instance-wide totals.
+func (s *PostgresAdminStore) Stats(ctx context.Context) (*models.PlatformStats, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	stats := &models.PlatformStats{}
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM scenes WHERE archived_at IS NULL AND status = 'live'),
+			(SELECT COUNT(*) FROM scenes WHERE archived_at IS NULL AND status = 'scheduled'),
+			(SELECT COUNT(*) FROM scenes WHERE archived_at IS NOT NULL),
+			(SELECT COUNT(*) FROM dm_conversations),
+			(SELECT COUNT(*) FROM dm_messages WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM scene_messages),
+			(SELECT COUNT(*) FROM attachments),
+			(SELECT COUNT(*) FROM reports WHERE status = 'open'),
+			(SELECT COALESCE(SUM(active_users), 0) FROM scenes WHERE archived_at IS NULL)`
+	err := s.db.QueryRow(ctx, query).Scan(
+		&stats.Users, &stats.LiveScenes, &stats.ScheduledScenes, &stats.ArchivedScenes, &stats.Conversations,
+		&stats.DMMessages, &stats.SceneMessages, &stats.Attachments, &stats.OpenReports, &stats.ActiveListeners,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get platform stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// purgeIndex returns the position of the purge step for table, or -1.
+func purgeIndex(table string) int {
+	for i, step := range purgeSteps {
+		if step.table == table {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestPurgeStepsOrder(t *testing.T) {
+	tests := []struct {
+		before, after string
+		why           string
+	}{
+		{"dm_messages (replies detached)", "dm_messages", "replies by others must be detached before the cascade deletes them"},
+		{"dm_participants", "dm_conversations", "conversations are removed once they have no participants left"},
+		{"scene_messages", "scenes", "the user's messages go before their scenes cascade"},
+		{"workspace_members", "users", "memberships reference the user"},
+	}
+	for _, tt := range tests {
+		before, after := purgeIndex(tt.before), purgeIndex(tt.after)
+		if before < 0 || after < 0 {
+			t.Errorf("purgeSteps is missing %q or %q", tt.before, tt.after)
+			continue
+		}
+		if before >= after {
+			t.Errorf("purge step %q (#%d) must come before %q (#%d): %s", tt.before, before, tt.after, after, tt.why)
+		}
+	}
+
+	if last := purgeSteps[len(purgeSteps)-1].table; last != "users" {
+		t.Errorf("last purge step = %q, want users so nothing is left referring to the user", last)
+	}
+}
+
+func TestPurgeStepsScoped(t *testing.T) {
+	// Steps that clean up after others rather than selecting by user
+	unscoped := map[string]bool{"dm_conversations": true}
+
+	seen := make(map[string]bool)
+	for _, step := range purgeSteps {
+		if seen[step.table] {
+			t.Errorf("purge step %q appears twice", step.table)
+		}
+		seen[step.table] = true
+
+		table, _, _ := strings.Cut(step.table, " ")
+		if !strings.Contains(step.query, " "+table+" ") && !strings.HasSuffix(step.query, " "+table) {
+			t.Errorf("purge step %q does not touch table %s", step.table, table)
+		}
+		if !unscoped[step.table] && !strings.Contains(step.query, "$1") {
+			t.Errorf("purge step %q does not filter by the user ID", step.table)
+		}
+	}
+}
+
+// Columns holding a user ID, by name.
+var userIDColumn = regexp.MustCompile(`^(user_id|sender_id|uploader_id|owner_id|creator_id|reporter_id|reported_user_id|recipient_id|follower_id|followee_id|\w+_by)$`)
+
+var (
+	createTable = regexp.MustCompile(`(?s)CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*?)\n\);`)
+	addColumn   = regexp.MustCompile(`ALTER TABLE (\w+) ADD COLUMN (?:IF NOT EXISTS )?(\w+)`)
+	columnDef   = regexp.MustCompile(`(?m)^\s+(\w+)\s`)
+)
+
+// userIDColumns returns the user ID columns the migrations create, as "table.column".
+func userIDColumns(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob("../../../migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	var cols []string
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range createTable.FindAllStringSubmatch(string(sql), -1) {
+			for _, c := range columnDef.FindAllStringSubmatch(m[2], -1) {
+				if userIDColumn.MatchString(c[1]) {
+					cols = append(cols, m[1]+"."+c[1])
+				}
+			}
+		}
+		for _, m := range addColumn.FindAllStringSubmatch(string(sql), -1) {
+			if userIDColumn.MatchString(m[2]) {
+				cols = append(cols, m[1]+"."+m[2])
+			}
+		}
+	}
+	return cols
+}
+
+func TestPurgeStepsCoverUserColumns(t *testing.T) {
+	// Columns left alone, and why
+	kept := map[string]string{
+		"reports.reported_user_id":       "reports about the user are kept for the moderation record",
+		"reports.resolved_by":            "the admin who resolved a report is part of the moderation record",
+		"dm_conversation_keys.sender_id": "keys the user wrapped for others are still needed by their recipients",
+		"scene_playback.updated_by":      "records who last changed another scene's playback",
+		"scene_playback.started_by":      "records who started another scene's playback",
+		"scene_queue.added_by":           "removing queued tracks would reorder another scene's queue",
+		"scene_play_history.played_by":   "part of another scene's play history",
+		"scene_bans.created_by":          "bans the user issued stay in force",
+		"scene_pins.pinned_by":           "pins the user made stay on the scene",
+		"scene_roles.assigned_by":        "roles the user assigned stay in force",
+		"scene_polls.created_by":         "polls belong to the scene and hold others' votes",
+	}
+
+	cols := userIDColumns(t)
+	covered := make(map[string]bool)
+	for _, col := range cols {
+		table, column, _ := strings.Cut(col, ".")
+		for _, step := range purgeSteps {
+			if name, _, _ := strings.Cut(step.table, " "); name == table && strings.Contains(step.query, column) {
+				covered[col] = true
+			}
+		}
+		if !covered[col] && kept[col] == "" {
+			t.Errorf("no purge step clears %s; add one or list it as kept", col)
+		}
+	}
+	for col := range kept {
+		if covered[col] {
+			t.Errorf("%s is listed as kept but a purge step clears it", col)
+		}
+		if !slices.Contains(cols, col) {
+			t.Errorf("%s is listed as kept but no migration creates it", col)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAnalyticsStore implements storage.AnalyticsStore using PostgreSQL.
+type PostgresAnalyticsStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.AnalyticsStore = (*PostgresAnalyticsStore)(nil)
+
+// NewPostgresAnalyticsStore creates a new PostgresAnalyticsStore backed by the shared pool db.
+func NewPostgresAnalyticsStore(db *pgxpool.Pool) *PostgresAnalyticsStore {
+	return &PostgresAnalyticsStore{db: db}
+}
+
+// sessionEnd is the effective end of a session row. Sessions left open by an
+// instance that stopped abruptly are capped at 12 hours so they do not count
+// as listening forever.
+const sessionEnd = `COALESCE(left_at, LEAST(NOW(), joined_at + INTERVAL '12 hours'))`
+
+// StartListening opens a listening session.
+func (s *PostgresAnalyticsStore) StartListening(ctx context.Context, sceneID, userID string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `INSERT INTO scene_listen_sessions (scene_id, user_id, joined_at) VALUES ($1, $2, $3)`, sceneID, userID, at)
+	if err != nil {
+		return fmt.Errorf("start listening session for %s in scene %s: %w", userID, sceneID, err)
+	}
+	return nil
+}
+
+// StopListening closes the user's most recent open session in the scene, if any.
+func (s *PostgresAnalyticsStore) StopListening(ctx context.Context, sceneID, userID string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE scene_listen_sessions SET left_at = GREATEST($3, joined_at)
+		WHERE id = (
+			SELECT id FROM scene_listen_sessions
+			WHERE scene_id = $1 AND user_id = $2 AND left_at IS NULL
+			ORDER BY joined_at DESC
+			LIMIT 1
+		)`
+	if _, err := s.db.Exec(ctx, query, sceneID, userID, at); err != nil {
+		return fmt.Errorf("stop listening session for %s in scene %s: %w", userID, sceneID, err)
+	}
+	return nil
+}
+
+// GetSceneAnalytics aggregates the sessions overlapping [from, to). A
+// returning listener is one with more than one session in the scene up to to.
+func (s *PostgresAnalyticsStore) GetSceneAnalytics(ctx context.Context, sceneID string, from, to time.Time, bucket models.AnalyticsBucket) (*models.SceneAnalytics, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	analytics := &models.SceneAnalytics{SceneID: sceneID, From: from, To: to, Bucket: bucket, Listeners: []models.ListenerCount{}}
+
+	summary := `
+		WITH windowed AS (
+			SELECT user_id, joined_at, left_at FROM scene_listen_sessions
+			WHERE scene_id = $1 AND joined_at < $3 AND ` + sessionEnd + ` >= $2
+		), listeners AS (
+			SELECT w.user_id,
+				(SELECT COUNT(*) FROM scene_listen_sessions p
+				 WHERE p.scene_id = $1 AND p.user_id = w.user_id AND p.joined_at < $3) AS sessions
+			FROM (SELECT DISTINCT user_id FROM windowed) w
+		)
+		SELECT
+			(SELECT COUNT(*) FROM windowed),
+			(SELECT COALESCE(AVG(EXTRACT(EPOCH FROM left_at - joined_at)), 0)::float8 FROM windowed WHERE left_at IS NOT NULL),
+			(SELECT COUNT(*) FROM listeners),
+			(SELECT COUNT(*) FROM listeners WHERE sessions > 1)`
+	err := s.db.QueryRow(ctx, summary, sceneID, from, to).Scan(
+		&analytics.Sessions, &analytics.AvgSessionSeconds, &analytics.UniqueListeners, &analytics.ReturningListeners,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summarize listening sessions for scene %s: %w", sceneID, err)
+	}
+	if analytics.UniqueListeners > 0 {
+		analytics.ReturningRate = float64(analytics.ReturningListeners) / float64(analytics.UniqueListeners)
+	}
+
+	series := `
+		SELECT b.bucket, COUNT(DISTINCT s.user_id)
+		FROM generate_series(date_trunc($4, $2::timestamptz), $3::timestamptz, ('1 ' || $4)::interval) AS b(bucket)
+		LEFT JOIN scene_listen_sessions s
+			ON s.scene_id = $1 AND s.joined_at < LEAST(b.bucket + ('1 ' || $4)::interval, $3)
+			AND ` + sessionEnd + ` >= GREATEST(b.bucket, $2)
+		WHERE b.bucket < $3
+		GROUP BY b.bucket
+		ORDER BY b.bucket`
+	rows, err := s.db.Query(ctx, series, sceneID, from, to, string(bucket))
+	if err != nil {
+		return nil, fmt.Errorf("count listeners over time for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var point models.ListenerCount
+		if err := rows.Scan(&point.Time, &point.Listeners); err != nil {
+			return nil, fmt.Errorf("scan listener count row for scene %s: %w", sceneID, err)
+		}
+		analytics.Listeners = append(analytics.Listeners, point)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate listener count rows for scene %s: %w", sceneID, err)
+	}
+	return analytics, nil
+}
+
+// RecordConcurrency stores one concurrency sample and refreshes active_users.
+// Scene IDs that are not valid scenes are ignored.
+func (s *PostgresAnalyticsStore) RecordConcurrency(ctx context.Context, counts map[string]int, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ids := make([]string, 0, len(counts))
+	listeners := make([]int32, 0, len(counts))
+	for id, n := range counts {
+		if n > 0 {
+			ids = append(ids, id)
+			listeners = append(listeners, int32(n))
+		}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin recording scene concurrency: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `UPDATE scenes SET active_users = 0 WHERE active_users <> 0 AND NOT (id::text = ANY($1))`, ids)
+	if err != nil {
+		return fmt.Errorf("reset idle scene active users: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE scenes s SET active_users = c.n
+		FROM unnest($1::text[], $2::int[]) AS c(id, n)
+		WHERE s.id::text = c.id AND s.active_users <> c.n`,
+		ids, listeners,
+	)
+	if err != nil {
+		return fmt.Errorf("update scene active users: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO scene_stats (scene_id, day, samples, total_listeners, peak_listeners, peak_at)
+		SELECT s.id, ($3::timestamptz AT TIME ZONE 'UTC')::date, 1, c.n, c.n, $3
+		FROM unnest($1::text[], $2::int[]) AS c(id, n)
+		JOIN scenes s ON s.id::text = c.id
+		ON CONFLICT (scene_id, day) DO UPDATE SET
+			samples = scene_stats.samples + 1,
+			total_listeners = scene_stats.total_listeners + EXCLUDED.total_listeners,
+			peak_at = CASE WHEN EXCLUDED.peak_listeners > scene_stats.peak_listeners THEN EXCLUDED.peak_at ELSE scene_stats.peak_at END,
+			peak_listeners = GREATEST(scene_stats.peak_listeners, EXCLUDED.peak_listeners)`,
+		ids, listeners, at,
+	)
+	if err != nil {
+		return fmt.Errorf("record scene stats sample: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit scene concurrency sample: %w", err)
+	}
+	return nil
+}
+
+// GetSceneStats returns the daily concurrency stats of the last days days, newest first.
+func (s *PostgresAnalyticsStore) GetSceneStats(ctx context.Context, sceneID string, days int) ([]models.SceneDayStats, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT to_char(day, 'YYYY-MM-DD'), peak_listeners, peak_at, total_listeners::float8 / GREATEST(samples, 1), samples
+		FROM scene_stats
+		WHERE scene_id = $1 AND day > (NOW() AT TIME ZONE 'UTC')::date - $2::int
+		ORDER BY day DESC`
+	rows, err := s.db.Query(ctx, query, sceneID, days)
+	if err != nil {
+		return nil, fmt.Errorf("get stats for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	var stats []models.SceneDayStats
+	for rows.Next() {
+		var day models.SceneDayStats
+		if err := rows.Scan(&day.Day, &day.PeakListeners, &day.PeakAt, &day.AvgListeners, &day.Samples); err != nil {
+			return nil, fmt.Errorf("scan stats row for scene %s: %w", sceneID, err)
+		}
+		stats = append(stats, day)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate stats rows for scene %s: %w", sceneID, err)
+	}
+	return stats, nil
+}
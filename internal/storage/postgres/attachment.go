@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAttachmentStore implements storage.AttachmentStore using PostgreSQL.
+type PostgresAttachmentStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.AttachmentStore = (*PostgresAttachmentStore)(nil)
+
+// NewPostgresAttachmentStore creates a new PostgresAttachmentStore backed by the shared pool db.
+func NewPostgresAttachmentStore(db *pgxpool.Pool) *PostgresAttachmentStore {
+	return &PostgresAttachmentStore{db: db}
+}
+
+// attachmentColumns selects an attachment row for scanAttachment.
+const attachmentColumns = `id, object_key, uploader_id, filename, content_type, size_bytes, created_at`
+
+// scanAttachment scans a row selected with attachmentColumns and fills in its download URL.
+func scanAttachment(row interface{ Scan(...any) error }, att *models.Attachment) error {
+	err := row.Scan(&att.ID, &att.Key, &att.UploaderID, &att.Name, &att.ContentType, &att.Size, &att.CreatedAt)
+	if err == nil {
+		att.URL = models.AttachmentURL(att.ID)
+	}
+	return err
+}
+
+// CreateAttachment records an attachment whose upload URL has been issued.
+func (s *PostgresAttachmentStore) CreateAttachment(ctx context.Context, att *models.Attachment) (*models.Attachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	created := &models.Attachment{}
+	query := `
+		INSERT INTO attachments (object_key, uploader_id, filename, content_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + attachmentColumns
+	err := scanAttachment(s.db.QueryRow(ctx, query, att.Key, att.UploaderID, att.Name, att.ContentType, att.Size), created)
+	if err != nil {
+		return nil, fmt.Errorf("create attachment %s: %w", att.Key, err)
+	}
+	return created, nil
+}
+
+// GetAttachment retrieves an attachment by ID.
+func (s *PostgresAttachmentStore) GetAttachment(ctx context.Context, attachmentID string) (*models.Attachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	att := &models.Attachment{}
+	err := scanAttachment(s.db.QueryRow(ctx, `SELECT `+attachmentColumns+` FROM attachments WHERE id = $1`, attachmentID), att)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get attachment %s: %w", attachmentID, err)
+	}
+	return att, nil
+}
+
+// CountUnlinked counts the attachments in ids uploaded by uploaderID and not yet sent.
+func (s *PostgresAttachmentStore) CountUnlinked(ctx context.Context, uploaderID string, ids []string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM attachments
+		WHERE id::text = ANY($1) AND uploader_id = $2
+			AND dm_message_id IS NULL AND scene_message_id IS NULL`,
+		ids, uploaderID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count unlinked attachments for %s: %w", uploaderID, err)
+	}
+	return count, nil
+}
+
+// LinkToDMMessage attaches ids to a DM message.
+func (s *PostgresAttachmentStore) LinkToDMMessage(ctx context.Context, messageID, uploaderID string, ids []string) ([]models.Attachment, error) {
+	return s.link(ctx, "dm_message_id", messageID, uploaderID, ids)
+}
+
+// LinkToSceneMessage attaches ids to a scene message.
+func (s *PostgresAttachmentStore) LinkToSceneMessage(ctx context.Context, messageID, uploaderID string, ids []string) ([]models.Attachment, error) {
+	return s.link(ctx, "scene_message_id", messageID, uploaderID, ids)
+}
+
+// link sets column to messageID on every attachment in ids, all or nothing.
+func (s *PostgresAttachmentStore) link(ctx context.Context, column, messageID, uploaderID string, ids []string) ([]models.Attachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin linking attachments to message %s: %w", messageID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE attachments SET `+column+` = $1
+		WHERE id::text = ANY($2) AND uploader_id = $3
+			AND dm_message_id IS NULL AND scene_message_id IS NULL
+		RETURNING `+attachmentColumns,
+		messageID, ids, uploaderID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("link attachments to message %s: %w", messageID, err)
+	}
+	var linked []models.Attachment
+	for rows.Next() {
+		att := models.Attachment{}
+		if err := scanAttachment(rows, &att); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan linked attachment for message %s: %w", messageID, err)
+		}
+		linked = append(linked, att)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate linked attachments for message %s: %w", messageID, err)
+	}
+	if len(linked) != len(ids) {
+		return nil, storage.ErrConflict
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit attachments for message %s: %w", messageID, err)
+	}
+	log.Printf("Linked %d attachment(s) to message %s", len(linked), messageID)
+	return linked, nil
+}
+
+// loadAttachments returns the attachments of each message in messageIDs,
+// keyed by message ID. column is dm_message_id or scene_message_id.
+func loadAttachments(ctx context.Context, db *pgxpool.Pool, column string, messageIDs []string) (map[string][]models.Attachment, error) {
+	attachments := make(map[string][]models.Attachment)
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT `+column+`::text, `+attachmentColumns+`
+		FROM attachments
+		WHERE `+column+` = ANY($1)
+		ORDER BY created_at, id`,
+		messageIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load attachments for %d messages: %w", len(messageIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID string
+		att := models.Attachment{}
+		err := rows.Scan(&messageID, &att.ID, &att.Key, &att.UploaderID, &att.Name, &att.ContentType, &att.Size, &att.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan attachment row: %w", err)
+		}
+		att.URL = models.AttachmentURL(att.ID)
+		attachments[messageID] = append(attachments[messageID], att)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate attachment rows: %w", err)
+	}
+	return attachments, nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresBadgeStore implements storage.BadgeStore using PostgreSQL.
+type PostgresBadgeStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.BadgeStore = (*PostgresBadgeStore)(nil)
+
+// NewPostgresBadgeStore creates a new PostgresBadgeStore backed by the shared pool db.
+func NewPostgresBadgeStore(db *pgxpool.Pool) *PostgresBadgeStore {
+	return &PostgresBadgeStore{db: db}
+}
+
+// AwardBadge records a badge unless the user already has it.
+func (s *PostgresBadgeStore) AwardBadge(ctx context.Context, userID string, badge models.Badge, sceneID string) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		INSERT INTO user_badges (user_id, badge, scene_id) VALUES ($1, $2, NULLIF($3, '')::uuid)
+		ON CONFLICT (user_id, badge) DO NOTHING`,
+		userID, badge, sceneID)
+	if err != nil {
+		return false, fmt.Errorf("award badge %s to user %s: %w", badge, userID, err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// HasBadge reports whether a user has earned a badge.
+func (s *PostgresBadgeStore) HasBadge(ctx context.Context, userID string, badge models.Badge) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var has bool
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM user_badges WHERE user_id = $1 AND badge = $2)`, userID, badge).Scan(&has)
+	if err != nil {
+		return false, fmt.Errorf("check badge %s of user %s: %w", badge, userID, err)
+	}
+	return has, nil
+}
+
+// GetUserBadges returns a user's badges, oldest first. Badges no longer in
+// models.Badges are skipped.
+func (s *PostgresBadgeStore) GetUserBadges(ctx context.Context, userID string) ([]models.UserBadge, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT badge, COALESCE(scene_id::text, ''), awarded_at FROM user_badges
+		WHERE user_id = $1
+		ORDER BY awarded_at, badge`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("get badges of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	badges := []models.UserBadge{}
+	for rows.Next() {
+		b := models.UserBadge{UserID: userID}
+		if err := rows.Scan(&b.Badge, &b.SceneID, &b.AwardedAt); err != nil {
+			return nil, fmt.Errorf("scan badge of user %s: %w", userID, err)
+		}
+		info, ok := b.Badge.Info()
+		if !ok {
+			continue
+		}
+		b.BadgeInfo = info
+		badges = append(badges, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get badges of user %s: %w", userID, err)
+	}
+	return badges, nil
+}
+
+// CountSceneListeners counts the distinct users with a listening session in a scene.
+func (s *PostgresBadgeStore) CountSceneListeners(ctx context.Context, sceneID string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRow(ctx, `SELECT COUNT(DISTINCT user_id) FROM scene_listen_sessions WHERE scene_id = $1`, sceneID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count listeners of scene %s: %w", sceneID, err)
+	}
+	return count, nil
+}
+
+// ListeningStreak counts the consecutive UTC days up to the day of at on
+// which a user joined a scene. Going back from that day, a listening day's
+// age in days equals its rank until the first missed day.
+func (s *PostgresBadgeStore) ListeningStreak(ctx context.Context, userID string, at time.Time) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var streak int
+	err := s.db.QueryRow(ctx, `
+		WITH days AS (
+			SELECT DISTINCT (joined_at AT TIME ZONE 'UTC')::date AS day
+			FROM scene_listen_sessions
+			WHERE user_id = $1 AND joined_at <= $2
+		)
+		SELECT COUNT(*) FROM (
+			SELECT ($2::timestamptz AT TIME ZONE 'UTC')::date - day AS age,
+				ROW_NUMBER() OVER (ORDER BY day DESC) - 1 AS rank
+			FROM days
+		) ranked
+		WHERE age = rank`,
+		userID, at,
+	).Scan(&streak)
+	if err != nil {
+		return 0, fmt.Errorf("get listening streak of user %s: %w", userID, err)
+	}
+	return streak, nil
+}
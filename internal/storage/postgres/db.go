@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig sizes the connection pool shared by all Postgres stores.
+type PoolConfig struct {
+	MaxConns        int32         // Max number of open connections to the database
+	MinConns        int32         // Connections kept open even when idle
+	MaxConnLifetime time.Duration // Max lifetime for a connection
+}
+
+// DefaultPoolConfig returns the pool settings used when none are configured.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxConns:        25,
+		MinConns:        2,
+		MaxConnLifetime: 5 * time.Minute,
+	}
+}
+
+// Open connects to PostgreSQL and returns a pool sized by cfg. The pool is
+// meant to be created once and passed to every store constructor; the caller
+// is responsible for closing it.
+func Open(dataSourceName string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database connection string: %w", err)
+	}
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// Ping the database to verify the connection
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	log.Printf("Successfully connected to PostgreSQL database (max conns %d, min conns %d, max lifetime %s).",
+		cfg.MaxConns, cfg.MinConns, cfg.MaxConnLifetime)
+	return db, nil
+}
@@ -1,183 +1,1211 @@
 package postgres
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort" // To ensure consistent participant order for unique constraint
+	"strconv"
 	"time"
 
 	"github.com/Vasu1712/scenyx-backend/internal/models"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// PostgresDMStore implements the DM storage interface using PostgreSQL.
+// PostgresDMStore implements storage.DMStore using PostgreSQL.
 type PostgresDMStore struct {
-	db *sql.DB
+	db *pgxpool.Pool
 }
 
-// NewPostgresDMStore creates a new PostgresDMStore instance.
-func NewPostgresDMStore(dataSourceName string) (*PostgresDMStore, error) {
-	db, err := sql.Open("postgres", dataSourceName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database connection for DMs: %w", err)
+var _ storage.DMStore = (*PostgresDMStore)(nil)
+
+// NewPostgresDMStore creates a new PostgresDMStore backed by the shared pool db.
+func NewPostgresDMStore(db *pgxpool.Pool) *PostgresDMStore {
+	return &PostgresDMStore{db: db}
+}
+
+// participantAvatarsColumn selects the avatar keys of a conversation's
+// participants as a JSON object keyed by user ID, NULL if none has one.
+const participantAvatarsColumn = `
+	(SELECT jsonb_object_agg(p.user_id, u.avatar_key)
+	 FROM dm_participants p JOIN users u ON u.id::text = p.user_id
+	 WHERE p.dm_conversation_id = c.id AND u.avatar_key IS NOT NULL) AS avatars
+`
+
+// conversationColumns selects a conversation row along with its participant IDs and avatars.
+const conversationColumns = `
+	c.id, c.name, c.is_group, COALESCE(c.workspace_id::text, ''),
+	ARRAY(SELECT p.user_id FROM dm_participants p WHERE p.dm_conversation_id = c.id ORDER BY p.joined_at, p.user_id) AS participants,
+	c.created_at, c.updated_at, COALESCE(c.message_ttl_seconds, 0), COALESCE(c.created_by, ''),
+` + participantAvatarsColumn
+
+// scanConversation scans a row selected with conversationColumns.
+// Any extra destinations are scanned from columns following conversationColumns.
+func scanConversation(row interface{ Scan(...any) error }, conv *models.DMConversation, extra ...any) error {
+	dest := []any{&conv.ID, &conv.Name, &conv.IsGroup, &conv.WorkspaceID, &conv.Participants, &conv.CreatedAt, &conv.UpdatedAt, &conv.MessageTTL, &conv.CreatorID, &conv.Avatars}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
 	}
+	resolveAvatars(conv.Avatars)
+	return nil
+}
 
-	err = db.Ping()
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database for DMs: %w", err)
+// resolveAvatars replaces the avatar keys in avatars with their URLs.
+func resolveAvatars(avatars map[string]string) {
+	for userID, key := range avatars {
+		avatars[userID] = models.AvatarURL(userID, key)
+	}
+}
+
+// conversationPreviewJoins attach to each conversation c its latest message
+// and, in one-to-one conversations, the participant other than the user $1.
+const conversationPreviewJoins = `
+	LEFT JOIN LATERAL (
+		SELECT m.id, m.sender_id, m.content, m.timestamp, m.deleted_at IS NOT NULL AS deleted,
+			m.key_version IS NOT NULL AS encrypted
+		FROM dm_messages m
+		WHERE m.dm_conversation_id = c.id AND (m.expires_at IS NULL OR m.expires_at > NOW())
+		ORDER BY m.timestamp DESC, m.id DESC
+		LIMIT 1
+	) latest ON TRUE
+	LEFT JOIN LATERAL (
+		SELECT u.id, u.display_name, u.avatar_key
+		FROM dm_participants p JOIN users u ON u.id::text = p.user_id
+		WHERE p.dm_conversation_id = c.id AND p.user_id <> $1 AND NOT c.is_group
+		LIMIT 1
+	) counterpart ON TRUE
+`
+
+// conversationPreviewColumns selects the joined preview for conversationPreview.
+var conversationPreviewColumns = `
+	latest.id, latest.sender_id, CASE WHEN latest.encrypted THEN '' ELSE left(latest.content, ` + strconv.Itoa(models.DMSnippetLength) + `) END,
+	latest.timestamp, latest.deleted, latest.encrypted,
+	counterpart.id, counterpart.display_name, COALESCE(counterpart.avatar_key, '')
+`
+
+// conversationPreview scans conversationPreviewColumns; the joins leave
+// every column NULL when there is no message or counterpart.
+type conversationPreview struct {
+	msgID, senderID, snippet *string
+	timestamp                *time.Time
+	deleted, encrypted       *bool
+	userID, displayName      *string
+	avatarKey                string
+}
+
+func (p *conversationPreview) dest() []any {
+	return []any{&p.msgID, &p.senderID, &p.snippet, &p.timestamp, &p.deleted, &p.encrypted, &p.userID, &p.displayName, &p.avatarKey}
+}
+
+// apply sets the conversation's LastMessage and Counterpart.
+func (p *conversationPreview) apply(conv *models.DMConversation) {
+	if p.msgID != nil {
+		conv.LastMessage = &models.DMMessagePreview{
+			ID:        *p.msgID,
+			SenderID:  *p.senderID,
+			Snippet:   *p.snippet,
+			Timestamp: *p.timestamp,
+			Deleted:   *p.deleted,
+			Encrypted: *p.encrypted,
+		}
 	}
+	if p.userID != nil {
+		conv.Counterpart = &models.DMCounterpart{
+			UserID:      *p.userID,
+			DisplayName: *p.displayName,
+			AvatarURL:   models.AvatarURL(*p.userID, p.avatarKey),
+		}
+	}
+}
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(10)
-	db.SetConnMaxLifetime(5 * time.Minute)
+// messageColumns selects a DM message row for scanMessage.
+const messageColumns = `id, dm_conversation_id, sender_id, content, timestamp, edited_at, deleted_at, parent_message_id, expires_at, key_version`
 
-	log.Println("Successfully connected to PostgreSQL database for DMs.")
+// liveMessage filters out messages past their expiry that the expiry job
+// has not deleted yet.
+const liveMessage = `(expires_at IS NULL OR expires_at > NOW())`
 
-	return &PostgresDMStore{db: db}, nil
+// scanMessage scans a row selected with messageColumns.
+// Any extra destinations are scanned from columns following messageColumns.
+func scanMessage(row interface{ Scan(...any) error }, msg *models.DMMessage, extra ...any) error {
+	dest := []any{
+		&msg.ID, &msg.DMConversationID, &msg.SenderID, &msg.Content, &msg.Timestamp, &msg.EditedAt, &msg.DeletedAt,
+		&msg.ParentMessageID, &msg.ExpiresAt, &msg.KeyVersion,
+	}
+	return row.Scan(append(dest, extra...)...)
 }
 
-// StartOrGetConversation finds an existing conversation between two users or creates a new one.
-func (s *PostgresDMStore) StartOrGetConversation(user1, user2 string) *models.DMConversation {
-	// Ensure consistent order of participants to handle the UNIQUE constraint
+// StartOrGetConversation finds an existing one-to-one conversation between two users or creates a new one.
+// Each workspace, and the space outside them, has its own conversation per pair.
+func (s *PostgresDMStore) StartOrGetConversation(ctx context.Context, workspaceID, user1, user2 string) (*models.DMConversation, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	// Ensure consistent order of participants so the direct key is unique per pair
 	participants := []string{user1, user2}
 	sort.Strings(participants)
 	p1, p2 := participants[0], participants[1]
+	directKey := p1 + ":" + p2
+	if workspaceID != "" {
+		directKey = workspaceID + ":" + directKey
+	}
 
 	conv := &models.DMConversation{}
-	query := `
-		SELECT id, participant1_id, participant2_id, created_at, updated_at
-		FROM dm_conversations
-		WHERE (participant1_id = $1 AND participant2_id = $2)
-	`
-	err := s.db.QueryRow(query, p1, p2).Scan(
-		&conv.ID, &conv.Participants[0], &conv.Participants[1], &conv.CreatedAt, &conv.UpdatedAt,
-	)
+	query := `SELECT ` + conversationColumns + ` FROM dm_conversations c WHERE c.direct_key = $1`
+	err := scanConversation(s.db.QueryRow(ctx, query, directKey), conv)
 
-	if err == sql.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		// Conversation does not exist, create a new one
-		insertQuery := `
-			INSERT INTO dm_conversations (participant1_id, participant2_id)
-			VALUES ($1, $2)
-			RETURNING id, participant1_id, participant2_id, created_at, updated_at
-		`
-		err = s.db.QueryRow(insertQuery, p1, p2).Scan(
-			&conv.ID, &conv.Participants[0], &conv.Participants[1], &conv.CreatedAt, &conv.UpdatedAt,
-		)
+		conv, err = s.createConversation(ctx, workspaceID, "", "", &directKey, participants)
 		if err != nil {
-			log.Printf("Error creating new DM conversation: %v", err)
-			return nil
+			return nil, err
 		}
 		log.Printf("Created new DM conversation: %s between %s and %s", conv.ID, p1, p2)
-		return conv
+		return conv, nil
 	} else if err != nil {
-		log.Printf("Error getting DM conversation: %v", err)
-		return nil
+		return nil, fmt.Errorf("get DM conversation between %s and %s: %w", p1, p2, err)
+	}
+
+	return conv, nil
+}
+
+// CreateGroupConversation creates a named group conversation containing the creator and the given members.
+func (s *PostgresDMStore) CreateGroupConversation(ctx context.Context, workspaceID, name, creatorID string, memberIDs []string) (*models.DMConversation, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	participants := []string{creatorID}
+	seen := map[string]bool{creatorID: true}
+	for _, id := range memberIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		participants = append(participants, id)
+	}
+
+	conv, err := s.createConversation(ctx, workspaceID, name, creatorID, nil, participants)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Created group DM conversation: %s (%s) with %d participants", conv.ID, name, len(conv.Participants))
+	return conv, nil
+}
+
+// createConversation inserts a conversation and its participants in a single transaction.
+// A group is created when creatorID is set, and a one-to-one conversation otherwise.
+// In a workspace it returns storage.ErrForbidden unless every participant is a member.
+func (s *PostgresDMStore) createConversation(ctx context.Context, workspaceID, name, creatorID string, directKey *string, participants []string) (*models.DMConversation, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin DM conversation transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if workspaceID != "" {
+		members, err := areWorkspaceMembers(ctx, tx, workspaceID, participants)
+		if err != nil {
+			return nil, err
+		}
+		if !members {
+			return nil, storage.ErrForbidden
+		}
+	}
+
+	conv := &models.DMConversation{Name: name, IsGroup: creatorID != "", CreatorID: creatorID, WorkspaceID: workspaceID}
+	insertQuery := `
+		INSERT INTO dm_conversations (name, is_group, direct_key, workspace_id, created_by)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, NULLIF($5, ''))
+		RETURNING id, created_at, updated_at
+	`
+	err = tx.QueryRow(ctx, insertQuery, name, conv.IsGroup, directKey, workspaceID, creatorID).Scan(&conv.ID, &conv.CreatedAt, &conv.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("create DM conversation: %w", err)
+	}
+
+	for _, userID := range participants {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO dm_participants (dm_conversation_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			conv.ID, userID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("add participant %s to DM conversation %s: %w", userID, conv.ID, err)
+		}
 	}
 
-	log.Printf("Retrieved existing DM conversation: %s between %s and %s", conv.ID, p1, p2)
-	return conv
+	err = tx.QueryRow(ctx, `SELECT `+participantAvatarsColumn+` FROM dm_conversations c WHERE c.id = $1`, conv.ID).Scan(&conv.Avatars)
+	if err != nil {
+		return nil, fmt.Errorf("get avatars for DM conversation %s: %w", conv.ID, err)
+	}
+	resolveAvatars(conv.Avatars)
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit DM conversation %s: %w", conv.ID, err)
+	}
+
+	conv.Participants = participants
+	return conv, nil
+}
+
+// GetConversation retrieves a single conversation by ID.
+// It returns storage.ErrNotFound if no such conversation exists.
+func (s *PostgresDMStore) GetConversation(ctx context.Context, dmID string) (*models.DMConversation, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	conv := &models.DMConversation{}
+	query := `SELECT ` + conversationColumns + ` FROM dm_conversations c WHERE c.id = $1`
+	err := scanConversation(s.db.QueryRow(ctx, query, dmID), conv)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get DM conversation %s: %w", dmID, err)
+	}
+	return conv, nil
 }
 
-// GetConversations lists all conversations a user is a part of.
-func (s *PostgresDMStore) GetConversations(userID string) []*models.DMConversation {
+// GetConversations lists a page of the conversations a user is a part of in
+// page.WorkspaceID, or outside any workspace, most recently active first.
+func (s *PostgresDMStore) GetConversations(ctx context.Context, userID string, page storage.ConversationPage) ([]*models.DMConversation, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	var convs []*models.DMConversation
+	limit := page.NormalizedLimit()
+
+	// The cursor compares on (updated_at, id) so conversations sharing a
+	// timestamp are neither skipped nor repeated across pages.
+	args := []any{userID, limit, page.Archived, page.WorkspaceID}
+	after := ""
+	if page.Cursor != "" {
+		updatedAt, id, err := storage.ParseConversationCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = `AND (c.updated_at, c.id) < ($5, $6::uuid)`
+		args = append(args, updatedAt, id)
+	}
 	query := `
-		SELECT id, participant1_id, participant2_id, created_at, updated_at
-		FROM dm_conversations
-		WHERE participant1_id = $1 OR participant2_id = $1
-		ORDER BY updated_at DESC
+		SELECT ` + conversationColumns + `, me.unread_count, me.archived_at IS NOT NULL, me.muted_at IS NOT NULL, ` + conversationPreviewColumns + `
+		FROM dm_conversations c
+		JOIN dm_participants me ON me.dm_conversation_id = c.id AND me.user_id = $1
+			AND (me.archived_at IS NOT NULL) = $3
+		` + conversationPreviewJoins + `
+		WHERE ` + inWorkspace("c.workspace_id", "$4") + ` AND ` + memberOrPublic("c.workspace_id", "$1") + ` ` + after + `
+		ORDER BY c.updated_at DESC, c.id DESC
+		LIMIT $2
 	`
-	rows, err := s.db.Query(query, userID)
+
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
-		log.Printf("Error getting conversations for user %s: %v", userID, err)
-		return nil
+		return nil, fmt.Errorf("get conversations for user %s: %w", userID, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		conv := &models.DMConversation{}
-		err := rows.Scan(
-			&conv.ID, &conv.Participants[0], &conv.Participants[1], &conv.CreatedAt, &conv.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning DM conversation row for user %s: %v", userID, err)
-			continue
+		var preview conversationPreview
+		if err := scanConversation(rows, conv, append([]any{&conv.UnreadCount, &conv.Archived, &conv.Muted}, preview.dest()...)...); err != nil {
+			return nil, fmt.Errorf("scan DM conversation row for user %s: %w", userID, err)
 		}
+		preview.apply(conv)
 		convs = append(convs, conv)
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating DM conversation rows for user %s: %v", userID, err)
-		return nil
+		return nil, fmt.Errorf("iterate DM conversation rows for user %s: %w", userID, err)
 	}
-	return convs
+	return convs, nil
 }
 
-// GetMessages retrieves all messages for a given conversation ID.
-func (s *PostgresDMStore) GetMessages(dmID string) []models.DMMessage {
-	var msgs []models.DMMessage
+// GetConversationIDs lists the IDs of every conversation a user is a part of.
+func (s *PostgresDMStore) GetConversationIDs(ctx context.Context, userID string) ([]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var ids []string
+	query := `SELECT dm_conversation_id FROM dm_participants WHERE user_id = $1`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation IDs for user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan conversation ID row for user %s: %w", userID, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate conversation ID rows for user %s: %w", userID, err)
+	}
+	return ids, nil
+}
+
+// GetParticipants lists the user IDs taking part in a conversation.
+func (s *PostgresDMStore) GetParticipants(ctx context.Context, dmID string) ([]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var participants []string
+	query := `SELECT user_id FROM dm_participants WHERE dm_conversation_id = $1 ORDER BY joined_at, user_id`
+	rows, err := s.db.Query(ctx, query, dmID)
+	if err != nil {
+		return nil, fmt.Errorf("get participants for DM %s: %w", dmID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan participant row for DM %s: %w", dmID, err)
+		}
+		participants = append(participants, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate participant rows for DM %s: %w", dmID, err)
+	}
+	return participants, nil
+}
+
+// AddParticipant adds a user to a group conversation.
+// It returns storage.ErrConflict if the conversation is not a group or the user is already a member,
+// and storage.ErrForbidden if the conversation belongs to a workspace the user is not a member of.
+func (s *PostgresDMStore) AddParticipant(ctx context.Context, dmID, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var workspaceID string
+	err := s.db.QueryRow(ctx, `SELECT COALESCE(workspace_id::text, '') FROM dm_conversations WHERE id = $1`, dmID).Scan(&workspaceID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("get workspace of DM %s: %w", dmID, err)
+	}
+	if workspaceID != "" {
+		member, err := areWorkspaceMembers(ctx, s.db, workspaceID, []string{userID})
+		if err != nil {
+			return err
+		}
+		if !member {
+			return storage.ErrForbidden
+		}
+	}
+
 	query := `
-		SELECT id, dm_conversation_id, sender_id, content, timestamp
-		FROM dm_messages
-		WHERE dm_conversation_id = $1
-		ORDER BY timestamp ASC
+		INSERT INTO dm_participants (dm_conversation_id, user_id)
+		SELECT id, $2 FROM dm_conversations WHERE id = $1 AND is_group
+		ON CONFLICT DO NOTHING
 	`
-	rows, err := s.db.Query(query, dmID)
+	result, err := s.db.Exec(ctx, query, dmID, userID)
 	if err != nil {
-		log.Printf("Error getting messages for DM %s: %v", dmID, err)
-		return nil
+		return fmt.Errorf("add user %s to DM %s: %w", userID, dmID, err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return storage.ErrConflict
+	}
+
+	log.Printf("User %s added to DM %s.", userID, dmID)
+	return nil
+}
+
+// RemoveParticipant removes a user from a group conversation.
+// It returns storage.ErrConflict if the conversation is not a group or the user is not a member.
+func (s *PostgresDMStore) RemoveParticipant(ctx context.Context, dmID, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		DELETE FROM dm_participants p
+		USING dm_conversations c
+		WHERE p.dm_conversation_id = c.id AND c.is_group AND c.id = $1 AND p.user_id = $2
+	`
+	result, err := s.db.Exec(ctx, query, dmID, userID)
+	if err != nil {
+		return fmt.Errorf("remove user %s from DM %s: %w", userID, dmID, err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return storage.ErrConflict
+	}
+
+	log.Printf("User %s removed from DM %s.", userID, dmID)
+	return nil
+}
+
+// GetMessages retrieves a page of top-level messages for a given conversation ID.
+func (s *PostgresDMStore) GetMessages(ctx context.Context, dmID string, page storage.MessagePage) ([]models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var msgs []models.DMMessage
+	limit := page.NormalizedLimit()
+
+	// Cursors compare on (timestamp, id) so messages sharing a timestamp are
+	// neither skipped nor repeated across pages.
+	var query string
+	var args []any
+	switch {
+	case page.After != "":
+		query = `
+			SELECT ` + messageColumns + `
+			FROM dm_messages
+			WHERE dm_conversation_id = $1 AND parent_message_id IS NULL AND ` + liveMessage + `
+				AND (timestamp, id) > (SELECT timestamp, id FROM dm_messages WHERE id = $2)
+			ORDER BY timestamp ASC, id ASC
+			LIMIT $3
+		`
+		args = []any{dmID, page.After, limit}
+	case page.Before != "":
+		query = `
+			SELECT ` + messageColumns + `
+			FROM dm_messages
+			WHERE dm_conversation_id = $1 AND parent_message_id IS NULL AND ` + liveMessage + `
+				AND (timestamp, id) < (SELECT timestamp, id FROM dm_messages WHERE id = $2)
+			ORDER BY timestamp DESC, id DESC
+			LIMIT $3
+		`
+		args = []any{dmID, page.Before, limit}
+	default:
+		query = `
+			SELECT ` + messageColumns + `
+			FROM dm_messages
+			WHERE dm_conversation_id = $1 AND parent_message_id IS NULL AND ` + liveMessage + `
+			ORDER BY timestamp DESC, id DESC
+			LIMIT $2
+		`
+		args = []any{dmID, limit}
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("get messages for DM %s: %w", dmID, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		msg := models.DMMessage{}
-		err := rows.Scan(
-			&msg.ID, &msg.DMConversationID, &msg.SenderID, &msg.Content, &msg.Timestamp,
-		)
-		if err != nil {
-			log.Printf("Error scanning DM message row for DM %s: %v", dmID, err)
-			continue
+		if err := scanMessage(rows, &msg); err != nil {
+			return nil, fmt.Errorf("scan DM message row for DM %s: %w", dmID, err)
 		}
 		msgs = append(msgs, msg)
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating DM message rows for DM %s: %v", dmID, err)
-		return nil
+		return nil, fmt.Errorf("iterate DM message rows for DM %s: %w", dmID, err)
+	}
+
+	ptrs := make([]*models.DMMessage, len(msgs))
+	for i := range msgs {
+		ptrs[i] = &msgs[i]
+	}
+	if err = s.attachDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+
+	// Newest-first queries are flipped back to chronological order
+	if page.After == "" {
+		for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+			msgs[i], msgs[j] = msgs[j], msgs[i]
+		}
+	}
+	return msgs, nil
+}
+
+// GetHistory returns up to limit messages of a conversation sent after the
+// message afterID (from the start when empty), in chronological order. Unlike
+// GetMessages it includes thread replies and deleted tombstones, so paging
+// through it covers the whole history.
+func (s *PostgresDMStore) GetHistory(ctx context.Context, dmID, afterID string, limit int) ([]models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+messageColumns+`
+		FROM dm_messages
+		WHERE dm_conversation_id = $1 AND `+liveMessage+`
+			AND ($2 = '' OR (timestamp, id) > (SELECT timestamp, id FROM dm_messages WHERE id::text = $2))
+		ORDER BY timestamp ASC, id ASC
+		LIMIT $3`,
+		dmID, afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get history of DM %s: %w", dmID, err)
+	}
+	defer rows.Close()
+
+	var msgs []models.DMMessage
+	for rows.Next() {
+		msg := models.DMMessage{}
+		if err := scanMessage(rows, &msg); err != nil {
+			return nil, fmt.Errorf("scan DM history row for DM %s: %w", dmID, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate DM history rows for DM %s: %w", dmID, err)
+	}
+
+	ptrs := make([]*models.DMMessage, len(msgs))
+	for i := range msgs {
+		ptrs[i] = &msgs[i]
 	}
-	return msgs
+	if err = s.attachDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+	return msgs, nil
 }
 
 // AddMessage adds a new message to a conversation in the database.
-func (s *PostgresDMStore) AddMessage(dmID, senderID, content string) *models.DMMessage {
+// Every participant other than the sender gets their unread count bumped;
+// the sender's is reset since they have evidently read the conversation.
+// The three statements are sent as one batch, so sending a message costs a
+// single round trip inside the transaction.
+func (s *PostgresDMStore) AddMessage(ctx context.Context, dmID, senderID, content string) (*models.DMMessage, error) {
+	return s.addMessage(ctx, dmID, nil, senderID, content, nil)
+}
+
+// AddReply adds a message to the thread started by parentMessageID. Replies to
+// a reply are attached to the same top-level message, so threads stay one level deep.
+func (s *PostgresDMStore) AddReply(ctx context.Context, dmID, parentMessageID, senderID, content string) (*models.DMMessage, error) {
+	return s.addMessage(ctx, dmID, &parentMessageID, senderID, content, nil)
+}
+
+// AddEncryptedMessage adds an end-to-end encrypted message, or a reply in
+// the thread of parentMessageID when it is set. The ciphertext is stored as
+// the message content and never inspected.
+func (s *PostgresDMStore) AddEncryptedMessage(ctx context.Context, dmID, parentMessageID, senderID, ciphertext string, keyVersion int) (*models.DMMessage, error) {
+	var parentID *string
+	if parentMessageID != "" {
+		parentID = &parentMessageID
+	}
+	return s.addMessage(ctx, dmID, parentID, senderID, ciphertext, &keyVersion)
+}
+
+// addMessage inserts a message, or a thread reply when parentID is set.
+// keyVersion is set for encrypted messages.
+func (s *PostgresDMStore) addMessage(ctx context.Context, dmID string, parentID *string, senderID, content string, keyVersion *int) (*models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin add message to DM %s: %w", dmID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	msg, err := insertMessage(ctx, tx, dmID, parentID, senderID, content, keyVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit message to DM %s: %w", dmID, err)
+	}
+
+	log.Printf("Added message %s to DM %s from sender %s", msg.ID, dmID, senderID)
+	return msg, nil
+}
+
+// insertMessage stores a message within tx, bumps the other participants'
+// unread counts, and records the message in the outbox. It returns
+// storage.ErrNotFound if parentID is set but not a live message in dmID.
+func insertMessage(ctx context.Context, tx pgx.Tx, dmID string, parentID *string, senderID, content string, keyVersion *int) (*models.DMMessage, error) {
+	var err error
+	if parentID != nil {
+		var rootID string
+		err = tx.QueryRow(ctx, `
+			SELECT COALESCE(parent_message_id, id)::text FROM dm_messages
+			WHERE id = $1 AND dm_conversation_id = $2 AND deleted_at IS NULL`,
+			*parentID, dmID,
+		).Scan(&rootID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("look up parent message %s in DM %s: %w", *parentID, dmID, err)
+		}
+		parentID = &rootID
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		INSERT INTO dm_messages (dm_conversation_id, sender_id, content, parent_message_id, key_version, expires_at)
+		VALUES ($1, $2, $3, $4, $5,
+			NOW() + (SELECT make_interval(secs => message_ttl_seconds) FROM dm_conversations WHERE id = $1))
+		RETURNING `+messageColumns, dmID, senderID, content, parentID, keyVersion)
+	batch.Queue(`
+		UPDATE dm_participants
+		SET unread_count = CASE
+				WHEN user_id = $2 THEN 0
+				WHEN muted_at IS NOT NULL THEN unread_count
+				ELSE unread_count + 1
+			END,
+			last_read_at = CASE WHEN user_id = $2 THEN NOW() ELSE last_read_at END,
+			archived_at = NULL
+		WHERE dm_conversation_id = $1
+	`, dmID, senderID)
+	// Update the updated_at timestamp of the conversation
+	batch.Queue(`UPDATE dm_conversations SET updated_at = NOW() WHERE id = $1`, dmID)
+
+	results := tx.SendBatch(ctx, batch)
+	msg := &models.DMMessage{}
+	if err = scanMessage(results.QueryRow(), msg); err != nil {
+		results.Close()
+		return nil, fmt.Errorf("add message to DM %s: %w", dmID, err)
+	}
+	if _, err = results.Exec(); err != nil {
+		results.Close()
+		return nil, fmt.Errorf("update unread counts for DM %s: %w", dmID, err)
+	}
+	if _, err = results.Exec(); err != nil {
+		results.Close()
+		return nil, fmt.Errorf("update conversation %s timestamp: %w", dmID, err)
+	}
+	if err = results.Close(); err != nil {
+		return nil, fmt.Errorf("add message to DM %s: %w", dmID, err)
+	}
+
+	if err = writeOutbox(ctx, tx, models.AggregateDM, dmID, models.EventMessageSent, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// EditMessage replaces the content of a message sent by senderID. keyVersion
+// is set when the new content is ciphertext.
+func (s *PostgresDMStore) EditMessage(ctx context.Context, messageID, senderID, content string, keyVersion *int) (*models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	msg := &models.DMMessage{}
+	query := `
+		UPDATE dm_messages SET content = $3, key_version = $4, edited_at = NOW()
+		WHERE id = $1 AND sender_id = $2 AND deleted_at IS NULL
+		RETURNING ` + messageColumns
+	err := scanMessage(s.db.QueryRow(ctx, query, messageID, senderID, content, keyVersion), msg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, s.messageWriteError(ctx, messageID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("edit DM message %s: %w", messageID, err)
+	}
+	if err = s.attachDetails(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	log.Printf("DM message %s edited by %s", messageID, senderID)
+	return msg, nil
+}
+
+// DeleteMessage turns a message sent by senderID into a tombstone: its
+// content, reactions, attachments, and mentions are cleared and deleted_at is set, but the row stays
+// so page cursors pointing at it keep working.
+func (s *PostgresDMStore) DeleteMessage(ctx context.Context, messageID, senderID string) (*models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	msg := &models.DMMessage{}
+	query := `
+		WITH deleted AS (
+			UPDATE dm_messages SET content = '', deleted_at = NOW()
+			WHERE id = $1 AND sender_id = $2 AND deleted_at IS NULL
+			RETURNING ` + messageColumns + `
+		), cleared AS (
+			DELETE FROM message_reactions WHERE dm_message_id IN (SELECT id FROM deleted)
+		), detached AS (
+			DELETE FROM attachments WHERE dm_message_id IN (SELECT id FROM deleted)
+		), unmentioned AS (
+			DELETE FROM message_mentions WHERE dm_message_id IN (SELECT id FROM deleted)
+		)
+		SELECT ` + messageColumns + ` FROM deleted`
+	err := scanMessage(s.db.QueryRow(ctx, query, messageID, senderID), msg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, s.messageWriteError(ctx, messageID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("delete DM message %s: %w", messageID, err)
+	}
+
+	log.Printf("DM message %s deleted by %s", messageID, senderID)
+	return msg, nil
+}
+
+// AddReaction records userID's emoji reaction on a DM message.
+func (s *PostgresDMStore) AddReaction(ctx context.Context, messageID, userID, emoji string) (*models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if err := addReaction(ctx, s.db, dmReactions, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+	return s.getMessage(ctx, messageID)
+}
+
+// RemoveReaction deletes userID's emoji reaction on a DM message.
+func (s *PostgresDMStore) RemoveReaction(ctx context.Context, messageID, userID, emoji string) (*models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if err := removeReaction(ctx, s.db, dmReactions, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+	return s.getMessage(ctx, messageID)
+}
+
+// getMessage loads a single DM message with its reaction counts.
+func (s *PostgresDMStore) getMessage(ctx context.Context, messageID string) (*models.DMMessage, error) {
 	msg := &models.DMMessage{}
+	err := scanMessage(s.db.QueryRow(ctx, `SELECT `+messageColumns+` FROM dm_messages WHERE id = $1`, messageID), msg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get DM message %s: %w", messageID, err)
+	}
+	if err = s.attachDetails(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// SetMentions resolves the @username spans of a DM message and replaces its
+// stored mentions with those that name a participant of the conversation.
+func (s *PostgresDMStore) SetMentions(ctx context.Context, messageID string, spans []models.Mention) ([]models.Mention, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return setMentions(ctx, s.db, dmMentions, messageID, spans)
+}
+
+// attachDetails fills in the reactions, attachments, mentions, receipts, and thread reply counts of msgs.
+func (s *PostgresDMStore) attachDetails(ctx context.Context, msgs ...*models.DMMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = msg.ID
+	}
+	reactions, err := loadReactions(ctx, s.db, dmReactions, ids)
+	if err != nil {
+		return err
+	}
+	attachments, err := loadAttachments(ctx, s.db, "dm_message_id", ids)
+	if err != nil {
+		return err
+	}
+	mentions, err := loadMentions(ctx, s.db, "dm_message_id", ids)
+	if err != nil {
+		return err
+	}
+	receipts, err := loadReceipts(ctx, s.db, ids)
+	if err != nil {
+		return err
+	}
+
+	replyCounts := make(map[string]int)
+	rows, err := s.db.Query(ctx, `
+		SELECT parent_message_id::text, COUNT(*) FROM dm_messages
+		WHERE parent_message_id = ANY($1) AND deleted_at IS NULL
+		GROUP BY parent_message_id`,
+		ids,
+	)
+	if err != nil {
+		return fmt.Errorf("load reply counts for %d messages: %w", len(ids), err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var parentID string
+		var count int
+		if err := rows.Scan(&parentID, &count); err != nil {
+			return fmt.Errorf("scan reply count row: %w", err)
+		}
+		replyCounts[parentID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate reply count rows: %w", err)
+	}
+
+	for _, msg := range msgs {
+		msg.Reactions = reactions[msg.ID]
+		msg.Attachments = attachments[msg.ID]
+		msg.Mentions = mentions[msg.ID]
+		msg.Receipts = receipts[msg.ID]
+		msg.ReplyCount = replyCounts[msg.ID]
+	}
+	return nil
+}
+
+// SearchMessages finds live plaintext messages matching search in the conversations
+// userID takes part in, restricted to dmID when it is set, newest first. Each
+// match comes with up to storage.SearchContextSize messages on either side
+// from the same thread (or the top level, for top-level messages).
+func (s *PostgresDMStore) SearchMessages(ctx context.Context, userID, dmID string, search storage.MessageSearch) ([]models.DMSearchResult, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tsQuery := prefixTSQuery(search.Query)
+	if tsQuery == "" {
+		return nil, nil
+	}
+
 	query := `
-		INSERT INTO dm_messages (dm_conversation_id, sender_id, content)
-		VALUES ($1, $2, $3)
-		RETURNING id, dm_conversation_id, sender_id, content, timestamp
+		SELECT ` + messageColumns + `
+		FROM dm_messages
+		WHERE search_vector @@ to_tsquery('simple', $2) AND deleted_at IS NULL AND key_version IS NULL AND ` + liveMessage + `
+			AND dm_conversation_id IN (
+				SELECT p.dm_conversation_id FROM dm_participants p
+				JOIN dm_conversations c ON c.id = p.dm_conversation_id
+				WHERE p.user_id = $1 AND ` + memberOrPublic("c.workspace_id", "$1") + `)
+			AND ($3 = '' OR dm_conversation_id::text = $3)
+		ORDER BY timestamp DESC, id DESC
+		LIMIT $4
 	`
-	err := s.db.QueryRow(query, dmID, senderID, content).Scan(
-		&msg.ID, &msg.DMConversationID, &msg.SenderID, &msg.Content, &msg.Timestamp,
+	rows, err := s.db.Query(ctx, query, userID, tsQuery, dmID, search.NormalizedLimit())
+	if err != nil {
+		return nil, fmt.Errorf("search DM messages of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var results []models.DMSearchResult
+	var ids []string
+	for rows.Next() {
+		var res models.DMSearchResult
+		if err := scanMessage(rows, &res.Message); err != nil {
+			return nil, fmt.Errorf("scan DM search row: %w", err)
+		}
+		res.DMConversationID = res.Message.DMConversationID
+		results = append(results, res)
+		ids = append(ids, res.Message.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate DM search rows: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	// Load the neighbours of every match in one round trip
+	contextQuery := `
+		SELECT c.*, m.id::text
+		FROM dm_messages m
+		CROSS JOIN LATERAL (
+			(SELECT ` + messageColumns + ` FROM dm_messages
+			 WHERE dm_conversation_id = m.dm_conversation_id AND parent_message_id IS NOT DISTINCT FROM m.parent_message_id
+				AND (timestamp, id) < (m.timestamp, m.id) AND ` + liveMessage + `
+			 ORDER BY timestamp DESC, id DESC LIMIT $2)
+			UNION ALL
+			(SELECT ` + messageColumns + ` FROM dm_messages
+			 WHERE dm_conversation_id = m.dm_conversation_id AND parent_message_id IS NOT DISTINCT FROM m.parent_message_id
+				AND (timestamp, id) > (m.timestamp, m.id) AND ` + liveMessage + `
+			 ORDER BY timestamp ASC, id ASC LIMIT $2)
+		) AS c
+		WHERE m.id = ANY($1)
+		ORDER BY c.timestamp, c.id
+	`
+	rows, err = s.db.Query(ctx, contextQuery, ids, storage.SearchContextSize)
+	if err != nil {
+		return nil, fmt.Errorf("load context of %d DM search results: %w", len(ids), err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(results))
+	for i := range results {
+		index[results[i].Message.ID] = i
+	}
+	for rows.Next() {
+		var msg models.DMMessage
+		var matchID string
+		if err := scanMessage(rows, &msg, &matchID); err != nil {
+			return nil, fmt.Errorf("scan DM search context row: %w", err)
+		}
+		res := &results[index[matchID]]
+		if msg.Timestamp.Before(res.Message.Timestamp) || (msg.Timestamp.Equal(res.Message.Timestamp) && msg.ID < res.Message.ID) {
+			res.Before = append(res.Before, msg)
+		} else {
+			res.After = append(res.After, msg)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate DM search context rows: %w", err)
+	}
+
+	var ptrs []*models.DMMessage
+	for i := range results {
+		res := &results[i]
+		if res.Before == nil {
+			res.Before = []models.DMMessage{}
+		}
+		if res.After == nil {
+			res.After = []models.DMMessage{}
+		}
+		ptrs = append(ptrs, &res.Message)
+		for j := range res.Before {
+			ptrs = append(ptrs, &res.Before[j])
+		}
+		for j := range res.After {
+			ptrs = append(ptrs, &res.After[j])
+		}
+	}
+	if err = s.attachDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetThread returns the top-level message of messageID's thread and all its replies.
+func (s *PostgresDMStore) GetThread(ctx context.Context, messageID string) (*models.DMThread, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	thread := &models.DMThread{}
+	query := `
+		SELECT ` + messageColumns + ` FROM dm_messages
+		WHERE id = (SELECT COALESCE(parent_message_id, id) FROM dm_messages WHERE id = $1)
+	`
+	err := scanMessage(s.db.QueryRow(ctx, query, messageID), &thread.Parent)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get thread of DM message %s: %w", messageID, err)
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+messageColumns+` FROM dm_messages
+		WHERE parent_message_id = $1 AND `+liveMessage+`
+		ORDER BY timestamp ASC, id ASC`,
+		thread.Parent.ID,
 	)
 	if err != nil {
-		log.Printf("Error adding message to DM %s: %v", dmID, err)
-		return nil
+		return nil, fmt.Errorf("get replies to DM message %s: %w", thread.Parent.ID, err)
 	}
+	defer rows.Close()
 
-	// Update the updated_at timestamp of the conversation
-	updateConvQuery := `UPDATE dm_conversations SET updated_at = NOW() WHERE id = $1`
-	_, err = s.db.Exec(updateConvQuery, dmID)
+	thread.Replies = []models.DMMessage{}
+	for rows.Next() {
+		msg := models.DMMessage{}
+		if err := scanMessage(rows, &msg); err != nil {
+			return nil, fmt.Errorf("scan reply row for DM message %s: %w", thread.Parent.ID, err)
+		}
+		thread.Replies = append(thread.Replies, msg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reply rows for DM message %s: %w", thread.Parent.ID, err)
+	}
+
+	ptrs := []*models.DMMessage{&thread.Parent}
+	for i := range thread.Replies {
+		ptrs = append(ptrs, &thread.Replies[i])
+	}
+	if err = s.attachDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// messageWriteError explains why a sender-scoped update matched no rows:
+// ErrNotFound if the message is missing or deleted, ErrForbidden otherwise.
+func (s *PostgresDMStore) messageWriteError(ctx context.Context, messageID string) error {
+	var live bool
+	err := s.db.QueryRow(ctx,
+		`SELECT deleted_at IS NULL FROM dm_messages WHERE id = $1`,
+		messageID,
+	).Scan(&live)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !live) {
+		return storage.ErrNotFound
+	}
 	if err != nil {
-		log.Printf("Error updating conversation %s timestamp: %v", dmID, err)
-		// This is non-fatal for message sending, but good to log
+		return fmt.Errorf("look up DM message %s: %w", messageID, err)
 	}
+	return storage.ErrForbidden
+}
 
-	log.Printf("Added message %s to DM %s from sender %s", msg.ID, dmID, senderID)
-	return msg
+// MarkRead resets a participant's unread count for a conversation and
+// records read receipts for the messages from others they had not yet read:
+// those newer than the last one they already read. Messages never delivered
+// are marked delivered at the same time.
+func (s *PostgresDMStore) MarkRead(ctx context.Context, dmID, userID string) ([]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH marked AS (
+			UPDATE dm_participants SET unread_count = 0, last_read_at = NOW()
+			WHERE dm_conversation_id = $1 AND user_id = $2
+			RETURNING user_id
+		), last_read AS (
+			SELECT MAX(m.timestamp) AS at
+			FROM dm_message_receipts r
+			JOIN dm_messages m ON m.id = r.dm_message_id
+			WHERE r.user_id = $2 AND r.read_at IS NOT NULL AND m.dm_conversation_id = $1
+		), receipts AS (
+			INSERT INTO dm_message_receipts (dm_message_id, user_id, delivered_at, read_at)
+			SELECT m.id, marked.user_id, NOW(), NOW()
+			FROM dm_messages m, marked, last_read
+			WHERE m.dm_conversation_id = $1 AND m.sender_id <> $2 AND m.deleted_at IS NULL
+				AND m.timestamp > COALESCE(last_read.at, '-infinity')
+			ON CONFLICT (dm_message_id, user_id) DO UPDATE SET read_at = EXCLUDED.read_at
+			WHERE dm_message_receipts.read_at IS NULL
+			RETURNING dm_message_id::text
+		)
+		SELECT EXISTS (SELECT 1 FROM marked), ARRAY(SELECT dm_message_id FROM receipts)
+	`
+	var participant bool
+	var read []string
+	if err := s.db.QueryRow(ctx, query, dmID, userID).Scan(&participant, &read); err != nil {
+		return nil, fmt.Errorf("mark DM %s read for user %s: %w", dmID, userID, err)
+	}
+	if !participant {
+		return nil, storage.ErrNotFound
+	}
+	return read, nil
+}
+
+// SetArchived archives or unarchives a conversation for one participant.
+// Archiving again keeps the original archived_at.
+func (s *PostgresDMStore) SetArchived(ctx context.Context, dmID, userID string, archived bool) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		UPDATE dm_participants
+		SET archived_at = CASE WHEN $3 THEN COALESCE(archived_at, NOW()) END
+		WHERE dm_conversation_id = $1 AND user_id = $2`,
+		dmID, userID, archived,
+	)
+	if err != nil {
+		return fmt.Errorf("set DM %s archived=%t for user %s: %w", dmID, archived, userID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// SetMuted mutes or unmutes a conversation for one participant. Muting
+// again keeps the original muted_at.
+func (s *PostgresDMStore) SetMuted(ctx context.Context, dmID, userID string, muted bool) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		UPDATE dm_participants
+		SET muted_at = CASE WHEN $3 THEN COALESCE(muted_at, NOW()) END
+		WHERE dm_conversation_id = $1 AND user_id = $2`,
+		dmID, userID, muted,
+	)
+	if err != nil {
+		return fmt.Errorf("set DM %s muted=%t for user %s: %w", dmID, muted, userID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// SetMessageTTL sets how long new messages in a conversation live before
+// they are deleted; ttl 0 keeps them. Messages already sent keep the expiry
+// they were sent with. It returns storage.ErrNotFound unless userID takes
+// part in the conversation.
+func (s *PostgresDMStore) SetMessageTTL(ctx context.Context, dmID, userID string, ttl time.Duration) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		UPDATE dm_conversations c
+		SET message_ttl_seconds = NULLIF($3, 0), updated_at = NOW()
+		WHERE c.id = $1
+			AND EXISTS (SELECT 1 FROM dm_participants p WHERE p.dm_conversation_id = c.id AND p.user_id = $2)`,
+		dmID, userID, int(ttl/time.Second),
+	)
+	if err != nil {
+		return fmt.Errorf("set message TTL of DM %s: %w", dmID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
 }
 
-// Close closes the database connection.
-func (s *PostgresDMStore) Close() error {
-	return s.db.Close()
+// DeleteExpiredMessages deletes up to limit messages past their expiry and
+// returns their IDs keyed by conversation ID. Replies to a deleted message
+// go with it. In the same statement, the files of their attachments are
+// queued for the orphan-blobs job and the events carrying them that wait for
+// offline participants are dropped, so neither outlives the messages.
+func (s *PostgresDMStore) DeleteExpiredMessages(ctx context.Context, limit int) (map[string][]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		WITH expired AS (
+			SELECT id, dm_conversation_id FROM dm_messages
+			WHERE expires_at <= NOW()
+			ORDER BY expires_at
+			LIMIT $1
+		), doomed AS (
+			SELECT id, dm_conversation_id FROM expired
+			UNION
+			SELECT r.id, r.dm_conversation_id FROM dm_messages r JOIN expired e ON r.parent_message_id = e.id
+		), files AS (
+			DELETE FROM attachments a USING doomed d
+			WHERE a.dm_message_id = d.id
+			RETURNING a.object_key
+		), queued AS (
+			INSERT INTO orphan_blobs (bucket, object_key) SELECT $2, object_key FROM files
+		), events AS (
+			DELETE FROM dm_offline_events o USING doomed d
+			WHERE o.dm_conversation_id = d.dm_conversation_id
+				AND d.id::text IN (o.event->'payload'->>'id', o.event->'payload'->>'message_id')
+		)
+		DELETE FROM dm_messages
+		WHERE id IN (SELECT id FROM expired)
+		RETURNING dm_conversation_id::text, id::text`,
+		limit, models.BucketAttachments,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("delete expired DM messages: %w", err)
+	}
+	defer rows.Close()
+
+	expired := make(map[string][]string)
+	for rows.Next() {
+		var dmID, messageID string
+		if err := rows.Scan(&dmID, &messageID); err != nil {
+			return nil, fmt.Errorf("scan expired DM message row: %w", err)
+		}
+		expired[dmID] = append(expired[dmID], messageID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate expired DM message rows: %w", err)
+	}
+	return expired, nil
+}
+
+// GetUnreadTotal sums a user's unread counts across all their conversations.
+func (s *PostgresDMStore) GetUnreadTotal(ctx context.Context, userID string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var total int
+	err := s.db.QueryRow(ctx,
+		`SELECT COALESCE(SUM(unread_count), 0) FROM dm_participants WHERE user_id = $1`,
+		userID,
+	).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("get unread total for user %s: %w", userID, err)
+	}
+	return total, nil
 }
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetDraft returns userID's draft in a conversation, with empty content if
+// they have none. It returns storage.ErrNotFound unless userID takes part in
+// the conversation.
+func (s *PostgresDMStore) GetDraft(ctx context.Context, dmID, userID string) (*models.DMDraft, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	draft := &models.DMDraft{DMID: dmID, UserID: userID}
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE(d.content, ''), d.updated_at
+		FROM dm_participants p
+		LEFT JOIN dm_drafts d ON d.dm_conversation_id = p.dm_conversation_id AND d.user_id = p.user_id
+		WHERE p.dm_conversation_id::text = $1 AND p.user_id = $2`,
+		dmID, userID,
+	).Scan(&draft.Content, &draft.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get draft of user %s in DM %s: %w", userID, dmID, err)
+	}
+	return draft, nil
+}
+
+// SaveDraft replaces userID's draft in a conversation; empty content deletes
+// it. It returns storage.ErrNotFound unless userID takes part in the
+// conversation.
+func (s *PostgresDMStore) SaveDraft(ctx context.Context, dmID, userID, content string) (*models.DMDraft, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	draft := &models.DMDraft{DMID: dmID, UserID: userID, Content: content}
+	if content == "" {
+		var participant bool
+		err := s.db.QueryRow(ctx, `
+			WITH deleted AS (
+				DELETE FROM dm_drafts WHERE dm_conversation_id::text = $1 AND user_id = $2
+			)
+			SELECT EXISTS (SELECT 1 FROM dm_participants WHERE dm_conversation_id::text = $1 AND user_id = $2)`,
+			dmID, userID,
+		).Scan(&participant)
+		if err != nil {
+			return nil, fmt.Errorf("delete draft of user %s in DM %s: %w", userID, dmID, err)
+		}
+		if !participant {
+			return nil, storage.ErrNotFound
+		}
+		return draft, nil
+	}
+
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO dm_drafts (dm_conversation_id, user_id, content)
+		SELECT p.dm_conversation_id, p.user_id, $3
+		FROM dm_participants p
+		WHERE p.dm_conversation_id::text = $1 AND p.user_id = $2
+		ON CONFLICT (dm_conversation_id, user_id) DO UPDATE SET content = EXCLUDED.content, updated_at = NOW()
+		RETURNING updated_at`,
+		dmID, userID, content,
+	).Scan(&draft.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("save draft of user %s in DM %s: %w", userID, dmID, err)
+	}
+	return draft, nil
+}
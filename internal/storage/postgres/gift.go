@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresGiftStore implements storage.GiftStore using PostgreSQL.
+type PostgresGiftStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.GiftStore = (*PostgresGiftStore)(nil)
+
+// NewPostgresGiftStore creates a new PostgresGiftStore backed by the shared pool db.
+func NewPostgresGiftStore(db *pgxpool.Pool) *PostgresGiftStore {
+	return &PostgresGiftStore{db: db}
+}
+
+// giftColumns selects a gift row for scanGift.
+const giftColumns = `id, scene_id, sender_id, recipient_id, kind, amount_cents, currency, message, method, status,
+	COALESCE(checkout_session_id, ''), COALESCE(payment_intent_id, ''), created_at, completed_at`
+
+// scanGift scans a row selected with giftColumns.
+func scanGift(row interface{ Scan(...any) error }, g *models.Gift) error {
+	return row.Scan(&g.ID, &g.SceneID, &g.SenderID, &g.RecipientID, &g.Kind, &g.AmountCents, &g.Currency, &g.Message, &g.Method, &g.Status,
+		&g.CheckoutSessionID, &g.PaymentIntentID, &g.CreatedAt, &g.CompletedAt)
+}
+
+// SendGiftWithCredits deducts the gift's amount from the sender's balance
+// and records the gift completed, in one transaction.
+func (s *PostgresGiftStore) SendGiftWithCredits(ctx context.Context, gift *models.Gift) (*models.Gift, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin send gift: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE user_credits SET balance_cents = balance_cents - $2, updated_at = NOW()
+		WHERE user_id = $1 AND balance_cents >= $2`,
+		gift.SenderID, gift.AmountCents)
+	if err != nil {
+		return nil, fmt.Errorf("debit %d credits from user %s: %w", gift.AmountCents, gift.SenderID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, storage.ErrConflict
+	}
+
+	sent := &models.Gift{}
+	err = scanGift(tx.QueryRow(ctx, `
+		INSERT INTO gifts (scene_id, sender_id, recipient_id, kind, amount_cents, currency, message, method, status, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'credits', 'completed', NOW())
+		RETURNING `+giftColumns,
+		gift.SceneID, gift.SenderID, gift.RecipientID, gift.Kind, gift.AmountCents, gift.Currency, gift.Message,
+	), sent)
+	if err != nil {
+		return nil, fmt.Errorf("record gift from user %s in scene %s: %w", gift.SenderID, gift.SceneID, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit gift from user %s in scene %s: %w", gift.SenderID, gift.SceneID, err)
+	}
+	return sent, nil
+}
+
+// CreateGiftCheckout records a pending gift paid through a Stripe checkout session.
+func (s *PostgresGiftStore) CreateGiftCheckout(ctx context.Context, gift *models.Gift) (*models.Gift, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	created := &models.Gift{}
+	err := scanGift(s.db.QueryRow(ctx, `
+		INSERT INTO gifts (scene_id, sender_id, recipient_id, kind, amount_cents, currency, message, method, status, checkout_session_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'stripe', 'pending', $8)
+		RETURNING `+giftColumns,
+		gift.SceneID, gift.SenderID, gift.RecipientID, gift.Kind, gift.AmountCents, gift.Currency, gift.Message, gift.CheckoutSessionID,
+	), created)
+	if err != nil {
+		return nil, fmt.Errorf("create gift checkout for user %s in scene %s: %w", gift.SenderID, gift.SceneID, err)
+	}
+	return created, nil
+}
+
+// ResolveGiftCheckout moves the pending gift of a checkout session to status.
+// Stripe redelivers webhook events, so a gift already resolved returns
+// storage.ErrConflict rather than being delivered again.
+func (s *PostgresGiftStore) ResolveGiftCheckout(ctx context.Context, sessionID, paymentIntentID string, status models.GiftStatus) (*models.Gift, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	gift := &models.Gift{}
+	err := scanGift(s.db.QueryRow(ctx, `
+		UPDATE gifts SET
+			status = $3,
+			payment_intent_id = COALESCE(NULLIF($2, ''), payment_intent_id),
+			completed_at = CASE WHEN $3 = 'completed' THEN NOW() END
+		WHERE checkout_session_id = $1 AND status = 'pending'
+		RETURNING `+giftColumns,
+		sessionID, paymentIntentID, status,
+	), gift)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM gifts WHERE checkout_session_id = $1)`, sessionID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("check gift checkout session %s: %w", sessionID, err)
+		}
+		if !exists {
+			return nil, storage.ErrNotFound
+		}
+		return nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve gift checkout session %s as %s: %w", sessionID, status, err)
+	}
+	return gift, nil
+}
+
+// RefundGift marks the completed gift of a payment intent refunded.
+func (s *PostgresGiftStore) RefundGift(ctx context.Context, paymentIntentID string) (*models.Gift, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	gift := &models.Gift{}
+	err := scanGift(s.db.QueryRow(ctx, `
+		UPDATE gifts SET status = 'refunded'
+		WHERE payment_intent_id = $1 AND status = 'completed'
+		RETURNING `+giftColumns,
+		paymentIntentID,
+	), gift)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("refund gift for payment intent %s: %w", paymentIntentID, err)
+	}
+	return gift, nil
+}
+
+// GetCreditBalance returns a user's credit balance in cents.
+func (s *PostgresGiftStore) GetCreditBalance(ctx context.Context, userID string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var balance int
+	err := s.db.QueryRow(ctx, `SELECT balance_cents FROM user_credits WHERE user_id = $1`, userID).Scan(&balance)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get credit balance of user %s: %w", userID, err)
+	}
+	return balance, nil
+}
+
+// AddCredits adds amountCents to a user's balance and returns the new balance.
+func (s *PostgresGiftStore) AddCredits(ctx context.Context, userID string, amountCents int) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var balance int
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO user_credits (user_id, balance_cents) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			balance_cents = user_credits.balance_cents + EXCLUDED.balance_cents,
+			updated_at = NOW()
+		RETURNING balance_cents`,
+		userID, amountCents,
+	).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("add %d credits to user %s: %w", amountCents, userID, err)
+	}
+	return balance, nil
+}
+
+// GetSceneEarnings totals the completed gifts sent in a scene.
+func (s *PostgresGiftStore) GetSceneEarnings(ctx context.Context, sceneID string, topSenders int) (*models.SceneEarnings, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	earnings := &models.SceneEarnings{
+		SceneID:    sceneID,
+		Currency:   models.GiftCurrency,
+		ByKind:     []models.GiftKindEarnings{},
+		TopSenders: []models.GiftSenderEarnings{},
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT kind, COUNT(*), SUM(amount_cents) FROM gifts
+		WHERE scene_id = $1 AND status = 'completed'
+		GROUP BY kind
+		ORDER BY SUM(amount_cents) DESC, kind`,
+		sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("total gifts of scene %s by kind: %w", sceneID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k models.GiftKindEarnings
+		if err := rows.Scan(&k.Kind, &k.Count, &k.AmountCents); err != nil {
+			return nil, fmt.Errorf("scan gift total of scene %s: %w", sceneID, err)
+		}
+		earnings.ByKind = append(earnings.ByKind, k)
+		earnings.GiftCount += k.Count
+		earnings.TotalCents += k.AmountCents
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("total gifts of scene %s by kind: %w", sceneID, err)
+	}
+
+	rows, err = s.db.Query(ctx, `
+		SELECT sender_id, COUNT(*), SUM(amount_cents) FROM gifts
+		WHERE scene_id = $1 AND status = 'completed'
+		GROUP BY sender_id
+		ORDER BY SUM(amount_cents) DESC, sender_id
+		LIMIT $2`,
+		sceneID, topSenders)
+	if err != nil {
+		return nil, fmt.Errorf("total gifts of scene %s by sender: %w", sceneID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sender models.GiftSenderEarnings
+		if err := rows.Scan(&sender.SenderID, &sender.Count, &sender.AmountCents); err != nil {
+			return nil, fmt.Errorf("scan gift sender of scene %s: %w", sceneID, err)
+		}
+		earnings.TopSenders = append(earnings.TopSenders, sender)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("total gifts of scene %s by sender: %w", sceneID, err)
+	}
+	return earnings, nil
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresHashtagStore implements storage.HashtagStore using PostgreSQL.
+type PostgresHashtagStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.HashtagStore = (*PostgresHashtagStore)(nil)
+
+// NewPostgresHashtagStore creates a new PostgresHashtagStore backed by the shared pool db.
+func NewPostgresHashtagStore(db *pgxpool.Pool) *PostgresHashtagStore {
+	return &PostgresHashtagStore{db: db}
+}
+
+// SetSceneHashtags replaces a scene's hashtags with tags. Hashtags the scene
+// already had keep their original created_at, so editing a scene does not
+// make its tags trend again.
+func (s *PostgresHashtagStore) SetSceneHashtags(ctx context.Context, sceneID string, tags []string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if tags == nil {
+		tags = []string{}
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin set hashtags of scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM scene_hashtags WHERE scene_id = $1 AND tag <> ALL($2)`, sceneID, tags)
+	if err != nil {
+		return fmt.Errorf("remove hashtags of scene %s: %w", sceneID, err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO scene_hashtags (scene_id, tag)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING`,
+		sceneID, tags,
+	)
+	if err != nil {
+		return fmt.Errorf("add hashtags to scene %s: %w", sceneID, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit hashtags of scene %s: %w", sceneID, err)
+	}
+	return nil
+}
+
+// AddMessageHashtags records the hashtags used in a scene chat message.
+func (s *PostgresHashtagStore) AddMessageHashtags(ctx context.Context, sceneID, messageID string, tags []string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO message_hashtags (scene_message_id, scene_id, tag)
+		SELECT $1, $2, unnest($3::text[])
+		ON CONFLICT DO NOTHING`,
+		messageID, sceneID, tags,
+	)
+	if err != nil {
+		return fmt.Errorf("add hashtags to scene message %s: %w", messageID, err)
+	}
+	return nil
+}
+
+// TrendingHashtags ranks the hashtags used since since by chat messages and
+// newly tagged scenes, most used first. Archived scenes and scenes private to
+// a workspace are not counted.
+func (s *PostgresHashtagStore) TrendingHashtags(ctx context.Context, since time.Time, limit int) ([]models.TrendingTag, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH uses AS (
+			SELECT tag, scene_id FROM message_hashtags WHERE created_at >= $1
+			UNION ALL
+			SELECT tag, scene_id FROM scene_hashtags WHERE created_at >= $1
+		)
+		SELECT u.tag, COUNT(*) AS uses, COUNT(DISTINCT u.scene_id) AS scenes
+		FROM uses u
+		JOIN scenes s ON s.id = u.scene_id AND s.archived_at IS NULL AND s.workspace_id IS NULL
+		GROUP BY u.tag
+		ORDER BY uses DESC, scenes DESC, u.tag
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get trending hashtags since %s: %w", since.Format(time.RFC3339), err)
+	}
+	defer rows.Close()
+
+	trending := []models.TrendingTag{}
+	for rows.Next() {
+		var t models.TrendingTag
+		if err := rows.Scan(&t.Tag, &t.Uses, &t.Scenes); err != nil {
+			return nil, fmt.Errorf("scan trending hashtag row: %w", err)
+		}
+		trending = append(trending, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trending hashtag rows: %w", err)
+	}
+	return trending, nil
+}
+
+// GetScenesByHashtag returns the non-archived public scenes tagged with tag or
+// whose chat used it, most recently used first, skipping offset results.
+func (s *PostgresHashtagStore) GetScenesByHashtag(ctx context.Context, tag string, limit, offset int) ([]*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH used AS (
+			SELECT scene_id, MAX(created_at) AS last_used FROM (
+				SELECT scene_id, created_at FROM scene_hashtags WHERE tag = $1
+				UNION ALL
+				SELECT scene_id, created_at FROM message_hashtags WHERE tag = $1
+			) t
+			GROUP BY scene_id
+		)
+		SELECT ` + sceneColumns + `
+		FROM scenes s
+		JOIN used ON used.scene_id = s.id
+		WHERE s.archived_at IS NULL AND s.workspace_id IS NULL
+		ORDER BY used.last_used DESC, s.id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(ctx, query, tag, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("get scenes for hashtag %q: %w", tag, err)
+	}
+	defer rows.Close()
+
+	scenes := []*models.Scene{}
+	for rows.Next() {
+		scene := &models.Scene{}
+		if err := scanScene(rows, scene); err != nil {
+			return nil, fmt.Errorf("scan hashtag scene row: %w", err)
+		}
+		scenes = append(scenes, scene)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate hashtag scene rows: %w", err)
+	}
+	return scenes, nil
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SavePublicKey registers key for its device, replacing the device's
+// previous key.
+func (s *PostgresUserStore) SavePublicKey(ctx context.Context, key models.PublicKey) (*models.PublicKey, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	saved := key
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO user_public_keys (user_id, device_id, algorithm, public_key)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, device_id) DO UPDATE
+		SET algorithm = EXCLUDED.algorithm, public_key = EXCLUDED.public_key, created_at = NOW()
+		RETURNING created_at`,
+		key.UserID, key.DeviceID, key.Algorithm, key.PublicKey,
+	).Scan(&saved.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("save public key of user %s device %s: %w", key.UserID, key.DeviceID, err)
+	}
+	return &saved, nil
+}
+
+// GetPublicKeys returns the public keys of every device of userID, oldest
+// first.
+func (s *PostgresUserStore) GetPublicKeys(ctx context.Context, userID string) ([]models.PublicKey, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT user_id, device_id, algorithm, public_key, created_at
+		FROM user_public_keys
+		WHERE user_id = $1
+		ORDER BY created_at, device_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get public keys of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	keys := []models.PublicKey{}
+	for rows.Next() {
+		var k models.PublicKey
+		if err := rows.Scan(&k.UserID, &k.DeviceID, &k.Algorithm, &k.PublicKey, &k.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan public key row: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate public key rows: %w", err)
+	}
+	return keys, nil
+}
+
+// DeletePublicKey removes the public key of one of userID's devices.
+func (s *PostgresUserStore) DeletePublicKey(ctx context.Context, userID, deviceID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx,
+		`DELETE FROM user_public_keys WHERE user_id = $1 AND device_id = $2`,
+		userID, deviceID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete public key of user %s device %s: %w", userID, deviceID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// AddConversationKeys stores version keyVersion of a conversation's key,
+// wrapped by senderID for each device in keys, all or nothing. Existing
+// rows are never replaced.
+func (s *PostgresDMStore) AddConversationKeys(ctx context.Context, dmID, senderID string, keyVersion int, keys []models.DMConversationKey) ([]models.DMConversationKey, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	users := map[string]bool{senderID: true}
+	userIDs := make([]string, len(keys))
+	deviceIDs := make([]string, len(keys))
+	wrapped := make([]string, len(keys))
+	for i, k := range keys {
+		users[k.UserID] = true
+		userIDs[i], deviceIDs[i], wrapped[i] = k.UserID, k.DeviceID, k.WrappedKey
+	}
+	members := make([]string, 0, len(users))
+	for userID := range users {
+		members = append(members, userID)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin add keys to DM %s: %w", dmID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var participants int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM dm_participants
+		WHERE dm_conversation_id::text = $1 AND user_id = ANY($2)`,
+		dmID, members,
+	).Scan(&participants)
+	if err != nil {
+		return nil, fmt.Errorf("check participants of DM %s: %w", dmID, err)
+	}
+	if participants != len(members) {
+		return nil, storage.ErrForbidden
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO dm_conversation_keys (dm_conversation_id, key_version, user_id, device_id, sender_id, wrapped_key)
+		SELECT $1::uuid, $2, t.user_id, t.device_id, $3, t.wrapped_key
+		FROM unnest($4::text[], $5::text[], $6::text[]) AS t (user_id, device_id, wrapped_key)
+		RETURNING dm_conversation_id::text, key_version, user_id, device_id, sender_id, wrapped_key, created_at`,
+		dmID, keyVersion, senderID, userIDs, deviceIDs, wrapped,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("add keys to DM %s: %w", dmID, err)
+	}
+	stored := make([]models.DMConversationKey, 0, len(keys))
+	for rows.Next() {
+		var k models.DMConversationKey
+		if err := rows.Scan(&k.DMID, &k.KeyVersion, &k.UserID, &k.DeviceID, &k.SenderID, &k.WrappedKey, &k.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan conversation key row: %w", err)
+		}
+		stored = append(stored, k)
+	}
+	rows.Close()
+	var pgErr *pgconn.PgError
+	if err = rows.Err(); errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("add keys to DM %s: %w", dmID, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit keys to DM %s: %w", dmID, err)
+	}
+	return stored, nil
+}
+
+// GetConversationKeys returns every version of a conversation's key wrapped
+// for one device of userID, newest first, so the device can read the whole
+// history.
+func (s *PostgresDMStore) GetConversationKeys(ctx context.Context, dmID, userID, deviceID string) ([]models.DMConversationKey, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT dm_conversation_id::text, key_version, user_id, device_id, sender_id, wrapped_key, created_at
+		FROM dm_conversation_keys
+		WHERE dm_conversation_id::text = $1 AND user_id = $2 AND device_id = $3
+		ORDER BY key_version DESC`,
+		dmID, userID, deviceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get keys of DM %s for user %s: %w", dmID, userID, err)
+	}
+	defer rows.Close()
+
+	keys := []models.DMConversationKey{}
+	for rows.Next() {
+		var k models.DMConversationKey
+		if err := rows.Scan(&k.DMID, &k.KeyVersion, &k.UserID, &k.DeviceID, &k.SenderID, &k.WrappedKey, &k.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan conversation key row: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate conversation key rows: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdvisoryLeader implements storage.Leader with a session-level Postgres
+// advisory lock. The lock is held on a dedicated pool connection, so it is
+// released automatically if this instance dies or loses its connection.
+type AdvisoryLeader struct {
+	db   *pgxpool.Pool
+	name string // Lock name, hashed into the advisory lock key
+
+	mu   sync.Mutex
+	conn *pgxpool.Conn // Holds the lock while this instance leads
+}
+
+var _ storage.Leader = (*AdvisoryLeader)(nil)
+
+// NewAdvisoryLeader creates an AdvisoryLeader competing for the lock name.
+func NewAdvisoryLeader(db *pgxpool.Pool, name string) *AdvisoryLeader {
+	return &AdvisoryLeader{db: db, name: name}
+}
+
+// IsLeader checks that the held lock connection is alive, or tries to take the lock.
+func (l *AdvisoryLeader) IsLeader(ctx context.Context) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		// Close the session so the lock cannot linger on a pooled connection
+		l.drop()
+	}
+
+	conn, err := l.db.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("acquire connection for leader lock %s: %w", l.name, err)
+	}
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, l.name).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, fmt.Errorf("try leader lock %s: %w", l.name, err)
+	}
+	if !acquired {
+		conn.Release()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Resign releases the lock if this instance holds it.
+func (l *AdvisoryLeader) Resign() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return
+	}
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, l.name); err != nil {
+		log.Printf("Error releasing leader lock %s: %v", l.name, err)
+		l.drop()
+		return
+	}
+	l.conn.Release()
+	l.conn = nil
+}
+
+// drop closes the lock connection instead of returning it to the pool, which
+// ends the session and releases the lock. Callers must hold l.mu.
+func (l *AdvisoryLeader) drop() {
+	ctx, cancel := withTimeout(context.Background())
+	defer cancel()
+	l.conn.Conn().Close(ctx)
+	l.conn.Release()
+	l.conn = nil
+}
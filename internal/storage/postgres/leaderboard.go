@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresLeaderboardStore implements storage.LeaderboardStore using PostgreSQL.
+type PostgresLeaderboardStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.LeaderboardStore = (*PostgresLeaderboardStore)(nil)
+
+// NewPostgresLeaderboardStore creates a new PostgresLeaderboardStore backed by the shared pool db.
+func NewPostgresLeaderboardStore(db *pgxpool.Pool) *PostgresLeaderboardStore {
+	return &PostgresLeaderboardStore{db: db}
+}
+
+// RefreshLeaderboards replaces all leaderboard entries in one transaction,
+// so readers see either the previous boards or the new ones. Scenes that
+// require approval to join are left off the scenes board; creators are
+// ranked by every scene they host, with ties broken by their listeners.
+// Scenes private to a workspace count toward neither board.
+func (s *PostgresLeaderboardStore) RefreshLeaderboards(ctx context.Context, weekSince time.Time, size int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin refresh leaderboards: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `DELETE FROM leaderboard_entries`); err != nil {
+		return 0, fmt.Errorf("clear leaderboards: %w", err)
+	}
+
+	query := `
+		WITH periods (period, since) AS (
+			VALUES ('week', $1::timestamptz), ('all-time', '-infinity'::timestamptz)
+		), scene_scores AS (
+			SELECT p.period, l.scene_id::text AS subject_id,
+				(SUM(EXTRACT(EPOCH FROM ` + sessionEnd + ` - joined_at)) / 3600)::float8 AS score,
+				COUNT(DISTINCT l.user_id) AS listeners
+			FROM periods p
+			JOIN scene_listen_sessions l ON l.joined_at >= p.since
+			JOIN scenes s ON s.id = l.scene_id
+			WHERE NOT s.join_approval AND s.workspace_id IS NULL
+			GROUP BY p.period, l.scene_id
+		), creator_listeners AS (
+			SELECT p.period, s.creator_id::text AS subject_id, COUNT(DISTINCT l.user_id) AS listeners
+			FROM periods p
+			JOIN scene_listen_sessions l ON l.joined_at >= p.since
+			JOIN scenes s ON s.id = l.scene_id AND s.workspace_id IS NULL
+			GROUP BY p.period, s.creator_id
+		), creator_scores AS (
+			SELECT c.period, c.subject_id, c.score, COALESCE(cl.listeners, 0) AS listeners
+			FROM (
+				SELECT p.period, s.creator_id::text AS subject_id, COUNT(*)::float8 AS score
+				FROM periods p
+				JOIN scenes s ON s.created_at >= p.since AND s.workspace_id IS NULL
+				GROUP BY p.period, s.creator_id
+			) c
+			JOIN users u ON u.id::text = c.subject_id
+			LEFT JOIN creator_listeners cl ON cl.period = c.period AND cl.subject_id = c.subject_id
+		), scored AS (
+			SELECT 'scenes' AS board, period, subject_id, score, listeners FROM scene_scores
+			UNION ALL
+			SELECT 'creators', period, subject_id, score, listeners FROM creator_scores
+		)
+		INSERT INTO leaderboard_entries (board, period, rank, subject_id, score, listeners)
+		SELECT board, period, rank, subject_id, score, listeners
+		FROM (
+			SELECT scored.*,
+				ROW_NUMBER() OVER (PARTITION BY board, period ORDER BY score DESC, listeners DESC, subject_id) AS rank
+			FROM scored
+			WHERE score > 0
+		) ranked
+		WHERE rank <= $2
+	`
+	result, err := tx.Exec(ctx, query, weekSince, size)
+	if err != nil {
+		return 0, fmt.Errorf("compute leaderboards: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit leaderboards: %w", err)
+	}
+
+	log.Printf("Refreshed leaderboards: %d entries stored", result.RowsAffected())
+	return result.RowsAffected(), nil
+}
+
+// GetLeaderboard returns a board's stored entries with their scenes or creators.
+func (s *PostgresLeaderboardStore) GetLeaderboard(ctx context.Context, board models.LeaderboardBoard, period models.LeaderboardPeriod) (*models.Leaderboard, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var query string
+	switch board {
+	case models.BoardScenes:
+		query = `
+			SELECT ` + sceneColumns + `, e.rank, e.score, e.listeners, e.computed_at
+			FROM leaderboard_entries e
+			JOIN scenes s ON s.id::text = e.subject_id
+			WHERE e.board = $1 AND e.period = $2 AND NOT s.join_approval AND s.workspace_id IS NULL
+			ORDER BY e.rank`
+	case models.BoardCreators:
+		query = `
+			SELECT ` + userColumns + `, e.rank, e.score, e.listeners, e.computed_at
+			FROM leaderboard_entries e
+			JOIN users ON users.id::text = e.subject_id
+			WHERE e.board = $1 AND e.period = $2
+			ORDER BY e.rank`
+	default:
+		return nil, fmt.Errorf("unknown leaderboard %q", board)
+	}
+
+	rows, err := s.db.Query(ctx, query, board, period)
+	if err != nil {
+		return nil, fmt.Errorf("get %s %s leaderboard: %w", period, board, err)
+	}
+	defer rows.Close()
+
+	leaderboard := &models.Leaderboard{Board: board, Period: period, Entries: []models.LeaderboardEntry{}}
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		var computedAt time.Time
+		extra := []any{&entry.Rank, &entry.Score, &entry.Listeners, &computedAt}
+		if board == models.BoardScenes {
+			entry.Scene = &models.Scene{}
+			err = scanScene(rows, entry.Scene, extra...)
+		} else {
+			var user models.User
+			err = scanUser(rows, &user, extra...)
+			summary := user.Summary()
+			entry.Creator = &summary
+		}
+		if err != nil {
+			return nil, fmt.Errorf("scan %s leaderboard row: %w", board, err)
+		}
+		leaderboard.Entries = append(leaderboard.Entries, entry)
+		leaderboard.ComputedAt = &computedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get %s %s leaderboard: %w", period, board, err)
+	}
+	return leaderboard, nil
+}
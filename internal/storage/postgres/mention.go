@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// mentionTarget describes how mentions attach to one kind of message.
+type mentionTarget struct {
+	column  string // message_mentions column referencing the message
+	members string // condition a user u must meet to be mentioned in message $1
+}
+
+var (
+	dmMentions = mentionTarget{
+		column: "dm_message_id",
+		members: `EXISTS (
+			SELECT 1 FROM dm_messages d
+			JOIN dm_participants p ON p.dm_conversation_id = d.dm_conversation_id
+			WHERE d.id = $1 AND p.user_id = u.id::text)`,
+	}
+	sceneMentions = mentionTarget{column: "scene_message_id", members: "TRUE"}
+)
+
+// setMentions replaces the mentions of messageID with the spans whose
+// username belongs to a user allowed by t. It returns the stored mentions
+// ordered by position; spans that did not resolve are dropped.
+func setMentions(ctx context.Context, db *pgxpool.Pool, t mentionTarget, messageID string, spans []models.Mention) ([]models.Mention, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin set mentions of message %s: %w", messageID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM message_mentions WHERE `+t.column+` = $1`, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("clear mentions of message %s: %w", messageID, err)
+	}
+
+	var mentions []models.Mention
+	if len(spans) > 0 {
+		usernames := make([]string, len(spans))
+		starts := make([]int32, len(spans))
+		ends := make([]int32, len(spans))
+		for i, span := range spans {
+			usernames[i], starts[i], ends[i] = span.Username, int32(span.Start), int32(span.End)
+		}
+		rows, err := tx.Query(ctx, `
+			INSERT INTO message_mentions (`+t.column+`, user_id, username, start_offset, end_offset)
+			SELECT $1, u.id::text, s.username, s.start_offset, s.end_offset
+			FROM unnest($2::text[], $3::int[], $4::int[]) AS s(username, start_offset, end_offset)
+			JOIN users u ON u.username = s.username
+			WHERE `+t.members+`
+			RETURNING user_id, username, start_offset, end_offset`,
+			messageID, usernames, starts, ends,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("add mentions to message %s: %w", messageID, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var m models.Mention
+			if err := rows.Scan(&m.UserID, &m.Username, &m.Start, &m.End); err != nil {
+				return nil, fmt.Errorf("scan mention row: %w", err)
+			}
+			mentions = append(mentions, m)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("iterate mention rows: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit mentions of message %s: %w", messageID, err)
+	}
+	slices.SortFunc(mentions, func(a, b models.Mention) int { return a.Start - b.Start })
+	return mentions, nil
+}
+
+// loadMentions returns the mentions of each message in messageIDs, keyed by
+// message ID and ordered by position.
+func loadMentions(ctx context.Context, db *pgxpool.Pool, column string, messageIDs []string) (map[string][]models.Mention, error) {
+	mentions := make(map[string][]models.Mention)
+	if len(messageIDs) == 0 {
+		return mentions, nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT `+column+`::text, user_id, username, start_offset, end_offset
+		FROM message_mentions
+		WHERE `+column+` = ANY($1)
+		ORDER BY start_offset`,
+		messageIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load mentions for %d messages: %w", len(messageIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID string
+		var m models.Mention
+		if err := rows.Scan(&messageID, &m.UserID, &m.Username, &m.Start, &m.End); err != nil {
+			return nil, fmt.Errorf("scan mention row: %w", err)
+		}
+		mentions[messageID] = append(mentions[messageID], m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mention rows: %w", err)
+	}
+	return mentions, nil
+}
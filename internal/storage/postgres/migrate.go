@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Migration is a SQL file in the migrations directory.
+type Migration struct {
+	Version string // File name, e.g. "001_create_users.sql"
+	Applied bool
+}
+
+// createMigrationsTable records which migration files have been applied.
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`
+
+// Migrations lists the .sql files in dir in order and whether each was applied.
+func Migrations(ctx context.Context, db *pgxpool.Pool, dir string) ([]Migration, error) {
+	files, err := migrationFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(ctx, createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	rows, err := db.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate applied migrations: %w", err)
+	}
+
+	migrations := make([]Migration, len(files))
+	for i, file := range files {
+		migrations[i] = Migration{Version: file, Applied: applied[file]}
+	}
+	return migrations, nil
+}
+
+// Migrate applies, in order, every .sql file in dir that has not been applied
+// yet, each in its own transaction. It returns the versions it applied and
+// stops at the first failure.
+func Migrate(ctx context.Context, db *pgxpool.Pool, dir string) ([]string, error) {
+	migrations, err := Migrations(ctx, db, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, m := range migrations {
+		if m.Applied {
+			continue
+		}
+		if err := applyMigration(ctx, db, dir, m.Version); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m.Version)
+	}
+	return applied, nil
+}
+
+// applyMigration runs one migration file and records it.
+func applyMigration(ctx context.Context, db *pgxpool.Pool, dir, version string) error {
+	sql, err := os.ReadFile(filepath.Join(dir, version))
+	if err != nil {
+		return fmt.Errorf("read migration %s: %w", version, err)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin migration %s: %w", version, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Exec without arguments uses the simple protocol, so a file may hold several statements.
+	if _, err := tx.Exec(ctx, string(sql)); err != nil {
+		return fmt.Errorf("apply migration %s: %w", version, err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return fmt.Errorf("record migration %s: %w", version, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit migration %s: %w", version, err)
+	}
+	return nil
+}
+
+// migrationFiles returns the names of the .sql files in dir, sorted.
+func migrationFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations directory: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".sql") {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
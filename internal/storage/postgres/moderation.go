@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresModerationStore implements storage.ModerationStore using PostgreSQL.
+type PostgresModerationStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.ModerationStore = (*PostgresModerationStore)(nil)
+
+// NewPostgresModerationStore creates a new PostgresModerationStore backed by the shared pool db.
+func NewPostgresModerationStore(db *pgxpool.Pool) *PostgresModerationStore {
+	return &PostgresModerationStore{db: db}
+}
+
+// FlagMessage records a flagged message and fills in the flag's ID and creation time.
+func (s *PostgresModerationStore) FlagMessage(ctx context.Context, flag *models.MessageFlag) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	reasons := flag.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	query := `
+		INSERT INTO message_flags (message_type, message_id, sender_id, reasons)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := s.db.QueryRow(ctx, query, flag.MessageType, flag.MessageID, flag.SenderID, reasons).Scan(&flag.ID, &flag.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("flag %s message %s: %w", flag.MessageType, flag.MessageID, err)
+	}
+	return nil
+}
+
+// reportTargets selects the (reported_user_id, content) of a live report
+// target with the given ID ($1) for each target type.
+var reportTargets = map[models.ReportTarget]string{
+	models.ReportDMMessage:    `SELECT sender_id, content FROM dm_messages WHERE id::text = $1 AND deleted_at IS NULL`,
+	models.ReportSceneMessage: `SELECT sender_id, content FROM scene_messages WHERE id::text = $1`,
+	models.ReportScene:        `SELECT creator_id::text, name FROM scenes WHERE id::text = $1`,
+	models.ReportUser:         `SELECT id::text, '' FROM users WHERE id::text = $1`,
+}
+
+// reportColumns is the column list scanned by scanReport.
+const reportColumns = `
+	id, reporter_id, target_type, target_id, reported_user_id, content, reason,
+	status, resolved_by, resolution_note, created_at, resolved_at
+`
+
+// scanReport scans a row selected with reportColumns.
+func scanReport(row interface{ Scan(...any) error }, report *models.Report) error {
+	return row.Scan(
+		&report.ID, &report.ReporterID, &report.TargetType, &report.TargetID, &report.ReportedUserID, &report.Content, &report.Reason,
+		&report.Status, &report.ResolvedBy, &report.ResolutionNote, &report.CreatedAt, &report.ResolvedAt,
+	)
+}
+
+// CreateReport files a report, recording who is responsible for the target
+// and a snapshot of its content. It returns storage.ErrNotFound if the target
+// does not exist and storage.ErrConflict if the reporter already has an open
+// report on it.
+func (s *PostgresModerationStore) CreateReport(ctx context.Context, report *models.Report) (*models.Report, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	target, ok := reportTargets[report.TargetType]
+	if !ok {
+		return nil, fmt.Errorf("create report: unknown target type %q", report.TargetType)
+	}
+
+	created := &models.Report{}
+	query := `
+		INSERT INTO reports (reporter_id, target_type, target_id, reported_user_id, content, reason)
+		SELECT $2, $3, $1, t.reported_user_id, t.content, $4
+		FROM (` + target + `) AS t (reported_user_id, content)
+		RETURNING ` + reportColumns
+	err := scanReport(s.db.QueryRow(ctx, query, report.TargetID, report.ReporterID, report.TargetType, report.Reason), created)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, storage.ErrConflict
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create report on %s %s: %w", report.TargetType, report.TargetID, err)
+	}
+
+	log.Printf("Report %s filed by %s on %s %s", created.ID, created.ReporterID, created.TargetType, created.TargetID)
+	return created, nil
+}
+
+// GetReport retrieves a report by ID.
+func (s *PostgresModerationStore) GetReport(ctx context.Context, reportID string) (*models.Report, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	report := &models.Report{}
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id::text = $1`
+	err := scanReport(s.db.QueryRow(ctx, query, reportID), report)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get report %s: %w", reportID, err)
+	}
+	return report, nil
+}
+
+// ListReports lists up to limit reports with the given status, oldest first.
+// An empty status lists reports of every status.
+func (s *PostgresModerationStore) ListReports(ctx context.Context, status models.ReportStatus, limit int) ([]models.Report, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var reports []models.Report
+	query := `
+		SELECT ` + reportColumns + `
+		FROM reports
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at ASC, id
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, string(status), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list %q reports: %w", status, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var report models.Report
+		if err := scanReport(rows, &report); err != nil {
+			return nil, fmt.Errorf("scan report row: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate report rows: %w", err)
+	}
+	return reports, nil
+}
+
+// ResolveReport closes an open report with the given status. It returns
+// storage.ErrNotFound if the report does not exist and storage.ErrConflict
+// if it was already resolved.
+func (s *PostgresModerationStore) ResolveReport(ctx context.Context, reportID, resolvedBy string, status models.ReportStatus, note string) (*models.Report, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	report := &models.Report{}
+	query := `
+		UPDATE reports
+		SET status = $2, resolved_by = $3, resolution_note = $4, resolved_at = NOW()
+		WHERE id::text = $1 AND status = 'open'
+		RETURNING ` + reportColumns
+	err := scanReport(s.db.QueryRow(ctx, query, reportID, string(status), resolvedBy, note), report)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Distinguish a missing report from one that is already closed
+		if _, getErr := s.GetReport(ctx, reportID); getErr != nil {
+			return nil, getErr
+		}
+		return nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve report %s: %w", reportID, err)
+	}
+
+	log.Printf("Report %s %s by %s", report.ID, report.Status, resolvedBy)
+	return report, nil
+}
@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresNotificationStore implements storage.NotificationStore using PostgreSQL.
+type PostgresNotificationStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.NotificationStore = (*PostgresNotificationStore)(nil)
+
+// NewPostgresNotificationStore creates a new PostgresNotificationStore backed by the shared pool db.
+func NewPostgresNotificationStore(db *pgxpool.Pool) *PostgresNotificationStore {
+	return &PostgresNotificationStore{db: db}
+}
+
+// GetNotificationPreferences reads each user's notification settings from
+// their saved settings, over the defaults, together with their override for
+// the DM or scene and whether they muted the DM.
+func (s *PostgresNotificationStore) GetNotificationPreferences(ctx context.Context, userIDs []string, dmID, sceneID string) (map[string]models.NotificationPreferences, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT u.id, st.settings->'notifications', o.level, p.muted_at IS NOT NULL
+		FROM unnest($1::text[]) AS u(id)
+		LEFT JOIN user_settings st ON st.user_id = u.id
+		LEFT JOIN notification_overrides o ON o.user_id = u.id
+			AND (o.dm_conversation_id::text = $2 OR o.scene_id::text = $3)
+		LEFT JOIN dm_participants p ON p.user_id = u.id AND p.dm_conversation_id::text = $2
+	`
+	rows, err := s.db.Query(ctx, query, userIDs, dmID, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	defaults := models.DefaultUserSettings().Notifications
+	prefs := make(map[string]models.NotificationPreferences, len(userIDs))
+	for rows.Next() {
+		var userID string
+		var raw []byte
+		var level *string
+		var muted bool
+		if err := rows.Scan(&userID, &raw, &level, &muted); err != nil {
+			return nil, fmt.Errorf("scan notification preferences row: %w", err)
+		}
+		p := models.NotificationPreferences{Settings: defaults, Muted: muted}
+		if raw != nil {
+			if err := json.Unmarshal(raw, &p.Settings); err != nil {
+				return nil, fmt.Errorf("decode notification settings of user %s: %w", userID, err)
+			}
+		}
+		if level != nil {
+			p.Override = models.NotificationLevel(*level)
+		}
+		prefs[userID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification preferences rows: %w", err)
+	}
+	return prefs, nil
+}
+
+// SetNotificationOverride inserts or replaces userID's override for a DM
+// they take part in or an existing scene.
+func (s *PostgresNotificationStore) SetNotificationOverride(ctx context.Context, userID, dmID, sceneID string, level models.NotificationLevel) (*models.NotificationOverride, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO notification_overrides (user_id, scene_id, level)
+		SELECT $1, s.id, $3 FROM scenes s WHERE s.id::text = $2
+		ON CONFLICT (user_id, scene_id) WHERE scene_id IS NOT NULL
+		DO UPDATE SET level = EXCLUDED.level, updated_at = NOW()
+		RETURNING updated_at
+	`
+	target := sceneID
+	if dmID != "" {
+		query = `
+			INSERT INTO notification_overrides (user_id, dm_conversation_id, level)
+			SELECT $1, p.dm_conversation_id, $3 FROM dm_participants p
+			WHERE p.dm_conversation_id::text = $2 AND p.user_id = $1
+			ON CONFLICT (user_id, dm_conversation_id) WHERE dm_conversation_id IS NOT NULL
+			DO UPDATE SET level = EXCLUDED.level, updated_at = NOW()
+			RETURNING updated_at
+		`
+		target = dmID
+	}
+
+	o := models.NotificationOverride{UserID: userID, DMID: dmID, SceneID: sceneID, Level: level}
+	err := s.db.QueryRow(ctx, query, userID, target, string(level)).Scan(&o.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set notification override of user %s for %s: %w", userID, target, err)
+	}
+	return &o, nil
+}
+
+// DeleteNotificationOverride removes userID's override for a DM or scene.
+func (s *PostgresNotificationStore) DeleteNotificationOverride(ctx context.Context, userID, dmID, sceneID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM notification_overrides
+		WHERE user_id = $1 AND (dm_conversation_id::text = $2 OR scene_id::text = $3)`,
+		userID, dmID, sceneID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete notification override of user %s: %w", userID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// GetNotificationOverrides returns userID's overrides, most recently changed first.
+func (s *PostgresNotificationStore) GetNotificationOverrides(ctx context.Context, userID string) ([]models.NotificationOverride, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT user_id, COALESCE(dm_conversation_id::text, ''), COALESCE(scene_id::text, ''), level, updated_at
+		FROM notification_overrides
+		WHERE user_id = $1
+		ORDER BY updated_at DESC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get notification overrides of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	overrides := []models.NotificationOverride{}
+	for rows.Next() {
+		var o models.NotificationOverride
+		if err := rows.Scan(&o.UserID, &o.DMID, &o.SceneID, &o.Level, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan notification override row: %w", err)
+		}
+		overrides = append(overrides, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notification override rows: %w", err)
+	}
+	return overrides, nil
+}
+
+// QueueNotification holds n for userID until their quiet hours end.
+func (s *PostgresNotificationStore) QueueNotification(ctx context.Context, userID string, n models.QueuedNotification) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO notification_queue (user_id, channel, type, payload)
+		VALUES ($1, $2, $3, $4::jsonb)`,
+		userID, string(n.Channel), n.Type, []byte(n.Payload),
+	)
+	if err != nil {
+		return fmt.Errorf("queue %s notification for user %s: %w", n.Type, userID, err)
+	}
+	return nil
+}
+
+// GetQueuedNotificationUsers returns the users with held notifications.
+func (s *PostgresNotificationStore) GetQueuedNotificationUsers(ctx context.Context) ([]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var userIDs []string
+	rows, err := s.db.Query(ctx, `SELECT DISTINCT user_id FROM notification_queue`)
+	if err != nil {
+		return nil, fmt.Errorf("get users with queued notifications: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan user with queued notifications: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate users with queued notifications: %w", err)
+	}
+	return userIDs, nil
+}
+
+// TakeQueuedNotifications deletes userID's held notifications and returns
+// them in the order they were queued.
+func (s *PostgresNotificationStore) TakeQueuedNotifications(ctx context.Context, userID string) ([]models.QueuedNotification, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		WITH taken AS (
+			DELETE FROM notification_queue
+			WHERE user_id = $1
+			RETURNING id, channel, type, payload, created_at
+		)
+		SELECT channel, type, payload, created_at FROM taken ORDER BY id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("take queued notifications of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var queued []models.QueuedNotification
+	for rows.Next() {
+		var n models.QueuedNotification
+		var payload []byte
+		if err := rows.Scan(&n.Channel, &n.Type, &payload, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan queued notification: %w", err)
+		}
+		n.Payload = payload
+		queued = append(queued, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate queued notifications: %w", err)
+	}
+	return queued, nil
+}
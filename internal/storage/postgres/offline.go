@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresOfflineStore implements storage.OfflineStore using PostgreSQL.
+type PostgresOfflineStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.OfflineStore = (*PostgresOfflineStore)(nil)
+
+// NewPostgresOfflineStore creates a new PostgresOfflineStore backed by the shared pool db.
+func NewPostgresOfflineStore(db *pgxpool.Pool) *PostgresOfflineStore {
+	return &PostgresOfflineStore{db: db}
+}
+
+// EnqueueDMEvent inserts one row per recipient.
+func (s *PostgresOfflineStore) EnqueueDMEvent(ctx context.Context, dmID string, userIDs []string, event []byte) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO dm_offline_events (user_id, dm_conversation_id, event)
+		SELECT u, $1, $3 FROM unnest($2::text[]) AS u`,
+		dmID, userIDs, event,
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue offline event for DM %s: %w", dmID, err)
+	}
+	return nil
+}
+
+// TakeDMEvents deletes the user's queued events for dmID and returns them in the order they were queued.
+func (s *PostgresOfflineStore) TakeDMEvents(ctx context.Context, userID, dmID string) ([][]byte, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		WITH taken AS (
+			DELETE FROM dm_offline_events
+			WHERE user_id = $1 AND dm_conversation_id = $2
+			RETURNING id, event
+		)
+		SELECT event FROM taken ORDER BY id`,
+		userID, dmID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("take offline events of user %s in DM %s: %w", userID, dmID, err)
+	}
+	defer rows.Close()
+
+	var events [][]byte
+	for rows.Next() {
+		var event []byte
+		if err := rows.Scan(&event); err != nil {
+			return nil, fmt.Errorf("scan offline event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate offline events: %w", err)
+	}
+	return events, nil
+}
+
+// DeleteDMEventsBefore deletes up to limit events queued before cutoff.
+func (s *PostgresOfflineStore) DeleteDMEventsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM dm_offline_events WHERE id IN (
+			SELECT id FROM dm_offline_events WHERE created_at < $1 LIMIT $2
+		)`, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete offline events queued before %s: %w", cutoff, err)
+	}
+	return tag.RowsAffected(), nil
+}
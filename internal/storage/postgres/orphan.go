@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresOrphanBlobStore implements storage.OrphanBlobStore using PostgreSQL.
+type PostgresOrphanBlobStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.OrphanBlobStore = (*PostgresOrphanBlobStore)(nil)
+
+// NewPostgresOrphanBlobStore creates a new PostgresOrphanBlobStore backed by the shared pool db.
+func NewPostgresOrphanBlobStore(db *pgxpool.Pool) *PostgresOrphanBlobStore {
+	return &PostgresOrphanBlobStore{db: db}
+}
+
+// PendingOrphanBlobs returns up to limit queued files in the order they were queued.
+func (s *PostgresOrphanBlobStore) PendingOrphanBlobs(ctx context.Context, limit int) ([]models.OrphanBlob, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `SELECT id, bucket, object_key FROM orphan_blobs ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query orphaned blobs: %w", err)
+	}
+	defer rows.Close()
+
+	var blobs []models.OrphanBlob
+	for rows.Next() {
+		var b models.OrphanBlob
+		if err := rows.Scan(&b.ID, &b.Bucket, &b.Key); err != nil {
+			return nil, fmt.Errorf("scan orphaned blob: %w", err)
+		}
+		blobs = append(blobs, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate orphaned blobs: %w", err)
+	}
+	return blobs, nil
+}
+
+// ForgetOrphanBlobs removes the given files from the queue.
+func (s *PostgresOrphanBlobStore) ForgetOrphanBlobs(ctx context.Context, ids []int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, `DELETE FROM orphan_blobs WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return fmt.Errorf("forget %d orphaned blobs: %w", len(ids), err)
+	}
+	return nil
+}
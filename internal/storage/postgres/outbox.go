@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// outboxEnabled makes the stores record domain events. See EnableOutbox.
+var outboxEnabled bool
+
+// EnableOutbox makes the stores write an outbox_events row in the same
+// transaction as each scene creation, scene join, and scene or DM message.
+// It is off by default so the table does not grow without a publisher, and
+// should be called once at startup, before the stores are used.
+func EnableOutbox(enabled bool) {
+	outboxEnabled = enabled
+}
+
+// execer is satisfied by both the pool and a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// writeOutbox records an event when the outbox is enabled. Pass the
+// transaction making the change so the event commits or rolls back with it.
+func writeOutbox(ctx context.Context, q execer, aggregateType, aggregateID, event string, payload any) error {
+	if !outboxEnabled {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s outbox event: %w", event, err)
+	}
+	_, err = q.Exec(ctx, `
+		INSERT INTO outbox_events (aggregate_type, aggregate_id, event, payload)
+		VALUES ($1, $2, $3, $4)`,
+		aggregateType, aggregateID, event, data,
+	)
+	if err != nil {
+		return fmt.Errorf("write %s outbox event for %s %s: %w", event, aggregateType, aggregateID, err)
+	}
+	return nil
+}
+
+// PostgresOutboxStore implements storage.OutboxStore using PostgreSQL.
+type PostgresOutboxStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.OutboxStore = (*PostgresOutboxStore)(nil)
+
+// NewPostgresOutboxStore creates a new PostgresOutboxStore backed by the shared pool db.
+func NewPostgresOutboxStore(db *pgxpool.Pool) *PostgresOutboxStore {
+	return &PostgresOutboxStore{db: db}
+}
+
+// PendingEvents returns up to limit unpublished events in the order they were written.
+func (s *PostgresOutboxStore) PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, event_id, aggregate_type, aggregate_id, event, payload, created_at
+		FROM outbox_events
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query pending outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var e models.OutboxEvent
+		if err := rows.Scan(&e.ID, &e.EventID, &e.AggregateType, &e.AggregateID, &e.Event, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkPublished stamps the given events as published.
+func (s *PostgresOutboxStore) MarkPublished(ctx context.Context, ids []int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, `UPDATE outbox_events SET published_at = NOW() WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return fmt.Errorf("mark %d outbox events published: %w", len(ids), err)
+	}
+	return nil
+}
+
+// DeletePublishedBefore deletes up to limit events published before cutoff.
+func (s *PostgresOutboxStore) DeletePublishedBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM outbox_events WHERE id IN (
+			SELECT id FROM outbox_events WHERE published_at < $1 LIMIT $2
+		)`, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete outbox events published before %s: %w", cutoff, err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresPlaybackStore implements storage.PlaybackStore using PostgreSQL.
+type PostgresPlaybackStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.PlaybackStore = (*PostgresPlaybackStore)(nil)
+
+// NewPostgresPlaybackStore creates a new PostgresPlaybackStore backed by the shared pool db.
+func NewPostgresPlaybackStore(db *pgxpool.Pool) *PostgresPlaybackStore {
+	return &PostgresPlaybackStore{db: db}
+}
+
+// playbackColumns is the column list scanned by scanPlayback.
+const playbackColumns = `scene_id, track_id, track_title, track_artist, position_ms, is_playing, updated_by, updated_at, started_by, started_at`
+
+// scanPlayback scans a row selected with playbackColumns.
+func scanPlayback(row interface{ Scan(...any) error }, p *models.PlaybackState) error {
+	return row.Scan(
+		&p.SceneID, &p.TrackID, &p.TrackTitle, &p.TrackArtist,
+		&p.PositionMs, &p.IsPlaying, &p.UpdatedBy, &p.UpdatedAt,
+		&p.StartedBy, &p.StartedAt,
+	)
+}
+
+// GetPlayback retrieves the playback state for a scene.
+func (s *PostgresPlaybackStore) GetPlayback(ctx context.Context, sceneID string) (*models.PlaybackState, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	state := &models.PlaybackState{}
+	query := `SELECT ` + playbackColumns + ` FROM scene_playback WHERE scene_id = $1`
+	err := scanPlayback(s.db.QueryRow(ctx, query, sceneID), state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get playback for scene %s: %w", sceneID, err)
+	}
+	return state, nil
+}
+
+// recordPlay appends a track to a scene's play history.
+func recordPlay(ctx context.Context, tx pgx.Tx, sceneID, trackID, title, artist, playedBy string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO scene_play_history (scene_id, track_id, track_title, track_artist, played_by)
+		VALUES ($1, $2, $3, $4, $5)`,
+		sceneID, trackID, title, artist, playedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("record play of %s in scene %s: %w", trackID, sceneID, err)
+	}
+	return nil
+}
+
+// SetPlayback replaces the playback state for a scene. UpdatedAt is set by the
+// database, and StartedBy and StartedAt are stamped when the track changes.
+func (s *PostgresPlaybackStore) SetPlayback(ctx context.Context, state *models.PlaybackState) (*models.PlaybackState, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin playback update for scene %s: %w", state.SceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Read the current track under lock to tell a track change from a seek or pause
+	var previous string
+	err = tx.QueryRow(ctx,
+		`SELECT track_id FROM scene_playback WHERE scene_id = $1 FOR UPDATE`,
+		state.SceneID,
+	).Scan(&previous)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("lock playback for scene %s: %w", state.SceneID, err)
+	}
+
+	saved := &models.PlaybackState{}
+	query := `
+		INSERT INTO scene_playback (scene_id, track_id, track_title, track_artist, position_ms, is_playing, updated_by, updated_at, started_by, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), $7, NOW())
+		ON CONFLICT (scene_id) DO UPDATE SET
+			started_by = CASE WHEN scene_playback.track_id = EXCLUDED.track_id
+				THEN scene_playback.started_by ELSE EXCLUDED.started_by END,
+			started_at = CASE WHEN scene_playback.track_id = EXCLUDED.track_id
+				THEN scene_playback.started_at ELSE EXCLUDED.started_at END,
+			track_id = EXCLUDED.track_id,
+			track_title = EXCLUDED.track_title,
+			track_artist = EXCLUDED.track_artist,
+			position_ms = EXCLUDED.position_ms,
+			is_playing = EXCLUDED.is_playing,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING ` + playbackColumns
+	err = scanPlayback(tx.QueryRow(ctx, query,
+		state.SceneID, state.TrackID, state.TrackTitle, state.TrackArtist,
+		state.PositionMs, state.IsPlaying, state.UpdatedBy,
+	), saved)
+	if err != nil {
+		return nil, fmt.Errorf("set playback for scene %s: %w", state.SceneID, err)
+	}
+
+	if saved.TrackID != "" && saved.TrackID != previous {
+		err = recordPlay(ctx, tx, saved.SceneID, saved.TrackID, saved.TrackTitle, saved.TrackArtist, saved.UpdatedBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Skip votes only ever count towards the track that is playing
+	_, err = tx.Exec(ctx,
+		`DELETE FROM scene_skip_votes WHERE scene_id = $1 AND track_id <> $2`,
+		saved.SceneID, saved.TrackID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("clear stale skip votes for scene %s: %w", saved.SceneID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit playback update for scene %s: %w", saved.SceneID, err)
+	}
+
+	log.Printf("Playback updated for scene %s by %s (track=%s, playing=%t)", saved.SceneID, saved.UpdatedBy, saved.TrackID, saved.IsPlaying)
+	return saved, nil
+}
+
+// AddSkipVote records a vote to skip the track that is playing in a scene.
+func (s *PostgresPlaybackStore) AddSkipVote(ctx context.Context, sceneID, trackID, userID string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		INSERT INTO scene_skip_votes (scene_id, track_id, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`,
+		sceneID, trackID, userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("add skip vote of %s in scene %s: %w", userID, sceneID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, storage.ErrConflict
+	}
+
+	var votes int
+	err = s.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM scene_skip_votes WHERE scene_id = $1 AND track_id = $2`,
+		sceneID, trackID,
+	).Scan(&votes)
+	if err != nil {
+		return 0, fmt.Errorf("count skip votes in scene %s: %w", sceneID, err)
+	}
+	return votes, nil
+}
+
+// SkipTrack advances a scene's playback to the head of its queue.
+func (s *PostgresPlaybackStore) SkipTrack(ctx context.Context, sceneID, trackID, updatedBy string) (*models.PlaybackState, *models.QueueItem, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin skip in scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the playback row so two votes reaching the threshold together
+	// skip only once
+	var locked string
+	err = tx.QueryRow(ctx,
+		`SELECT scene_id FROM scene_playback WHERE scene_id = $1 AND track_id = $2 FOR UPDATE`,
+		sceneID, trackID,
+	).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("lock playback for scene %s: %w", sceneID, err)
+	}
+
+	var next *models.QueueItem
+	item := &models.QueueItem{}
+	query := `
+		DELETE FROM scene_queue
+		WHERE id = (SELECT id FROM scene_queue WHERE scene_id = $1 ORDER BY position ASC LIMIT 1)
+		RETURNING ` + queueColumns
+	err = scanQueueItem(tx.QueryRow(ctx, query, sceneID), item)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return nil, nil, fmt.Errorf("pop queue for scene %s: %w", sceneID, err)
+	default:
+		next = item
+		_, err = tx.Exec(ctx,
+			`UPDATE scene_queue SET position = position - 1 WHERE scene_id = $1 AND position > $2`,
+			sceneID, item.Position,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("compact queue for scene %s: %w", sceneID, err)
+		}
+	}
+
+	saved := &models.PlaybackState{}
+	query = `
+		UPDATE scene_playback SET
+			track_id = $2, track_title = $3, track_artist = $4,
+			position_ms = 0, is_playing = $5, updated_by = $6, updated_at = NOW(),
+			started_by = $6, started_at = NOW()
+		WHERE scene_id = $1
+		RETURNING ` + playbackColumns
+	if next != nil {
+		err = scanPlayback(tx.QueryRow(ctx, query, sceneID, next.ProviderID, next.Title, next.Artist, true, updatedBy), saved)
+	} else {
+		err = scanPlayback(tx.QueryRow(ctx, query, sceneID, "", "", "", false, updatedBy), saved)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("advance playback for scene %s: %w", sceneID, err)
+	}
+
+	if next != nil && next.ProviderID != "" {
+		if err = recordPlay(ctx, tx, sceneID, next.ProviderID, next.Title, next.Artist, updatedBy); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM scene_skip_votes WHERE scene_id = $1`, sceneID); err != nil {
+		return nil, nil, fmt.Errorf("clear skip votes for scene %s: %w", sceneID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit skip in scene %s: %w", sceneID, err)
+	}
+
+	log.Printf("Track %s skipped in scene %s (next=%s)", trackID, sceneID, saved.TrackID)
+	return saved, next, nil
+}
+
+// GetPlayHistory lists the tracks started in a scene in play order.
+func (s *PostgresPlaybackStore) GetPlayHistory(ctx context.Context, sceneID string) ([]models.PlayedTrack, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var plays []models.PlayedTrack
+	query := `
+		SELECT track_id, track_title, track_artist, played_by, played_at
+		FROM scene_play_history
+		WHERE scene_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := s.db.Query(ctx, query, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get play history for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		play := models.PlayedTrack{}
+		if err := rows.Scan(&play.TrackID, &play.Title, &play.Artist, &play.PlayedBy, &play.PlayedAt); err != nil {
+			return nil, fmt.Errorf("scan play history row for scene %s: %w", sceneID, err)
+		}
+		plays = append(plays, play)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate play history rows for scene %s: %w", sceneID, err)
+	}
+	return plays, nil
+}
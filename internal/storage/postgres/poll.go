@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// pollColumns is the select list read by scanPoll; scene_polls must be aliased as p.
+// votes holds one count per option, in option order.
+const pollColumns = `
+	p.id, p.scene_id, p.created_by, p.question, p.options, p.created_at, p.closed_at,
+	ARRAY(
+		SELECT COUNT(v.user_id) FROM generate_subscripts(p.options, 1) AS i
+		LEFT JOIN scene_poll_votes v ON v.poll_id = p.id AND v.option_index = i - 1
+		GROUP BY i ORDER BY i
+	) AS votes`
+
+// scanPoll scans a row selected with pollColumns into poll.
+func scanPoll(row interface{ Scan(...any) error }, poll *models.ScenePoll) error {
+	var options []string
+	var votes []int64
+	err := row.Scan(&poll.ID, &poll.SceneID, &poll.CreatedBy, &poll.Question, &options, &poll.CreatedAt, &poll.ClosedAt, &votes)
+	if err != nil {
+		return err
+	}
+	poll.Options = make([]models.PollOption, len(options))
+	poll.TotalVotes = 0
+	for i, text := range options {
+		poll.Options[i] = models.PollOption{Text: text}
+		if i < len(votes) {
+			poll.Options[i].Votes = int(votes[i])
+			poll.TotalVotes += int(votes[i])
+		}
+	}
+	return nil
+}
+
+// CreatePoll asks a new question in a scene.
+func (s *PostgresSceneStore) CreatePoll(ctx context.Context, sceneID, createdBy, question string, options []string) (*models.ScenePoll, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	exists, err := s.sceneExists(ctx, sceneID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, storage.ErrNotFound
+	}
+
+	var pollID string
+	err = s.db.QueryRow(ctx,
+		`INSERT INTO scene_polls (scene_id, created_by, question, options) VALUES ($1, $2, $3, $4) RETURNING id`,
+		sceneID, createdBy, question, options,
+	).Scan(&pollID)
+	if err != nil {
+		return nil, fmt.Errorf("create poll in scene %s: %w", sceneID, err)
+	}
+
+	log.Printf("Poll %s created in scene %s by %s.", pollID, sceneID, createdBy)
+	return s.GetPoll(ctx, pollID)
+}
+
+// GetPoll retrieves a poll and its vote counts.
+func (s *PostgresSceneStore) GetPoll(ctx context.Context, pollID string) (*models.ScenePoll, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	poll := &models.ScenePoll{}
+	err := scanPoll(s.db.QueryRow(ctx, `SELECT `+pollColumns+` FROM scene_polls p WHERE p.id = $1`, pollID), poll)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get poll %s: %w", pollID, err)
+	}
+	return poll, nil
+}
+
+// GetPolls lists the polls of a scene with their vote counts, newest first.
+func (s *PostgresSceneStore) GetPolls(ctx context.Context, sceneID string) ([]models.ScenePoll, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var polls []models.ScenePoll
+	rows, err := s.db.Query(ctx, `SELECT `+pollColumns+` FROM scene_polls p WHERE p.scene_id = $1 ORDER BY p.created_at DESC`, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get polls for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var poll models.ScenePoll
+		if err := scanPoll(rows, &poll); err != nil {
+			return nil, fmt.Errorf("scan poll row for scene %s: %w", sceneID, err)
+		}
+		polls = append(polls, poll)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate poll rows for scene %s: %w", sceneID, err)
+	}
+	return polls, nil
+}
+
+// Vote records userID's choice, replacing any earlier vote on the same poll.
+func (s *PostgresSceneStore) Vote(ctx context.Context, pollID, userID string, option int) (*models.ScenePoll, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		INSERT INTO scene_poll_votes (poll_id, user_id, option_index)
+		SELECT id, $2, $3 FROM scene_polls
+		WHERE id = $1 AND closed_at IS NULL AND $3 >= 0 AND $3 < cardinality(options)
+		ON CONFLICT (poll_id, user_id) DO UPDATE SET option_index = EXCLUDED.option_index, voted_at = NOW()`,
+		pollID, userID, option,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("record vote of %s on poll %s: %w", userID, pollID, err)
+	}
+
+	poll, err := s.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		if poll.ClosedAt != nil {
+			return nil, storage.ErrForbidden
+		}
+		return nil, storage.ErrConflict
+	}
+	return poll, nil
+}
+
+// ClosePoll marks a poll closed; its votes are kept as the final results.
+func (s *PostgresSceneStore) ClosePoll(ctx context.Context, pollID string) (*models.ScenePoll, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `UPDATE scene_polls SET closed_at = NOW() WHERE id = $1 AND closed_at IS NULL`, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("close poll %s: %w", pollID, err)
+	}
+
+	poll, err := s.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, storage.ErrConflict
+	}
+
+	log.Printf("Poll %s in scene %s closed.", pollID, poll.SceneID)
+	return poll, nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reactionTarget describes how reactions attach to one kind of message.
+type reactionTarget struct {
+	column       string // message_reactions column referencing the message
+	messageTable string // table holding the messages
+	liveFilter   string // extra condition a message must meet to accept reactions
+}
+
+var (
+	dmReactions    = reactionTarget{column: "dm_message_id", messageTable: "dm_messages", liveFilter: "deleted_at IS NULL"}
+	sceneReactions = reactionTarget{column: "scene_message_id", messageTable: "scene_messages", liveFilter: "TRUE"}
+)
+
+// addReaction records userID's emoji reaction on messageID. It returns
+// storage.ErrNotFound if the message does not accept reactions and
+// storage.ErrConflict if the user already reacted with that emoji.
+func addReaction(ctx context.Context, db *pgxpool.Pool, t reactionTarget, messageID, userID, emoji string) error {
+	query := `
+		INSERT INTO message_reactions (` + t.column + `, user_id, emoji)
+		SELECT id, $2, $3 FROM ` + t.messageTable + ` WHERE id = $1 AND ` + t.liveFilter + `
+		ON CONFLICT DO NOTHING
+	`
+	result, err := db.Exec(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return fmt.Errorf("add reaction to message %s: %w", messageID, err)
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	exists, err := reactableMessageExists(ctx, db, t, messageID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrNotFound
+	}
+	return storage.ErrConflict
+}
+
+// removeReaction deletes userID's emoji reaction on messageID. It returns
+// storage.ErrNotFound if the user had not reacted with that emoji.
+func removeReaction(ctx context.Context, db *pgxpool.Pool, t reactionTarget, messageID, userID, emoji string) error {
+	query := `DELETE FROM message_reactions WHERE ` + t.column + ` = $1 AND user_id = $2 AND emoji = $3`
+	result, err := db.Exec(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return fmt.Errorf("remove reaction from message %s: %w", messageID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// reactableMessageExists reports whether messageID exists and accepts reactions.
+func reactableMessageExists(ctx context.Context, db *pgxpool.Pool, t reactionTarget, messageID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM ` + t.messageTable + ` WHERE id = $1 AND ` + t.liveFilter + `)`
+	err := db.QueryRow(ctx, query, messageID).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check message %s: %w", messageID, err)
+	}
+	return exists, nil
+}
+
+// loadReactions returns the reaction counts of each message in messageIDs,
+// keyed by message ID. Emojis are ordered by when they were first used.
+func loadReactions(ctx context.Context, db *pgxpool.Pool, t reactionTarget, messageIDs []string) (map[string][]models.ReactionCount, error) {
+	reactions := make(map[string][]models.ReactionCount)
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+
+	query := `
+		SELECT ` + t.column + `::text, emoji, COUNT(*)
+		FROM message_reactions
+		WHERE ` + t.column + ` = ANY($1)
+		GROUP BY ` + t.column + `, emoji
+		ORDER BY MIN(created_at), emoji
+	`
+	rows, err := db.Query(ctx, query, messageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load reactions for %d messages: %w", len(messageIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID string
+		var rc models.ReactionCount
+		if err := rows.Scan(&messageID, &rc.Emoji, &rc.Count); err != nil {
+			return nil, fmt.Errorf("scan reaction row: %w", err)
+		}
+		reactions[messageID] = append(reactions[messageID], rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reaction rows: %w", err)
+	}
+	return reactions, nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MarkDelivered records that messages of dmID reached userID. Messages of
+// other conversations, userID's own messages, and messages already delivered
+// to them are skipped, as is everything if userID is not a participant.
+func (s *PostgresDMStore) MarkDelivered(ctx context.Context, dmID, userID string, messageIDs []string) ([]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, `
+		INSERT INTO dm_message_receipts (dm_message_id, user_id)
+		SELECT m.id, p.user_id
+		FROM dm_messages m
+		JOIN dm_participants p ON p.dm_conversation_id = m.dm_conversation_id AND p.user_id = $2
+		WHERE m.dm_conversation_id::text = $1 AND m.id::text = ANY($3) AND m.sender_id <> $2
+		ON CONFLICT DO NOTHING
+		RETURNING dm_message_id::text`,
+		dmID, userID, messageIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("mark %d messages of DM %s delivered to user %s: %w", len(messageIDs), dmID, userID, err)
+	}
+	defer rows.Close()
+
+	var delivered []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan delivered message ID: %w", err)
+		}
+		delivered = append(delivered, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate delivered message IDs: %w", err)
+	}
+	return delivered, nil
+}
+
+// loadReceipts returns the receipts of each DM message in messageIDs, keyed
+// by message ID and ordered by delivery.
+func loadReceipts(ctx context.Context, db *pgxpool.Pool, messageIDs []string) (map[string][]models.DMReceipt, error) {
+	receipts := make(map[string][]models.DMReceipt)
+	if len(messageIDs) == 0 {
+		return receipts, nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT dm_message_id::text, user_id, delivered_at, read_at
+		FROM dm_message_receipts
+		WHERE dm_message_id = ANY($1)
+		ORDER BY delivered_at, user_id`,
+		messageIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load receipts for %d messages: %w", len(messageIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID string
+		var r models.DMReceipt
+		if err := rows.Scan(&messageID, &r.UserID, &r.DeliveredAt, &r.ReadAt); err != nil {
+			return nil, fmt.Errorf("scan receipt row: %w", err)
+		}
+		receipts[messageID] = append(receipts[messageID], r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate receipt rows: %w", err)
+	}
+	return receipts, nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRecommendationStore implements storage.RecommendationStore using PostgreSQL.
+type PostgresRecommendationStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.RecommendationStore = (*PostgresRecommendationStore)(nil)
+
+// NewPostgresRecommendationStore creates a new PostgresRecommendationStore backed by the shared pool db.
+func NewPostgresRecommendationStore(db *pgxpool.Pool) *PostgresRecommendationStore {
+	return &PostgresRecommendationStore{db: db}
+}
+
+// publicScene is the condition a scene s must meet to be recommended: open
+// to anyone without approval, outside any workspace, and not archived.
+const publicScene = `NOT s.join_approval AND s.workspace_id IS NULL AND s.archived_at IS NULL`
+
+// RefreshRecommendations replaces all recommendations in one transaction, so
+// readers see either the previous set or the new one. Scenes a user created
+// or has already joined are not recommended to them.
+func (s *PostgresRecommendationStore) RefreshRecommendations(ctx context.Context, weights storage.RecommendationWeights, since time.Time, perUser int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin refresh recommendations: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `DELETE FROM scene_recommendations`); err != nil {
+		return 0, fmt.Errorf("clear recommendations: %w", err)
+	}
+
+	query := `
+		WITH listened AS (
+			SELECT user_id, scene_id,
+				(SUM(EXTRACT(EPOCH FROM ` + sessionEnd + ` - joined_at)) / 3600)::float8 AS hours
+			FROM scene_listen_sessions
+			WHERE joined_at >= $1
+			GROUP BY user_id, scene_id
+		), candidates AS (
+			SELECT s.id, s.creator_id::text AS creator_id FROM scenes s WHERE ` + publicScene + `
+		), tag_parts AS (
+			SELECT ut.user_id, h.scene_id, COUNT(*) AS shared
+			FROM (
+				SELECT DISTINCT l.user_id, h.tag
+				FROM listened l JOIN scene_hashtags h ON h.scene_id = l.scene_id
+			) ut
+			JOIN scene_hashtags h ON h.tag = ut.tag
+			JOIN candidates c ON c.id = h.scene_id
+			GROUP BY ut.user_id, h.scene_id
+		), history_parts AS (
+			SELECT a.user_id, c.id AS scene_id, LEAST(a.hours, $5::float8) AS hours
+			FROM (
+				SELECT l.user_id, s.creator_id::text AS creator_id, SUM(l.hours) AS hours
+				FROM listened l JOIN scenes s ON s.id = l.scene_id
+				GROUP BY l.user_id, s.creator_id
+			) a
+			JOIN candidates c ON c.creator_id = a.creator_id
+		), parts AS (
+			SELECT user_id, scene_id, shared, FALSE AS follows, 0::float8 AS hours FROM tag_parts
+			UNION ALL
+			SELECT f.follower_id, c.id, 0, TRUE, 0
+			FROM user_follows f JOIN candidates c ON c.creator_id = f.followee_id
+			UNION ALL
+			SELECT user_id, scene_id, 0, FALSE, hours FROM history_parts
+		), scored AS (
+			SELECT user_id, scene_id,
+				SUM(shared)::int AS shared_tags, BOOL_OR(follows) AS follows_creator, SUM(hours) AS history_hours
+			FROM parts
+			GROUP BY user_id, scene_id
+		), ranked AS (
+			SELECT sc.*,
+				sc.shared_tags * $2::float8 + CASE WHEN sc.follows_creator THEN $3::float8 ELSE 0 END + sc.history_hours * $4::float8 AS score
+			FROM scored sc
+			JOIN candidates c ON c.id = sc.scene_id
+			WHERE c.creator_id <> sc.user_id
+				AND NOT EXISTS (SELECT 1 FROM scene_participants p WHERE p.scene_id = sc.scene_id AND p.user_id = sc.user_id)
+		)
+		INSERT INTO scene_recommendations (user_id, scene_id, score, shared_tags, follows_creator, history_hours)
+		SELECT user_id, scene_id, score, shared_tags, follows_creator, history_hours
+		FROM (
+			SELECT ranked.*, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY score DESC, scene_id) AS rank
+			FROM ranked
+			WHERE score > 0
+		) top
+		WHERE rank <= $6
+	`
+	result, err := tx.Exec(ctx, query, since, weights.SharedTag, weights.FollowedCreator, weights.HistoryHour, weights.MaxHistoryHours, perUser)
+	if err != nil {
+		return 0, fmt.Errorf("compute recommendations: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit recommendations: %w", err)
+	}
+
+	log.Printf("Refreshed scene recommendations: %d stored", result.RowsAffected())
+	return result.RowsAffected(), nil
+}
+
+// GetRecommendations returns userID's stored recommendations, best first.
+func (s *PostgresRecommendationStore) GetRecommendations(ctx context.Context, userID string, limit int) ([]models.RecommendedScene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + sceneColumns + `, r.score, r.shared_tags, r.follows_creator, r.history_hours, r.computed_at
+		FROM scene_recommendations r
+		JOIN scenes s ON s.id = r.scene_id
+		WHERE r.user_id = $1 AND ` + publicScene + `
+		ORDER BY r.score DESC, r.scene_id
+		LIMIT $2
+	`
+	rows, err := s.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get recommendations for %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	recommended := []models.RecommendedScene{}
+	for rows.Next() {
+		rec := models.RecommendedScene{Scene: &models.Scene{}}
+		err := scanScene(rows, rec.Scene, &rec.Score, &rec.Reasons.SharedTags, &rec.Reasons.FollowsCreator, &rec.Reasons.HistoryHours, &rec.ComputedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan recommendation row: %w", err)
+		}
+		recommended = append(recommended, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate recommendation rows: %w", err)
+	}
+	return recommended, nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresReputationStore implements storage.ReputationStore using PostgreSQL.
+type PostgresReputationStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.ReputationStore = (*PostgresReputationStore)(nil)
+
+// NewPostgresReputationStore creates a new PostgresReputationStore backed by the shared pool db.
+func NewPostgresReputationStore(db *pgxpool.Pool) *PostgresReputationStore {
+	return &PostgresReputationStore{db: db}
+}
+
+// RefreshReputation replaces all reputations in one transaction, so readers
+// see either the previous scores or the new ones.
+func (s *PostgresReputationStore) RefreshReputation(ctx context.Context, weights storage.ReputationWeights, since time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin refresh reputation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `DELETE FROM user_reputation`); err != nil {
+		return 0, fmt.Errorf("clear reputation: %w", err)
+	}
+
+	query := `
+		WITH listened AS (
+			SELECT user_id, (SUM(EXTRACT(EPOCH FROM ` + sessionEnd + ` - joined_at)) / 3600)::float8 AS hours
+			FROM scene_listen_sessions
+			WHERE joined_at >= $1
+			GROUP BY user_id
+		), hosted AS (
+			SELECT creator_id::text AS user_id, COUNT(*) AS scenes
+			FROM scenes
+			WHERE created_at >= $1
+			GROUP BY creator_id
+		), chatted AS (
+			SELECT sender_id AS user_id, COUNT(*) AS messages
+			FROM scene_messages
+			WHERE created_at >= $1
+			GROUP BY sender_id
+		), reported AS (
+			SELECT reported_user_id AS user_id,
+				COUNT(*) FILTER (WHERE status = 'actioned') AS actioned,
+				COUNT(*) FILTER (WHERE status = 'open') AS open
+			FROM reports
+			GROUP BY reported_user_id
+		), restricted AS (
+			SELECT user_id,
+				COUNT(*) FILTER (WHERE kind = 'ban') AS bans,
+				COUNT(*) FILTER (WHERE kind = 'mute') AS mutes
+			FROM scene_bans
+			GROUP BY user_id
+		), signals AS (
+			SELECT u.user_id,
+				COALESCE(l.hours, 0) AS hours,
+				COALESCE(h.scenes, 0) AS scenes,
+				COALESCE(c.messages, 0) AS messages,
+				COALESCE(r.actioned, 0) AS actioned,
+				COALESCE(r.open, 0) AS open,
+				COALESCE(b.bans, 0) AS bans,
+				COALESCE(b.mutes, 0) AS mutes
+			FROM (
+				SELECT user_id FROM listened
+				UNION SELECT user_id FROM hosted
+				UNION SELECT user_id FROM chatted
+				UNION SELECT user_id FROM reported
+				UNION SELECT user_id FROM restricted
+			) u
+			LEFT JOIN listened l ON l.user_id = u.user_id
+			LEFT JOIN hosted h ON h.user_id = u.user_id
+			LEFT JOIN chatted c ON c.user_id = u.user_id
+			LEFT JOIN reported r ON r.user_id = u.user_id
+			LEFT JOIN restricted b ON b.user_id = u.user_id
+		)
+		INSERT INTO user_reputation (user_id, score, listening_hours, scenes_hosted, messages_sent,
+			reports_actioned, reports_open, scene_bans, scene_mutes)
+		SELECT user_id,
+			LEAST(hours, $3::float8) * $2::float8
+				+ LEAST(scenes, $5::float8) * $4::float8
+				+ LEAST(messages, $7::float8) * $6::float8
+				- actioned * $8::float8
+				- open * $9::float8
+				- bans * $10::float8
+				- mutes * $11::float8,
+			hours, scenes, messages, actioned, open, bans, mutes
+		FROM signals
+	`
+	result, err := tx.Exec(ctx, query, since,
+		weights.ListeningHour, weights.MaxListeningHours,
+		weights.SceneHosted, weights.MaxScenesHosted,
+		weights.Message, weights.MaxMessages,
+		weights.ActionedReport, weights.OpenReport, weights.SceneBan, weights.SceneMute)
+	if err != nil {
+		return 0, fmt.Errorf("compute reputation: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit reputation: %w", err)
+	}
+
+	log.Printf("Refreshed user reputation: %d users scored", result.RowsAffected())
+	return result.RowsAffected(), nil
+}
+
+// GetReputations returns the stored reputation of each user, filling in a
+// zero reputation for users without a row.
+func (s *PostgresReputationStore) GetReputations(ctx context.Context, userIDs []string) (map[string]models.Reputation, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	reputations := make(map[string]models.Reputation, len(userIDs))
+	for _, id := range userIDs {
+		reputations[id] = models.Reputation{UserID: id}
+	}
+	if len(userIDs) == 0 {
+		return reputations, nil
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT user_id, score, listening_hours, scenes_hosted, messages_sent,
+			reports_actioned, reports_open, scene_bans, scene_mutes, computed_at
+		FROM user_reputation
+		WHERE user_id = ANY($1)`,
+		userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get reputation of %d users: %w", len(userIDs), err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rep models.Reputation
+		sig := &rep.Signals
+		if err := rows.Scan(&rep.UserID, &rep.Score, &sig.ListeningHours, &sig.ScenesHosted, &sig.MessagesSent,
+			&sig.ReportsActioned, &sig.ReportsOpen, &sig.SceneBans, &sig.SceneMutes, &rep.ComputedAt); err != nil {
+			return nil, fmt.Errorf("scan reputation row: %w", err)
+		}
+		reputations[rep.UserID] = rep
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get reputation of %d users: %w", len(userIDs), err)
+	}
+	return reputations, nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRetentionStore implements storage.RetentionStore using PostgreSQL.
+type PostgresRetentionStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.RetentionStore = (*PostgresRetentionStore)(nil)
+
+// NewPostgresRetentionStore creates a new PostgresRetentionStore backed by the shared pool db.
+func NewPostgresRetentionStore(db *pgxpool.Pool) *PostgresRetentionStore {
+	return &PostgresRetentionStore{db: db}
+}
+
+// DeleteDMMessagesBefore deletes up to limit DM messages sent before cutoff.
+func (s *PostgresRetentionStore) DeleteDMMessagesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		DELETE FROM dm_messages WHERE id IN (
+			SELECT m.id FROM dm_messages m
+			WHERE m.timestamp < $1
+				AND NOT EXISTS (SELECT 1 FROM dm_messages r WHERE r.parent_message_id = m.id AND r.timestamp >= $1)
+			LIMIT $2
+		)`
+	tag, err := s.db.Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete DM messages before %s: %w", cutoff, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteStaleScenes deletes up to limit empty scenes inactive since cutoff.
+func (s *PostgresRetentionStore) DeleteStaleScenes(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		DELETE FROM scenes WHERE id IN (
+			SELECT s.id FROM scenes s
+			WHERE s.status = 'live' AND s.created_at < $1 AND s.active_users = 0
+				AND NOT EXISTS (SELECT 1 FROM scene_participants p WHERE p.scene_id = s.id AND p.user_id <> s.creator_id::text)
+				AND NOT EXISTS (SELECT 1 FROM scene_messages m WHERE m.scene_id = s.id AND m.created_at >= $1)
+				AND NOT EXISTS (SELECT 1 FROM scene_listen_sessions l WHERE l.scene_id = s.id AND COALESCE(l.left_at, NOW()) >= $1)
+			LIMIT $2
+		)`
+	tag, err := s.db.Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete stale scenes before %s: %w", cutoff, err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -1,205 +1,1715 @@
 package postgres
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/Vasu1712/scenyx-backend/internal/models"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// PostgresSceneStore implements the Scene storage interface using PostgreSQL.
+// PostgresSceneStore implements storage.SceneStore using PostgreSQL.
 type PostgresSceneStore struct {
-	db *sql.DB
+	db *pgxpool.Pool
 }
 
-// NewPostgresSceneStore creates a new PostgresSceneStore instance.
-// It takes a PostgreSQL connection string (DSN).
-func NewPostgresSceneStore(dataSourceName string) (*PostgresSceneStore, error) {
-	db, err := sql.Open("postgres", dataSourceName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database connection: %w", err)
-	}
+var _ storage.SceneStore = (*PostgresSceneStore)(nil)
 
-	// Ping the database to verify the connection
-	err = db.Ping()
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
+// NewPostgresSceneStore creates a new PostgresSceneStore backed by the shared pool db.
+func NewPostgresSceneStore(db *pgxpool.Pool) *PostgresSceneStore {
+	return &PostgresSceneStore{db: db}
+}
 
-	// Set connection pool parameters (optional, but good for performance)
-	db.SetMaxOpenConns(25) // Max number of open connections to the database
-	db.SetMaxIdleConns(10) // Max number of idle connections in the pool
-	db.SetConnMaxLifetime(5 * time.Minute) // Max lifetime for a connection
+// CreateScene creates a new scene in the PostgreSQL database.
+// A non-nil scheduledAt creates it as a scheduled scene that goes live at that time.
+// A non-empty workspaceID makes it private to that workspace, of which the creator must be a member.
+func (s *PostgresSceneStore) CreateScene(ctx context.Context, workspaceID, name, artistName, creatorID string, tags []string, scheduledAt *time.Time, ephemeral bool) (*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
-	log.Println("Successfully connected to PostgreSQL database for Scenes.")
+	if tags == nil {
+		tags = []string{}
+	}
+	status := models.SceneLive
+	if scheduledAt != nil {
+		status = models.SceneScheduled
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create scene: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	return &PostgresSceneStore{db: db}, nil
-}
+	if workspaceID != "" {
+		member, err := areWorkspaceMembers(ctx, tx, workspaceID, []string{creatorID})
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			return nil, storage.ErrForbidden
+		}
+	}
 
-// CreateScene creates a new scene in the PostgreSQL database.
-func (s *PostgresSceneStore) CreateScene(name, artistName, creatorID string) *models.Scene {
 	scene := &models.Scene{}
 	// Insert the new scene into the scenes table
-	// RETURNING * will return all columns of the inserted row
-	query := `INSERT INTO scenes (name, artist_name, creator_id) VALUES ($1, $2, $3) RETURNING id, name, artist_name, creator_id, created_at, updated_at`
-	err := s.db.QueryRow(query, name, artistName, creatorID).Scan(
-		&scene.ID, &scene.Name, &scene.ArtistName, &scene.CreatorID, &scene.CreatedAt, &scene.UpdatedAt,
+	query := `
+		INSERT INTO scenes (name, artist_name, creator_id, tags, status, scheduled_at, ephemeral, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid)
+		RETURNING id, name, artist_name, description, cover_image_url, tags, creator_id, created_at, updated_at, status, scheduled_at, ephemeral, ticket_currency,
+			COALESCE(workspace_id::text, '')`
+	err = tx.QueryRow(ctx, query, name, artistName, creatorID, tags, string(status), scheduledAt, ephemeral, workspaceID).Scan(
+		&scene.ID, &scene.Name, &scene.ArtistName, &scene.Description, &scene.CoverImageURL, &scene.Tags, &scene.CreatorID, &scene.CreatedAt, &scene.UpdatedAt,
+		&scene.Status, &scene.ScheduledAt, &scene.Ephemeral, &scene.TicketCurrency, &scene.WorkspaceID,
 	)
 	if err != nil {
-		log.Printf("Error creating scene in DB: %v", err)
-		return nil
+		return nil, fmt.Errorf("create scene: %w", err)
 	}
 
 	// Also add the creator as the first participant in scene_participants
 	joinQuery := `INSERT INTO scene_participants (scene_id, user_id) VALUES ($1, $2) ON CONFLICT (scene_id, user_id) DO NOTHING`
-	_, err = s.db.Exec(joinQuery, scene.ID, creatorID)
-	if err != nil {
-		log.Printf("Error adding creator %s to scene_participants for scene %s: %v", creatorID, scene.ID, err)
-		// This is a non-fatal error for scene creation, but good to log
-	} else {
-		// Increment listeners count after successful join (for immediate return, though it's usually derived)
-		scene.Listeners = 1
+	if _, err = tx.Exec(ctx, joinQuery, scene.ID, creatorID); err != nil {
+		return nil, fmt.Errorf("add creator %s to scene %s: %w", creatorID, scene.ID, err)
+	}
+	roleQuery := `INSERT INTO scene_roles (scene_id, user_id, role, assigned_by) VALUES ($1, $2, $3, $2)`
+	if _, err = tx.Exec(ctx, roleQuery, scene.ID, creatorID, string(models.RoleHost)); err != nil {
+		return nil, fmt.Errorf("make creator %s host of scene %s: %w", creatorID, scene.ID, err)
+	}
+	scene.Listeners = 1
+
+	if err = writeOutbox(ctx, tx, models.AggregateScene, scene.ID, models.EventSceneCreated, scene); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit scene %s: %w", scene.ID, err)
 	}
 
 	log.Printf("Scene created in DB: ID=%s, Name=%s, CreatorID=%s", scene.ID, scene.Name, scene.CreatorID)
-	return scene
+	return scene, nil
+}
+
+// sceneEndsAt is the SQL expression for when a scene with a maximum duration
+// ends, NULL for scenes without one; the scenes table must be aliased as s.
+const sceneEndsAt = `COALESCE(s.scheduled_at, s.created_at) + s.max_duration_seconds * INTERVAL '1 second'`
+
+// sceneColumns is the select list read by scanScene; the scenes table must be aliased as s.
+const sceneColumns = `
+	s.id, s.name, s.artist_name, s.description, s.cover_image_url, s.tags, s.creator_id,
+	(SELECT COUNT(*) FROM scene_participants WHERE scene_id = s.id) AS listeners,
+	s.active_users, s.created_at, s.updated_at, s.archived_at,
+	s.status, s.scheduled_at, (SELECT COUNT(*) FROM scene_rsvps WHERE scene_id = s.id) AS rsvp_count,
+	s.join_approval, s.skip_threshold, s.cover_key, COALESCE(s.slug, ''), s.ephemeral,
+	s.max_duration_seconds, ` + sceneEndsAt + `, s.ticket_price_cents, s.ticket_currency,
+	COALESCE(s.workspace_id::text, '')`
+
+// scanScene scans a row selected with sceneColumns into scene, followed by
+// any extra columns. An uploaded cover takes the place of coverImageURL.
+func scanScene(row interface{ Scan(...any) error }, scene *models.Scene, extra ...any) error {
+	dest := []any{
+		&scene.ID, &scene.Name, &scene.ArtistName, &scene.Description, &scene.CoverImageURL, &scene.Tags, &scene.CreatorID,
+		&scene.Listeners, &scene.ActiveUsers, &scene.CreatedAt, &scene.UpdatedAt, &scene.ArchivedAt,
+		&scene.Status, &scene.ScheduledAt, &scene.RSVPCount,
+		&scene.RequiresApproval, &scene.SkipThreshold, &scene.CoverKey, &scene.Slug, &scene.Ephemeral,
+		&scene.MaxDurationSeconds, &scene.EndsAt, &scene.TicketPriceCents, &scene.TicketCurrency,
+		&scene.WorkspaceID,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	scene.CoverImages = models.NewSceneCoverImages(scene.ID, scene.CoverKey)
+	if scene.CoverImages != nil {
+		scene.CoverImageURL = scene.CoverImages.Large
+	}
+	return nil
 }
 
 // GetScene retrieves a scene by its ID from the PostgreSQL database.
-func (s *PostgresSceneStore) GetScene(sceneID string) *models.Scene {
+// It returns storage.ErrNotFound if no such scene exists.
+func (s *PostgresSceneStore) GetScene(ctx context.Context, sceneID string) (*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	scene := &models.Scene{}
-	query := `
-		SELECT
-			s.id, s.name, s.artist_name, s.creator_id,
-			(SELECT COUNT(*) FROM scene_participants WHERE scene_id = s.id) AS listeners,
-			s.active_users, s.created_at, s.updated_at
-		FROM scenes s
-		WHERE s.id = $1
-	`
-	err := s.db.QueryRow(query, sceneID).Scan(
-		&scene.ID, &scene.Name, &scene.ArtistName, &scene.CreatorID,
-		&scene.Listeners, &scene.ActiveUsers, &scene.CreatedAt, &scene.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil // Scene not found
+	query := `SELECT ` + sceneColumns + ` FROM scenes s WHERE s.id = $1`
+	err := scanScene(s.db.QueryRow(ctx, query, sceneID), scene)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
 	}
 	if err != nil {
-		log.Printf("Error getting scene %s from DB: %v", sceneID, err)
-		return nil
+		return nil, fmt.Errorf("get scene %s: %w", sceneID, err)
 	}
-	return scene
+	return scene, nil
 }
 
-// GetScenesForUser retrieves all scenes created by or joined by a specific user.
-func (s *PostgresSceneStore) GetScenesForUser(userID string) []*models.Scene {
+// GetScenesForUser retrieves all non-archived scenes created by or joined by a specific user,
+// in workspaceID or, when it is empty, outside any workspace.
+func (s *PostgresSceneStore) GetScenesForUser(ctx context.Context, userID, workspaceID string) ([]*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	var scenes []*models.Scene
 
 	// Query for scenes created by the user OR where the user is a participant
 	// Use UNION to combine results and DISTINCT to avoid duplicates if a user created and is also a participant (though unlikely for creator to be in scene_participants explicitly if always joining on creation).
 	query := `
-		SELECT DISTINCT ON (s.id)
-			s.id, s.name, s.artist_name, s.creator_id,
-			(SELECT COUNT(*) FROM scene_participants sp WHERE sp.scene_id = s.id) AS listeners,
-			s.active_users, s.created_at, s.updated_at
+		SELECT DISTINCT ON (s.id) ` + sceneColumns + `
 		FROM scenes s
 		LEFT JOIN scene_participants sp_join ON s.id = sp_join.scene_id
-		WHERE s.creator_id = $1 OR sp_join.user_id = $1
+		WHERE (s.creator_id = $1 OR sp_join.user_id = $1) AND s.archived_at IS NULL
+			AND ` + inWorkspace("s.workspace_id", "$2") + `
 		ORDER BY s.id, s.created_at DESC -- ORDER BY s.id is necessary for DISTINCT ON
 	`
 
-	rows, err := s.db.Query(query, userID)
+	rows, err := s.db.Query(ctx, query, userID, workspaceID)
 	if err != nil {
-		log.Printf("Error getting scenes for user %s from DB: %v", userID, err)
-		return nil
+		return nil, fmt.Errorf("get scenes for user %s: %w", userID, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		scene := &models.Scene{}
-		err := rows.Scan(
-			&scene.ID, &scene.Name, &scene.ArtistName, &scene.CreatorID,
-			&scene.Listeners, &scene.ActiveUsers, &scene.CreatedAt, &scene.UpdatedAt,
-		)
+		err := scanScene(rows, scene)
 		if err != nil {
-			log.Printf("Error scanning scene row for user %s: %v", userID, err)
-			continue
+			return nil, fmt.Errorf("scan scene row for user %s: %w", userID, err)
+		}
+		scenes = append(scenes, scene)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scene rows for user %s: %w", userID, err)
+	}
+
+	return scenes, nil
+}
+
+// SearchScenes finds non-archived scenes whose name, artist name, or tags
+// match every word of query, treating the last word as a prefix so results
+// update as the user types. Name matches rank above artist matches, which
+// rank above tag matches. Only the scenes of workspaceID are searched, or the
+// public scenes when it is empty.
+func (s *PostgresSceneStore) SearchScenes(ctx context.Context, workspaceID, query string, limit, offset int) ([]*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tsQuery := prefixTSQuery(query)
+	if tsQuery == "" {
+		return nil, nil
+	}
+
+	var scenes []*models.Scene
+	sqlQuery := `
+		SELECT ` + sceneColumns + `
+		FROM scenes s, to_tsquery('simple', $1) AS q
+		WHERE s.search_vector @@ q AND s.archived_at IS NULL AND ` + inWorkspace("s.workspace_id", "$4") + `
+		ORDER BY ts_rank(s.search_vector, q) DESC, s.created_at DESC, s.id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(ctx, sqlQuery, tsQuery, limit, offset, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("search scenes for %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		scene := &models.Scene{}
+		if err := scanScene(rows, scene); err != nil {
+			return nil, fmt.Errorf("scan scene search row: %w", err)
 		}
 		scenes = append(scenes, scene)
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating scene rows for user %s: %v", userID, err)
+		return nil, fmt.Errorf("iterate scene search rows: %w", err)
+	}
+	return scenes, nil
+}
+
+// GetSceneBySlug retrieves the scene that claimed slug.
+// It returns storage.ErrNotFound if no scene has it.
+func (s *PostgresSceneStore) GetSceneBySlug(ctx context.Context, slug string) (*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	scene := &models.Scene{}
+	query := `SELECT ` + sceneColumns + ` FROM scenes s WHERE s.slug = $1`
+	err := scanScene(s.db.QueryRow(ctx, query, slug), scene)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get scene by slug %s: %w", slug, err)
+	}
+	return scene, nil
+}
+
+// SetSceneSlug claims slug for a scene, replacing its previous slug; an empty
+// slug releases it. It returns storage.ErrConflict if another scene has the
+// slug and storage.ErrNotFound if no such scene exists.
+func (s *PostgresSceneStore) SetSceneSlug(ctx context.Context, sceneID, slug string) (*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	scene := &models.Scene{}
+	query := `
+		UPDATE scenes s SET slug = NULLIF($2, ''), updated_at = NOW() WHERE s.id = $1
+		RETURNING ` + sceneColumns
+	err := scanScene(s.db.QueryRow(ctx, query, sceneID, slug), scene)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, storage.ErrConflict
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set slug for scene %s: %w", sceneID, err)
+	}
+	return scene, nil
+}
+
+// UpdateScene changes the non-nil fields of update and returns the updated scene.
+// Setting CoverImageURL detaches any uploaded cover.
+// It returns storage.ErrNotFound if no such scene exists.
+func (s *PostgresSceneStore) UpdateScene(ctx context.Context, sceneID string, update storage.SceneUpdate) (*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE scenes SET
+			name = COALESCE($2, name),
+			artist_name = COALESCE($3, artist_name),
+			description = COALESCE($4, description),
+			cover_image_url = COALESCE($5, cover_image_url),
+			cover_key = CASE WHEN $5::text IS NULL THEN cover_key ELSE '' END,
+			tags = COALESCE($6, tags),
+			join_approval = COALESCE($7, join_approval),
+			skip_threshold = COALESCE($8, skip_threshold),
+			max_duration_seconds = CASE WHEN $9::int IS NULL THEN max_duration_seconds ELSE NULLIF($9, 0) END,
+			end_warning_seconds = CASE WHEN $9::int IS NULL THEN end_warning_seconds END,
+			ticket_price_cents = COALESCE($10, ticket_price_cents),
+			ticket_currency = COALESCE($11, ticket_currency),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	result, err := s.db.Exec(ctx, query, sceneID, update.Name, update.ArtistName, update.Description, update.CoverImageURL, update.Tags, update.RequiresApproval, update.SkipThreshold, update.MaxDurationSeconds,
+		update.TicketPriceCents, update.TicketCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("update scene %s: %w", sceneID, err)
+	}
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, storage.ErrNotFound
+	}
+	return s.GetScene(ctx, sceneID)
+}
+
+// SetSceneCover stores the key of a scene's newly uploaded cover. It returns
+// the updated scene and the key of the cover it replaced, empty if there was none.
+func (s *PostgresSceneStore) SetSceneCover(ctx context.Context, sceneID, key string) (*models.Scene, string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	scene := &models.Scene{}
+	var oldKey string
+	query := `
+		WITH old AS (SELECT cover_key FROM scenes WHERE id = $1 FOR UPDATE)
+		UPDATE scenes s SET cover_key = $2, cover_image_url = '', updated_at = NOW() WHERE s.id = $1
+		RETURNING ` + sceneColumns + `, (SELECT cover_key FROM old)`
+	err := scanScene(s.db.QueryRow(ctx, query, sceneID, key), scene, &oldKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("set cover for scene %s: %w", sceneID, err)
+	}
+	return scene, oldKey, nil
+}
+
+// DeleteScene permanently removes a scene along with its participants,
+// messages, and queue once creatorID is confirmed as its creator. Playback,
+// restrictions, and the rest are removed by ON DELETE CASCADE; the files of
+// message attachments and transcript exports are queued for the orphan-blobs
+// job. The scene row is locked first so a concurrent join cannot slip in
+// between the deletes.
+func (s *PostgresSceneStore) DeleteScene(ctx context.Context, sceneID, creatorID string) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("begin delete of scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var owner, coverKey string
+	err = tx.QueryRow(ctx, `SELECT creator_id, cover_key FROM scenes WHERE id = $1 FOR UPDATE`, sceneID).Scan(&owner, &coverKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", storage.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("lock scene %s for delete: %w", sceneID, err)
+	}
+	if owner != creatorID {
+		return "", storage.ErrForbidden
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM scene_participants WHERE scene_id = $1`, sceneID); err != nil {
+		return "", fmt.Errorf("delete participants of scene %s: %w", sceneID, err)
+	}
+	if err = deleteSceneFiles(ctx, tx, sceneID); err != nil {
+		return "", err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM scene_messages WHERE scene_id = $1`, sceneID); err != nil {
+		return "", fmt.Errorf("delete messages of scene %s: %w", sceneID, err)
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM scene_queue WHERE scene_id = $1`, sceneID); err != nil {
+		return "", fmt.Errorf("delete queue of scene %s: %w", sceneID, err)
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM scenes WHERE id = $1`, sceneID); err != nil {
+		return "", fmt.Errorf("delete scene %s: %w", sceneID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("commit delete of scene %s: %w", sceneID, err)
+	}
+
+	log.Printf("Scene %s deleted by creator %s.", sceneID, creatorID)
+	return coverKey, nil
+}
+
+// Reschedule moves a scheduled scene's start time and re-arms its reminder.
+// It returns storage.ErrNotFound if the scene does not exist and
+// storage.ErrConflict if it is already live.
+func (s *PostgresSceneStore) Reschedule(ctx context.Context, sceneID string, at time.Time) (*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		UPDATE scenes SET scheduled_at = $2, reminder_sent_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'scheduled'`,
+		sceneID, at,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("reschedule scene %s: %w", sceneID, err)
+	}
+	if result.RowsAffected() == 0 {
+		if _, err := s.GetScene(ctx, sceneID); err != nil {
+			return nil, err
+		}
+		return nil, storage.ErrConflict
+	}
+	return s.GetScene(ctx, sceneID)
+}
+
+// AddRSVP records that userID plans to attend a scheduled scene. It returns
+// storage.ErrNotFound if the scene does not exist, storage.ErrForbidden if it
+// is not scheduled, and storage.ErrConflict if the user already RSVP'd.
+func (s *PostgresSceneStore) AddRSVP(ctx context.Context, sceneID, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		INSERT INTO scene_rsvps (scene_id, user_id)
+		SELECT id, $2 FROM scenes WHERE id = $1 AND status = 'scheduled'
+		ON CONFLICT DO NOTHING`,
+		sceneID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("add RSVP of %s to scene %s: %w", userID, sceneID, err)
+	}
+	if result.RowsAffected() > 0 {
 		return nil
 	}
 
-	return scenes
+	scene, err := s.GetScene(ctx, sceneID)
+	if err != nil {
+		return err
+	}
+	if scene.Status != models.SceneScheduled {
+		return storage.ErrForbidden
+	}
+	return storage.ErrConflict
 }
 
-// JoinScene adds a user to a scene's participants in the database.
-func (s *PostgresSceneStore) JoinScene(sceneID, userID string) bool {
-	// Check if the scene exists
-	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM scenes WHERE id = $1)", sceneID).Scan(&exists)
-	if err != nil || !exists {
-		log.Printf("Scene %s not found for join operation: %v", sceneID, err)
-		return false
+// RemoveRSVP withdraws userID's RSVP. It returns storage.ErrNotFound if there was none.
+func (s *PostgresSceneStore) RemoveRSVP(ctx context.Context, sceneID, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `DELETE FROM scene_rsvps WHERE scene_id = $1 AND user_id = $2`, sceneID, userID)
+	if err != nil {
+		return fmt.Errorf("remove RSVP of %s from scene %s: %w", userID, sceneID, err)
 	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
 
-	// Attempt to insert into scene_participants. ON CONFLICT DO NOTHING handles if user is already joined.
-	query := `INSERT INTO scene_participants (scene_id, user_id) VALUES ($1, $2) ON CONFLICT (scene_id, user_id) DO NOTHING RETURNING scene_id`
-	var insertedSceneID string
-	err = s.db.QueryRow(query, sceneID, userID).Scan(&insertedSceneID)
+// GetRSVPs lists the IDs of the users who RSVP'd to a scene, earliest first.
+func (s *PostgresSceneStore) GetRSVPs(ctx context.Context, sceneID string) ([]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var userIDs []string
+	rows, err := s.db.Query(ctx, `SELECT user_id FROM scene_rsvps WHERE scene_id = $1 ORDER BY created_at, user_id`, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get RSVPs for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan RSVP row for scene %s: %w", sceneID, err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate RSVP rows for scene %s: %w", sceneID, err)
+	}
+	return userIDs, nil
+}
+
+// ClaimReminders marks scheduled scenes starting within lead as reminded and
+// returns them. Each scene is returned by exactly one call, so concurrent
+// instances never send the same reminder twice.
+func (s *PostgresSceneStore) ClaimReminders(ctx context.Context, lead time.Duration) ([]*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE scenes s SET reminder_sent_at = NOW()
+		WHERE s.status = 'scheduled' AND s.reminder_sent_at IS NULL AND s.archived_at IS NULL
+			AND s.scheduled_at <= NOW() + make_interval(secs => $1)
+		RETURNING ` + sceneColumns
+	return s.queryScenes(ctx, "claim scene reminders", query, lead.Seconds())
+}
+
+// StartDueScenes switches scheduled scenes whose start time has passed to
+// live and returns them. Each scene is returned by exactly one call.
+func (s *PostgresSceneStore) StartDueScenes(ctx context.Context) ([]*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE scenes s SET status = 'live', updated_at = NOW()
+		WHERE s.status = 'scheduled' AND s.scheduled_at <= NOW()
+		RETURNING ` + sceneColumns
+	return s.queryScenes(ctx, "start due scenes", query)
+}
+
+// GetScenesEndingBy returns the live, unarchived scenes whose maximum
+// duration runs out by by, soonest first.
+func (s *PostgresSceneStore) GetScenesEndingBy(ctx context.Context, by time.Time) ([]*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + sceneColumns + `
+		FROM scenes s
+		WHERE s.max_duration_seconds IS NOT NULL AND s.archived_at IS NULL AND s.status = 'live'
+		  AND ` + sceneEndsAt + ` <= $1
+		ORDER BY ` + sceneEndsAt
+	return s.queryScenes(ctx, "get scenes ending by", query, by)
+}
 
-	// If no row was returned (Scan error), it means ON CONFLICT DO NOTHING was triggered (user already joined)
-	if err == sql.ErrNoRows {
-		log.Printf("User %s already joined scene %s.", userID, sceneID)
-		return false
+// ClaimEndWarning records lead as the countdown warning last sent for a
+// scene, unless it or a shorter one already was.
+func (s *PostgresSceneStore) ClaimEndWarning(ctx context.Context, sceneID string, lead time.Duration) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `
+		UPDATE scenes SET end_warning_seconds = $2
+		WHERE id = $1 AND (end_warning_seconds IS NULL OR end_warning_seconds > $2)`,
+		sceneID, int(lead.Seconds()))
+	if err != nil {
+		return false, fmt.Errorf("claim %s end warning for scene %s: %w", lead, sceneID, err)
 	}
+	return result.RowsAffected() > 0, nil
+}
+
+// queryScenes runs a query selecting sceneColumns and scans every row.
+func (s *PostgresSceneStore) queryScenes(ctx context.Context, op, query string, args ...any) ([]*models.Scene, error) {
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
-		log.Printf("Error joining user %s to scene %s in DB: %v", userID, sceneID, err)
-		return false
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer rows.Close()
 
-	log.Printf("User %s successfully joined scene %s.", userID, sceneID)
-	return true
+	var scenes []*models.Scene
+	for rows.Next() {
+		scene := &models.Scene{}
+		if err := scanScene(rows, scene); err != nil {
+			return nil, fmt.Errorf("%s: scan scene row: %w", op, err)
+		}
+		scenes = append(scenes, scene)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate scene rows: %w", op, err)
+	}
+	return scenes, nil
 }
 
-// LeaveScene removes a user from a scene's participants in the database.
-func (s *PostgresSceneStore) LeaveScene(sceneID, userID string) bool {
-	// Check if the scene exists
-	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM scenes WHERE id = $1)", sceneID).Scan(&exists)
-	if err != nil || !exists {
-		log.Printf("Scene %s not found for leave operation: %v", sceneID, err)
-		return false
+// SetArchived archives or restores a scene. Archiving an ephemeral scene
+// deletes its history in the same transaction (see deleteSceneHistory).
+func (s *PostgresSceneStore) SetArchived(ctx context.Context, sceneID string, archived bool) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin archive of scene %s: %w", sceneID, err)
 	}
+	defer tx.Rollback(ctx)
 
-	// Delete the participant entry
-	result, err := s.db.Exec("DELETE FROM scene_participants WHERE scene_id = $1 AND user_id = $2", sceneID, userID)
+	query := `UPDATE scenes SET archived_at = NULL, updated_at = NOW() WHERE id = $1 RETURNING ephemeral`
+	if archived {
+		query = `UPDATE scenes SET archived_at = COALESCE(archived_at, NOW()), updated_at = NOW() WHERE id = $1 RETURNING ephemeral`
+	}
+
+	var ephemeral bool
+	err = tx.QueryRow(ctx, query, sceneID).Scan(&ephemeral)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("set archived=%t on scene %s: %w", archived, sceneID, err)
+	}
+	if archived && ephemeral {
+		if err = deleteSceneHistory(ctx, tx, sceneID); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit archive of scene %s: %w", sceneID, err)
+	}
+	return nil
+}
+
+// deleteSceneFiles deletes the attachments of a scene's messages and its
+// transcript exports, queueing their files for the orphan-blobs job.
+func deleteSceneFiles(ctx context.Context, tx pgx.Tx, sceneID string) error {
+	_, err := tx.Exec(ctx, `
+		WITH gone AS (
+			DELETE FROM attachments a USING scene_messages m
+			WHERE a.scene_message_id = m.id AND m.scene_id = $1
+			RETURNING a.object_key
+		)
+		INSERT INTO orphan_blobs (bucket, object_key) SELECT $2, object_key FROM gone`,
+		sceneID, models.BucketAttachments)
+	if err != nil {
+		return fmt.Errorf("delete attachments of scene %s: %w", sceneID, err)
+	}
+	_, err = tx.Exec(ctx, `
+		WITH gone AS (
+			DELETE FROM scene_transcript_exports WHERE scene_id = $1
+			RETURNING blob_key
+		)
+		INSERT INTO orphan_blobs (bucket, object_key) SELECT $2, blob_key FROM gone WHERE blob_key IS NOT NULL`,
+		sceneID, models.BucketTranscripts)
+	if err != nil {
+		return fmt.Errorf("delete transcript exports of scene %s: %w", sceneID, err)
+	}
+	return nil
+}
+
+// deleteSceneHistory deletes a scene's chat, queue, and play history and its
+// transcript exports when an ephemeral scene ends. Pins, reactions, message
+// hashtags, and track votes go with the rows they belong to; the files of the
+// message attachments and exports are queued for the orphan-blobs job.
+func deleteSceneHistory(ctx context.Context, tx pgx.Tx, sceneID string) error {
+	if err := deleteSceneFiles(ctx, tx, sceneID); err != nil {
+		return err
+	}
+	for _, table := range []string{"scene_messages", "scene_queue", "scene_play_history"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE scene_id = $1`, sceneID); err != nil {
+			return fmt.Errorf("delete %s of ephemeral scene %s: %w", table, sceneID, err)
+		}
+	}
+	return nil
+}
+
+// CloseScene archives a scene and releases its slug, so share links to it
+// stop resolving, and returns the closed scene. An ephemeral scene's history
+// is deleted in the same transaction.
+func (s *PostgresSceneStore) CloseScene(ctx context.Context, sceneID string) (*models.Scene, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		log.Printf("Error leaving user %s from scene %s in DB: %v", userID, sceneID, err)
-		return false
+		return nil, fmt.Errorf("begin close of scene %s: %w", sceneID, err)
 	}
+	defer tx.Rollback(ctx)
 
-	rowsAffected, err := result.RowsAffected()
+	scene := &models.Scene{}
+	query := `
+		UPDATE scenes s SET archived_at = NOW(), slug = NULL, updated_at = NOW()
+		WHERE s.id = $1 AND s.archived_at IS NULL
+		RETURNING ` + sceneColumns
+	err = scanScene(tx.QueryRow(ctx, query, sceneID), scene)
+	if errors.Is(err, pgx.ErrNoRows) {
+		exists, err := s.sceneExists(ctx, sceneID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, storage.ErrNotFound
+		}
+		return nil, storage.ErrConflict
+	}
 	if err != nil {
-		log.Printf("Error getting rows affected after leaving scene: %v", err)
-		return false
+		return nil, fmt.Errorf("close scene %s: %w", sceneID, err)
+	}
+	if scene.Ephemeral {
+		if err = deleteSceneHistory(ctx, tx, sceneID); err != nil {
+			return nil, err
+		}
 	}
 
-	if rowsAffected == 0 {
-		log.Printf("User %s was not found in scene %s participants to leave.", userID, sceneID)
-		return false // User was not a participant
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit close of scene %s: %w", sceneID, err)
 	}
+	return scene, nil
+}
 
-	log.Printf("User %s successfully left scene %s.", userID, sceneID)
-	return true
+// sceneExists reports whether a scene with the given ID exists.
+func (s *PostgresSceneStore) sceneExists(ctx context.Context, sceneID string) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := s.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM scenes WHERE id = $1)", sceneID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check scene %s exists: %w", sceneID, err)
+	}
+	return exists, nil
+}
+
+// sceneAccess reports whether a scene exists and whether userID may join it,
+// which outside a workspace everyone may and inside one only its members.
+func (s *PostgresSceneStore) sceneAccess(ctx context.Context, sceneID, userID string) (exists, allowed bool, err error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	err = s.db.QueryRow(ctx, `
+		SELECT s.workspace_id IS NULL OR EXISTS (
+			SELECT 1 FROM workspace_members m WHERE m.workspace_id = s.workspace_id AND m.user_id = $2
+		)
+		FROM scenes s WHERE s.id = $1`,
+		sceneID, userID,
+	).Scan(&allowed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("check access of user %s to scene %s: %w", userID, sceneID, err)
+	}
+	return true, allowed, nil
 }
 
-// Close closes the database connection.
-func (s *PostgresSceneStore) Close() error {
-	return s.db.Close()
+// JoinScene adds a user to a scene's participants in the database.
+func (s *PostgresSceneStore) JoinScene(ctx context.Context, sceneID, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	exists, allowed, err := s.sceneAccess(ctx, sceneID, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrNotFound
+	}
+	if !allowed {
+		return storage.ErrForbidden
+	}
+
+	banned, err := s.HasRestriction(ctx, sceneID, userID, models.RestrictionBan)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return storage.ErrForbidden
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin join scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Attempt to insert into scene_participants. ON CONFLICT DO NOTHING handles if user is already joined.
+	query := `INSERT INTO scene_participants (scene_id, user_id) VALUES ($1, $2) ON CONFLICT (scene_id, user_id) DO NOTHING RETURNING scene_id`
+	var insertedSceneID string
+	err = tx.QueryRow(ctx, query, sceneID, userID).Scan(&insertedSceneID)
+
+	// If no row was returned, ON CONFLICT DO NOTHING was triggered (user already joined)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("join user %s to scene %s: %w", userID, sceneID, err)
+	}
+
+	joined := map[string]string{"sceneID": sceneID, "userID": userID}
+	if err = writeOutbox(ctx, tx, models.AggregateScene, sceneID, models.EventUserJoined, joined); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit join of user %s to scene %s: %w", userID, sceneID, err)
+	}
+
+	log.Printf("User %s successfully joined scene %s.", userID, sceneID)
+	return nil
+}
+
+// RequestJoin records userID's request to join a scene that requires approval.
+func (s *PostgresSceneStore) RequestJoin(ctx context.Context, sceneID, userID string) (*models.SceneJoinRequest, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	exists, allowed, err := s.sceneAccess(ctx, sceneID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, storage.ErrNotFound
+	}
+	if !allowed {
+		return nil, storage.ErrForbidden
+	}
+
+	banned, err := s.HasRestriction(ctx, sceneID, userID, models.RestrictionBan)
+	if err != nil {
+		return nil, err
+	}
+	if banned {
+		return nil, storage.ErrForbidden
+	}
+
+	request := &models.SceneJoinRequest{SceneID: sceneID, UserID: userID}
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO scene_join_requests (scene_id, user_id)
+		SELECT $1, $2
+		WHERE NOT EXISTS (SELECT 1 FROM scene_participants WHERE scene_id = $1 AND user_id = $2)
+		ON CONFLICT DO NOTHING
+		RETURNING created_at`,
+		sceneID, userID,
+	).Scan(&request.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("request join of user %s to scene %s: %w", userID, sceneID, err)
+	}
+	return request, nil
+}
+
+// GetJoinRequests lists the pending join requests of a scene, oldest first.
+func (s *PostgresSceneStore) GetJoinRequests(ctx context.Context, sceneID string) ([]models.SceneJoinRequest, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var requests []models.SceneJoinRequest
+	rows, err := s.db.Query(ctx, `
+		SELECT scene_id, user_id, created_at FROM scene_join_requests
+		WHERE scene_id = $1 ORDER BY created_at, user_id`, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get join requests for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var request models.SceneJoinRequest
+		if err := rows.Scan(&request.SceneID, &request.UserID, &request.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan join request row for scene %s: %w", sceneID, err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate join request rows for scene %s: %w", sceneID, err)
+	}
+	return requests, nil
+}
+
+// ResolveJoinRequest deletes userID's pending request and, if approve is
+// set, adds them to the participants in the same transaction.
+func (s *PostgresSceneStore) ResolveJoinRequest(ctx context.Context, sceneID, userID string, approve bool) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin resolving join request of user %s in scene %s: %w", userID, sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `DELETE FROM scene_join_requests WHERE scene_id = $1 AND user_id = $2`, sceneID, userID)
+	if err != nil {
+		return fmt.Errorf("delete join request of user %s in scene %s: %w", userID, sceneID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+
+	if approve {
+		_, err = tx.Exec(ctx, `INSERT INTO scene_participants (scene_id, user_id) VALUES ($1, $2) ON CONFLICT (scene_id, user_id) DO NOTHING`, sceneID, userID)
+		if err != nil {
+			return fmt.Errorf("join user %s to scene %s: %w", userID, sceneID, err)
+		}
+		joined := map[string]string{"sceneID": sceneID, "userID": userID}
+		if err = writeOutbox(ctx, tx, models.AggregateScene, sceneID, models.EventUserJoined, joined); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit join request of user %s in scene %s: %w", userID, sceneID, err)
+	}
+
+	log.Printf("Join request of user %s to scene %s resolved (approved=%t).", userID, sceneID, approve)
+	return nil
+}
+
+// LeaveScene removes a user from a scene's participants in the database.
+func (s *PostgresSceneStore) LeaveScene(ctx context.Context, sceneID, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	exists, err := s.sceneExists(ctx, sceneID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrNotFound
+	}
+
+	// Delete the participant entry
+	result, err := s.db.Exec(ctx, "DELETE FROM scene_participants WHERE scene_id = $1 AND user_id = $2", sceneID, userID)
+	if err != nil {
+		return fmt.Errorf("remove user %s from scene %s: %w", userID, sceneID, err)
+	}
+
+	rowsAffected := result.RowsAffected()
+
+	if rowsAffected == 0 {
+		return storage.ErrConflict // User was not a participant
+	}
+
+	_, err = s.db.Exec(ctx, "DELETE FROM scene_roles WHERE scene_id = $1 AND user_id = $2 AND role <> $3", sceneID, userID, string(models.RoleHost))
+	if err != nil {
+		return fmt.Errorf("drop role of user %s leaving scene %s: %w", userID, sceneID, err)
+	}
+
+	log.Printf("User %s successfully left scene %s.", userID, sceneID)
+	return nil
+}
+
+// GetSceneParticipants returns the participants of the given scenes in one query.
+func (s *PostgresSceneStore) GetSceneParticipants(ctx context.Context, sceneIDs []string) (map[string][]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	participants := make(map[string][]string, len(sceneIDs))
+	query := `SELECT scene_id::text, user_id::text FROM scene_participants WHERE scene_id::text = ANY($1) ORDER BY scene_id, user_id`
+	rows, err := s.db.Query(ctx, query, sceneIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get participants of %d scenes: %w", len(sceneIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sceneID, userID string
+		if err := rows.Scan(&sceneID, &userID); err != nil {
+			return nil, fmt.Errorf("scan scene participant row: %w", err)
+		}
+		participants[sceneID] = append(participants[sceneID], userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scene participant rows: %w", err)
+	}
+	return participants, nil
+}
+
+// sceneMessageColumns selects a scene message row for scanSceneMessage.
+const sceneMessageColumns = `id, scene_id, sender_id, content, created_at`
+
+// scanSceneMessage scans a row selected with sceneMessageColumns.
+// Any extra destinations are scanned from columns following sceneMessageColumns.
+func scanSceneMessage(row interface{ Scan(...any) error }, msg *models.SceneMessage, extra ...any) error {
+	dest := []any{&msg.ID, &msg.SceneID, &msg.SenderID, &msg.Content, &msg.CreatedAt}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// AddSceneMessage stores a new chat message for a scene.
+func (s *PostgresSceneStore) AddSceneMessage(ctx context.Context, sceneID, senderID, content string) (*models.SceneMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin add message to scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	msg := &models.SceneMessage{}
+	query := `
+		INSERT INTO scene_messages (scene_id, sender_id, content)
+		VALUES ($1, $2, $3)
+		RETURNING ` + sceneMessageColumns
+	err = scanSceneMessage(tx.QueryRow(ctx, query, sceneID, senderID, content), msg)
+	if err != nil {
+		return nil, fmt.Errorf("add message to scene %s: %w", sceneID, err)
+	}
+
+	if err = writeOutbox(ctx, tx, models.AggregateScene, sceneID, models.EventMessageSent, msg); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit message to scene %s: %w", sceneID, err)
+	}
+
+	log.Printf("Added message %s to scene %s from sender %s", msg.ID, sceneID, senderID)
+	return msg, nil
+}
+
+// DeleteSceneMessage permanently removes a scene message along with its
+// reactions, pin, attachments, and mentions, and returns the removed message.
+func (s *PostgresSceneStore) DeleteSceneMessage(ctx context.Context, messageID string) (*models.SceneMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	msg := &models.SceneMessage{}
+	query := `DELETE FROM scene_messages WHERE id::text = $1 RETURNING ` + sceneMessageColumns
+	err := scanSceneMessage(s.db.QueryRow(ctx, query, messageID), msg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("delete scene message %s: %w", messageID, err)
+	}
+	log.Printf("Deleted message %s from scene %s", msg.ID, msg.SceneID)
+	return msg, nil
+}
+
+// GetSceneMessages retrieves all chat messages for a scene, oldest first.
+func (s *PostgresSceneStore) GetSceneMessages(ctx context.Context, sceneID string) ([]models.SceneMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var msgs []models.SceneMessage
+	query := `
+		SELECT ` + sceneMessageColumns + `
+		FROM scene_messages
+		WHERE scene_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := s.db.Query(ctx, query, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get messages for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		msg := models.SceneMessage{}
+		if err := scanSceneMessage(rows, &msg); err != nil {
+			return nil, fmt.Errorf("scan scene message row for scene %s: %w", sceneID, err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scene message rows for scene %s: %w", sceneID, err)
+	}
+
+	ptrs := make([]*models.SceneMessage, len(msgs))
+	for i := range msgs {
+		ptrs[i] = &msgs[i]
+	}
+	if err = s.attachDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// SearchSceneMessages finds messages in a scene's chat matching search,
+// newest first, each with up to storage.SearchContextSize messages on either side.
+func (s *PostgresSceneStore) SearchSceneMessages(ctx context.Context, sceneID string, search storage.MessageSearch) ([]models.SceneSearchResult, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tsQuery := prefixTSQuery(search.Query)
+	if tsQuery == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT ` + sceneMessageColumns + `
+		FROM scene_messages
+		WHERE scene_id = $1 AND search_vector @@ to_tsquery('simple', $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := s.db.Query(ctx, query, sceneID, tsQuery, search.NormalizedLimit())
+	if err != nil {
+		return nil, fmt.Errorf("search messages of scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	var results []models.SceneSearchResult
+	var ids []string
+	for rows.Next() {
+		res := models.SceneSearchResult{SceneID: sceneID}
+		if err := scanSceneMessage(rows, &res.Message); err != nil {
+			return nil, fmt.Errorf("scan scene search row for scene %s: %w", sceneID, err)
+		}
+		results = append(results, res)
+		ids = append(ids, res.Message.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scene search rows for scene %s: %w", sceneID, err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	// Load the neighbours of every match in one round trip
+	contextQuery := `
+		SELECT c.*, m.id::text
+		FROM scene_messages m
+		CROSS JOIN LATERAL (
+			(SELECT ` + sceneMessageColumns + ` FROM scene_messages
+			 WHERE scene_id = m.scene_id AND (created_at, id) < (m.created_at, m.id)
+			 ORDER BY created_at DESC, id DESC LIMIT $2)
+			UNION ALL
+			(SELECT ` + sceneMessageColumns + ` FROM scene_messages
+			 WHERE scene_id = m.scene_id AND (created_at, id) > (m.created_at, m.id)
+			 ORDER BY created_at ASC, id ASC LIMIT $2)
+		) AS c
+		WHERE m.id = ANY($1)
+		ORDER BY c.created_at, c.id
+	`
+	rows, err = s.db.Query(ctx, contextQuery, ids, storage.SearchContextSize)
+	if err != nil {
+		return nil, fmt.Errorf("load context of %d scene search results: %w", len(ids), err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(results))
+	for i := range results {
+		index[results[i].Message.ID] = i
+	}
+	for rows.Next() {
+		var msg models.SceneMessage
+		var matchID string
+		if err := scanSceneMessage(rows, &msg, &matchID); err != nil {
+			return nil, fmt.Errorf("scan scene search context row: %w", err)
+		}
+		res := &results[index[matchID]]
+		if msg.CreatedAt.Before(res.Message.CreatedAt) || (msg.CreatedAt.Equal(res.Message.CreatedAt) && msg.ID < res.Message.ID) {
+			res.Before = append(res.Before, msg)
+		} else {
+			res.After = append(res.After, msg)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scene search context rows: %w", err)
+	}
+
+	var ptrs []*models.SceneMessage
+	for i := range results {
+		res := &results[i]
+		if res.Before == nil {
+			res.Before = []models.SceneMessage{}
+		}
+		if res.After == nil {
+			res.After = []models.SceneMessage{}
+		}
+		ptrs = append(ptrs, &res.Message)
+		for j := range res.Before {
+			ptrs = append(ptrs, &res.Before[j])
+		}
+		for j := range res.After {
+			ptrs = append(ptrs, &res.After[j])
+		}
+	}
+	if err = s.attachDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// AddSceneReaction records userID's emoji reaction on a scene message.
+func (s *PostgresSceneStore) AddSceneReaction(ctx context.Context, messageID, userID, emoji string) (*models.SceneMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if err := addReaction(ctx, s.db, sceneReactions, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+	return s.getSceneMessage(ctx, messageID)
+}
+
+// RemoveSceneReaction deletes userID's emoji reaction on a scene message.
+func (s *PostgresSceneStore) RemoveSceneReaction(ctx context.Context, messageID, userID, emoji string) (*models.SceneMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if err := removeReaction(ctx, s.db, sceneReactions, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+	return s.getSceneMessage(ctx, messageID)
+}
+
+// PinMessage pins a scene message. The scene row is locked so concurrent
+// pins cannot push the scene past models.MaxPinnedMessages.
+func (s *PostgresSceneStore) PinMessage(ctx context.Context, sceneID, messageID, pinnedBy string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin pin in scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var pins int
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM scene_pins WHERE scene_id = s.id)
+		FROM scenes s WHERE s.id = $1 FOR UPDATE`,
+		sceneID,
+	).Scan(&pins)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("count pins in scene %s: %w", sceneID, err)
+	}
+
+	var inScene, pinned bool
+	err = tx.QueryRow(ctx, `
+		SELECT
+			EXISTS(SELECT 1 FROM scene_messages WHERE id = $1 AND scene_id = $2),
+			EXISTS(SELECT 1 FROM scene_pins WHERE message_id = $1)`,
+		messageID, sceneID,
+	).Scan(&inScene, &pinned)
+	if err != nil {
+		return fmt.Errorf("check message %s in scene %s: %w", messageID, sceneID, err)
+	}
+	switch {
+	case !inScene:
+		return storage.ErrNotFound
+	case pinned:
+		return storage.ErrConflict
+	case pins >= models.MaxPinnedMessages:
+		return storage.ErrLimitReached
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO scene_pins (message_id, scene_id, pinned_by) VALUES ($1, $2, $3)`,
+		messageID, sceneID, pinnedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("pin message %s in scene %s: %w", messageID, sceneID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit pin in scene %s: %w", sceneID, err)
+	}
+	log.Printf("Message %s pinned in scene %s by %s", messageID, sceneID, pinnedBy)
+	return nil
+}
+
+// UnpinMessage removes a pin from a scene message.
+func (s *PostgresSceneStore) UnpinMessage(ctx context.Context, sceneID, messageID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `DELETE FROM scene_pins WHERE message_id = $1 AND scene_id = $2`, messageID, sceneID)
+	if err != nil {
+		return fmt.Errorf("unpin message %s in scene %s: %w", messageID, sceneID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	log.Printf("Message %s unpinned in scene %s", messageID, sceneID)
+	return nil
+}
+
+// GetPinnedMessages lists a scene's pinned messages, oldest pin first.
+func (s *PostgresSceneStore) GetPinnedMessages(ctx context.Context, sceneID string) ([]models.PinnedMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var pins []models.PinnedMessage
+	query := `
+		SELECT m.id, m.scene_id, m.sender_id, m.content, m.created_at, p.pinned_by, p.pinned_at
+		FROM scene_pins p
+		JOIN scene_messages m ON m.id = p.message_id
+		WHERE p.scene_id = $1
+		ORDER BY p.pinned_at ASC
+	`
+	rows, err := s.db.Query(ctx, query, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get pinned messages for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pin := models.PinnedMessage{}
+		err := rows.Scan(
+			&pin.ID, &pin.SceneID, &pin.SenderID, &pin.Content, &pin.CreatedAt, &pin.PinnedBy, &pin.PinnedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan pinned message row for scene %s: %w", sceneID, err)
+		}
+		pins = append(pins, pin)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate pinned message rows for scene %s: %w", sceneID, err)
+	}
+
+	ptrs := make([]*models.SceneMessage, len(pins))
+	for i := range pins {
+		ptrs[i] = &pins[i].SceneMessage
+	}
+	if err = s.attachDetails(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+// getSceneMessage loads a single scene message with its reaction counts.
+func (s *PostgresSceneStore) getSceneMessage(ctx context.Context, messageID string) (*models.SceneMessage, error) {
+	msg := &models.SceneMessage{}
+	err := scanSceneMessage(s.db.QueryRow(ctx, `SELECT `+sceneMessageColumns+` FROM scene_messages WHERE id = $1`, messageID), msg)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get scene message %s: %w", messageID, err)
+	}
+	if err = s.attachDetails(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// SetSceneMessageMentions resolves the @username spans of a scene message
+// and replaces its stored mentions with those that name a user.
+func (s *PostgresSceneStore) SetSceneMessageMentions(ctx context.Context, messageID string, spans []models.Mention) ([]models.Mention, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return setMentions(ctx, s.db, sceneMentions, messageID, spans)
+}
+
+// attachDetails fills in the reactions, attachments, and mentions of msgs.
+func (s *PostgresSceneStore) attachDetails(ctx context.Context, msgs ...*models.SceneMessage) error {
+	ids := make([]string, len(msgs))
+	for i, msg := range msgs {
+		ids[i] = msg.ID
+	}
+	reactions, err := loadReactions(ctx, s.db, sceneReactions, ids)
+	if err != nil {
+		return err
+	}
+	attachments, err := loadAttachments(ctx, s.db, "scene_message_id", ids)
+	if err != nil {
+		return err
+	}
+	mentions, err := loadMentions(ctx, s.db, "scene_message_id", ids)
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		msg.Reactions = reactions[msg.ID]
+		msg.Attachments = attachments[msg.ID]
+		msg.Mentions = mentions[msg.ID]
+	}
+	return nil
+}
+
+// queueColumns is the column list scanned by scanQueueItem.
+const queueColumns = `id, scene_id, position, title, artist, artwork_url, provider_id, added_by, added_at`
+
+// queueVoteColumns extends queueColumns with an item's vote counts; the
+// scene_queue table must not be aliased.
+const queueVoteColumns = queueColumns + `,
+	(SELECT COUNT(*) FROM scene_queue_votes v WHERE v.item_id = scene_queue.id AND v.vote = 1) AS upvotes,
+	(SELECT COUNT(*) FROM scene_queue_votes v WHERE v.item_id = scene_queue.id AND v.vote = -1) AS downvotes`
+
+// queueItemFields returns the scan destinations for queueColumns.
+func queueItemFields(item *models.QueueItem) []any {
+	return []any{
+		&item.ID, &item.SceneID, &item.Position, &item.Title, &item.Artist,
+		&item.ArtworkURL, &item.ProviderID, &item.AddedBy, &item.AddedAt,
+	}
+}
+
+// scanQueueItem scans a row selected with queueColumns.
+func scanQueueItem(row interface{ Scan(...any) error }, item *models.QueueItem) error {
+	return row.Scan(queueItemFields(item)...)
+}
+
+// scanQueueItemVotes scans a row selected with queueVoteColumns.
+func scanQueueItemVotes(row interface{ Scan(...any) error }, item *models.QueueItem) error {
+	return row.Scan(append(queueItemFields(item), &item.Upvotes, &item.Downvotes)...)
+}
+
+// AddToQueue appends a track to the end of a scene's queue.
+func (s *PostgresSceneStore) AddToQueue(ctx context.Context, item *models.QueueItem) (*models.QueueItem, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	saved := &models.QueueItem{}
+	query := `
+		INSERT INTO scene_queue (scene_id, position, title, artist, artwork_url, provider_id, added_by)
+		VALUES ($1, (SELECT COALESCE(MAX(position) + 1, 0) FROM scene_queue WHERE scene_id = $1), $2, $3, $4, $5, $6)
+		RETURNING ` + queueColumns
+	err := scanQueueItem(s.db.QueryRow(ctx, query,
+		item.SceneID, item.Title, item.Artist, item.ArtworkURL, item.ProviderID, item.AddedBy,
+	), saved)
+	if err != nil {
+		return nil, fmt.Errorf("add track to queue for scene %s: %w", item.SceneID, err)
+	}
+
+	log.Printf("Queued track %s in scene %s at position %d", saved.ID, saved.SceneID, saved.Position)
+	return saved, nil
+}
+
+// RemoveFromQueue deletes a queued track and closes the gap it leaves.
+func (s *PostgresSceneStore) RemoveFromQueue(ctx context.Context, sceneID, itemID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin queue removal for scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var position int
+	err = tx.QueryRow(ctx,
+		`DELETE FROM scene_queue WHERE id = $1 AND scene_id = $2 RETURNING position`,
+		itemID, sceneID,
+	).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("remove queue item %s from scene %s: %w", itemID, sceneID, err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE scene_queue SET position = position - 1 WHERE scene_id = $1 AND position > $2`,
+		sceneID, position,
+	)
+	if err != nil {
+		return fmt.Errorf("compact queue for scene %s: %w", sceneID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit queue removal for scene %s: %w", sceneID, err)
+	}
+	return nil
+}
+
+// ReorderQueue rewrites queue positions to match the order of itemIDs.
+func (s *PostgresSceneStore) ReorderQueue(ctx context.Context, sceneID string, itemIDs []string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	// Duplicate IDs would leave some queued item without a new position
+	seen := make(map[string]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		if seen[id] {
+			return storage.ErrConflict
+		}
+		seen[id] = true
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin queue reorder for scene %s: %w", sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the scene's queue rows so concurrent adds/removes can't interleave
+	var count int
+	err = tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM (SELECT 1 FROM scene_queue WHERE scene_id = $1 FOR UPDATE) q`,
+		sceneID,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("count queue for scene %s: %w", sceneID, err)
+	}
+	if count != len(itemIDs) {
+		return storage.ErrConflict
+	}
+
+	for i, id := range itemIDs {
+		result, err := tx.Exec(ctx,
+			`UPDATE scene_queue SET position = $3 WHERE id = $1 AND scene_id = $2`,
+			id, sceneID, i,
+		)
+		if err != nil {
+			return fmt.Errorf("reorder queue item %s in scene %s: %w", id, sceneID, err)
+		}
+		if result.RowsAffected() == 0 {
+			return storage.ErrConflict
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit queue reorder for scene %s: %w", sceneID, err)
+	}
+	return nil
+}
+
+// GetQueue lists the tracks queued in a scene in play order.
+func (s *PostgresSceneStore) GetQueue(ctx context.Context, sceneID string) ([]models.QueueItem, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var items []models.QueueItem
+	query := `SELECT ` + queueVoteColumns + ` FROM scene_queue WHERE scene_id = $1 ORDER BY position ASC`
+	rows, err := s.db.Query(ctx, query, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get queue for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := models.QueueItem{}
+		if err := scanQueueItemVotes(rows, &item); err != nil {
+			return nil, fmt.Errorf("scan queue row for scene %s: %w", sceneID, err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate queue rows for scene %s: %w", sceneID, err)
+	}
+	return items, nil
+}
+
+// VoteQueueItem sets, changes, or withdraws a user's vote on a queued track.
+func (s *PostgresSceneStore) VoteQueueItem(ctx context.Context, sceneID, itemID, userID string, vote int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := s.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM scene_queue WHERE id = $1 AND scene_id = $2)`,
+		itemID, sceneID,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check queue item %s in scene %s: %w", itemID, sceneID, err)
+	}
+	if !exists {
+		return storage.ErrNotFound
+	}
+
+	if vote == 0 {
+		_, err = s.db.Exec(ctx,
+			`DELETE FROM scene_queue_votes WHERE item_id = $1 AND user_id = $2`,
+			itemID, userID,
+		)
+	} else {
+		_, err = s.db.Exec(ctx, `
+			INSERT INTO scene_queue_votes (item_id, user_id, vote)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (item_id, user_id) DO UPDATE SET vote = EXCLUDED.vote, voted_at = NOW()`,
+			itemID, userID, vote,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("record vote of %s on queue item %s: %w", userID, itemID, err)
+	}
+	return nil
+}
+
+// AddRestriction bans or mutes a user in a scene. Re-applying an existing restriction is a no-op.
+func (s *PostgresSceneStore) AddRestriction(ctx context.Context, sceneID, userID string, kind models.SceneRestriction, createdBy string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin %s for user %s in scene %s: %w", kind, userID, sceneID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO scene_bans (scene_id, user_id, kind, created_by) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`,
+		sceneID, userID, string(kind), createdBy,
+	)
+	if err != nil {
+		return fmt.Errorf("%s user %s in scene %s: %w", kind, userID, sceneID, err)
+	}
+
+	if kind == models.RestrictionBan {
+		_, err = tx.Exec(ctx, `DELETE FROM scene_participants WHERE scene_id = $1 AND user_id = $2`, sceneID, userID)
+		if err != nil {
+			return fmt.Errorf("remove banned user %s from scene %s: %w", userID, sceneID, err)
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM scene_join_requests WHERE scene_id = $1 AND user_id = $2`, sceneID, userID)
+		if err != nil {
+			return fmt.Errorf("drop join request of banned user %s in scene %s: %w", userID, sceneID, err)
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM scene_roles WHERE scene_id = $1 AND user_id = $2 AND role <> $3`, sceneID, userID, string(models.RoleHost))
+		if err != nil {
+			return fmt.Errorf("drop role of banned user %s in scene %s: %w", userID, sceneID, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit %s for user %s in scene %s: %w", kind, userID, sceneID, err)
+	}
+
+	log.Printf("User %s received %s in scene %s from %s.", userID, kind, sceneID, createdBy)
+	return nil
+}
+
+// RemoveRestriction lifts a ban or mute.
+func (s *PostgresSceneStore) RemoveRestriction(ctx context.Context, sceneID, userID string, kind models.SceneRestriction) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx,
+		`DELETE FROM scene_bans WHERE scene_id = $1 AND user_id = $2 AND kind = $3`,
+		sceneID, userID, string(kind),
+	)
+	if err != nil {
+		return fmt.Errorf("lift %s for user %s in scene %s: %w", kind, userID, sceneID, err)
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// HasRestriction reports whether a user is banned or muted in a scene.
+func (s *PostgresSceneStore) HasRestriction(ctx context.Context, sceneID, userID string, kind models.SceneRestriction) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := s.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM scene_bans WHERE scene_id = $1 AND user_id = $2 AND kind = $3)`,
+		sceneID, userID, string(kind),
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check %s for user %s in scene %s: %w", kind, userID, sceneID, err)
+	}
+	return exists, nil
+}
+
+// GetSceneRole looks up userID's role in a scene, defaulting to listener.
+func (s *PostgresSceneStore) GetSceneRole(ctx context.Context, sceneID, userID string) (models.SceneRole, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var role *string
+	err := s.db.QueryRow(ctx, `
+		SELECT r.role FROM scenes s
+		LEFT JOIN scene_roles r ON r.scene_id = s.id AND r.user_id = $2
+		WHERE s.id = $1`,
+		sceneID, userID,
+	).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", storage.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get role of user %s in scene %s: %w", userID, sceneID, err)
+	}
+	if role == nil {
+		return models.RoleListener, nil
+	}
+	return models.SceneRole(*role), nil
+}
+
+// SetSceneRole records a co-host and deletes the row of a user demoted to
+// listener. The host's row is never touched.
+func (s *PostgresSceneStore) SetSceneRole(ctx context.Context, sceneID, userID string, role models.SceneRole, assignedBy string) (*models.SceneRoleAssignment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	current, err := s.GetSceneRole(ctx, sceneID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current == models.RoleHost {
+		return nil, storage.ErrForbidden
+	}
+
+	assignment := &models.SceneRoleAssignment{SceneID: sceneID, UserID: userID, Role: role, AssignedBy: assignedBy, UpdatedAt: time.Now()}
+	if role == models.RoleListener {
+		_, err = s.db.Exec(ctx, `DELETE FROM scene_roles WHERE scene_id = $1 AND user_id = $2 AND role <> $3`, sceneID, userID, string(models.RoleHost))
+		if err != nil {
+			return nil, fmt.Errorf("demote user %s in scene %s: %w", userID, sceneID, err)
+		}
+		return assignment, nil
+	}
+
+	err = s.db.QueryRow(ctx, `
+		INSERT INTO scene_roles (scene_id, user_id, role, assigned_by) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scene_id, user_id) DO UPDATE SET role = EXCLUDED.role, assigned_by = EXCLUDED.assigned_by, updated_at = NOW()
+		WHERE scene_roles.role <> 'host'
+		RETURNING updated_at`,
+		sceneID, userID, string(role), assignedBy,
+	).Scan(&assignment.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrForbidden
+	}
+	if err != nil {
+		return nil, fmt.Errorf("make user %s %s of scene %s: %w", userID, role, sceneID, err)
+	}
+
+	log.Printf("User %s is now %s of scene %s (assigned by %s).", userID, role, sceneID, assignedBy)
+	return assignment, nil
+}
+
+// GetSceneRoles lists a scene's host and co-hosts, host first.
+func (s *PostgresSceneStore) GetSceneRoles(ctx context.Context, sceneID string) ([]models.SceneRoleAssignment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var roles []models.SceneRoleAssignment
+	rows, err := s.db.Query(ctx, `
+		SELECT scene_id, user_id, role, assigned_by, updated_at FROM scene_roles
+		WHERE scene_id = $1 AND role <> 'listener'
+		ORDER BY role = 'host' DESC, updated_at, user_id`, sceneID)
+	if err != nil {
+		return nil, fmt.Errorf("get roles for scene %s: %w", sceneID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role models.SceneRoleAssignment
+		if err := rows.Scan(&role.SceneID, &role.UserID, &role.Role, &role.AssignedBy, &role.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan role row for scene %s: %w", sceneID, err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate role rows for scene %s: %w", sceneID, err)
+	}
+	return roles, nil
 }
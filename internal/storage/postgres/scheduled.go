@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+)
+
+// scheduledColumns selects a scheduled DM message row for scanScheduled.
+const scheduledColumns = `id, dm_conversation_id, sender_id, content, parent_message_id, attachment_ids, flag_reasons, send_at, created_at`
+
+// scanScheduled scans a row selected with scheduledColumns.
+func scanScheduled(row interface{ Scan(...any) error }, m *models.ScheduledDMMessage) error {
+	return row.Scan(&m.ID, &m.DMConversationID, &m.SenderID, &m.Content, &m.ParentMessageID,
+		&m.AttachmentIDs, &m.FlagReasons, &m.SendAt, &m.CreatedAt)
+}
+
+// ScheduleMessage stores m to be sent at m.SendAt. It returns
+// storage.ErrNotFound if the sender is not a participant of the conversation
+// or the parent message is not a live message in it.
+func (s *PostgresDMStore) ScheduleMessage(ctx context.Context, m *models.ScheduledDMMessage) (*models.ScheduledDMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	attachmentIDs, flagReasons := m.AttachmentIDs, m.FlagReasons
+	if attachmentIDs == nil {
+		attachmentIDs = []string{}
+	}
+	if flagReasons == nil {
+		flagReasons = []string{}
+	}
+	query := `
+		INSERT INTO dm_scheduled_messages (dm_conversation_id, sender_id, content, parent_message_id, attachment_ids, flag_reasons, send_at)
+		SELECT p.dm_conversation_id, p.user_id, $3, parent.id, $5, $6, $7
+		FROM dm_participants p
+		LEFT JOIN dm_messages parent ON parent.id::text = $4 AND parent.dm_conversation_id = p.dm_conversation_id
+			AND parent.deleted_at IS NULL
+		WHERE p.dm_conversation_id::text = $1 AND p.user_id = $2 AND ($4 = '' OR parent.id IS NOT NULL)
+		RETURNING ` + scheduledColumns
+	var parentID string
+	if m.ParentMessageID != nil {
+		parentID = *m.ParentMessageID
+	}
+	scheduled := &models.ScheduledDMMessage{}
+	err := scanScheduled(s.db.QueryRow(ctx, query,
+		m.DMConversationID, m.SenderID, m.Content, parentID, attachmentIDs, flagReasons, m.SendAt,
+	), scheduled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("schedule message to DM %s: %w", m.DMConversationID, err)
+	}
+	return scheduled, nil
+}
+
+// GetScheduledMessages returns the messages senderID has scheduled and not
+// yet sent, restricted to dmID when it is set, soonest first.
+func (s *PostgresDMStore) GetScheduledMessages(ctx context.Context, senderID, dmID string) ([]models.ScheduledDMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM dm_scheduled_messages
+		WHERE sender_id = $1 AND ($2 = '' OR dm_conversation_id::text = $2)
+		ORDER BY send_at, id`,
+		senderID, dmID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get scheduled messages of user %s: %w", senderID, err)
+	}
+	defer rows.Close()
+
+	scheduled := []models.ScheduledDMMessage{}
+	for rows.Next() {
+		var m models.ScheduledDMMessage
+		if err := scanScheduled(rows, &m); err != nil {
+			return nil, fmt.Errorf("scan scheduled message row: %w", err)
+		}
+		scheduled = append(scheduled, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scheduled message rows: %w", err)
+	}
+	return scheduled, nil
+}
+
+// CancelScheduledMessage deletes a message senderID scheduled. It returns
+// storage.ErrNotFound if there is no such message, including once it was sent.
+func (s *PostgresDMStore) CancelScheduledMessage(ctx context.Context, id, senderID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx,
+		`DELETE FROM dm_scheduled_messages WHERE id::text = $1 AND sender_id = $2`,
+		id, senderID,
+	)
+	if err != nil {
+		return fmt.Errorf("cancel scheduled message %s: %w", id, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// SendScheduledMessage sends the scheduled message that has been due the
+// longest, in the same transaction that removes it from the schedule, and
+// returns both. A reply whose parent was deleted in the meantime is sent
+// top-level. A message whose sender has left the conversation is dropped
+// and returned with a nil message. It returns nils when nothing is due.
+// Rows locked by another instance are skipped, so instances never send the
+// same message twice.
+func (s *PostgresDMStore) SendScheduledMessage(ctx context.Context) (*models.ScheduledDMMessage, *models.DMMessage, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin send scheduled message: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	scheduled := &models.ScheduledDMMessage{}
+	err = scanScheduled(tx.QueryRow(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM dm_scheduled_messages
+		WHERE send_at <= NOW()
+		ORDER BY send_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`,
+	), scheduled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get due scheduled message: %w", err)
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM dm_scheduled_messages WHERE id = $1`, scheduled.ID); err != nil {
+		return nil, nil, fmt.Errorf("unschedule message %s: %w", scheduled.ID, err)
+	}
+
+	var participant bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM dm_participants WHERE dm_conversation_id = $1 AND user_id = $2)`,
+		scheduled.DMConversationID, scheduled.SenderID,
+	).Scan(&participant)
+	if err != nil {
+		return nil, nil, fmt.Errorf("check sender of scheduled message %s: %w", scheduled.ID, err)
+	}
+
+	var msg *models.DMMessage
+	if participant {
+		msg, err = insertMessage(ctx, tx, scheduled.DMConversationID, scheduled.ParentMessageID, scheduled.SenderID, scheduled.Content, nil)
+		if errors.Is(err, storage.ErrNotFound) {
+			msg, err = insertMessage(ctx, tx, scheduled.DMConversationID, nil, scheduled.SenderID, scheduled.Content, nil)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit scheduled message %s: %w", scheduled.ID, err)
+	}
+
+	if msg != nil {
+		log.Printf("Sent scheduled message %s as %s to DM %s", scheduled.ID, msg.ID, scheduled.DMConversationID)
+	}
+	return scheduled, msg, nil
+}
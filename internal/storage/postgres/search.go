@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxSearchTerms caps how many words of a search query are used.
+const maxSearchTerms = 8
+
+// searchTerms splits query into lowercase words of letters and digits,
+// dropping the punctuation that would otherwise be tsquery syntax.
+func searchTerms(query string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// prefixTSQuery builds a to_tsquery expression requiring every word of
+// query, with the last word matched as a prefix. It returns "" if query has
+// no searchable words.
+func prefixTSQuery(query string) string {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return ""
+	}
+	terms[len(terms)-1] += ":*"
+	return strings.Join(terms, " & ")
+}
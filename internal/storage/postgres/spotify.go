@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresSpotifyTokenStore implements storage.SpotifyTokenStore using PostgreSQL.
+type PostgresSpotifyTokenStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.SpotifyTokenStore = (*PostgresSpotifyTokenStore)(nil)
+
+// NewPostgresSpotifyTokenStore creates a new PostgresSpotifyTokenStore backed by the shared pool db.
+func NewPostgresSpotifyTokenStore(db *pgxpool.Pool) *PostgresSpotifyTokenStore {
+	return &PostgresSpotifyTokenStore{db: db}
+}
+
+// SaveSpotifyToken inserts or replaces a user's Spotify credentials.
+func (s *PostgresSpotifyTokenStore) SaveSpotifyToken(ctx context.Context, token *models.SpotifyToken) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO spotify_tokens (user_id, encrypted_refresh_token, scope, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			encrypted_refresh_token = EXCLUDED.encrypted_refresh_token,
+			scope = EXCLUDED.scope,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := s.db.Exec(ctx, query, token.UserID, token.EncryptedRefreshToken, token.Scope)
+	if err != nil {
+		return fmt.Errorf("save Spotify token for user %s: %w", token.UserID, err)
+	}
+	return nil
+}
+
+// GetSpotifyToken retrieves a user's Spotify credentials.
+func (s *PostgresSpotifyTokenStore) GetSpotifyToken(ctx context.Context, userID string) (*models.SpotifyToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	token := &models.SpotifyToken{}
+	query := `SELECT user_id, encrypted_refresh_token, scope, updated_at FROM spotify_tokens WHERE user_id = $1`
+	err := s.db.QueryRow(ctx, query, userID).Scan(
+		&token.UserID, &token.EncryptedRefreshToken, &token.Scope, &token.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get Spotify token for user %s: %w", userID, err)
+	}
+	return token, nil
+}
+
+// DeleteSpotifyToken unlinks a user's Spotify account.
+func (s *PostgresSpotifyTokenStore) DeleteSpotifyToken(ctx context.Context, userID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `DELETE FROM spotify_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("delete Spotify token for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// spotifyPlaylistColumns is the column list scanned by scanSpotifyPlaylist.
+const spotifyPlaylistColumns = `scene_id, user_id, playlist_id, playlist_url, track_count, synced_at`
+
+// scanSpotifyPlaylist scans a row selected with spotifyPlaylistColumns.
+func scanSpotifyPlaylist(row interface{ Scan(...any) error }, p *models.SpotifyPlaylist) error {
+	return row.Scan(&p.SceneID, &p.UserID, &p.PlaylistID, &p.URL, &p.TrackCount, &p.SyncedAt)
+}
+
+// GetScenePlaylist retrieves the Spotify playlist a scene was exported to.
+func (s *PostgresSpotifyTokenStore) GetScenePlaylist(ctx context.Context, sceneID string) (*models.SpotifyPlaylist, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	playlist := &models.SpotifyPlaylist{}
+	query := `SELECT ` + spotifyPlaylistColumns + ` FROM scene_spotify_playlists WHERE scene_id = $1`
+	err := scanSpotifyPlaylist(s.db.QueryRow(ctx, query, sceneID), playlist)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get Spotify playlist for scene %s: %w", sceneID, err)
+	}
+	return playlist, nil
+}
+
+// SaveScenePlaylist records the Spotify playlist a scene was exported to. SyncedAt is set by the database.
+func (s *PostgresSpotifyTokenStore) SaveScenePlaylist(ctx context.Context, playlist *models.SpotifyPlaylist) (*models.SpotifyPlaylist, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	saved := &models.SpotifyPlaylist{}
+	query := `
+		INSERT INTO scene_spotify_playlists (scene_id, user_id, playlist_id, playlist_url, track_count, synced_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (scene_id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			playlist_id = EXCLUDED.playlist_id,
+			playlist_url = EXCLUDED.playlist_url,
+			track_count = EXCLUDED.track_count,
+			synced_at = EXCLUDED.synced_at
+		RETURNING ` + spotifyPlaylistColumns
+	err := scanSpotifyPlaylist(s.db.QueryRow(ctx, query,
+		playlist.SceneID, playlist.UserID, playlist.PlaylistID, playlist.URL, playlist.TrackCount,
+	), saved)
+	if err != nil {
+		return nil, fmt.Errorf("save Spotify playlist for scene %s: %w", playlist.SceneID, err)
+	}
+	return saved, nil
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresTicketStore implements storage.TicketStore using PostgreSQL.
+type PostgresTicketStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.TicketStore = (*PostgresTicketStore)(nil)
+
+// NewPostgresTicketStore creates a new PostgresTicketStore backed by the shared pool db.
+func NewPostgresTicketStore(db *pgxpool.Pool) *PostgresTicketStore {
+	return &PostgresTicketStore{db: db}
+}
+
+// ticketColumns selects a ticket row for scanTicket.
+const ticketColumns = `id, scene_id, user_id, amount_cents, currency, status, checkout_session_id, COALESCE(payment_intent_id, ''), created_at, paid_at`
+
+// scanTicket scans a row selected with ticketColumns.
+func scanTicket(row interface{ Scan(...any) error }, t *models.SceneTicket) error {
+	return row.Scan(&t.ID, &t.SceneID, &t.UserID, &t.AmountCents, &t.Currency, &t.Status, &t.CheckoutSessionID, &t.PaymentIntentID, &t.CreatedAt, &t.PaidAt)
+}
+
+// CreateTicket records a pending ticket for a checkout session.
+func (s *PostgresTicketStore) CreateTicket(ctx context.Context, ticket *models.SceneTicket) (*models.SceneTicket, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	created := &models.SceneTicket{}
+	err := scanTicket(s.db.QueryRow(ctx, `
+		INSERT INTO scene_tickets (scene_id, user_id, amount_cents, currency, checkout_session_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+ticketColumns,
+		ticket.SceneID, ticket.UserID, ticket.AmountCents, ticket.Currency, ticket.CheckoutSessionID,
+	), created)
+	if err != nil {
+		return nil, fmt.Errorf("create ticket for user %s in scene %s: %w", ticket.UserID, ticket.SceneID, err)
+	}
+	return created, nil
+}
+
+// GetTicket returns the user's most recent ticket for a scene, preferring a
+// paid one, or storage.ErrNotFound if they never started a checkout.
+func (s *PostgresTicketStore) GetTicket(ctx context.Context, sceneID, userID string) (*models.SceneTicket, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ticket := &models.SceneTicket{}
+	err := scanTicket(s.db.QueryRow(ctx, `
+		SELECT `+ticketColumns+` FROM scene_tickets
+		WHERE scene_id = $1 AND user_id = $2
+		ORDER BY status = 'paid' DESC, created_at DESC
+		LIMIT 1`,
+		sceneID, userID,
+	), ticket)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get ticket for user %s in scene %s: %w", userID, sceneID, err)
+	}
+	return ticket, nil
+}
+
+// HasPaidTicket reports whether the user holds a paid ticket for a scene.
+func (s *PostgresTicketStore) HasPaidTicket(ctx context.Context, sceneID, userID string) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var paid bool
+	err := s.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM scene_tickets WHERE scene_id = $1 AND user_id = $2 AND status = 'paid')`,
+		sceneID, userID,
+	).Scan(&paid)
+	if err != nil {
+		return false, fmt.Errorf("check ticket for user %s in scene %s: %w", userID, sceneID, err)
+	}
+	return paid, nil
+}
+
+// ResolveCheckout moves the pending ticket of a checkout session to status.
+// Stripe redelivers webhook events, so a ticket already resolved returns
+// storage.ErrConflict rather than being changed again.
+func (s *PostgresTicketStore) ResolveCheckout(ctx context.Context, sessionID, paymentIntentID string, status models.TicketStatus) (*models.SceneTicket, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ticket := &models.SceneTicket{}
+	err := scanTicket(s.db.QueryRow(ctx, `
+		UPDATE scene_tickets SET
+			status = $3,
+			payment_intent_id = COALESCE(NULLIF($2, ''), payment_intent_id),
+			paid_at = CASE WHEN $3 = 'paid' THEN NOW() END
+		WHERE checkout_session_id = $1 AND status = 'pending'
+		RETURNING `+ticketColumns,
+		sessionID, paymentIntentID, status,
+	), ticket)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM scene_tickets WHERE checkout_session_id = $1)`, sessionID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("check checkout session %s: %w", sessionID, err)
+		}
+		if !exists {
+			return nil, storage.ErrNotFound
+		}
+		return nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve checkout session %s as %s: %w", sessionID, status, err)
+	}
+	return ticket, nil
+}
+
+// RefundTicket marks the paid ticket of a payment intent refunded.
+func (s *PostgresTicketStore) RefundTicket(ctx context.Context, paymentIntentID string) (*models.SceneTicket, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ticket := &models.SceneTicket{}
+	err := scanTicket(s.db.QueryRow(ctx, `
+		UPDATE scene_tickets SET status = 'refunded'
+		WHERE payment_intent_id = $1 AND status = 'paid'
+		RETURNING `+ticketColumns,
+		paymentIntentID,
+	), ticket)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("refund ticket for payment intent %s: %w", paymentIntentID, err)
+	}
+	return ticket, nil
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultQueryTimeout bounds a single store call when no timeout is configured.
+const DefaultQueryTimeout = 5 * time.Second
+
+// queryTimeout is the deadline applied to every store call, including all
+// queries of a transaction. See SetQueryTimeout.
+var queryTimeout = DefaultQueryTimeout
+
+// SetQueryTimeout changes the deadline applied to every store call so a slow
+// or hung database cannot pin request goroutines. Non-positive values disable
+// the deadline. It should be called once at startup, before the stores are used.
+func SetQueryTimeout(d time.Duration) {
+	queryTimeout = d
+}
+
+// withTimeout derives a context for one store call from the caller's context,
+// so request cancellation still propagates to the query.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, queryTimeout)
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresTranscriptStore implements storage.TranscriptStore using PostgreSQL.
+type PostgresTranscriptStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.TranscriptStore = (*PostgresTranscriptStore)(nil)
+
+// NewPostgresTranscriptStore creates a new PostgresTranscriptStore backed by the shared pool db.
+func NewPostgresTranscriptStore(db *pgxpool.Pool) *PostgresTranscriptStore {
+	return &PostgresTranscriptStore{db: db}
+}
+
+// staleTranscriptExport is how long an export may stay running before
+// another run of the job takes it over, e.g. after a crash.
+const staleTranscriptExport = `INTERVAL '15 minutes'`
+
+// transcriptExportColumns selects a transcript export row for scanTranscriptExport.
+const transcriptExportColumns = `id, scene_id, requested_by, format, status, COALESCE(blob_key, ''), COALESCE(error, ''), created_at, completed_at`
+
+// scanTranscriptExport scans a row selected with transcriptExportColumns.
+func scanTranscriptExport(row interface{ Scan(...any) error }, e *models.TranscriptExport) error {
+	return row.Scan(&e.ID, &e.SceneID, &e.RequestedBy, &e.Format, &e.Status, &e.BlobKey, &e.Error, &e.CreatedAt, &e.CompletedAt)
+}
+
+// CountSceneMessages returns the number of chat messages in a scene.
+func (s *PostgresTranscriptStore) CountSceneMessages(ctx context.Context, sceneID string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var n int
+	err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM scene_messages WHERE scene_id = $1`, sceneID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count messages of scene %s: %w", sceneID, err)
+	}
+	return n, nil
+}
+
+// CreateTranscriptExport queues a transcript export of a scene.
+func (s *PostgresTranscriptStore) CreateTranscriptExport(ctx context.Context, sceneID, requestedBy string, format models.TranscriptFormat) (*models.TranscriptExport, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	export := &models.TranscriptExport{}
+	err := scanTranscriptExport(s.db.QueryRow(ctx, `
+		INSERT INTO scene_transcript_exports (scene_id, requested_by, format)
+		VALUES ($1, $2, $3)
+		RETURNING `+transcriptExportColumns,
+		sceneID, requestedBy, format,
+	), export)
+	if err != nil {
+		return nil, fmt.Errorf("create transcript export of scene %s: %w", sceneID, err)
+	}
+	return export, nil
+}
+
+// GetTranscriptExport returns storage.ErrNotFound if there is no export id.
+func (s *PostgresTranscriptStore) GetTranscriptExport(ctx context.Context, id string) (*models.TranscriptExport, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	export := &models.TranscriptExport{}
+	err := scanTranscriptExport(s.db.QueryRow(ctx, `
+		SELECT `+transcriptExportColumns+` FROM scene_transcript_exports WHERE id::text = $1`,
+		id,
+	), export)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get transcript export %s: %w", id, err)
+	}
+	return export, nil
+}
+
+// ClaimTranscriptExport marks the oldest pending export running and returns
+// it, or nil if none is waiting. Exports left running for too long are
+// claimed again.
+func (s *PostgresTranscriptStore) ClaimTranscriptExport(ctx context.Context) (*models.TranscriptExport, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	export := &models.TranscriptExport{}
+	err := scanTranscriptExport(s.db.QueryRow(ctx, `
+		UPDATE scene_transcript_exports SET status = 'running', started_at = NOW()
+		WHERE id = (
+			SELECT id FROM scene_transcript_exports
+			WHERE status = 'pending' OR (status = 'running' AND started_at < NOW() - `+staleTranscriptExport+`)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+transcriptExportColumns,
+	), export)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claim transcript export: %w", err)
+	}
+	return export, nil
+}
+
+// CompleteTranscriptExport records the outcome of a running export: the key
+// its file was stored at, or why it failed when exportErr is set.
+func (s *PostgresTranscriptStore) CompleteTranscriptExport(ctx context.Context, id, blobKey, exportErr string) (*models.TranscriptExport, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	status := models.TranscriptReady
+	if exportErr != "" {
+		status = models.TranscriptFailed
+	}
+	export := &models.TranscriptExport{}
+	err := scanTranscriptExport(s.db.QueryRow(ctx, `
+		UPDATE scene_transcript_exports
+		SET status = $2, blob_key = NULLIF($3, ''), error = NULLIF($4, ''), completed_at = NOW()
+		WHERE id = $1
+		RETURNING `+transcriptExportColumns,
+		id, status, blobKey, exportErr,
+	), export)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("complete transcript export %s: %w", id, err)
+	}
+	return export, nil
+}
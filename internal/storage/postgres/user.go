@@ -0,0 +1,431 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresUserStore implements storage.UserStore using PostgreSQL.
+type PostgresUserStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.UserStore = (*PostgresUserStore)(nil)
+
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// NewPostgresUserStore creates a new PostgresUserStore backed by the shared pool db.
+func NewPostgresUserStore(db *pgxpool.Pool) *PostgresUserStore {
+	return &PostgresUserStore{db: db}
+}
+
+// userColumns is the column list scanned by scanUser.
+const userColumns = `id, display_name, email, password_hash, created_at, last_seen_at, COALESCE(avatar_key, ''), COALESCE(username, '')`
+
+// scanUser scans a row selected with userColumns.
+// Any extra destinations are scanned from columns following userColumns.
+func scanUser(row interface{ Scan(...any) error }, user *models.User, extra ...any) error {
+	dest := []any{&user.ID, &user.DisplayName, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.LastSeenAt, &user.AvatarKey, &user.Username}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	user.AvatarURL = models.AvatarURL(user.ID, user.AvatarKey)
+	return nil
+}
+
+// CreateUser inserts a new user. The password must already be hashed, and an
+// empty username leaves it unset. It returns storage.ErrConflict if the email
+// or username is already registered.
+func (s *PostgresUserStore) CreateUser(ctx context.Context, displayName, username, email, passwordHash string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	query := `
+		INSERT INTO users (display_name, username, email, password_hash)
+		VALUES ($1, NULLIF($2, ''), $3, $4)
+		RETURNING ` + userColumns
+	err := scanUser(s.db.QueryRow(ctx, query, displayName, username, email, passwordHash), user)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create user %s: %w", email, err)
+	}
+
+	log.Printf("User created in DB: ID=%s, Email=%s", user.ID, user.Email)
+	return user, nil
+}
+
+// GetUser retrieves a user by ID.
+func (s *PostgresUserStore) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	err := scanUser(s.db.QueryRow(ctx, query, userID), user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user %s: %w", userID, err)
+	}
+	return user, nil
+}
+
+// GetUsers retrieves the users with the given IDs in one query.
+func (s *PostgresUserStore) GetUsers(ctx context.Context, userIDs []string) (map[string]*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	users := make(map[string]*models.User, len(userIDs))
+	query := `SELECT ` + userColumns + ` FROM users WHERE id::text = ANY($1)`
+	rows, err := s.db.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get %d users: %w", len(userIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &models.User{}
+		if err := scanUser(rows, user); err != nil {
+			return nil, fmt.Errorf("scan user row: %w", err)
+		}
+		users[user.ID] = user
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate user rows: %w", err)
+	}
+	return users, nil
+}
+
+// GetUserByEmail retrieves a user by email address, used for login.
+func (s *PostgresUserStore) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	err := scanUser(s.db.QueryRow(ctx, query, email), user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user by email %s: %w", email, err)
+	}
+	return user, nil
+}
+
+// UpdateDisplayName changes a user's display name and returns the updated user.
+func (s *PostgresUserStore) UpdateDisplayName(ctx context.Context, userID, displayName string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	query := `UPDATE users SET display_name = $2 WHERE id = $1 RETURNING ` + userColumns
+	err := scanUser(s.db.QueryRow(ctx, query, userID, displayName), user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update display name for user %s: %w", userID, err)
+	}
+	return user, nil
+}
+
+// GetUserByUsername retrieves a user by username.
+func (s *PostgresUserStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	err := scanUser(s.db.QueryRow(ctx, query, username), user)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user by username %s: %w", username, err)
+	}
+	return user, nil
+}
+
+// SetUsername changes a user's username and returns the updated user.
+// It returns storage.ErrConflict if another user has the username.
+func (s *PostgresUserStore) SetUsername(ctx context.Context, userID, username string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	query := `UPDATE users SET username = $2 WHERE id = $1 RETURNING ` + userColumns
+	err := scanUser(s.db.QueryRow(ctx, query, userID, username), user)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, storage.ErrConflict
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set username for user %s: %w", userID, err)
+	}
+	return user, nil
+}
+
+// SearchUsers returns up to limit users whose username or display name
+// starts with prefix, ignoring case. Username matches come first.
+func (s *PostgresUserStore) SearchUsers(ctx context.Context, prefix string, limit int) ([]*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var users []*models.User
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE username LIKE $1 || '%' OR lower(display_name) LIKE $1 || '%'
+		ORDER BY username IS NOT DISTINCT FROM $2 DESC, COALESCE(username LIKE $1 || '%', FALSE) DESC, display_name, id
+		LIMIT $3
+	`
+	prefix = strings.ToLower(prefix)
+	rows, err := s.db.Query(ctx, query, escapeLike(prefix), prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search users for %q: %w", prefix, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &models.User{}
+		if err := scanUser(rows, user); err != nil {
+			return nil, fmt.Errorf("scan user search row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate user search rows: %w", err)
+	}
+	return users, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SetAvatar stores the key of a user's new avatar. It returns the updated user
+// and the key of the avatar it replaced, empty if there was none.
+func (s *PostgresUserStore) SetAvatar(ctx context.Context, userID, key string) (*models.User, string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &models.User{}
+	var oldKey string
+	query := `
+		WITH old AS (SELECT COALESCE(avatar_key, '') AS avatar_key FROM users WHERE id = $1 FOR UPDATE)
+		UPDATE users SET avatar_key = $2 WHERE id = $1
+		RETURNING ` + userColumns + `, (SELECT avatar_key FROM old)`
+	err := scanUser(s.db.QueryRow(ctx, query, userID, key), user, &oldKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("set avatar for user %s: %w", userID, err)
+	}
+	return user, oldKey, nil
+}
+
+// TouchLastSeen records that a user was seen at the given time.
+func (s *PostgresUserStore) TouchLastSeen(ctx context.Context, userID string, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `UPDATE users SET last_seen_at = $2 WHERE id::text = $1`, userID, at)
+	if err != nil {
+		return fmt.Errorf("update last seen for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// GetLastSeen returns the last-seen time of each user that has one.
+func (s *PostgresUserStore) GetLastSeen(ctx context.Context, userIDs []string) (map[string]time.Time, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	lastSeen := make(map[string]time.Time, len(userIDs))
+	query := `SELECT id, last_seen_at FROM users WHERE id::text = ANY($1) AND last_seen_at IS NOT NULL`
+	rows, err := s.db.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get last seen for %d users: %w", len(userIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var at time.Time
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, fmt.Errorf("scan last seen row: %w", err)
+		}
+		lastSeen[id] = at
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate last seen rows: %w", err)
+	}
+	return lastSeen, nil
+}
+
+// Follow records that followerID follows followeeID. It returns
+// storage.ErrNotFound if followeeID is not a user and storage.ErrConflict if
+// the follow already exists.
+func (s *PostgresUserStore) Follow(ctx context.Context, followerID, followeeID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_follows (follower_id, followee_id)
+		SELECT $1, id::text FROM users WHERE id::text = $2
+		ON CONFLICT DO NOTHING
+	`
+	result, err := s.db.Exec(ctx, query, followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("follow user %s by %s: %w", followeeID, followerID, err)
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = s.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id::text = $1)`, followeeID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check user %s: %w", followeeID, err)
+	}
+	if !exists {
+		return storage.ErrNotFound
+	}
+	return storage.ErrConflict
+}
+
+// Unfollow removes a follow. It returns storage.ErrNotFound if followerID
+// did not follow followeeID.
+func (s *PostgresUserStore) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, `DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("unfollow user %s by %s: %w", followeeID, followerID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// GetFollowing returns the users userID follows, most recently followed first.
+func (s *PostgresUserStore) GetFollowing(ctx context.Context, userID string) ([]*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var users []*models.User
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		JOIN (SELECT followee_id, created_at AS followed_at FROM user_follows WHERE follower_id = $1) f
+			ON f.followee_id = users.id::text
+		ORDER BY f.followed_at DESC, users.id
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get users followed by %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := &models.User{}
+		if err := scanUser(rows, user); err != nil {
+			return nil, fmt.Errorf("scan followed user row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate followed user rows: %w", err)
+	}
+	return users, nil
+}
+
+// GetSettings returns a user's saved settings over the defaults, so settings
+// added after they were saved take their default value, and when they were
+// saved; the time is nil if the user never saved any. It returns
+// storage.ErrNotFound if the user does not exist.
+func (s *PostgresUserStore) GetSettings(ctx context.Context, userID string) (*models.UserSettings, *time.Time, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var raw []byte
+	var updatedAt *time.Time
+	query := `
+		SELECT st.settings, st.updated_at
+		FROM users u
+		LEFT JOIN user_settings st ON st.user_id = u.id::text
+		WHERE u.id::text = $1
+	`
+	err := s.db.QueryRow(ctx, query, userID).Scan(&raw, &updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get settings of user %s: %w", userID, err)
+	}
+
+	settings := models.DefaultUserSettings()
+	if raw != nil {
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return nil, nil, fmt.Errorf("decode settings of user %s: %w", userID, err)
+		}
+	}
+	return &settings, updatedAt, nil
+}
+
+// SaveSettings replaces a user's settings and returns when they were saved.
+// It returns storage.ErrNotFound if the user does not exist.
+func (s *PostgresUserStore) SaveSettings(ctx context.Context, userID string, settings models.UserSettings) (time.Time, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("encode settings of user %s: %w", userID, err)
+	}
+
+	var updatedAt time.Time
+	query := `
+		INSERT INTO user_settings (user_id, settings)
+		SELECT id::text, $2::jsonb FROM users WHERE id::text = $1
+		ON CONFLICT (user_id) DO UPDATE SET settings = EXCLUDED.settings, updated_at = NOW()
+		RETURNING updated_at
+	`
+	err = s.db.QueryRow(ctx, query, userID, raw).Scan(&updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("save settings of user %s: %w", userID, err)
+	}
+	return updatedAt, nil
+}
@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresWebhookStore implements storage.WebhookStore using PostgreSQL.
+type PostgresWebhookStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.WebhookStore = (*PostgresWebhookStore)(nil)
+
+// NewPostgresWebhookStore creates a new PostgresWebhookStore backed by the shared pool db.
+func NewPostgresWebhookStore(db *pgxpool.Pool) *PostgresWebhookStore {
+	return &PostgresWebhookStore{db: db}
+}
+
+// webhookColumns is the column list scanned by scanWebhook; the secret is left out.
+const webhookColumns = `id, owner_id, scene_id::text, url, events, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }, webhook *models.Webhook) error {
+	return row.Scan(&webhook.ID, &webhook.OwnerID, &webhook.SceneID, &webhook.URL, &webhook.Events, &webhook.CreatedAt)
+}
+
+// CreateWebhook registers a webhook and fills in its ID and creation time.
+func (s *PostgresWebhookStore) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhooks (owner_id, scene_id, url, secret, events)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	err := s.db.QueryRow(ctx, query, webhook.OwnerID, webhook.SceneID, webhook.URL, webhook.Secret, webhook.Events).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("create webhook for %s: %w", webhook.OwnerID, err)
+	}
+	return webhook, nil
+}
+
+// GetWebhook returns a webhook without its secret.
+func (s *PostgresWebhookStore) GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	webhook := &models.Webhook{}
+	err := scanWebhook(s.db.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id::text = $1`, webhookID), webhook)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get webhook %s: %w", webhookID, err)
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns the webhooks registered by ownerID, oldest first.
+func (s *PostgresWebhookStore) ListWebhooks(ctx context.Context, ownerID string) ([]models.Webhook, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE owner_id = $1 ORDER BY created_at`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks of %s: %w", ownerID, err)
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		var webhook models.Webhook
+		if err := scanWebhook(rows, &webhook); err != nil {
+			return nil, fmt.Errorf("scan webhook row of %s: %w", ownerID, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook rows of %s: %w", ownerID, err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery history.
+func (s *PostgresWebhookStore) DeleteWebhook(ctx context.Context, webhookID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.db.Exec(ctx, `DELETE FROM webhooks WHERE id::text = $1`, webhookID)
+	if err != nil {
+		return fmt.Errorf("delete webhook %s: %w", webhookID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueEvent queues one delivery per subscribed webhook.
+func (s *PostgresWebhookStore) EnqueueEvent(ctx context.Context, eventID, event, sceneID string, payload []byte) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload)
+		SELECT id, $1, $2, $4 FROM webhooks
+		WHERE $2 = ANY(events) AND (scene_id IS NULL OR scene_id::text = $3)`
+	tag, err := s.db.Exec(ctx, query, eventID, event, sceneID, payload)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue %s event %s: %w", event, eventID, err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// deliveryColumns is the column list scanned by scanDelivery.
+const deliveryColumns = `
+	d.id, d.webhook_id, d.event_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+func scanDelivery(row interface{ Scan(...any) error }, d *models.WebhookDelivery, extra ...any) error {
+	return row.Scan(append([]any{
+		&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	}, extra...)...)
+}
+
+// ClaimDeliveries leases up to limit due deliveries, oldest first.
+func (s *PostgresWebhookStore) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING ` + deliveryColumns + `, w.url, w.secret`
+	rows, err := s.db.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := scanDelivery(rows, &d, &d.URL, &d.Secret); err != nil {
+			return nil, fmt.Errorf("scan claimed delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate claimed deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt.
+func (s *PostgresWebhookStore) RecordAttempt(ctx context.Context, deliveryID string, statusCode *int, errMsg string, retryAt *time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	status := models.DeliveryPending
+	switch {
+	case retryAt != nil:
+	case errMsg == "":
+		status = models.DeliveryDelivered
+	default:
+		status = models.DeliveryFailed
+	}
+	query := `
+		UPDATE webhook_deliveries SET
+			attempts = attempts + 1,
+			status = $2,
+			last_status_code = $3,
+			last_error = $4,
+			next_attempt_at = COALESCE($5, next_attempt_at),
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() END
+		WHERE id = $1`
+	if _, err := s.db.Exec(ctx, query, deliveryID, string(status), statusCode, errMsg, retryAt); err != nil {
+		return fmt.Errorf("record attempt for delivery %s: %w", deliveryID, err)
+	}
+	return nil
+}
+
+// ListDeliveries returns a webhook's most recent deliveries, newest first.
+func (s *PostgresWebhookStore) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + deliveryColumns + ` FROM webhook_deliveries d
+		WHERE d.webhook_id::text = $1
+		ORDER BY d.created_at DESC
+		LIMIT $2`
+	rows, err := s.db.Query(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries of webhook %s: %w", webhookID, err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, fmt.Errorf("scan delivery of webhook %s: %w", webhookID, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deliveries of webhook %s: %w", webhookID, err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,334 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+	"github.com/Vasu1712/scenyx-backend/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresWorkspaceStore implements storage.WorkspaceStore using PostgreSQL.
+type PostgresWorkspaceStore struct {
+	db *pgxpool.Pool
+}
+
+var _ storage.WorkspaceStore = (*PostgresWorkspaceStore)(nil)
+
+// NewPostgresWorkspaceStore creates a new PostgresWorkspaceStore backed by the shared pool db.
+func NewPostgresWorkspaceStore(db *pgxpool.Pool) *PostgresWorkspaceStore {
+	return &PostgresWorkspaceStore{db: db}
+}
+
+// workspaceColumns selects a workspace row for scanWorkspace; the workspaces
+// table must be aliased as w.
+const workspaceColumns = `
+	w.id, w.name, w.owner_id,
+	(SELECT COUNT(*) FROM workspace_members WHERE workspace_id = w.id) AS members,
+	w.created_at`
+
+func scanWorkspace(row interface{ Scan(...any) error }, ws *models.Workspace) error {
+	return row.Scan(&ws.ID, &ws.Name, &ws.OwnerID, &ws.Members, &ws.CreatedAt)
+}
+
+// inWorkspace is a condition matching rows whose workspace ID column equals
+// the workspace ID bound to param, or rows outside any workspace when it is empty.
+func inWorkspace(column, param string) string {
+	return column + ` IS NOT DISTINCT FROM NULLIF(` + param + `, '')::uuid`
+}
+
+// memberOrPublic is a condition matching rows outside any workspace, or in a
+// workspace the user whose ID is bound to param is a member of.
+func memberOrPublic(column, param string) string {
+	return `(` + column + ` IS NULL OR EXISTS (
+		SELECT 1 FROM workspace_members wm WHERE wm.workspace_id = ` + column + ` AND wm.user_id = ` + param + `))`
+}
+
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// areWorkspaceMembers reports whether every one of userIDs is a member of
+// the workspace. Pass the transaction making the change so the check and the
+// write see the same memberships.
+func areWorkspaceMembers(ctx context.Context, q rowQuerier, workspaceID string, userIDs []string) (bool, error) {
+	var members bool
+	err := q.QueryRow(ctx, `
+		SELECT NOT EXISTS (
+			SELECT 1 FROM unnest($2::text[]) AS u(id)
+			WHERE NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = $1 AND m.user_id = u.id)
+		)`,
+		workspaceID, userIDs,
+	).Scan(&members)
+	if err != nil {
+		return false, fmt.Errorf("check membership of workspace %s: %w", workspaceID, err)
+	}
+	return members, nil
+}
+
+// CreateWorkspace creates a workspace and makes its owner the first member
+// in the same transaction.
+func (s *PostgresWorkspaceStore) CreateWorkspace(ctx context.Context, name, ownerID string) (*models.Workspace, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create workspace: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ws := &models.Workspace{Name: name, OwnerID: ownerID, Members: 1}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO workspaces (name, owner_id) VALUES ($1, $2) RETURNING id, created_at`,
+		name, ownerID,
+	).Scan(&ws.ID, &ws.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("create workspace: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)`,
+		ws.ID, ownerID, string(models.WorkspaceOwner),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("add owner %s to workspace %s: %w", ownerID, ws.ID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit workspace %s: %w", ws.ID, err)
+	}
+
+	log.Printf("Workspace created in DB: ID=%s, Name=%s, OwnerID=%s", ws.ID, ws.Name, ws.OwnerID)
+	return ws, nil
+}
+
+// GetWorkspace retrieves a workspace by ID.
+// It returns storage.ErrNotFound if no such workspace exists.
+func (s *PostgresWorkspaceStore) GetWorkspace(ctx context.Context, workspaceID string) (*models.Workspace, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ws := &models.Workspace{}
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces w WHERE w.id = $1`
+	err := scanWorkspace(s.db.QueryRow(ctx, query, workspaceID), ws)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get workspace %s: %w", workspaceID, err)
+	}
+	return ws, nil
+}
+
+// GetWorkspaces lists the workspaces a user is a member of, oldest first.
+func (s *PostgresWorkspaceStore) GetWorkspaces(ctx context.Context, userID string) ([]*models.Workspace, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members me ON me.workspace_id = w.id AND me.user_id = $1
+		ORDER BY w.created_at, w.id
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get workspaces for user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	workspaces := []*models.Workspace{}
+	for rows.Next() {
+		ws := &models.Workspace{}
+		if err := scanWorkspace(rows, ws); err != nil {
+			return nil, fmt.Errorf("scan workspace row for user %s: %w", userID, err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate workspace rows for user %s: %w", userID, err)
+	}
+	return workspaces, nil
+}
+
+// GetMembers lists a workspace's members, earliest to join first.
+func (s *PostgresWorkspaceStore) GetMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMembership, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT user_id, role, joined_at FROM workspace_members
+		WHERE workspace_id = $1
+		ORDER BY joined_at, user_id`,
+		workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get members of workspace %s: %w", workspaceID, err)
+	}
+	defer rows.Close()
+
+	members := []models.WorkspaceMembership{}
+	for rows.Next() {
+		m := models.WorkspaceMembership{WorkspaceID: workspaceID}
+		if err := rows.Scan(&m.UserID, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("scan member of workspace %s: %w", workspaceID, err)
+		}
+		members = append(members, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate members of workspace %s: %w", workspaceID, err)
+	}
+	return members, nil
+}
+
+// GetMemberRole returns a member's role in a workspace.
+// It returns storage.ErrNotFound if the user is not a member.
+func (s *PostgresWorkspaceStore) GetMemberRole(ctx context.Context, workspaceID, userID string) (models.WorkspaceRole, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var role models.WorkspaceRole
+	err := s.db.QueryRow(ctx,
+		`SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceID, userID,
+	).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", storage.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get role of user %s in workspace %s: %w", userID, workspaceID, err)
+	}
+	return role, nil
+}
+
+// AddMember adds a user to a workspace with role.
+func (s *PostgresWorkspaceStore) AddMember(ctx context.Context, workspaceID, userID string, role models.WorkspaceRole) (*models.WorkspaceMembership, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	m := &models.WorkspaceMembership{WorkspaceID: workspaceID, UserID: userID, Role: role}
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		SELECT id, $2, $3 FROM workspaces WHERE id = $1
+		RETURNING joined_at`,
+		workspaceID, userID, string(role),
+	).Scan(&m.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, storage.ErrConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("add user %s to workspace %s: %w", userID, workspaceID, err)
+	}
+
+	log.Printf("User %s added to workspace %s as %s.", userID, workspaceID, role)
+	return m, nil
+}
+
+// RemoveMember removes a user from a workspace, along with the workspace's
+// scenes and group conversations they joined and their roles in its scenes.
+// The owner cannot be removed.
+func (s *PostgresWorkspaceStore) RemoveMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceRooms, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin remove user %s from workspace %s: %w", userID, workspaceID, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var role models.WorkspaceRole
+	err = tx.QueryRow(ctx,
+		`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2 RETURNING role`,
+		workspaceID, userID,
+	).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("remove user %s from workspace %s: %w", userID, workspaceID, err)
+	}
+	if role == models.WorkspaceOwner {
+		return nil, storage.ErrForbidden
+	}
+
+	// Collect the rooms before the user's group conversations are left
+	rooms, err := workspaceRooms(ctx, tx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM scene_participants p USING scenes s
+		WHERE p.scene_id = s.id AND s.workspace_id = $1 AND p.user_id = $2`,
+		workspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("remove user %s from scenes of workspace %s: %w", userID, workspaceID, err)
+	}
+	_, err = tx.Exec(ctx, `
+		DELETE FROM scene_roles r USING scenes s
+		WHERE r.scene_id = s.id AND s.workspace_id = $1 AND r.user_id = $2`,
+		workspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("remove roles of user %s in workspace %s: %w", userID, workspaceID, err)
+	}
+	_, err = tx.Exec(ctx, `
+		DELETE FROM dm_participants p USING dm_conversations c
+		WHERE p.dm_conversation_id = c.id AND c.workspace_id = $1 AND c.is_group AND p.user_id = $2`,
+		workspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("remove user %s from conversations of workspace %s: %w", userID, workspaceID, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit removal of user %s from workspace %s: %w", userID, workspaceID, err)
+	}
+
+	log.Printf("User %s removed from workspace %s.", userID, workspaceID)
+	return rooms, nil
+}
+
+// workspaceRooms lists the workspace's scenes and the conversations of the
+// workspace userID takes part in.
+func workspaceRooms(ctx context.Context, tx pgx.Tx, workspaceID, userID string) (*models.WorkspaceRooms, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT 'scene', id::text FROM scenes WHERE workspace_id = $1
+		UNION ALL
+		SELECT 'dm', c.id::text FROM dm_conversations c
+		JOIN dm_participants p ON p.dm_conversation_id = c.id
+		WHERE c.workspace_id = $1 AND p.user_id = $2`,
+		workspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list rooms of user %s in workspace %s: %w", userID, workspaceID, err)
+	}
+	defer rows.Close()
+
+	rooms := &models.WorkspaceRooms{}
+	for rows.Next() {
+		var kind, id string
+		if err := rows.Scan(&kind, &id); err != nil {
+			return nil, fmt.Errorf("scan room row of workspace %s: %w", workspaceID, err)
+		}
+		if kind == "scene" {
+			rooms.SceneIDs = append(rooms.SceneIDs, id)
+		} else {
+			rooms.DMIDs = append(rooms.DMIDs, id)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate room rows of workspace %s: %w", workspaceID, err)
+	}
+	return rooms, nil
+}
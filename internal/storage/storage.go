@@ -0,0 +1,839 @@
+// Package storage defines the persistence interfaces the API handlers depend on.
+// Concrete implementations live in subpackages (e.g. storage/postgres).
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Vasu1712/scenyx-backend/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("storage: not found")
+	// ErrConflict is returned when a write would violate a uniqueness or
+	// membership rule (e.g. joining a scene twice, duplicate email).
+	ErrConflict = errors.New("storage: conflict")
+	// ErrForbidden is returned when a moderation restriction blocks the write
+	// (e.g. a banned user joining a scene).
+	ErrForbidden = errors.New("storage: forbidden")
+	// ErrLimitReached is returned when a write would exceed a per-record cap
+	// (e.g. pinning more than models.MaxPinnedMessages in a scene).
+	ErrLimitReached = errors.New("storage: limit reached")
+	// ErrInvalidCursor is returned when a page cursor was not produced by
+	// this package (e.g. it was truncated or edited by the client).
+	ErrInvalidCursor = errors.New("storage: invalid cursor")
+)
+
+// DefaultPageLimit and MaxPageLimit bound the number of rows a paginated query returns.
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// MessagePage selects a window of a message history. Before and After are
+// message IDs used as exclusive cursors; at most one should be set. With
+// neither, the most recent messages are returned. Results are always in
+// chronological order.
+type MessagePage struct {
+	Limit  int
+	Before string
+	After  string
+}
+
+// NormalizedLimit clamps Limit to (0, MaxPageLimit], defaulting to DefaultPageLimit.
+func (p MessagePage) NormalizedLimit() int {
+	return normalizedPageLimit(p.Limit)
+}
+
+// ConversationPage selects a window of a user's conversations, most recently
+// active first. Cursor is the value ConversationCursor returned for the last
+// conversation of the previous page; empty starts from the most recent.
+// Conversations that become active while a client is paging move to the front
+// and are not repeated on later pages.
+type ConversationPage struct {
+	Limit    int
+	Cursor   string
+	Archived bool // List the conversations the user archived instead of the others
+
+	WorkspaceID string // List the conversations of this workspace; empty lists those outside any workspace
+}
+
+// NormalizedLimit clamps Limit to (0, MaxPageLimit], defaulting to DefaultPageLimit.
+func (p ConversationPage) NormalizedLimit() int {
+	return normalizedPageLimit(p.Limit)
+}
+
+func normalizedPageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return limit
+}
+
+// ConversationCursor returns the opaque cursor of the page following conv.
+func ConversationCursor(conv *models.DMConversation) string {
+	raw := conv.UpdatedAt.UTC().Format(time.RFC3339Nano) + " " + conv.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseConversationCursor decodes a cursor made by ConversationCursor into
+// the position it marks. It returns ErrInvalidCursor if cursor is malformed.
+func ParseConversationCursor(cursor string) (updatedAt time.Time, id string, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), " ")
+	if !ok {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	if updatedAt, err = time.Parse(time.RFC3339Nano, ts); err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	if _, err = uuid.Parse(id); err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return updatedAt, id, nil
+}
+
+// Message search bounds: the default and maximum number of matches, and how
+// many neighbouring messages are returned on each side of a match.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+	SearchContextSize  = 2
+)
+
+// MessageSearch is a full-text query over chat messages. Every word must
+// match; the last is matched as a prefix. Results are newest first.
+type MessageSearch struct {
+	Query string
+	Limit int
+}
+
+// NormalizedLimit clamps Limit to (0, MaxSearchLimit], defaulting to DefaultSearchLimit.
+func (s MessageSearch) NormalizedLimit() int {
+	if s.Limit <= 0 {
+		return DefaultSearchLimit
+	}
+	if s.Limit > MaxSearchLimit {
+		return MaxSearchLimit
+	}
+	return s.Limit
+}
+
+// SceneUpdate lists the scene fields to change; nil fields are left as they are.
+type SceneUpdate struct {
+	Name          *string
+	ArtistName    *string
+	Description   *string
+	CoverImageURL *string
+	Tags          *[]string
+	// RequiresApproval switches join approval on or off; pending requests
+	// are kept either way.
+	RequiresApproval *bool
+	// SkipThreshold sets the fraction of active users needed to skip a track.
+	SkipThreshold *float64
+	// MaxDurationSeconds limits how long the scene runs; 0 removes the limit.
+	// Changing it re-arms the countdown warnings.
+	MaxDurationSeconds *int
+	// TicketPriceCents sets the price of entry; 0 makes the scene free.
+	TicketPriceCents *int
+	// TicketCurrency sets the lowercase ISO 4217 currency of the price.
+	TicketCurrency *string
+}
+
+// SceneStore persists scenes, their participants, and scene chat.
+type SceneStore interface {
+	// CreateScene creates a scheduled scene when scheduledAt is non-nil. An
+	// ephemeral scene's history is deleted when it is archived or closed.
+	// With workspaceID set the scene is private to that workspace, and
+	// CreateScene returns ErrForbidden unless creatorID is a member.
+	CreateScene(ctx context.Context, workspaceID, name, artistName, creatorID string, tags []string, scheduledAt *time.Time, ephemeral bool) (*models.Scene, error)
+	GetScene(ctx context.Context, sceneID string) (*models.Scene, error)
+	// GetScenesForUser omits archived scenes. It lists the scenes of
+	// workspaceID, or the public scenes when workspaceID is empty.
+	GetScenesForUser(ctx context.Context, userID, workspaceID string) ([]*models.Scene, error)
+	// SearchScenes returns non-archived scenes matching query by name, artist
+	// name, or tag, best match first, skipping offset results. It searches
+	// the scenes of workspaceID, or the public scenes when workspaceID is empty.
+	SearchScenes(ctx context.Context, workspaceID, query string, limit, offset int) ([]*models.Scene, error)
+	// UpdateScene applies update and returns the updated scene, or ErrNotFound.
+	UpdateScene(ctx context.Context, sceneID string, update SceneUpdate) (*models.Scene, error)
+	// SetSceneCover returns the updated scene and the replaced cover key, empty if none.
+	SetSceneCover(ctx context.Context, sceneID, key string) (*models.Scene, string, error)
+	// GetSceneBySlug returns ErrNotFound if no scene has claimed slug.
+	GetSceneBySlug(ctx context.Context, slug string) (*models.Scene, error)
+	// SetSceneSlug claims slug for a scene, or releases its slug if empty.
+	// It returns ErrConflict if another scene holds the slug.
+	SetSceneSlug(ctx context.Context, sceneID, slug string) (*models.Scene, error)
+	// DeleteScene removes the scene and everything attached to it, checking
+	// in the same transaction that creatorID created it. It returns the key
+	// of the scene's uploaded cover, if any, ErrNotFound if the scene does not
+	// exist, and ErrForbidden if creatorID is not its creator.
+	DeleteScene(ctx context.Context, sceneID, creatorID string) (coverKey string, err error)
+	// SetArchived hides (or restores) a scene in listings while keeping its
+	// history, unless the scene is ephemeral: archiving one deletes its
+	// history, attachments, and transcript exports.
+	SetArchived(ctx context.Context, sceneID string, archived bool) error
+	// GetScenesEndingBy returns the live, unarchived scenes whose maximum
+	// duration runs out by by, soonest first.
+	GetScenesEndingBy(ctx context.Context, by time.Time) ([]*models.Scene, error)
+	// ClaimEndWarning records that the countdown warning for lead has been
+	// sent for a scene. It reports false if it, or a shorter one, already was.
+	ClaimEndWarning(ctx context.Context, sceneID string, lead time.Duration) (bool, error)
+	// CloseScene archives a scene, deleting an ephemeral scene's history,
+	// attachments, and transcript exports, and releases its slug. It returns
+	// ErrNotFound if the scene does not exist and ErrConflict if it is
+	// already archived, so each close is handled once.
+	CloseScene(ctx context.Context, sceneID string) (*models.Scene, error)
+	// Reschedule returns ErrNotFound if the scene does not exist and ErrConflict if it is already live.
+	Reschedule(ctx context.Context, sceneID string, at time.Time) (*models.Scene, error)
+	// AddRSVP returns ErrNotFound if the scene does not exist, ErrForbidden if
+	// it is not scheduled, and ErrConflict if the user already RSVP'd.
+	AddRSVP(ctx context.Context, sceneID, userID string) error
+	// RemoveRSVP returns ErrNotFound if the user had not RSVP'd.
+	RemoveRSVP(ctx context.Context, sceneID, userID string) error
+	GetRSVPs(ctx context.Context, sceneID string) ([]string, error)
+	// ClaimReminders and StartDueScenes atomically take the scheduled scenes
+	// starting within lead, or already due, so each is handled exactly once.
+	ClaimReminders(ctx context.Context, lead time.Duration) ([]*models.Scene, error)
+	StartDueScenes(ctx context.Context) ([]*models.Scene, error)
+	// JoinScene returns ErrNotFound if the scene does not exist,
+	// ErrForbidden if the user is banned or not a member of the scene's
+	// workspace, and ErrConflict if the user has already joined.
+	JoinScene(ctx context.Context, sceneID, userID string) error
+	// RequestJoin records a pending join request. It returns ErrNotFound if the
+	// scene does not exist, ErrForbidden if the user is banned or not a member
+	// of the scene's workspace, and ErrConflict if the user has already joined
+	// or is already waiting.
+	RequestJoin(ctx context.Context, sceneID, userID string) (*models.SceneJoinRequest, error)
+	// GetJoinRequests lists a scene's pending join requests, oldest first.
+	GetJoinRequests(ctx context.Context, sceneID string) ([]models.SceneJoinRequest, error)
+	// ResolveJoinRequest removes a pending request, adding the user to the
+	// participants if approve is set. It returns ErrNotFound if there is no such request.
+	ResolveJoinRequest(ctx context.Context, sceneID, userID string, approve bool) error
+	// LeaveScene returns ErrNotFound if the scene does not exist and
+	// ErrConflict if the user is not a participant. Leaving drops any co-host role.
+	LeaveScene(ctx context.Context, sceneID, userID string) error
+	// GetSceneParticipants returns the joined users of each scene, keyed by
+	// scene ID. Scenes without participants are omitted.
+	GetSceneParticipants(ctx context.Context, sceneIDs []string) (map[string][]string, error)
+	AddSceneMessage(ctx context.Context, sceneID, senderID, content string) (*models.SceneMessage, error)
+	GetSceneMessages(ctx context.Context, sceneID string) ([]models.SceneMessage, error)
+	SearchSceneMessages(ctx context.Context, sceneID string, search MessageSearch) ([]models.SceneSearchResult, error)
+	// DeleteSceneMessage permanently removes a message; ErrNotFound if it does not exist.
+	DeleteSceneMessage(ctx context.Context, messageID string) (*models.SceneMessage, error)
+	// AddSceneReaction returns ErrNotFound if the message does not exist and
+	// ErrConflict if the user already reacted with emoji. RemoveSceneReaction
+	// returns ErrNotFound if there is no such reaction. Both return the message
+	// with its updated reaction counts.
+	AddSceneReaction(ctx context.Context, messageID, userID, emoji string) (*models.SceneMessage, error)
+	RemoveSceneReaction(ctx context.Context, messageID, userID, emoji string) (*models.SceneMessage, error)
+	// SetSceneMessageMentions replaces a message's mentions with the spans
+	// whose username belongs to a user, and returns the stored mentions.
+	SetSceneMessageMentions(ctx context.Context, messageID string, spans []models.Mention) ([]models.Mention, error)
+	// PinMessage returns ErrNotFound if the message is not in the scene,
+	// ErrConflict if it is already pinned, and ErrLimitReached if the scene
+	// already has models.MaxPinnedMessages pins.
+	PinMessage(ctx context.Context, sceneID, messageID, pinnedBy string) error
+	// UnpinMessage returns ErrNotFound if the message is not pinned in the scene.
+	UnpinMessage(ctx context.Context, sceneID, messageID string) error
+	// GetPinnedMessages lists a scene's pins, oldest pin first.
+	GetPinnedMessages(ctx context.Context, sceneID string) ([]models.PinnedMessage, error)
+	AddToQueue(ctx context.Context, item *models.QueueItem) (*models.QueueItem, error)
+	// RemoveFromQueue returns ErrNotFound if the item is not queued in the scene.
+	RemoveFromQueue(ctx context.Context, sceneID, itemID string) error
+	// ReorderQueue sets the queue order to itemIDs, which must list every
+	// queued item exactly once; otherwise it returns ErrConflict.
+	ReorderQueue(ctx context.Context, sceneID string, itemIDs []string) error
+	GetQueue(ctx context.Context, sceneID string) ([]models.QueueItem, error)
+	// VoteQueueItem records userID's vote on a queued track: 1 up, -1 down,
+	// and 0 to withdraw it. It returns ErrNotFound if the item is not queued
+	// in the scene.
+	VoteQueueItem(ctx context.Context, sceneID, itemID, userID string, vote int) error
+	// CreatePoll returns ErrNotFound if the scene does not exist.
+	CreatePoll(ctx context.Context, sceneID, createdBy, question string, options []string) (*models.ScenePoll, error)
+	// GetPoll returns the poll with its vote counts, or ErrNotFound.
+	GetPoll(ctx context.Context, pollID string) (*models.ScenePoll, error)
+	// GetPolls lists a scene's polls, newest first.
+	GetPolls(ctx context.Context, sceneID string) ([]models.ScenePoll, error)
+	// Vote records or changes userID's choice and returns the updated poll. It
+	// returns ErrNotFound if the poll does not exist, ErrForbidden if it is
+	// closed, and ErrConflict if option is out of range.
+	Vote(ctx context.Context, pollID, userID string, option int) (*models.ScenePoll, error)
+	// ClosePoll stops a poll accepting votes. It returns ErrNotFound if the
+	// poll does not exist and ErrConflict if it is already closed.
+	ClosePoll(ctx context.Context, pollID string) (*models.ScenePoll, error)
+	// GetSceneRole returns userID's role, RoleListener if they have none, or
+	// ErrNotFound if the scene does not exist.
+	GetSceneRole(ctx context.Context, sceneID, userID string) (models.SceneRole, error)
+	// SetSceneRole makes userID a co-host or a listener. The host's role cannot
+	// be changed; ErrForbidden is returned for it.
+	SetSceneRole(ctx context.Context, sceneID, userID string, role models.SceneRole, assignedBy string) (*models.SceneRoleAssignment, error)
+	// GetSceneRoles lists the host and co-hosts of a scene, host first.
+	GetSceneRoles(ctx context.Context, sceneID string) ([]models.SceneRoleAssignment, error)
+	// AddRestriction bans or mutes a user. Banning also removes them from the
+	// participants and drops any co-host role.
+	AddRestriction(ctx context.Context, sceneID, userID string, kind models.SceneRestriction, createdBy string) error
+	// RemoveRestriction returns ErrNotFound if the user had no such restriction.
+	RemoveRestriction(ctx context.Context, sceneID, userID string, kind models.SceneRestriction) error
+	HasRestriction(ctx context.Context, sceneID, userID string, kind models.SceneRestriction) (bool, error)
+}
+
+// DMStore persists direct-message conversations and their messages.
+type DMStore interface {
+	// StartOrGetConversation and CreateGroupConversation create the
+	// conversation in workspaceID when it is set, returning ErrForbidden
+	// unless every participant is a member. A one-to-one conversation is
+	// separate in each workspace and in the public space.
+	StartOrGetConversation(ctx context.Context, workspaceID, user1, user2 string) (*models.DMConversation, error)
+	CreateGroupConversation(ctx context.Context, workspaceID, name, creatorID string, memberIDs []string) (*models.DMConversation, error)
+	GetConversation(ctx context.Context, dmID string) (*models.DMConversation, error)
+	// GetConversations returns a page of the user's conversations, most
+	// recently active first, or ErrInvalidCursor for a bad page.Cursor.
+	// Conversations of workspaces the user has left are not listed.
+	GetConversations(ctx context.Context, userID string, page ConversationPage) ([]*models.DMConversation, error)
+	// GetConversationIDs returns the IDs of every conversation the user takes part in.
+	GetConversationIDs(ctx context.Context, userID string) ([]string, error)
+	GetParticipants(ctx context.Context, dmID string) ([]string, error)
+	// AddParticipant and RemoveParticipant return ErrConflict if the
+	// conversation is not a group or membership is already as requested.
+	// AddParticipant returns ErrForbidden if the conversation belongs to a
+	// workspace the user is not a member of.
+	AddParticipant(ctx context.Context, dmID, userID string) error
+	RemoveParticipant(ctx context.Context, dmID, userID string) error
+	// GetMessages pages through top-level messages; thread replies are fetched with GetThread.
+	GetMessages(ctx context.Context, dmID string, page MessagePage) ([]models.DMMessage, error)
+	// GetHistory pages through every message of a conversation, replies and
+	// tombstones included, oldest first, starting after message afterID.
+	GetHistory(ctx context.Context, dmID, afterID string, limit int) ([]models.DMMessage, error)
+	// AddMessage stores a message and bumps every other participant's unread count.
+	AddMessage(ctx context.Context, dmID, senderID, content string) (*models.DMMessage, error)
+	// AddReply stores a reply in the thread of parentMessageID, which must be
+	// a live message in dmID (otherwise ErrNotFound). Replying to a reply adds
+	// to the same thread.
+	AddReply(ctx context.Context, dmID, parentMessageID, senderID, content string) (*models.DMMessage, error)
+	// AddEncryptedMessage stores ciphertext sealed with keyVersion of the
+	// conversation key, as a reply when parentMessageID is set (as AddReply).
+	AddEncryptedMessage(ctx context.Context, dmID, parentMessageID, senderID, ciphertext string, keyVersion int) (*models.DMMessage, error)
+	// SearchMessages searches the conversations userID takes part in, outside
+	// workspaces they have left, or only dmID when it is set; encrypted
+	// messages never match. Context messages come from the match's thread.
+	SearchMessages(ctx context.Context, userID, dmID string, search MessageSearch) ([]models.DMSearchResult, error)
+	// GetThread returns a top-level message and its replies; ErrNotFound if the message does not exist.
+	GetThread(ctx context.Context, messageID string) (*models.DMThread, error)
+	// EditMessage and DeleteMessage return ErrNotFound if the message does not
+	// exist or was already deleted, and ErrForbidden if senderID did not send it.
+	// EditMessage stores ciphertext when keyVersion is set.
+	EditMessage(ctx context.Context, messageID, senderID, content string, keyVersion *int) (*models.DMMessage, error)
+	DeleteMessage(ctx context.Context, messageID, senderID string) (*models.DMMessage, error)
+	// AddReaction returns ErrNotFound if the message does not exist or was
+	// deleted, and ErrConflict if the user already reacted with emoji.
+	// RemoveReaction returns ErrNotFound if there is no such reaction. Both
+	// return the message with its updated reaction counts.
+	AddReaction(ctx context.Context, messageID, userID, emoji string) (*models.DMMessage, error)
+	RemoveReaction(ctx context.Context, messageID, userID, emoji string) (*models.DMMessage, error)
+	// SetMentions replaces a message's mentions with the spans whose username
+	// belongs to a participant of its conversation, and returns the stored mentions.
+	SetMentions(ctx context.Context, messageID string, spans []models.Mention) ([]models.Mention, error)
+	// MarkRead resets a participant's unread count and records read receipts
+	// for the messages they had not read, returning those messages' IDs.
+	// ErrNotFound if they are not a participant.
+	MarkRead(ctx context.Context, dmID, userID string) ([]string, error)
+	// MarkDelivered records that messages of dmID reached userID, skipping
+	// their own messages and ones already delivered. It returns the IDs newly
+	// marked; none if userID is not a participant.
+	MarkDelivered(ctx context.Context, dmID, userID string, messageIDs []string) ([]string, error)
+	// SetArchived archives or unarchives a conversation for one participant;
+	// ErrNotFound if they are not a participant.
+	SetArchived(ctx context.Context, dmID, userID string, archived bool) error
+	// SetMuted mutes or unmutes a conversation for one participant;
+	// ErrNotFound if they are not a participant.
+	SetMuted(ctx context.Context, dmID, userID string, muted bool) error
+	// SetMessageTTL sets how long new messages in a conversation live; 0
+	// keeps them. ErrNotFound if userID is not a participant.
+	SetMessageTTL(ctx context.Context, dmID, userID string, ttl time.Duration) error
+	// DeleteExpiredMessages deletes up to limit messages past their expiry,
+	// along with their attachments and queued offline events, and returns
+	// their IDs keyed by conversation ID. The attachments' files are queued
+	// in the orphan blob store.
+	DeleteExpiredMessages(ctx context.Context, limit int) (map[string][]string, error)
+	// ScheduleMessage stores a message to be sent at its SendAt; ErrNotFound
+	// if the sender is not a participant or the parent message is missing.
+	ScheduleMessage(ctx context.Context, m *models.ScheduledDMMessage) (*models.ScheduledDMMessage, error)
+	// GetScheduledMessages lists senderID's unsent scheduled messages, in
+	// dmID only when it is set, soonest first.
+	GetScheduledMessages(ctx context.Context, senderID, dmID string) ([]models.ScheduledDMMessage, error)
+	// CancelScheduledMessage returns ErrNotFound if senderID has no such
+	// scheduled message.
+	CancelScheduledMessage(ctx context.Context, id, senderID string) error
+	// SendScheduledMessage sends the longest-due scheduled message and
+	// returns it with the message it became, which is nil if the sender left
+	// the conversation. It returns nils when nothing is due.
+	SendScheduledMessage(ctx context.Context) (*models.ScheduledDMMessage, *models.DMMessage, error)
+	// GetDraft returns userID's draft in dmID, empty if there is none;
+	// ErrNotFound if they are not a participant.
+	GetDraft(ctx context.Context, dmID, userID string) (*models.DMDraft, error)
+	// SaveDraft replaces userID's draft in dmID, deleting it when content is
+	// empty; ErrNotFound if they are not a participant.
+	SaveDraft(ctx context.Context, dmID, userID, content string) (*models.DMDraft, error)
+	// AddConversationKeys stores version keyVersion of dmID's key, wrapped by
+	// senderID for the UserID and DeviceID of each of keys. ErrForbidden
+	// unless the sender and every recipient are participants; ErrConflict if
+	// a device already has that version.
+	AddConversationKeys(ctx context.Context, dmID, senderID string, keyVersion int, keys []models.DMConversationKey) ([]models.DMConversationKey, error)
+	// GetConversationKeys returns the keys wrapped for one device of userID,
+	// newest version first.
+	GetConversationKeys(ctx context.Context, dmID, userID, deviceID string) ([]models.DMConversationKey, error)
+	GetUnreadTotal(ctx context.Context, userID string) (int, error)
+}
+
+// UserStore persists user accounts.
+type UserStore interface {
+	// CreateUser returns ErrConflict if the email or username is already
+	// registered. An empty username leaves it unset.
+	CreateUser(ctx context.Context, displayName, username, email, passwordHash string) (*models.User, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	// GetUsers returns the users that exist among userIDs, keyed by ID.
+	GetUsers(ctx context.Context, userIDs []string) (map[string]*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdateDisplayName(ctx context.Context, userID, displayName string) (*models.User, error)
+	// GetUserByUsername returns ErrNotFound if no user has username.
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// SetUsername returns ErrConflict if another user has username.
+	SetUsername(ctx context.Context, userID, username string) (*models.User, error)
+	// SearchUsers prefix-matches usernames and display names, username matches first.
+	SearchUsers(ctx context.Context, prefix string, limit int) ([]*models.User, error)
+	// Follow returns ErrNotFound if the followee does not exist and
+	// ErrConflict if the follow already exists. Unfollow returns ErrNotFound
+	// if there is no such follow.
+	Follow(ctx context.Context, followerID, followeeID string) error
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	// GetFollowing returns the users userID follows, most recently followed first.
+	GetFollowing(ctx context.Context, userID string) ([]*models.User, error)
+	// GetSettings returns the user's settings, defaults included, and when
+	// they were saved (nil if never). SaveSettings replaces them. Both return
+	// ErrNotFound if the user does not exist.
+	GetSettings(ctx context.Context, userID string) (*models.UserSettings, *time.Time, error)
+	SaveSettings(ctx context.Context, userID string, settings models.UserSettings) (time.Time, error)
+	// SetAvatar returns the updated user and the replaced avatar key, empty if none.
+	SetAvatar(ctx context.Context, userID, key string) (*models.User, string, error)
+	TouchLastSeen(ctx context.Context, userID string, at time.Time) error
+	// GetLastSeen returns last-seen times keyed by user ID; users never seen are omitted.
+	GetLastSeen(ctx context.Context, userIDs []string) (map[string]time.Time, error)
+	// SavePublicKey registers or replaces the public key of one of a user's devices.
+	SavePublicKey(ctx context.Context, key models.PublicKey) (*models.PublicKey, error)
+	// GetPublicKeys returns the public keys of every device of userID.
+	GetPublicKeys(ctx context.Context, userID string) ([]models.PublicKey, error)
+	// DeletePublicKey returns ErrNotFound if the device has no key.
+	DeletePublicKey(ctx context.Context, userID, deviceID string) error
+}
+
+// WorkspaceStore persists workspaces and their members.
+type WorkspaceStore interface {
+	// CreateWorkspace creates a workspace with ownerID as its owner and first member.
+	CreateWorkspace(ctx context.Context, name, ownerID string) (*models.Workspace, error)
+	// GetWorkspace returns ErrNotFound if the workspace does not exist.
+	GetWorkspace(ctx context.Context, workspaceID string) (*models.Workspace, error)
+	// GetWorkspaces returns the workspaces userID is a member of, oldest first.
+	GetWorkspaces(ctx context.Context, userID string) ([]*models.Workspace, error)
+	// GetMembers returns a workspace's members, earliest to join first.
+	GetMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMembership, error)
+	// GetMemberRole returns ErrNotFound if userID is not a member of the workspace.
+	GetMemberRole(ctx context.Context, workspaceID, userID string) (models.WorkspaceRole, error)
+	// AddMember returns ErrNotFound if the workspace does not exist and
+	// ErrConflict if the user is already a member.
+	AddMember(ctx context.Context, workspaceID, userID string, role models.WorkspaceRole) (*models.WorkspaceMembership, error)
+	// RemoveMember also takes the user out of the workspace's scenes and
+	// group conversations, and returns the rooms they could reach until now.
+	// It returns ErrNotFound if the user is not a member and ErrForbidden for
+	// the owner, who cannot leave their workspace.
+	RemoveMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceRooms, error)
+}
+
+// SceneVisible reports whether userID may see scene. Scenes outside
+// workspaces are visible to anyone, including callers who give no user ID;
+// workspace scenes only to the workspace's members.
+func SceneVisible(ctx context.Context, workspaces WorkspaceStore, scene *models.Scene, userID string) (bool, error) {
+	if scene.WorkspaceID == "" {
+		return true, nil
+	}
+	if userID == "" {
+		return false, nil
+	}
+	_, err := workspaces.GetMemberRole(ctx, scene.WorkspaceID, userID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ConversationVisible reports whether userID may read conv: they must be one
+// of its participants and, for workspace conversations, still a member of the
+// workspace, since leaving it keeps them in its one-to-one conversations.
+func ConversationVisible(ctx context.Context, workspaces WorkspaceStore, conv *models.DMConversation, userID string) (bool, error) {
+	if userID == "" || !slices.Contains(conv.Participants, userID) {
+		return false, nil
+	}
+	if conv.WorkspaceID == "" {
+		return true, nil
+	}
+	_, err := workspaces.GetMemberRole(ctx, conv.WorkspaceID, userID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TranscriptStore tracks scene transcript exports generated in the background.
+type TranscriptStore interface {
+	CountSceneMessages(ctx context.Context, sceneID string) (int, error)
+	CreateTranscriptExport(ctx context.Context, sceneID, requestedBy string, format models.TranscriptFormat) (*models.TranscriptExport, error)
+	// GetTranscriptExport returns ErrNotFound if there is no such export.
+	GetTranscriptExport(ctx context.Context, id string) (*models.TranscriptExport, error)
+	// ClaimTranscriptExport marks the oldest waiting export running and
+	// returns it; nil if there is none.
+	ClaimTranscriptExport(ctx context.Context) (*models.TranscriptExport, error)
+	// CompleteTranscriptExport marks an export ready with its file at
+	// blobKey, or failed when exportErr is set.
+	CompleteTranscriptExport(ctx context.Context, id, blobKey, exportErr string) (*models.TranscriptExport, error)
+}
+
+// TicketStore tracks the tickets bought for ticketed scenes.
+type TicketStore interface {
+	// CreateTicket records a pending ticket for a checkout session.
+	CreateTicket(ctx context.Context, ticket *models.SceneTicket) (*models.SceneTicket, error)
+	// GetTicket returns the user's most recent ticket for a scene, or
+	// ErrNotFound if they never started a checkout.
+	GetTicket(ctx context.Context, sceneID, userID string) (*models.SceneTicket, error)
+	// HasPaidTicket reports whether the user holds a paid ticket for a scene.
+	HasPaidTicket(ctx context.Context, sceneID, userID string) (bool, error)
+	// ResolveCheckout moves the pending ticket of a checkout session to
+	// status, recording its payment intent. It returns ErrNotFound if there
+	// is no such session and ErrConflict if the ticket is no longer pending.
+	ResolveCheckout(ctx context.Context, sessionID, paymentIntentID string, status models.TicketStatus) (*models.SceneTicket, error)
+	// RefundTicket marks the paid ticket of a payment intent refunded. It
+	// returns ErrNotFound if no paid ticket has that payment intent.
+	RefundTicket(ctx context.Context, paymentIntentID string) (*models.SceneTicket, error)
+}
+
+// GiftStore keeps the gifts ledger and users' credit balances.
+type GiftStore interface {
+	// SendGiftWithCredits deducts the gift's amount from the sender's credit
+	// balance and records the gift completed. It returns ErrConflict if the
+	// balance is too low.
+	SendGiftWithCredits(ctx context.Context, gift *models.Gift) (*models.Gift, error)
+	// CreateGiftCheckout records a pending gift paid through a Stripe checkout session.
+	CreateGiftCheckout(ctx context.Context, gift *models.Gift) (*models.Gift, error)
+	// ResolveGiftCheckout moves the pending gift of a checkout session to
+	// status, recording its payment intent. It returns ErrNotFound if there
+	// is no such session and ErrConflict if the gift is no longer pending.
+	ResolveGiftCheckout(ctx context.Context, sessionID, paymentIntentID string, status models.GiftStatus) (*models.Gift, error)
+	// RefundGift marks the completed gift of a payment intent refunded. It
+	// returns ErrNotFound if no completed gift has that payment intent.
+	RefundGift(ctx context.Context, paymentIntentID string) (*models.Gift, error)
+	// GetCreditBalance returns a user's credit balance in cents; 0 if they never had credits.
+	GetCreditBalance(ctx context.Context, userID string) (int, error)
+	// AddCredits adds amountCents to a user's balance and returns the new balance.
+	AddCredits(ctx context.Context, userID string, amountCents int) (int, error)
+	// GetSceneEarnings totals the completed gifts sent in a scene, listing
+	// at most topSenders senders.
+	GetSceneEarnings(ctx context.Context, sceneID string, topSenders int) (*models.SceneEarnings, error)
+}
+
+// BadgeStore persists the badges users earn and the facts the achievement
+// rules check.
+type BadgeStore interface {
+	// AwardBadge records that userID earned badge, through sceneID if it is
+	// set. It reports false if the user already had the badge.
+	AwardBadge(ctx context.Context, userID string, badge models.Badge, sceneID string) (bool, error)
+	// HasBadge reports whether userID has earned badge.
+	HasBadge(ctx context.Context, userID string, badge models.Badge) (bool, error)
+	// GetUserBadges returns the badges userID earned, oldest first.
+	GetUserBadges(ctx context.Context, userID string) ([]models.UserBadge, error)
+	// CountSceneListeners returns how many different users have listened to sceneID.
+	CountSceneListeners(ctx context.Context, sceneID string) (int, error)
+	// ListeningStreak returns the number of consecutive UTC days, ending on
+	// the day of at, on which userID joined a scene.
+	ListeningStreak(ctx context.Context, userID string, at time.Time) (int, error)
+}
+
+// PlaybackStore persists the shared player state of each scene.
+type PlaybackStore interface {
+	// GetPlayback returns ErrNotFound if the scene has no playback state yet.
+	GetPlayback(ctx context.Context, sceneID string) (*models.PlaybackState, error)
+	// SetPlayback records the track in the scene's play history when it changes.
+	SetPlayback(ctx context.Context, state *models.PlaybackState) (*models.PlaybackState, error)
+	// AddSkipVote records userID's vote to skip trackID and returns the number
+	// of votes the track now has. It returns ErrConflict if the user already voted.
+	AddSkipVote(ctx context.Context, sceneID, trackID, userID string) (int, error)
+	// SkipTrack replaces trackID with the first queued track, removing it from
+	// the queue, or stops playback if the queue is empty. It returns the new
+	// state and the track that was started, and ErrConflict if trackID is no
+	// longer playing.
+	SkipTrack(ctx context.Context, sceneID, trackID, updatedBy string) (*models.PlaybackState, *models.QueueItem, error)
+	// GetPlayHistory lists every track started in a scene, oldest first.
+	GetPlayHistory(ctx context.Context, sceneID string) ([]models.PlayedTrack, error)
+}
+
+// SpotifyTokenStore persists users' linked Spotify credentials and the
+// playlists scenes were exported to.
+type SpotifyTokenStore interface {
+	SaveSpotifyToken(ctx context.Context, token *models.SpotifyToken) error
+	// GetSpotifyToken returns ErrNotFound if the user has not linked Spotify.
+	GetSpotifyToken(ctx context.Context, userID string) (*models.SpotifyToken, error)
+	DeleteSpotifyToken(ctx context.Context, userID string) error
+	// GetScenePlaylist returns ErrNotFound if the scene was never exported.
+	GetScenePlaylist(ctx context.Context, sceneID string) (*models.SpotifyPlaylist, error)
+	// SaveScenePlaylist inserts or replaces a scene's playlist mapping.
+	SaveScenePlaylist(ctx context.Context, playlist *models.SpotifyPlaylist) (*models.SpotifyPlaylist, error)
+}
+
+// AttachmentStore persists uploaded file metadata and links files to messages.
+type AttachmentStore interface {
+	CreateAttachment(ctx context.Context, att *models.Attachment) (*models.Attachment, error)
+	// GetAttachment returns ErrNotFound if the attachment does not exist.
+	GetAttachment(ctx context.Context, attachmentID string) (*models.Attachment, error)
+	// CountUnlinked returns how many of ids are attachments uploaded by
+	// uploaderID that are not yet linked to a message.
+	CountUnlinked(ctx context.Context, uploaderID string, ids []string) (int, error)
+	// LinkToDMMessage and LinkToSceneMessage attach ids to a message. They
+	// return ErrConflict, linking nothing, unless every id is an unlinked
+	// attachment uploaded by uploaderID.
+	LinkToDMMessage(ctx context.Context, messageID, uploaderID string, ids []string) ([]models.Attachment, error)
+	LinkToSceneMessage(ctx context.Context, messageID, uploaderID string, ids []string) ([]models.Attachment, error)
+}
+
+// OrphanBlobStore queues stored files whose rows were deleted. The other
+// stores queue a file in the same transaction as the delete, so the file is
+// removed even if the server stops before it gets to it.
+type OrphanBlobStore interface {
+	// PendingOrphanBlobs returns up to limit queued files, oldest first.
+	PendingOrphanBlobs(ctx context.Context, limit int) ([]models.OrphanBlob, error)
+	// ForgetOrphanBlobs removes the files with the given IDs from the queue.
+	ForgetOrphanBlobs(ctx context.Context, ids []int64) error
+}
+
+// ModerationStore persists content filter flags and user reports.
+type ModerationStore interface {
+	FlagMessage(ctx context.Context, flag *models.MessageFlag) error
+	// CreateReport returns ErrNotFound if the reported target does not exist
+	// and ErrConflict if the reporter already has an open report on it.
+	CreateReport(ctx context.Context, report *models.Report) (*models.Report, error)
+	// GetReport returns ErrNotFound if the report does not exist.
+	GetReport(ctx context.Context, reportID string) (*models.Report, error)
+	// ListReports returns reports with the given status, oldest first; an
+	// empty status lists every report.
+	ListReports(ctx context.Context, status models.ReportStatus, limit int) ([]models.Report, error)
+	// ResolveReport closes an open report. It returns ErrNotFound if the
+	// report does not exist and ErrConflict if it was already resolved.
+	ResolveReport(ctx context.Context, reportID, resolvedBy string, status models.ReportStatus, note string) (*models.Report, error)
+}
+
+// AnalyticsStore records scene listening sessions and aggregates them.
+type AnalyticsStore interface {
+	// StartListening opens a session for userID in sceneID at at.
+	StartListening(ctx context.Context, sceneID, userID string, at time.Time) error
+	// StopListening closes userID's most recent open session in sceneID.
+	StopListening(ctx context.Context, sceneID, userID string, at time.Time) error
+	// GetSceneAnalytics aggregates the sessions overlapping [from, to).
+	GetSceneAnalytics(ctx context.Context, sceneID string, from, to time.Time, bucket models.AnalyticsBucket) (*models.SceneAnalytics, error)
+	// RecordConcurrency stores one sample of sceneID -> connected listeners,
+	// taken at at, and refreshes each scene's active_users. Scenes missing
+	// from counts are recorded as having no listeners.
+	RecordConcurrency(ctx context.Context, counts map[string]int, at time.Time) error
+	// GetSceneStats returns the daily concurrency stats of the last days
+	// days that have samples, newest first.
+	GetSceneStats(ctx context.Context, sceneID string, days int) ([]models.SceneDayStats, error)
+}
+
+// HashtagStore records the #hashtags used by scenes and scene chat.
+type HashtagStore interface {
+	// SetSceneHashtags replaces the hashtags taken from a scene's details.
+	SetSceneHashtags(ctx context.Context, sceneID string, tags []string) error
+	// AddMessageHashtags records the hashtags used in a scene chat message.
+	AddMessageHashtags(ctx context.Context, sceneID, messageID string, tags []string) error
+	// TrendingHashtags returns up to limit hashtags used since since, most used first.
+	TrendingHashtags(ctx context.Context, since time.Time, limit int) ([]models.TrendingTag, error)
+	// GetScenesByHashtag returns non-archived scenes using tag, most recently
+	// used first, skipping offset results.
+	GetScenesByHashtag(ctx context.Context, tag string, limit, offset int) ([]*models.Scene, error)
+}
+
+// RecommendationWeights are the points each signal adds to a scene's
+// recommendation score.
+type RecommendationWeights struct {
+	SharedTag       float64 // Per hashtag shared with scenes the user listened to
+	FollowedCreator float64 // When the user follows the scene's creator
+	HistoryHour     float64 // Per hour listened to the creator's scenes
+	MaxHistoryHours float64 // Cap on the hours counted per creator
+}
+
+// RecommendationStore computes and serves per-user scene recommendations.
+type RecommendationStore interface {
+	// RefreshRecommendations rebuilds every user's recommendations from
+	// listening since since and current follows, keeping each user's top
+	// perUser public scenes. It returns the number of recommendations stored.
+	RefreshRecommendations(ctx context.Context, weights RecommendationWeights, since time.Time, perUser int) (int64, error)
+	// GetRecommendations returns up to limit of userID's recommendations,
+	// best first, skipping scenes that have since become private or archived.
+	GetRecommendations(ctx context.Context, userID string, limit int) ([]models.RecommendedScene, error)
+}
+
+// ReputationWeights are the points each signal adds to or subtracts from a
+// user's reputation score.
+type ReputationWeights struct {
+	ListeningHour     float64 // Per hour listened
+	MaxListeningHours float64 // Cap on the hours counted
+	SceneHosted       float64 // Per scene created
+	MaxScenesHosted   float64 // Cap on the scenes counted
+	Message           float64 // Per scene chat message sent
+	MaxMessages       float64 // Cap on the messages counted
+	ActionedReport    float64 // Subtracted per report against the user that an admin acted on
+	OpenReport        float64 // Subtracted per report against the user awaiting review
+	SceneBan          float64 // Subtracted per scene that banned the user
+	SceneMute         float64 // Subtracted per scene that muted the user
+}
+
+// ReputationStore computes and serves per-user reputations.
+type ReputationStore interface {
+	// RefreshReputation rebuilds every user's reputation from participation
+	// since since and all reports and scene restrictions against them. It
+	// returns the number of users scored.
+	RefreshReputation(ctx context.Context, weights ReputationWeights, since time.Time) (int64, error)
+	// GetReputations returns the reputation of each of userIDs. Users the job
+	// has not scored get a zero reputation with a nil ComputedAt.
+	GetReputations(ctx context.Context, userIDs []string) (map[string]models.Reputation, error)
+}
+
+// LeaderboardStore aggregates and serves the leaderboards.
+type LeaderboardStore interface {
+	// RefreshLeaderboards rebuilds every board for both periods, counting
+	// activity since weekSince for the weekly ones and keeping the top size
+	// entries of each. It returns the number of entries stored.
+	RefreshLeaderboards(ctx context.Context, weekSince time.Time, size int) (int64, error)
+	// GetLeaderboard returns the stored entries of a board, best first.
+	// Scenes that have since become private are skipped.
+	GetLeaderboard(ctx context.Context, board models.LeaderboardBoard, period models.LeaderboardPeriod) (*models.Leaderboard, error)
+}
+
+// NotificationStore holds per-conversation notification overrides and
+// loads what the notification dispatcher needs to decide who is notified.
+// Overrides name a DM conversation or a scene; exactly one of dmID and
+// sceneID is set.
+type NotificationStore interface {
+	// GetNotificationPreferences returns the notification settings of each of
+	// userIDs with their override for the DM or scene, if any, and whether they
+	// muted the DM. Users who never saved settings get the defaults.
+	GetNotificationPreferences(ctx context.Context, userIDs []string, dmID, sceneID string) (map[string]models.NotificationPreferences, error)
+	// SetNotificationOverride sets userID's level for a DM or scene. It
+	// returns ErrNotFound if the scene does not exist or userID is not a
+	// participant of the DM.
+	SetNotificationOverride(ctx context.Context, userID, dmID, sceneID string, level models.NotificationLevel) (*models.NotificationOverride, error)
+	// DeleteNotificationOverride removes userID's override for a DM or scene,
+	// returning ErrNotFound if there is none.
+	DeleteNotificationOverride(ctx context.Context, userID, dmID, sceneID string) error
+	// GetNotificationOverrides returns userID's overrides, most recently changed first.
+	GetNotificationOverrides(ctx context.Context, userID string) ([]models.NotificationOverride, error)
+	// QueueNotification holds n for userID until their quiet hours end.
+	QueueNotification(ctx context.Context, userID string, n models.QueuedNotification) error
+	// GetQueuedNotificationUsers returns the users with held notifications.
+	GetQueuedNotificationUsers(ctx context.Context) ([]string, error)
+	// TakeQueuedNotifications deletes userID's held notifications and returns
+	// them oldest first.
+	TakeQueuedNotifications(ctx context.Context, userID string) ([]models.QueuedNotification, error)
+}
+
+// AdminStore backs operational tooling.
+type AdminStore interface {
+	// ListScenes returns scenes newest first, skipping archived ones unless includeArchived.
+	ListScenes(ctx context.Context, limit int, includeArchived bool) ([]*models.Scene, error)
+	// PurgeUser permanently deletes a user and everything they created. It
+	// returns ErrNotFound if there is nothing to delete.
+	PurgeUser(ctx context.Context, userID string) ([]models.TableRows, error)
+	Stats(ctx context.Context) (*models.PlatformStats, error)
+}
+
+// RetentionStore deletes data that has aged out of the retention policy.
+// Each call removes at most limit rows so large backlogs are cleared in
+// short transactions; callers repeat until fewer than limit are deleted.
+type RetentionStore interface {
+	// DeleteDMMessagesBefore deletes DM messages sent before cutoff, keeping
+	// a thread's root while it has newer replies.
+	DeleteDMMessagesBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	// DeleteStaleScenes deletes live scenes created before cutoff that nobody
+	// but the creator joined and that have had no chat or listeners since.
+	DeleteStaleScenes(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// OutboxStore reads the event outbox. Events are written by the other stores
+// in the same transaction as the change they describe.
+type OutboxStore interface {
+	// PendingEvents returns up to limit unpublished events, oldest first.
+	PendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	// MarkPublished stamps the events with the given IDs as published.
+	MarkPublished(ctx context.Context, ids []int64) error
+	// DeletePublishedBefore deletes up to limit events published before cutoff.
+	DeletePublishedBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// OfflineStore queues DM events for participants who were not connected to
+// the conversation when the events were sent.
+type OfflineStore interface {
+	// EnqueueDMEvent queues event, an encoded WebSocket envelope, for each of userIDs.
+	EnqueueDMEvent(ctx context.Context, dmID string, userIDs []string, event []byte) error
+	// TakeDMEvents deletes and returns the events queued for userID in dmID, oldest first.
+	TakeDMEvents(ctx context.Context, userID, dmID string) ([][]byte, error)
+	// DeleteDMEventsBefore deletes up to limit events queued before cutoff.
+	DeleteDMEventsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// Leader elects one instance of a multi-instance deployment to run background jobs.
+type Leader interface {
+	// IsLeader reports whether this instance holds leadership, trying to
+	// acquire it if nobody does.
+	IsLeader(ctx context.Context) (bool, error)
+	// Resign gives up leadership so another instance can take over.
+	Resign()
+}
+
+// WebhookStore persists webhooks and their delivery queue.
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	// GetWebhook returns ErrNotFound if the webhook does not exist.
+	GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context, ownerID string) ([]models.Webhook, error)
+	// DeleteWebhook returns ErrNotFound if the webhook does not exist.
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	// EnqueueEvent queues payload for every webhook subscribed to event,
+	// global ones and, when sceneID is set, that scene's. It returns how
+	// many deliveries were queued.
+	EnqueueEvent(ctx context.Context, eventID, event, sceneID string, payload []byte) (int, error)
+	// ClaimDeliveries returns up to limit due deliveries with their URL and
+	// secret, and pushes their next attempt back by lease so concurrent
+	// senders do not pick them up again while they are in flight.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	// RecordAttempt stores the outcome of an attempt. A nil retryAt marks the
+	// delivery delivered when err is empty and failed otherwise.
+	RecordAttempt(ctx context.Context, deliveryID string, statusCode *int, errMsg string, retryAt *time.Time) error
+	// ListDeliveries returns a webhook's most recent deliveries, newest first.
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]models.WebhookDelivery, error)
+}
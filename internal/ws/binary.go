@@ -0,0 +1,63 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackProtocol is the Sec-WebSocket-Protocol value that selects binary
+// frames: envelopes are sent and accepted as MessagePack maps with the same
+// keys as the JSON encoding, which saves bandwidth on high-frequency events
+// such as playback and reactions. Clients offer it alongside any token, e.g.
+// new WebSocket(url, ["scenyx.msgpack", "scenyx.token", token]). JSON stays
+// the default for clients that do not offer it.
+const MsgpackProtocol = "scenyx.msgpack"
+
+// Subprotocols lists the protocols upgraders should accept, in order of
+// preference.
+var Subprotocols = []string{MsgpackProtocol, TokenProtocol}
+
+// toMsgpack re-encodes a JSON frame as MessagePack. Integers stay integers
+// so that seq and ts do not turn into floats.
+func toMsgpack(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode JSON frame: %w", err)
+	}
+	return msgpack.Marshal(numbers(v))
+}
+
+// fromMsgpack re-encodes a MessagePack frame as JSON.
+func fromMsgpack(data []byte) ([]byte, error) {
+	var v any
+	if err := msgpack.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("decode MessagePack frame: %w", err)
+	}
+	return json.Marshal(v)
+}
+
+// numbers replaces the json.Numbers in v with int64 or float64 values.
+func numbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = numbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = numbers(e)
+		}
+	}
+	return v
+}
@@ -0,0 +1,18 @@
+package ws
+
+import "context"
+
+// Broker fans broadcasts out across backend instances. When a hub has a
+// broker, every broadcast is published to it and each instance delivers the
+// messages it receives back to its own locally connected clients.
+type Broker interface {
+	// Publish sends msg to every subscribed instance, including this one.
+	// DM and scene messages are given the next Seq of their channel, shared
+	// by all instances, and reach subscribers in Seq order.
+	Publish(ctx context.Context, msg BroadcastMessage) error
+	// Subscribe calls deliver for each message published by any instance.
+	// It blocks until ctx is cancelled or the subscription fails.
+	Subscribe(ctx context.Context, deliver func(BroadcastMessage)) error
+	// Close releases the broker's connections.
+	Close() error
+}
@@ -0,0 +1,48 @@
+package ws
+
+import "time"
+
+// OnSceneEmpty registers fn to be called once a scene has had no connections
+// on this instance for after. A connection to the scene in the meantime
+// cancels the call. It must be called before Run. fn runs on its own
+// goroutine so it may safely use the hub.
+//
+// With a broker, clients of the scene may still be connected to other
+// instances; fn should only take action that is safe in that case.
+func (h *Hub) OnSceneEmpty(after time.Duration, fn func(sceneID string)) {
+	h.emptyAfter = after
+	h.onEmpty = fn
+}
+
+// scheduleEmpty starts the countdown to onEmpty for a scene whose last
+// connection just closed. Callers must hold s.mu.
+func (s *shard) scheduleEmpty(sceneID string) {
+	h := s.hub
+	if h.onEmpty == nil {
+		return
+	}
+	s.cancelEmpty(sceneID)
+	var timer *time.Timer
+	timer = time.AfterFunc(h.emptyAfter, func() {
+		s.mu.Lock()
+		// A connection may have arrived while the timer fired
+		current := s.emptyTimers[sceneID] == timer && len(s.sceneUsers[sceneID]) == 0
+		if current {
+			delete(s.emptyTimers, sceneID)
+		}
+		s.mu.Unlock()
+		if current {
+			h.onEmpty(sceneID)
+		}
+	})
+	s.emptyTimers[sceneID] = timer
+}
+
+// cancelEmpty stops the countdown to onEmpty for a scene that has a
+// connection again. Callers must hold s.mu.
+func (s *shard) cancelEmpty(sceneID string) {
+	if timer, ok := s.emptyTimers[sceneID]; ok {
+		timer.Stop()
+		delete(s.emptyTimers, sceneID)
+	}
+}
@@ -0,0 +1,172 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EnvelopeVersion is the current version of the WebSocket message envelope.
+const EnvelopeVersion = 1
+
+// MessageType identifies the kind of event carried in an Envelope.
+type MessageType string
+
+// Message types sent over DM and scene sockets.
+const (
+	TypeChat                MessageType = "chat"                 // A new chat message (DM or scene)
+	TypeMessageUpdated      MessageType = "message.updated"      // A chat message was edited
+	TypeMessageDeleted      MessageType = "message.deleted"      // A chat message was deleted
+	TypeReaction            MessageType = "message.reaction"     // A reaction was added to or removed from a message
+	TypeMessagePinned       MessageType = "message.pinned"       // A scene message was pinned or unpinned
+	TypePresence            MessageType = "presence"             // A user's presence changed
+	TypePlayback            MessageType = "playback"             // Scene playback state changed
+	TypeTyping              MessageType = "typing"               // A user started or stopped typing
+	TypeQueue               MessageType = "queue"                // Scene track queue or its votes changed
+	TypeSceneUpdated        MessageType = "scene.updated"        // Scene details (name, artist, cover) changed
+	TypeSceneArchived       MessageType = "scene.archived"       // Scene was archived or restored
+	TypeSceneClosed         MessageType = "scene.closed"         // Scene was closed; the server then closes every connection to it
+	TypeSceneEnding         MessageType = "scene.ending"         // Scene reaches its maximum duration soon and will then be closed
+	TypeTicketUpdated       MessageType = "ticket.updated"       // The user's ticket to a scene was paid, failed, or refunded
+	TypeGiftReceived        MessageType = "gift.received"        // A tip or gift to the host landed; clients play the animation named by its kind
+	TypeBadgeAwarded        MessageType = "badge.awarded"        // Sent to a user when they earn a badge
+	TypeSceneReminder       MessageType = "scene.reminder"       // A scheduled scene the user RSVP'd to starts soon
+	TypeSceneLive           MessageType = "scene.live"           // A scheduled scene reached its start time
+	TypeListenerJoined      MessageType = "listener.joined"      // A user opened their first connection to the scene
+	TypeListenerLeft        MessageType = "listener.left"        // A user closed their last connection to the scene
+	TypeResync              MessageType = "resync"               // Missed events could not be replayed; refetch history
+	TypeJoinRequested       MessageType = "join.requested"       // Sent to a scene's creator when a user asks to join
+	TypeJoinResolved        MessageType = "join.resolved"        // Sent to a user when their join request is approved or rejected
+	TypeRoleChanged         MessageType = "role.changed"         // A user was promoted to co-host or demoted to listener
+	TypePoll                MessageType = "poll"                 // A scene poll was created, voted on, or closed
+	TypeTrackSkipped        MessageType = "track.skipped"        // Listeners voted the current scene track off
+	TypeNowPlaying          MessageType = "now_playing"          // Scene playback moved on to a new track
+	TypeMention             MessageType = "mention"              // Sent to a user when a DM or scene message @mentions them
+	TypeDMReceived          MessageType = "dm.received"          // Sent to a user's connections when a message arrives in one of their DMs
+	TypeNotificationSummary MessageType = "notification.summary" // Sent to a user when their quiet hours end, listing the notifications held back
+	TypeDelivered           MessageType = "message.delivered"    // Sent by a DM client to acknowledge messages it received; recorded, not relayed
+	TypeReceipt             MessageType = "message.receipt"      // DM messages were delivered to or read by a participant
+	TypeMessageExpired      MessageType = "message.expired"      // DM messages reached the end of their conversation's TTL and were deleted
+	TypeMessageTTL          MessageType = "dm.message_ttl"       // A participant changed how long new messages in a DM live
+	TypeDraft               MessageType = "dm.draft"             // Sent to a user's connections when they save a DM draft on any device
+	TypeConversationKey     MessageType = "dm.key"               // A participant distributed a new version of a DM's end-to-end encryption key
+	TypeTranscriptReady     MessageType = "transcript.ready"     // Sent to a scene's host when their transcript export is ready or failed
+)
+
+// Envelope is the JSON frame every WebSocket message is wrapped in, e.g.
+// {"v":1,"id":"...","type":"chat","payload":{...},"ts":1700000000000}.
+type Envelope struct {
+	Version int             `json:"v"`             // Envelope version, currently EnvelopeVersion
+	ID      string          `json:"id,omitempty"`  // Event ID clients pass back as last_event_id; empty on relayed client frames
+	Seq     int64           `json:"seq,omitempty"` // Consecutive per DM or scene, so clients can spot gaps; absent if unordered
+	Type    MessageType     `json:"type"`          // Event type used by clients to dispatch
+	Payload json.RawMessage `json:"payload"`       // Type-specific body
+	TS      int64           `json:"ts"`            // Server timestamp in Unix milliseconds
+}
+
+// Encode wraps payload in an Envelope of type t and returns its JSON encoding.
+func Encode(t MessageType, payload any) ([]byte, error) {
+	_, data, err := encode(t, payload)
+	return data, err
+}
+
+// encode is Encode that also returns the new event's ID.
+func encode(t MessageType, payload any) (string, []byte, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode %s payload: %w", t, err)
+	}
+	id := uuid.NewString()
+	data, err := json.Marshal(Envelope{
+		Version: EnvelopeVersion,
+		ID:      id,
+		Type:    t,
+		Payload: raw,
+		TS:      time.Now().UnixMilli(),
+	})
+	return id, data, err
+}
+
+// withSeq adds seq to an encoded envelope. Envelopes are encoded before
+// their sequence number is known, so it is spliced in as the first field.
+func withSeq(data []byte, seq int64) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	out := append([]byte(`{"seq":`), strconv.FormatInt(seq, 10)...)
+	if data[1] != '}' {
+		out = append(out, ',')
+	}
+	return append(out, data[1:]...)
+}
+
+// Decode parses an Envelope, rejecting frames without a type or with an
+// unsupported version.
+func Decode(data []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("decode envelope: %w", err)
+	}
+	if env.Type == "" {
+		return nil, errors.New("decode envelope: missing type")
+	}
+	if env.Version != EnvelopeVersion {
+		return nil, fmt.Errorf("decode envelope: unsupported version %d", env.Version)
+	}
+	return &env, nil
+}
+
+// DecodePayload unmarshals the envelope's payload into v.
+func (e *Envelope) DecodePayload(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// SendToDM encodes payload as a t envelope and broadcasts it to a DM's clients.
+func (h *Hub) SendToDM(dmID string, t MessageType, payload any) {
+	id, data, err := encode(t, payload)
+	if err != nil {
+		log.Printf("Failed to encode %s event for DM %s: %v", t, dmID, err)
+		return
+	}
+	h.notifyDMEvent(dmID, t, data)
+	h.Broadcast(BroadcastMessage{DMID: dmID, EventID: id, Data: data})
+}
+
+// SendToScene encodes payload as a t envelope and broadcasts it to a scene's clients.
+func (h *Hub) SendToScene(sceneID string, t MessageType, payload any) {
+	id, data, err := encode(t, payload)
+	if err != nil {
+		log.Printf("Failed to encode %s event for scene %s: %v", t, sceneID, err)
+		return
+	}
+	h.Broadcast(BroadcastMessage{SceneID: sceneID, EventID: id, Data: data})
+}
+
+// CloseScene broadcasts payload as a scene.closed event to a scene's clients
+// on every instance. Each instance lets its clients' write pumps flush the
+// event and anything queued before it, then closes their connections with a
+// normal closure.
+func (h *Hub) CloseScene(sceneID string, payload any) {
+	id, data, err := encode(TypeSceneClosed, payload)
+	if err != nil {
+		log.Printf("Failed to encode %s event for scene %s: %v", TypeSceneClosed, sceneID, err)
+		return
+	}
+	h.Broadcast(BroadcastMessage{SceneID: sceneID, EventID: id, Data: data, Close: true})
+}
+
+// SendToUser encodes payload as a t envelope and delivers it to every
+// connection userID has open, whichever DM or scene it belongs to.
+func (h *Hub) SendToUser(userID string, t MessageType, payload any) {
+	data, err := Encode(t, payload)
+	if err != nil {
+		log.Printf("Failed to encode %s event for user %s: %v", t, userID, err)
+		return
+	}
+	h.Broadcast(BroadcastMessage{UserID: userID, Data: data})
+}
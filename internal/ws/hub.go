@@ -1,8 +1,12 @@
 package ws
 
 import (
-	"log"  // For logging messages
+	"context" // For broker publish/subscribe calls
+	"log"     // For logging messages
+	"sort"    // For ordering active user IDs
 	"sync" // For RWMutex to handle concurrent access
+	"sync/atomic" // For the hub's counters
+	"time" // For close frame write deadlines
 
 	"github.com/gorilla/websocket" // WebSocket library
 )
@@ -12,134 +16,326 @@ type Client struct {
 	UserID string // ID of the user connected
 	DMID   string // ID of the DM conversation this client is connected to (if any)
 	SceneID string // ID of the Scene this client is connected to (if any)
-	Send   chan []byte       // Buffered channel for outgoing messages
 	Conn   *websocket.Conn   // The WebSocket connection
+	IP     string            // Remote address, see Hub.RemoteIP; counted against ConnLimits.PerIP
+	LastEventID string       // ID of the last event received before reconnecting; missed events are replayed on register
+	binary      bool         // Whether the client negotiated MsgpackProtocol; set by Hub.Serve
+	queue       *sendQueue   // Outgoing messages; created by Hub.Serve
+	connectedAt time.Time    // When the client registered
 }
 
 // Hub maintains the set of active clients and broadcasts messages to them.
+// Its DMs and scenes are spread over shards, each with its own worker; see shard.
 type Hub struct {
-	mu         sync.RWMutex                      // Read-write mutex for concurrent access to client maps
-	DMClients  map[string]map[*Client]bool       // dmID -> clients connected to that DM
-	SceneClients map[string]map[*Client]bool     // sceneID -> clients connected to that Scene
-	Register   chan *Client                      // Channel for clients to register with the hub
-	Unregister chan *Client                      // Channel for clients to unregister from the hub
-	Broadcast  chan BroadcastMessage             // Channel for broadcasting messages
+	mu     sync.RWMutex // Guards the user and connection maps below
+	shards []*shard     // Workers owning the DMs and scenes, see shardFor
+	broker Broker       // Optional cross-instance transport; nil means broadcasts stay in-process
+
+	userClients map[string]map[*Client]bool // userID -> all of that user's connections
+	userStatus  map[string]PresenceStatus   // userID -> online/away for connected users
+	onPresence  func(Presence)              // Optional listener for presence changes
+	onListener  func(ListenerEvent)         // Optional listener for scene joins and leaves
+	onDMEvent   func(DMEvent)               // Optional listener for events sent to DMs
+	onDMConnect func(*Client)               // Optional listener for new DM connections
+	onEmpty     func(string)                // Optional callback for scenes left without connections, see OnSceneEmpty
+	emptyAfter  time.Duration               // How long a scene must stay empty before onEmpty is called
+	pump        PumpConfig                  // Deadlines and keepalive for clients started with Serve
+	limits      ConnLimits                  // Connection caps enforced by Serve
+	userConns   map[string]int              // userID -> connections admitted by Serve
+	ipConns     map[string]int              // remote IP -> connections admitted by Serve
+
+	sent    atomic.Uint64 // Messages queued for clients, see Stats
+	dropped atomic.Uint64 // Messages discarded under OverflowDropOldest
+	evicted atomic.Uint64 // Clients evicted under OverflowDisconnect
+	rates   rateSampler   // Samples of the broadcast count for Stats.BroadcastRate
 }
 
-// BroadcastMessage contains the target ID (DM or Scene) and the data to broadcast.
+// BroadcastMessage contains the target ID (DM, Scene, or user) and the data to broadcast.
 type BroadcastMessage struct {
-	DMID    string // DM ID for DM messages
-	SceneID string // Scene ID for Scene messages
-	Data    []byte // The actual message data
+	DMID    string `json:"dm_id,omitempty"`    // DM ID for DM messages
+	SceneID string `json:"scene_id,omitempty"` // Scene ID for Scene messages
+	UserID  string `json:"user_id,omitempty"`  // User ID for notifications sent to all of a user's connections
+	EventID string `json:"event_id,omitempty"` // Envelope ID of DM and Scene events, recorded for replay
+	Seq     int64  `json:"seq,omitempty"`      // Position in the DM's or Scene's event stream, assigned when published
+	Data    []byte `json:"data"`               // The actual message data
+	Close   bool   `json:"close,omitempty"`    // Close the Scene's connections once Data is written, see CloseScene
 }
 
-// NewHub creates and returns a new instance of Hub.
+// NewHub creates and returns a new instance of Hub with DefaultShards workers.
 func NewHub() *Hub {
-	return &Hub{
-		DMClients:    make(map[string]map[*Client]bool),
-		SceneClients: make(map[string]map[*Client]bool),
-		Register:     make(chan *Client),
-		Unregister:   make(chan *Client),
-		Broadcast:    make(chan BroadcastMessage),
+	h := &Hub{
+		userClients: make(map[string]map[*Client]bool),
+		userStatus:  make(map[string]PresenceStatus),
+		pump:        DefaultPumpConfig,
+		limits:      DefaultConnLimits,
+		userConns:   make(map[string]int),
+		ipConns:     make(map[string]int),
 	}
+	h.UseShards(DefaultShards)
+	return h
 }
 
-// Run starts the hub's event loop, processing client registrations, unregistrations, and broadcasts.
+// UseBroker routes broadcasts through b so they reach clients connected to
+// other backend instances. It must be called before Run.
+func (h *Hub) UseBroker(b Broker) {
+	h.broker = b
+}
+
+// Run starts the hub's shard workers, which process client registrations,
+// unregistrations, and broadcasts. It does not return.
 func (h *Hub) Run() {
+	if h.broker != nil {
+		go h.consumeBroker()
+	}
+	go h.sampleRates()
+
+	var wg sync.WaitGroup
+	for _, s := range h.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.run()
+		}()
+	}
+	wg.Wait()
+}
+
+// consumeBroker forwards messages received from the broker to the workers
+// of their DMs and scenes.
+func (h *Hub) consumeBroker() {
+	err := h.broker.Subscribe(context.Background(), func(msg BroadcastMessage) {
+		key := "user:" + msg.UserID
+		if msg.DMID != "" || msg.SceneID != "" {
+			key = channelKey(msg.DMID, msg.SceneID)
+		}
+		h.shardFor(key).inbound <- msg
+	})
+	if err != nil {
+		log.Printf("Broker subscription ended: %v", err)
+	}
+}
+
+// deliver sends a broadcast message to the clients connected to this instance.
+func (s *shard) deliver(msg BroadcastMessage) {
+	if msg.Seq > 0 {
+		msg.Data = withSeq(msg.Data, msg.Seq)
+	}
+	if msg.EventID != "" && (msg.DMID != "" || msg.SceneID != "") {
+		s.replays.record(channelKey(msg.DMID, msg.SceneID), msg.EventID, msg.Data, time.Now())
+	}
+
+	// Clients that cannot keep up are handled by their queues' overflow
+	// policy; see Hub.send
+	h := s.hub
+	s.broadcasts.Add(1)
+	s.mu.RLock() // Acquire a read lock
+	if msg.DMID != "" {
+		for client := range s.dmClients[msg.DMID] {
+			h.send(client, msg.Data)
+		}
+	}
+	var closing []*Client
+	if msg.SceneID != "" {
+		for client := range s.sceneClients[msg.SceneID] {
+			if msg.Close {
+				closing = append(closing, client)
+			}
+			h.send(client, msg.Data)
+		}
+	}
+	s.mu.RUnlock() // Release the lock
+
+	if msg.UserID != "" {
+		h.mu.RLock()
+		for client := range h.userClients[msg.UserID] {
+			h.send(client, msg.Data)
+		}
+		h.mu.RUnlock()
+	}
+
+	if len(closing) > 0 {
+		go h.closeWhenDrained(msg.SceneID, closing)
+	}
+}
+
+// closeWhenDrained gives the write pumps of a closed scene's clients up to
+// one write deadline to flush their queues, then closes the connections.
+func (h *Hub) closeWhenDrained(sceneID string, clients []*Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.pump.WriteWait)
+	defer cancel()
+	drain(ctx, clients)
+
+	closeClients(clients, websocket.CloseNormalClosure, "scene closed")
+	log.Printf("Closed %d connection(s) to Scene %s: scene closed", len(clients), sceneID)
+}
+
+// DisconnectSceneUser sends a close frame with reason to every connection
+// userID has open in sceneID on this instance and closes them. The read pumps
+// then unregister the clients as usual. It returns the number of connections closed.
+func (h *Hub) DisconnectSceneUser(sceneID, userID, reason string) int {
+	n := h.closeSceneClients(sceneID, reason, func(c *Client) bool { return c.UserID == userID })
+	if n > 0 {
+		log.Printf("Disconnected %d connection(s) of user %s from Scene %s: %s", n, userID, sceneID, reason)
+	}
+	return n
+}
+
+// DisconnectDMUser sends a close frame with reason to every connection userID
+// has open in dmID on this instance and closes them, like DisconnectSceneUser.
+func (h *Hub) DisconnectDMUser(dmID, userID, reason string) int {
+	s := h.shardFor(channelKey(dmID, ""))
+	s.mu.RLock()
+	var targets []*Client
+	for client := range s.dmClients[dmID] {
+		if client.UserID == userID {
+			targets = append(targets, client)
+		}
+	}
+	s.mu.RUnlock()
+
+	closeClients(targets, websocket.ClosePolicyViolation, reason)
+	if len(targets) > 0 {
+		log.Printf("Disconnected %d connection(s) of user %s from DM %s: %s", len(targets), userID, dmID, reason)
+	}
+	return len(targets)
+}
+
+// DisconnectUser sends a close frame with reason to each connection userID
+// has open on this instance that match accepts and closes them, like
+// DisconnectSceneUser. It returns the number of connections closed.
+func (h *Hub) DisconnectUser(userID, reason string, match func(*Client) bool) int {
+	h.mu.RLock()
+	var targets []*Client
+	for client := range h.userClients[userID] {
+		if match(client) {
+			targets = append(targets, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	closeClients(targets, websocket.ClosePolicyViolation, reason)
+	if len(targets) > 0 {
+		log.Printf("Disconnected %d connection(s) of user %s: %s", len(targets), userID, reason)
+	}
+	return len(targets)
+}
+
+// closeSceneClients sends a close frame to and closes each scene client accepted by match.
+func (h *Hub) closeSceneClients(sceneID, reason string, match func(*Client) bool) int {
+	s := h.shardFor(channelKey("", sceneID))
+	s.mu.RLock()
+	var targets []*Client
+	for client := range s.sceneClients[sceneID] {
+		if match(client) {
+			targets = append(targets, client)
+		}
+	}
+	s.mu.RUnlock()
+
+	closeClients(targets, websocket.ClosePolicyViolation, reason)
+	return len(targets)
+}
+
+// closeClients sends each client a close frame with code and reason and
+// closes the connection. The read pumps then unregister them as usual.
+func closeClients(clients []*Client, code int, reason string) {
+	msg := websocket.FormatCloseMessage(code, reason)
+	for _, client := range clients {
+		client.Conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		client.Conn.Close()
+	}
+}
+
+// drain waits until the write pumps of clients have emptied their send
+// queues or ctx expires. It returns the number of messages left unsent.
+func drain(ctx context.Context, clients []*Client) int {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
 	for {
+		pending := 0
+		for _, client := range clients {
+			pending += client.queue.len()
+		}
+		if pending == 0 {
+			return 0
+		}
 		select {
-		case client := <-h.Register:
-			h.mu.Lock() // Acquire a write lock
-			if client.DMID != "" {
-				if h.DMClients[client.DMID] == nil {
-					h.DMClients[client.DMID] = make(map[*Client]bool)
-				}
-				h.DMClients[client.DMID][client] = true
-				log.Printf("Client %s registered to DM %s", client.UserID, client.DMID)
-			}
-			if client.SceneID != "" {
-				if h.SceneClients[client.SceneID] == nil {
-					h.SceneClients[client.SceneID] = make(map[*Client]bool)
-				}
-				h.SceneClients[client.SceneID][client] = true
-				log.Printf("Client %s registered to Scene %s", client.UserID, client.SceneID)
-			}
-			h.mu.Unlock() // Release the lock
-
-		case client := <-h.Unregister:
-			h.mu.Lock() // Acquire a write lock
-			if client.DMID != "" {
-				if clients, ok := h.DMClients[client.DMID]; ok {
-					if _, ok := clients[client]; ok {
-						delete(clients, client)
-						close(client.Send) // Close the send channel
-						log.Printf("Client %s unregistered from DM %s", client.UserID, client.DMID)
-					}
-				}
-			}
-			if client.SceneID != "" {
-				if clients, ok := h.SceneClients[client.SceneID]; ok {
-					if _, ok := clients[client]; ok {
-						delete(clients, client)
-						// Only close client.Send once, if it hasn't been closed by DM unregister
-						// This assumes a client is either in a DM or a Scene, or if in both,
-						// the first unregister closes the channel. A more robust solution
-						// would involve a reference counter or separate send channels.
-						// For now, if client.Send is already closed, this will panic.
-						// A simple check can prevent this:
-						select {
-						case <-client.Send: // Check if channel is already closed
-						default:
-							close(client.Send)
-						}
-						log.Printf("Client %s unregistered from Scene %s", client.UserID, client.SceneID)
-					}
-				}
-			}
-			h.mu.Unlock() // Release the lock
-
-		case msg := <-h.Broadcast:
-			h.mu.RLock() // Acquire a read lock
-			if msg.DMID != "" {
-				if clients, ok := h.DMClients[msg.DMID]; ok {
-					for client := range clients {
-						select {
-						case client.Send <- msg.Data:
-						default:
-							// If sending fails, assume client is gone and unregister
-							close(client.Send)
-							delete(h.DMClients[msg.DMID], client)
-							log.Printf("Failed to send to client %s in DM %s. Unregistering.", client.UserID, client.DMID)
-						}
-					}
-				}
+		case <-ctx.Done():
+			return pending
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown drains every client connected to this instance: it waits for
+// queued messages to be written (or ctx to expire), then sends a going-away
+// close frame and closes the connection. It returns ctx.Err() if the drain
+// timed out before all send queues were flushed.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	var clients []*Client
+	for _, s := range h.shards {
+		s.mu.RLock()
+		for _, group := range s.dmClients {
+			for client := range group {
+				clients = append(clients, client)
 			}
-			if msg.SceneID != "" {
-				if clients, ok := h.SceneClients[msg.SceneID]; ok {
-					for client := range clients {
-						select {
-						case client.Send <- msg.Data:
-						default:
-							// If sending fails, assume client is gone and unregister
-							close(client.Send)
-							delete(h.SceneClients[msg.SceneID], client)
-							log.Printf("Failed to send to client %s in Scene %s. Unregistering.", client.UserID, client.SceneID)
-						}
-					}
-				}
+		}
+		for _, group := range s.sceneClients {
+			for client := range group {
+				clients = append(clients, client)
 			}
-			h.mu.RUnlock() // Release the lock
 		}
+		s.mu.RUnlock()
 	}
+
+	log.Printf("Shutting down hub: draining %d connection(s)", len(clients))
+
+	// Give the write pumps a chance to flush what is already queued
+	var err error
+	if pending := drain(ctx, clients); pending > 0 {
+		err = ctx.Err()
+		log.Printf("Hub drain timed out with %d message(s) unsent", pending)
+	}
+
+	closeClients(clients, websocket.CloseGoingAway, "server shutting down")
+	return err
 }
 
-// GetActiveSceneUsersCount returns the number of active WebSocket connections for a given scene.
+// GetActiveSceneUsersCount returns the number of users connected to a given
+// scene. A user with several connections, e.g. in multiple tabs, counts once.
 func (h *Hub) GetActiveSceneUsersCount(sceneID string) int {
-	h.mu.RLock() // Acquire a read lock
-	defer h.mu.RUnlock() // Release the lock
+	s := h.shardFor(channelKey("", sceneID))
+	s.mu.RLock() // Acquire a read lock
+	defer s.mu.RUnlock() // Release the lock
+
+	return len(s.sceneUsers[sceneID])
+}
+
+// GetActiveSceneUsers returns the IDs of the users connected to a given scene
+// on this instance, sorted and without duplicates.
+func (h *Hub) GetActiveSceneUsers(sceneID string) []string {
+	s := h.shardFor(channelKey("", sceneID))
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if clients, ok := h.SceneClients[sceneID]; ok {
-		return len(clients)
+	userIDs := make([]string, 0, len(s.sceneUsers[sceneID]))
+	for userID := range s.sceneUsers[sceneID] {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	return userIDs
+}
+
+// ActiveSceneCounts returns the number of users connected to every scene
+// with at least one on this instance, counting each user once.
+func (h *Hub) ActiveSceneCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, s := range h.shards {
+		s.mu.RLock()
+		for sceneID, users := range s.sceneUsers {
+			counts[sceneID] = len(users)
+		}
+		s.mu.RUnlock()
 	}
-	return 0
+	return counts
 }
@@ -0,0 +1,93 @@
+package ws
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// CloseTooManyConnections is the close code sent to a connection rejected by
+// ConnLimits, mirroring HTTP 429 in the application-defined range.
+const CloseTooManyConnections = 4029
+
+// ConnLimits caps simultaneous connections on this instance. Zero means unlimited.
+type ConnLimits struct {
+	PerUser int // Connections one user may hold across all DMs and scenes
+	PerIP   int // Connections from one remote address
+	// TrustForwardedFor takes the remote address from X-Forwarded-For. Only
+	// enable it behind a proxy that sets the header, since clients can forge it.
+	TrustForwardedFor bool
+}
+
+// DefaultConnLimits allows each user a handful of tabs and devices. Per-IP
+// limits are off since many users may share an address behind NAT or a proxy.
+var DefaultConnLimits = ConnLimits{PerUser: 10}
+
+// UseConnLimits caps the connections clients started with Serve may hold.
+// It must be called before Run.
+func (h *Hub) UseConnLimits(l ConnLimits) {
+	h.limits = l
+}
+
+// RemoteIP returns the address r came from, honoring X-Forwarded-For if the
+// hub's ConnLimits trust it. Handlers store it in Client.IP.
+func (h *Hub) RemoteIP(r *http.Request) string {
+	if h.limits.TrustForwardedFor {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// admit reserves a connection slot for c, returning an error naming the
+// exceeded limit if there is none. Slots are held until release.
+func (h *Hub) admit(c *Client) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.limits.PerUser > 0 && h.userConns[c.UserID] >= h.limits.PerUser {
+		return fmt.Errorf("too many connections for user (max %d)", h.limits.PerUser)
+	}
+	if h.limits.PerIP > 0 && c.IP != "" && h.ipConns[c.IP] >= h.limits.PerIP {
+		return fmt.Errorf("too many connections from address (max %d)", h.limits.PerIP)
+	}
+	h.userConns[c.UserID]++
+	if c.IP != "" {
+		h.ipConns[c.IP]++
+	}
+	return nil
+}
+
+// release frees the slot admit reserved for c.
+func (h *Hub) release(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.userConns[c.UserID]--; h.userConns[c.UserID] <= 0 {
+		delete(h.userConns, c.UserID)
+	}
+	if c.IP != "" {
+		if h.ipConns[c.IP]--; h.ipConns[c.IP] <= 0 {
+			delete(h.ipConns, c.IP)
+		}
+	}
+}
+
+// reject closes c with CloseTooManyConnections and reason.
+func (c *Client) reject(reason string) {
+	log.Printf("Rejected %s: %s", c, reason)
+	msg := websocket.FormatCloseMessage(CloseTooManyConnections, reason)
+	c.Conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	c.Conn.Close()
+}
@@ -0,0 +1,71 @@
+package ws
+
+import "time"
+
+// ListenerEvent reports that a user started or stopped listening to a scene,
+// i.e. opened their first or closed their last scene connection on this instance.
+type ListenerEvent struct {
+	SceneID string
+	UserID  string
+	Joined  bool // True when the user started listening, false when they left
+	At      time.Time
+}
+
+// ListenerPayload is the payload of TypeListenerJoined and TypeListenerLeft events.
+type ListenerPayload struct {
+	SceneID string `json:"sceneID"`
+	UserID  string `json:"userID"`
+}
+
+// OnListenerChange registers fn to be called whenever a user starts or stops
+// listening to a scene on this instance. It must be called before Run. fn
+// runs on its own goroutine so it may safely use the hub.
+func (h *Hub) OnListenerChange(fn func(ListenerEvent)) {
+	h.onListener = fn
+}
+
+// addSceneConnection counts a new connection of client's user to its scene
+// and returns how many the user now has open there. Callers must hold s.mu.
+func (s *shard) addSceneConnection(client *Client) int {
+	users := s.sceneUsers[client.SceneID]
+	if users == nil {
+		users = make(map[string]int)
+		s.sceneUsers[client.SceneID] = users
+		s.cancelEmpty(client.SceneID)
+	}
+	users[client.UserID]++
+	return users[client.UserID]
+}
+
+// removeSceneConnection forgets a connection counted by addSceneConnection
+// and returns how many the user still has open. Callers must hold s.mu.
+func (s *shard) removeSceneConnection(client *Client) int {
+	users := s.sceneUsers[client.SceneID]
+	if users[client.UserID] <= 1 {
+		delete(users, client.UserID)
+		if len(users) == 0 {
+			delete(s.sceneUsers, client.SceneID)
+			s.scheduleEmpty(client.SceneID)
+		}
+		return 0
+	}
+	users[client.UserID]--
+	return users[client.UserID]
+}
+
+// notifyListener tells the scene's clients that e.UserID joined or left and
+// hands e to the listener callback, if any. Callers hold the scene's shard
+// lock, so the broadcast is sent from its own goroutine.
+func (h *Hub) notifyListener(e ListenerEvent) {
+	if e.UserID == "" {
+		return
+	}
+	t := TypeListenerLeft
+	if e.Joined {
+		t = TypeListenerJoined
+	}
+	go h.SendToScene(e.SceneID, t, ListenerPayload{SceneID: e.SceneID, UserID: e.UserID})
+	if h.onListener != nil {
+		go h.onListener(e)
+	}
+}
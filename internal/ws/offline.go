@@ -0,0 +1,56 @@
+package ws
+
+// DMEvent reports an event sent to a DM with SendToDM, so it can be queued
+// for participants who are not connected to the conversation.
+type DMEvent struct {
+	DMID      string
+	Type      MessageType
+	Data      []byte   // Encoded envelope, without a sequence number
+	Connected []string // Users with a connection to the DM on this instance when the event was sent
+}
+
+// OnDMEvent registers fn to be called for every event sent to a DM from this
+// instance. It must be called before Run. fn runs on its own goroutine so it
+// may safely use the hub.
+func (h *Hub) OnDMEvent(fn func(DMEvent)) {
+	h.onDMEvent = fn
+}
+
+// OnDMConnect registers fn to be called whenever a client connects to a DM
+// on this instance. It must be called before Run. fn runs on its own
+// goroutine, so live events may reach the client before anything fn sends.
+func (h *Hub) OnDMConnect(fn func(*Client)) {
+	h.onDMConnect = fn
+}
+
+// SendToClient queues data for a single client. It reports false if the
+// client has unregistered or was evicted for overflowing its send queue.
+func (h *Hub) SendToClient(client *Client, data []byte) bool {
+	s := h.shardOf(client)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.dmClients[client.DMID][client] && !s.sceneClients[client.SceneID][client] {
+		return false
+	}
+	return h.send(client, data)
+}
+
+// notifyDMEvent hands an event sent to dmID to the DM event callback, if any.
+func (h *Hub) notifyDMEvent(dmID string, t MessageType, data []byte) {
+	if h.onDMEvent == nil {
+		return
+	}
+	s := h.shardFor(channelKey(dmID, ""))
+	s.mu.RLock()
+	seen := make(map[string]bool)
+	var connected []string
+	for client := range s.dmClients[dmID] {
+		if !seen[client.UserID] {
+			seen[client.UserID] = true
+			connected = append(connected, client.UserID)
+		}
+	}
+	s.mu.RUnlock()
+	go h.onDMEvent(DMEvent{DMID: dmID, Type: t, Data: data, Connected: connected})
+}
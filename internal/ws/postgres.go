@@ -0,0 +1,197 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// pgChannel is the LISTEN/NOTIFY channel all instances publish broadcasts on.
+	pgChannel = "scenyx_ws_broadcast"
+	// pgMaxNotify is the largest notification sent inline. Postgres rejects
+	// payloads of 8000 bytes or more; larger broadcasts are spilled to a table.
+	pgMaxNotify = 7900
+	// pgSpillTTL is how long spilled broadcasts are kept for subscribers to read.
+	pgSpillTTL = 5 * time.Minute
+	// pgReconnectDelay is how long Subscribe waits before listening again
+	// after losing its connection.
+	pgReconnectDelay = 2 * time.Second
+)
+
+// pgNotification is a NOTIFY payload: a broadcast, or the id of one spilled
+// to ws_broadcast_payloads.
+type pgNotification struct {
+	Msg *BroadcastMessage `json:"msg,omitempty"`
+	Ref int64             `json:"ref,omitempty"`
+}
+
+// PostgresBroker implements Broker using Postgres LISTEN/NOTIFY, for
+// deployments that share a database but run no Redis.
+type PostgresBroker struct {
+	pool *pgxpool.Pool
+}
+
+var _ Broker = (*PostgresBroker)(nil)
+
+// NewPostgresBroker connects to the database at databaseURL. It keeps its own
+// small pool, one connection of which is held for LISTEN.
+func NewPostgresBroker(databaseURL string) (*PostgresBroker, error) {
+	cfg, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	cfg.MaxConns = 4
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create broker pool: %w", err)
+	}
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	log.Println("Using PostgreSQL LISTEN/NOTIFY for WebSocket broadcasts.")
+
+	return &PostgresBroker{pool: pool}, nil
+}
+
+// Publish numbers msg and notifies every listening instance in one
+// transaction. The sequence row stays locked until commit and Postgres
+// delivers notifications in commit order, so messages of a channel arrive in
+// the order of their sequence numbers.
+func (b *PostgresBroker) Publish(ctx context.Context, msg BroadcastMessage) error {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin publish: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if msg.DMID != "" || msg.SceneID != "" {
+		err = tx.QueryRow(ctx, `
+			INSERT INTO ws_broadcast_seqs (channel, seq) VALUES ($1, 1)
+			ON CONFLICT (channel) DO UPDATE SET seq = ws_broadcast_seqs.seq + 1
+			RETURNING seq`,
+			channelKey(msg.DMID, msg.SceneID),
+		).Scan(&msg.Seq)
+		if err != nil {
+			return fmt.Errorf("number broadcast: %w", err)
+		}
+	}
+
+	payload, err := json.Marshal(pgNotification{Msg: &msg})
+	if err != nil {
+		return fmt.Errorf("encode broadcast: %w", err)
+	}
+	if len(payload) > pgMaxNotify {
+		if payload, err = b.spill(ctx, tx, msg); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `SELECT pg_notify($1, $2)`, pgChannel, string(payload)); err != nil {
+		return fmt.Errorf("notify broadcast: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// spill stores msg in ws_broadcast_payloads and returns the notification
+// referencing it. Spilled broadcasts older than pgSpillTTL are deleted.
+func (b *PostgresBroker) spill(ctx context.Context, tx pgx.Tx, msg BroadcastMessage) ([]byte, error) {
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encode broadcast: %w", err)
+	}
+	var ref int64
+	if err := tx.QueryRow(ctx, `INSERT INTO ws_broadcast_payloads (payload) VALUES ($1) RETURNING id`, encoded).Scan(&ref); err != nil {
+		return nil, fmt.Errorf("store large broadcast: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM ws_broadcast_payloads WHERE created_at < $1`, time.Now().Add(-pgSpillTTL)); err != nil {
+		return nil, fmt.Errorf("prune large broadcasts: %w", err)
+	}
+	return json.Marshal(pgNotification{Ref: ref})
+}
+
+// Subscribe listens on the shared channel and calls deliver for each
+// message. A lost connection is re-established; broadcasts published while
+// it was down are not delivered, and clients recover them through replay.
+func (b *PostgresBroker) Subscribe(ctx context.Context, deliver func(BroadcastMessage)) error {
+	for {
+		err := b.listen(ctx, deliver)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("Lost PostgreSQL broadcast subscription, listening again in %s: %v", pgReconnectDelay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pgReconnectDelay):
+		}
+	}
+}
+
+// listen holds a connection listening on pgChannel and delivers its
+// notifications until the connection fails or ctx is cancelled.
+func (b *PostgresBroker) listen(ctx context.Context, deliver func(BroadcastMessage)) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire listen connection: %w", err)
+	}
+	// A listening connection must not go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgChannel); err != nil {
+		return fmt.Errorf("listen on %s: %w", pgChannel, err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var note pgNotification
+		if err := json.Unmarshal([]byte(n.Payload), &note); err != nil {
+			log.Printf("Discarding malformed broadcast from PostgreSQL: %v", err)
+			continue
+		}
+		msg := note.Msg
+		if msg == nil {
+			if msg, err = b.fetchSpilled(ctx, note.Ref); err != nil {
+				log.Printf("Discarding broadcast %d spilled to PostgreSQL: %v", note.Ref, err)
+				continue
+			}
+		}
+		deliver(*msg)
+	}
+}
+
+// fetchSpilled reads a broadcast stored by spill.
+func (b *PostgresBroker) fetchSpilled(ctx context.Context, ref int64) (*BroadcastMessage, error) {
+	var encoded []byte
+	err := b.pool.QueryRow(ctx, `SELECT payload FROM ws_broadcast_payloads WHERE id = $1`, ref).Scan(&encoded)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("payload already pruned")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var msg BroadcastMessage
+	if err := json.Unmarshal(encoded, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// Close closes the broker's pool.
+func (b *PostgresBroker) Close() error {
+	b.pool.Close()
+	return nil
+}
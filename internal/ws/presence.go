@@ -0,0 +1,97 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+// PresenceStatus is a user's availability as seen by other users.
+type PresenceStatus string
+
+const (
+	StatusOnline  PresenceStatus = "online"  // At least one open connection
+	StatusAway    PresenceStatus = "away"    // Connected but marked idle by the client
+	StatusOffline PresenceStatus = "offline" // No open connections
+)
+
+// Presence describes a user's current status. LastSeenAt is set when the
+// user goes offline; for connected users it is the time of the change.
+type Presence struct {
+	UserID     string         `json:"userID"`
+	Status     PresenceStatus `json:"status"`
+	LastSeenAt time.Time      `json:"lastSeenAt"`
+}
+
+// OnPresenceChange registers fn to be called whenever a user's presence
+// changes on this instance. It must be called before Run. fn runs on its own
+// goroutine so it may safely use the hub.
+func (h *Hub) OnPresenceChange(fn func(Presence)) {
+	h.onPresence = fn
+}
+
+// trackConnect records a new connection for client's user. Callers must hold h.mu.
+func (h *Hub) trackConnect(client *Client) {
+	if client.UserID == "" {
+		return
+	}
+	conns := h.userClients[client.UserID]
+	if conns == nil {
+		conns = make(map[*Client]bool)
+		h.userClients[client.UserID] = conns
+	}
+	conns[client] = true
+	if len(conns) == 1 {
+		h.userStatus[client.UserID] = StatusOnline
+		h.notifyPresence(Presence{UserID: client.UserID, Status: StatusOnline, LastSeenAt: time.Now()})
+	}
+}
+
+// trackDisconnect forgets client's connection. Callers must hold h.mu.
+// It is safe to call more than once for the same client.
+func (h *Hub) trackDisconnect(client *Client) {
+	conns, ok := h.userClients[client.UserID]
+	if !ok || !conns[client] {
+		return
+	}
+	delete(conns, client)
+	if len(conns) == 0 {
+		delete(h.userClients, client.UserID)
+		delete(h.userStatus, client.UserID)
+		h.notifyPresence(Presence{UserID: client.UserID, Status: StatusOffline, LastSeenAt: time.Now()})
+	}
+}
+
+// notifyPresence hands p to the presence listener, if any.
+func (h *Hub) notifyPresence(p Presence) {
+	if h.onPresence != nil {
+		go h.onPresence(p)
+	}
+}
+
+// SetStatus switches a connected user between online and away. It returns
+// false if the user has no open connections on this instance.
+func (h *Hub) SetStatus(userID string, status PresenceStatus) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.userClients[userID]) == 0 {
+		return false
+	}
+	if h.userStatus[userID] != status {
+		h.userStatus[userID] = status
+		log.Printf("User %s is now %s", userID, status)
+		h.notifyPresence(Presence{UserID: userID, Status: status, LastSeenAt: time.Now()})
+	}
+	return true
+}
+
+// GetStatus returns a user's status on this instance, StatusOffline if not connected.
+func (h *Hub) GetStatus(userID string) PresenceStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if status, ok := h.userStatus[userID]; ok {
+		return status
+	}
+	return StatusOffline
+}
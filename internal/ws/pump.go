@@ -0,0 +1,208 @@
+package ws
+
+import (
+	"compress/flate"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// PumpConfig sets the deadlines and keepalive of a client's read and write pumps.
+type PumpConfig struct {
+	WriteWait      time.Duration // Deadline for writing a single frame
+	PongWait       time.Duration // How long the read pump waits for any frame, pongs included, before dropping the client
+	PingPeriod     time.Duration // How often the write pump pings; must be less than PongWait
+	MaxMessageSize int64         // Largest frame accepted; larger ones disconnect the client. 0 means no limit
+	MessageRate    float64       // Frames per second a client may send on average; 0 means unlimited
+	MessageBurst   int           // Frames a client may send back to back before MessageRate applies
+	OnRateExceeded RatePolicy    // What happens to frames over the budget; defaults to RateDrop
+
+	SendQueueSize  int            // Messages queued for a client before OnSendOverflow applies
+	OnSendOverflow OverflowPolicy // What happens to a client whose queue is full; defaults to OverflowDisconnect
+
+	// Messages of at least CompressMinSize bytes are sent with permessage-deflate
+	// to clients that negotiated it; 0 disables compression. Smaller messages
+	// are sent as is, since each message is deflated without context takeover
+	// and short ones barely shrink.
+	CompressMinSize  int
+	CompressionLevel int // flate level from 1 (fastest) to 9 (smallest)
+}
+
+// DefaultPumpConfig drops a connection after it leaves three pings unanswered,
+// which clears half-open connections from mobile clients within a few minutes.
+var DefaultPumpConfig = PumpConfig{
+	WriteWait:      10 * time.Second,
+	PongWait:       90 * time.Second,
+	PingPeriod:     30 * time.Second,
+	MaxMessageSize: 64 << 10,
+	MessageRate:    10,
+	MessageBurst:   20,
+	OnRateExceeded: RateDrop,
+
+	SendQueueSize:  256,
+	OnSendOverflow: OverflowDisconnect,
+
+	CompressMinSize:  256,
+	CompressionLevel: flate.BestSpeed,
+}
+
+// UsePumpConfig replaces the pump settings of clients served afterwards.
+// It must be called before Run.
+func (h *Hub) UsePumpConfig(cfg PumpConfig) {
+	h.pump = cfg
+}
+
+// Serve registers c with the hub and starts its read and write pumps. handle
+// is called from the read pump with each frame the client sends; it may be
+// nil for connections that only receive. When the connection fails or its
+// read deadline passes, the client is unregistered and the connection closed.
+// Connections over the hub's ConnLimits are closed with CloseTooManyConnections.
+func (h *Hub) Serve(c *Client, handle func(data []byte)) {
+	if err := h.admit(c); err != nil {
+		c.reject(err.Error())
+		return
+	}
+	c.binary = c.Conn.Subprotocol() == MsgpackProtocol
+	c.queue = newSendQueue(h.pump.SendQueueSize, h.pump.OnSendOverflow)
+	h.shardOf(c).register <- c
+	go c.writePump(h.pump)
+	go c.readPump(h, h.pump, handle)
+}
+
+// String identifies the client in log messages.
+func (c *Client) String() string {
+	switch {
+	case c.DMID != "":
+		return fmt.Sprintf("client %s in DM %s", c.UserID, c.DMID)
+	case c.SceneID != "":
+		return fmt.Sprintf("client %s in Scene %s", c.UserID, c.SceneID)
+	}
+	return "client " + c.UserID
+}
+
+// readPump reads frames from the connection until it fails. Every frame,
+// including the pongs answering the write pump's pings, extends the read
+// deadline. Frames over the client's rate budget never reach handle, and
+// binary frames reach it re-encoded as JSON.
+func (c *Client) readPump(h *Hub, cfg PumpConfig, handle func([]byte)) {
+	defer func() {
+		h.shardOf(c).unregister <- c
+		h.release(c)
+		c.Conn.Close()
+		log.Printf("Read pump closed for %s", c)
+	}()
+
+	if cfg.MaxMessageSize > 0 {
+		c.Conn.SetReadLimit(cfg.MaxMessageSize)
+	}
+	c.Conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	})
+	limiter := newRateLimiter(cfg.MessageRate, cfg.MessageBurst)
+	dropping := false // Whether the previous frame was dropped, so a flood is logged once
+
+	for {
+		kind, data, err := c.Conn.ReadMessage()
+		if err != nil {
+			if errors.Is(err, websocket.ErrReadLimit) {
+				log.Printf("Disconnected %s: frame larger than %d bytes", c, cfg.MaxMessageSize)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("WebSocket read error for %s: %v", c, err)
+			}
+			return
+		}
+		now := time.Now()
+		c.Conn.SetReadDeadline(now.Add(cfg.PongWait))
+		if !limiter.allow(now) {
+			if cfg.OnRateExceeded == RateDisconnect {
+				log.Printf("Disconnected %s: over %g messages per second", c, cfg.MessageRate)
+				msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "rate limit exceeded")
+				c.Conn.WriteControl(websocket.CloseMessage, msg, now.Add(cfg.WriteWait))
+				return
+			}
+			if !dropping {
+				log.Printf("Dropping frames from %s: over %g messages per second", c, cfg.MessageRate)
+				dropping = true
+			}
+			continue
+		}
+		dropping = false
+		if kind == websocket.BinaryMessage {
+			if data, err = fromMsgpack(data); err != nil {
+				log.Printf("Dropped frame from %s: %v", c, err)
+				continue
+			}
+		}
+		if handle != nil {
+			handle(data)
+		}
+	}
+}
+
+// writePump writes queued messages and periodic pings to the connection
+// until the hub closes c's queue or a write fails. A client evicted for
+// overflowing its queue stops without writing the rest; see Hub.evict.
+// Clients that negotiated MsgpackProtocol get binary frames.
+func (c *Client) writePump(cfg PumpConfig) {
+	if cfg.CompressMinSize > 0 {
+		if err := c.Conn.SetCompressionLevel(cfg.CompressionLevel); err != nil {
+			log.Printf("Invalid compression level for %s: %v", c, err)
+		}
+	}
+	ticker := time.NewTicker(cfg.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Conn.Close()
+		log.Printf("Write pump closed for %s", c)
+	}()
+
+	for {
+		select {
+		case <-c.queue.ready:
+			messages, closed, overflowed := c.queue.take()
+			if overflowed {
+				return
+			}
+			for _, message := range messages {
+				if err := c.write(cfg, message); err != nil {
+					log.Printf("WebSocket write error for %s: %v", c, err)
+					return
+				}
+			}
+			if closed {
+				// The hub dropped the client; tell the peer if it is still listening
+				c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("WebSocket ping failed for %s: %v", c, err)
+				return
+			}
+		}
+	}
+}
+
+// write sends one queued message. Messages that cannot be converted for a
+// MsgpackProtocol client are dropped rather than failing the connection.
+func (c *Client) write(cfg PumpConfig, message []byte) error {
+	c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+	kind := websocket.TextMessage
+	if c.binary {
+		packed, err := toMsgpack(message)
+		if err != nil {
+			log.Printf("Dropped message for %s: %v", c, err)
+			return nil
+		}
+		kind, message = websocket.BinaryMessage, packed
+	}
+	// Has no effect unless the client negotiated permessage-deflate
+	c.Conn.EnableWriteCompression(cfg.CompressMinSize > 0 && len(message) >= cfg.CompressMinSize)
+	return c.Conn.WriteMessage(kind, message)
+}
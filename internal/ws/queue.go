@@ -0,0 +1,152 @@
+package ws
+
+import (
+	"log"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// OverflowPolicy is what happens when a message is sent to a client whose
+// send queue is full, i.e. whose connection is not keeping up.
+type OverflowPolicy string
+
+const (
+	OverflowDropOldest OverflowPolicy = "drop-oldest" // Discard the oldest queued message and keep the connection
+	OverflowDisconnect OverflowPolicy = "disconnect"  // Discard the queue and evict the client
+)
+
+// pushResult reports what sendQueue.push did with a message.
+type pushResult int
+
+const (
+	pushQueued     pushResult = iota // Queued
+	pushDropped                      // Queued after discarding the oldest message; reported once until the writer catches up
+	pushOverflowed                   // Not queued; the queue just overflowed under OverflowDisconnect and the client must be evicted
+	pushRejected                     // Not queued; the queue is closed or overflowed earlier
+)
+
+// sendQueue is a client's bounded queue of outbound messages, filled by the
+// hub and emptied by the client's write pump.
+type sendQueue struct {
+	mu         sync.Mutex
+	items      [][]byte
+	size       int
+	policy     OverflowPolicy
+	dropping   bool          // Whether a message was dropped since the writer last emptied the queue
+	closed     bool          // No more messages are queued; the writer exits once it has written the rest
+	overflowed bool          // Overflowed under OverflowDisconnect; queued messages were discarded
+	ready      chan struct{} // Signalled when messages are queued or the queue closes
+}
+
+func newSendQueue(size int, policy OverflowPolicy) *sendQueue {
+	if size < 1 {
+		size = 1
+	}
+	return &sendQueue{size: size, policy: policy, ready: make(chan struct{}, 1)}
+}
+
+// push queues data, applying the queue's overflow policy if it is full.
+func (q *sendQueue) push(data []byte) pushResult {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed || q.overflowed {
+		return pushRejected
+	}
+	result := pushQueued
+	if len(q.items) >= q.size {
+		if q.policy == OverflowDisconnect {
+			q.overflowed = true
+			q.items = nil
+			q.signal()
+			return pushOverflowed
+		}
+		q.items[0] = nil
+		q.items = q.items[1:]
+		if !q.dropping {
+			q.dropping = true
+			result = pushDropped
+		}
+	}
+	q.items = append(q.items, data)
+	q.signal()
+	return result
+}
+
+// take removes and returns every queued message. closed reports that the
+// writer should stop once they are written; overflowed that the queue was
+// discarded under OverflowDisconnect.
+func (q *sendQueue) take() (items [][]byte, closed, overflowed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items, q.items = q.items, nil
+	q.dropping = false
+	return items, q.closed, q.overflowed
+}
+
+// close stops the queue accepting messages and wakes the writer. It is safe
+// to call more than once.
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.signal()
+}
+
+// len returns the number of messages waiting to be written.
+func (q *sendQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items)
+}
+
+// free returns how many messages can be queued before the queue overflows.
+func (q *sendQueue) free() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.size - len(q.items)
+}
+
+// signal wakes the writer without blocking. Callers must hold q.mu.
+func (q *sendQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// send queues data for client. A client whose queue overflows under
+// OverflowDisconnect is evicted. It reports whether data was queued.
+func (h *Hub) send(client *Client, data []byte) bool {
+	switch client.queue.push(data) {
+	case pushQueued:
+		h.sent.Add(1)
+		return true
+	case pushDropped:
+		log.Printf("Dropping oldest queued messages for %s: send queue full", client)
+		h.sent.Add(1)
+		h.dropped.Add(1)
+		return true
+	case pushOverflowed:
+		log.Printf("Evicting %s: send queue overflowed", client)
+		h.evicted.Add(1)
+		h.evict(client)
+	}
+	return false
+}
+
+// evict unregisters client through its shard's worker, like its read pump
+// does when the connection ends, then closes the connection, which also
+// interrupts a write stuck on the slow peer. It does not block, so it may be
+// called while holding a shard lock or from the worker itself.
+func (h *Hub) evict(client *Client) {
+	go func() {
+		h.shardOf(client).unregister <- client
+		closeClients([]*Client{client}, websocket.ClosePolicyViolation, "send queue overflowed")
+	}()
+}
@@ -0,0 +1,46 @@
+package ws
+
+import "time"
+
+// RatePolicy is what the read pump does with frames over a client's budget.
+type RatePolicy string
+
+const (
+	RateDrop       RatePolicy = "drop"       // Discard the frame and keep the connection
+	RateDisconnect RatePolicy = "disconnect" // Close the connection with a policy violation
+)
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at rate
+// tokens per second. It is only used from a client's read pump, so it needs
+// no locking.
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a full bucket, or nil if rate is not positive.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// allow takes a token if one is available. A nil limiter allows everything.
+func (l *rateLimiter) allow(now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
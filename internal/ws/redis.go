@@ -0,0 +1,97 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisChannel is the pub/sub channel all instances publish broadcasts on.
+const redisChannel = "scenyx:ws:broadcast"
+
+// redisSeqPrefix prefixes the counter key of each DM and scene channel.
+const redisSeqPrefix = "scenyx:ws:seq:"
+
+// publishSequenced numbers a broadcast and publishes it in one atomic step,
+// so messages of a channel are published in the order of their sequence numbers.
+var publishSequenced = redis.NewScript(`
+local msg = cjson.decode(ARGV[2])
+msg.seq = redis.call('INCR', KEYS[1])
+redis.call('PUBLISH', ARGV[1], cjson.encode(msg))
+return msg.seq
+`)
+
+// RedisBroker implements Broker using Redis pub/sub.
+type RedisBroker struct {
+	client *redis.Client
+}
+
+var _ Broker = (*RedisBroker)(nil)
+
+// NewRedisBroker connects to the Redis server at redisURL (e.g. redis://localhost:6379/0).
+func NewRedisBroker(redisURL string) (*RedisBroker, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	log.Println("Successfully connected to Redis for WebSocket broadcasts.")
+
+	return &RedisBroker{client: client}, nil
+}
+
+// Publish encodes msg as JSON and publishes it on the shared channel. DM and
+// scene messages are numbered from a Redis counter per channel.
+func (b *RedisBroker) Publish(ctx context.Context, msg BroadcastMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode broadcast: %w", err)
+	}
+	if msg.DMID == "" && msg.SceneID == "" {
+		return b.client.Publish(ctx, redisChannel, payload).Err()
+	}
+	key := redisSeqPrefix + channelKey(msg.DMID, msg.SceneID)
+	return publishSequenced.Run(ctx, b.client, []string{key}, redisChannel, payload).Err()
+}
+
+// Subscribe listens on the shared channel and calls deliver for each message.
+func (b *RedisBroker) Subscribe(ctx context.Context, deliver func(BroadcastMessage)) error {
+	sub := b.client.Subscribe(ctx, redisChannel)
+	defer sub.Close()
+
+	// Wait for the subscription to be confirmed before consuming messages
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("subscribe to %s: %w", redisChannel, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-sub.Channel():
+			if !ok {
+				return fmt.Errorf("redis subscription to %s closed", redisChannel)
+			}
+			var msg BroadcastMessage
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				log.Printf("Discarding malformed broadcast from Redis: %v", err)
+				continue
+			}
+			deliver(msg)
+		}
+	}
+}
+
+// Close closes the Redis client.
+func (b *RedisBroker) Close() error {
+	return b.client.Close()
+}
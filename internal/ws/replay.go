@@ -0,0 +1,117 @@
+package ws
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	// replayCapacity is how many events are kept per DM or scene.
+	replayCapacity = 256
+	// replayTTL is how long events stay replayable after being delivered.
+	replayTTL = 2 * time.Minute
+)
+
+// ResyncPayload is the payload of TypeResync, sent on reconnect when the
+// missed events can no longer be replayed. The client should refetch history
+// over HTTP instead.
+type ResyncPayload struct {
+	LastEventID string `json:"lastEventID"`
+}
+
+// replayedEvent is a delivered event kept for reconnecting clients.
+type replayedEvent struct {
+	id   string
+	data []byte
+	at   time.Time
+}
+
+// replayLog keeps the recent events of every DM and scene, keyed by
+// "dm:<id>" or "scene:<id>". Each shard has its own, only used from the shard's
+// worker, so recording a delivery and replaying to a new client never interleave.
+type replayLog struct {
+	events    map[string][]replayedEvent
+	lastPrune time.Time
+}
+
+func newReplayLog() *replayLog {
+	return &replayLog{events: make(map[string][]replayedEvent)}
+}
+
+// channelKey returns the replay log key of a DM or scene.
+func channelKey(dmID, sceneID string) string {
+	if dmID != "" {
+		return "dm:" + dmID
+	}
+	return "scene:" + sceneID
+}
+
+// record appends an event to key's log, dropping the oldest past capacity.
+func (l *replayLog) record(key, id string, data []byte, now time.Time) {
+	events := append(l.events[key], replayedEvent{id: id, data: data, at: now})
+	if len(events) > replayCapacity {
+		events = events[len(events)-replayCapacity:]
+	}
+	l.events[key] = events
+
+	if now.Sub(l.lastPrune) > replayTTL {
+		l.prune(now)
+	}
+}
+
+// since returns the events recorded for key after lastID. ok is false if
+// lastID is no longer (or was never) in the log.
+func (l *replayLog) since(key, lastID string, now time.Time) (missed [][]byte, ok bool) {
+	events := l.events[key]
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].id != lastID {
+			continue
+		}
+		if now.Sub(events[i].at) > replayTTL {
+			return nil, false
+		}
+		for _, e := range events[i+1:] {
+			missed = append(missed, e.data)
+		}
+		return missed, true
+	}
+	return nil, false
+}
+
+// prune forgets events older than replayTTL.
+func (l *replayLog) prune(now time.Time) {
+	for key, events := range l.events {
+		i := 0
+		for i < len(events) && now.Sub(events[i].at) > replayTTL {
+			i++
+		}
+		if i == len(events) {
+			delete(l.events, key)
+		} else if i > 0 {
+			l.events[key] = append([]replayedEvent(nil), events[i:]...)
+		}
+	}
+	l.lastPrune = now
+}
+
+// replay queues the events client missed since client.LastEventID, or a
+// TypeResync event if they are gone. Callers must hold s.mu.
+func (s *shard) replay(client *Client) {
+	missed, ok := s.replays.since(channelKey(client.DMID, client.SceneID), client.LastEventID, time.Now())
+	if !ok || len(missed) > client.queue.free() {
+		data, err := Encode(TypeResync, ResyncPayload{LastEventID: client.LastEventID})
+		if err != nil {
+			log.Printf("Failed to encode resync for %s: %v", client, err)
+			return
+		}
+		s.hub.send(client, data)
+		log.Printf("Asked %s to resync from event %s", client, client.LastEventID)
+		return
+	}
+	for _, data := range missed {
+		s.hub.send(client, data)
+	}
+	if len(missed) > 0 {
+		log.Printf("Replayed %d event(s) to %s", len(missed), client)
+	}
+}
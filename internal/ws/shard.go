@@ -0,0 +1,202 @@
+package ws
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultShards is how many workers a hub spreads its DMs and scenes over.
+const DefaultShards = 16
+
+// shard owns the DMs and scenes whose channel key hashes to it. Its worker
+// serializes their registrations, unregistrations and broadcasts, so a busy
+// scene only delays the rooms sharing its shard rather than every room on
+// the instance. A client belongs to one DM or one scene, and so to one shard.
+type shard struct {
+	hub *Hub
+
+	mu           sync.RWMutex                // Guards the maps below
+	dmClients    map[string]map[*Client]bool // dmID -> clients connected to that DM
+	sceneClients map[string]map[*Client]bool // sceneID -> clients connected to that Scene
+	sceneUsers   map[string]map[string]int   // sceneID -> userID -> that user's connections to the Scene
+	emptyTimers  map[string]*time.Timer      // sceneID -> pending onEmpty call
+
+	replays *replayLog       // Recent DM and scene events for reconnecting clients; only used by the worker
+	seqs    map[string]int64 // Last sequence number of each DM and scene, when there is no broker; only used by the worker
+
+	broadcasts atomic.Uint64 // Broadcasts delivered, see Stats
+	busySince  atomic.Int64  // Unix nanoseconds the worker started its current operation, 0 when idle
+
+	register   chan *Client
+	unregister chan *Client
+	broadcast  chan BroadcastMessage
+	inbound    chan BroadcastMessage // Messages received from the broker, delivered to local clients
+}
+
+func newShard(h *Hub) *shard {
+	return &shard{
+		hub:          h,
+		dmClients:    make(map[string]map[*Client]bool),
+		sceneClients: make(map[string]map[*Client]bool),
+		sceneUsers:   make(map[string]map[string]int),
+		emptyTimers:  make(map[string]*time.Timer),
+		replays:      newReplayLog(),
+		seqs:         make(map[string]int64),
+		register:     make(chan *Client),
+		unregister:   make(chan *Client),
+		broadcast:    make(chan BroadcastMessage),
+		inbound:      make(chan BroadcastMessage, 256),
+	}
+}
+
+// UseShards spreads the hub's DMs and scenes over n workers. It must be
+// called before Run and before any client is served.
+func (h *Hub) UseShards(n int) {
+	if n < 1 {
+		n = 1
+	}
+	h.shards = make([]*shard, n)
+	for i := range h.shards {
+		h.shards[i] = newShard(h)
+	}
+}
+
+// shardFor returns the shard owning the DM or scene with channel key key.
+func (h *Hub) shardFor(key string) *shard {
+	if len(h.shards) == 1 {
+		return h.shards[0]
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return h.shards[hash.Sum32()%uint32(len(h.shards))]
+}
+
+// shardOf returns the shard owning client's DM or scene.
+func (h *Hub) shardOf(client *Client) *shard {
+	return h.shardFor(channelKey(client.DMID, client.SceneID))
+}
+
+// Broadcast hands msg to the worker of its DM or scene, which delivers it to
+// the clients connected there. Messages for a user go to the shard their ID
+// hashes to.
+func (h *Hub) Broadcast(msg BroadcastMessage) {
+	key := "user:" + msg.UserID
+	if msg.DMID != "" || msg.SceneID != "" {
+		key = channelKey(msg.DMID, msg.SceneID)
+	}
+	h.shardFor(key).broadcast <- msg
+}
+
+// run is the shard's worker loop.
+func (s *shard) run() {
+	h := s.hub
+	for {
+		s.busySince.Store(0)
+		select {
+		case client := <-s.register:
+			s.busySince.Store(time.Now().UnixNano())
+			s.add(client)
+
+		case client := <-s.unregister:
+			s.busySince.Store(time.Now().UnixNano())
+			s.remove(client)
+
+		case msg := <-s.broadcast:
+			s.busySince.Store(time.Now().UnixNano())
+			if h.broker != nil {
+				// Every instance (including this one) receives the message back from
+				// the broker and delivers it to its own clients via the inbound queue.
+				if err := h.broker.Publish(context.Background(), msg); err != nil {
+					// Delivered without a sequence number, since this instance cannot
+					// know the next one; clients treat such events as unordered
+					log.Printf("Failed to publish broadcast to broker, delivering locally only: %v", err)
+					s.deliver(msg)
+				}
+				continue
+			}
+			if msg.DMID != "" || msg.SceneID != "" {
+				key := channelKey(msg.DMID, msg.SceneID)
+				s.seqs[key]++
+				msg.Seq = s.seqs[key]
+			}
+			s.deliver(msg)
+
+		case msg := <-s.inbound:
+			s.busySince.Store(time.Now().UnixNano())
+			s.deliver(msg)
+		}
+	}
+}
+
+// add registers client with its DM or scene.
+func (s *shard) add(client *Client) {
+	h := s.hub
+	client.connectedAt = time.Now()
+	s.mu.Lock()
+	if client.DMID != "" {
+		if s.dmClients[client.DMID] == nil {
+			s.dmClients[client.DMID] = make(map[*Client]bool)
+		}
+		s.dmClients[client.DMID][client] = true
+		log.Printf("Client %s registered to DM %s", client.UserID, client.DMID)
+		if h.onDMConnect != nil {
+			go h.onDMConnect(client)
+		}
+	}
+	if client.SceneID != "" {
+		if s.sceneClients[client.SceneID] == nil {
+			s.sceneClients[client.SceneID] = make(map[*Client]bool)
+		}
+		s.sceneClients[client.SceneID][client] = true
+		log.Printf("Client %s registered to Scene %s", client.UserID, client.SceneID)
+		if s.addSceneConnection(client) == 1 {
+			h.notifyListener(ListenerEvent{SceneID: client.SceneID, UserID: client.UserID, Joined: true, At: time.Now()})
+		}
+	}
+	if client.LastEventID != "" {
+		s.replay(client)
+	}
+	s.mu.Unlock()
+
+	h.mu.Lock()
+	h.trackConnect(client)
+	h.mu.Unlock()
+}
+
+// remove unregisters client and closes its send channel. It is safe to call
+// more than once for the same client.
+func (s *shard) remove(client *Client) {
+	h := s.hub
+	s.mu.Lock()
+	clients := s.dmClients[client.DMID]
+	if client.DMID == "" {
+		clients = s.sceneClients[client.SceneID]
+	}
+	if clients[client] {
+		delete(clients, client)
+		client.queue.close()
+		if client.DMID != "" {
+			if len(clients) == 0 {
+				delete(s.dmClients, client.DMID)
+			}
+			log.Printf("Client %s unregistered from DM %s", client.UserID, client.DMID)
+		} else {
+			if len(clients) == 0 {
+				delete(s.sceneClients, client.SceneID)
+			}
+			log.Printf("Client %s unregistered from Scene %s", client.UserID, client.SceneID)
+			if s.removeSceneConnection(client) == 0 {
+				h.notifyListener(ListenerEvent{SceneID: client.SceneID, UserID: client.UserID, At: time.Now()})
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	h.mu.Lock()
+	h.trackDisconnect(client)
+	h.mu.Unlock()
+}
@@ -0,0 +1,238 @@
+package ws
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// rateWindow is the period BroadcastRate is averaged over.
+	rateWindow = time.Minute
+	// rateSampleEvery is how often the broadcast count is sampled for BroadcastRate.
+	rateSampleEvery = 5 * time.Second
+)
+
+// Stats are the hub's gauges and counters on this instance. Counters are
+// totals since the hub started.
+type Stats struct {
+	Connections   int          `json:"connections"`
+	Users         int          `json:"users"`  // Users with at least one connection
+	DMs           int          `json:"dms"`    // DMs with at least one connection
+	Scenes        int          `json:"scenes"` // Scenes with at least one connection
+	Broadcasts    uint64       `json:"broadcasts"`
+	BroadcastRate float64      `json:"broadcastRate"` // Broadcasts delivered per second over the last minute
+	Sent          uint64       `json:"sent"`          // Messages queued for clients
+	Dropped       uint64       `json:"dropped"`       // Queued messages discarded under OverflowDropOldest
+	Evicted       uint64       `json:"evicted"`       // Clients evicted under OverflowDisconnect
+	QueuedTotal   int          `json:"queuedTotal"`   // Messages waiting in all send queues
+	QueuedMax     int          `json:"queuedMax"`     // Messages waiting in the fullest send queue
+	QueueSize     int          `json:"queueSize"`     // Capacity of each send queue, see PumpConfig.SendQueueSize
+	Shards        []ShardStats `json:"shards"`
+}
+
+// ShardStats describe one of the hub's workers.
+type ShardStats struct {
+	Rooms       int        `json:"rooms"` // DMs and scenes with at least one connection
+	Connections int        `json:"connections"`
+	Broadcasts  uint64     `json:"broadcasts"`
+	Inbound     int        `json:"inbound"`             // Broker messages waiting for the worker
+	BusySince   *time.Time `json:"busySince,omitempty"` // When the worker started its current operation; unset when idle
+}
+
+// RoomState describes a DM or scene with connections on this instance.
+type RoomState struct {
+	Kind        string        `json:"kind"` // "dm" or "scene"
+	ID          string        `json:"id"`
+	Shard       int           `json:"shard"`
+	Connections int           `json:"connections"`
+	Users       int           `json:"users"`
+	QueuedTotal int           `json:"queuedTotal"`
+	QueuedMax   int           `json:"queuedMax"`
+	Clients     []ClientState `json:"clients"`
+}
+
+// ClientState describes one connection.
+type ClientState struct {
+	UserID      string    `json:"userID"`
+	IP          string    `json:"ip,omitempty"`
+	Binary      bool      `json:"binary"` // Negotiated MsgpackProtocol
+	Queued      int       `json:"queued"` // Messages waiting to be written
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+// HubState is a dump of the hub for debugging, see Snapshot.
+type HubState struct {
+	Stats Stats       `json:"stats"`
+	Rooms []RoomState `json:"rooms"`
+}
+
+// rateSampler keeps recent samples of the broadcast count to average
+// BroadcastRate over rateWindow.
+type rateSampler struct {
+	mu      sync.Mutex
+	samples []rateSample
+}
+
+type rateSample struct {
+	at    time.Time
+	count uint64
+}
+
+// sample records the broadcast count, forgetting samples older than rateWindow.
+func (r *rateSampler) sample(now time.Time, count uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.samples = append(r.samples, rateSample{at: now, count: count})
+	i := 0
+	for i < len(r.samples)-1 && now.Sub(r.samples[i].at) > rateWindow {
+		i++
+	}
+	r.samples = r.samples[i:]
+}
+
+// rate returns broadcasts per second between the oldest sample and count at now.
+func (r *rateSampler) rate(now time.Time, count uint64) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.samples) == 0 {
+		return 0
+	}
+	oldest := r.samples[0]
+	elapsed := now.Sub(oldest.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(count-oldest.count) / elapsed
+}
+
+// sampleRates samples the broadcast count every rateSampleEvery. It does not return.
+func (h *Hub) sampleRates() {
+	ticker := time.NewTicker(rateSampleEvery)
+	defer ticker.Stop()
+	h.rates.sample(time.Now(), h.broadcastCount())
+	for now := range ticker.C {
+		h.rates.sample(now, h.broadcastCount())
+	}
+}
+
+// broadcastCount returns the broadcasts delivered by every shard.
+func (h *Hub) broadcastCount() uint64 {
+	var count uint64
+	for _, s := range h.shards {
+		count += s.broadcasts.Load()
+	}
+	return count
+}
+
+// Stats returns the hub's current gauges and counters.
+func (h *Hub) Stats() Stats {
+	return h.snapshot(func(string, string) bool { return false }).Stats
+}
+
+// Snapshot returns the hub's stats and the state of every DM and scene with
+// connections on this instance. With dmID or sceneID set, only that room is
+// listed; the stats still cover the whole hub.
+func (h *Hub) Snapshot(dmID, sceneID string) HubState {
+	return h.snapshot(func(kind, id string) bool {
+		switch {
+		case dmID == "" && sceneID == "":
+			return true
+		case kind == "dm":
+			return id == dmID
+		default:
+			return id == sceneID
+		}
+	})
+}
+
+// snapshot returns the hub's stats and the state of the rooms list accepts.
+func (h *Hub) snapshot(list func(kind, id string) bool) HubState {
+	now := time.Now()
+	broadcasts := h.broadcastCount()
+	state := HubState{
+		Stats: Stats{
+			Broadcasts:    broadcasts,
+			BroadcastRate: h.rates.rate(now, broadcasts),
+			Sent:          h.sent.Load(),
+			Dropped:       h.dropped.Load(),
+			Evicted:       h.evicted.Load(),
+			QueueSize:     h.pump.SendQueueSize,
+			Shards:        make([]ShardStats, len(h.shards)),
+		},
+		Rooms: []RoomState{},
+	}
+	stats := &state.Stats
+
+	for i, s := range h.shards {
+		shard := &stats.Shards[i]
+		shard.Broadcasts = s.broadcasts.Load()
+		shard.Inbound = len(s.inbound)
+		if busy := s.busySince.Load(); busy != 0 {
+			since := time.Unix(0, busy)
+			shard.BusySince = &since
+		}
+
+		s.mu.RLock()
+		for _, kind := range []string{"dm", "scene"} {
+			rooms := s.dmClients
+			if kind == "scene" {
+				rooms = s.sceneClients
+			}
+			for id, clients := range rooms {
+				room := RoomState{Kind: kind, ID: id, Shard: i, Connections: len(clients)}
+				listed := list(kind, id)
+				users := make(map[string]bool)
+				for client := range clients {
+					queued := client.queue.len()
+					users[client.UserID] = true
+					room.QueuedTotal += queued
+					room.QueuedMax = max(room.QueuedMax, queued)
+					if listed {
+						room.Clients = append(room.Clients, ClientState{
+							UserID:      client.UserID,
+							IP:          client.IP,
+							Binary:      client.binary,
+							Queued:      queued,
+							ConnectedAt: client.connectedAt,
+						})
+					}
+				}
+				room.Users = len(users)
+
+				shard.Rooms++
+				shard.Connections += room.Connections
+				stats.Connections += room.Connections
+				stats.QueuedTotal += room.QueuedTotal
+				stats.QueuedMax = max(stats.QueuedMax, room.QueuedMax)
+				if kind == "dm" {
+					stats.DMs++
+				} else {
+					stats.Scenes++
+				}
+				if listed {
+					sort.Slice(room.Clients, func(a, b int) bool {
+						return room.Clients[a].ConnectedAt.Before(room.Clients[b].ConnectedAt)
+					})
+					state.Rooms = append(state.Rooms, room)
+				}
+			}
+		}
+		s.mu.RUnlock()
+	}
+
+	h.mu.RLock()
+	stats.Users = len(h.userClients)
+	h.mu.RUnlock()
+
+	// Busiest rooms first
+	sort.Slice(state.Rooms, func(a, b int) bool {
+		if state.Rooms[a].Connections != state.Rooms[b].Connections {
+			return state.Rooms[a].Connections > state.Rooms[b].Connections
+		}
+		return state.Rooms[a].ID < state.Rooms[b].ID
+	})
+	return state
+}
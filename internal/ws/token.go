@@ -0,0 +1,120 @@
+package ws
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TokenProtocol is the Sec-WebSocket-Protocol value that precedes a token
+// when a client cannot set query parameters, e.g.
+// new WebSocket(url, ["scenyx.token", token]). Upgraders must list it in
+// Subprotocols so the handshake echoes it back.
+const TokenProtocol = "scenyx.token"
+
+// ErrInvalidToken is returned for tokens that are malformed, forged, or expired.
+var ErrInvalidToken = errors.New("ws: invalid or expired token")
+
+// TokenSigner issues and verifies the short-lived tokens that authenticate
+// WebSocket upgrades. A token is "<userID>.<expiry unix>.<base64 HMAC>".
+type TokenSigner struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewTokenSigner creates a TokenSigner whose tokens expire after ttl. Every
+// instance behind a load balancer must share key.
+func NewTokenSigner(key []byte, ttl time.Duration) *TokenSigner {
+	return &TokenSigner{key: key, ttl: ttl}
+}
+
+// TTL returns how long issued tokens stay valid.
+func (s *TokenSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue returns a token for userID that expires ttl from now.
+func (s *TokenSigner) Issue(userID string) string {
+	payload := userID + "." + strconv.FormatInt(time.Now().Add(s.ttl).Unix(), 10)
+	return payload + "." + s.sign(payload)
+}
+
+// Verify returns the user ID of a token produced by Issue, or ErrInvalidToken.
+func (s *TokenSigner) Verify(token string) (string, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i <= 0 {
+		return "", ErrInvalidToken
+	}
+	payload := token[:i]
+	if !hmac.Equal([]byte(s.sign(payload)), []byte(token[i+1:])) {
+		return "", ErrInvalidToken
+	}
+	j := strings.LastIndexByte(payload, '.')
+	if j <= 0 {
+		return "", ErrInvalidToken
+	}
+	expiry, err := strconv.ParseInt(payload[j+1:], 10, 64)
+	if err != nil || time.Now().Unix() >= expiry {
+		return "", ErrInvalidToken
+	}
+	return payload[:j], nil
+}
+
+func (s *TokenSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// TokenFromRequest returns the token of a WebSocket upgrade request, taken
+// from the "token" query parameter or from the Sec-WebSocket-Protocol value
+// following TokenProtocol. It returns "" if neither is present.
+func TokenFromRequest(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+	protocols := websocket.Subprotocols(r)
+	for i, p := range protocols {
+		if p == TokenProtocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
+
+// BearerToken returns the token of an HTTP request's
+// "Authorization: Bearer <token>" header, or "" if it has none.
+func BearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// AuthenticateBearer returns the user an HTTP API request is made by, from
+// the token in its Authorization header.
+func (s *TokenSigner) AuthenticateBearer(r *http.Request) (string, error) {
+	return s.Verify(BearerToken(r))
+}
+
+// Authenticate returns the user a WebSocket upgrade request is made for. The
+// request must carry a valid token and, if it also names a user_id, the
+// token must have been issued to that user.
+func (s *TokenSigner) Authenticate(r *http.Request) (string, error) {
+	userID, err := s.Verify(TokenFromRequest(r))
+	if err != nil {
+		return "", err
+	}
+	if claimed := r.URL.Query().Get("user_id"); claimed != "" && claimed != userID {
+		return "", ErrInvalidToken
+	}
+	return userID, nil
+}
@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenSignerVerify(t *testing.T) {
+	signer := NewTokenSigner([]byte("key"), time.Minute)
+	token := signer.Issue("user-1")
+	dotted := signer.Issue("user.with.dots")
+
+	tests := []struct {
+		name   string
+		signer *TokenSigner
+		token  string
+		want   string // "" for ErrInvalidToken
+	}{
+		{"issued", signer, token, "user-1"},
+		{"user ID with dots", signer, dotted, "user.with.dots"},
+		{"other key", NewTokenSigner([]byte("other"), time.Minute), token, ""},
+		{"expired", signer, NewTokenSigner([]byte("key"), -time.Second).Issue("user-1"), ""},
+		{"user swapped", signer, "user-2" + strings.TrimPrefix(token, "user-1"), ""},
+		{"expiry extended", signer, "user-1.9999999999." + token[strings.LastIndexByte(token, '.')+1:], ""},
+		{"signature truncated", signer, token[:len(token)-1], ""},
+		{"no signature", signer, strings.TrimSuffix(token, token[strings.LastIndexByte(token, '.'):]), ""},
+		{"no expiry", signer, "user-1." + signer.sign("user-1"), ""},
+		{"bad expiry", signer, "user-1.soon." + signer.sign("user-1.soon"), ""},
+		{"empty", signer, "", ""},
+		{"dots only", signer, "..", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.signer.Verify(tt.token)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Verify(%q) = %q, %v; want ErrInvalidToken", tt.token, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Verify(%q) = %q, %v; want %q", tt.token, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenSignerAuthenticate(t *testing.T) {
+	signer := NewTokenSigner([]byte("key"), time.Minute)
+	token := signer.Issue("user-1")
+
+	tests := []struct {
+		name     string
+		target   string
+		protocol string // Sec-WebSocket-Protocol header
+		want     string // "" for ErrInvalidToken
+	}{
+		{"query token", "/ws?token=" + token, "", "user-1"},
+		{"query token with matching user", "/ws?user_id=user-1&token=" + token, "", "user-1"},
+		{"query token with other user", "/ws?user_id=user-2&token=" + token, "", ""},
+		{"subprotocol token", "/ws", TokenProtocol + ", " + token, "user-1"},
+		{"subprotocol without token", "/ws", TokenProtocol, ""},
+		{"no token", "/ws?user_id=user-1", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.protocol != "" {
+				r.Header.Set("Sec-WebSocket-Protocol", tt.protocol)
+			}
+			got, err := signer.Authenticate(r)
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Authenticate = %q, %v; want ErrInvalidToken", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Authenticate = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenSignerAuthenticateBearer(t *testing.T) {
+	signer := NewTokenSigner([]byte("key"), time.Minute)
+	token := signer.Issue("user-1")
+
+	tests := []struct {
+		name   string
+		header string // Authorization header
+		bearer string // Expected BearerToken
+		want   string // Expected user; "" for ErrInvalidToken
+	}{
+		{"bearer", "Bearer " + token, token, "user-1"},
+		{"lowercase scheme", "bearer " + token, token, "user-1"},
+		{"padded", "Bearer  " + token + " ", token, "user-1"},
+		{"basic", "Basic dXNlcjpwYXNz", "", ""},
+		{"scheme only", "Bearer", "", ""},
+		{"missing", "", "", ""},
+		{"forged", "Bearer user-1.9999999999.forged", "user-1.9999999999.forged", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/hub?token="+token, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if got := BearerToken(r); got != tt.bearer {
+				t.Errorf("BearerToken = %q, want %q", got, tt.bearer)
+			}
+			got, err := signer.AuthenticateBearer(r)
+			if tt.want == "" {
+				// The query token must not stand in for the header
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("AuthenticateBearer = %q, %v; want ErrInvalidToken", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("AuthenticateBearer = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS scene_messages (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    sender_id  TEXT NOT NULL,
+    content    TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_messages_scene_created ON scene_messages (scene_id, created_at);
@@ -0,0 +1,27 @@
+-- Move DM participants into a join table so conversations can have more
+-- than two members. One-to-one conversations keep a unique direct_key
+-- ("<user_a>:<user_b>", sorted) so StartOrGetConversation stays idempotent.
+ALTER TABLE dm_conversations ADD COLUMN IF NOT EXISTS is_group BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE dm_conversations ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+ALTER TABLE dm_conversations ADD COLUMN IF NOT EXISTS direct_key TEXT UNIQUE;
+
+CREATE TABLE IF NOT EXISTS dm_participants (
+    dm_conversation_id UUID NOT NULL REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    user_id            TEXT NOT NULL,
+    joined_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (dm_conversation_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dm_participants_user ON dm_participants (user_id);
+
+-- Backfill existing one-to-one conversations
+INSERT INTO dm_participants (dm_conversation_id, user_id, joined_at)
+SELECT id, participant1_id, created_at FROM dm_conversations
+ON CONFLICT DO NOTHING;
+INSERT INTO dm_participants (dm_conversation_id, user_id, joined_at)
+SELECT id, participant2_id, created_at FROM dm_conversations
+ON CONFLICT DO NOTHING;
+UPDATE dm_conversations SET direct_key = participant1_id || ':' || participant2_id WHERE direct_key IS NULL;
+
+ALTER TABLE dm_conversations DROP COLUMN IF EXISTS participant1_id;
+ALTER TABLE dm_conversations DROP COLUMN IF EXISTS participant2_id;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS scene_playback (
+    scene_id     UUID PRIMARY KEY REFERENCES scenes(id) ON DELETE CASCADE,
+    track_id     TEXT NOT NULL DEFAULT '',
+    track_title  TEXT NOT NULL DEFAULT '',
+    track_artist TEXT NOT NULL DEFAULT '',
+    position_ms  BIGINT NOT NULL DEFAULT 0,
+    is_playing   BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by   TEXT NOT NULL DEFAULT '',
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
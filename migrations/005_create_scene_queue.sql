@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS scene_queue (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scene_id    UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    title       TEXT NOT NULL,
+    artist      TEXT NOT NULL DEFAULT '',
+    artwork_url TEXT NOT NULL DEFAULT '',
+    provider_id TEXT NOT NULL DEFAULT '',
+    added_by    TEXT NOT NULL,
+    added_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_queue_scene_position ON scene_queue (scene_id, position);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS spotify_tokens (
+    user_id                 TEXT PRIMARY KEY,
+    encrypted_refresh_token BYTEA NOT NULL,
+    scope                   TEXT NOT NULL DEFAULT '',
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE dm_participants ADD COLUMN IF NOT EXISTS unread_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dm_participants ADD COLUMN IF NOT EXISTS last_read_at TIMESTAMPTZ;
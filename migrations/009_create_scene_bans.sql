@@ -0,0 +1,10 @@
+-- Per-scene restrictions placed by moderators. kind is 'ban' (cannot join)
+-- or 'mute' (cannot post chat messages).
+CREATE TABLE IF NOT EXISTS scene_bans (
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL,
+    kind       TEXT NOT NULL CHECK (kind IN ('ban', 'mute')),
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scene_id, user_id, kind)
+);
@@ -0,0 +1 @@
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
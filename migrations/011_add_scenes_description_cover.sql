@@ -0,0 +1,2 @@
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS cover_image_url TEXT NOT NULL DEFAULT '';
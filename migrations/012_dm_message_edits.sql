@@ -0,0 +1,4 @@
+-- Edited messages keep their row and record when they changed; deleted
+-- messages become tombstones (empty content) so pagination cursors stay valid.
+ALTER TABLE dm_messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+ALTER TABLE dm_messages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,16 @@
+-- Emoji reactions on DM and scene messages. Exactly one of the message
+-- columns is set; the foreign keys remove reactions with their message.
+CREATE TABLE IF NOT EXISTS message_reactions (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dm_message_id    UUID REFERENCES dm_messages(id) ON DELETE CASCADE,
+    scene_message_id UUID REFERENCES scene_messages(id) ON DELETE CASCADE,
+    user_id          TEXT NOT NULL,
+    emoji            TEXT NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((dm_message_id IS NULL) <> (scene_message_id IS NULL)),
+    UNIQUE (dm_message_id, user_id, emoji),
+    UNIQUE (scene_message_id, user_id, emoji)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_reactions_dm ON message_reactions (dm_message_id) WHERE dm_message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_message_reactions_scene ON message_reactions (scene_message_id) WHERE scene_message_id IS NOT NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS scene_pins (
+    message_id UUID PRIMARY KEY REFERENCES scene_messages(id) ON DELETE CASCADE,
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    pinned_by  TEXT NOT NULL,
+    pinned_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_pins_scene ON scene_pins (scene_id, pinned_at);
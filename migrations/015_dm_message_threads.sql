@@ -0,0 +1,4 @@
+-- Replies point at the top-level message that started their thread.
+ALTER TABLE dm_messages ADD COLUMN IF NOT EXISTS parent_message_id UUID REFERENCES dm_messages(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_dm_messages_parent ON dm_messages (parent_message_id, timestamp) WHERE parent_message_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Files uploaded to object storage. An attachment is created when the upload
+-- URL is issued and linked to at most one DM or scene message when sent.
+CREATE TABLE IF NOT EXISTS attachments (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    uploader_id      TEXT NOT NULL,
+    object_key       TEXT NOT NULL UNIQUE,
+    filename         TEXT NOT NULL,
+    content_type     TEXT NOT NULL,
+    size_bytes       BIGINT NOT NULL,
+    dm_message_id    UUID REFERENCES dm_messages(id) ON DELETE CASCADE,
+    scene_message_id UUID REFERENCES scene_messages(id) ON DELETE CASCADE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (dm_message_id IS NULL OR scene_message_id IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_dm_message ON attachments (dm_message_id) WHERE dm_message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_scene_message ON attachments (scene_message_id) WHERE scene_message_id IS NOT NULL;
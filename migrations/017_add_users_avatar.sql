@@ -0,0 +1,2 @@
+-- Object key of the user's uploaded avatar, NULL until one is uploaded.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key TEXT;
@@ -0,0 +1,11 @@
+-- Messages the content filter matched but let through with the "flag" action.
+CREATE TABLE IF NOT EXISTS message_flags (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_type TEXT NOT NULL CHECK (message_type IN ('dm', 'scene')),
+    message_id   UUID NOT NULL,
+    sender_id    TEXT NOT NULL,
+    reasons      TEXT[] NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_flags_created ON message_flags (created_at DESC);
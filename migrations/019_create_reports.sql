@@ -0,0 +1,20 @@
+-- User reports of abusive messages, scenes, and users, worked through by admins.
+CREATE TABLE IF NOT EXISTS reports (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reporter_id      TEXT NOT NULL,
+    target_type      TEXT NOT NULL CHECK (target_type IN ('dm_message', 'scene_message', 'scene', 'user')),
+    target_id        TEXT NOT NULL,
+    reported_user_id TEXT NOT NULL,
+    content          TEXT NOT NULL DEFAULT '',
+    reason           TEXT NOT NULL,
+    status           TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'actioned')),
+    resolved_by      TEXT,
+    resolution_note  TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at      TIMESTAMPTZ
+);
+
+-- A reporter may have only one open report per target
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_unique
+    ON reports (reporter_id, target_type, target_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_reports_status_created ON reports (status, created_at);
@@ -0,0 +1,19 @@
+-- Free-form tags on scenes and a weighted full-text index over name,
+-- artist name, and tags for /api/v1/scenes/search. The 'simple' config is
+-- used because scene and artist names are proper nouns that should not be stemmed.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- array_to_string is only STABLE, so wrap the expression in an IMMUTABLE
+-- function to use it in a generated column.
+CREATE OR REPLACE FUNCTION scene_search_vector(name TEXT, artist_name TEXT, tags TEXT[])
+RETURNS tsvector
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT setweight(to_tsvector('simple'::regconfig, coalesce(name, '')), 'A')
+        || setweight(to_tsvector('simple'::regconfig, coalesce(artist_name, '')), 'B')
+        || setweight(to_tsvector('simple'::regconfig, array_to_string(coalesce(tags, '{}'), ' ')), 'C')
+$$;
+
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (scene_search_vector(name, artist_name, tags)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_scenes_search ON scenes USING GIN (search_vector);
@@ -0,0 +1,9 @@
+-- Full-text indexes over DM and scene chat for message search. Deleted DM
+-- messages have empty content, so they drop out of the index on their own.
+ALTER TABLE dm_messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple'::regconfig, content)) STORED;
+CREATE INDEX IF NOT EXISTS idx_dm_messages_search ON dm_messages USING GIN (search_vector);
+
+ALTER TABLE scene_messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple'::regconfig, content)) STORED;
+CREATE INDEX IF NOT EXISTS idx_scene_messages_search ON scene_messages USING GIN (search_vector);
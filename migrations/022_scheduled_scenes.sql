@@ -0,0 +1,16 @@
+-- Scenes can be scheduled for a future start time. A scheduled scene turns
+-- "live" when scheduled_at passes; reminder_sent_at records that RSVP'd users
+-- were reminded so each scene is announced only once across instances.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'live'
+    CHECK (status IN ('scheduled', 'live'));
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMPTZ;
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS reminder_sent_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_scenes_scheduled ON scenes (scheduled_at) WHERE status = 'scheduled';
+
+CREATE TABLE IF NOT EXISTS scene_rsvps (
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scene_id, user_id)
+);
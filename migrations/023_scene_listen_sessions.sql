@@ -0,0 +1,14 @@
+-- One row per listening session: a user's time connected to a scene, from
+-- their first connection to their last disconnect on an instance. left_at is
+-- NULL while the session is open or if the instance stopped without closing it.
+CREATE TABLE IF NOT EXISTS scene_listen_sessions (
+    id        UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scene_id  UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    user_id   TEXT NOT NULL,
+    joined_at TIMESTAMPTZ NOT NULL,
+    left_at   TIMESTAMPTZ,
+    CHECK (left_at IS NULL OR left_at >= joined_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_listen_sessions_scene ON scene_listen_sessions (scene_id, joined_at);
+CREATE INDEX IF NOT EXISTS idx_scene_listen_sessions_open ON scene_listen_sessions (scene_id, user_id) WHERE left_at IS NULL;
@@ -0,0 +1,12 @@
+-- Daily concurrency samples per scene. Each sample taken while at least one
+-- listener is connected adds to samples and total_listeners, so the average
+-- concurrency while the scene was active is total_listeners / samples.
+CREATE TABLE IF NOT EXISTS scene_stats (
+    scene_id        UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    day             DATE NOT NULL,
+    samples         INTEGER NOT NULL DEFAULT 0,
+    total_listeners BIGINT NOT NULL DEFAULT 0,
+    peak_listeners  INTEGER NOT NULL DEFAULT 0,
+    peak_at         TIMESTAMPTZ,
+    PRIMARY KEY (scene_id, day)
+);
@@ -0,0 +1,34 @@
+-- Outbound webhooks. A webhook with a scene_id receives that scene's events;
+-- one without receives every event. Each event is queued as one delivery row
+-- per matching webhook and retried with backoff until it succeeds or runs out
+-- of attempts.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id   TEXT NOT NULL,
+    scene_id   UUID REFERENCES scenes(id) ON DELETE CASCADE,
+    url        TEXT NOT NULL,
+    secret     TEXT NOT NULL,
+    events     TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_owner ON webhooks (owner_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_scene ON webhooks (scene_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id       UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id         UUID NOT NULL,
+    event            TEXT NOT NULL,
+    payload          JSONB NOT NULL,
+    status           TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts         INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error       TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at);
@@ -0,0 +1,18 @@
+-- Transactional outbox. Store mutations that produce a domain event insert a
+-- row here in the same transaction, so an event exists if and only if its
+-- change was committed. The publisher job sends unpublished rows to the
+-- message broker in id order and stamps published_at; published rows are
+-- pruned after a day.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id             BIGSERIAL PRIMARY KEY,
+    event_id       UUID NOT NULL DEFAULT gen_random_uuid(),
+    aggregate_type TEXT NOT NULL,
+    aggregate_id   TEXT NOT NULL,
+    event          TEXT NOT NULL,
+    payload        JSONB NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published ON outbox_events (published_at) WHERE published_at IS NOT NULL;
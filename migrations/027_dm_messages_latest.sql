@@ -0,0 +1,2 @@
+-- Finds a conversation's latest message for the conversation list previews.
+CREATE INDEX IF NOT EXISTS idx_dm_messages_latest ON dm_messages (dm_conversation_id, timestamp DESC, id DESC);
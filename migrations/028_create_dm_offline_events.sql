@@ -0,0 +1,14 @@
+-- DM events sent while a participant had no connection to the conversation.
+-- They are handed to the participant's next connection to it and deleted;
+-- events nobody picked up within a week are pruned, and clients catch up
+-- from the REST history instead.
+CREATE TABLE IF NOT EXISTS dm_offline_events (
+    id                 BIGSERIAL PRIMARY KEY,
+    user_id            TEXT NOT NULL,
+    dm_conversation_id UUID NOT NULL REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    event              JSONB NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dm_offline_events_user ON dm_offline_events (user_id, dm_conversation_id, id);
+CREATE INDEX IF NOT EXISTS idx_dm_offline_events_created ON dm_offline_events (created_at);
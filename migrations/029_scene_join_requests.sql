@@ -0,0 +1,10 @@
+-- Scenes with join_approval set admit users only once the creator approves
+-- their request; pending requests live in scene_join_requests.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS join_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS scene_join_requests (
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scene_id, user_id)
+);
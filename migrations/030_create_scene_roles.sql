@@ -0,0 +1,15 @@
+-- Roles users hold in a scene. The creator is the host; the host can promote
+-- participants to co-host, who may then moderate and control playback.
+-- Participants without a row are listeners.
+CREATE TABLE IF NOT EXISTS scene_roles (
+    scene_id    UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    user_id     TEXT NOT NULL,
+    role        TEXT NOT NULL CHECK (role IN ('host', 'co-host', 'listener')),
+    assigned_by TEXT NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scene_id, user_id)
+);
+
+INSERT INTO scene_roles (scene_id, user_id, role, assigned_by)
+SELECT id, creator_id, 'host', creator_id FROM scenes
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,21 @@
+-- Multiple-choice polls asked in a scene. Each participant has at most one
+-- vote per poll, which they may change until the poll is closed.
+CREATE TABLE IF NOT EXISTS scene_polls (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    created_by TEXT NOT NULL,
+    question   TEXT NOT NULL,
+    options    TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_polls_scene ON scene_polls (scene_id, created_at);
+
+CREATE TABLE IF NOT EXISTS scene_poll_votes (
+    poll_id      UUID NOT NULL REFERENCES scene_polls(id) ON DELETE CASCADE,
+    user_id      TEXT NOT NULL,
+    option_index INTEGER NOT NULL,
+    voted_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (poll_id, user_id)
+);
@@ -0,0 +1,22 @@
+-- Listeners vote queued tracks up or down, and vote to skip the track that is
+-- playing. Once skip votes reach skip_threshold (a fraction of the scene's
+-- active listeners) playback moves on; 0 turns vote-to-skip off.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS skip_threshold DOUBLE PRECISION NOT NULL DEFAULT 0.5;
+
+CREATE TABLE IF NOT EXISTS scene_queue_votes (
+    item_id  UUID NOT NULL REFERENCES scene_queue(id) ON DELETE CASCADE,
+    user_id  TEXT NOT NULL,
+    vote     SMALLINT NOT NULL CHECK (vote IN (-1, 1)),
+    voted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (item_id, user_id)
+);
+
+-- Skip votes are keyed by the track they were cast against, so votes for a
+-- track that has already changed never count towards the next one.
+CREATE TABLE IF NOT EXISTS scene_skip_votes (
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    track_id   TEXT NOT NULL,
+    user_id    TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scene_id, track_id, user_id)
+);
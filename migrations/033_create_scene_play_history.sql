@@ -0,0 +1,24 @@
+-- Every track started in a scene, in play order, so the scene can be
+-- exported as a playlist.
+CREATE TABLE IF NOT EXISTS scene_play_history (
+    id           BIGSERIAL PRIMARY KEY,
+    scene_id     UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    track_id     TEXT NOT NULL,
+    track_title  TEXT NOT NULL DEFAULT '',
+    track_artist TEXT NOT NULL DEFAULT '',
+    played_by    TEXT NOT NULL,
+    played_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_play_history_scene ON scene_play_history (scene_id, id);
+
+-- The Spotify playlist a scene was exported to. track_count is how many of the
+-- scene's tracks the playlist already holds; later exports append the rest.
+CREATE TABLE IF NOT EXISTS scene_spotify_playlists (
+    scene_id     UUID PRIMARY KEY REFERENCES scenes(id) ON DELETE CASCADE,
+    user_id      TEXT NOT NULL,
+    playlist_id  TEXT NOT NULL,
+    playlist_url TEXT NOT NULL DEFAULT '',
+    track_count  INTEGER NOT NULL DEFAULT 0,
+    synced_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
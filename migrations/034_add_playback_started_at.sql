@@ -0,0 +1,4 @@
+-- When and by whom the current track was started, so late joiners can be told
+-- what is now playing.
+ALTER TABLE scene_playback ADD COLUMN IF NOT EXISTS started_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE scene_playback ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
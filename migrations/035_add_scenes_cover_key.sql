@@ -0,0 +1,3 @@
+-- Storage key of a cover uploaded through the API; each resized copy is
+-- stored beneath it. Empty when the scene uses a linked cover_image_url.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS cover_key TEXT NOT NULL DEFAULT '';
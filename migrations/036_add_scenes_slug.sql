@@ -0,0 +1,4 @@
+-- Human-readable name a creator can claim for a scene's share link (/s/<slug>).
+-- NULL until claimed; slugs are stored lowercase.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS slug TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scenes_slug ON scenes (slug);
@@ -0,0 +1,7 @@
+-- Unique handle users can be found by. NULL until chosen; stored lowercase.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+
+-- Prefix search over usernames and display names
+CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON users (username text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_display_name_prefix ON users (lower(display_name) text_pattern_ops);
@@ -0,0 +1,18 @@
+-- @username mentions resolved in DM and scene messages. Exactly one of the
+-- message columns is set; offsets are byte positions of the "@username" span
+-- in the message content.
+CREATE TABLE IF NOT EXISTS message_mentions (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dm_message_id    UUID REFERENCES dm_messages(id) ON DELETE CASCADE,
+    scene_message_id UUID REFERENCES scene_messages(id) ON DELETE CASCADE,
+    user_id          TEXT NOT NULL,
+    username         TEXT NOT NULL, -- As written in the message, lowercased
+    start_offset     INT NOT NULL,
+    end_offset       INT NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((dm_message_id IS NULL) <> (scene_message_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_mentions_dm ON message_mentions (dm_message_id) WHERE dm_message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_message_mentions_scene ON message_mentions (scene_message_id) WHERE scene_message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_message_mentions_user ON message_mentions (user_id, created_at DESC);
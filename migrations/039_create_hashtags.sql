@@ -0,0 +1,23 @@
+-- #hashtags used by scenes and their chat. A scene's hashtags come from its
+-- name, description, and tags and are replaced when those change; created_at
+-- is kept for hashtags the scene already had. Message hashtags are recorded
+-- once when the message is sent. Both feed tag pages and trending topics.
+CREATE TABLE IF NOT EXISTS scene_hashtags (
+    scene_id   UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    tag        TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scene_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_hashtags_tag ON scene_hashtags (tag, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS message_hashtags (
+    scene_message_id UUID NOT NULL REFERENCES scene_messages(id) ON DELETE CASCADE,
+    scene_id         UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    tag              TEXT NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scene_message_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_hashtags_tag ON message_hashtags (tag, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_message_hashtags_created ON message_hashtags (created_at);
@@ -0,0 +1,11 @@
+-- Users following other users, typically scene creators. Used to recommend
+-- the scenes of creators a user follows.
+CREATE TABLE IF NOT EXISTS user_follows (
+    follower_id TEXT NOT NULL,
+    followee_id TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows (followee_id);
@@ -0,0 +1,15 @@
+-- Scenes recommended to each user, rebuilt periodically by the
+-- scene-recommendations job. The score parts are kept so clients can say why
+-- a scene was recommended.
+CREATE TABLE IF NOT EXISTS scene_recommendations (
+    user_id          TEXT NOT NULL,
+    scene_id         UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    score            DOUBLE PRECISION NOT NULL,
+    shared_tags      INT NOT NULL DEFAULT 0,
+    follows_creator  BOOLEAN NOT NULL DEFAULT FALSE,
+    history_hours    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    computed_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, scene_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_recommendations_user ON scene_recommendations (user_id, score DESC);
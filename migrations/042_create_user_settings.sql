@@ -0,0 +1,7 @@
+-- Frontend preferences (theme, locale, privacy) saved per user. Users without
+-- a row get models.DefaultUserSettings.
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id    TEXT PRIMARY KEY,
+    settings   JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
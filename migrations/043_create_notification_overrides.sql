@@ -0,0 +1,16 @@
+-- Per-conversation notification levels that override a user's channel
+-- settings (kept in user_settings). Exactly one of the conversation columns
+-- is set.
+CREATE TABLE IF NOT EXISTS notification_overrides (
+    user_id            TEXT NOT NULL,
+    dm_conversation_id UUID REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    scene_id           UUID REFERENCES scenes(id) ON DELETE CASCADE,
+    level              TEXT NOT NULL CHECK (level IN ('all', 'mentions', 'none')),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((dm_conversation_id IS NULL) <> (scene_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_overrides_dm
+    ON notification_overrides (user_id, dm_conversation_id) WHERE dm_conversation_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_overrides_scene
+    ON notification_overrides (user_id, scene_id) WHERE scene_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Notifications held back during a user's quiet hours. They are deleted when
+-- the summary of them is sent after the quiet hours end.
+CREATE TABLE IF NOT EXISTS notification_queue (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    channel    TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    payload    JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_queue_user ON notification_queue (user_id, id);
@@ -0,0 +1,3 @@
+-- Participants can archive a conversation to hide it from their default list
+-- without affecting the other participants. New messages bring it back.
+ALTER TABLE dm_participants ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
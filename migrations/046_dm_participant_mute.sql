@@ -0,0 +1,3 @@
+-- Participants can mute a conversation: its messages are still delivered but
+-- raise no notifications and leave the participant's unread count alone.
+ALTER TABLE dm_participants ADD COLUMN IF NOT EXISTS muted_at TIMESTAMPTZ;
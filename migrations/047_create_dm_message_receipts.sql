@@ -0,0 +1,12 @@
+-- When each DM message reached a recipient's device and when they read it.
+-- A row is added on delivery (a WebSocket ack or a history fetch); read_at
+-- is set when the recipient marks the conversation read. Senders get no row.
+CREATE TABLE IF NOT EXISTS dm_message_receipts (
+    dm_message_id UUID NOT NULL REFERENCES dm_messages(id) ON DELETE CASCADE,
+    user_id       TEXT NOT NULL,
+    delivered_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at       TIMESTAMPTZ,
+    PRIMARY KEY (dm_message_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dm_message_receipts_user ON dm_message_receipts (user_id) WHERE read_at IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Disappearing messages: a conversation with a message TTL stamps each new
+-- message with an expiry, and a background job deletes messages past it.
+ALTER TABLE dm_conversations ADD COLUMN IF NOT EXISTS message_ttl_seconds INT
+    CHECK (message_ttl_seconds > 0);
+ALTER TABLE dm_messages ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_dm_messages_expires_at
+    ON dm_messages (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,16 @@
+-- DM messages a sender scheduled for later. A background job moves each one
+-- into dm_messages once send_at passes; cancelling deletes the row.
+CREATE TABLE IF NOT EXISTS dm_scheduled_messages (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dm_conversation_id UUID NOT NULL REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    sender_id          TEXT NOT NULL,
+    content            TEXT NOT NULL,
+    parent_message_id  UUID REFERENCES dm_messages(id) ON DELETE SET NULL,
+    attachment_ids     TEXT[] NOT NULL DEFAULT '{}',
+    flag_reasons       TEXT[] NOT NULL DEFAULT '{}', -- Content filter matches, recorded once the message is sent
+    send_at            TIMESTAMPTZ NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dm_scheduled_messages_send_at ON dm_scheduled_messages (send_at);
+CREATE INDEX IF NOT EXISTS idx_dm_scheduled_messages_sender ON dm_scheduled_messages (sender_id, send_at);
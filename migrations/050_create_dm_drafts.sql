@@ -0,0 +1,9 @@
+-- The message a participant has started typing in a conversation, so it
+-- follows them across devices. Saving empty content deletes the row.
+CREATE TABLE IF NOT EXISTS dm_drafts (
+    dm_conversation_id UUID NOT NULL REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    user_id            TEXT NOT NULL,
+    content            TEXT NOT NULL,
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (dm_conversation_id, user_id)
+);
@@ -0,0 +1,31 @@
+-- End-to-end encryption primitives. The server only stores and relays key
+-- material and ciphertext; it never sees a private key or a plaintext
+-- conversation key.
+
+-- Public keys users register for each of their devices.
+CREATE TABLE IF NOT EXISTS user_public_keys (
+    user_id    TEXT NOT NULL,
+    device_id  TEXT NOT NULL,
+    algorithm  TEXT NOT NULL,
+    public_key TEXT NOT NULL, -- Base64
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, device_id)
+);
+
+-- Each version of a conversation key, wrapped by a participant for every
+-- device of every participant. Rows are never overwritten, so a key cannot
+-- be swapped out from under a device.
+CREATE TABLE IF NOT EXISTS dm_conversation_keys (
+    dm_conversation_id UUID NOT NULL REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    key_version        INT NOT NULL CHECK (key_version > 0),
+    user_id            TEXT NOT NULL,
+    device_id          TEXT NOT NULL,
+    sender_id          TEXT NOT NULL,
+    wrapped_key        TEXT NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (dm_conversation_id, user_id, device_id, key_version)
+);
+
+-- Messages with a key version are ciphertext sealed with that version of
+-- the conversation key; NULL means plaintext.
+ALTER TABLE dm_messages ADD COLUMN IF NOT EXISTS key_version INT;
@@ -0,0 +1,18 @@
+-- Transcript exports of large scenes, generated by a background job and
+-- stored as a file the requesting host downloads. Small scenes are exported
+-- inline and leave no row.
+CREATE TABLE IF NOT EXISTS scene_transcript_exports (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scene_id     UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    requested_by TEXT NOT NULL,
+    format       TEXT NOT NULL CHECK (format IN ('json', 'csv')),
+    status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'ready', 'failed')),
+    blob_key     TEXT,
+    error        TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at   TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_transcript_exports_pending
+    ON scene_transcript_exports (created_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,3 @@
+-- Ephemeral scenes keep no history: their chat, queue, and play history are
+-- deleted in the same transaction that archives or closes the scene.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS ephemeral BOOLEAN NOT NULL DEFAULT FALSE;
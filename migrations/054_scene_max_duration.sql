@@ -0,0 +1,10 @@
+-- A scene with a maximum duration ends on its own once that long has passed
+-- since it started (its scheduled time, or its creation if created live).
+-- end_warning_seconds is the shortest countdown warning already sent, so
+-- each is sent once; it is cleared when the duration changes.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS max_duration_seconds INT
+    CHECK (max_duration_seconds > 0);
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS end_warning_seconds INT;
+
+CREATE INDEX IF NOT EXISTS idx_scenes_max_duration
+    ON scenes (id) WHERE max_duration_seconds IS NOT NULL AND archived_at IS NULL;
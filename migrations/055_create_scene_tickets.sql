@@ -0,0 +1,24 @@
+-- Ticketed scenes charge entry through Stripe Checkout. A ticket row is
+-- created when a checkout session starts and resolved by Stripe's webhook;
+-- only users holding a paid ticket may join. Stripe remains the record of
+-- the payment itself.
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS ticket_price_cents INT NOT NULL DEFAULT 0
+    CHECK (ticket_price_cents >= 0);
+ALTER TABLE scenes ADD COLUMN IF NOT EXISTS ticket_currency TEXT NOT NULL DEFAULT 'usd';
+
+CREATE TABLE IF NOT EXISTS scene_tickets (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scene_id            UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    user_id             TEXT NOT NULL,
+    amount_cents        INT NOT NULL,
+    currency            TEXT NOT NULL,
+    status              TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'failed', 'refunded')),
+    checkout_session_id TEXT NOT NULL UNIQUE,
+    payment_intent_id   TEXT,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    paid_at             TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scene_tickets_user ON scene_tickets (scene_id, user_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scene_tickets_paid ON scene_tickets (scene_id, user_id) WHERE status = 'paid';
+CREATE INDEX IF NOT EXISTS idx_scene_tickets_payment_intent ON scene_tickets (payment_intent_id) WHERE payment_intent_id IS NOT NULL;
@@ -0,0 +1,28 @@
+-- Tips and virtual gifts sent to scene hosts. Every gift is a row in the
+-- gifts ledger, paid from the sender's credit balance or through Stripe
+-- Checkout; a host's earnings are the completed rows for their scenes.
+CREATE TABLE IF NOT EXISTS user_credits (
+    user_id       TEXT PRIMARY KEY,
+    balance_cents BIGINT NOT NULL DEFAULT 0 CHECK (balance_cents >= 0),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS gifts (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scene_id            UUID NOT NULL REFERENCES scenes(id) ON DELETE CASCADE,
+    sender_id           TEXT NOT NULL,
+    recipient_id        TEXT NOT NULL,
+    kind                TEXT NOT NULL,
+    amount_cents        INT NOT NULL CHECK (amount_cents > 0),
+    currency            TEXT NOT NULL,
+    message             TEXT NOT NULL DEFAULT '',
+    method              TEXT NOT NULL CHECK (method IN ('credits', 'stripe')),
+    status              TEXT NOT NULL CHECK (status IN ('pending', 'completed', 'failed', 'refunded')),
+    checkout_session_id TEXT UNIQUE,
+    payment_intent_id   TEXT,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_gifts_scene_completed ON gifts (scene_id) WHERE status = 'completed';
+CREATE INDEX IF NOT EXISTS idx_gifts_payment_intent ON gifts (payment_intent_id) WHERE payment_intent_id IS NOT NULL;
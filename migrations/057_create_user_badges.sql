@@ -0,0 +1,12 @@
+-- Badges awarded by the achievements engine. A user earns each badge once;
+-- scene_id records the scene that earned it, when there is one.
+CREATE TABLE IF NOT EXISTS user_badges (
+    user_id    TEXT NOT NULL,
+    badge      TEXT NOT NULL,
+    scene_id   UUID REFERENCES scenes(id) ON DELETE SET NULL,
+    awarded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, badge)
+);
+
+-- Listening streaks count the days a user joined any scene
+CREATE INDEX IF NOT EXISTS idx_scene_listen_sessions_user ON scene_listen_sessions (user_id, joined_at);
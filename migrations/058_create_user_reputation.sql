@@ -0,0 +1,18 @@
+-- Per-user reputation, rebuilt by the user-reputation job from recent
+-- participation and the reports and scene restrictions against each user.
+-- Users with no participation or moderation history have no row.
+CREATE TABLE IF NOT EXISTS user_reputation (
+    user_id          TEXT PRIMARY KEY,
+    score            DOUBLE PRECISION NOT NULL,
+    listening_hours  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    scenes_hosted    INT NOT NULL DEFAULT 0,
+    messages_sent    INT NOT NULL DEFAULT 0,
+    reports_actioned INT NOT NULL DEFAULT 0,
+    reports_open     INT NOT NULL DEFAULT 0,
+    scene_bans       INT NOT NULL DEFAULT 0,
+    scene_mutes      INT NOT NULL DEFAULT 0,
+    computed_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_reported_user ON reports (reported_user_id, status);
+CREATE INDEX IF NOT EXISTS idx_scene_messages_sender ON scene_messages (sender_id, created_at);
@@ -0,0 +1,13 @@
+-- Leaderboards rebuilt by the leaderboards job. board is 'scenes' (most
+-- listened) or 'creators' (most active hosts); period is 'week' (the last
+-- seven days) or 'all-time'. subject_id is a scene ID or a user ID.
+CREATE TABLE IF NOT EXISTS leaderboard_entries (
+    board       TEXT NOT NULL CHECK (board IN ('scenes', 'creators')),
+    period      TEXT NOT NULL CHECK (period IN ('week', 'all-time')),
+    rank        INT NOT NULL,
+    subject_id  TEXT NOT NULL,
+    score       DOUBLE PRECISION NOT NULL,
+    listeners   INT NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (board, period, rank)
+);
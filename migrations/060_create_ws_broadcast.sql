@@ -0,0 +1,17 @@
+-- State of the Postgres WebSocket broker (WS_BROKER=postgres), which fans
+-- broadcasts out across instances with LISTEN/NOTIFY. ws_broadcast_seqs holds
+-- the last sequence number of each DM and scene channel. Broadcasts too large
+-- for a NOTIFY payload are stored in ws_broadcast_payloads and referenced by
+-- id; publishers delete them after a few minutes.
+CREATE TABLE IF NOT EXISTS ws_broadcast_seqs (
+    channel TEXT PRIMARY KEY,
+    seq     BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ws_broadcast_payloads (
+    id         BIGSERIAL PRIMARY KEY,
+    payload    BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ws_broadcast_payloads_created ON ws_broadcast_payloads (created_at);
@@ -0,0 +1,9 @@
+-- Files in object storage whose rows have been deleted, queued in the same
+-- transaction as the delete and removed by the orphan-blobs job. bucket
+-- names the blob store holding the file.
+CREATE TABLE IF NOT EXISTS orphan_blobs (
+    id         BIGSERIAL PRIMARY KEY,
+    bucket     TEXT NOT NULL CHECK (bucket IN ('attachments', 'transcripts')),
+    object_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
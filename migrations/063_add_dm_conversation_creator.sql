@@ -0,0 +1,4 @@
+-- The user who created a group conversation, who may remove its other
+-- members. NULL for one-to-one conversations and for groups created before
+-- the column existed.
+ALTER TABLE dm_conversations ADD COLUMN IF NOT EXISTS created_by TEXT;